@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/agent"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/collector"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+func main() {
+	agentCfg, err := agent.LoadConfigFromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load agent config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log := logger.New(os.Getenv("LOG_LEVEL"))
+	log.Info(
+		"Starting monitoring agent",
+		"host", agentCfg.Host,
+		"server", agentCfg.ServerURL,
+		"interval", agentCfg.Interval.String(),
+	)
+
+	metricsCollector := collector.NewSystemMetricsCollector(collector.Config{
+		Disk: collector.DiskConfig{
+			FSTypes:        agentCfg.DiskFSTypes,
+			ExcludeFSTypes: agentCfg.DiskExcludeFSTypes,
+			ExcludeMounts:  agentCfg.DiskExcludeMounts,
+			ExcludeDevices: agentCfg.DiskExcludeDevices,
+		},
+		Network: collector.NetworkConfig{
+			Interfaces:        agentCfg.NetworkInterfaces,
+			ExcludeInterfaces: agentCfg.NetworkExclude,
+		},
+	}, nil)
+	client := agent.NewClient(agentCfg.ServerURL, agentCfg.Token, agentCfg.RequestTimeout)
+	runner := agent.NewRunner(metricsCollector, client, log, agentCfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go runner.Start(ctx)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	<-sigCh
+
+	log.Info("Shutdown signal received")
+	cancel()
+
+	log.Info("Monitoring agent stopped")
+}
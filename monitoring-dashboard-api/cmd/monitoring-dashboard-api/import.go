@@ -0,0 +1,168 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/bulk"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/metrictype"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/postgres"
+	"github.com/dreschagin/monitoring-dashboard/pkg/config"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// runImport выполняет подкоманду import: загружает историю метрик из CSV/NDJSON напрямую в БД
+// Отчет (как у POST /api/v1/metrics/import) печатается в stdout; код возврата 1 - импорт прерван
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatName := flags.String("format", "", "file format: csv or ndjson (default: by file extension, csv for stdin)")
+	dryRun := flags.Bool("dry-run", false, "validate rows and count duplicates without writing")
+	batchSize := flags.Int("batch-size", 1000, "rows per database write")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: monitoring-dashboard-api import [-format csv|ndjson] [-dry-run] [-batch-size N] FILE|-")
+		fmt.Fprintln(flags.Output(), "FILE may be gzip-compressed (.gz); - reads stdin. Database settings come from the usual environment.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	format, err := importFormat(*formatName, path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+
+	// Отчет идет в stdout, поэтому по умолчанию логируются только ошибки
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "error"
+	}
+	log := logger.New(logLevel)
+
+	if _, err := metrictype.Configure(valueobject.DefaultMetricTypeRegistry(), cfg.Metrics.TypesFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load metric types from %s: %v\n", cfg.Metrics.TypesFile, err)
+		return 1
+	}
+
+	input, err := openImportFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", path, err)
+		return 1
+	}
+	defer input.Close()
+
+	db, err := sql.Open("postgres", cfg.Database.DSN())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to ping database: %v\n", err)
+		return 1
+	}
+
+	var rollupMetricsUC *usecase.RollupMetricsUseCase
+	if cfg.Metrics.RollupsEnabled {
+		rollupMetricsUC = usecase.NewRollupMetricsUseCase(
+			postgres.NewPostgresMetricRollupRepository(db),
+			usecase.RollupMetricsConfig{},
+			log,
+		)
+	}
+
+	importMetricsUC := usecase.NewImportMetricsUseCase(
+		postgres.NewPostgresMetricRepository(db),
+		service.NewMetricValidator(),
+		rollupMetricsUC, // Can be nil if rollups disabled
+		usecase.ImportMetricsConfig{BatchSize: *batchSize},
+		log,
+	)
+
+	reader, err := bulk.NewReader(format, input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", path, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, importErr := importMetricsUC.Execute(ctx, reader, *dryRun)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(result)
+
+	if importErr != nil {
+		fmt.Fprintf(os.Stderr, "Import stopped: %v\n", importErr)
+		return 1
+	}
+	return 0
+}
+
+// importFormat выбирает формат по флагу или расширению файла (.csv, .ndjson, .jsonl, в том числе с .gz)
+func importFormat(name, path string) (bulk.Format, error) {
+	if name == "" && path != "-" {
+		name = strings.TrimPrefix(filepath.Ext(strings.TrimSuffix(path, ".gz")), ".")
+	}
+	return bulk.ParseFormat(name)
+}
+
+// openImportFile открывает файл импорта ("-" - stdin); .gz распаковывается на лету
+func openImportFile(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: gz, file: file}, nil
+}
+
+// gzipFile закрывает и распаковщик, и сам файл
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (f *gzipFile) Close() error {
+	_ = f.Reader.Close()
+	return f.file.Close()
+}
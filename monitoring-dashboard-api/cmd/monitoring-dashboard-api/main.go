@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -13,17 +14,28 @@ import (
 
 	// Application
 	applicationPort "github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/promql"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
 
 	// Domain
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 
 	// Infrastructure
 	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/collector"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/otlp"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/remotewrite"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/statsd"
 	natsInfra "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/messaging/nats"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/metrictype"
+	notificationChannel "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/notification/channel"
 	wsInfra "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/notification/websocket"
 	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/observability/cloudwatch"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/observability/prometheus"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/buffer"
 	dynamodbRepo "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/dynamodb"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/instrumented"
 	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/postgres"
 	s3storage "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/storage/s3"
 
@@ -40,6 +52,11 @@ import (
 )
 
 func main() {
+	// Подкоманда import загружает историю метрик из файла и завершается, не запуская сервер
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+
 	// 1. Загружаем конфигурацию
 	cfg, err := config.Load()
 	if err != nil {
@@ -51,6 +68,16 @@ func main() {
 	log := logger.New(os.Getenv("LOG_LEVEL"))
 	log.Info("Starting Monitoring Dashboard")
 
+	// Регистрируем дополнительные типы метрик из конфигурации
+	registeredTypes, err := metrictype.Configure(valueobject.DefaultMetricTypeRegistry(), cfg.Metrics.TypesFile)
+	if err != nil {
+		log.Error("Failed to load metric types", err, "file", cfg.Metrics.TypesFile)
+		os.Exit(1)
+	}
+	if registeredTypes > 0 {
+		log.Info("Metric types loaded", "file", cfg.Metrics.TypesFile, "count", registeredTypes)
+	}
+
 	// 3. Подключаемся к БД
 	db, err := sql.Open("postgres", cfg.Database.DSN())
 	if err != nil {
@@ -74,14 +101,74 @@ func main() {
 
 	// 4. Dependency Injection - Infrastructure Layer
 
+	// Метрики самого сервиса для Prometheus (/metrics)
+	var serviceMetrics applicationPort.ServiceMetrics
+	var serviceMetricsImpl *prometheus.ServiceMetrics
+	var serviceMetricsRegistry *prometheus.Registry
+	if cfg.Metrics.EndpointEnabled {
+		serviceMetricsRegistry = prometheus.NewRegistry()
+		serviceMetricsImpl = prometheus.NewServiceMetrics(serviceMetricsRegistry)
+		serviceMetrics = serviceMetricsImpl
+	} else {
+		log.Warn("Service metrics endpoint /metrics is disabled")
+	}
+
 	// Repository
 	metricRepository := postgres.NewPostgresMetricRepository(db)
+	alertRuleRepository := postgres.NewPostgresAlertRuleRepository(db)
+	incidentRepository := postgres.NewPostgresIncidentRepository(db)
+	notificationDeliveryRepository := postgres.NewPostgresNotificationDeliveryRepository(db)
+
+	// Путь записи: прием метрик идет через буфер, который пишет в БД пачками через COPY
+	var metricWriteRepository repository.MetricRepository = metricRepository
+	if serviceMetrics != nil {
+		// Задержка SaveBatch измеряется на реальной записи в БД, под буфером
+		metricWriteRepository = instrumented.NewMetricRepository(metricRepository, serviceMetrics)
+	}
+	// Импорт истории пишет мимо буфера: дедупликация следующей пачки сверяется с уже записанными точками
+	metricImportRepository := metricWriteRepository
+	var metricWriteBuffer *buffer.MetricWriteBuffer
+	if cfg.Metrics.WriteBufferEnabled {
+		metricWriteBuffer = buffer.NewMetricWriteBuffer(metricWriteRepository, buffer.MetricWriteBufferConfig{
+			FlushSize:      cfg.Metrics.WriteBufferSize,
+			FlushInterval:  cfg.Metrics.WriteBufferInterval,
+			MaxPending:     cfg.Metrics.WriteBufferMax,
+			EnqueueTimeout: cfg.Metrics.WriteBufferTimeout,
+		}, serviceMetrics, log)
+		metricWriteRepository = metricWriteBuffer
+	}
+
+	var metricRollupRepository repository.MetricRollupRepository
+	if cfg.Metrics.RollupsEnabled {
+		metricRollupRepository = postgres.NewPostgresMetricRollupRepository(db)
+	} else {
+		log.Warn("Metric rollups are disabled, history is always read from raw metrics")
+	}
 
 	// Collectors
-	metricsCollector := collector.NewSystemMetricsCollector()
+	metricsCollector := collector.NewSystemMetricsCollector(collector.Config{
+		Disk: collector.DiskConfig{
+			FSTypes:        cfg.Metrics.DiskFSTypes,
+			ExcludeFSTypes: cfg.Metrics.DiskExcludeFSTypes,
+			ExcludeMounts:  cfg.Metrics.DiskExcludeMounts,
+			ExcludeDevices: cfg.Metrics.DiskExcludeDevices,
+		},
+		Network: collector.NetworkConfig{
+			Interfaces:        cfg.Metrics.NetworkInterfaces,
+			ExcludeInterfaces: cfg.Metrics.NetworkExclude,
+		},
+		Cgroup: collector.CgroupConfig{
+			Enabled: cfg.Metrics.CgroupEnabled,
+			Root:    cfg.Metrics.CgroupRoot,
+			Path:    cfg.Metrics.CgroupPath,
+		},
+	}, serviceMetrics)
 
 	// WebSocket Hub
 	hub := wsInfra.NewHub(log)
+	if serviceMetricsImpl != nil {
+		serviceMetricsImpl.RegisterWebSocketHub(hub.ClientCount, hub.DroppedSnapshots)
+	}
 
 	// 5. Dependency Injection - Domain Layer
 
@@ -105,6 +192,7 @@ func main() {
 				BufferSize:        cfg.CloudWatch.MetricsBufferSize,
 				FlushInterval:     cfg.CloudWatch.MetricsFlushInterval,
 				StorageResolution: cfg.CloudWatch.MetricsStorageResolution,
+				ServiceMetrics:    serviceMetrics,
 			})
 		if initErr != nil {
 			log.Error("Failed to initialize CloudWatch metrics publisher", initErr)
@@ -130,6 +218,7 @@ func main() {
 				BufferSize:      cfg.CloudWatch.LogsBufferSize,
 				FlushInterval:   cfg.CloudWatch.LogsFlushInterval,
 				AutoCreate:      true,
+				ServiceMetrics:  serviceMetrics,
 			})
 		if initErr != nil {
 			log.Error("Failed to initialize CloudWatch logs publisher", initErr)
@@ -145,7 +234,7 @@ func main() {
 	// 5.6. NATS Event Publisher
 	var eventPublisher applicationPort.EventPublisher
 	if cfg.NATS.Enabled {
-		publisherImpl, initErr := natsInfra.NewNATSPublisher(cfg.NATS.URL, log)
+		publisherImpl, initErr := natsInfra.NewNATSPublisher(cfg.NATS.URL, serviceMetrics, log)
 		if initErr != nil {
 			log.Warn("Failed to connect to NATS, continuing without event publishing", "error", initErr.Error())
 		} else {
@@ -157,29 +246,206 @@ func main() {
 		log.Warn("NATS event publishing is disabled")
 	}
 
+	// 5.7. Notification Channels (webhook, Slack, Telegram, SMTP)
+	var dispatchNotificationsUC *usecase.DispatchNotificationsUseCase
+	if cfg.Notifications.ChannelsFile != "" {
+		channelsConfig, loadErr := notificationChannel.LoadFile(cfg.Notifications.ChannelsFile)
+		if loadErr != nil {
+			log.Error("Failed to load notification channels", loadErr, "file", cfg.Notifications.ChannelsFile)
+			os.Exit(1)
+		}
+		dispatchNotificationsUC = usecase.NewDispatchNotificationsUseCase(
+			channelsConfig.Channels,
+			notificationDeliveryRepository,
+			usecase.DispatchNotificationsConfig{
+				DefaultChannels: channelsConfig.DefaultChannels,
+				Workers:         cfg.Notifications.Workers,
+				QueueSize:       cfg.Notifications.QueueSize,
+				MaxAttempts:     cfg.Notifications.MaxAttempts,
+				RetryBackoff:    cfg.Notifications.RetryBackoff,
+				MaxBackoff:      cfg.Notifications.MaxBackoff,
+				SendTimeout:     cfg.Notifications.SendTimeout,
+			},
+			log,
+		)
+		log.Info("Notification channels loaded",
+			"count", len(channelsConfig.Channels),
+			"default", strings.Join(channelsConfig.DefaultChannels, ","))
+	} else {
+		log.Warn("Notification channels are disabled (NOTIFICATION_CHANNELS_FILE not set)")
+	}
+
 	// 6. Dependency Injection - Application Layer (Use Cases)
 
+	manageIncidentsUC := usecase.NewManageIncidentsUseCase(
+		incidentRepository,
+		hub,
+		log,
+	)
+
+	evaluateAlertRulesUC := usecase.NewEvaluateAlertRulesUseCase(
+		alertRuleRepository,
+		metricWriteRepository, // Буфер дополняет окно алерта еще не записанными точками
+		metricAggregator,
+		hub,
+		eventPublisher, // Can be nil if NATS disabled
+		manageIncidentsUC,
+		dispatchNotificationsUC, // Can be nil if notification channels disabled
+		log,
+	)
+	if err := evaluateAlertRulesUC.Restore(context.Background()); err != nil {
+		// Без восстановления инциденты, открытые до перезапуска, придется закрыть вручную
+		log.Error("Failed to restore alert states from open incidents", err)
+	}
+
+	retentionOverrides := make(map[valueobject.MetricType]time.Duration, len(cfg.Metrics.RetentionOverrides))
+	for name, days := range cfg.Metrics.RetentionOverrides {
+		metricType := valueobject.MetricType(name)
+		if err := metricType.Validate(); err != nil {
+			log.Error("Invalid METRICS_RETENTION_OVERRIDES entry", err, "type", name)
+			os.Exit(1)
+		}
+		retentionOverrides[metricType] = time.Duration(days) * 24 * time.Hour
+	}
+
+	enforceRetentionUC := usecase.NewEnforceRetentionUseCase(
+		metricRepository,
+		usecase.EnforceRetentionConfig{
+			Retention:  time.Duration(cfg.Metrics.RetentionDays) * 24 * time.Hour,
+			Overrides:  retentionOverrides,
+			BatchSize:  cfg.Metrics.RetentionBatchSize,
+			BatchPause: cfg.Metrics.RetentionBatchPause,
+		},
+		log,
+	)
+
+	var manageMetricPartitionsUC *usecase.ManageMetricPartitionsUseCase
+	if cfg.Metrics.PartitioningEnabled {
+		// Партиция удаляется, только когда истек самый длинный срок хранения среди всех типов
+		partitionRetention := time.Duration(cfg.Metrics.RetentionDays) * 24 * time.Hour
+		for _, retention := range retentionOverrides {
+			if retention <= 0 || partitionRetention <= 0 {
+				partitionRetention = 0
+				break
+			}
+			if retention > partitionRetention {
+				partitionRetention = retention
+			}
+		}
+
+		manageMetricPartitionsUC = usecase.NewManageMetricPartitionsUseCase(
+			postgres.NewPostgresMetricPartitionRepository(db),
+			usecase.ManageMetricPartitionsConfig{
+				Interval:  cfg.Metrics.PartitionInterval,
+				Premake:   cfg.Metrics.PartitionPremake,
+				Retention: partitionRetention,
+			},
+			log,
+		)
+	} else {
+		log.Warn("Metric partition management is disabled")
+	}
+
+	var rollupMetricsUC *usecase.RollupMetricsUseCase
+	if metricRollupRepository != nil {
+		rollupRetention := make(map[valueobject.RollupTier]time.Duration, len(cfg.Metrics.RollupRetention))
+		for name, days := range cfg.Metrics.RollupRetention {
+			tier := valueobject.RollupTier(name)
+			if err := tier.Validate(); err != nil || tier == valueobject.TierRaw {
+				log.Error("Invalid METRICS_ROLLUP_RETENTION entry", err, "tier", name)
+				os.Exit(1)
+			}
+			rollupRetention[tier] = time.Duration(days) * 24 * time.Hour
+		}
+
+		rollupMetricsUC = usecase.NewRollupMetricsUseCase(
+			metricRollupRepository,
+			usecase.RollupMetricsConfig{
+				Lag:       cfg.Metrics.RollupLag,
+				Backfill:  time.Duration(cfg.Metrics.RetentionDays) * 24 * time.Hour,
+				Retention: rollupRetention,
+			},
+			log,
+		)
+	}
+
+	importMetricsUC := usecase.NewImportMetricsUseCase(
+		metricImportRepository,
+		metricValidator,
+		rollupMetricsUC, // Can be nil if rollups disabled
+		usecase.ImportMetricsConfig{},
+		log,
+	)
+
+	manageAlertRulesUC := usecase.NewManageAlertRulesUseCase(
+		alertRuleRepository,
+		log,
+	)
+
 	collectMetricsUC := usecase.NewCollectMetricsUseCase(
 		metricsCollector,
-		metricRepository,
+		metricWriteRepository,
 		hub,
 		metricValidator,
 		metricsPublisher, // Can be nil if CloudWatch disabled
 		eventPublisher,   // Can be nil if NATS disabled
+		evaluateAlertRulesUC,
+		serviceMetrics, // Can be nil if /metrics disabled
+		cfg.Metrics.Host,
 		log,
 	)
 
+	var collectProcessesUC *usecase.CollectProcessesUseCase
+	if cfg.Metrics.ProcessesEnabled {
+		collectProcessesUC = usecase.NewCollectProcessesUseCase(
+			collector.NewProcessCollector(collector.ProcessConfig{
+				IncludeCmdline: cfg.Metrics.ProcessesCmdline,
+			}),
+			hub,
+			usecase.CollectProcessesConfig{TopN: cfg.Metrics.ProcessesTopN},
+			cfg.Metrics.Host,
+			log,
+		)
+	} else {
+		log.Warn("Process collection is disabled")
+	}
+
 	getCurrentMetricsUC := usecase.NewGetCurrentMetricsUseCase(
 		metricRepository,
+		cfg.Metrics.Host,
 		log,
 	)
 
 	getHistoricalMetricsUC := usecase.NewGetHistoricalMetricsUseCase(
 		metricRepository,
+		metricRollupRepository, // Can be nil if rollups disabled
 		metricAggregator,
+		usecase.GetHistoricalMetricsConfig{MaxSamples: cfg.Metrics.QueryMaxSamples},
+		log,
+	)
+
+	listMetricsUC := usecase.NewListMetricsUseCase(
+		metricRepository,
+		usecase.ListMetricsConfig{},
+		log,
+	)
+
+	exportMetricsUC := usecase.NewExportMetricsUseCase(
+		metricRepository,
+		usecase.ExportMetricsConfig{
+			Staleness:      cfg.Metrics.QueryLookback,
+			MetadataLabels: cfg.Metrics.ExportLabels,
+		},
 		log,
 	)
 
+	// Буфер записи дополняет выборку запросов еще не записанными точками
+	queryEngine := promql.NewEngine(metricWriteRepository, promql.EngineConfig{
+		Lookback:   cfg.Metrics.QueryLookback,
+		MaxSamples: cfg.Metrics.QueryMaxSamples,
+	})
+	queryMetricsUC := usecase.NewQueryMetricsUseCase(queryEngine, log)
+
 	var screenshotStorage applicationPort.ScreenshotStorage
 	if cfg.S3.Enabled {
 		storageImpl, initErr := s3storage.NewScreenshotStorage(context.Background(), s3storage.Config{
@@ -258,7 +524,7 @@ func main() {
 	}
 
 	websocketHandler := handler.NewWebSocketHandler(hub, cfg.Security.AllowedOrigins, authConfig, log)
-	metricsAPIHandler := handler.NewMetricsAPIHandler(getHistoricalMetricsUC, 24*time.Hour, log)
+	metricsAPIHandler := handler.NewMetricsAPIHandler(getHistoricalMetricsUC, listMetricsUC, cfg.Metrics.HistoryMaxDuration, log)
 	screenshotAPIHandler := handler.NewScreenshotAPIHandler(
 		saveDashboardScreenshotsUC,
 		listDashboardScreenshotsUC,
@@ -275,6 +541,66 @@ func main() {
 		log,
 	)
 
+	var ingestAPIHandler *handler.IngestAPIHandler
+	if cfg.Ingest.Enabled {
+		remoteWriteDefaultType := valueobject.MetricType(cfg.Ingest.RemoteWriteDefaultType)
+		if remoteWriteDefaultType != "" {
+			if err := remoteWriteDefaultType.Validate(); err != nil {
+				log.Error("Invalid INGEST_REMOTE_WRITE_DEFAULT_TYPE", err, "type", remoteWriteDefaultType.String())
+				os.Exit(1)
+			}
+		}
+		otlpDefaultType := valueobject.MetricType(cfg.Ingest.OTLPDefaultType)
+		if otlpDefaultType != "" {
+			if err := otlpDefaultType.Validate(); err != nil {
+				log.Error("Invalid INGEST_OTLP_DEFAULT_TYPE", err, "type", otlpDefaultType.String())
+				os.Exit(1)
+			}
+		}
+		ingestAPIHandler = handler.NewIngestAPIHandler(
+			collectMetricsUC,
+			remotewrite.NewMapper(remotewrite.MapperConfig{
+				DefaultType: remoteWriteDefaultType,
+			}),
+			otlp.NewMapper(otlp.MapperConfig{
+				DefaultType:        otlpDefaultType,
+				ResourceAttributes: cfg.Ingest.OTLPResourceAttributes,
+			}),
+			middleware.AuthConfig{
+				Enabled:     true,
+				BearerToken: strings.TrimSpace(cfg.Ingest.AuthToken),
+			},
+			cfg.Ingest.MaxPayloadBytes,
+			log,
+		)
+		log.Info("Remote metrics ingest enabled", "paths", "/api/v1/ingest/metrics,/api/v1/ingest/remote_write,/api/v1/ingest/otlp/v1/metrics")
+	} else {
+		log.Warn("Remote metrics ingest is disabled")
+	}
+
+	alertRulesAPIHandler := handler.NewAlertRulesAPIHandler(manageAlertRulesUC, log)
+	incidentsAPIHandler := handler.NewIncidentsAPIHandler(manageIncidentsUC, log)
+
+	var notificationsAPIHandler *handler.NotificationsAPIHandler
+	if dispatchNotificationsUC != nil {
+		notificationsAPIHandler = handler.NewNotificationsAPIHandler(dispatchNotificationsUC, log)
+	}
+
+	retentionAPIHandler := handler.NewRetentionAPIHandler(enforceRetentionUC, cfg.Metrics.RetentionDryRun, log)
+	queryAPIHandler := handler.NewQueryAPIHandler(queryMetricsUC, cfg.Metrics.HistoryMaxDuration, log)
+	exportAPIHandler := handler.NewExportAPIHandler(exportMetricsUC, cfg.Metrics.ExportMaxRange, log)
+	importAPIHandler := handler.NewImportAPIHandler(importMetricsUC, cfg.Metrics.ImportMaxBytes, log)
+
+	var processesAPIHandler *handler.ProcessesAPIHandler
+	if collectProcessesUC != nil {
+		processesAPIHandler = handler.NewProcessesAPIHandler(collectProcessesUC, log)
+	}
+
+	var serviceMetricsHandler http.Handler
+	if serviceMetricsRegistry != nil {
+		serviceMetricsHandler = serviceMetricsRegistry.Handler()
+	}
+
 	// Router
 	router := httpInterface.NewRouter(
 		dashboardHandler,
@@ -283,6 +609,16 @@ func main() {
 		screenshotAPIHandler,
 		authAPIHandler,
 		releaseAnalyzerAPIHandler,
+		ingestAPIHandler,
+		alertRulesAPIHandler,
+		incidentsAPIHandler,
+		notificationsAPIHandler,
+		retentionAPIHandler,
+		queryAPIHandler,
+		exportAPIHandler,
+		importAPIHandler,
+		processesAPIHandler,   // Can be nil if process collection disabled
+		serviceMetricsHandler, // Can be nil if /metrics disabled
 		cfg.Security,
 		log,
 	)
@@ -296,13 +632,58 @@ func main() {
 	go hub.Run()
 	log.Info("WebSocket hub started")
 
+	// Запускаем воркеры доставки уведомлений
+	if dispatchNotificationsUC != nil {
+		go dispatchNotificationsUC.Run(ctx)
+		log.Info("Notification dispatcher started", "workers", cfg.Notifications.Workers)
+	}
+
+	// Запускаем сброс буфера записи метрик
+	if metricWriteBuffer != nil {
+		go metricWriteBuffer.Run(ctx)
+		log.Info("Metric write buffer started",
+			"flush_size", cfg.Metrics.WriteBufferSize,
+			"flush_interval", cfg.Metrics.WriteBufferInterval.String(),
+			"max_pending", cfg.Metrics.WriteBufferMax)
+	}
+
+	// Запускаем прием StatsD
+	var statsdServer *statsd.Server
+	if cfg.StatsD.Enabled {
+		statsdDefaultType := valueobject.MetricType(cfg.StatsD.DefaultType)
+		if statsdDefaultType != "" {
+			if err := statsdDefaultType.Validate(); err != nil {
+				log.Error("Invalid STATSD_DEFAULT_TYPE", err, "type", statsdDefaultType.String())
+				os.Exit(1)
+			}
+		}
+		statsdServer = statsd.NewServer(statsd.ServerConfig{
+			Address:       cfg.StatsD.Address,
+			FlushInterval: cfg.StatsD.FlushInterval,
+			Aggregator: statsd.AggregatorConfig{
+				DefaultType: statsdDefaultType,
+				DefaultHost: cfg.StatsD.DefaultHost,
+				MaxSeries:   cfg.StatsD.MaxSeries,
+			},
+		}, collectMetricsUC, serviceMetrics, log)
+		if err := statsdServer.Listen(); err != nil {
+			log.Error("Failed to start StatsD listener", err)
+			os.Exit(1)
+		}
+		go statsdServer.Run(ctx)
+		log.Info("StatsD listener started",
+			"address", statsdServer.Addr().String(),
+			"flush_interval", cfg.StatsD.FlushInterval.String())
+	}
+
 	// Запускаем сборщик метрик (каждые 2 секунды)
 	go func() {
 		ticker := time.NewTicker(cfg.Metrics.CollectionInterval)
 		defer ticker.Stop()
 
 		log.Info("Metrics collector started",
-			"interval", cfg.Metrics.CollectionInterval.String())
+			"interval", cfg.Metrics.CollectionInterval.String(),
+			"host", cfg.Metrics.Host)
 
 		for {
 			select {
@@ -317,6 +698,101 @@ func main() {
 		}
 	}()
 
+	// Запускаем снимки процессов с тем же интервалом, что и сбор метрик
+	if collectProcessesUC != nil {
+		go func() {
+			ticker := time.NewTicker(cfg.Metrics.CollectionInterval)
+			defer ticker.Stop()
+
+			log.Info("Process collector started", "top", cfg.Metrics.ProcessesTopN)
+
+			for {
+				select {
+				case <-ticker.C:
+					// Ошибка уже записана в журнал use case'ом
+					_ = collectProcessesUC.Execute(ctx)
+				case <-ctx.Done():
+					log.Info("Process collector stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	// Запускаем очистку устаревших метрик (первый прогон сразу после старта)
+	go func() {
+		ticker := time.NewTicker(cfg.Metrics.RetentionInterval)
+		defer ticker.Stop()
+
+		log.Info("Retention worker started",
+			"interval", cfg.Metrics.RetentionInterval.String(),
+			"retention_days", cfg.Metrics.RetentionDays,
+			"dry_run", cfg.Metrics.RetentionDryRun)
+
+		for {
+			if _, err := enforceRetentionUC.Execute(ctx, cfg.Metrics.RetentionDryRun); err != nil && !errors.Is(err, usecase.ErrRetentionInProgress) {
+				log.Error("Failed to enforce metrics retention", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				log.Info("Retention worker stopped")
+				return
+			}
+		}
+	}()
+
+	// Запускаем управление партициями метрик (первый прогон сразу после старта)
+	if manageMetricPartitionsUC != nil {
+		go func() {
+			ticker := time.NewTicker(cfg.Metrics.PartitionCheck)
+			defer ticker.Stop()
+
+			log.Info("Partition manager started",
+				"interval", cfg.Metrics.PartitionInterval.String(),
+				"premake", cfg.Metrics.PartitionPremake)
+
+			for {
+				if err := manageMetricPartitionsUC.Execute(ctx); err != nil && ctx.Err() == nil {
+					log.Error("Failed to manage metric partitions", err)
+				}
+
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					log.Info("Partition manager stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	// Запускаем построение уровней агрегации (первый прогон сразу после старта)
+	if rollupMetricsUC != nil {
+		go func() {
+			ticker := time.NewTicker(cfg.Metrics.RollupInterval)
+			defer ticker.Stop()
+
+			log.Info("Rollup worker started",
+				"interval", cfg.Metrics.RollupInterval.String(),
+				"lag", cfg.Metrics.RollupLag.String())
+
+			for {
+				if err := rollupMetricsUC.Execute(ctx); err != nil && ctx.Err() == nil {
+					log.Error("Failed to build metric rollups", err)
+				}
+
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					log.Info("Rollup worker stopped")
+					return
+				}
+			}
+		}()
+	}
+
 	// 9. Настраиваем HTTP сервер
 
 	server := &http.Server{
@@ -354,6 +830,25 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer shutdownCancel()
 
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("Server shutdown error", err)
+	}
+
+	// Передаем агрегаты StatsD последнего окна
+	if statsdServer != nil {
+		if err := statsdServer.Flush(shutdownCtx); err != nil {
+			log.Error("Failed to flush StatsD aggregates", err)
+		}
+	}
+
+	// Записываем метрики, оставшиеся в буфере после остановки приема
+	if metricWriteBuffer != nil {
+		log.Info("Flushing metric write buffer...", "pending", metricWriteBuffer.Pending())
+		if err := metricWriteBuffer.Flush(shutdownCtx); err != nil {
+			log.Error("Failed to flush metric write buffer", err)
+		}
+	}
+
 	// Flush CloudWatch buffers before shutdown
 	if metricsPublisher != nil {
 		log.Info("Flushing CloudWatch metrics buffer...")
@@ -369,9 +864,5 @@ func main() {
 		}
 	}
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Error("Server shutdown error", err)
-	}
-
 	log.Info("Server stopped gracefully")
 }
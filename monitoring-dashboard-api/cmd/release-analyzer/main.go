@@ -10,6 +10,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/metrictype"
 	"github.com/dreschagin/monitoring-dashboard/internal/releaseanalyzer"
 	"github.com/dreschagin/monitoring-dashboard/pkg/config"
 	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
@@ -54,6 +56,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	if _, err := metrictype.Configure(valueobject.DefaultMetricTypeRegistry(), baseCfg.Metrics.TypesFile); err != nil {
+		log.Error("Failed to load metric types", err, "file", baseCfg.Metrics.TypesFile)
+		os.Exit(1)
+	}
+
 	service := releaseanalyzer.NewService(db)
 	runner := releaseanalyzer.NewRunner(service, log, analyzerCfg.Interval)
 	handler := releaseanalyzer.NewHandler(runner)
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+// PushError описывает отказ ingest endpoint
+type PushError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *PushError) Error() string {
+	return fmt.Sprintf("ingest endpoint returned %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable сообщает, имеет ли смысл повторять отправку того же пакета
+func (e *PushError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// Client отправляет пакеты метрик в monitoring-dashboard-api
+type Client struct {
+	httpClient *http.Client
+	url        string
+	token      string
+}
+
+func NewClient(serverURL, token string, timeout time.Duration) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: timeout},
+		url:        serverURL + IngestPath,
+		token:      token,
+	}
+}
+
+// Push отправляет пакет сырых метрик хоста host
+func (c *Client) Push(ctx context.Context, host string, metrics []port.RawMetric) (*dto.IngestMetricsResultDTO, error) {
+	payload, err := json.Marshal(NewIngestRequest(host, metrics))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ingest request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ingest request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send ingest request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &PushError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))}
+	}
+
+	var result dto.IngestMetricsResultDTO
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode ingest response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// NewIngestRequest конвертирует сырые метрики collector'а в формат ingest endpoint
+func NewIngestRequest(host string, metrics []port.RawMetric) *dto.IngestMetricsRequestDTO {
+	items := make([]dto.IngestMetricDTO, 0, len(metrics))
+	for _, metric := range metrics {
+		items = append(items, dto.IngestMetricDTO{
+			Type:        metric.Type.String(),
+			Name:        metric.Name,
+			Value:       metric.Value.Raw(),
+			Unit:        metric.Value.Unit(),
+			Labels:      metric.Labels,
+			Metadata:    metric.Metadata,
+			CollectedAt: metric.CollectedAt,
+		})
+	}
+
+	return &dto.IngestMetricsRequestDTO{
+		Host:    host,
+		Metrics: items,
+	}
+}
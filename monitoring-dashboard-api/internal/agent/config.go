@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IngestPath путь ingest endpoint на стороне monitoring-dashboard-api
+const IngestPath = "/api/v1/ingest/metrics"
+
+type Config struct {
+	ServerURL      string
+	Token          string
+	Host           string
+	Interval       time.Duration
+	RequestTimeout time.Duration
+	BatchSize      int
+	MaxPending     int
+
+	// Фильтры сборщика дисков (пустой список - значения по умолчанию сборщика)
+	DiskFSTypes        []string
+	DiskExcludeFSTypes []string
+	DiskExcludeMounts  []string
+	DiskExcludeDevices []string
+
+	// Фильтры сетевых интерфейсов (пустой список - значения по умолчанию сборщика)
+	NetworkInterfaces []string
+	NetworkExclude    []string
+}
+
+func LoadConfigFromEnv() (Config, error) {
+	interval, err := time.ParseDuration(getEnv("AGENT_INTERVAL", "5s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid AGENT_INTERVAL: %w", err)
+	}
+	if interval < time.Second {
+		return Config{}, errors.New("AGENT_INTERVAL must be >= 1s")
+	}
+
+	requestTimeout, err := time.ParseDuration(getEnv("AGENT_REQUEST_TIMEOUT", "5s"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid AGENT_REQUEST_TIMEOUT: %w", err)
+	}
+
+	batchSize, err := strconv.Atoi(getEnv("AGENT_BATCH_SIZE", "1000"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid AGENT_BATCH_SIZE: %w", err)
+	}
+	if batchSize <= 0 {
+		return Config{}, errors.New("AGENT_BATCH_SIZE must be > 0")
+	}
+
+	maxPending, err := strconv.Atoi(getEnv("AGENT_MAX_PENDING", "10000"))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid AGENT_MAX_PENDING: %w", err)
+	}
+
+	token := strings.TrimSpace(os.Getenv("AGENT_TOKEN"))
+	if token == "" {
+		return Config{}, errors.New("AGENT_TOKEN is required")
+	}
+
+	host := getEnv("AGENT_HOST", "")
+	if host == "" {
+		host, err = os.Hostname()
+		if err != nil || host == "" {
+			return Config{}, errors.New("AGENT_HOST is required when hostname is unavailable")
+		}
+	}
+
+	return Config{
+		ServerURL:      strings.TrimRight(getEnv("AGENT_SERVER_URL", "http://localhost:8080"), "/"),
+		Token:          token,
+		Host:           host,
+		Interval:       interval,
+		RequestTimeout: requestTimeout,
+		BatchSize:      batchSize,
+		MaxPending:     maxPending,
+
+		DiskFSTypes:        getEnvList("AGENT_DISK_FS_TYPES"),
+		DiskExcludeFSTypes: getEnvList("AGENT_DISK_FS_TYPES_EXCLUDE"),
+		DiskExcludeMounts:  getEnvList("AGENT_DISK_MOUNTS_EXCLUDE"),
+		DiskExcludeDevices: getEnvList("AGENT_DISK_DEVICES_EXCLUDE"),
+
+		NetworkInterfaces: getEnvList("AGENT_NETWORK_INTERFACES"),
+		NetworkExclude:    getEnvList("AGENT_NETWORK_INTERFACES_EXCLUDE"),
+	}, nil
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvList разбирает список через запятую (пустые элементы пропускаются)
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
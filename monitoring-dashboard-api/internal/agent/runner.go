@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// Runner периодически собирает локальные метрики и отправляет их на сервер
+// Метрики, которые не удалось отправить, копятся в буфере (не более maxPending)
+// и досылаются на следующих циклах
+type Runner struct {
+	collector  port.MetricsCollector
+	client     *Client
+	log        *logger.Logger
+	host       string
+	interval   time.Duration
+	batchSize  int
+	maxPending int
+
+	runMu   sync.Mutex
+	pending []port.RawMetric
+}
+
+func NewRunner(collector port.MetricsCollector, client *Client, log *logger.Logger, cfg Config) *Runner {
+	return &Runner{
+		collector:  collector,
+		client:     client,
+		log:        log,
+		host:       cfg.Host,
+		interval:   cfg.Interval,
+		batchSize:  cfg.BatchSize,
+		maxPending: cfg.MaxPending,
+	}
+}
+
+func (r *Runner) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.RunOnce(ctx); err != nil {
+				// RunOnce already logs context.
+				continue
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunOnce выполняет один цикл сбора и отправки
+func (r *Runner) RunOnce(ctx context.Context) error {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	metrics, err := r.collector.CollectAll(ctx)
+	if err != nil {
+		wrappedErr := fmt.Errorf("failed to collect metrics: %w", err)
+		r.log.Error("Agent collection failed", wrappedErr)
+		return wrappedErr
+	}
+
+	// Фиксируем время сбора, чтобы досылаемые метрики сохранили исходную метку времени
+	collectedAt := time.Now()
+	for i := range metrics {
+		if metrics[i].CollectedAt.IsZero() {
+			metrics[i].CollectedAt = collectedAt
+		}
+	}
+
+	r.enqueue(metrics)
+
+	return r.flush(ctx)
+}
+
+// enqueue добавляет метрики в буфер, отбрасывая самые старые при переполнении
+func (r *Runner) enqueue(metrics []port.RawMetric) {
+	r.pending = append(r.pending, metrics...)
+
+	if r.maxPending > 0 && len(r.pending) > r.maxPending {
+		dropped := len(r.pending) - r.maxPending
+		r.pending = append([]port.RawMetric(nil), r.pending[dropped:]...)
+		r.log.Warn("Agent pending buffer overflow, dropping oldest metrics", "dropped", dropped)
+	}
+}
+
+// flush отправляет буфер пакетами по batchSize
+func (r *Runner) flush(ctx context.Context) error {
+	for len(r.pending) > 0 {
+		end := r.batchSize
+		if end > len(r.pending) {
+			end = len(r.pending)
+		}
+		batch := r.pending[:end]
+
+		result, err := r.client.Push(ctx, r.host, batch)
+		if err != nil {
+			var pushErr *PushError
+			if errors.As(err, &pushErr) && !pushErr.Retryable() {
+				// Сервер отверг пакет (auth/validation) - повтор не поможет
+				r.pending = r.pending[end:]
+				r.log.Error("Agent batch rejected by server", err, "batch_size", len(batch))
+				continue
+			}
+
+			r.log.Error("Agent push failed, will retry on next cycle", err, "pending", len(r.pending))
+			return err
+		}
+
+		r.pending = r.pending[end:]
+		r.log.Debug("Agent batch pushed",
+			"host", result.Host,
+			"accepted", result.Accepted,
+			"rejected", result.Rejected,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+type agentMockCollector struct{}
+
+func (c *agentMockCollector) CollectAll(_ context.Context) ([]port.RawMetric, error) {
+	value, _ := valueobject.NewMetricValue(12.5, "%")
+	return []port.RawMetric{{Type: valueobject.CPU, Name: "cpu_usage", Value: value}}, nil
+}
+
+func (c *agentMockCollector) CollectCPU(ctx context.Context) ([]port.RawMetric, error) {
+	return c.CollectAll(ctx)
+}
+
+func (c *agentMockCollector) CollectMemory(_ context.Context) ([]port.RawMetric, error) {
+	return nil, nil
+}
+
+func (c *agentMockCollector) CollectDisk(_ context.Context) ([]port.RawMetric, error) {
+	return nil, nil
+}
+
+func (c *agentMockCollector) CollectNetwork(_ context.Context) ([]port.RawMetric, error) {
+	return nil, nil
+}
+
+type ingestRecorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []dto.IngestMetricsRequestDTO
+}
+
+func (rec *ingestRecorder) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != IngestPath {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer agent-token" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+
+		var req dto.IngestMetricsRequestDTO
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode ingest request: %v", err)
+		}
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.requests = append(rec.requests, req)
+
+		status := http.StatusAccepted
+		if len(rec.statuses) > 0 {
+			status = rec.statuses[0]
+			rec.statuses = rec.statuses[1:]
+		}
+		if status != http.StatusAccepted {
+			http.Error(w, "unavailable", status)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(dto.IngestMetricsResultDTO{
+			Host:     req.Host,
+			Received: len(req.Metrics),
+			Accepted: len(req.Metrics),
+		})
+	}
+}
+
+func newTestRunner(serverURL string) *Runner {
+	cfg := Config{
+		Host:       "edge-1",
+		Interval:   time.Second,
+		BatchSize:  10,
+		MaxPending: 100,
+	}
+	client := NewClient(serverURL, "agent-token", time.Second)
+	return NewRunner(&agentMockCollector{}, client, logger.New("error"), cfg)
+}
+
+func TestRunnerRetriesPendingMetricsAfterServerError(t *testing.T) {
+	rec := &ingestRecorder{statuses: []int{http.StatusServiceUnavailable}}
+	server := httptest.NewServer(rec.handler(t))
+	defer server.Close()
+
+	runner := newTestRunner(server.URL)
+
+	if err := runner.RunOnce(context.Background()); err == nil {
+		t.Fatal("RunOnce() expected error on 503")
+	}
+	if len(runner.pending) != 1 {
+		t.Fatalf("pending = %d, want 1", len(runner.pending))
+	}
+
+	if err := runner.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(runner.pending) != 0 {
+		t.Fatalf("pending = %d, want 0", len(runner.pending))
+	}
+
+	last := rec.requests[len(rec.requests)-1]
+	if last.Host != "edge-1" {
+		t.Fatalf("host = %q, want edge-1", last.Host)
+	}
+	if len(last.Metrics) != 2 {
+		t.Fatalf("retried batch size = %d, want 2", len(last.Metrics))
+	}
+	if last.Metrics[0].CollectedAt.IsZero() || last.Metrics[0].CollectedAt.After(last.Metrics[1].CollectedAt) {
+		t.Fatalf("expected original collected_at to be preserved, got %v and %v",
+			last.Metrics[0].CollectedAt, last.Metrics[1].CollectedAt)
+	}
+}
+
+func TestRunnerDropsRejectedBatch(t *testing.T) {
+	rec := &ingestRecorder{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(rec.handler(t))
+	defer server.Close()
+
+	runner := newTestRunner(server.URL)
+
+	if err := runner.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(runner.pending) != 0 {
+		t.Fatalf("pending = %d, want 0 after non-retryable rejection", len(runner.pending))
+	}
+}
+
+func TestRunnerPendingBufferIsBounded(t *testing.T) {
+	runner := newTestRunner("http://example.invalid")
+	runner.maxPending = 3
+
+	value, _ := valueobject.NewMetricValue(1, "%")
+	batch := make([]port.RawMetric, 5)
+	for i := range batch {
+		batch[i] = port.RawMetric{Type: valueobject.CPU, Name: "cpu_usage", Value: value}
+	}
+
+	runner.enqueue(batch)
+	if len(runner.pending) != 3 {
+		t.Fatalf("pending = %d, want 3", len(runner.pending))
+	}
+}
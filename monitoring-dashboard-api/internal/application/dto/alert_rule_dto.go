@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+)
+
+// AlertRuleRequestDTO представляет тело запроса создания/обновления правила
+// Длительности задаются в формате Go (например, "30s", "5m")
+type AlertRuleRequestDTO struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	MetricType  string   `json:"metric_type"`
+	MetricName  string   `json:"metric_name"`
+	Unit        string   `json:"unit"`
+	Selector    string   `json:"selector"`
+	Aggregate   string   `json:"aggregate"`
+	Window      string   `json:"window"`
+	Comparator  string   `json:"comparator"`
+	Threshold   float64  `json:"threshold"`
+	For         string   `json:"for"`
+	Cooldown    string   `json:"cooldown"`
+	Severity    string   `json:"severity"`
+	Enabled     *bool    `json:"enabled"`
+	Channels    []string `json:"channels"`
+}
+
+// AlertRuleDTO представляет правило алертинга
+type AlertRuleDTO struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	MetricType  string    `json:"metric_type"`
+	MetricName  string    `json:"metric_name,omitempty"`
+	Unit        string    `json:"unit,omitempty"`
+	Selector    string    `json:"selector,omitempty"`
+	Aggregate   string    `json:"aggregate"`
+	Window      string    `json:"window"`
+	Comparator  string    `json:"comparator"`
+	Threshold   float64   `json:"threshold"`
+	For         string    `json:"for"`
+	Cooldown    string    `json:"cooldown"`
+	Severity    string    `json:"severity"`
+	Enabled     bool      `json:"enabled"`
+	Channels    []string  `json:"channels,omitempty"`
+	Condition   string    `json:"condition"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FromAlertRule конвертирует правило в DTO
+func FromAlertRule(rule *entity.AlertRule) *AlertRuleDTO {
+	params := rule.Params()
+
+	selector := ""
+	if !params.Selector.IsEmpty() {
+		selector = params.Selector.String()
+	}
+
+	return &AlertRuleDTO{
+		ID:          rule.ID(),
+		Name:        params.Name,
+		Description: params.Description,
+		MetricType:  params.MetricType.String(),
+		MetricName:  params.MetricName,
+		Unit:        params.Unit,
+		Selector:    selector,
+		Aggregate:   string(params.Aggregate),
+		Window:      params.Window.String(),
+		Comparator:  string(params.Comparator),
+		Threshold:   params.Threshold,
+		For:         params.For.String(),
+		Cooldown:    params.Cooldown.String(),
+		Severity:    params.Severity.String(),
+		Enabled:     params.Enabled,
+		Channels:    params.Channels,
+		Condition:   rule.Describe(),
+		CreatedAt:   rule.CreatedAt(),
+		UpdatedAt:   rule.UpdatedAt(),
+	}
+}
+
+// ToAlertRuleDTOs конвертирует слайс правил в DTOs
+func ToAlertRuleDTOs(rules []*entity.AlertRule) []*AlertRuleDTO {
+	dtos := make([]*AlertRuleDTO, 0, len(rules))
+	for _, rule := range rules {
+		dtos = append(dtos, FromAlertRule(rule))
+	}
+	return dtos
+}
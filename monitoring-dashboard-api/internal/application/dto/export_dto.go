@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// MetricFamilyDTO представляет последние значения всех серий одной метрики для экспорта
+type MetricFamilyDTO struct {
+	Name    string
+	Help    string
+	Unit    string
+	Samples []MetricSampleDTO
+}
+
+// MetricSampleDTO представляет последнее значение одной серии
+// Labels включают host, метки метрики и выбранные ключи метаданных
+type MetricSampleDTO struct {
+	Labels      map[string]string
+	Value       float64
+	CollectedAt time.Time
+}
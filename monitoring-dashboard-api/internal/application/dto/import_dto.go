@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// ImportRowErrorDTO строка файла импорта, которая не была загружена
+type ImportRowErrorDTO struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportMetricsResultDTO отчет об импорте истории метрик
+type ImportMetricsResultDTO struct {
+	DryRun     bool       `json:"dry_run"`
+	Rows       int        `json:"rows"`       // Прочитано строк с данными
+	Imported   int        `json:"imported"`   // Записано (в dry-run - было бы записано)
+	Duplicates int        `json:"duplicates"` // Уже есть в хранилище или повторяются в файле
+	Rejected   int        `json:"rejected"`   // Не прошли разбор или валидацию
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+
+	Errors          []ImportRowErrorDTO `json:"errors"`
+	ErrorsTruncated bool                `json:"errors_truncated,omitempty"` // В отчете только первые ошибки
+	Error           string              `json:"error,omitempty"`            // Импорт прерван: загружены строки до ошибки
+}
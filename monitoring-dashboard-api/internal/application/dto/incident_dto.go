@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+)
+
+// IncidentDTO представляет инцидент
+type IncidentDTO struct {
+	ID             string            `json:"id"`
+	RuleID         string            `json:"rule_id,omitempty"`
+	Title          string            `json:"title"`
+	Severity       string            `json:"severity"`
+	Status         string            `json:"status"`
+	Host           string            `json:"host,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	LastValue      float64           `json:"last_value"`
+	LastMessage    string            `json:"last_message,omitempty"`
+	AlertCount     int               `json:"alert_count"`
+	OpenedAt       time.Time         `json:"opened_at"`
+	LastAlertAt    time.Time         `json:"last_alert_at"`
+	AcknowledgedAt *time.Time        `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string            `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time        `json:"resolved_at,omitempty"`
+	ResolvedBy     string            `json:"resolved_by,omitempty"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// CreateIncidentRequestDTO представляет тело запроса ручного создания инцидента
+type CreateIncidentRequestDTO struct {
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Host     string `json:"host"`
+	Message  string `json:"message"`
+}
+
+// IncidentActionRequestDTO представляет тело запроса acknowledge/resolve
+type IncidentActionRequestDTO struct {
+	By      string `json:"by"`
+	Message string `json:"message"`
+}
+
+// FromIncident конвертирует инцидент в DTO
+func FromIncident(incident *entity.Incident) *IncidentDTO {
+	params := incident.Params()
+	lifecycle := incident.Lifecycle()
+
+	result := &IncidentDTO{
+		ID:             incident.ID(),
+		RuleID:         params.RuleID,
+		Title:          params.Title,
+		Severity:       params.Severity.String(),
+		Status:         string(lifecycle.Status),
+		Host:           params.Host,
+		Labels:         params.Labels.Map(),
+		LastValue:      lifecycle.LastValue,
+		LastMessage:    lifecycle.LastMessage,
+		AlertCount:     lifecycle.AlertCount,
+		OpenedAt:       lifecycle.OpenedAt,
+		LastAlertAt:    lifecycle.LastAlertAt,
+		AcknowledgedBy: lifecycle.AcknowledgedBy,
+		ResolvedBy:     lifecycle.ResolvedBy,
+		UpdatedAt:      lifecycle.UpdatedAt,
+	}
+	if !lifecycle.AcknowledgedAt.IsZero() {
+		acknowledgedAt := lifecycle.AcknowledgedAt
+		result.AcknowledgedAt = &acknowledgedAt
+	}
+	if !lifecycle.ResolvedAt.IsZero() {
+		resolvedAt := lifecycle.ResolvedAt
+		result.ResolvedAt = &resolvedAt
+	}
+
+	return result
+}
+
+// ToIncidentDTOs конвертирует слайс инцидентов в слайс DTO
+func ToIncidentDTOs(incidents []*entity.Incident) []*IncidentDTO {
+	dtos := make([]*IncidentDTO, len(incidents))
+	for i, incident := range incidents {
+		dtos[i] = FromIncident(incident)
+	}
+	return dtos
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// IngestMetricsRequestDTO представляет пакет метрик от удаленного агента
+// Используется как формат POST /api/v1/ingest/metrics
+type IngestMetricsRequestDTO struct {
+	Host    string            `json:"host"`
+	Metrics []IngestMetricDTO `json:"metrics"`
+}
+
+// IngestMetricDTO представляет одну сырую метрику в пакете агента
+type IngestMetricDTO struct {
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Value       float64                `json:"value"`
+	Unit        string                 `json:"unit"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	CollectedAt time.Time              `json:"collected_at"`
+}
+
+// IngestMetricsResultDTO содержит результат приема пакета метрик
+type IngestMetricsResultDTO struct {
+	Host     string `json:"host"`
+	Received int    `json:"received"`
+	Accepted int    `json:"accepted"`
+	Rejected int    `json:"rejected"`
+}
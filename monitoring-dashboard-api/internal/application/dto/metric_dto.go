@@ -11,6 +11,8 @@ type MetricDTO struct {
 	ID          string                 `json:"id"`
 	Type        string                 `json:"type"`
 	Name        string                 `json:"name"`
+	Host        string                 `json:"host,omitempty"`
+	Labels      map[string]string      `json:"labels,omitempty"`
 	Value       float64                `json:"value"`
 	Unit        string                 `json:"unit"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
@@ -19,6 +21,16 @@ type MetricDTO struct {
 	// Computed fields
 	IsCritical bool `json:"is_critical"`
 	IsWarning  bool `json:"is_warning"`
+	// Rollup заполняется для точек уровня агрегации (Value - среднее по бакету)
+	Rollup *MetricRollupStatsDTO `json:"rollup,omitempty"`
+}
+
+// MetricRollupStatsDTO представляет статистику бакета уровня агрегации
+type MetricRollupStatsDTO struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int64   `json:"count"`
+	Last  float64 `json:"last"`
 }
 
 // FromEntity конвертирует Domain Entity в DTO
@@ -27,6 +39,8 @@ func FromEntity(metric *entity.Metric) *MetricDTO {
 		ID:          metric.ID(),
 		Type:        metric.Type().String(),
 		Name:        metric.Name(),
+		Host:        metric.Host(),
+		Labels:      metric.Labels().Map(),
 		Value:       metric.Value().Raw(),
 		Unit:        metric.Value().Unit(),
 		Metadata:    metric.Metadata(),
@@ -45,3 +59,42 @@ func ToMetricDTOs(metrics []*entity.Metric) []*MetricDTO {
 	}
 	return dtos
 }
+
+// FromRollup конвертирует бакет уровня агрегации в DTO точки графика
+// Время точки - начало бакета, значение - среднее по бакету
+func FromRollup(rollup *entity.MetricRollup) *MetricDTO {
+	return &MetricDTO{
+		Type:        rollup.Type.String(),
+		Name:        rollup.Name,
+		Host:        rollup.Host,
+		Labels:      rollup.Labels.Map(),
+		Value:       rollup.Avg(),
+		Unit:        rollup.Unit,
+		CollectedAt: rollup.BucketStart,
+		CreatedAt:   rollup.LastAt,
+		IsCritical:  rollup.IsCritical(),
+		IsWarning:   rollup.IsWarning(),
+		Rollup: &MetricRollupStatsDTO{
+			Min:   rollup.Min,
+			Max:   rollup.Max,
+			Count: rollup.Count,
+			Last:  rollup.Last,
+		},
+	}
+}
+
+// ToRollupDTOs конвертирует слайс бакетов в слайс DTO
+func ToRollupDTOs(rollups []*entity.MetricRollup) []*MetricDTO {
+	dtos := make([]*MetricDTO, len(rollups))
+	for i, r := range rollups {
+		dtos[i] = FromRollup(r)
+	}
+	return dtos
+}
+
+// MetricPageDTO представляет страницу сырых метрик
+// NextCursor передается в параметре cursor следующего запроса; пустой - страниц больше нет
+type MetricPageDTO struct {
+	Metrics    []*MetricDTO `json:"metrics"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
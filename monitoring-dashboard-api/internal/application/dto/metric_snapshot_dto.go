@@ -9,13 +9,16 @@ import (
 
 // MetricSnapshotDTO представляет snapshot всех метрик
 // Используется для передачи через WebSocket
+// Поля CPU/Memory/Disk/Network сохранены для совместимости; Metrics содержит все типы, включая пользовательские
 type MetricSnapshotDTO struct {
-	Timestamp time.Time           `json:"timestamp"`
-	CPU       *MetricDTO          `json:"cpu,omitempty"`
-	Memory    *MetricDTO          `json:"memory,omitempty"`
-	Disk      *MetricDTO          `json:"disk,omitempty"`
-	Network   *MetricDTO          `json:"network,omitempty"`
-	Summary   *SnapshotSummaryDTO `json:"summary"`
+	Timestamp time.Time             `json:"timestamp"`
+	Host      string                `json:"host,omitempty"`
+	CPU       *MetricDTO            `json:"cpu,omitempty"`
+	Memory    *MetricDTO            `json:"memory,omitempty"`
+	Disk      *MetricDTO            `json:"disk,omitempty"`
+	Network   *MetricDTO            `json:"network,omitempty"`
+	Metrics   map[string]*MetricDTO `json:"metrics"`
+	Summary   *SnapshotSummaryDTO   `json:"summary"`
 }
 
 // SnapshotSummaryDTO содержит сводную информацию
@@ -32,10 +35,12 @@ type SnapshotSummaryDTO struct {
 func NewMetricSnapshotDTO(metricsMap map[valueobject.MetricType]*entity.Metric) *MetricSnapshotDTO {
 	snapshot := &MetricSnapshotDTO{
 		Timestamp: time.Now(),
+		Metrics:   make(map[string]*MetricDTO, len(metricsMap)),
 		Summary:   &SnapshotSummaryDTO{},
 	}
 
 	var criticalCount, warningCount int
+	hosts := make(map[string]struct{})
 
 	// Конвертируем каждую метрику
 	for metricType, metric := range metricsMap {
@@ -43,6 +48,8 @@ func NewMetricSnapshotDTO(metricsMap map[valueobject.MetricType]*entity.Metric)
 			continue
 		}
 
+		hosts[metric.Host()] = struct{}{}
+
 		dto := FromEntity(metric)
 		snapshot.Summary.TotalMetrics++
 
@@ -53,6 +60,7 @@ func NewMetricSnapshotDTO(metricsMap map[valueobject.MetricType]*entity.Metric)
 		}
 
 		// Распределяем по типам
+		snapshot.Metrics[metricType.String()] = dto
 		switch metricType {
 		case valueobject.CPU:
 			snapshot.CPU = dto
@@ -65,6 +73,13 @@ func NewMetricSnapshotDTO(metricsMap map[valueobject.MetricType]*entity.Metric)
 		}
 	}
 
+	// Snapshot привязан к хосту, только если все метрики с одного хоста
+	if len(hosts) == 1 {
+		for host := range hosts {
+			snapshot.Host = host
+		}
+	}
+
 	// Заполняем summary
 	snapshot.Summary.CriticalCount = criticalCount
 	snapshot.Summary.WarningCount = warningCount
@@ -95,11 +110,19 @@ func NewMetricSnapshotFromSlice(metrics []*entity.Metric) *MetricSnapshotDTO {
 }
 
 // AlertDTO представляет alert для отправки клиентам
+// Для алертов правил заполнены поля правила и серии; Metric - последняя метрика серии
 type AlertDTO struct {
-	Timestamp time.Time  `json:"timestamp"`
-	Level     string     `json:"level"` // "warning", "critical"
-	Metric    *MetricDTO `json:"metric"`
-	Message   string     `json:"message"`
+	Timestamp time.Time         `json:"timestamp"`
+	Level     string            `json:"level"`           // "info", "warning", "critical"
+	State     string            `json:"state,omitempty"` // "firing", "resolved"
+	RuleID    string            `json:"rule_id,omitempty"`
+	RuleName  string            `json:"rule_name,omitempty"`
+	Host      string            `json:"host,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Value     float64           `json:"value"`
+	Threshold float64           `json:"threshold"`
+	Metric    *MetricDTO        `json:"metric"`
+	Message   string            `json:"message"`
 }
 
 // NewAlertDTO создает новый alert
@@ -112,11 +135,33 @@ func NewAlertDTO(metric *entity.Metric, message string) *AlertDTO {
 	return &AlertDTO{
 		Timestamp: time.Now(),
 		Level:     level,
+		Host:      metric.Host(),
+		Value:     metric.Value().Raw(),
 		Metric:    FromEntity(metric),
 		Message:   message,
 	}
 }
 
+// NewRuleAlertDTO создает alert по изменению состояния правила
+func NewRuleAlertDTO(rule *entity.AlertRule, state *entity.AlertState, metric *entity.Metric, message string) *AlertDTO {
+	alert := &AlertDTO{
+		Timestamp: time.Now(),
+		Level:     rule.Severity().String(),
+		State:     string(state.Status()),
+		RuleID:    rule.ID(),
+		RuleName:  rule.Name(),
+		Host:      state.Host(),
+		Labels:    state.Labels().Map(),
+		Value:     state.Value(),
+		Threshold: rule.Params().Threshold,
+		Message:   message,
+	}
+	if metric != nil {
+		alert.Metric = FromEntity(metric)
+	}
+	return alert
+}
+
 // MetricHistoryDTO представляет исторические данные метрик с агрегатами
 type MetricHistoryDTO struct {
 	Type          string       `json:"type"`
@@ -126,4 +171,20 @@ type MetricHistoryDTO struct {
 	Max           float64      `json:"max"`
 	CriticalCount int          `json:"critical_count"`
 	WarningCount  int          `json:"warning_count"`
+	Resolution    string       `json:"resolution,omitempty"`   // Уровень агрегации точек: raw, 1m, 5m, 1h
+	StepSeconds   float64      `json:"step_seconds,omitempty"` // Шаг бакетов, если задан параметром step
+}
+
+// MetricSeriesDTO представляет историю одной группы метрик (значения метки group_by)
+type MetricSeriesDTO struct {
+	Value   string            `json:"value"`
+	History *MetricHistoryDTO `json:"history"`
+}
+
+// MetricGroupedHistoryDTO представляет историю метрик, сгруппированную по метке
+type MetricGroupedHistoryDTO struct {
+	Type     string             `json:"type"`
+	GroupBy  string             `json:"group_by"`
+	Selector string             `json:"selector,omitempty"`
+	Series   []*MetricSeriesDTO `json:"series"`
 }
@@ -0,0 +1,38 @@
+package dto
+
+import "github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+
+// MetricTypeDTO представляет описание типа метрики для клиентов
+type MetricTypeDTO struct {
+	Name          string             `json:"name"`
+	DisplayName   string             `json:"display_name"`
+	Description   string             `json:"description,omitempty"`
+	Units         []string           `json:"units"`
+	ThresholdUnit string             `json:"threshold_unit,omitempty"`
+	Warning       float64            `json:"warning,omitempty"`
+	Critical      float64            `json:"critical,omitempty"`
+	MaxValues     map[string]float64 `json:"max_values,omitempty"`
+}
+
+// FromMetricTypeDefinition конвертирует описание типа в DTO
+func FromMetricTypeDefinition(definition valueobject.MetricTypeDefinition) *MetricTypeDTO {
+	return &MetricTypeDTO{
+		Name:          definition.Type.String(),
+		DisplayName:   definition.DisplayName,
+		Description:   definition.Description,
+		Units:         definition.Units,
+		ThresholdUnit: definition.Thresholds.Unit,
+		Warning:       definition.Thresholds.Warning,
+		Critical:      definition.Thresholds.Critical,
+		MaxValues:     definition.MaxValues,
+	}
+}
+
+// ToMetricTypeDTOs конвертирует описания типов в DTOs
+func ToMetricTypeDTOs(definitions []valueobject.MetricTypeDefinition) []*MetricTypeDTO {
+	dtos := make([]*MetricTypeDTO, 0, len(definitions))
+	for _, definition := range definitions {
+		dtos = append(dtos, FromMetricTypeDefinition(definition))
+	}
+	return dtos
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+)
+
+// NotificationDeliveryDTO представляет запись журнала доставки уведомлений
+type NotificationDeliveryDTO struct {
+	ID          string    `json:"id"`
+	Channel     string    `json:"channel"`
+	ChannelType string    `json:"channel_type,omitempty"`
+	RuleID      string    `json:"rule_id,omitempty"`
+	RuleName    string    `json:"rule_name,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	AlertState  string    `json:"alert_state,omitempty"`
+	Severity    string    `json:"severity,omitempty"`
+	Message     string    `json:"message"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// FromNotificationDelivery конвертирует запись журнала в DTO
+func FromNotificationDelivery(delivery *entity.NotificationDelivery) *NotificationDeliveryDTO {
+	params := delivery.Params()
+	return &NotificationDeliveryDTO{
+		ID:          delivery.ID(),
+		Channel:     params.Channel,
+		ChannelType: params.ChannelType,
+		RuleID:      params.RuleID,
+		RuleName:    params.RuleName,
+		Host:        params.Host,
+		AlertState:  params.AlertState,
+		Severity:    params.Severity,
+		Message:     params.Message,
+		Status:      string(delivery.Status()),
+		Attempts:    delivery.Attempts(),
+		LastError:   delivery.LastError(),
+		CreatedAt:   delivery.CreatedAt(),
+		UpdatedAt:   delivery.UpdatedAt(),
+	}
+}
+
+// ToNotificationDeliveryDTOs конвертирует слайс записей журнала в слайс DTO
+func ToNotificationDeliveryDTOs(deliveries []*entity.NotificationDelivery) []*NotificationDeliveryDTO {
+	dtos := make([]*NotificationDeliveryDTO, len(deliveries))
+	for i, delivery := range deliveries {
+		dtos[i] = FromNotificationDelivery(delivery)
+	}
+	return dtos
+}
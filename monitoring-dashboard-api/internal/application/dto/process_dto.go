@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// ProcessDTO представляет процесс в таблице top
+type ProcessDTO struct {
+	PID           int32   `json:"pid"`
+	Name          string  `json:"name"`
+	Cmdline       string  `json:"cmdline,omitempty"`
+	User          string  `json:"user,omitempty"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	RSSBytes      uint64  `json:"rss_bytes"`
+	MemoryPercent float64 `json:"memory_percent"`
+}
+
+// TopProcessesDTO представляет самые нагруженные процессы хоста на момент сбора
+type TopProcessesDTO struct {
+	Host           string       `json:"host,omitempty"`
+	CollectedAt    time.Time    `json:"collected_at"`
+	TotalProcesses int          `json:"total_processes"`
+	ByCPU          []ProcessDTO `json:"by_cpu"`
+	ByMemory       []ProcessDTO `json:"by_memory"`
+}
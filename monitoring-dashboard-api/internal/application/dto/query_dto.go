@@ -0,0 +1,31 @@
+package dto
+
+// QueryResponseDTO ответ API языка запросов в формате, совместимом с Prometheus HTTP API
+type QueryResponseDTO struct {
+	Status    string        `json:"status"` // "success" или "error"
+	Data      *QueryDataDTO `json:"data,omitempty"`
+	ErrorType string        `json:"errorType,omitempty"` // bad_data, execution, internal
+	Error     string        `json:"error,omitempty"`
+}
+
+// QueryDataDTO результат вычисления выражения
+// Result - []QuerySampleDTO для vector, []QuerySeriesDTO для matrix, QueryPointDTO для scalar
+type QueryDataDTO struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+// QueryPointDTO точка в виде [unix-секунды, "значение"]
+type QueryPointDTO [2]interface{}
+
+// QuerySampleDTO значение серии в момент вычисления (instant vector)
+type QuerySampleDTO struct {
+	Metric map[string]string `json:"metric"`
+	Value  QueryPointDTO     `json:"value"`
+}
+
+// QuerySeriesDTO точки серии (range vector)
+type QuerySeriesDTO struct {
+	Metric map[string]string `json:"metric"`
+	Values []QueryPointDTO   `json:"values"`
+}
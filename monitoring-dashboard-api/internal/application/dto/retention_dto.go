@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// RetentionPolicyResultDTO результат применения одной политики хранения
+type RetentionPolicyResultDTO struct {
+	MetricType string    `json:"metric_type"` // "*" - политика по умолчанию для остальных типов
+	Retention  string    `json:"retention"`
+	Cutoff     time.Time `json:"cutoff"`
+	Rows       int64     `json:"rows"` // Удалено строк (в dry-run - подлежит удалению)
+	Batches    int       `json:"batches"`
+}
+
+// RetentionRunDTO результат прогона очистки
+type RetentionRunDTO struct {
+	DryRun     bool                        `json:"dry_run"`
+	StartedAt  time.Time                   `json:"started_at"`
+	FinishedAt time.Time                   `json:"finished_at"`
+	DurationMs int64                       `json:"duration_ms"`
+	Rows       int64                       `json:"rows"`
+	Policies   []*RetentionPolicyResultDTO `json:"policies"`
+	Error      string                      `json:"error,omitempty"`
+}
+
+// RetentionStatsDTO накопленная статистика очистки с момента запуска процесса
+type RetentionStatsDTO struct {
+	Runs        int64            `json:"runs"`
+	RowsDeleted int64            `json:"rows_deleted"`
+	LastRun     *RetentionRunDTO `json:"last_run,omitempty"`
+}
@@ -0,0 +1,21 @@
+package port
+
+// ImportRow строка файла импорта истории метрик
+type ImportRow struct {
+	// Line номер строки в файле (с 1, заголовок CSV тоже считается)
+	Line int
+
+	// Metric разобранная метрика; CollectedAt обязателен
+	Metric RawMetric
+
+	// Err ошибка разбора строки: строка попадает в отчет и пропускается, импорт продолжается
+	Err error
+}
+
+// MetricRowReader последовательно читает строки файла импорта (Port)
+// Реализация будет в Infrastructure слое (CSV, NDJSON)
+type MetricRowReader interface {
+	// Next возвращает следующую строку; io.EOF - строки закончились,
+	// другая ошибка - файл поврежден и дальше не читается
+	Next() (ImportRow, error)
+}
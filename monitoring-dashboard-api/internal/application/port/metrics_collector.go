@@ -2,6 +2,7 @@ package port
 
 import (
 	"context"
+	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 )
@@ -13,6 +14,15 @@ type RawMetric struct {
 	Name     string
 	Value    valueobject.MetricValue
 	Metadata map[string]interface{}
+
+	// Labels измерения серии (mount, interface, core...), индексируются в хранилище
+	Labels map[string]string
+
+	// Host идентифицирует машину, с которой собрана метрика (пустой - локальный хост)
+	Host string
+
+	// CollectedAt время сбора на стороне агента (нулевое - время приема)
+	CollectedAt time.Time
 }
 
 // MetricsCollector определяет интерфейс для сбора метрик (Port)
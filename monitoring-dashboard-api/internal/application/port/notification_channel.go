@@ -0,0 +1,29 @@
+package port
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// ErrNotificationRejected означает, что канал отклонил уведомление и повтор не поможет
+// (например, неверный токен или адрес). Адаптеры оборачивают им такие ошибки.
+var ErrNotificationRejected = errors.New("notification rejected")
+
+// NotificationChannel определяет внешний канал доставки алертов (Port)
+// Реализации в Infrastructure слое: webhook, Slack, Telegram, SMTP
+type NotificationChannel interface {
+	// Name возвращает уникальное имя канала, на которое ссылаются правила
+	Name() string
+
+	// Type возвращает тип адаптера ("webhook", "slack", "telegram", "smtp")
+	Type() string
+
+	// MinSeverity возвращает минимальный уровень алертов, принимаемых каналом ("" - любые)
+	MinSeverity() valueobject.AlertSeverity
+
+	// Send доставляет алерт; ошибка означает неудачную попытку
+	Send(ctx context.Context, alert *dto.AlertDTO) error
+}
@@ -11,6 +11,12 @@ type NotificationService interface {
 	// BroadcastAlert отправляет alert всем подключенным клиентам
 	BroadcastAlert(alert *dto.AlertDTO)
 
+	// BroadcastIncident отправляет изменение инцидента всем подключенным клиентам
+	BroadcastIncident(incident *dto.IncidentDTO)
+
+	// BroadcastProcesses отправляет снимок самых нагруженных процессов всем подключенным клиентам
+	BroadcastProcesses(processes *dto.TopProcessesDTO)
+
 	// ClientCount возвращает количество подключенных клиентов
 	ClientCount() int
 }
@@ -0,0 +1,29 @@
+package port
+
+import "context"
+
+// ProcessSample снимок одного процесса за цикл сбора
+type ProcessSample struct {
+	PID     int32
+	Name    string
+	Cmdline string
+	User    string
+
+	// CPUPercent загрузка CPU за интервал между снимками в процентах одного ядра
+	// (как в top: многопоточный процесс может превышать 100)
+	CPUPercent float64
+
+	// RSSBytes резидентная память процесса
+	RSSBytes uint64
+
+	// MemoryPercent доля RSS от физической памяти хоста
+	MemoryPercent float64
+}
+
+// ProcessCollector определяет интерфейс для сбора снимка процессов хоста (Port)
+// Реализация будет в Infrastructure слое
+type ProcessCollector interface {
+	// CollectProcesses возвращает все доступные процессы; CPUPercent считается
+	// относительно предыдущего вызова, поэтому вызывать его следует с постоянным интервалом
+	CollectProcesses(ctx context.Context) ([]ProcessSample, error)
+}
@@ -0,0 +1,51 @@
+package port
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// Причины отбрасывания метрики конвейером сбора (значение метки reason)
+const (
+	DropReasonInvalidType   = "invalid_type"
+	DropReasonInvalidLabels = "invalid_labels"
+	DropReasonValidation    = "validation"
+	DropReasonUnreasonable  = "unreasonable"
+
+	// DropReasonStorageRejected метрика отвергнута хранилищем при сбросе буфера записи
+	DropReasonStorageRejected = "storage_rejected"
+
+	// DropReasonStatsDRetention агрегат StatsD не записан за несколько неудачных сбросов и вытеснен более новыми
+	DropReasonStatsDRetention = "statsd_retention"
+)
+
+// Получатели публикации (значение метки target)
+const (
+	PublishTargetCloudWatchMetrics = "cloudwatch_metrics"
+	PublishTargetCloudWatchLogs    = "cloudwatch_logs"
+	PublishTargetNATS              = "nats"
+)
+
+// ServiceMetrics учитывает метрики работы самого сервиса (Port)
+// Реализация отдает их в формате Prometheus на /metrics
+type ServiceMetrics interface {
+	// ObserveCollection учитывает длительность цикла локального сбора метрик
+	ObserveCollection(duration time.Duration)
+
+	// CollectorFailed учитывает ошибку сборщика метрик типа metricType
+	CollectorFailed(metricType valueobject.MetricType)
+
+	// MetricDropped учитывает метрику, отброшенную при валидации
+	MetricDropped(reason string)
+
+	// ObserveSaveBatch учитывает длительность записи пачки метрик в хранилище
+	ObserveSaveBatch(duration time.Duration, err error)
+
+	// CacheHit и CacheMiss учитывают обращения к кешу исторических метрик
+	CacheHit()
+	CacheMiss()
+
+	// PublishFailed учитывает неудачную публикацию во внешнюю систему target
+	PublishFailed(target string)
+}
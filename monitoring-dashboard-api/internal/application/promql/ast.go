@@ -0,0 +1,123 @@
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// ErrTooManySamples возвращается, если селектор выбирает больше точек, чем разрешено
+var ErrTooManySamples = errors.New("query selects too many samples")
+
+// ParseError ошибка разбора выражения с позицией в исходной строке
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+// Error реализует error
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at char %d: %s", e.Pos+1, e.Msg)
+}
+
+// ValueType тип значения выражения
+type ValueType string
+
+const (
+	ValueTypeScalar ValueType = "scalar"
+	ValueTypeVector ValueType = "vector"
+	ValueTypeMatrix ValueType = "matrix"
+)
+
+// Expr узел дерева выражения
+type Expr interface {
+	// Type возвращает тип значения, которое дает выражение
+	Type() ValueType
+}
+
+// NumberLiteral числовая константа
+type NumberLiteral struct {
+	Value float64
+}
+
+// VectorSelector выбирает серии по имени и меткам
+// С ненулевым Range это селектор диапазона (cpu_usage[5m]), дающий matrix
+type VectorSelector struct {
+	Name     string
+	Selector valueobject.LabelSelector
+	Range    time.Duration
+	Offset   time.Duration
+}
+
+// Call вызов функции
+type Call struct {
+	Func *Function
+	Args []Expr
+}
+
+// AggregateExpr агрегация по сериям: sum by (host) (...)
+type AggregateExpr struct {
+	Op       string
+	Expr     Expr
+	Param    Expr // параметр quantile
+	Grouping []string
+	Without  bool
+}
+
+// BinaryExpr бинарная операция
+type BinaryExpr struct {
+	Op         string
+	LHS, RHS   Expr
+	ReturnBool bool // сравнение с модификатором bool возвращает 0/1 вместо фильтрации
+	Matching   *VectorMatching
+}
+
+// VectorMatching правило сопоставления серий двух векторов: on(...) или ignoring(...)
+type VectorMatching struct {
+	On     bool
+	Labels []string
+}
+
+// UnaryExpr унарный минус
+type UnaryExpr struct {
+	Op   string
+	Expr Expr
+}
+
+// ParenExpr выражение в скобках
+type ParenExpr struct {
+	Expr Expr
+}
+
+// Type реализует Expr
+func (e *NumberLiteral) Type() ValueType { return ValueTypeScalar }
+
+// Type реализует Expr
+func (e *VectorSelector) Type() ValueType {
+	if e.Range > 0 {
+		return ValueTypeMatrix
+	}
+	return ValueTypeVector
+}
+
+// Type реализует Expr
+func (e *Call) Type() ValueType { return e.Func.ReturnType }
+
+// Type реализует Expr
+func (e *AggregateExpr) Type() ValueType { return ValueTypeVector }
+
+// Type реализует Expr
+func (e *BinaryExpr) Type() ValueType {
+	if e.LHS.Type() == ValueTypeScalar && e.RHS.Type() == ValueTypeScalar {
+		return ValueTypeScalar
+	}
+	return ValueTypeVector
+}
+
+// Type реализует Expr
+func (e *UnaryExpr) Type() ValueType { return e.Expr.Type() }
+
+// Type реализует Expr
+func (e *ParenExpr) Type() ValueType { return e.Expr.Type() }
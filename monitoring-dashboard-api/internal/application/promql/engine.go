@@ -0,0 +1,603 @@
+package promql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+const (
+	// defaultLookback окно, в котором ищется последнее значение серии для мгновенного селектора
+	defaultLookback = 5 * time.Minute
+
+	// defaultMaxSamples ограничивает количество точек, загружаемых одним запросом
+	defaultMaxSamples = 50000
+)
+
+// EvalError ошибка вычисления корректно разобранного выражения (например, неоднозначное сопоставление серий)
+type EvalError struct {
+	Msg string
+}
+
+// Error реализует error
+func (e *EvalError) Error() string {
+	return e.Msg
+}
+
+// EngineConfig параметры вычислителя запросов
+type EngineConfig struct {
+	// Lookback окно поиска последнего значения для мгновенного селектора (0 - 5m)
+	Lookback time.Duration
+
+	// MaxSamples максимальное количество точек, загружаемых одним запросом (0 - 50000)
+	MaxSamples int
+}
+
+// Engine вычисляет выражения по сырым метрикам из MetricRepository
+type Engine struct {
+	repository repository.MetricRepository
+	lookback   time.Duration
+	maxSamples int
+}
+
+// NewEngine создает вычислитель запросов
+func NewEngine(repository repository.MetricRepository, config EngineConfig) *Engine {
+	if config.Lookback <= 0 {
+		config.Lookback = defaultLookback
+	}
+	if config.MaxSamples <= 0 {
+		config.MaxSamples = defaultMaxSamples
+	}
+
+	return &Engine{
+		repository: repository,
+		lookback:   config.Lookback,
+		maxSamples: config.MaxSamples,
+	}
+}
+
+// Instant вычисляет выражение в момент ts
+func (e *Engine) Instant(ctx context.Context, query string, ts time.Time) (Result, error) {
+	expr, err := Parse(query)
+	if err != nil {
+		return Result{}, err
+	}
+
+	ev, err := e.prepare(ctx, expr, ts, ts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	value, err := ev.eval(expr, ts)
+	if err != nil {
+		return Result{}, err
+	}
+	switch value.Type {
+	case ValueTypeVector:
+		sortVector(value.Vector)
+	case ValueTypeMatrix:
+		sortMatrix(value.Matrix)
+	}
+	return value, nil
+}
+
+// Range вычисляет выражение в каждой точке [start, end] с шагом step и возвращает matrix
+func (e *Engine) Range(ctx context.Context, query string, start, end time.Time, step time.Duration) (Result, error) {
+	if step <= 0 {
+		return Result{}, errors.New("step must be positive")
+	}
+	if end.Before(start) {
+		return Result{}, errors.New("end must not be before start")
+	}
+
+	expr, err := Parse(query)
+	if err != nil {
+		return Result{}, err
+	}
+	if expr.Type() == ValueTypeMatrix {
+		return Result{}, &ParseError{Pos: 0, Msg: "range query must return an instant vector or a scalar"}
+	}
+
+	ev, err := e.prepare(ctx, expr, start, end)
+	if err != nil {
+		return Result{}, err
+	}
+
+	series := make(map[string]*Series)
+	for ts := start; !ts.After(end); ts = ts.Add(step) {
+		value, err := ev.eval(expr, ts)
+		if err != nil {
+			return Result{}, err
+		}
+
+		samples := value.Vector
+		if value.Type == ValueTypeScalar {
+			samples = Vector{{Metric: Labels{}, Point: value.Scalar}}
+		}
+		for _, sample := range samples {
+			key := sample.Metric.String()
+			s, ok := series[key]
+			if !ok {
+				s = &Series{Metric: sample.Metric}
+				series[key] = s
+			}
+			s.Points = append(s.Points, Point{T: ts, V: sample.V})
+		}
+	}
+
+	matrix := make(Matrix, 0, len(series))
+	for _, s := range series {
+		matrix = append(matrix, *s)
+	}
+	sortMatrix(matrix)
+
+	return Result{Type: ValueTypeMatrix, Matrix: matrix}, nil
+}
+
+// prepare загружает точки всех селекторов выражения для вычисления в диапазоне [start, end]
+func (e *Engine) prepare(ctx context.Context, expr Expr, start, end time.Time) (*evaluator, error) {
+	ev := &evaluator{
+		lookback: e.lookback,
+		series:   make(map[*VectorSelector][]Series),
+	}
+
+	total := 0
+	for _, selector := range collectSelectors(expr) {
+		window := selector.Range
+		if window == 0 {
+			window = e.lookback
+		}
+		timeRange, err := valueobject.NewTimeRange(
+			start.Add(-selector.Offset-window),
+			end.Add(-selector.Offset),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector time range: %w", err)
+		}
+
+		metrics, err := e.repository.FindByLabels(ctx, repository.MetricQuery{
+			Name:      selector.Name,
+			Selector:  selector.Selector,
+			TimeRange: timeRange,
+			Limit:     e.maxSamples - total + 1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metrics: %w", err)
+		}
+
+		total += len(metrics)
+		if total > e.maxSamples {
+			return nil, fmt.Errorf("%w (max %d)", ErrTooManySamples, e.maxSamples)
+		}
+
+		byKey := make(map[string]*Series)
+		for _, metric := range metrics {
+			labels := Labels(metric.Labels().Map())
+			labels[valueobject.NameLabel] = metric.Name()
+			if metric.Host() != "" {
+				labels[valueobject.HostLabel] = metric.Host()
+			}
+
+			key := labels.String()
+			s, ok := byKey[key]
+			if !ok {
+				s = &Series{Metric: labels}
+				byKey[key] = s
+			}
+			s.Points = append(s.Points, Point{T: metric.CollectedAt(), V: metric.Value().Raw()})
+		}
+
+		series := make([]Series, 0, len(byKey))
+		for _, s := range byKey {
+			sort.Slice(s.Points, func(i, j int) bool { return s.Points[i].T.Before(s.Points[j].T) })
+			series = append(series, *s)
+		}
+		ev.series[selector] = series
+	}
+
+	return ev, nil
+}
+
+// collectSelectors возвращает все селекторы выражения
+func collectSelectors(expr Expr) []*VectorSelector {
+	var result []*VectorSelector
+	var walk func(Expr)
+	walk = func(expr Expr) {
+		switch e := expr.(type) {
+		case *VectorSelector:
+			result = append(result, e)
+		case *Call:
+			for _, arg := range e.Args {
+				walk(arg)
+			}
+		case *AggregateExpr:
+			if e.Param != nil {
+				walk(e.Param)
+			}
+			walk(e.Expr)
+		case *BinaryExpr:
+			walk(e.LHS)
+			walk(e.RHS)
+		case *UnaryExpr:
+			walk(e.Expr)
+		case *ParenExpr:
+			walk(e.Expr)
+		}
+	}
+	walk(expr)
+	return result
+}
+
+// evaluator вычисляет выражение по заранее загруженным сериям
+type evaluator struct {
+	lookback time.Duration
+	series   map[*VectorSelector][]Series
+}
+
+func (ev *evaluator) eval(expr Expr, ts time.Time) (Result, error) {
+	switch e := expr.(type) {
+	case *NumberLiteral:
+		return scalarResult(ts, e.Value), nil
+	case *ParenExpr:
+		return ev.eval(e.Expr, ts)
+	case *VectorSelector:
+		return ev.evalSelector(e, ts), nil
+	case *Call:
+		return ev.evalCall(e, ts)
+	case *AggregateExpr:
+		return ev.evalAggregate(e, ts)
+	case *BinaryExpr:
+		return ev.evalBinary(e, ts)
+	case *UnaryExpr:
+		value, err := ev.eval(e.Expr, ts)
+		if err != nil {
+			return Result{}, err
+		}
+		if value.Type == ValueTypeScalar {
+			return scalarResult(ts, -value.Scalar.V), nil
+		}
+		vector := make(Vector, len(value.Vector))
+		for i, sample := range value.Vector {
+			vector[i] = Sample{Metric: sample.Metric.withoutName(), Point: Point{T: ts, V: -sample.V}}
+		}
+		return vectorResult(vector), nil
+	default:
+		return Result{}, &EvalError{Msg: fmt.Sprintf("unsupported expression %T", expr)}
+	}
+}
+
+// evalSelector возвращает последнее значение каждой серии в окне lookback
+// или все точки окна Range для селектора диапазона
+func (ev *evaluator) evalSelector(selector *VectorSelector, ts time.Time) Result {
+	end := ts.Add(-selector.Offset)
+
+	if selector.Range > 0 {
+		start := end.Add(-selector.Range)
+		var matrix Matrix
+		for _, s := range ev.series[selector] {
+			points := pointsInWindow(s.Points, start, end)
+			if len(points) > 0 {
+				matrix = append(matrix, Series{Metric: s.Metric, Points: points})
+			}
+		}
+		return Result{Type: ValueTypeMatrix, Matrix: matrix}
+	}
+
+	start := end.Add(-ev.lookback)
+	var vector Vector
+	for _, s := range ev.series[selector] {
+		points := pointsInWindow(s.Points, start, end)
+		if len(points) > 0 {
+			vector = append(vector, Sample{Metric: s.Metric, Point: Point{T: ts, V: points[len(points)-1].V}})
+		}
+	}
+	return vectorResult(vector)
+}
+
+// pointsInWindow возвращает точки с временем в (start, end]; points отсортированы по времени
+func pointsInWindow(points []Point, start, end time.Time) []Point {
+	from := sort.Search(len(points), func(i int) bool { return points[i].T.After(start) })
+	to := sort.Search(len(points), func(i int) bool { return points[i].T.After(end) })
+	if from >= to {
+		return nil
+	}
+	return points[from:to]
+}
+
+func (ev *evaluator) evalCall(call *Call, ts time.Time) (Result, error) {
+	var scalars []float64
+	var input Result
+	for _, arg := range call.Args {
+		value, err := ev.eval(arg, ts)
+		if err != nil {
+			return Result{}, err
+		}
+		if value.Type == ValueTypeScalar {
+			scalars = append(scalars, value.Scalar.V)
+			continue
+		}
+		input = value
+	}
+
+	var vector Vector
+	if call.Func.overRange != nil {
+		for _, s := range input.Matrix {
+			v, ok := call.Func.overRange(s.Points, scalars)
+			if !ok {
+				continue
+			}
+			metric := s.Metric
+			if call.Func.Name != "last_over_time" {
+				metric = metric.withoutName()
+			}
+			vector = append(vector, Sample{Metric: metric, Point: Point{T: ts, V: v}})
+		}
+		return vectorResult(vector), nil
+	}
+
+	for _, sample := range input.Vector {
+		vector = append(vector, Sample{
+			Metric: sample.Metric.withoutName(),
+			Point:  Point{T: ts, V: call.Func.instant(sample.V)},
+		})
+	}
+	return vectorResult(vector), nil
+}
+
+func (ev *evaluator) evalAggregate(agg *AggregateExpr, ts time.Time) (Result, error) {
+	var param float64
+	if agg.Param != nil {
+		value, err := ev.eval(agg.Param, ts)
+		if err != nil {
+			return Result{}, err
+		}
+		param = value.Scalar.V
+	}
+
+	input, err := ev.eval(agg.Expr, ts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	type group struct {
+		metric Labels
+		values []float64
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, sample := range input.Vector {
+		var metric Labels
+		if agg.Without {
+			metric = sample.Metric.copyWithout(append([]string{valueobject.NameLabel}, agg.Grouping...)...)
+		} else {
+			metric = sample.Metric.only(agg.Grouping)
+		}
+
+		key := metric.String()
+		g, ok := groups[key]
+		if !ok {
+			g = &group{metric: metric}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.values = append(g.values, sample.V)
+	}
+
+	vector := make(Vector, 0, len(groups))
+	for _, key := range order {
+		g := groups[key]
+		vector = append(vector, Sample{Metric: g.metric, Point: Point{T: ts, V: aggregate(agg.Op, param, g.values)}})
+	}
+	return vectorResult(vector), nil
+}
+
+// aggregate применяет оператор агрегации к значениям группы
+func aggregate(op string, param float64, values []float64) float64 {
+	switch op {
+	case "sum":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum
+	case "avg":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values))
+	case "min":
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Min(result, v)
+		}
+		return result
+	case "max":
+		result := values[0]
+		for _, v := range values[1:] {
+			result = math.Max(result, v)
+		}
+		return result
+	case "count":
+		return float64(len(values))
+	case "stddev":
+		return stddev(values)
+	case "quantile":
+		return quantile(param, values)
+	default:
+		return math.NaN()
+	}
+}
+
+func (ev *evaluator) evalBinary(binary *BinaryExpr, ts time.Time) (Result, error) {
+	lhs, err := ev.eval(binary.LHS, ts)
+	if err != nil {
+		return Result{}, err
+	}
+	rhs, err := ev.eval(binary.RHS, ts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	comparison := isComparison(binary.Op)
+	dropName := !comparison || binary.ReturnBool
+
+	switch {
+	case lhs.Type == ValueTypeScalar && rhs.Type == ValueTypeScalar:
+		v, keep := applyOp(binary.Op, lhs.Scalar.V, rhs.Scalar.V)
+		if comparison {
+			v = boolValue(keep)
+		}
+		return scalarResult(ts, v), nil
+
+	case lhs.Type == ValueTypeVector && rhs.Type == ValueTypeVector:
+		return ev.evalVectorBinary(binary, lhs.Vector, rhs.Vector, ts, dropName)
+
+	default:
+		// Вектор и скаляр: операция применяется к каждому значению вектора
+		vector, scalar, scalarLeft := lhs.Vector, rhs.Scalar.V, false
+		if lhs.Type == ValueTypeScalar {
+			vector, scalar, scalarLeft = rhs.Vector, lhs.Scalar.V, true
+		}
+
+		var result Vector
+		for _, sample := range vector {
+			l, r := sample.V, scalar
+			if scalarLeft {
+				l, r = r, l
+			}
+			v, keep := applyOp(binary.Op, l, r)
+			if comparison {
+				if binary.ReturnBool {
+					v = boolValue(keep)
+				} else if !keep {
+					continue
+				} else {
+					// Фильтр сохраняет значение вектора, даже если скаляр слева
+					v = sample.V
+				}
+			}
+
+			metric := sample.Metric
+			if dropName {
+				metric = metric.withoutName()
+			}
+			result = append(result, Sample{Metric: metric, Point: Point{T: ts, V: v}})
+		}
+		return vectorResult(result), nil
+	}
+}
+
+// evalVectorBinary сопоставляет серии двух векторов один к одному по сигнатуре меток
+func (ev *evaluator) evalVectorBinary(binary *BinaryExpr, lhs, rhs Vector, ts time.Time, dropName bool) (Result, error) {
+	signature := func(metric Labels) Labels {
+		switch {
+		case binary.Matching == nil:
+			return metric.withoutName()
+		case binary.Matching.On:
+			return metric.only(binary.Matching.Labels)
+		default:
+			return metric.copyWithout(append([]string{valueobject.NameLabel}, binary.Matching.Labels...)...)
+		}
+	}
+
+	right := make(map[string]Sample, len(rhs))
+	for _, sample := range rhs {
+		key := signature(sample.Metric).String()
+		if _, ok := right[key]; ok {
+			return Result{}, &EvalError{Msg: fmt.Sprintf("found duplicate series for the match group %s on the right hand-side of the operation", key)}
+		}
+		right[key] = sample
+	}
+
+	matched := make(map[string]struct{}, len(lhs))
+	var result Vector
+	for _, sample := range lhs {
+		key := signature(sample.Metric).String()
+		other, ok := right[key]
+		if !ok {
+			continue
+		}
+		if _, ok := matched[key]; ok {
+			return Result{}, &EvalError{Msg: fmt.Sprintf("found duplicate series for the match group %s on the left hand-side of the operation", key)}
+		}
+		matched[key] = struct{}{}
+
+		v, keep := applyOp(binary.Op, sample.V, other.V)
+		if isComparison(binary.Op) {
+			if binary.ReturnBool {
+				v = boolValue(keep)
+			} else if !keep {
+				continue
+			}
+		}
+
+		metric := sample.Metric
+		if binary.Matching != nil {
+			if binary.Matching.On {
+				metric = metric.only(binary.Matching.Labels)
+			} else {
+				metric = metric.copyWithout(binary.Matching.Labels...)
+			}
+		}
+		if dropName {
+			metric = metric.withoutName()
+		}
+		result = append(result, Sample{Metric: metric, Point: Point{T: ts, V: v}})
+	}
+
+	return vectorResult(result), nil
+}
+
+// applyOp применяет бинарный оператор; для сравнений второй результат - истинность условия
+func applyOp(op string, l, r float64) (float64, bool) {
+	switch op {
+	case "+":
+		return l + r, true
+	case "-":
+		return l - r, true
+	case "*":
+		return l * r, true
+	case "/":
+		return l / r, true
+	case "%":
+		return math.Mod(l, r), true
+	case "^":
+		return math.Pow(l, r), true
+	case "==":
+		return l, l == r
+	case "!=":
+		return l, l != r
+	case ">":
+		return l, l > r
+	case "<":
+		return l, l < r
+	case ">=":
+		return l, l >= r
+	case "<=":
+		return l, l <= r
+	default:
+		return math.NaN(), false
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func scalarResult(ts time.Time, v float64) Result {
+	return Result{Type: ValueTypeScalar, Scalar: Point{T: ts, V: v}}
+}
+
+func vectorResult(vector Vector) Result {
+	return Result{Type: ValueTypeVector, Vector: vector}
+}
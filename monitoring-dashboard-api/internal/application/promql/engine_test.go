@@ -0,0 +1,246 @@
+package promql
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// engineMockRepository отдает метрики по имени, меткам и окну времени
+type engineMockRepository struct {
+	repository.MetricRepository
+	metrics []*entity.Metric
+	queries []repository.MetricQuery
+}
+
+func (m *engineMockRepository) FindByLabels(_ context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	m.queries = append(m.queries, query)
+	var result []*entity.Metric
+	for _, metric := range m.metrics {
+		if query.Name != "" && metric.Name() != query.Name {
+			continue
+		}
+		if query.TimeRange.Contains(metric.CollectedAt()) && metric.MatchesSelector(query.Selector) {
+			result = append(result, metric)
+		}
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func engineTestMetric(t *testing.T, name, host string, labels map[string]string, value float64, at time.Time) *entity.Metric {
+	t.Helper()
+	metricValue, err := valueobject.NewMetricValue(value, "%")
+	if err != nil {
+		t.Fatalf("NewMetricValue() error = %v", err)
+	}
+	metricLabels, err := valueobject.NewLabels(labels)
+	if err != nil {
+		t.Fatalf("NewLabels() error = %v", err)
+	}
+	return entity.Reconstruct(name+host+at.String(), valueobject.CPU, name, host, metricLabels, metricValue, nil, at, at)
+}
+
+// newTestEngine создает метрики cpu_usage (2 хоста x 2 ядра) и счетчик requests_total
+// с точкой каждые 15 секунд за последние 10 минут до now
+func newTestEngine(t *testing.T, now time.Time) (*Engine, *engineMockRepository) {
+	t.Helper()
+	repo := &engineMockRepository{}
+	for i := 0; i <= 40; i++ {
+		at := now.Add(-time.Duration(40-i) * 15 * time.Second)
+		for hostIndex, host := range []string{"web-1", "web-2"} {
+			for core, base := range []float64{10, 30} {
+				value := base + float64(hostIndex*40) + float64(i%2)
+				repo.metrics = append(repo.metrics, engineTestMetric(t, "cpu_usage", host,
+					map[string]string{"core": string(rune('0' + core))}, value, at))
+			}
+			// Счетчик растет на 3 в секунду, у web-2 сбрасывается в середине окна
+			counter := float64(i) * 45
+			if host == "web-2" && i >= 20 {
+				counter = float64(i-20) * 45
+			}
+			repo.metrics = append(repo.metrics, engineTestMetric(t, "requests_total", host, nil, counter, at))
+		}
+	}
+	return NewEngine(repo, EngineConfig{}), repo
+}
+
+func vectorByHost(t *testing.T, result Result) map[string]float64 {
+	t.Helper()
+	if result.Type != ValueTypeVector {
+		t.Fatalf("result type = %s, want vector", result.Type)
+	}
+	values := make(map[string]float64, len(result.Vector))
+	for _, sample := range result.Vector {
+		values[sample.Metric[valueobject.HostLabel]] = sample.V
+	}
+	return values
+}
+
+func TestEngineInstantSelectorReturnsLatestSample(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	engine, repo := newTestEngine(t, now)
+
+	result, err := engine.Instant(context.Background(), `cpu_usage{host="web-2", core="1"}`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if len(result.Vector) != 1 {
+		t.Fatalf("expected 1 sample, got %d", len(result.Vector))
+	}
+	sample := result.Vector[0]
+	if sample.V != 70 || sample.Metric[valueobject.NameLabel] != "cpu_usage" || sample.Metric["core"] != "1" {
+		t.Fatalf("unexpected sample: %+v", sample)
+	}
+	if !sample.T.Equal(now) {
+		t.Fatalf("sample time = %v, want evaluation time %v", sample.T, now)
+	}
+
+	query := repo.queries[0]
+	if query.Name != "cpu_usage" || !query.TimeRange.Start().Equal(now.Add(-defaultLookback)) || query.Limit != defaultMaxSamples+1 {
+		t.Fatalf("unexpected repository query: %+v", query)
+	}
+}
+
+func TestEngineRateHandlesCounterReset(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	engine, _ := newTestEngine(t, now)
+
+	result, err := engine.Instant(context.Background(), `rate(requests_total[5m])`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	values := vectorByHost(t, result)
+	if len(values) != 2 || values["web-1"] != 3 || values["web-2"] != 3 {
+		t.Fatalf("unexpected rates: %v", values)
+	}
+	for _, sample := range result.Vector {
+		if _, ok := sample.Metric[valueobject.NameLabel]; ok {
+			t.Fatalf("rate must drop the metric name: %v", sample.Metric)
+		}
+	}
+}
+
+func TestEngineAggregationByAndOverTime(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	engine, _ := newTestEngine(t, now)
+
+	// Последняя точка i=40 четная: web-1 = 10 и 30, web-2 = 50 и 70
+	result, err := engine.Instant(context.Background(), `avg by (host) (cpu_usage)`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if values := vectorByHost(t, result); values["web-1"] != 20 || values["web-2"] != 60 {
+		t.Fatalf("unexpected averages: %v", values)
+	}
+	if labels := result.Vector[0].Metric; len(labels) != 1 {
+		t.Fatalf("by (host) must keep only host: %v", labels)
+	}
+
+	result, err = engine.Instant(context.Background(), `max(max_over_time(cpu_usage{core="0"}[1m]))`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if len(result.Vector) != 1 || result.Vector[0].V != 51 {
+		t.Fatalf("unexpected max: %+v", result.Vector)
+	}
+
+	result, err = engine.Instant(context.Background(), `quantile_over_time(0.5, cpu_usage{host="web-1",core="0"}[2m15s])`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if len(result.Vector) != 1 || result.Vector[0].V != 10 {
+		t.Fatalf("unexpected median: %+v", result.Vector)
+	}
+}
+
+func TestEngineBinaryOperators(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	engine, _ := newTestEngine(t, now)
+
+	// Фильтр сохраняет имя метрики и исходное значение
+	result, err := engine.Instant(context.Background(), `cpu_usage > 40`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if len(result.Vector) != 2 || result.Vector[0].Metric[valueobject.NameLabel] != "cpu_usage" {
+		t.Fatalf("unexpected filtered vector: %+v", result.Vector)
+	}
+
+	// Векторы сопоставляются по host после агрегации
+	result, err = engine.Instant(context.Background(), `sum by (host) (cpu_usage) / on (host) sum by (host) (cpu_usage{core="0"}) * 100`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if values := vectorByHost(t, result); values["web-1"] != 400 || values["web-2"] != 240 {
+		t.Fatalf("unexpected ratios: %v", values)
+	}
+
+	result, err = engine.Instant(context.Background(), `2 ^ 3 - 1 > bool 6`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if result.Type != ValueTypeScalar || result.Scalar.V != 1 {
+		t.Fatalf("unexpected scalar: %+v", result)
+	}
+
+	result, err = engine.Instant(context.Background(), `1 / 0`, now)
+	if err != nil {
+		t.Fatalf("Instant() error = %v", err)
+	}
+	if !math.IsInf(result.Scalar.V, 1) {
+		t.Fatalf("1 / 0 = %v, want +Inf", result.Scalar.V)
+	}
+
+	// on (host) без агрегации дает две серии (по ядрам) на каждый хост
+	_, err = engine.Instant(context.Background(), `cpu_usage + on (host) cpu_usage`, now)
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) {
+		t.Fatalf("expected duplicate match error, got %v", err)
+	}
+}
+
+func TestEngineRangeQuery(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	engine, _ := newTestEngine(t, now)
+
+	result, err := engine.Range(context.Background(), `sum(cpu_usage{host="web-1"})`, now.Add(-2*time.Minute), now, time.Minute)
+	if err != nil {
+		t.Fatalf("Range() error = %v", err)
+	}
+	if result.Type != ValueTypeMatrix || len(result.Matrix) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	series := result.Matrix[0]
+	if len(series.Points) != 3 || len(series.Metric) != 0 {
+		t.Fatalf("unexpected series: %+v", series)
+	}
+	for i, point := range series.Points {
+		if want := now.Add(time.Duration(i-2) * time.Minute); !point.T.Equal(want) || point.V != 40 {
+			t.Fatalf("point %d = %+v, want 40 at %v", i, point, want)
+		}
+	}
+
+	if _, err := engine.Range(context.Background(), `cpu_usage[5m]`, now.Add(-time.Minute), now, time.Minute); err == nil {
+		t.Fatal("range query over a range vector must fail")
+	}
+}
+
+func TestEngineRejectsTooManySamples(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	_, repo := newTestEngine(t, now)
+	engine := NewEngine(repo, EngineConfig{MaxSamples: 10})
+
+	_, err := engine.Instant(context.Background(), `rate(requests_total[10m])`, now)
+	if !errors.Is(err, ErrTooManySamples) {
+		t.Fatalf("expected ErrTooManySamples, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package promql
+
+import (
+	"math"
+	"sort"
+)
+
+// Function описание функции языка запросов
+type Function struct {
+	Name       string
+	ArgTypes   []ValueType
+	ReturnType ValueType
+
+	// overRange считает значение по точкам окна селектора диапазона; args - скалярные аргументы
+	overRange func(points []Point, args []float64) (float64, bool)
+
+	// instant применяется к каждому значению вектора
+	instant func(value float64) float64
+}
+
+// functions поддерживаемые функции
+var functions = map[string]*Function{
+	"rate":               rangeFunction("rate", rate),
+	"increase":           rangeFunction("increase", increase),
+	"delta":              rangeFunction("delta", delta),
+	"avg_over_time":      rangeFunction("avg_over_time", avgOverTime),
+	"min_over_time":      rangeFunction("min_over_time", minOverTime),
+	"max_over_time":      rangeFunction("max_over_time", maxOverTime),
+	"sum_over_time":      rangeFunction("sum_over_time", sumOverTime),
+	"count_over_time":    rangeFunction("count_over_time", countOverTime),
+	"last_over_time":     rangeFunction("last_over_time", lastOverTime),
+	"quantile_over_time": {Name: "quantile_over_time", ArgTypes: []ValueType{ValueTypeScalar, ValueTypeMatrix}, ReturnType: ValueTypeVector, overRange: quantileOverTime},
+	"abs":                instantFunction("abs", math.Abs),
+	"ceil":               instantFunction("ceil", math.Ceil),
+	"floor":              instantFunction("floor", math.Floor),
+	"round":              instantFunction("round", math.Round),
+	"sqrt":               instantFunction("sqrt", math.Sqrt),
+}
+
+// aggregations поддерживаемые операторы агрегации; true - оператор принимает параметр
+var aggregations = map[string]bool{
+	"sum":      false,
+	"avg":      false,
+	"min":      false,
+	"max":      false,
+	"count":    false,
+	"stddev":   false,
+	"quantile": true,
+}
+
+func rangeFunction(name string, fn func(points []Point) (float64, bool)) *Function {
+	return &Function{
+		Name:       name,
+		ArgTypes:   []ValueType{ValueTypeMatrix},
+		ReturnType: ValueTypeVector,
+		overRange: func(points []Point, _ []float64) (float64, bool) {
+			return fn(points)
+		},
+	}
+}
+
+func instantFunction(name string, fn func(float64) float64) *Function {
+	return &Function{
+		Name:       name,
+		ArgTypes:   []ValueType{ValueTypeVector},
+		ReturnType: ValueTypeVector,
+		instant:    fn,
+	}
+}
+
+// increase прирост счетчика за окно; уменьшение значения считается сбросом счетчика
+func increase(points []Point) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	var result float64
+	for i := 1; i < len(points); i++ {
+		if points[i].V < points[i-1].V {
+			result += points[i].V
+			continue
+		}
+		result += points[i].V - points[i-1].V
+	}
+	return result, true
+}
+
+// rate средний прирост счетчика в секунду между первой и последней точкой окна
+func rate(points []Point) (float64, bool) {
+	inc, ok := increase(points)
+	if !ok {
+		return 0, false
+	}
+	seconds := points[len(points)-1].T.Sub(points[0].T).Seconds()
+	if seconds <= 0 {
+		return 0, false
+	}
+	return inc / seconds, true
+}
+
+// delta разница между последней и первой точкой окна (для gauge)
+func delta(points []Point) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	return points[len(points)-1].V - points[0].V, true
+}
+
+func avgOverTime(points []Point) (float64, bool) {
+	sum, ok := sumOverTime(points)
+	if !ok {
+		return 0, false
+	}
+	return sum / float64(len(points)), true
+}
+
+func minOverTime(points []Point) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	result := points[0].V
+	for _, p := range points[1:] {
+		result = math.Min(result, p.V)
+	}
+	return result, true
+}
+
+func maxOverTime(points []Point) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	result := points[0].V
+	for _, p := range points[1:] {
+		result = math.Max(result, p.V)
+	}
+	return result, true
+}
+
+func sumOverTime(points []Point) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, p := range points {
+		sum += p.V
+	}
+	return sum, true
+}
+
+func countOverTime(points []Point) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	return float64(len(points)), true
+}
+
+func lastOverTime(points []Point) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	return points[len(points)-1].V, true
+}
+
+func quantileOverTime(points []Point, args []float64) (float64, bool) {
+	if len(points) == 0 {
+		return 0, false
+	}
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.V
+	}
+	return quantile(args[0], values), true
+}
+
+// quantile вычисляет φ-квантиль с линейной интерполяцией между соседними значениями
+func quantile(q float64, values []float64) float64 {
+	switch {
+	case len(values) == 0 || math.IsNaN(q):
+		return math.NaN()
+	case q < 0:
+		return math.Inf(-1)
+	case q > 1:
+		return math.Inf(1)
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := q * float64(len(sorted)-1)
+	lower := math.Floor(rank)
+	upper := math.Ceil(rank)
+	weight := rank - lower
+	return sorted[int(lower)]*(1-weight) + sorted[int(upper)]*weight
+}
+
+// stddev стандартное отклонение генеральной совокупности
+func stddev(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
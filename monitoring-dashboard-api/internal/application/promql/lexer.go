@@ -0,0 +1,221 @@
+package promql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind вид лексемы
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokDuration
+	tokString
+	tokLeftParen
+	tokRightParen
+	tokLeftBrace
+	tokRightBrace
+	tokLeftBracket
+	tokRightBracket
+	tokComma
+	tokAssign    // =
+	tokRegexp    // =~
+	tokNotRegexp // !~
+	tokOperator  // + - * / % ^ == != > < >= <=
+)
+
+// token лексема с позицией в исходной строке
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexer разбивает выражение на лексемы
+type lexer struct {
+	input  string
+	pos    int
+	tokens []token
+}
+
+// lex возвращает все лексемы выражения, последняя - tokEOF
+func lex(input string) ([]token, error) {
+	l := &lexer{input: input}
+	for {
+		l.skipSpaces()
+		if l.pos >= len(l.input) {
+			l.tokens = append(l.tokens, token{kind: tokEOF, pos: l.pos})
+			return l.tokens, nil
+		}
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (l *lexer) skipSpaces() {
+	for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+		l.pos++
+	}
+}
+
+func (l *lexer) emit(kind tokenKind, start int) {
+	l.tokens = append(l.tokens, token{kind: kind, text: l.input[start:l.pos], pos: start})
+}
+
+func (l *lexer) next() error {
+	start := l.pos
+	c := l.input[l.pos]
+
+	switch {
+	case isIdentStart(c):
+		for l.pos < len(l.input) && isIdentChar(l.input[l.pos]) {
+			l.pos++
+		}
+		l.emit(tokIdent, start)
+		return nil
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.input) && isDigit(l.input[l.pos+1])):
+		return l.lexNumber()
+	case c == '"' || c == '\'' || c == '`':
+		return l.lexString()
+	}
+
+	single := map[byte]tokenKind{
+		'(': tokLeftParen, ')': tokRightParen,
+		'{': tokLeftBrace, '}': tokRightBrace,
+		'[': tokLeftBracket, ']': tokRightBracket,
+		',': tokComma,
+	}
+	if kind, ok := single[c]; ok {
+		l.pos++
+		l.emit(kind, start)
+		return nil
+	}
+
+	two := ""
+	if l.pos+1 < len(l.input) {
+		two = l.input[l.pos : l.pos+2]
+	}
+	switch two {
+	case "=~":
+		l.pos += 2
+		l.emit(tokRegexp, start)
+		return nil
+	case "!~":
+		l.pos += 2
+		l.emit(tokNotRegexp, start)
+		return nil
+	case "==", "!=", ">=", "<=":
+		l.pos += 2
+		l.emit(tokOperator, start)
+		return nil
+	}
+
+	switch c {
+	case '=':
+		l.pos++
+		l.emit(tokAssign, start)
+		return nil
+	case '+', '-', '*', '/', '%', '^', '>', '<':
+		l.pos++
+		l.emit(tokOperator, start)
+		return nil
+	}
+
+	return &ParseError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", c)}
+}
+
+// lexNumber разбирает число или длительность (5m, 1h30m)
+func (l *lexer) lexNumber() error {
+	start := l.pos
+	for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+		l.pos++
+	}
+
+	// Экспонента: 1e3, 2.5E-2
+	if l.pos+1 < len(l.input) && (l.input[l.pos] == 'e' || l.input[l.pos] == 'E') {
+		next := l.pos + 1
+		if next < len(l.input) && (l.input[next] == '+' || l.input[next] == '-') {
+			next++
+		}
+		if next < len(l.input) && isDigit(l.input[next]) {
+			l.pos = next
+			for l.pos < len(l.input) && isDigit(l.input[l.pos]) {
+				l.pos++
+			}
+			l.emit(tokNumber, start)
+			return nil
+		}
+	}
+
+	// Число, за которым сразу идет единица измерения, - длительность
+	if l.pos < len(l.input) && isDurationUnit(l.input[l.pos]) {
+		for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || isDurationUnit(l.input[l.pos])) {
+			l.pos++
+		}
+		l.emit(tokDuration, start)
+		return nil
+	}
+
+	l.emit(tokNumber, start)
+	return nil
+}
+
+// lexString разбирает строку в двойных, одинарных или обратных кавычках
+func (l *lexer) lexString() error {
+	start := l.pos
+	quote := l.input[l.pos]
+	l.pos++
+
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if c == '\\' && quote != '`' {
+			l.pos += 2
+			continue
+		}
+		l.pos++
+		if c == quote {
+			raw := l.input[start:l.pos]
+			value, err := unquote(raw)
+			if err != nil {
+				return &ParseError{Pos: start, Msg: fmt.Sprintf("invalid string %s", raw)}
+			}
+			l.tokens = append(l.tokens, token{kind: tokString, text: value, pos: start})
+			return nil
+		}
+	}
+
+	return &ParseError{Pos: start, Msg: "unterminated string"}
+}
+
+// unquote снимает кавычки; одинарные кавычки трактуются как двойные
+func unquote(raw string) (string, error) {
+	if raw[0] == '\'' {
+		inner := raw[1 : len(raw)-1]
+		inner = strings.ReplaceAll(inner, `\'`, `'`)
+		inner = strings.ReplaceAll(inner, `"`, `\"`)
+		raw = `"` + inner + `"`
+	}
+	return strconv.Unquote(raw)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isDurationUnit(c byte) bool {
+	return strings.IndexByte("smhdwy", c) >= 0
+}
@@ -0,0 +1,534 @@
+package promql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// binaryPrecedence приоритет бинарных операторов (больше - связывает сильнее)
+var binaryPrecedence = map[string]int{
+	"==": 1, "!=": 1, ">": 1, "<": 1, ">=": 1, "<=": 1,
+	"+": 2, "-": 2,
+	"*": 3, "/": 3, "%": 3,
+	"^": 4,
+}
+
+// isComparison проверяет, является ли оператор сравнением
+func isComparison(op string) bool {
+	return binaryPrecedence[op] == 1
+}
+
+// parser разбирает выражение рекурсивным спуском
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse разбирает выражение языка запросов
+//
+// Поддерживаются: селекторы name{label="v",l=~"re"}[5m] offset 1h, функции над диапазоном
+// (rate, increase, delta, *_over_time), математические функции (abs, ceil, floor, round, sqrt),
+// агрегации sum/avg/min/max/count/stddev/quantile с by/without и бинарные операторы
+// + - * / % ^ == != > < >= <= с модификаторами bool и on/ignoring
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, &ParseError{Pos: 0, Msg: "empty expression"}
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+
+	return expr, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	tok := p.peek()
+	if tok.kind != kind {
+		return tok, p.errorf(tok, "expected %s, got %q", what, tok.text)
+	}
+	return p.advance(), nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return &ParseError{Pos: tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseExpr разбирает бинарные выражения с приоритетом не ниже minPrecedence
+func (p *parser) parseExpr(minPrecedence int) (Expr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		precedence, ok := binaryPrecedence[tok.text]
+		if tok.kind != tokOperator || !ok || precedence < minPrecedence {
+			return lhs, nil
+		}
+		p.advance()
+
+		binary := &BinaryExpr{Op: tok.text, LHS: lhs}
+		if isComparison(tok.text) && p.peek().kind == tokIdent && p.peek().text == "bool" {
+			p.advance()
+			binary.ReturnBool = true
+		}
+		if binary.Matching, err = p.parseVectorMatching(); err != nil {
+			return nil, err
+		}
+
+		// ^ правоассоциативен, остальные операторы - левоассоциативны
+		next := precedence + 1
+		if tok.text == "^" {
+			next = precedence
+		}
+		if binary.RHS, err = p.parseExpr(next); err != nil {
+			return nil, err
+		}
+
+		if err := p.checkBinary(tok, binary); err != nil {
+			return nil, err
+		}
+		lhs = binary
+	}
+}
+
+// parseVectorMatching разбирает необязательный on(...) или ignoring(...)
+func (p *parser) parseVectorMatching() (*VectorMatching, error) {
+	tok := p.peek()
+	if tok.kind != tokIdent || (tok.text != "on" && tok.text != "ignoring") {
+		return nil, nil
+	}
+	p.advance()
+
+	labels, err := p.parseLabelList()
+	if err != nil {
+		return nil, err
+	}
+	return &VectorMatching{On: tok.text == "on", Labels: labels}, nil
+}
+
+// checkBinary проверяет типы операндов бинарного выражения
+func (p *parser) checkBinary(tok token, binary *BinaryExpr) error {
+	lhsType, rhsType := binary.LHS.Type(), binary.RHS.Type()
+	if lhsType == ValueTypeMatrix || rhsType == ValueTypeMatrix {
+		return p.errorf(tok, "operator %q is not defined for range vectors", binary.Op)
+	}
+	if lhsType == ValueTypeScalar && rhsType == ValueTypeScalar {
+		if isComparison(binary.Op) && !binary.ReturnBool {
+			return p.errorf(tok, "comparisons between scalars must use the bool modifier")
+		}
+		if binary.Matching != nil {
+			return p.errorf(tok, "vector matching is only allowed between vectors")
+		}
+	}
+	if binary.ReturnBool && !isComparison(binary.Op) {
+		return p.errorf(tok, "bool modifier is only allowed on comparisons")
+	}
+	return nil
+}
+
+// parseUnary разбирает унарные + и -
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.peek()
+	if tok.kind == tokOperator && (tok.text == "-" || tok.text == "+") {
+		p.advance()
+		// Унарный минус связывает слабее ^: -2^2 = -4
+		expr, err := p.parseExpr(binaryPrecedence["^"])
+		if err != nil {
+			return nil, err
+		}
+		if expr.Type() == ValueTypeMatrix {
+			return nil, p.errorf(tok, "unary %q is not defined for range vectors", tok.text)
+		}
+		if tok.text == "+" {
+			return expr, nil
+		}
+		if number, ok := expr.(*NumberLiteral); ok {
+			return &NumberLiteral{Value: -number.Value}, nil
+		}
+		return &UnaryExpr{Op: "-", Expr: expr}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+// parsePostfix разбирает первичное выражение с необязательными [range] и offset
+func (p *parser) parsePostfix() (Expr, error) {
+	expr, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind == tokLeftBracket {
+		tok := p.advance()
+		selector, ok := expr.(*VectorSelector)
+		if !ok || selector.Range > 0 {
+			return nil, p.errorf(tok, "range can only be applied to a vector selector")
+		}
+		durationTok, err := p.expect(tokDuration, "range duration")
+		if err != nil {
+			return nil, err
+		}
+		if selector.Range, err = parseDuration(durationTok); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRightBracket, "]"); err != nil {
+			return nil, err
+		}
+	}
+
+	if tok := p.peek(); tok.kind == tokIdent && tok.text == "offset" {
+		p.advance()
+		selector, ok := expr.(*VectorSelector)
+		if !ok {
+			return nil, p.errorf(tok, "offset can only be applied to a selector")
+		}
+		durationTok, err := p.expect(tokDuration, "offset duration")
+		if err != nil {
+			return nil, err
+		}
+		if selector.Offset, err = parseDuration(durationTok); err != nil {
+			return nil, err
+		}
+	}
+
+	return expr, nil
+}
+
+// parsePrimary разбирает число, скобки, агрегацию, вызов функции или селектор
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.peek()
+
+	switch tok.kind {
+	case tokNumber:
+		p.advance()
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+		return &NumberLiteral{Value: value}, nil
+	case tokLeftParen:
+		p.advance()
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return &ParenExpr{Expr: expr}, nil
+	case tokLeftBrace:
+		return p.parseSelector("")
+	case tokIdent:
+		switch strings.ToLower(tok.text) {
+		case "inf":
+			p.advance()
+			return &NumberLiteral{Value: math.Inf(1)}, nil
+		case "nan":
+			p.advance()
+			return &NumberLiteral{Value: math.NaN()}, nil
+		}
+
+		next := p.peekAt(1)
+		if _, ok := aggregations[tok.text]; ok && (next.kind == tokLeftParen || next.text == "by" || next.text == "without") {
+			return p.parseAggregate()
+		}
+		if next.kind == tokLeftParen {
+			return p.parseCall()
+		}
+		p.advance()
+		return p.parseSelector(tok.text)
+	case tokEOF:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	default:
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+}
+
+// parseCall разбирает вызов функции и проверяет типы аргументов
+func (p *parser) parseCall() (Expr, error) {
+	nameTok := p.advance()
+	fn, ok := functions[nameTok.text]
+	if !ok {
+		return nil, p.errorf(nameTok, "unknown function %q", nameTok.text)
+	}
+	p.advance() // (
+
+	var args []Expr
+	for p.peek().kind != tokRightParen {
+		if len(args) > 0 {
+			if _, err := p.expect(tokComma, ","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.advance() // )
+
+	if len(args) != len(fn.ArgTypes) {
+		return nil, p.errorf(nameTok, "%s expects %d argument(s), got %d", fn.Name, len(fn.ArgTypes), len(args))
+	}
+	for i, arg := range args {
+		if arg.Type() != fn.ArgTypes[i] {
+			return nil, p.errorf(nameTok, "argument %d of %s must be %s, got %s", i+1, fn.Name, fn.ArgTypes[i], arg.Type())
+		}
+	}
+
+	return &Call{Func: fn, Args: args}, nil
+}
+
+// parseAggregate разбирает агрегацию; by/without допускается до или после аргументов
+func (p *parser) parseAggregate() (Expr, error) {
+	opTok := p.advance()
+	agg := &AggregateExpr{Op: opTok.text}
+
+	grouped := false
+	if tok := p.peek(); tok.text == "by" || tok.text == "without" {
+		if err := p.parseGrouping(agg); err != nil {
+			return nil, err
+		}
+		grouped = true
+	}
+
+	if _, err := p.expect(tokLeftParen, "("); err != nil {
+		return nil, err
+	}
+	if aggregations[agg.Op] {
+		param, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if param.Type() != ValueTypeScalar {
+			return nil, p.errorf(opTok, "parameter of %s must be a scalar", agg.Op)
+		}
+		agg.Param = param
+		if _, err := p.expect(tokComma, ","); err != nil {
+			return nil, err
+		}
+	}
+	expr, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if expr.Type() != ValueTypeVector {
+		return nil, p.errorf(opTok, "%s expects an instant vector, got %s", agg.Op, expr.Type())
+	}
+	agg.Expr = expr
+	if _, err := p.expect(tokRightParen, ")"); err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); !grouped && (tok.text == "by" || tok.text == "without") {
+		if err := p.parseGrouping(agg); err != nil {
+			return nil, err
+		}
+	}
+
+	return agg, nil
+}
+
+// parseGrouping разбирает by (...) или without (...)
+func (p *parser) parseGrouping(agg *AggregateExpr) error {
+	tok := p.advance()
+	agg.Without = tok.text == "without"
+
+	labels, err := p.parseLabelList()
+	if err != nil {
+		return err
+	}
+	agg.Grouping = labels
+	return nil
+}
+
+// parseLabelList разбирает список меток в скобках: (host, mount)
+func (p *parser) parseLabelList() ([]string, error) {
+	if _, err := p.expect(tokLeftParen, "("); err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	for p.peek().kind != tokRightParen {
+		if len(labels) > 0 {
+			if _, err := p.expect(tokComma, ","); err != nil {
+				return nil, err
+			}
+		}
+		tok, err := p.expect(tokIdent, "label name")
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, tok.text)
+	}
+	p.advance() // )
+
+	return labels, nil
+}
+
+// parseSelector разбирает необязательные условия на метки после имени метрики
+func (p *parser) parseSelector(name string) (Expr, error) {
+	selector := &VectorSelector{Name: name}
+	start := p.peek()
+
+	if start.kind == tokLeftBrace {
+		p.advance()
+		for p.peek().kind != tokRightBrace {
+			if len(selector.Selector) > 0 {
+				if _, err := p.expect(tokComma, ","); err != nil {
+					return nil, err
+				}
+				// Допускается запятая перед закрывающей скобкой
+				if p.peek().kind == tokRightBrace {
+					break
+				}
+			}
+			matcher, err := p.parseMatcher()
+			if err != nil {
+				return nil, err
+			}
+			selector.Selector = append(selector.Selector, matcher)
+		}
+		p.advance() // }
+	}
+
+	// Имя может быть задано условием __name__="..."
+	if selector.Name == "" {
+		for i, matcher := range selector.Selector {
+			if matcher.Name() == valueobject.NameLabel && matcher.Type() == valueobject.MatchEqual {
+				selector.Name = matcher.Value()
+				selector.Selector = append(selector.Selector[:i:i], selector.Selector[i+1:]...)
+				break
+			}
+		}
+	}
+
+	if selector.Name == "" && !hasNonEmptyMatcher(selector.Selector) {
+		return nil, p.errorf(start, "selector must contain a metric name or a matcher that does not match the empty string")
+	}
+
+	return selector, nil
+}
+
+// parseMatcher разбирает одно условие label="value"
+func (p *parser) parseMatcher() (valueobject.LabelMatcher, error) {
+	nameTok, err := p.expect(tokIdent, "label name")
+	if err != nil {
+		return valueobject.LabelMatcher{}, err
+	}
+
+	opTok := p.advance()
+	var matchType valueobject.MatchType
+	switch {
+	case opTok.kind == tokAssign:
+		matchType = valueobject.MatchEqual
+	case opTok.kind == tokOperator && opTok.text == "!=":
+		matchType = valueobject.MatchNotEqual
+	case opTok.kind == tokRegexp:
+		matchType = valueobject.MatchRegexp
+	case opTok.kind == tokNotRegexp:
+		matchType = valueobject.MatchNotRegexp
+	default:
+		return valueobject.LabelMatcher{}, p.errorf(opTok, "expected label match operator, got %q", opTok.text)
+	}
+
+	valueTok, err := p.expect(tokString, "quoted label value")
+	if err != nil {
+		return valueobject.LabelMatcher{}, err
+	}
+
+	matcher, err := valueobject.NewLabelMatcher(nameTok.text, matchType, valueTok.text)
+	if err != nil {
+		return valueobject.LabelMatcher{}, p.errorf(nameTok, "%v", err)
+	}
+	return matcher, nil
+}
+
+// hasNonEmptyMatcher проверяет, что хотя бы одно условие не совпадает с пустой строкой
+// Иначе селектор выбрал бы все метрики хранилища
+func hasNonEmptyMatcher(selector valueobject.LabelSelector) bool {
+	for _, matcher := range selector {
+		if !matcher.Matches("") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDuration разбирает длительность с единицами ms, s, m, h, d, w, y (1h30m, 7d)
+func parseDuration(tok token) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+
+	var total time.Duration
+	rest := tok.text
+	for rest != "" {
+		i := 0
+		for i < len(rest) && isDigit(rest[i]) {
+			i++
+		}
+		j := i
+		for j < len(rest) && !isDigit(rest[j]) {
+			j++
+		}
+		unit, ok := units[rest[i:j]]
+		if i == 0 || !ok {
+			return 0, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("invalid duration %q", tok.text)}
+		}
+		value, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil || value > int64(math.MaxInt64/unit) {
+			return 0, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("invalid duration %q", tok.text)}
+		}
+		total += time.Duration(value) * unit
+		rest = rest[j:]
+	}
+
+	if total <= 0 {
+		return 0, &ParseError{Pos: tok.pos, Msg: "duration must be positive"}
+	}
+	return total, nil
+}
@@ -0,0 +1,124 @@
+package promql
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSelectorWithMatchersRangeAndOffset(t *testing.T) {
+	expr, err := Parse(`cpu_usage{host="web-1", core=~"0|1", mode!=''}[5m] offset 1h`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	selector, ok := expr.(*VectorSelector)
+	if !ok {
+		t.Fatalf("expected *VectorSelector, got %T", expr)
+	}
+	if selector.Name != "cpu_usage" || selector.Range != 5*time.Minute || selector.Offset != time.Hour {
+		t.Fatalf("unexpected selector: %+v", selector)
+	}
+	if got := selector.Selector.String(); got != `{host="web-1",core=~"0|1",mode!=""}` {
+		t.Fatalf("unexpected matchers: %s", got)
+	}
+	if expr.Type() != ValueTypeMatrix {
+		t.Fatalf("expected matrix, got %s", expr.Type())
+	}
+}
+
+func TestParseNameMatcherAndPrecedence(t *testing.T) {
+	expr, err := Parse(`{__name__="memory_usage"} - 2 * 3 ^ 2 ^ 0.5`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	sub, ok := expr.(*BinaryExpr)
+	if !ok || sub.Op != "-" {
+		t.Fatalf("expected top-level '-', got %#v", expr)
+	}
+	if selector := sub.LHS.(*VectorSelector); selector.Name != "memory_usage" || len(selector.Selector) != 0 {
+		t.Fatalf("__name__ matcher must become the selector name: %+v", selector)
+	}
+	mul := sub.RHS.(*BinaryExpr)
+	if mul.Op != "*" {
+		t.Fatalf("expected '*' under '-', got %q", mul.Op)
+	}
+	pow := mul.RHS.(*BinaryExpr)
+	if pow.Op != "^" || pow.RHS.(*BinaryExpr).Op != "^" {
+		t.Fatalf("'^' must be right-associative")
+	}
+}
+
+func TestParseAggregationAndModifiers(t *testing.T) {
+	expr, err := Parse(`quantile without (core) (0.9, rate(cpu_usage[5m])) > bool on (host) max by (host) (disk_usage)`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	cmp := expr.(*BinaryExpr)
+	if cmp.Op != ">" || !cmp.ReturnBool || cmp.Matching == nil || !cmp.Matching.On || cmp.Matching.Labels[0] != "host" {
+		t.Fatalf("unexpected comparison: %+v", cmp)
+	}
+	q := cmp.LHS.(*AggregateExpr)
+	if q.Op != "quantile" || !q.Without || q.Grouping[0] != "core" || q.Param.(*NumberLiteral).Value != 0.9 {
+		t.Fatalf("unexpected quantile: %+v", q)
+	}
+	if call := q.Expr.(*Call); call.Func.Name != "rate" {
+		t.Fatalf("unexpected call: %+v", call)
+	}
+	if max := cmp.RHS.(*AggregateExpr); max.Op != "max" || max.Without || max.Grouping[0] != "host" {
+		t.Fatalf("unexpected max: %+v", max)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":                  "",
+		"unknown function":       `foo(cpu_usage)`,
+		"wrong argument type":    `rate(cpu_usage)`,
+		"range on expression":    `(cpu_usage)[5m]`,
+		"matrix in binary":       `cpu_usage[5m] + 1`,
+		"scalar comparison":      `1 > 2`,
+		"empty selector":         `{host=~".*"}`,
+		"invalid regexp":         `cpu_usage{host=~"("}`,
+		"unterminated string":    `cpu_usage{host="web}`,
+		"missing paren":          `sum(cpu_usage`,
+		"bool on arithmetic":     `cpu_usage + bool 1`,
+		"trailing tokens":        `cpu_usage cpu_usage`,
+		"quantile without param": `quantile(cpu_usage)`,
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(input)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q) error = %v, want *ParseError", input, err)
+			}
+		})
+	}
+}
+
+func TestParseDurationUnits(t *testing.T) {
+	expr, err := Parse(`avg_over_time(cpu_usage[1h30m])`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	selector := expr.(*Call).Args[0].(*VectorSelector)
+	if selector.Range != 90*time.Minute {
+		t.Fatalf("Range = %v, want 1h30m", selector.Range)
+	}
+
+	expr, err = Parse(`cpu_usage offset 1w`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if offset := expr.(*VectorSelector).Offset; offset != 7*24*time.Hour {
+		t.Fatalf("Offset = %v, want 1w", offset)
+	}
+
+	if _, err := Parse(`-cpu_usage{host="a"} * -1`); err != nil {
+		t.Fatalf("Parse() unary error = %v", err)
+	}
+}
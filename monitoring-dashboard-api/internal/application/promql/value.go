@@ -0,0 +1,109 @@
+package promql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// Labels метки серии результата, включая __name__ и host
+type Labels map[string]string
+
+// String возвращает каноническое представление: {a="1",b="2"}
+func (l Labels) String() string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// copyWithout возвращает копию меток без перечисленных
+func (l Labels) copyWithout(names ...string) Labels {
+	result := make(Labels, len(l))
+	for name, value := range l {
+		result[name] = value
+	}
+	for _, name := range names {
+		delete(result, name)
+	}
+	return result
+}
+
+// withoutName возвращает копию меток без __name__
+func (l Labels) withoutName() Labels {
+	return l.copyWithout(valueobject.NameLabel)
+}
+
+// only возвращает копию, содержащую только перечисленные метки
+func (l Labels) only(names []string) Labels {
+	result := make(Labels, len(names))
+	for _, name := range names {
+		if value, ok := l[name]; ok && value != "" {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// Point значение серии в момент времени
+type Point struct {
+	T time.Time
+	V float64
+}
+
+// Sample значение одной серии в момент вычисления
+type Sample struct {
+	Metric Labels
+	Point
+}
+
+// Vector набор значений разных серий в один момент времени
+type Vector []Sample
+
+// Series точки одной серии
+type Series struct {
+	Metric Labels
+	Points []Point
+}
+
+// Matrix набор серий с точками
+type Matrix []Series
+
+// Result результат запроса; заполнено поле, соответствующее Type
+type Result struct {
+	Type   ValueType
+	Scalar Point
+	Vector Vector
+	Matrix Matrix
+}
+
+// sortMatrix упорядочивает серии по меткам для стабильного ответа
+func sortMatrix(matrix Matrix) {
+	sort.Slice(matrix, func(i, j int) bool {
+		return matrix[i].Metric.String() < matrix[j].Metric.String()
+	})
+}
+
+// sortVector упорядочивает значения по меткам для стабильного ответа
+func sortVector(vector Vector) {
+	sort.Slice(vector, func(i, j int) bool {
+		return vector[i].Metric.String() < vector[j].Metric.String()
+	})
+}
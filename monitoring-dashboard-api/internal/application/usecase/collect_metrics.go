@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
@@ -13,25 +15,35 @@ import (
 	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
 )
 
+// ErrInvalidHost идентификатор хоста пакета метрик не прошел валидацию
+var ErrInvalidHost = errors.New("invalid host")
+
 // CollectMetricsUseCase координирует сбор, валидацию, сохранение и рассылку метрик
 type CollectMetricsUseCase struct {
 	collector        port.MetricsCollector
 	repository       repository.MetricRepository
 	notifier         port.NotificationService
 	validator        *service.MetricValidator
-	metricsPublisher port.MetricsPublisher // Optional CloudWatch publisher
-	eventPublisher   port.EventPublisher   // Optional NATS event publisher
+	metricsPublisher port.MetricsPublisher      // Optional CloudWatch publisher
+	eventPublisher   port.EventPublisher        // Optional NATS event publisher
+	alertRules       *EvaluateAlertRulesUseCase // Optional alert rules evaluator
+	serviceMetrics   port.ServiceMetrics        // Optional /metrics instrumentation
+	localHost        string
 	logger           *logger.Logger
 }
 
 // NewCollectMetricsUseCase создает новый use case
+// localHost - идентификатор хоста, проставляемый локально собранным метрикам
 func NewCollectMetricsUseCase(
 	collector port.MetricsCollector,
 	repository repository.MetricRepository,
 	notifier port.NotificationService,
 	validator *service.MetricValidator,
 	metricsPublisher port.MetricsPublisher, // Can be nil if CloudWatch disabled
-	eventPublisher port.EventPublisher,     // Can be nil if NATS disabled
+	eventPublisher port.EventPublisher, // Can be nil if NATS disabled
+	alertRules *EvaluateAlertRulesUseCase, // Can be nil if alerting disabled
+	serviceMetrics port.ServiceMetrics, // Can be nil if /metrics disabled
+	localHost string,
 	logger *logger.Logger,
 ) *CollectMetricsUseCase {
 	return &CollectMetricsUseCase{
@@ -41,12 +53,20 @@ func NewCollectMetricsUseCase(
 		validator:        validator,
 		metricsPublisher: metricsPublisher,
 		eventPublisher:   eventPublisher,
+		alertRules:       alertRules,
+		serviceMetrics:   serviceMetrics,
+		localHost:        localHost,
 		logger:           logger,
 	}
 }
 
 // Execute выполняет сбор метрик
 func (uc *CollectMetricsUseCase) Execute(ctx context.Context) error {
+	if uc.serviceMetrics != nil {
+		startedAt := time.Now()
+		defer func() { uc.serviceMetrics.ObserveCollection(time.Since(startedAt)) }()
+	}
+
 	// 1. Собираем сырые метрики от collector
 	uc.logger.Debug("Collecting metrics from system")
 	rawMetrics, err := uc.collector.CollectAll(ctx)
@@ -57,15 +77,89 @@ func (uc *CollectMetricsUseCase) Execute(ctx context.Context) error {
 
 	uc.logger.Debug("Collected raw metrics", "count", len(rawMetrics))
 
+	for i := range rawMetrics {
+		if rawMetrics[i].Host == "" {
+			rawMetrics[i].Host = uc.localHost
+		}
+	}
+
+	_, err = uc.process(ctx, rawMetrics)
+	return err
+}
+
+// Ingest принимает пакет метрик, собранных удаленным агентом на хосте host,
+// и прогоняет его через тот же конвейер, что и локальный сбор
+func (uc *CollectMetricsUseCase) Ingest(
+	ctx context.Context,
+	host string,
+	rawMetrics []port.RawMetric,
+) (*dto.IngestMetricsResultDTO, error) {
+	if err := uc.validator.ValidateHost(host); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHost, err)
+	}
+
+	uc.logger.Debug("Ingesting remote metrics", "host", host, "count", len(rawMetrics))
+
+	for i := range rawMetrics {
+		rawMetrics[i].Host = host
+	}
+
+	accepted, err := uc.process(ctx, rawMetrics)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.IngestMetricsResultDTO{
+		Host:     host,
+		Received: len(rawMetrics),
+		Accepted: accepted,
+		Rejected: len(rawMetrics) - accepted,
+	}, nil
+}
+
+// IngestSeries принимает метрики нескольких хостов (например, из Prometheus remote_write)
+// Хост берется из каждой метрики и проверяется вместе с ней; метрики с некорректным хостом отклоняются
+func (uc *CollectMetricsUseCase) IngestSeries(
+	ctx context.Context,
+	rawMetrics []port.RawMetric,
+) (*dto.IngestMetricsResultDTO, error) {
+	uc.logger.Debug("Ingesting remote series", "count", len(rawMetrics))
+
+	accepted, err := uc.process(ctx, rawMetrics)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.IngestMetricsResultDTO{
+		Received: len(rawMetrics),
+		Accepted: accepted,
+		Rejected: len(rawMetrics) - accepted,
+	}, nil
+}
+
+// process валидирует, сохраняет и рассылает сырые метрики
+// Возвращает количество принятых метрик
+func (uc *CollectMetricsUseCase) process(ctx context.Context, rawMetrics []port.RawMetric) (int, error) {
 	// 2. Конвертируем в Domain Entities
 	metrics := make([]*entity.Metric, 0, len(rawMetrics))
 	for _, raw := range rawMetrics {
-		metric, err := entity.NewMetric(raw.Type, raw.Name, raw.Value)
+		metric, err := entity.NewMetricAt(raw.Type, raw.Name, raw.Value, raw.CollectedAt)
 		if err != nil {
 			uc.logger.Warn("Skipping invalid metric", "type", raw.Type, "name", raw.Name, "error", err.Error())
+			uc.metricDropped(port.DropReasonInvalidType)
 			continue
 		}
 
+		metric.SetHost(raw.Host)
+
+		labels, err := valueobject.NewLabels(raw.Labels)
+		if err != nil {
+			uc.logger.Warn("Skipping metric with invalid labels", "type", raw.Type, "name", raw.Name, "error", err.Error())
+			uc.metricDropped(port.DropReasonInvalidLabels)
+			continue
+		}
+		metric.SetLabels(labels)
+
 		// Добавляем метаданные
 		if raw.Metadata != nil {
 			for key, value := range raw.Metadata {
@@ -76,12 +170,14 @@ func (uc *CollectMetricsUseCase) Execute(ctx context.Context) error {
 		// Валидация метрики
 		if err := uc.validator.Validate(metric); err != nil {
 			uc.logger.Warn("Metric validation failed", "id", metric.ID(), "error", err.Error())
+			uc.metricDropped(port.DropReasonValidation)
 			continue
 		}
 
 		// Проверка на разумность значений
 		if !uc.validator.IsReasonable(metric) {
 			uc.logger.Warn("Metric value is unreasonable", "id", metric.ID(), "value", metric.Value().Raw())
+			uc.metricDropped(port.DropReasonUnreasonable)
 			continue
 		}
 
@@ -90,7 +186,7 @@ func (uc *CollectMetricsUseCase) Execute(ctx context.Context) error {
 
 	if len(metrics) == 0 {
 		uc.logger.Warn("No valid metrics to save")
-		return nil
+		return 0, nil
 	}
 
 	uc.logger.Debug("Converted to domain entities", "valid_count", len(metrics))
@@ -98,7 +194,7 @@ func (uc *CollectMetricsUseCase) Execute(ctx context.Context) error {
 	// 3. Сохраняем в репозитории (batch insert)
 	if err := uc.repository.SaveBatch(ctx, metrics); err != nil {
 		uc.logger.Error("Failed to save metrics batch", err)
-		return fmt.Errorf("failed to save metrics: %w", err)
+		return 0, fmt.Errorf("failed to save metrics: %w", err)
 	}
 
 	uc.logger.Debug("Metrics saved to repository", "count", len(metrics))
@@ -113,88 +209,85 @@ func (uc *CollectMetricsUseCase) Execute(ctx context.Context) error {
 		}
 	}
 
-	// 4. Создаем snapshot для рассылки
-	metricsMap := uc.buildMetricsMap(metrics)
-	snapshot := dto.NewMetricSnapshotDTO(metricsMap)
-
-	// 5. Рассылаем через WebSocket
-	uc.notifier.Broadcast(snapshot)
-	uc.logger.Debug("Metrics broadcasted to clients", "client_count", uc.notifier.ClientCount())
-
-	// 5.5. Публикуем событие в NATS если включено
-	if uc.eventPublisher != nil {
-		event := map[string]interface{}{
-			"event_type":     "metric.collected",
-			"aggregate_id":   fmt.Sprintf("metrics-batch-%d", snapshot.Timestamp.Unix()),
-			"aggregate_type": "metrics",
-			"payload": map[string]interface{}{
-				"metrics_count": len(metrics),
-				"collected_at":  snapshot.Timestamp,
-				"cpu_usage":     snapshot.CPU,
-				"memory_usage":  snapshot.Memory,
-				"disk_usage":    snapshot.Disk,
-			},
-			"version": 1,
+	// 4. Создаем snapshot для рассылки (отдельный snapshot на каждый хост)
+	for host, hostMetrics := range uc.groupByHost(metrics) {
+		metricsMap := uc.buildMetricsMap(hostMetrics)
+		snapshot := dto.NewMetricSnapshotDTO(metricsMap)
+		snapshot.Host = host
+
+		// 5. Рассылаем через WebSocket
+		uc.notifier.Broadcast(snapshot)
+		uc.logger.Debug("Metrics broadcasted to clients", "host", host, "client_count", uc.notifier.ClientCount())
+
+		// 5.5. Публикуем событие в NATS если включено
+		if uc.eventPublisher != nil {
+			event := map[string]interface{}{
+				"event_type":     "metric.collected",
+				"aggregate_id":   fmt.Sprintf("metrics-batch-%d", snapshot.Timestamp.Unix()),
+				"aggregate_type": "metrics",
+				"payload": map[string]interface{}{
+					"host":          host,
+					"metrics_count": len(hostMetrics),
+					"collected_at":  snapshot.Timestamp,
+					"cpu_usage":     snapshot.CPU,
+					"memory_usage":  snapshot.Memory,
+					"disk_usage":    snapshot.Disk,
+				},
+				"version": 1,
+			}
+
+			if err := uc.eventPublisher.PublishEvent(ctx, "events.metrics.collected", event); err != nil {
+				// Log error but don't fail the entire operation (graceful degradation)
+				uc.logger.Error("Failed to publish metrics event to NATS", err)
+			} else {
+				uc.logger.Debug("Metrics event published to NATS")
+			}
 		}
+	}
 
-		if err := uc.eventPublisher.PublishEvent(ctx, "events.metrics.collected", event); err != nil {
+	// 6. Оцениваем правила алертинга по затронутым сериям
+	if uc.alertRules != nil {
+		if err := uc.alertRules.Execute(ctx, metrics); err != nil {
 			// Log error but don't fail the entire operation (graceful degradation)
-			uc.logger.Error("Failed to publish metrics event to NATS", err)
-		} else {
-			uc.logger.Debug("Metrics event published to NATS")
+			uc.logger.Error("Failed to evaluate alert rules", err)
 		}
 	}
 
-	// 6. Отправляем alerts для критических метрик
-	uc.checkAndSendAlerts(ctx, metrics)
+	return len(metrics), nil
+}
 
-	return nil
+// metricDropped учитывает отброшенную метрику, если инструментирование включено
+func (uc *CollectMetricsUseCase) metricDropped(reason string) {
+	if uc.serviceMetrics != nil {
+		uc.serviceMetrics.MetricDropped(reason)
+	}
 }
 
-// buildMetricsMap строит map метрик по типам (берем последнюю метрику каждого типа)
-func (uc *CollectMetricsUseCase) buildMetricsMap(metrics []*entity.Metric) map[valueobject.MetricType]*entity.Metric {
-	metricsMap := make(map[valueobject.MetricType]*entity.Metric)
+// groupByHost группирует метрики по хосту
+func (uc *CollectMetricsUseCase) groupByHost(metrics []*entity.Metric) map[string][]*entity.Metric {
+	grouped := make(map[string][]*entity.Metric)
 
 	for _, metric := range metrics {
-		// Перезаписываем если уже есть (оставляем последнюю)
-		metricsMap[metric.Type()] = metric
+		grouped[metric.Host()] = append(grouped[metric.Host()], metric)
 	}
 
-	return metricsMap
+	return grouped
 }
 
-// checkAndSendAlerts проверяет критические метрики и отправляет alerts
-func (uc *CollectMetricsUseCase) checkAndSendAlerts(ctx context.Context, metrics []*entity.Metric) {
+// buildMetricsMap строит map метрик по типам (берем последнюю метрику каждого типа)
+// Из одновременных серий типа (разделы, интерфейсы) берется серия с наибольшим значением, как в FindLatest
+func (uc *CollectMetricsUseCase) buildMetricsMap(metrics []*entity.Metric) map[valueobject.MetricType]*entity.Metric {
+	metricsMap := make(map[valueobject.MetricType]*entity.Metric)
+
 	for _, metric := range metrics {
-		if metric.IsCritical() {
-			message := fmt.Sprintf("%s usage is critical: %.2f%s",
-				metric.Type().String(),
-				metric.Value().Raw(),
-				metric.Value().Unit())
-
-			alert := dto.NewAlertDTO(metric, message)
-			uc.notifier.BroadcastAlert(alert)
-			uc.logger.Warn("Critical metric detected", "type", metric.Type(), "value", metric.Value().Raw())
-
-			// Публикуем событие критической метрики в NATS
-			if uc.eventPublisher != nil {
-				event := map[string]interface{}{
-					"event_type":     "metric.critical",
-					"aggregate_id":   metric.ID(),
-					"aggregate_type": "metric",
-					"payload": map[string]interface{}{
-						"metric_type": metric.Type().String(),
-						"value":       metric.Value().Raw(),
-						"unit":        metric.Value().Unit(),
-						"message":     message,
-					},
-					"version": 1,
-				}
-
-				if err := uc.eventPublisher.PublishEvent(ctx, "events.metrics.critical", event); err != nil {
-					uc.logger.Error("Failed to publish critical metric event", err)
-				}
-			}
+		current, ok := metricsMap[metric.Type()]
+		switch {
+		case !ok, metric.CollectedAt().After(current.CollectedAt()):
+			metricsMap[metric.Type()] = metric
+		case metric.CollectedAt().Equal(current.CollectedAt()) && metric.Value().Raw() > current.Value().Raw():
+			metricsMap[metric.Type()] = metric
 		}
 	}
+
+	return metricsMap
 }
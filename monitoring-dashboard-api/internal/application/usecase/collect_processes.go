@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// CollectProcessesConfig настройки снимков процессов
+type CollectProcessesConfig struct {
+	// TopN число процессов в каждом списке (по CPU и по памяти), рассылаемых по WebSocket
+	TopN int
+
+	// MaxTopN верхняя граница limit в запросе GET /api/v1/processes
+	MaxTopN int
+}
+
+// processSnapshot последний снимок процессов
+type processSnapshot struct {
+	collectedAt time.Time
+	samples     []port.ProcessSample
+}
+
+// CollectProcessesUseCase снимает процессы локального хоста каждый цикл сбора,
+// рассылает самые нагруженные по WebSocket и отдает последний снимок по запросу
+// Снимки не сохраняются в хранилище: серии по PID неограниченно растили бы число серий
+type CollectProcessesUseCase struct {
+	collector port.ProcessCollector
+	notifier  port.NotificationService
+	config    CollectProcessesConfig
+	localHost string
+	logger    *logger.Logger
+
+	mu     sync.RWMutex
+	latest *processSnapshot
+
+	// now подменяется в тестах
+	now func() time.Time
+}
+
+// NewCollectProcessesUseCase создает новый use case
+// localHost - идентификатор хоста, проставляемый снимкам
+func NewCollectProcessesUseCase(
+	collector port.ProcessCollector,
+	notifier port.NotificationService,
+	config CollectProcessesConfig,
+	localHost string,
+	logger *logger.Logger,
+) *CollectProcessesUseCase {
+	if config.TopN <= 0 {
+		config.TopN = 10
+	}
+	if config.MaxTopN < config.TopN {
+		config.MaxTopN = max(config.TopN, 100)
+	}
+
+	return &CollectProcessesUseCase{
+		collector: collector,
+		notifier:  notifier,
+		config:    config,
+		localHost: localHost,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Execute снимает процессы и рассылает top N по CPU и по памяти
+func (uc *CollectProcessesUseCase) Execute(ctx context.Context) error {
+	snapshot, err := uc.collect(ctx)
+	if err != nil {
+		uc.logger.Error("Failed to collect processes", err)
+		return err
+	}
+
+	uc.notifier.BroadcastProcesses(uc.top(snapshot, uc.config.TopN))
+	uc.logger.Debug("Processes broadcasted to clients", "total", len(snapshot.samples))
+	return nil
+}
+
+// Top возвращает limit самых нагруженных процессов из последнего снимка
+// limit <= 0 означает TopN, больше MaxTopN - MaxTopN
+// До первого цикла сбора снимок снимается по запросу (CPU в среднем за время жизни процессов)
+func (uc *CollectProcessesUseCase) Top(ctx context.Context, limit int) (*dto.TopProcessesDTO, error) {
+	if limit <= 0 {
+		limit = uc.config.TopN
+	}
+	limit = min(limit, uc.config.MaxTopN)
+
+	uc.mu.RLock()
+	snapshot := uc.latest
+	uc.mu.RUnlock()
+
+	if snapshot == nil {
+		var err error
+		if snapshot, err = uc.collect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.top(snapshot, limit), nil
+}
+
+// collect снимает процессы и запоминает снимок
+func (uc *CollectProcessesUseCase) collect(ctx context.Context) (*processSnapshot, error) {
+	samples, err := uc.collector.CollectProcesses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect processes: %w", err)
+	}
+
+	snapshot := &processSnapshot{collectedAt: uc.now(), samples: samples}
+
+	uc.mu.Lock()
+	uc.latest = snapshot
+	uc.mu.Unlock()
+
+	return snapshot, nil
+}
+
+// top строит списки limit процессов по CPU и по RSS
+// Снимок не изменяется: сортируются копии
+func (uc *CollectProcessesUseCase) top(snapshot *processSnapshot, limit int) *dto.TopProcessesDTO {
+	byCPU := topProcesses(snapshot.samples, limit, func(a, b port.ProcessSample) bool {
+		return a.CPUPercent > b.CPUPercent
+	})
+	byMemory := topProcesses(snapshot.samples, limit, func(a, b port.ProcessSample) bool {
+		return a.RSSBytes > b.RSSBytes
+	})
+
+	return &dto.TopProcessesDTO{
+		Host:           uc.localHost,
+		CollectedAt:    snapshot.collectedAt,
+		TotalProcesses: len(snapshot.samples),
+		ByCPU:          byCPU,
+		ByMemory:       byMemory,
+	}
+}
+
+// topProcesses первые limit процессов в порядке greater; при равенстве - по возрастанию PID
+func topProcesses(samples []port.ProcessSample, limit int, greater func(a, b port.ProcessSample) bool) []dto.ProcessDTO {
+	sorted := make([]port.ProcessSample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		if greater(sorted[i], sorted[j]) {
+			return true
+		}
+		if greater(sorted[j], sorted[i]) {
+			return false
+		}
+		return sorted[i].PID < sorted[j].PID
+	})
+
+	result := make([]dto.ProcessDTO, 0, min(limit, len(sorted)))
+	for _, sample := range sorted[:min(limit, len(sorted))] {
+		result = append(result, dto.ProcessDTO{
+			PID:           sample.PID,
+			Name:          sample.Name,
+			Cmdline:       sample.Cmdline,
+			User:          sample.User,
+			CPUPercent:    sample.CPUPercent,
+			RSSBytes:      sample.RSSBytes,
+			MemoryPercent: sample.MemoryPercent,
+		})
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// processMockCollector отдает заданные снимки по очереди и считает вызовы
+type processMockCollector struct {
+	snapshots [][]port.ProcessSample
+	calls     int
+}
+
+func (m *processMockCollector) CollectProcesses(context.Context) ([]port.ProcessSample, error) {
+	samples := m.snapshots[min(m.calls, len(m.snapshots)-1)]
+	m.calls++
+	return samples, nil
+}
+
+type processMockNotifier struct {
+	alertMockNotifier
+	processes []*dto.TopProcessesDTO
+}
+
+func (m *processMockNotifier) BroadcastProcesses(processes *dto.TopProcessesDTO) {
+	m.processes = append(m.processes, processes)
+}
+
+func TestCollectProcessesBroadcastsTopN(t *testing.T) {
+	collector := &processMockCollector{snapshots: [][]port.ProcessSample{{
+		{PID: 10, Name: "idle", CPUPercent: 0, RSSBytes: 1 << 20},
+		{PID: 20, Name: "compiler", CPUPercent: 250, RSSBytes: 300 << 20},
+		{PID: 30, Name: "database", CPUPercent: 40, RSSBytes: 4 << 30},
+		{PID: 5, Name: "cron", CPUPercent: 40, RSSBytes: 2 << 20},
+	}}}
+	notifier := &processMockNotifier{}
+	uc := NewCollectProcessesUseCase(collector, notifier, CollectProcessesConfig{TopN: 2}, "web-1", logger.New("error"))
+	collectedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return collectedAt }
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(notifier.processes) != 1 {
+		t.Fatalf("expected one broadcast, got %d", len(notifier.processes))
+	}
+
+	top := notifier.processes[0]
+	if top.Host != "web-1" || !top.CollectedAt.Equal(collectedAt) || top.TotalProcesses != 4 {
+		t.Fatalf("unexpected snapshot header: %+v", top)
+	}
+	// При равной загрузке CPU порядок определяется PID
+	if len(top.ByCPU) != 2 || top.ByCPU[0].PID != 20 || top.ByCPU[1].PID != 5 {
+		t.Fatalf("unexpected top by CPU: %+v", top.ByCPU)
+	}
+	if len(top.ByMemory) != 2 || top.ByMemory[0].PID != 30 || top.ByMemory[1].PID != 20 {
+		t.Fatalf("unexpected top by memory: %+v", top.ByMemory)
+	}
+}
+
+func TestCollectProcessesTopUsesLatestSnapshot(t *testing.T) {
+	collector := &processMockCollector{snapshots: [][]port.ProcessSample{
+		{{PID: 1, Name: "first", CPUPercent: 1}},
+		{{PID: 2, Name: "second", CPUPercent: 2}},
+	}}
+	uc := NewCollectProcessesUseCase(collector, &processMockNotifier{}, CollectProcessesConfig{}, "web-1", logger.New("error"))
+
+	// До первого цикла снимок снимается по запросу и запоминается
+	top, err := uc.Top(context.Background(), 0)
+	if err != nil || len(top.ByCPU) != 1 || top.ByCPU[0].Name != "first" {
+		t.Fatalf("Top() before first cycle = %+v, %v", top, err)
+	}
+	if _, err := uc.Top(context.Background(), 5); err != nil || collector.calls != 1 {
+		t.Fatalf("Top() must reuse the snapshot, collector called %d times (err %v)", collector.calls, err)
+	}
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	top, err = uc.Top(context.Background(), 1000)
+	if err != nil || top.ByCPU[0].Name != "second" {
+		t.Fatalf("Top() after cycle = %+v, %v", top, err)
+	}
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// DispatchNotificationsConfig настройки доставки уведомлений во внешние каналы
+type DispatchNotificationsConfig struct {
+	// DefaultChannels используются для правил без собственного списка каналов
+	DefaultChannels []string
+
+	Workers      int
+	QueueSize    int
+	MaxAttempts  int
+	RetryBackoff time.Duration // Задержка перед второй попыткой, далее удваивается
+	MaxBackoff   time.Duration
+	SendTimeout  time.Duration // Таймаут одной попытки
+}
+
+type notificationJob struct {
+	channel  port.NotificationChannel
+	alert    *dto.AlertDTO
+	delivery *entity.NotificationDelivery
+}
+
+// DispatchNotificationsUseCase доставляет алерты во внешние каналы с повторами и журналом доставки
+type DispatchNotificationsUseCase struct {
+	channels   map[string]port.NotificationChannel
+	deliveries repository.NotificationDeliveryRepository
+	config     DispatchNotificationsConfig
+	queue      chan notificationJob
+	logger     *logger.Logger
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// NewDispatchNotificationsUseCase создает новый use case
+func NewDispatchNotificationsUseCase(
+	channels []port.NotificationChannel,
+	deliveries repository.NotificationDeliveryRepository,
+	config DispatchNotificationsConfig,
+	logger *logger.Logger,
+) *DispatchNotificationsUseCase {
+	if config.Workers <= 0 {
+		config.Workers = 2
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 2 * time.Second
+	}
+	if config.MaxBackoff < config.RetryBackoff {
+		config.MaxBackoff = config.RetryBackoff
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 10 * time.Second
+	}
+
+	byName := make(map[string]port.NotificationChannel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+
+	return &DispatchNotificationsUseCase{
+		channels:   byName,
+		deliveries: deliveries,
+		config:     config,
+		queue:      make(chan notificationJob, config.QueueSize),
+		logger:     logger,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// Run запускает воркеры доставки и блокируется до отмены ctx
+func (uc *DispatchNotificationsUseCase) Run(ctx context.Context) {
+	done := make(chan struct{})
+	for i := 0; i < uc.config.Workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-uc.queue:
+					uc.deliver(ctx, job)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < uc.config.Workers; i++ {
+		<-done
+	}
+}
+
+// Dispatch ставит алерт в очередь доставки по каналам правила (или каналам по умолчанию)
+// Не блокируется: при переполнении очереди доставка записывается в журнал как failed
+func (uc *DispatchNotificationsUseCase) Dispatch(ctx context.Context, alert *dto.AlertDTO, channels []string) {
+	if len(channels) == 0 {
+		channels = uc.config.DefaultChannels
+	}
+
+	severity := valueobject.AlertSeverity(alert.Level)
+	for _, name := range channels {
+		channel, ok := uc.channels[name]
+		if ok && !severity.AtLeast(channel.MinSeverity()) {
+			continue
+		}
+
+		params := entity.NotificationDeliveryParams{
+			Channel:    name,
+			RuleID:     alert.RuleID,
+			RuleName:   alert.RuleName,
+			Host:       alert.Host,
+			AlertState: alert.State,
+			Severity:   alert.Level,
+			Message:    alert.Message,
+		}
+		if ok {
+			params.ChannelType = channel.Type()
+		}
+		delivery := entity.NewNotificationDelivery(params, uc.now())
+
+		if !ok {
+			delivery.Fail(fmt.Errorf("unknown channel %q", name), uc.now())
+			uc.logger.Warn("Alert routed to unknown notification channel", "channel", name, "rule", alert.RuleName)
+			uc.saveDelivery(ctx, delivery)
+			continue
+		}
+
+		uc.saveDelivery(ctx, delivery)
+
+		select {
+		case uc.queue <- notificationJob{channel: channel, alert: alert, delivery: delivery}:
+		default:
+			delivery.Fail(errors.New("delivery queue is full"), uc.now())
+			uc.logger.Warn("Notification queue full, dropping delivery", "channel", name, "rule", alert.RuleName)
+			uc.saveDelivery(ctx, delivery)
+		}
+	}
+}
+
+// ListDeliveries возвращает журнал доставки
+func (uc *DispatchNotificationsUseCase) ListDeliveries(
+	ctx context.Context,
+	filter repository.NotificationDeliveryFilter,
+) ([]*dto.NotificationDeliveryDTO, error) {
+	switch filter.Status {
+	case "", entity.DeliveryPending, entity.DeliveryDelivered, entity.DeliveryFailed:
+	default:
+		return nil, fmt.Errorf("invalid delivery filter: unknown status %q", filter.Status)
+	}
+
+	deliveries, err := uc.deliveries.FindRecent(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+	return dto.ToNotificationDeliveryDTOs(deliveries), nil
+}
+
+// deliver выполняет попытки доставки с экспоненциальной задержкой
+func (uc *DispatchNotificationsUseCase) deliver(ctx context.Context, job notificationJob) {
+	// Журнал пишется и при остановке, поэтому без отмены родительского контекста
+	saveCtx := context.WithoutCancel(ctx)
+	backoff := uc.config.RetryBackoff
+
+	for attempt := 1; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, uc.config.SendTimeout)
+		err := job.channel.Send(sendCtx, job.alert)
+		cancel()
+
+		final := err == nil ||
+			attempt >= uc.config.MaxAttempts ||
+			errors.Is(err, port.ErrNotificationRejected) ||
+			ctx.Err() != nil
+		job.delivery.RecordAttempt(err, final, uc.now())
+		uc.saveDelivery(saveCtx, job.delivery)
+
+		if err == nil {
+			uc.logger.Debug("Notification delivered", "channel", job.channel.Name(), "attempt", attempt)
+			return
+		}
+		if final {
+			uc.logger.Error("Notification delivery failed", err, "channel", job.channel.Name(), "attempts", attempt)
+			return
+		}
+
+		uc.logger.Warn("Notification delivery attempt failed, retrying",
+			"channel", job.channel.Name(),
+			"attempt", attempt,
+			"backoff", backoff.String(),
+			"error", err.Error())
+
+		if err := uc.sleep(ctx, backoff); err != nil {
+			job.delivery.Fail(fmt.Errorf("dispatcher stopped before retry: %w", err), uc.now())
+			uc.saveDelivery(saveCtx, job.delivery)
+			return
+		}
+
+		backoff *= 2
+		if backoff > uc.config.MaxBackoff {
+			backoff = uc.config.MaxBackoff
+		}
+	}
+}
+
+func (uc *DispatchNotificationsUseCase) saveDelivery(ctx context.Context, delivery *entity.NotificationDelivery) {
+	if err := uc.deliveries.Save(ctx, delivery); err != nil {
+		uc.logger.Error("Failed to save notification delivery", err, "channel", delivery.Channel())
+	}
+}
+
+// sleepContext ждет d или отмены ctx
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+type notificationMockChannel struct {
+	name        string
+	minSeverity valueobject.AlertSeverity
+	errs        []error // ошибки по порядку попыток; дальше - успех
+	sent        int
+}
+
+func (c *notificationMockChannel) Name() string                           { return c.name }
+func (c *notificationMockChannel) Type() string                           { return "mock" }
+func (c *notificationMockChannel) MinSeverity() valueobject.AlertSeverity { return c.minSeverity }
+
+func (c *notificationMockChannel) Send(_ context.Context, _ *dto.AlertDTO) error {
+	attempt := c.sent
+	c.sent++
+	if attempt < len(c.errs) {
+		return c.errs[attempt]
+	}
+	return nil
+}
+
+type deliveryMockRepository struct {
+	deliveries map[string]*entity.NotificationDelivery
+}
+
+func (m *deliveryMockRepository) Save(_ context.Context, delivery *entity.NotificationDelivery) error {
+	m.deliveries[delivery.ID()] = delivery
+	return nil
+}
+
+func (m *deliveryMockRepository) FindRecent(_ context.Context, filter repository.NotificationDeliveryFilter) ([]*entity.NotificationDelivery, error) {
+	result := make([]*entity.NotificationDelivery, 0, len(m.deliveries))
+	for _, delivery := range m.deliveries {
+		if filter.Channel != "" && delivery.Channel() != filter.Channel {
+			continue
+		}
+		result = append(result, delivery)
+	}
+	return result, nil
+}
+
+// newDispatchTestUseCase создает диспетчер с записью задержек вместо ожидания
+func newDispatchTestUseCase(channels []port.NotificationChannel, config DispatchNotificationsConfig) (*DispatchNotificationsUseCase, *deliveryMockRepository, *[]time.Duration) {
+	deliveries := &deliveryMockRepository{deliveries: make(map[string]*entity.NotificationDelivery)}
+	uc := NewDispatchNotificationsUseCase(channels, deliveries, config, logger.New("error"))
+
+	var backoffs []time.Duration
+	uc.sleep = func(_ context.Context, d time.Duration) error {
+		backoffs = append(backoffs, d)
+		return nil
+	}
+	return uc, deliveries, &backoffs
+}
+
+// drain синхронно обрабатывает очередь вместо воркеров
+func drain(uc *DispatchNotificationsUseCase) {
+	for len(uc.queue) > 0 {
+		uc.deliver(context.Background(), <-uc.queue)
+	}
+}
+
+func deliveryFor(t *testing.T, repo *deliveryMockRepository, channel string) *entity.NotificationDelivery {
+	t.Helper()
+	deliveries, _ := repo.FindRecent(context.Background(), repository.NotificationDeliveryFilter{Channel: channel})
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 delivery for %s, got %d", channel, len(deliveries))
+	}
+	return deliveries[0]
+}
+
+func criticalAlert() *dto.AlertDTO {
+	return &dto.AlertDTO{Level: "critical", State: "firing", RuleName: "High CPU", Host: "web-1", Message: "cpu is high"}
+}
+
+func TestDispatchNotificationsRetriesWithBackoff(t *testing.T) {
+	flaky := &notificationMockChannel{name: "ops", errs: []error{
+		errors.New("connection reset"),
+		errors.New("status 502"),
+		errors.New("status 503"),
+	}}
+	uc, deliveries, backoffs := newDispatchTestUseCase([]port.NotificationChannel{flaky}, DispatchNotificationsConfig{
+		MaxAttempts:  5,
+		RetryBackoff: 10 * time.Millisecond,
+		MaxBackoff:   25 * time.Millisecond,
+	})
+
+	uc.Dispatch(context.Background(), criticalAlert(), []string{"ops"})
+	drain(uc)
+
+	delivery := deliveryFor(t, deliveries, "ops")
+	if delivery.Status() != entity.DeliveryDelivered || delivery.Attempts() != 4 {
+		t.Fatalf("expected delivered after 4 attempts, got %s after %d", delivery.Status(), delivery.Attempts())
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond}
+	if fmt.Sprint(*backoffs) != fmt.Sprint(want) {
+		t.Fatalf("backoffs = %v, want %v", *backoffs, want)
+	}
+}
+
+func TestDispatchNotificationsStopsRetrying(t *testing.T) {
+	rejected := &notificationMockChannel{name: "slack", errs: []error{
+		fmt.Errorf("%w: status 404", port.ErrNotificationRejected),
+	}}
+	down := &notificationMockChannel{name: "webhook", errs: []error{
+		errors.New("timeout"), errors.New("timeout"), errors.New("timeout"),
+	}}
+	uc, deliveries, _ := newDispatchTestUseCase([]port.NotificationChannel{rejected, down}, DispatchNotificationsConfig{
+		MaxAttempts: 3,
+	})
+
+	uc.Dispatch(context.Background(), criticalAlert(), []string{"slack", "webhook"})
+	drain(uc)
+
+	if delivery := deliveryFor(t, deliveries, "slack"); delivery.Status() != entity.DeliveryFailed || delivery.Attempts() != 1 {
+		t.Fatalf("rejected delivery must not be retried: %s after %d", delivery.Status(), delivery.Attempts())
+	}
+	delivery := deliveryFor(t, deliveries, "webhook")
+	if delivery.Status() != entity.DeliveryFailed || delivery.Attempts() != 3 || delivery.LastError() != "timeout" {
+		t.Fatalf("expected failure after max attempts, got %s after %d (%q)", delivery.Status(), delivery.Attempts(), delivery.LastError())
+	}
+}
+
+func TestDispatchNotificationsRouting(t *testing.T) {
+	ops := &notificationMockChannel{name: "ops"}
+	pager := &notificationMockChannel{name: "pager", minSeverity: valueobject.SeverityCritical}
+	uc, deliveries, _ := newDispatchTestUseCase([]port.NotificationChannel{ops, pager}, DispatchNotificationsConfig{
+		DefaultChannels: []string{"ops", "pager"},
+	})
+
+	// Правило без каналов использует каналы по умолчанию; pager отсекает warning
+	warning := criticalAlert()
+	warning.Level = "warning"
+	uc.Dispatch(context.Background(), warning, nil)
+	drain(uc)
+
+	if ops.sent != 1 || pager.sent != 0 {
+		t.Fatalf("unexpected sends: ops=%d pager=%d", ops.sent, pager.sent)
+	}
+
+	// Каналы правила заменяют каналы по умолчанию; неизвестный канал попадает в журнал
+	uc.Dispatch(context.Background(), criticalAlert(), []string{"pager", "missing"})
+	drain(uc)
+
+	if ops.sent != 1 || pager.sent != 1 {
+		t.Fatalf("unexpected sends: ops=%d pager=%d", ops.sent, pager.sent)
+	}
+	if delivery := deliveryFor(t, deliveries, "missing"); delivery.Status() != entity.DeliveryFailed {
+		t.Fatalf("expected failed delivery for unknown channel, got %s", delivery.Status())
+	}
+}
+
+func TestDispatchNotificationsQueueFull(t *testing.T) {
+	ops := &notificationMockChannel{name: "ops"}
+	uc, deliveries, _ := newDispatchTestUseCase([]port.NotificationChannel{ops}, DispatchNotificationsConfig{QueueSize: 1})
+
+	uc.Dispatch(context.Background(), criticalAlert(), []string{"ops"})
+	uc.Dispatch(context.Background(), criticalAlert(), []string{"ops"})
+
+	listed, _ := uc.ListDeliveries(context.Background(), repository.NotificationDeliveryFilter{})
+	statuses := map[string]int{}
+	for _, delivery := range listed {
+		statuses[delivery.Status]++
+	}
+	if statuses["pending"] != 1 || statuses["failed"] != 1 || len(deliveries.deliveries) != 2 {
+		t.Fatalf("expected one pending and one dropped delivery, got %v", statuses)
+	}
+}
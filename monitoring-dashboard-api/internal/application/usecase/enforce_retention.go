@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ErrRetentionInProgress возвращается, если очистка уже выполняется
+var ErrRetentionInProgress = errors.New("retention run already in progress")
+
+// defaultRetentionPolicyType обозначает политику по умолчанию в результатах
+const defaultRetentionPolicyType = "*"
+
+// EnforceRetentionConfig настройки очистки устаревших метрик
+type EnforceRetentionConfig struct {
+	// Retention срок хранения по умолчанию (0 - хранить бессрочно)
+	Retention time.Duration
+
+	// Overrides сроки хранения отдельных типов (0 - хранить бессрочно)
+	Overrides map[valueobject.MetricType]time.Duration
+
+	BatchSize  int
+	BatchPause time.Duration // Пауза между пачками, чтобы не мешать записи
+}
+
+// retentionPolicy срок хранения для набора типов
+type retentionPolicy struct {
+	label     string
+	retention time.Duration
+	query     repository.RetentionQuery
+}
+
+// EnforceRetentionUseCase удаляет метрики с истекшим сроком хранения пачками
+type EnforceRetentionUseCase struct {
+	repository repository.MetricRepository
+	config     EnforceRetentionConfig
+	logger     *logger.Logger
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	running sync.Mutex
+
+	statsMu sync.Mutex
+	stats   dto.RetentionStatsDTO
+}
+
+// NewEnforceRetentionUseCase создает новый use case
+func NewEnforceRetentionUseCase(
+	repository repository.MetricRepository,
+	config EnforceRetentionConfig,
+	logger *logger.Logger,
+) *EnforceRetentionUseCase {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 5000
+	}
+
+	return &EnforceRetentionUseCase{
+		repository: repository,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// Execute применяет политики хранения
+// В режиме dryRun только подсчитывает строки, подлежащие удалению
+func (uc *EnforceRetentionUseCase) Execute(ctx context.Context, dryRun bool) (*dto.RetentionRunDTO, error) {
+	if !uc.running.TryLock() {
+		return nil, ErrRetentionInProgress
+	}
+	defer uc.running.Unlock()
+
+	run := &dto.RetentionRunDTO{
+		DryRun:    dryRun,
+		StartedAt: uc.now(),
+		Policies:  []*dto.RetentionPolicyResultDTO{},
+	}
+
+	uc.logger.Info("Retention run started", "dry_run", dryRun)
+
+	var runErr error
+	for _, policy := range uc.policies(run.StartedAt) {
+		result, err := uc.apply(ctx, policy, dryRun)
+		run.Policies = append(run.Policies, result)
+		run.Rows += result.Rows
+		if err != nil {
+			runErr = fmt.Errorf("failed to apply retention for %s: %w", policy.label, err)
+			break
+		}
+	}
+
+	run.FinishedAt = uc.now()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	if runErr != nil {
+		run.Error = runErr.Error()
+		uc.logger.Error("Retention run failed", runErr, "rows", run.Rows)
+	} else {
+		uc.logger.Info("Retention run finished", "dry_run", dryRun, "rows", run.Rows, "duration_ms", run.DurationMs)
+	}
+
+	uc.statsMu.Lock()
+	uc.stats.Runs++
+	if !dryRun {
+		uc.stats.RowsDeleted += run.Rows
+	}
+	uc.stats.LastRun = run
+	uc.statsMu.Unlock()
+
+	return run, runErr
+}
+
+// Stats возвращает статистику очистки с момента запуска процесса
+func (uc *EnforceRetentionUseCase) Stats() dto.RetentionStatsDTO {
+	uc.statsMu.Lock()
+	defer uc.statsMu.Unlock()
+	return uc.stats
+}
+
+// policies строит политики: по одной на каждый переопределенный тип и общую для остальных типов
+func (uc *EnforceRetentionUseCase) policies(now time.Time) []retentionPolicy {
+	overridden := make([]valueobject.MetricType, 0, len(uc.config.Overrides))
+	for metricType := range uc.config.Overrides {
+		overridden = append(overridden, metricType)
+	}
+	sort.Slice(overridden, func(i, j int) bool { return overridden[i] < overridden[j] })
+
+	policies := make([]retentionPolicy, 0, len(overridden)+1)
+	for _, metricType := range overridden {
+		retention := uc.config.Overrides[metricType]
+		if retention <= 0 {
+			continue
+		}
+		policies = append(policies, retentionPolicy{
+			label:     metricType.String(),
+			retention: retention,
+			query: repository.RetentionQuery{
+				Before: now.Add(-retention),
+				Types:  []valueobject.MetricType{metricType},
+			},
+		})
+	}
+
+	if uc.config.Retention > 0 {
+		policies = append(policies, retentionPolicy{
+			label:     defaultRetentionPolicyType,
+			retention: uc.config.Retention,
+			query: repository.RetentionQuery{
+				Before:       now.Add(-uc.config.Retention),
+				ExcludeTypes: overridden,
+			},
+		})
+	}
+
+	return policies
+}
+
+// apply удаляет строки политики пачками до исчерпания
+func (uc *EnforceRetentionUseCase) apply(
+	ctx context.Context,
+	policy retentionPolicy,
+	dryRun bool,
+) (*dto.RetentionPolicyResultDTO, error) {
+	result := &dto.RetentionPolicyResultDTO{
+		MetricType: policy.label,
+		Retention:  policy.retention.String(),
+		Cutoff:     policy.query.Before,
+	}
+
+	if dryRun {
+		count, err := uc.repository.CountExpired(ctx, policy.query)
+		if err != nil {
+			return result, err
+		}
+		result.Rows = count
+		uc.logger.Info("Retention dry run", "type", policy.label, "cutoff", policy.query.Before, "rows", count)
+		return result, nil
+	}
+
+	query := policy.query
+	query.Limit = uc.config.BatchSize
+	for {
+		deleted, err := uc.repository.DeleteExpired(ctx, query)
+		if err != nil {
+			return result, err
+		}
+		result.Rows += deleted
+		result.Batches++
+
+		uc.logger.Debug("Retention batch deleted",
+			"type", policy.label,
+			"batch", result.Batches,
+			"deleted", deleted,
+			"total", result.Rows)
+
+		if deleted < int64(query.Limit) {
+			break
+		}
+		if err := uc.sleep(ctx, uc.config.BatchPause); err != nil {
+			return result, err
+		}
+	}
+
+	uc.logger.Info("Retention policy applied",
+		"type", policy.label,
+		"cutoff", policy.query.Before,
+		"deleted", result.Rows,
+		"batches", result.Batches)
+
+	return result, nil
+}
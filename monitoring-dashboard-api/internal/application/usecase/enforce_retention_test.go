@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// retentionMockRepository хранит количество устаревших строк по типам
+type retentionMockRepository struct {
+	repository.MetricRepository
+	expired   map[valueobject.MetricType]int64
+	queries   []repository.RetentionQuery
+	failAfter int // Ошибка на вызове с этим номером (0 - без ошибок)
+}
+
+func (m *retentionMockRepository) matching(query repository.RetentionQuery) []valueobject.MetricType {
+	var types []valueobject.MetricType
+	for metricType := range m.expired {
+		if len(query.Types) > 0 && !containsType(query.Types, metricType) {
+			continue
+		}
+		if containsType(query.ExcludeTypes, metricType) {
+			continue
+		}
+		types = append(types, metricType)
+	}
+	return types
+}
+
+func containsType(types []valueobject.MetricType, metricType valueobject.MetricType) bool {
+	for _, candidate := range types {
+		if candidate == metricType {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *retentionMockRepository) CountExpired(_ context.Context, query repository.RetentionQuery) (int64, error) {
+	var count int64
+	for _, metricType := range m.matching(query) {
+		count += m.expired[metricType]
+	}
+	return count, nil
+}
+
+func (m *retentionMockRepository) DeleteExpired(_ context.Context, query repository.RetentionQuery) (int64, error) {
+	m.queries = append(m.queries, query)
+	if m.failAfter > 0 && len(m.queries) == m.failAfter {
+		return 0, errors.New("connection lost")
+	}
+
+	var deleted int64
+	for _, metricType := range m.matching(query) {
+		take := m.expired[metricType]
+		if remaining := int64(query.Limit) - deleted; take > remaining {
+			take = remaining
+		}
+		m.expired[metricType] -= take
+		deleted += take
+	}
+	return deleted, nil
+}
+
+func newRetentionTestUseCase(repo *retentionMockRepository, config EnforceRetentionConfig) (*EnforceRetentionUseCase, *[]time.Duration) {
+	uc := NewEnforceRetentionUseCase(repo, config, logger.New("error"))
+	uc.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	var pauses []time.Duration
+	uc.sleep = func(_ context.Context, d time.Duration) error {
+		pauses = append(pauses, d)
+		return nil
+	}
+	return uc, &pauses
+}
+
+func TestEnforceRetentionBatchesAndOverrides(t *testing.T) {
+	repo := &retentionMockRepository{expired: map[valueobject.MetricType]int64{
+		valueobject.CPU:     25,
+		valueobject.Memory:  5,
+		valueobject.Disk:    7,
+		valueobject.Network: 100,
+	}}
+	uc, pauses := newRetentionTestUseCase(repo, EnforceRetentionConfig{
+		Retention: 7 * 24 * time.Hour,
+		Overrides: map[valueobject.MetricType]time.Duration{
+			valueobject.Disk:    30 * 24 * time.Hour,
+			valueobject.Network: 0, // бессрочно
+		},
+		BatchSize:  10,
+		BatchPause: 50 * time.Millisecond,
+	})
+
+	run, err := uc.Execute(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if run.Rows != 37 || len(run.Policies) != 2 {
+		t.Fatalf("unexpected run: rows=%d policies=%d", run.Rows, len(run.Policies))
+	}
+
+	disk, general := run.Policies[0], run.Policies[1]
+	if disk.MetricType != "disk" || disk.Rows != 7 || disk.Batches != 1 {
+		t.Fatalf("unexpected disk policy: %+v", disk)
+	}
+	if !disk.Cutoff.Equal(time.Date(2026, 1, 30, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected disk cutoff: %s", disk.Cutoff)
+	}
+	// 30 строк cpu+memory пачками по 10: 10, 10, 10, 0
+	if general.MetricType != "*" || general.Rows != 30 || general.Batches != 4 {
+		t.Fatalf("unexpected default policy: %+v", general)
+	}
+	if len(*pauses) != 3 {
+		t.Fatalf("expected a pause between full batches, got %v", *pauses)
+	}
+
+	if repo.expired[valueobject.Network] != 100 {
+		t.Fatal("network metrics must be kept forever")
+	}
+	lastQuery := repo.queries[len(repo.queries)-1]
+	if !containsType(lastQuery.ExcludeTypes, valueobject.Disk) || !containsType(lastQuery.ExcludeTypes, valueobject.Network) {
+		t.Fatalf("default policy must exclude overridden types: %v", lastQuery.ExcludeTypes)
+	}
+
+	if stats := uc.Stats(); stats.Runs != 1 || stats.RowsDeleted != 37 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestEnforceRetentionDryRun(t *testing.T) {
+	repo := &retentionMockRepository{expired: map[valueobject.MetricType]int64{valueobject.CPU: 12}}
+	uc, _ := newRetentionTestUseCase(repo, EnforceRetentionConfig{Retention: time.Hour})
+
+	run, err := uc.Execute(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !run.DryRun || run.Rows != 12 || len(repo.queries) != 0 || repo.expired[valueobject.CPU] != 12 {
+		t.Fatalf("dry run must not delete anything: %+v", run)
+	}
+	if stats := uc.Stats(); stats.RowsDeleted != 0 {
+		t.Fatalf("dry run must not count as deleted: %+v", stats)
+	}
+}
+
+func TestEnforceRetentionPartialFailure(t *testing.T) {
+	repo := &retentionMockRepository{
+		expired:   map[valueobject.MetricType]int64{valueobject.CPU: 25},
+		failAfter: 3,
+	}
+	uc, _ := newRetentionTestUseCase(repo, EnforceRetentionConfig{Retention: time.Hour, BatchSize: 10})
+
+	run, err := uc.Execute(context.Background(), false)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if run == nil || run.Rows != 20 || run.Error == "" {
+		t.Fatalf("expected partial result with 20 rows, got %+v", run)
+	}
+}
+
+func TestEnforceRetentionRejectsConcurrentRuns(t *testing.T) {
+	uc, _ := newRetentionTestUseCase(&retentionMockRepository{}, EnforceRetentionConfig{Retention: time.Hour})
+
+	uc.running.Lock()
+	defer uc.running.Unlock()
+
+	if _, err := uc.Execute(context.Background(), false); !errors.Is(err, ErrRetentionInProgress) {
+		t.Fatalf("expected ErrRetentionInProgress, got %v", err)
+	}
+}
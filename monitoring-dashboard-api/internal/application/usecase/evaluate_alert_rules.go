@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// EvaluateAlertRulesUseCase оценивает правила алертинга и ведет состояние pending/firing/resolved
+// Состояние хранится в памяти процесса отдельно для каждой пары (правило, серия)
+type EvaluateAlertRulesUseCase struct {
+	rules          repository.AlertRuleRepository
+	metrics        repository.MetricRepository
+	aggregator     *service.MetricAggregator
+	notifier       port.NotificationService
+	eventPublisher port.EventPublisher           // Optional NATS event publisher
+	incidents      *ManageIncidentsUseCase       // Optional incident tracking
+	dispatcher     *DispatchNotificationsUseCase // Optional external notification channels
+	logger         *logger.Logger
+
+	// mu защищает только states: запросы к хранилищу и уведомления выполняются без блокировки,
+	// чтобы оценка правил из одного запроса ingest не задерживала остальные
+	mu     sync.Mutex
+	states map[string]*entity.AlertState
+
+	// effectsMu сохраняет порядок переходов при записи в инциденты и рассылке:
+	// берется под mu и удерживается только на время выполнения эффектов
+	effectsMu sync.Mutex
+
+	// maxWindowSamples предел точек окна одного правила
+	maxWindowSamples int
+	now              func() time.Time
+}
+
+// maxAlertWindowSamples предел точек окна правила: правило, выбирающее больше,
+// не оценивается, а его состояния не меняются до сужения правила (metric_name, selector)
+const maxAlertWindowSamples = 50000
+
+// alertEffect переход или уведомление, выполняемые после снятия блокировки
+type alertEffect struct {
+	rule   *entity.AlertRule
+	state  *entity.AlertState // снимок состояния на момент оценки
+	latest *entity.Metric
+
+	// transition - состояние изменилось и должно попасть в инцидент
+	transition bool
+	// notify - нужно разослать уведомление
+	notify bool
+}
+
+// NewEvaluateAlertRulesUseCase создает новый use case
+func NewEvaluateAlertRulesUseCase(
+	rules repository.AlertRuleRepository,
+	metrics repository.MetricRepository,
+	aggregator *service.MetricAggregator,
+	notifier port.NotificationService,
+	eventPublisher port.EventPublisher, // Can be nil if NATS disabled
+	incidents *ManageIncidentsUseCase, // Can be nil if incidents disabled
+	dispatcher *DispatchNotificationsUseCase, // Can be nil if notification channels disabled
+	logger *logger.Logger,
+) *EvaluateAlertRulesUseCase {
+	return &EvaluateAlertRulesUseCase{
+		rules:          rules,
+		metrics:        metrics,
+		aggregator:     aggregator,
+		notifier:       notifier,
+		eventPublisher: eventPublisher,
+		incidents:      incidents,
+		dispatcher:     dispatcher,
+		logger:         logger,
+		states:         make(map[string]*entity.AlertState),
+
+		maxWindowSamples: maxAlertWindowSamples,
+		now:              time.Now,
+	}
+}
+
+// Restore восстанавливает состояния firing по незакрытым инцидентам включенных правил
+// Состояние алертов хранится в памяти: без восстановления инцидент, открытый до перезапуска,
+// не получил бы resolved. Вызывается один раз при старте, до первого цикла оценки;
+// дальше серии восстановленных состояний оцениваются как обычно
+func (uc *EvaluateAlertRulesUseCase) Restore(ctx context.Context) error {
+	if uc.incidents == nil {
+		return nil
+	}
+
+	incidents, err := uc.incidents.ActiveAlertIncidents(ctx)
+	if err != nil {
+		return err
+	}
+	rules, err := uc.rules.FindEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load alert rules: %w", err)
+	}
+
+	enabled := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		enabled[rule.ID()] = struct{}{}
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	restored := 0
+	for _, incident := range incidents {
+		params := incident.Params()
+		// Инциденты выключенных и удаленных правил не восстанавливаются, как и состояния таких правил
+		if _, ok := enabled[params.RuleID]; !ok {
+			continue
+		}
+
+		key := alertStateKey(params.RuleID, params.SeriesKey)
+		if _, ok := uc.states[key]; ok {
+			continue
+		}
+
+		lifecycle := incident.Lifecycle()
+		state := entity.NewAlertState(params.RuleID, params.SeriesKey, params.Host, params.Labels)
+		state.RestoreFiring(lifecycle.LastValue, lifecycle.LastAlertAt)
+		uc.states[key] = state
+		restored++
+	}
+
+	if restored > 0 {
+		uc.logger.Info("Alert states restored from open incidents", "count", restored)
+	}
+	return nil
+}
+
+// Execute оценивает включенные правила
+// Если передан batch, оцениваются правила, к которым относится хотя бы одна метрика пакета,
+// и правила с отслеживаемыми сериями: серия, переставшая поступать, должна разрешиться,
+// даже если ни одна новая метрика к правилу не относится
+func (uc *EvaluateAlertRulesUseCase) Execute(ctx context.Context, batch []*entity.Metric) error {
+	rules, err := uc.rules.FindEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load alert rules: %w", err)
+	}
+
+	uc.mu.Lock()
+	uc.pruneStates(rules)
+	selected := make([]*entity.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		if batch != nil && !matchesAny(rule, batch) && !uc.hasStates(rule) {
+			continue
+		}
+		selected = append(selected, rule)
+	}
+	uc.mu.Unlock()
+
+	for _, rule := range selected {
+		if err := uc.evaluateRule(ctx, rule); err != nil {
+			uc.logger.Error("Failed to evaluate alert rule", err, "rule_id", rule.ID(), "rule", rule.Name())
+		}
+	}
+
+	return nil
+}
+
+// evaluateRule оценивает правило по каждой серии в окне
+// Окно читается без блокировки; при ошибке чтения (в том числе превышении лимита точек)
+// состояния правила не меняются: неполное окно не должно разрешать алерты
+func (uc *EvaluateAlertRulesUseCase) evaluateRule(ctx context.Context, rule *entity.AlertRule) error {
+	params := rule.Params()
+	now := uc.now()
+
+	timeRange, err := valueobject.NewTimeRange(now.Add(-params.Window), now)
+	if err != nil {
+		return err
+	}
+
+	metrics, err := findWithinLimit(ctx, uc.metrics, repository.MetricQuery{
+		Type:      params.MetricType,
+		Name:      params.MetricName,
+		Selector:  params.Selector,
+		TimeRange: timeRange,
+	}, uc.maxWindowSamples)
+	if err != nil {
+		return err
+	}
+
+	series := make(map[string][]*entity.Metric)
+	for _, metric := range metrics {
+		// Выборка ограничена типом, именем и селектором; единица измерения проверяется здесь
+		if !rule.Matches(metric) {
+			continue
+		}
+		series[metric.SeriesKey()] = append(series[metric.SeriesKey()], metric)
+	}
+
+	effects := uc.applyRule(rule, series)
+	if len(effects) == 0 {
+		return nil
+	}
+
+	// applyRule вернул эффекты с захваченным effectsMu
+	defer uc.effectsMu.Unlock()
+	for _, effect := range effects {
+		uc.applyEffect(ctx, effect)
+	}
+
+	return nil
+}
+
+// applyRule применяет результаты оценки серий к состояниям правила под блокировкой
+// Возвращает переходы и уведомления, которые нужно выполнить после снятия блокировки;
+// если они есть, effectsMu захватывается до снятия mu и освобождается вызывающим
+func (uc *EvaluateAlertRulesUseCase) applyRule(rule *entity.AlertRule, series map[string][]*entity.Metric) []alertEffect {
+	params := rule.Params()
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	// Время переходов берется под блокировкой, чтобы параллельные оценки не откатывали его назад
+	now := uc.now()
+
+	var effects []alertEffect
+	for seriesKey, seriesMetrics := range series {
+		// Значения окна упорядочиваем от старых к новым
+		sorted := uc.aggregator.SortByTime(seriesMetrics, false)
+		values := make([]float64, len(sorted))
+		for i, metric := range sorted {
+			values[i] = metric.Value().Raw()
+		}
+		latest := sorted[len(sorted)-1]
+
+		value, conditionMet := rule.Evaluate(values)
+
+		key := alertStateKey(rule.ID(), seriesKey)
+		state, ok := uc.states[key]
+		if !ok {
+			if !conditionMet {
+				continue
+			}
+			state = entity.NewAlertState(rule.ID(), seriesKey, latest.Host(), latest.Labels())
+			uc.states[key] = state
+		}
+
+		if effect, ok := uc.observe(rule, state, conditionMet, value, latest, now); ok {
+			effects = append(effects, effect)
+		}
+	}
+
+	for key, state := range uc.states {
+		if state.RuleID() != rule.ID() {
+			continue
+		}
+		// Серии без данных в окне считаются не удовлетворяющими условию
+		if _, seen := series[state.SeriesKey()]; !seen {
+			if effect, ok := uc.observe(rule, state, false, state.Value(), nil, now); ok {
+				effects = append(effects, effect)
+			}
+		}
+		if state.IsStale(params.Cooldown, now) {
+			delete(uc.states, key)
+		}
+	}
+
+	if len(effects) > 0 {
+		uc.effectsMu.Lock()
+	}
+	return effects
+}
+
+// observe применяет результат оценки к состоянию
+// Возвращает переход и уведомление для выполнения вне блокировки (ok - если есть что выполнять)
+func (uc *EvaluateAlertRulesUseCase) observe(
+	rule *entity.AlertRule,
+	state *entity.AlertState,
+	conditionMet bool,
+	value float64,
+	latest *entity.Metric,
+	now time.Time,
+) (alertEffect, bool) {
+	effect := alertEffect{rule: rule, latest: latest}
+
+	if state.Observe(conditionMet, value, rule.Params().For, now) {
+		uc.logger.Debug("Alert state changed",
+			"rule", rule.Name(),
+			"host", state.Host(),
+			"status", string(state.Status()),
+			"value", value)
+
+		// Инциденты отражают каждый переход, независимо от cooldown уведомлений
+		effect.transition = true
+	}
+
+	if state.ShouldNotify(rule.Params().Cooldown, now) {
+		state.MarkNotified(now)
+		effect.notify = true
+	}
+
+	if !effect.transition && !effect.notify {
+		return effect, false
+	}
+	effect.state = state.Snapshot()
+	return effect, true
+}
+
+// applyEffect записывает переход в инцидент и рассылает уведомление
+func (uc *EvaluateAlertRulesUseCase) applyEffect(ctx context.Context, effect alertEffect) {
+	if effect.transition && uc.incidents != nil {
+		if err := uc.incidents.RecordAlertTransition(ctx, effect.rule, effect.state, alertMessage(effect.rule, effect.state)); err != nil {
+			uc.logger.Error("Failed to record incident", err, "rule", effect.rule.Name(), "host", effect.state.Host())
+		}
+	}
+
+	if effect.notify {
+		uc.notify(ctx, effect.rule, effect.state, effect.latest)
+	}
+}
+
+// notify рассылает alert клиентам и публикует событие
+func (uc *EvaluateAlertRulesUseCase) notify(
+	ctx context.Context,
+	rule *entity.AlertRule,
+	state *entity.AlertState,
+	latest *entity.Metric,
+) {
+	message := alertMessage(rule, state)
+	alert := dto.NewRuleAlertDTO(rule, state, latest, message)
+	uc.notifier.BroadcastAlert(alert)
+
+	// Доставляем во внешние каналы согласно маршрутизации правила
+	if uc.dispatcher != nil {
+		uc.dispatcher.Dispatch(ctx, alert, rule.Params().Channels)
+	}
+
+	if state.Status() == entity.AlertFiring {
+		uc.logger.Warn("Alert firing", "rule", rule.Name(), "host", state.Host(), "severity", rule.Severity().String(), "value", state.Value())
+	} else {
+		uc.logger.Info("Alert resolved", "rule", rule.Name(), "host", state.Host(), "value", state.Value())
+	}
+
+	// Публикуем событие алерта в NATS
+	if uc.eventPublisher != nil {
+		event := map[string]interface{}{
+			"event_type":     "alert." + string(state.Status()),
+			"aggregate_id":   rule.ID(),
+			"aggregate_type": "alert_rule",
+			"payload": map[string]interface{}{
+				"rule_name": rule.Name(),
+				"severity":  rule.Severity().String(),
+				"host":      state.Host(),
+				"labels":    state.Labels().Map(),
+				"value":     state.Value(),
+				"threshold": rule.Params().Threshold,
+				"message":   message,
+			},
+			"version": 1,
+		}
+
+		if err := uc.eventPublisher.PublishEvent(ctx, "events.alerts."+string(state.Status()), event); err != nil {
+			uc.logger.Error("Failed to publish alert event", err)
+		}
+	}
+}
+
+// alertMessage формирует текст уведомления о состоянии алерта
+func alertMessage(rule *entity.AlertRule, state *entity.AlertState) string {
+	message := fmt.Sprintf("%s: %s (value %.2f)", rule.Name(), rule.Describe(), state.Value())
+	if state.Status() == entity.AlertResolved {
+		message = fmt.Sprintf("%s resolved (value %.2f)", rule.Name(), state.Value())
+	}
+	if state.Host() != "" {
+		message = fmt.Sprintf("[%s] %s", state.Host(), message)
+	}
+	return message
+}
+
+// pruneStates удаляет состояния правил, которые были удалены или выключены
+func (uc *EvaluateAlertRulesUseCase) pruneStates(rules []*entity.AlertRule) {
+	enabled := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		enabled[rule.ID()] = struct{}{}
+	}
+
+	for key, state := range uc.states {
+		if _, ok := enabled[state.RuleID()]; !ok {
+			delete(uc.states, key)
+		}
+	}
+}
+
+// hasStates проверяет, отслеживаются ли серии правила
+func (uc *EvaluateAlertRulesUseCase) hasStates(rule *entity.AlertRule) bool {
+	for _, state := range uc.states {
+		if state.RuleID() == rule.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny проверяет, относится ли к правилу хотя бы одна метрика
+func matchesAny(rule *entity.AlertRule, metrics []*entity.Metric) bool {
+	for _, metric := range metrics {
+		if rule.Matches(metric) {
+			return true
+		}
+	}
+	return false
+}
+
+func alertStateKey(ruleID, seriesKey string) string {
+	return ruleID + "|" + seriesKey
+}
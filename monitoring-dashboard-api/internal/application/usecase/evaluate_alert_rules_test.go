@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+type alertMockRuleRepository struct {
+	repository.AlertRuleRepository
+	rules []*entity.AlertRule
+}
+
+func (m *alertMockRuleRepository) FindEnabled(_ context.Context) ([]*entity.AlertRule, error) {
+	return m.rules, nil
+}
+
+type alertMockMetricRepository struct {
+	repository.MetricRepository
+	metrics []*entity.Metric
+}
+
+func (m *alertMockMetricRepository) FindByLabels(_ context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	result := make([]*entity.Metric, 0)
+	for _, metric := range m.metrics {
+		if metric.Type() != query.Type || !metric.MatchesSelector(query.Selector) {
+			continue
+		}
+		if query.HasTimeRange() && !query.TimeRange.Contains(metric.CollectedAt()) {
+			continue
+		}
+		result = append(result, metric)
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+type alertMockNotifier struct {
+	alerts    []*dto.AlertDTO
+	incidents []*dto.IncidentDTO
+}
+
+func (m *alertMockNotifier) Broadcast(_ *dto.MetricSnapshotDTO) {}
+
+func (m *alertMockNotifier) BroadcastAlert(alert *dto.AlertDTO) {
+	m.alerts = append(m.alerts, alert)
+}
+
+func (m *alertMockNotifier) BroadcastIncident(incident *dto.IncidentDTO) {
+	m.incidents = append(m.incidents, incident)
+}
+
+func (m *alertMockNotifier) BroadcastProcesses(_ *dto.TopProcessesDTO) {}
+
+func (m *alertMockNotifier) ClientCount() int {
+	return 0
+}
+
+type alertTestEnv struct {
+	uc        *EvaluateAlertRulesUseCase
+	incidents *ManageIncidentsUseCase
+	metrics   *alertMockMetricRepository
+	notifier  *alertMockNotifier
+	now       time.Time
+}
+
+func newAlertTestEnv(t *testing.T, params entity.AlertRuleParams) *alertTestEnv {
+	t.Helper()
+
+	rule, err := entity.NewAlertRule(params)
+	if err != nil {
+		t.Fatalf("failed to build alert rule: %v", err)
+	}
+
+	env := &alertTestEnv{
+		metrics:  &alertMockMetricRepository{},
+		notifier: &alertMockNotifier{},
+		now:      time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	env.incidents = NewManageIncidentsUseCase(newIncidentMockRepository(), env.notifier, logger.New("error"))
+	env.incidents.now = func() time.Time { return env.now }
+	env.uc = NewEvaluateAlertRulesUseCase(
+		&alertMockRuleRepository{rules: []*entity.AlertRule{rule}},
+		env.metrics,
+		service.NewMetricAggregator(),
+		env.notifier,
+		nil,
+		env.incidents,
+		nil,
+		logger.New("error"),
+	)
+	env.uc.now = func() time.Time { return env.now }
+	return env
+}
+
+// step добавляет точку серии host и выполняет оценку правил
+func (e *alertTestEnv) step(t *testing.T, host string, value float64) {
+	t.Helper()
+
+	e.now = e.now.Add(10 * time.Second)
+	metricValue, err := valueobject.NewMetricValue(value, "%")
+	if err != nil {
+		t.Fatalf("failed to build metric value: %v", err)
+	}
+	metric := entity.Reconstruct(host+e.now.String(), valueobject.CPU, "cpu_usage", host, valueobject.Labels{}, metricValue, nil, e.now, e.now)
+	e.metrics.metrics = append(e.metrics.metrics, metric)
+
+	if err := e.uc.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+}
+
+// record добавляет точку серии без оценки правил
+func (e *alertTestEnv) record(t *testing.T, metricType valueobject.MetricType, name, host string, value float64, unit string) *entity.Metric {
+	t.Helper()
+
+	metricValue, err := valueobject.NewMetricValue(value, unit)
+	if err != nil {
+		t.Fatalf("failed to build metric value: %v", err)
+	}
+	metric := entity.Reconstruct(name+host+e.now.String(), metricType, name, host, valueobject.Labels{}, metricValue, nil, e.now, e.now)
+	e.metrics.metrics = append(e.metrics.metrics, metric)
+	return metric
+}
+
+func (e *alertTestEnv) states() []string {
+	states := make([]string, len(e.notifier.alerts))
+	for i, alert := range e.notifier.alerts {
+		states[i] = alert.Host + ":" + alert.State
+	}
+	return states
+}
+
+func assertAlertStates(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected alerts %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected alerts %v, got %v", want, got)
+		}
+	}
+}
+
+func TestEvaluateAlertRulesUseCase_PendingFiringResolved(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "High CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateLast,
+		Window:     time.Minute,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		For:        20 * time.Second,
+		Severity:   valueobject.SeverityCritical,
+		Enabled:    true,
+	})
+
+	env.step(t, "web-1", 95) // pending
+	env.step(t, "web-1", 96) // pending, 10s < for
+	assertAlertStates(t, env.states())
+
+	env.step(t, "web-1", 97) // firing
+	env.step(t, "web-1", 98) // still firing, no repeat
+	assertAlertStates(t, env.states(), "web-1:firing")
+
+	alert := env.notifier.alerts[0]
+	if alert.Level != "critical" || alert.Value != 97 || alert.Threshold != 90 || alert.RuleName != "High CPU" {
+		t.Fatalf("unexpected firing alert: %+v", alert)
+	}
+
+	env.step(t, "web-1", 50) // resolved
+	env.step(t, "web-1", 40)
+	assertAlertStates(t, env.states(), "web-1:firing", "web-1:resolved")
+}
+
+func TestEvaluateAlertRulesUseCase_PendingClearsWithoutNotification(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "High CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateLast,
+		Window:     time.Minute,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		For:        time.Minute,
+		Enabled:    true,
+	})
+
+	env.step(t, "web-1", 95)
+	env.step(t, "web-1", 20)
+	env.step(t, "web-1", 95)
+	assertAlertStates(t, env.states())
+}
+
+func TestEvaluateAlertRulesUseCase_Cooldown(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "High CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateLast,
+		Window:     time.Minute,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		Cooldown:   time.Minute,
+		Enabled:    true,
+	})
+
+	env.step(t, "web-1", 95) // firing
+	env.step(t, "web-1", 10) // resolved
+	env.step(t, "web-1", 95) // firing again, within cooldown
+	env.step(t, "web-1", 10) // resolved silently
+	assertAlertStates(t, env.states(), "web-1:firing", "web-1:resolved")
+
+	for i := 0; i < 6; i++ {
+		env.step(t, "web-1", 10)
+	}
+	env.step(t, "web-1", 95) // cooldown elapsed
+	assertAlertStates(t, env.states(), "web-1:firing", "web-1:resolved", "web-1:firing")
+}
+
+func TestEvaluateAlertRulesUseCase_WindowAggregateAndSeries(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "Sustained CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateAvg,
+		Window:     30 * time.Second,
+		Comparator: valueobject.ComparatorGreaterOrEqual,
+		Threshold:  80,
+		Enabled:    true,
+	})
+
+	// Одиночный всплеск не поднимает среднее окна выше порога
+	env.step(t, "web-1", 50)
+	env.step(t, "web-1", 50)
+	env.step(t, "web-1", 100)
+	env.step(t, "web-2", 85)
+	assertAlertStates(t, env.states(), "web-2:firing")
+
+	// Серия web-2 пропала из окна - алерт разрешается
+	for i := 0; i < 4; i++ {
+		env.step(t, "web-1", 50)
+	}
+	assertAlertStates(t, env.states(), "web-2:firing", "web-2:resolved")
+}
+
+func TestEvaluateAlertRulesUseCase_IgnoresOtherUnits(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "Memory usage critical",
+		MetricType: valueobject.Memory,
+		MetricName: "memory_usage",
+		Unit:       "%",
+		Aggregate:  valueobject.AggregateLast,
+		Window:     time.Minute,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		Enabled:    true,
+	})
+
+	// Серии того же типа и имени в байтах не сравниваются с процентным порогом
+	env.now = env.now.Add(10 * time.Second)
+	batch := []*entity.Metric{
+		env.record(t, valueobject.Memory, "memory_usage", "node-1", 8<<30, "bytes"),
+		env.record(t, valueobject.Memory, "memory_available", "node-1", 4<<30, "bytes"),
+	}
+	if err := env.uc.Execute(context.Background(), batch); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	assertAlertStates(t, env.states())
+
+	env.now = env.now.Add(10 * time.Second)
+	batch = []*entity.Metric{env.record(t, valueobject.Memory, "memory_usage", "node-1", 95, "%")}
+	if err := env.uc.Execute(context.Background(), batch); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	assertAlertStates(t, env.states(), "node-1:firing")
+}
+
+func TestEvaluateAlertRulesUseCase_ResolvesSilentSeries(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "High CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateLast,
+		Window:     30 * time.Second,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		Enabled:    true,
+	})
+
+	env.now = env.now.Add(10 * time.Second)
+	batch := []*entity.Metric{env.record(t, valueobject.CPU, "cpu_usage", "edge-1", 97, "%")}
+	if err := env.uc.Execute(context.Background(), batch); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	assertAlertStates(t, env.states(), "edge-1:firing")
+
+	// Хост edge-1 перестал присылать метрики; пакеты содержат только метрики других типов
+	for i := 0; i < 4; i++ {
+		env.now = env.now.Add(10 * time.Second)
+		batch = []*entity.Metric{env.record(t, valueobject.Memory, "memory_usage", "dashboard", 40, "%")}
+		if err := env.uc.Execute(context.Background(), batch); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+	assertAlertStates(t, env.states(), "edge-1:firing", "edge-1:resolved")
+}
+
+func TestEvaluateAlertRulesUseCase_TruncatedWindowKeepsStates(t *testing.T) {
+	env := newAlertTestEnv(t, entity.AlertRuleParams{
+		Name:       "High CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateLast,
+		Window:     time.Minute,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		Enabled:    true,
+	})
+
+	env.step(t, "web-1", 95)
+	assertAlertStates(t, env.states(), "web-1:firing")
+
+	// Окно не помещается в лимит: правило не оценивается, firing не разрешается по неполным данным
+	env.uc.maxWindowSamples = 2
+	for _, host := range []string{"web-2", "web-3"} {
+		env.record(t, valueobject.CPU, "cpu_usage", host, 10, "%")
+	}
+	env.step(t, "web-4", 10)
+	assertAlertStates(t, env.states(), "web-1:firing")
+
+	env.uc.maxWindowSamples = maxAlertWindowSamples
+	env.step(t, "web-1", 10)
+	assertAlertStates(t, env.states(), "web-1:firing", "web-1:resolved")
+}
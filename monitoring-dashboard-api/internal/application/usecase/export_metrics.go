@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ExportMetricsConfig настройки экспорта последних значений метрик
+type ExportMetricsConfig struct {
+	// Staleness серии без точек за этот период не экспортируются
+	Staleness time.Duration
+
+	// MetadataLabels ключи метаданных, которые экспортируются как метки (mount, cores, interface)
+	MetadataLabels []string
+
+	// PageSize количество строк, читаемых из хранилища за один запрос при выгрузке истории
+	PageSize int
+}
+
+// ExportMetricsUseCase отдает последнее значение каждой серии для внешних систем
+// (например, для scrape в формате Prometheus)
+type ExportMetricsUseCase struct {
+	repository repository.MetricRepository
+	config     ExportMetricsConfig
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewExportMetricsUseCase создает новый use case
+func NewExportMetricsUseCase(
+	repository repository.MetricRepository,
+	config ExportMetricsConfig,
+	logger *logger.Logger,
+) *ExportMetricsUseCase {
+	if config.Staleness <= 0 {
+		config.Staleness = 5 * time.Minute
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 1000
+	}
+
+	return &ExportMetricsUseCase{
+		repository: repository,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Latest возвращает последние значения серий, удовлетворяющих selector, сгруппированные по имени
+// Семейства и серии внутри них отсортированы, чтобы ответ был стабильным между запросами
+func (uc *ExportMetricsUseCase) Latest(ctx context.Context, selector valueobject.LabelSelector) ([]*dto.MetricFamilyDTO, error) {
+	now := uc.now()
+	timeRange, err := valueobject.NewTimeRange(now.Add(-uc.config.Staleness), now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export window: %w", err)
+	}
+
+	metrics, err := uc.repository.FindLatestSeries(ctx, repository.MetricQuery{
+		Selector:  selector,
+		TimeRange: timeRange,
+	})
+	if err != nil {
+		uc.logger.Error("Failed to fetch latest series for export", err)
+		return nil, fmt.Errorf("failed to fetch latest series: %w", err)
+	}
+
+	uc.logger.Debug("Exporting latest series", "count", len(metrics))
+
+	byName := make(map[string]*dto.MetricFamilyDTO)
+	for _, metric := range metrics {
+		family, ok := byName[metric.Name()]
+		if !ok {
+			family = &dto.MetricFamilyDTO{
+				Name: metric.Name(),
+				Help: exportHelp(metric),
+				Unit: metric.Value().Unit(),
+			}
+			byName[metric.Name()] = family
+		}
+		family.Samples = append(family.Samples, dto.MetricSampleDTO{
+			Labels:      uc.sampleLabels(metric),
+			Value:       metric.Value().Raw(),
+			CollectedAt: metric.CollectedAt(),
+		})
+	}
+
+	families := make([]*dto.MetricFamilyDTO, 0, len(byName))
+	for _, family := range byName {
+		sort.Slice(family.Samples, func(i, j int) bool {
+			return labelsKey(family.Samples[i].Labels) < labelsKey(family.Samples[j].Labels)
+		})
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+
+	return families, nil
+}
+
+// Stream выгружает все метрики запроса по возрастанию времени, передавая их в fn по одной
+// Хранилище читается страницами по курсору (collected_at, id), поэтому объем выгрузки не ограничен
+// Возвращает количество переданных метрик; ошибка fn прерывает выгрузку
+func (uc *ExportMetricsUseCase) Stream(
+	ctx context.Context,
+	query repository.MetricQuery,
+	fn func(*dto.MetricDTO) error,
+) (int, error) {
+	query.Limit = uc.config.PageSize
+
+	var cursor *repository.MetricCursor
+	exported := 0
+	for {
+		page, err := uc.repository.FindPage(ctx, query, cursor)
+		if err != nil {
+			uc.logger.Error("Failed to fetch metrics page for export", err, "exported", exported)
+			return exported, fmt.Errorf("failed to fetch metrics page: %w", err)
+		}
+
+		for _, metric := range page {
+			if err := fn(dto.FromEntity(metric)); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if len(page) < query.Limit {
+			return exported, nil
+		}
+
+		last := page[len(page)-1]
+		cursor = &repository.MetricCursor{CollectedAt: last.CollectedAt(), ID: last.ID()}
+	}
+}
+
+// sampleLabels собирает метки серии: host, метки метрики и выбранные ключи метаданных
+// Метки метрики имеют приоритет над одноименными ключами метаданных
+func (uc *ExportMetricsUseCase) sampleLabels(metric *entity.Metric) map[string]string {
+	labels := metric.Labels().Map()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	metadata := metric.Metadata()
+	for _, key := range uc.config.MetadataLabels {
+		if _, ok := labels[key]; ok {
+			continue
+		}
+		value, ok := metadata[key]
+		if !ok || value == nil {
+			continue
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			continue // Вложенные структуры не превращаются в метки
+		}
+		if formatted := fmt.Sprint(value); formatted != "" {
+			labels[key] = formatted
+		}
+	}
+
+	if metric.Host() != "" {
+		labels[valueobject.HostLabel] = metric.Host()
+	}
+
+	return labels
+}
+
+// exportHelp описание семейства из реестра типов метрик
+func exportHelp(metric *entity.Metric) string {
+	if definition, ok := metric.Type().Definition(); ok {
+		return fmt.Sprintf("%s (%s)", definition.DisplayName, metric.Value().Unit())
+	}
+	return fmt.Sprintf("%s (%s)", metric.Name(), metric.Value().Unit())
+}
+
+// labelsKey строит ключ сортировки серий по меткам
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	key := ""
+	for _, name := range names {
+		key += name + "\xff" + labels[name] + "\xff"
+	}
+	return key
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// exportMockRepository возвращает заданные последние серии и запоминает запрос
+type exportMockRepository struct {
+	repository.MetricRepository
+	series  []*entity.Metric
+	query   repository.MetricQuery
+	cursors []*repository.MetricCursor
+}
+
+func (m *exportMockRepository) FindLatestSeries(_ context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	m.query = query
+	return m.series, nil
+}
+
+// FindPage отдает series страницами и запоминает курсоры запросов
+func (m *exportMockRepository) FindPage(_ context.Context, query repository.MetricQuery, after *repository.MetricCursor) ([]*entity.Metric, error) {
+	m.query = query
+	m.cursors = append(m.cursors, after)
+	start := 0
+	if after != nil {
+		for i, metric := range m.series {
+			if metric.ID() == after.ID {
+				start = i + 1
+			}
+		}
+	}
+	end := start + query.Limit
+	if end > len(m.series) {
+		end = len(m.series)
+	}
+	return m.series[start:end], nil
+}
+
+func exportTestMetric(t *testing.T, metricType valueobject.MetricType, name, host string, labels map[string]string, metadata map[string]interface{}, value float64) *entity.Metric {
+	t.Helper()
+	metricValue, err := valueobject.NewMetricValue(value, "%")
+	if err != nil {
+		t.Fatalf("NewMetricValue() error = %v", err)
+	}
+	metricLabels, err := valueobject.NewLabels(labels)
+	if err != nil {
+		t.Fatalf("NewLabels() error = %v", err)
+	}
+	now := time.Now()
+	return entity.Reconstruct(name+host, metricType, name, host, metricLabels, metricValue, metadata, now, now)
+}
+
+func TestExportMetricsGroupsSeriesAndAddsMetadataLabels(t *testing.T) {
+	repo := &exportMockRepository{series: []*entity.Metric{
+		exportTestMetric(t, valueobject.Disk, "disk_usage", "web-2", map[string]string{"mount": "/"}, map[string]interface{}{"mount": "/ignored", "total_gb": 100}, 70),
+		exportTestMetric(t, valueobject.CPU, "cpu_usage", "web-1", nil, map[string]interface{}{"cores": 8, "nested": map[string]interface{}{"a": 1}}, 40),
+		exportTestMetric(t, valueobject.Disk, "disk_usage", "web-1", map[string]string{"mount": "/data"}, nil, 55),
+	}}
+	uc := NewExportMetricsUseCase(repo, ExportMetricsConfig{
+		Staleness:      time.Minute,
+		MetadataLabels: []string{"mount", "cores", "nested"},
+	}, logger.New("error"))
+
+	families, err := uc.Latest(context.Background(), valueobject.LabelSelector{})
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+
+	if window := repo.query.TimeRange.Duration(); window != time.Minute {
+		t.Fatalf("export window = %v, want staleness 1m", window)
+	}
+	if len(families) != 2 || families[0].Name != "cpu_usage" || families[1].Name != "disk_usage" {
+		t.Fatalf("unexpected families: %+v", families)
+	}
+	if families[0].Help != "CPU Usage (%)" || families[0].Unit != "%" {
+		t.Fatalf("unexpected cpu family: %+v", families[0])
+	}
+
+	// total_gb не входит в MetadataLabels, вложенные метаданные пропускаются
+	if got, want := families[0].Samples[0].Labels, map[string]string{"host": "web-1", "cores": "8"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("cpu labels = %v, want %v", got, want)
+	}
+
+	// Серии отсортированы по меткам; метка метрики важнее одноименных метаданных
+	disk := families[1].Samples
+	if len(disk) != 2 || disk[0].Labels["host"] != "web-1" || disk[1].Labels["mount"] != "/" || disk[1].Value != 70 {
+		t.Fatalf("unexpected disk samples: %+v", disk)
+	}
+}
+
+func TestExportMetricsStreamPagesThroughRepository(t *testing.T) {
+	repo := &exportMockRepository{}
+	for i := 0; i < 5; i++ {
+		repo.series = append(repo.series, exportTestMetric(t, valueobject.CPU, "cpu_usage", fmt.Sprintf("web-%d", i), nil, nil, float64(i)))
+	}
+	uc := NewExportMetricsUseCase(repo, ExportMetricsConfig{PageSize: 2}, logger.New("error"))
+
+	var hosts []string
+	exported, err := uc.Stream(context.Background(), repository.MetricQuery{Type: valueobject.CPU}, func(metric *dto.MetricDTO) error {
+		hosts = append(hosts, metric.Host)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if exported != 5 || fmt.Sprint(hosts) != "[web-0 web-1 web-2 web-3 web-4]" {
+		t.Fatalf("exported %d rows: %v", exported, hosts)
+	}
+	// Три страницы: 2 + 2 + 1, каждая следующая - после последней строки предыдущей
+	if len(repo.cursors) != 3 || repo.cursors[0] != nil || repo.cursors[2].ID != repo.series[3].ID() {
+		t.Fatalf("unexpected cursors: %+v", repo.cursors)
+	}
+	if repo.query.Limit != 2 || repo.query.Type != valueobject.CPU {
+		t.Fatalf("unexpected page query: %+v", repo.query)
+	}
+
+	stop := errors.New("client gone")
+	exported, err = uc.Stream(context.Background(), repository.MetricQuery{}, func(*dto.MetricDTO) error { return stop })
+	if !errors.Is(err, stop) || exported != 0 {
+		t.Fatalf("Stream() = %d, %v; want callback error", exported, err)
+	}
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+)
+
+// ErrTooManySamples возвращается, если запрос выбирает больше сырых точек, чем разрешено
+var ErrTooManySamples = errors.New("query selects too many samples")
+
+// findWithinLimit выбирает метрики запроса, но не больше maxSamples
+// Репозиторий без явного лимита молча обрезает выборку; здесь превышение лимита - ошибка
+// ErrTooManySamples, чтобы вызывающий не принял неполные данные за полные
+func findWithinLimit(
+	ctx context.Context,
+	metrics repository.MetricRepository,
+	query repository.MetricQuery,
+	maxSamples int,
+) ([]*entity.Metric, error) {
+	query.Limit = maxSamples + 1
+
+	result, err := metrics.FindByLabels(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > maxSamples {
+		return nil, fmt.Errorf("%w (max %d)", ErrTooManySamples, maxSamples)
+	}
+	return result, nil
+}
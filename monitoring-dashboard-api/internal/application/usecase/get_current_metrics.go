@@ -12,22 +12,33 @@ import (
 // GetCurrentMetricsUseCase возвращает текущие метрики (последние по каждому типу)
 type GetCurrentMetricsUseCase struct {
 	repository repository.MetricRepository
-	logger     *logger.Logger
+
+	// host хост самого dashboard: снимок по умолчанию не смешивает метрики разных агентов
+	host string
+
+	logger *logger.Logger
 }
 
 // NewGetCurrentMetricsUseCase создает новый use case
+// host - хост локально собираемых метрик (пустой - последние метрики каждого типа по всем хостам)
 func NewGetCurrentMetricsUseCase(
 	repository repository.MetricRepository,
+	host string,
 	logger *logger.Logger,
 ) *GetCurrentMetricsUseCase {
 	return &GetCurrentMetricsUseCase{
 		repository: repository,
+		host:       host,
 		logger:     logger,
 	}
 }
 
-// Execute выполняет получение текущих метрик
+// Execute выполняет получение текущих метрик хоста dashboard
 func (uc *GetCurrentMetricsUseCase) Execute(ctx context.Context) (*dto.MetricSnapshotDTO, error) {
+	if uc.host != "" {
+		return uc.ExecuteForHost(ctx, uc.host)
+	}
+
 	uc.logger.Debug("Fetching current metrics")
 
 	// Получаем последние метрики каждого типа
@@ -44,3 +55,23 @@ func (uc *GetCurrentMetricsUseCase) Execute(ctx context.Context) (*dto.MetricSna
 
 	return snapshot, nil
 }
+
+// ExecuteForHost возвращает текущие метрики указанного хоста
+func (uc *GetCurrentMetricsUseCase) ExecuteForHost(ctx context.Context, host string) (*dto.MetricSnapshotDTO, error) {
+	if host == "" {
+		return uc.Execute(ctx)
+	}
+
+	uc.logger.Debug("Fetching current metrics", "host", host)
+
+	latestMetrics, err := uc.repository.FindLatestByHost(ctx, host)
+	if err != nil {
+		uc.logger.Error("Failed to fetch latest metrics for host", err, "host", host)
+		return nil, fmt.Errorf("failed to fetch latest metrics for host: %w", err)
+	}
+
+	snapshot := dto.NewMetricSnapshotDTO(latestMetrics)
+	snapshot.Host = host
+
+	return snapshot, nil
+}
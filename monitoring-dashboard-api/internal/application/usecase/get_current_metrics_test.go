@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// latestMockRepository хранит последние метрики по хостам
+type latestMockRepository struct {
+	repository.MetricRepository
+	byHost map[string]*entity.Metric
+}
+
+func (m *latestMockRepository) FindLatest(_ context.Context) (map[valueobject.MetricType]*entity.Metric, error) {
+	var latest *entity.Metric
+	for _, metric := range m.byHost {
+		if latest == nil || metric.CollectedAt().After(latest.CollectedAt()) {
+			latest = metric
+		}
+	}
+	return map[valueobject.MetricType]*entity.Metric{latest.Type(): latest}, nil
+}
+
+func (m *latestMockRepository) FindLatestByHost(_ context.Context, host string) (map[valueobject.MetricType]*entity.Metric, error) {
+	result := make(map[valueobject.MetricType]*entity.Metric)
+	if metric, ok := m.byHost[host]; ok {
+		result[metric.Type()] = metric
+	}
+	return result, nil
+}
+
+func TestGetCurrentMetricsUsesDashboardHost(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cpu := func(host string, value float64, at time.Time) *entity.Metric {
+		metricValue, _ := valueobject.NewMetricValue(value, "%")
+		return entity.Reconstruct(host, valueobject.CPU, "cpu_usage", host, valueobject.Labels{}, metricValue, nil, at, at)
+	}
+	repo := &latestMockRepository{byHost: map[string]*entity.Metric{
+		"dashboard": cpu("dashboard", 20, now),
+		"edge-1":    cpu("edge-1", 95, now.Add(time.Second)), // агент записал позже
+	}}
+
+	snapshot, err := NewGetCurrentMetricsUseCase(repo, "dashboard", logger.New("error")).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if snapshot.Host != "dashboard" || snapshot.CPU == nil || snapshot.CPU.Value != 20 {
+		t.Fatalf("default snapshot must show the dashboard host: %+v", snapshot)
+	}
+
+	// Без хоста dashboard снимок собирается по всем хостам
+	snapshot, err = NewGetCurrentMetricsUseCase(repo, "", logger.New("error")).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if snapshot.CPU == nil || snapshot.CPU.Value != 95 {
+		t.Fatalf("unexpected snapshot without host: %+v", snapshot)
+	}
+}
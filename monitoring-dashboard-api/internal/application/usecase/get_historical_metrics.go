@@ -2,31 +2,53 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
 )
 
+// GetHistoricalMetricsConfig настройки чтения истории
+type GetHistoricalMetricsConfig struct {
+	// MaxSamples предел сырых точек одного запроса истории (0 - 50000)
+	// Запрос сверх предела не обрезается молча: он строится из агрегатов или завершается ErrTooManySamples
+	MaxSamples int
+}
+
 // GetHistoricalMetricsUseCase возвращает исторические метрики за указанный период
+// Для длинных периодов история строится из уровней агрегации (1m/5m/1h), если они настроены
 type GetHistoricalMetricsUseCase struct {
 	repository repository.MetricRepository
+	rollups    repository.MetricRollupRepository
 	aggregator *service.MetricAggregator
+	config     GetHistoricalMetricsConfig
 	logger     *logger.Logger
 }
 
 // NewGetHistoricalMetricsUseCase создает новый use case
 func NewGetHistoricalMetricsUseCase(
 	repository repository.MetricRepository,
+	rollups repository.MetricRollupRepository, // Can be nil: history is always read from raw metrics
 	aggregator *service.MetricAggregator,
+	config GetHistoricalMetricsConfig,
 	logger *logger.Logger,
 ) *GetHistoricalMetricsUseCase {
+	if config.MaxSamples <= 0 {
+		config.MaxSamples = 50000
+	}
+
 	return &GetHistoricalMetricsUseCase{
 		repository: repository,
+		rollups:    rollups,
 		aggregator: aggregator,
+		config:     config,
 		logger:     logger,
 	}
 }
@@ -71,17 +93,212 @@ func (uc *GetHistoricalMetricsUseCase) ExecuteWithAggregation(
 	metricType valueobject.MetricType,
 	timeRange valueobject.TimeRange,
 ) (*dto.MetricHistoryDTO, error) {
-	// Получаем метрики
-	metrics, err := uc.repository.FindByTimeRange(ctx, metricType, timeRange)
+	return uc.ExecuteWithAggregationForHost(ctx, "", metricType, timeRange)
+}
+
+// ExecuteWithAggregationForHost возвращает исторические метрики хоста с агрегированными данными
+// Пустой host означает выборку по всем хостам
+func (uc *GetHistoricalMetricsUseCase) ExecuteWithAggregationForHost(
+	ctx context.Context,
+	host string,
+	metricType valueobject.MetricType,
+	timeRange valueobject.TimeRange,
+) (*dto.MetricHistoryDTO, error) {
+	query := repository.MetricQuery{Type: metricType, TimeRange: timeRange}
+	if host != "" {
+		hostMatcher, err := valueobject.NewLabelMatcher(valueobject.HostLabel, valueobject.MatchEqual, host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host: %w", err)
+		}
+		query.Selector = query.Selector.With(hostMatcher)
+	}
+
+	history, err := uc.findHistory(ctx, metricType, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch historical metrics: %w", err)
 	}
+	return history, nil
+}
+
+// ExecuteWithSelector возвращает исторические метрики, отфильтрованные селектором меток
+// Ненулевой step возвращает точки, агрегированные в бакеты этого шага на стороне сервера;
+// при нулевом шаге разрешение выбирается по длительности периода
+func (uc *GetHistoricalMetricsUseCase) ExecuteWithSelector(
+	ctx context.Context,
+	metricType valueobject.MetricType,
+	selector valueobject.LabelSelector,
+	timeRange valueobject.TimeRange,
+	step time.Duration,
+) (*dto.MetricHistoryDTO, error) {
+	query := repository.MetricQuery{
+		Type:      metricType,
+		Selector:  selector,
+		TimeRange: timeRange,
+	}
+
+	if step > 0 {
+		tier, buckets, err := uc.findBuckets(ctx, query, step)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch historical metrics: %w", err)
+		}
+		return uc.buildStepHistory(metricType, tier, step, buckets), nil
+	}
+
+	history, err := uc.findHistory(ctx, metricType, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch historical metrics: %w", err)
+	}
+	return history, nil
+}
+
+// findHistory строит историю запроса из уровня, выбранного по длительности периода
+// Если сырых точек короткого периода больше MaxSamples (селектор выбирает много серий),
+// история строится из минутных агрегатов; без агрегатов возвращается ErrTooManySamples
+func (uc *GetHistoricalMetricsUseCase) findHistory(
+	ctx context.Context,
+	metricType valueobject.MetricType,
+	query repository.MetricQuery,
+) (*dto.MetricHistoryDTO, error) {
+	tier := uc.selectTier(query.TimeRange)
+	if tier == valueobject.TierRaw {
+		metrics, err := uc.findRaw(ctx, query)
+		if err == nil {
+			return uc.buildHistory(metricType, metrics), nil
+		}
+		if !errors.Is(err, ErrTooManySamples) || uc.rollups == nil {
+			return nil, err
+		}
+		tier = valueobject.Tier1m
+	}
+
+	tier, rollups, err := uc.findRollupsWithFallback(ctx, tier, query)
+	if err != nil {
+		return nil, err
+	}
+	return uc.buildRollupHistory(metricType, tier, rollups), nil
+}
+
+// findRaw выбирает сырые точки запроса, не больше MaxSamples
+func (uc *GetHistoricalMetricsUseCase) findRaw(ctx context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	return findWithinLimit(ctx, uc.repository, query, uc.config.MaxSamples)
+}
+
+// ExecuteGroupedByLabel возвращает исторические метрики, сгруппированные по значению метки
+// Серии отсортированы по значению метки; step имеет тот же смысл, что и в ExecuteWithSelector
+func (uc *GetHistoricalMetricsUseCase) ExecuteGroupedByLabel(
+	ctx context.Context,
+	metricType valueobject.MetricType,
+	selector valueobject.LabelSelector,
+	timeRange valueobject.TimeRange,
+	label string,
+	step time.Duration,
+) (*dto.MetricGroupedHistoryDTO, error) {
+	if err := valueobject.ValidateLabelName(label); err != nil {
+		return nil, fmt.Errorf("invalid group label: %w", err)
+	}
+
+	query := repository.MetricQuery{
+		Type:      metricType,
+		Selector:  selector,
+		TimeRange: timeRange,
+	}
+
+	histories := make(map[string]*dto.MetricHistoryDTO)
+	if step > 0 {
+		tier, buckets, err := uc.findBuckets(ctx, query, step)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch grouped metrics: %w", err)
+		}
+		for value, group := range groupRollupsByLabel(buckets, label) {
+			histories[value] = uc.buildStepHistory(metricType, tier, step, group)
+		}
+	} else {
+		tier := uc.selectTier(timeRange)
+		if tier == valueobject.TierRaw {
+			groups, err := uc.findRawGroups(ctx, query, label)
+			switch {
+			case err == nil:
+				for value, group := range groups {
+					histories[value] = uc.buildHistory(metricType, group)
+				}
+			case errors.Is(err, ErrTooManySamples) && uc.rollups != nil:
+				// Слишком много сырых точек: группы строятся из минутных агрегатов
+				tier = valueobject.Tier1m
+			default:
+				return nil, fmt.Errorf("failed to fetch grouped metrics: %w", err)
+			}
+		}
+		if tier != valueobject.TierRaw {
+			tier, rollups, err := uc.findRollupsWithFallback(ctx, tier, query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch grouped metrics: %w", err)
+			}
+			for value, group := range groupRollupsByLabel(rollups, label) {
+				histories[value] = uc.buildRollupHistory(metricType, tier, group)
+			}
+		}
+	}
 
+	values := make([]string, 0, len(histories))
+	for value := range histories {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	series := make([]*dto.MetricSeriesDTO, 0, len(values))
+	for _, value := range values {
+		series = append(series, &dto.MetricSeriesDTO{
+			Value:   value,
+			History: histories[value],
+		})
+	}
+
+	result := &dto.MetricGroupedHistoryDTO{
+		Type:    metricType.String(),
+		GroupBy: label,
+		Series:  series,
+	}
+	if !selector.IsEmpty() {
+		result.Selector = selector.String()
+	}
+
+	return result, nil
+}
+
+// findRawGroups выбирает сырые точки запроса, сгруппированные по значению метки, не больше MaxSamples в сумме
+func (uc *GetHistoricalMetricsUseCase) findRawGroups(
+	ctx context.Context,
+	query repository.MetricQuery,
+	label string,
+) (map[string][]*entity.Metric, error) {
+	query.Limit = uc.config.MaxSamples + 1
+
+	groups, err := uc.repository.GroupByLabel(ctx, query, label)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, group := range groups {
+		total += len(group)
+	}
+	if total > uc.config.MaxSamples {
+		return nil, fmt.Errorf("%w (max %d)", ErrTooManySamples, uc.config.MaxSamples)
+	}
+	return groups, nil
+}
+
+// buildHistory вычисляет агрегаты и собирает MetricHistoryDTO
+func (uc *GetHistoricalMetricsUseCase) buildHistory(
+	metricType valueobject.MetricType,
+	metrics []*entity.Metric,
+) *dto.MetricHistoryDTO {
 	if len(metrics) == 0 {
 		return &dto.MetricHistoryDTO{
-			Type:    metricType.String(),
-			Metrics: []*dto.MetricDTO{},
-		}, nil
+			Type:       metricType.String(),
+			Metrics:    []*dto.MetricDTO{},
+			Resolution: valueobject.TierRaw.String(),
+		}
 	}
 
 	// Вычисляем агрегаты
@@ -104,5 +321,193 @@ func (uc *GetHistoricalMetricsUseCase) ExecuteWithAggregation(
 		Max:           max,
 		CriticalCount: len(critical),
 		WarningCount:  len(warnings),
-	}, nil
+		Resolution:    valueobject.TierRaw.String(),
+	}
+}
+
+// selectTier выбирает уровень агрегации для периода
+// Без хранилища агрегатов история всегда читается из сырых метрик
+func (uc *GetHistoricalMetricsUseCase) selectTier(timeRange valueobject.TimeRange) valueobject.RollupTier {
+	if uc.rollups == nil {
+		return valueobject.TierRaw
+	}
+	return valueobject.SelectRollupTier(timeRange.Duration())
+}
+
+// groupRollupsByLabel группирует бакеты по значению метки
+func groupRollupsByLabel(rollups []*entity.MetricRollup, label string) map[string][]*entity.MetricRollup {
+	groups := make(map[string][]*entity.MetricRollup)
+	for _, rollup := range rollups {
+		key := rollup.LabelValue(label)
+		groups[key] = append(groups[key], rollup)
+	}
+	return groups
+}
+
+// findBuckets возвращает точки периода, агрегированные в бакеты шага step, и уровень, из которого они построены
+// Уровень выбирается по шагу: его бакеты должны без остатка складываться в бакеты шага
+func (uc *GetHistoricalMetricsUseCase) findBuckets(
+	ctx context.Context,
+	query repository.MetricQuery,
+	step time.Duration,
+) (valueobject.RollupTier, []*entity.MetricRollup, error) {
+	tier := valueobject.TierRaw
+	if uc.rollups != nil {
+		tier = valueobject.SelectRollupTierForStep(step)
+	}
+
+	if tier == valueobject.TierRaw {
+		// Без агрегатов или с шагом, не кратным минуте, бакеты не собрать из уровней,
+		// поэтому превышение MaxSamples здесь всегда ошибка
+		metrics, err := uc.findRaw(ctx, query)
+		if err != nil {
+			return tier, nil, err
+		}
+		buckets := uc.aggregator.BucketByStep(metrics, query.TimeRange, step)
+		if len(buckets) > 0 || uc.rollups == nil {
+			return tier, buckets, nil
+		}
+		// Сырые точки периода уже удалены политикой хранения, остаются только агрегаты
+		tier = valueobject.Tier1m
+	}
+
+	tier, rollups, err := uc.findRollupsWithFallback(ctx, tier, query)
+	if err != nil {
+		return tier, nil, err
+	}
+
+	resolution := tier.Resolution()
+	if step > resolution && step%resolution == 0 {
+		rollups = uc.aggregator.MergeRollups(rollups, step)
+	}
+
+	return tier, rollups, nil
+}
+
+// findRollupsWithFallback возвращает бакеты уровня tier, а если их нет - ближайшего более крупного уровня
+// У мелких уровней срок хранения короче, и для старых периодов данные остаются только в крупных
+func (uc *GetHistoricalMetricsUseCase) findRollupsWithFallback(
+	ctx context.Context,
+	tier valueobject.RollupTier,
+	query repository.MetricQuery,
+) (valueobject.RollupTier, []*entity.MetricRollup, error) {
+	tiers := valueobject.RollupTiers()
+	for i, candidate := range tiers {
+		if candidate != tier {
+			continue
+		}
+
+		for _, fallback := range tiers[i:] {
+			rollups, err := uc.findRollups(ctx, fallback, query)
+			if err != nil {
+				return fallback, nil, err
+			}
+			if len(rollups) > 0 {
+				return fallback, rollups, nil
+			}
+		}
+		break
+	}
+
+	return tier, nil, nil
+}
+
+// findRollups возвращает бакеты уровня за период запроса
+// Хвост периода после watermark уровня (еще не обработанный воркером) агрегируется из сырых метрик
+func (uc *GetHistoricalMetricsUseCase) findRollups(
+	ctx context.Context,
+	tier valueobject.RollupTier,
+	query repository.MetricQuery,
+) ([]*entity.MetricRollup, error) {
+	watermark, err := uc.rollups.Watermark(ctx, tier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s rollup watermark: %w", tier, err)
+	}
+
+	var rollups []*entity.MetricRollup
+	if watermark.After(query.TimeRange.Start()) {
+		rollups, err = uc.rollups.FindRollups(ctx, tier, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s rollups: %w", tier, err)
+		}
+	}
+
+	tailStart := query.TimeRange.Start()
+	if watermark.After(tailStart) {
+		tailStart = watermark
+	}
+	if tailStart.Before(query.TimeRange.End()) {
+		tailRange, err := valueobject.NewTimeRange(tailStart, query.TimeRange.End())
+		if err != nil {
+			return nil, fmt.Errorf("invalid tail time range: %w", err)
+		}
+		tailQuery := query
+		tailQuery.TimeRange = tailRange
+
+		metrics, err := uc.findRaw(ctx, tailQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch raw metrics after %s watermark: %w", tier, err)
+		}
+		rollups = append(rollups, uc.aggregator.RollupByBucket(metrics, tier.Resolution())...)
+	}
+
+	uc.logger.Debug("Fetched rollups",
+		"tier", tier.String(),
+		"watermark", watermark,
+		"count", len(rollups))
+
+	return rollups, nil
+}
+
+// buildStepHistory собирает MetricHistoryDTO из бакетов шага step
+func (uc *GetHistoricalMetricsUseCase) buildStepHistory(
+	metricType valueobject.MetricType,
+	tier valueobject.RollupTier,
+	step time.Duration,
+	buckets []*entity.MetricRollup,
+) *dto.MetricHistoryDTO {
+	history := uc.buildRollupHistory(metricType, tier, buckets)
+	history.StepSeconds = step.Seconds()
+	return history
+}
+
+// buildRollupHistory вычисляет агрегаты по бакетам и собирает MetricHistoryDTO
+// Критические и предупреждающие значения считаются по среднему бакета
+func (uc *GetHistoricalMetricsUseCase) buildRollupHistory(
+	metricType valueobject.MetricType,
+	tier valueobject.RollupTier,
+	rollups []*entity.MetricRollup,
+) *dto.MetricHistoryDTO {
+	if len(rollups) == 0 {
+		return &dto.MetricHistoryDTO{
+			Type:       metricType.String(),
+			Metrics:    []*dto.MetricDTO{},
+			Resolution: tier.String(),
+		}
+	}
+
+	avg, min, max, _ := uc.aggregator.SummarizeRollups(rollups)
+
+	var criticalCount, warningCount int
+	for _, rollup := range rollups {
+		switch {
+		case rollup.IsCritical():
+			criticalCount++
+		case rollup.IsWarning():
+			warningCount++
+		}
+	}
+
+	sortedRollups := uc.aggregator.SortRollupsByTime(rollups, false)
+
+	return &dto.MetricHistoryDTO{
+		Type:          metricType.String(),
+		Metrics:       dto.ToRollupDTOs(sortedRollups),
+		Average:       avg,
+		Min:           min,
+		Max:           max,
+		CriticalCount: criticalCount,
+		WarningCount:  warningCount,
+		Resolution:    tier.String(),
+	}
 }
@@ -18,6 +18,7 @@ type GetHistoricalMetricsCachedUseCase struct {
 	repository repository.MetricRepository
 	aggregator *service.MetricAggregator
 	cache      port.Cache
+	metrics    port.ServiceMetrics // Optional: counts cache hits and misses
 	logger     *logger.Logger
 }
 
@@ -26,12 +27,14 @@ func NewGetHistoricalMetricsCachedUseCase(
 	repository repository.MetricRepository,
 	aggregator *service.MetricAggregator,
 	cache port.Cache,
+	metrics port.ServiceMetrics, // Can be nil if /metrics disabled
 	logger *logger.Logger,
 ) *GetHistoricalMetricsCachedUseCase {
 	return &GetHistoricalMetricsCachedUseCase{
 		repository: repository,
 		aggregator: aggregator,
 		cache:      cache,
+		metrics:    metrics,
 		logger:     logger,
 	}
 }
@@ -59,6 +62,7 @@ func (uc *GetHistoricalMetricsCachedUseCase) Execute(
 	// Пытаемся получить из кеша
 	var cachedDTOs []*dto.MetricDTO
 	err := uc.cache.Get(ctx, cacheKey, &cachedDTOs)
+	uc.recordCacheLookup(err == nil)
 	if err == nil {
 		uc.logger.Debug("Cache hit for historical metrics",
 			"type", metricType.String(),
@@ -85,6 +89,18 @@ func (uc *GetHistoricalMetricsCachedUseCase) Execute(
 	return dtos, nil
 }
 
+// recordCacheLookup учитывает попадание или промах кеша, если инструментирование включено
+func (uc *GetHistoricalMetricsCachedUseCase) recordCacheLookup(hit bool) {
+	if uc.metrics == nil {
+		return
+	}
+	if hit {
+		uc.metrics.CacheHit()
+	} else {
+		uc.metrics.CacheMiss()
+	}
+}
+
 // executeWithoutCache получает метрики без кеширования
 func (uc *GetHistoricalMetricsCachedUseCase) executeWithoutCache(
 	ctx context.Context,
@@ -132,6 +148,7 @@ func (uc *GetHistoricalMetricsCachedUseCase) ExecuteWithAggregation(
 	// Пытаемся получить из кеша
 	var cachedHistory *dto.MetricHistoryDTO
 	err := uc.cache.Get(ctx, cacheKey, &cachedHistory)
+	uc.recordCacheLookup(err == nil)
 	if err == nil {
 		uc.logger.Debug("Cache hit for aggregated historical metrics",
 			"type", metricType.String())
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// historyMockRepository возвращает сырые метрики и запоминает запросы
+type historyMockRepository struct {
+	repository.MetricRepository
+	metrics []*entity.Metric
+	queries []repository.MetricQuery
+}
+
+func (m *historyMockRepository) FindByLabels(_ context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	m.queries = append(m.queries, query)
+	var result []*entity.Metric
+	for _, metric := range m.metrics {
+		if query.TimeRange.Contains(metric.CollectedAt()) && metric.MatchesSelector(query.Selector) {
+			result = append(result, metric)
+		}
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+func (m *historyMockRepository) GroupByLabel(ctx context.Context, query repository.MetricQuery, label string) (map[string][]*entity.Metric, error) {
+	metrics, _ := m.FindByLabels(ctx, query)
+	groups := make(map[string][]*entity.Metric)
+	for _, metric := range metrics {
+		groups[metric.LabelValue(label)] = append(groups[metric.LabelValue(label)], metric)
+	}
+	return groups, nil
+}
+
+func (m *historyMockRepository) FindByTimeRange(ctx context.Context, metricType valueobject.MetricType, timeRange valueobject.TimeRange) ([]*entity.Metric, error) {
+	return m.FindByLabels(ctx, repository.MetricQuery{Type: metricType, TimeRange: timeRange})
+}
+
+func historyTestMetric(t *testing.T, host string, value float64, at time.Time) *entity.Metric {
+	t.Helper()
+	metricValue, err := valueobject.NewMetricValue(value, "%")
+	if err != nil {
+		t.Fatalf("NewMetricValue() error = %v", err)
+	}
+	return entity.Reconstruct(at.String(), valueobject.CPU, "cpu_usage", host, valueobject.Labels{}, metricValue, nil, at, at)
+}
+
+func TestGetHistoricalMetricsUsesRollupTierWithRawTail(t *testing.T) {
+	end := time.Now().UTC().Truncate(time.Minute).Add(30 * time.Second)
+	watermark := end.Add(-90 * time.Second).Truncate(time.Minute)
+
+	rollups := newRollupMockRepository()
+	rollups.watermarks[valueobject.Tier1m] = watermark
+	for i := 1; i <= 3; i++ {
+		rollups.rollups[valueobject.Tier1m] = append(rollups.rollups[valueobject.Tier1m], &entity.MetricRollup{
+			Type:        valueobject.CPU,
+			Name:        "cpu_usage",
+			Host:        "web-1",
+			Unit:        "%",
+			BucketStart: watermark.Add(-time.Duration(i) * time.Minute),
+			Min:         10,
+			Max:         30,
+			Sum:         60,
+			Count:       3,
+			Last:        30,
+		})
+	}
+
+	raw := &historyMockRepository{metrics: []*entity.Metric{
+		historyTestMetric(t, "web-1", 10, watermark.Add(-10*time.Second)), // уже в бакете 1m
+		historyTestMetric(t, "web-1", 40, watermark.Add(5*time.Second)),
+		historyTestMetric(t, "web-1", 60, watermark.Add(20*time.Second)),
+		historyTestMetric(t, "web-2", 95, watermark.Add(20*time.Second)),
+	}}
+
+	uc := NewGetHistoricalMetricsUseCase(raw, rollups, service.NewMetricAggregator(), GetHistoricalMetricsConfig{}, logger.New("error"))
+	timeRange, err := valueobject.NewTimeRange(end.Add(-6*time.Hour), end)
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+
+	history, err := uc.ExecuteWithAggregationForHost(context.Background(), "web-1", valueobject.CPU, timeRange)
+	if err != nil {
+		t.Fatalf("ExecuteWithAggregationForHost() error = %v", err)
+	}
+
+	if history.Resolution != "1m" {
+		t.Fatalf("expected 1m resolution for 6h range, got %q", history.Resolution)
+	}
+	// 3 бакета из уровня + 1 бакет, собранный из сырых точек после watermark
+	if len(history.Metrics) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(history.Metrics))
+	}
+	tail := history.Metrics[3]
+	if !tail.CollectedAt.Equal(watermark) || tail.Value != 50 || tail.Rollup == nil || tail.Rollup.Count != 2 {
+		t.Fatalf("unexpected tail bucket: %+v", tail)
+	}
+	// Среднее взвешено по количеству точек: (3*60 + 100) / (9 + 2)
+	if history.Min != 10 || history.Max != 60 || history.Average != 280.0/11 {
+		t.Fatalf("unexpected aggregates: avg=%v min=%v max=%v", history.Average, history.Min, history.Max)
+	}
+	if len(raw.queries) != 1 || !raw.queries[0].TimeRange.Start().Equal(watermark) {
+		t.Fatalf("raw tail must start at the watermark: %+v", raw.queries)
+	}
+}
+
+func TestGetHistoricalMetricsShortRangeReadsRawMetrics(t *testing.T) {
+	end := time.Now().UTC()
+	rollups := newRollupMockRepository()
+	raw := &historyMockRepository{metrics: []*entity.Metric{
+		historyTestMetric(t, "", 20, end.Add(-10*time.Minute)),
+		historyTestMetric(t, "", 30, end.Add(-5*time.Minute)),
+	}}
+
+	uc := NewGetHistoricalMetricsUseCase(raw, rollups, service.NewMetricAggregator(), GetHistoricalMetricsConfig{}, logger.New("error"))
+	timeRange, err := valueobject.NewTimeRange(end.Add(-time.Hour), end)
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+
+	history, err := uc.ExecuteWithAggregation(context.Background(), valueobject.CPU, timeRange)
+	if err != nil {
+		t.Fatalf("ExecuteWithAggregation() error = %v", err)
+	}
+	if history.Resolution != "raw" || len(history.Metrics) != 2 || history.Metrics[0].Rollup != nil {
+		t.Fatalf("expected raw points for 1h range, got %+v", history)
+	}
+}
+
+func TestGetHistoricalMetricsShortRangeOverLimitFallsBackToRollups(t *testing.T) {
+	end := time.Now().UTC().Truncate(time.Minute)
+	raw := &historyMockRepository{}
+	for i := 1; i <= 3; i++ {
+		raw.metrics = append(raw.metrics, historyTestMetric(t, "web-1", 10, end.Add(-time.Duration(i)*10*time.Second)))
+	}
+	timeRange, err := valueobject.NewTimeRange(end.Add(-30*time.Minute), end)
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+
+	// Без rollup-уровней превышение лимита - явная ошибка, а не обрезанный ряд
+	uc := NewGetHistoricalMetricsUseCase(raw, nil, service.NewMetricAggregator(), GetHistoricalMetricsConfig{MaxSamples: 2}, logger.New("error"))
+	if _, err := uc.ExecuteWithSelector(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, 0); !errors.Is(err, ErrTooManySamples) {
+		t.Fatalf("expected ErrTooManySamples, got %v", err)
+	}
+
+	rollups := newRollupMockRepository()
+	rollups.watermarks[valueobject.Tier1m] = end
+	rollups.rollups[valueobject.Tier1m] = []*entity.MetricRollup{{
+		Type:        valueobject.CPU,
+		Name:        "cpu_usage",
+		Host:        "web-1",
+		Unit:        "%",
+		BucketStart: end.Add(-time.Minute),
+		Min:         10,
+		Max:         10,
+		Sum:         30,
+		Count:       3,
+		Last:        10,
+	}}
+	uc = NewGetHistoricalMetricsUseCase(raw, rollups, service.NewMetricAggregator(), GetHistoricalMetricsConfig{MaxSamples: 2}, logger.New("error"))
+	history, err := uc.ExecuteWithSelector(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, 0)
+	if err != nil {
+		t.Fatalf("ExecuteWithSelector() error = %v", err)
+	}
+	if history.Resolution != "1m" || len(history.Metrics) != 1 || history.Metrics[0].Rollup.Count != 3 {
+		t.Fatalf("expected fallback to 1m rollups, got %+v", history)
+	}
+}
+
+func TestGetHistoricalMetricsStepMergesCoarserTierWhenFinerIsPruned(t *testing.T) {
+	start := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+	rollups := newRollupMockRepository()
+	for _, tier := range valueobject.RollupTiers() {
+		rollups.watermarks[tier] = start.Add(24 * time.Hour)
+	}
+	// Уровень 1m за этот период уже удален, остались бакеты 5m
+	for i, value := range []float64{10, 20, 30, 70} {
+		rollups.rollups[valueobject.Tier5m] = append(rollups.rollups[valueobject.Tier5m], &entity.MetricRollup{
+			Type:        valueobject.CPU,
+			Name:        "cpu_usage",
+			Host:        "web-1",
+			Unit:        "%",
+			BucketStart: start.Add(time.Duration(i) * 5 * time.Minute),
+			Min:         value,
+			Max:         value,
+			Sum:         value * 5,
+			Count:       5,
+			Last:        value,
+			LastAt:      start.Add(time.Duration(i)*5*time.Minute + 4*time.Minute),
+		})
+	}
+
+	uc := NewGetHistoricalMetricsUseCase(&historyMockRepository{}, rollups, service.NewMetricAggregator(), GetHistoricalMetricsConfig{}, logger.New("error"))
+	timeRange, err := valueobject.NewTimeRange(start, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+
+	history, err := uc.ExecuteWithSelector(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("ExecuteWithSelector() error = %v", err)
+	}
+
+	if history.Resolution != "5m" || history.StepSeconds != 900 {
+		t.Fatalf("expected 15m buckets built from 5m tier, got resolution=%q step=%v", history.Resolution, history.StepSeconds)
+	}
+	if len(history.Metrics) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(history.Metrics))
+	}
+	first, second := history.Metrics[0], history.Metrics[1]
+	if first.Value != 20 || first.Rollup.Count != 15 || first.Rollup.Min != 10 || first.Rollup.Max != 30 || first.Rollup.Last != 30 {
+		t.Fatalf("unexpected first bucket: %+v %+v", first, first.Rollup)
+	}
+	if !second.CollectedAt.Equal(start.Add(15*time.Minute)) || second.Value != 70 {
+		t.Fatalf("unexpected second bucket: %+v", second)
+	}
+}
+
+func TestGetHistoricalMetricsRawStepBuckets(t *testing.T) {
+	start := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+	raw := &historyMockRepository{metrics: []*entity.Metric{
+		historyTestMetric(t, "web-1", 10, start.Add(5*time.Second)),
+		historyTestMetric(t, "web-1", 20, start.Add(25*time.Second)),
+		historyTestMetric(t, "web-1", 60, start.Add(35*time.Second)),
+		historyTestMetric(t, "web-1", 99, start.Add(2*time.Minute)), // вне периода
+	}}
+
+	uc := NewGetHistoricalMetricsUseCase(raw, newRollupMockRepository(), service.NewMetricAggregator(), GetHistoricalMetricsConfig{}, logger.New("error"))
+	timeRange, err := valueobject.NewTimeRange(start, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+
+	history, err := uc.ExecuteWithSelector(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, 30*time.Second)
+	if err != nil {
+		t.Fatalf("ExecuteWithSelector() error = %v", err)
+	}
+	if history.Resolution != "raw" || len(history.Metrics) != 2 {
+		t.Fatalf("expected 2 raw 30s buckets, got %+v", history)
+	}
+	if history.Metrics[0].Value != 15 || history.Metrics[1].Value != 60 || history.Max != 60 {
+		t.Fatalf("unexpected buckets: %v, %v (max %v)", history.Metrics[0].Value, history.Metrics[1].Value, history.Max)
+	}
+}
+
+func TestGetHistoricalMetricsRawBucketsAndGroupsOverLimit(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Minute).Add(-10 * time.Minute)
+	raw := &historyMockRepository{}
+	for i, host := range []string{"web-1", "web-2", "web-3"} {
+		raw.metrics = append(raw.metrics, historyTestMetric(t, host, 10, start.Add(time.Duration(i+1)*time.Second)))
+	}
+	timeRange, err := valueobject.NewTimeRange(start, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+
+	rollups := newRollupMockRepository()
+	rollups.watermarks[valueobject.Tier1m] = start.Add(time.Minute)
+	for _, host := range []string{"web-1", "web-2", "web-3"} {
+		rollups.rollups[valueobject.Tier1m] = append(rollups.rollups[valueobject.Tier1m], &entity.MetricRollup{
+			Type:        valueobject.CPU,
+			Name:        "cpu_usage",
+			Host:        host,
+			Unit:        "%",
+			BucketStart: start,
+			Min:         10,
+			Max:         10,
+			Sum:         10,
+			Count:       1,
+			Last:        10,
+		})
+	}
+	uc := NewGetHistoricalMetricsUseCase(raw, rollups, service.NewMetricAggregator(), GetHistoricalMetricsConfig{MaxSamples: 2}, logger.New("error"))
+
+	// Шаг 30s не собрать из минутных агрегатов: вместо обрезанных бакетов - ошибка
+	if _, err := uc.ExecuteWithSelector(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, 30*time.Second); !errors.Is(err, ErrTooManySamples) {
+		t.Fatalf("expected ErrTooManySamples for raw step buckets, got %v", err)
+	}
+
+	grouped, err := uc.ExecuteGroupedByLabel(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, valueobject.HostLabel, 0)
+	if err != nil {
+		t.Fatalf("ExecuteGroupedByLabel() error = %v", err)
+	}
+	if len(grouped.Series) != 3 {
+		t.Fatalf("expected all 3 hosts from 1m rollups, got %d series", len(grouped.Series))
+	}
+	for _, series := range grouped.Series {
+		if series.History.Resolution != "1m" || len(series.History.Metrics) != 1 {
+			t.Fatalf("unexpected series %q: %+v", series.Value, series.History)
+		}
+	}
+
+	uc = NewGetHistoricalMetricsUseCase(raw, nil, service.NewMetricAggregator(), GetHistoricalMetricsConfig{MaxSamples: 2}, logger.New("error"))
+	if _, err := uc.ExecuteGroupedByLabel(context.Background(), valueobject.CPU, valueobject.LabelSelector{}, timeRange, valueobject.HostLabel, 0); !errors.Is(err, ErrTooManySamples) {
+		t.Fatalf("expected ErrTooManySamples without rollups, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ErrInvalidImportFile возвращается, если файл импорта поврежден и не читается дальше
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// ImportMetricsConfig настройки импорта истории метрик
+type ImportMetricsConfig struct {
+	// BatchSize строк в одной записи SaveBatch
+	BatchSize int
+
+	// MaxReportedErrors ошибок строк в отчете; остальные только считаются
+	MaxReportedErrors int
+}
+
+// ImportMetricsUseCase загружает историю метрик из файла (перенос хостов, бэкфилл)
+// Строки проверяются тем же MetricValidator, что и прием от агентов; точки из прошлого допустимы.
+// Точки, уже имеющиеся в хранилище или повторяющиеся в файле, пропускаются, поэтому повторный импорт безопасен
+type ImportMetricsUseCase struct {
+	repository repository.MetricRepository
+	validator  *service.MetricValidator
+	rollups    *RollupMetricsUseCase // Optional: пересчет уровней агрегации за период импорта
+	config     ImportMetricsConfig
+	logger     *logger.Logger
+}
+
+// NewImportMetricsUseCase создает новый use case
+// repository должен писать синхронно (не через буфер записи): дедупликация следующей пачки
+// сверяется с уже записанными точками
+func NewImportMetricsUseCase(
+	repository repository.MetricRepository,
+	validator *service.MetricValidator,
+	rollups *RollupMetricsUseCase, // Can be nil if rollups disabled
+	config ImportMetricsConfig,
+	logger *logger.Logger,
+) *ImportMetricsUseCase {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	if config.MaxReportedErrors <= 0 {
+		config.MaxReportedErrors = 100
+	}
+
+	return &ImportMetricsUseCase{
+		repository: repository,
+		validator:  validator,
+		rollups:    rollups,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// importCandidate строка, прошедшая валидацию
+type importCandidate struct {
+	line   int
+	key    string
+	metric *entity.Metric
+}
+
+// Execute читает строки reader и записывает их пачками
+// В dry-run строки проверяются (включая дубликаты в хранилище), но не записываются.
+// Результат возвращается и вместе с ошибкой: пачки до ошибки уже записаны
+func (uc *ImportMetricsUseCase) Execute(ctx context.Context, reader port.MetricRowReader, dryRun bool) (*dto.ImportMetricsResultDTO, error) {
+	result := &dto.ImportMetricsResultDTO{
+		DryRun: dryRun,
+		Errors: []dto.ImportRowErrorDTO{},
+	}
+
+	batch := make([]importCandidate, 0, uc.config.BatchSize)
+	seen := make(map[string]struct{}, uc.config.BatchSize)
+	var from, to time.Time
+
+	flush := func() error {
+		imported, err := uc.writeBatch(ctx, batch, dryRun, result)
+		if err != nil {
+			return err
+		}
+		for _, candidate := range imported {
+			at := candidate.metric.CollectedAt()
+			if from.IsZero() || at.Before(from) {
+				from = at
+			}
+			if to.IsZero() || at.After(to) {
+				to = at
+			}
+		}
+		batch = batch[:0]
+		// Записанные пачки находит проверка хранилища; в dry-run ничего не пишется, поэтому ключи копятся за весь файл
+		if !dryRun {
+			clear(seen)
+		}
+		return nil
+	}
+
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if flushErr := flush(); flushErr != nil {
+				err = flushErr
+			} else {
+				err = fmt.Errorf("%w: %w", ErrInvalidImportFile, err)
+			}
+			return uc.finish(ctx, result, from, to, err)
+		}
+
+		result.Rows++
+		candidate, err := uc.prepare(row)
+		if err != nil {
+			uc.reject(result, row.Line, err)
+			continue
+		}
+
+		// Повтор в текущей пачке (в dry-run - во всем файле)
+		if _, duplicate := seen[candidate.key]; duplicate {
+			result.Duplicates++
+			continue
+		}
+		seen[candidate.key] = struct{}{}
+		batch = append(batch, candidate)
+
+		if len(batch) >= uc.config.BatchSize {
+			if err := flush(); err != nil {
+				return uc.finish(ctx, result, from, to, err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return uc.finish(ctx, result, from, to, err)
+	}
+	return uc.finish(ctx, result, from, to, nil)
+}
+
+// prepare превращает строку файла в метрику и проверяет ее
+func (uc *ImportMetricsUseCase) prepare(row port.ImportRow) (importCandidate, error) {
+	if row.Err != nil {
+		return importCandidate{}, row.Err
+	}
+
+	raw := row.Metric
+	if raw.CollectedAt.IsZero() {
+		return importCandidate{}, errors.New("collected_at is required")
+	}
+
+	// Хранилище держит время с точностью до микросекунд: иначе повторный импорт не распознает дубликаты
+	metric, err := entity.NewMetricAt(raw.Type, raw.Name, raw.Value, raw.CollectedAt.Truncate(time.Microsecond))
+	if err != nil {
+		return importCandidate{}, err
+	}
+	metric.SetHost(raw.Host)
+
+	labels, err := valueobject.NewLabels(raw.Labels)
+	if err != nil {
+		return importCandidate{}, err
+	}
+	metric.SetLabels(labels)
+
+	for key, value := range raw.Metadata {
+		metric.SetMetadata(key, value)
+	}
+
+	if err := uc.validator.Validate(metric); err != nil {
+		return importCandidate{}, err
+	}
+	if !uc.validator.IsReasonable(metric) {
+		return importCandidate{}, errors.New("value is out of the plausible range for the metric type")
+	}
+
+	return importCandidate{line: row.Line, key: importKey(metric), metric: metric}, nil
+}
+
+// writeBatch отбрасывает точки, уже имеющиеся в хранилище, и записывает остальные
+// Возвращает записанные кандидаты
+func (uc *ImportMetricsUseCase) writeBatch(
+	ctx context.Context,
+	batch []importCandidate,
+	dryRun bool,
+	result *dto.ImportMetricsResultDTO,
+) ([]importCandidate, error) {
+	if len(batch) == 0 {
+		return nil, nil
+	}
+
+	existing, err := uc.existingKeys(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := make([]importCandidate, 0, len(batch))
+	metrics := make([]*entity.Metric, 0, len(batch))
+	for _, candidate := range batch {
+		if _, ok := existing[candidate.key]; ok {
+			result.Duplicates++
+			continue
+		}
+		fresh = append(fresh, candidate)
+		metrics = append(metrics, candidate.metric)
+	}
+
+	if len(metrics) > 0 && !dryRun {
+		if err := uc.repository.SaveBatch(ctx, metrics); err != nil {
+			uc.logger.Error("Failed to save imported metrics batch", err, "first_line", fresh[0].line)
+			return nil, fmt.Errorf("failed to save metrics from line %d: %w", fresh[0].line, err)
+		}
+	}
+
+	result.Imported += len(fresh)
+	return fresh, nil
+}
+
+// existingKeys находит в хранилище точки серий пачки за ее временной интервал
+// Запрос на каждую пару (тип, имя, хост): метки сравниваются уже по ключу
+func (uc *ImportMetricsUseCase) existingKeys(ctx context.Context, batch []importCandidate) (map[string]struct{}, error) {
+	type seriesGroup struct {
+		metric   *entity.Metric
+		from, to time.Time
+	}
+
+	groups := make(map[string]*seriesGroup)
+	for _, candidate := range batch {
+		metric := candidate.metric
+		groupKey := string(metric.Type()) + "\xff" + metric.Name() + "\xff" + metric.Host()
+		group, ok := groups[groupKey]
+		if !ok {
+			groups[groupKey] = &seriesGroup{metric: metric, from: metric.CollectedAt(), to: metric.CollectedAt()}
+			continue
+		}
+		if metric.CollectedAt().Before(group.from) {
+			group.from = metric.CollectedAt()
+		}
+		if metric.CollectedAt().After(group.to) {
+			group.to = metric.CollectedAt()
+		}
+	}
+
+	existing := make(map[string]struct{})
+	for _, group := range groups {
+		timeRange, err := valueobject.NewTimeRange(group.from, group.to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid import batch range: %w", err)
+		}
+
+		query := repository.MetricQuery{
+			Type:      group.metric.Type(),
+			Name:      group.metric.Name(),
+			TimeRange: timeRange,
+			Limit:     uc.config.BatchSize,
+		}
+		if host := group.metric.Host(); host != "" {
+			hostMatcher, err := valueobject.NewLabelMatcher(valueobject.HostLabel, valueobject.MatchEqual, host)
+			if err != nil {
+				return nil, fmt.Errorf("invalid import host: %w", err)
+			}
+			query.Selector = query.Selector.With(hostMatcher)
+		}
+
+		var cursor *repository.MetricCursor
+		for {
+			page, err := uc.repository.FindPage(ctx, query, cursor)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check existing metrics: %w", err)
+			}
+			for _, metric := range page {
+				existing[importKey(metric)] = struct{}{}
+			}
+			if len(page) < query.Limit {
+				break
+			}
+			last := page[len(page)-1]
+			cursor = &repository.MetricCursor{CollectedAt: last.CollectedAt(), ID: last.ID()}
+		}
+	}
+
+	return existing, nil
+}
+
+// finish дописывает в отчет период импорта и пересчитывает агрегаты за него
+func (uc *ImportMetricsUseCase) finish(
+	ctx context.Context,
+	result *dto.ImportMetricsResultDTO,
+	from, to time.Time,
+	err error,
+) (*dto.ImportMetricsResultDTO, error) {
+	if !from.IsZero() {
+		result.From, result.To = &from, &to
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if uc.rollups != nil && !result.DryRun && result.Imported > 0 {
+		if rollupErr := uc.rollups.Rebuild(ctx, from, to); rollupErr != nil {
+			// Точки уже записаны: агрегаты можно пересчитать повторным импортом того же файла
+			uc.logger.Error("Failed to rebuild rollups after import", rollupErr)
+		}
+	}
+
+	uc.logger.Info("Metrics import finished",
+		"dry_run", result.DryRun,
+		"rows", result.Rows,
+		"imported", result.Imported,
+		"duplicates", result.Duplicates,
+		"rejected", result.Rejected,
+	)
+
+	return result, err
+}
+
+// reject учитывает отклоненную строку в отчете
+func (uc *ImportMetricsUseCase) reject(result *dto.ImportMetricsResultDTO, line int, err error) {
+	result.Rejected++
+	if len(result.Errors) >= uc.config.MaxReportedErrors {
+		result.ErrorsTruncated = true
+		return
+	}
+	result.Errors = append(result.Errors, dto.ImportRowErrorDTO{Line: line, Error: err.Error()})
+}
+
+// importKey ключ дедупликации точки: серия (тип, имя, хост, метки) и время с точностью хранилища
+func importKey(metric *entity.Metric) string {
+	return fmt.Sprintf("%s\xff%s\xff%s\xff%s\xff%d",
+		metric.Type(), metric.Name(), metric.Host(), metric.Labels().String(), metric.CollectedAt().UnixMicro())
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// importMockRepository хранит записанные метрики и отдает их через FindPage
+type importMockRepository struct {
+	repository.MetricRepository
+	saved   []*entity.Metric
+	batches int
+}
+
+func (m *importMockRepository) SaveBatch(_ context.Context, metrics []*entity.Metric) error {
+	m.saved = append(m.saved, metrics...)
+	m.batches++
+	return nil
+}
+
+func (m *importMockRepository) FindPage(_ context.Context, query repository.MetricQuery, after *repository.MetricCursor) ([]*entity.Metric, error) {
+	if after != nil {
+		return nil, nil
+	}
+	var result []*entity.Metric
+	for _, metric := range m.saved {
+		if metric.Type() == query.Type && metric.Name() == query.Name && query.TimeRange.Contains(metric.CollectedAt()) {
+			result = append(result, metric)
+		}
+	}
+	return result, nil
+}
+
+// sliceRowReader отдает заранее подготовленные строки, затем ошибку err (по умолчанию io.EOF)
+type sliceRowReader struct {
+	rows []port.ImportRow
+	err  error
+}
+
+func (r *sliceRowReader) Next() (port.ImportRow, error) {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return port.ImportRow{}, r.err
+		}
+		return port.ImportRow{}, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+func importTestRow(t *testing.T, line int, metricType valueobject.MetricType, value float64, unit string, labels map[string]string, at time.Time) port.ImportRow {
+	t.Helper()
+	metricValue, err := valueobject.NewMetricValue(value, unit)
+	if err != nil {
+		t.Fatalf("NewMetricValue() error = %v", err)
+	}
+	return port.ImportRow{Line: line, Metric: port.RawMetric{
+		Type:        metricType,
+		Name:        string(metricType) + "_usage",
+		Value:       metricValue,
+		Labels:      labels,
+		Host:        "old-host",
+		CollectedAt: at,
+	}}
+}
+
+func TestImportMetricsValidatesDedupesAndReports(t *testing.T) {
+	repo := &importMockRepository{}
+	uc := NewImportMetricsUseCase(repo, service.NewMetricValidator(), nil, ImportMetricsConfig{BatchSize: 2, MaxReportedErrors: 2}, logger.New("error"))
+
+	past := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	rows := []port.ImportRow{
+		importTestRow(t, 2, valueobject.CPU, 10, "%", nil, past),
+		importTestRow(t, 3, valueobject.CPU, 10, "%", nil, past), // повтор в пачке
+		importTestRow(t, 4, valueobject.Disk, 70, "%", map[string]string{"mount": "/"}, past),
+		importTestRow(t, 5, valueobject.Disk, 71, "%", map[string]string{"mount": "/data"}, past), // другая серия
+		importTestRow(t, 6, valueobject.CPU, 10, "%", nil, past),                                  // повтор уже записанной пачки
+		importTestRow(t, 7, valueobject.CPU, 150, "%", nil, past.Add(time.Minute)),                // неправдоподобно
+		importTestRow(t, 8, valueobject.CPU, 20, "%", nil, time.Now().Add(time.Hour)),             // из будущего
+		{Line: 9, Err: errors.New("invalid value \"abc\"")},
+		importTestRow(t, 10, valueobject.CPU, 30, "%", nil, time.Time{}),
+	}
+
+	result, err := uc.Execute(context.Background(), &sliceRowReader{rows: rows}, false)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if result.Rows != 9 || result.Imported != 3 || result.Duplicates != 2 || result.Rejected != 4 {
+		t.Fatalf("unexpected counters: %+v", result)
+	}
+	if len(repo.saved) != 3 {
+		t.Fatalf("saved %d metrics, want 3", len(repo.saved))
+	}
+	if len(result.Errors) != 2 || !result.ErrorsTruncated || result.Errors[0].Line != 7 {
+		t.Fatalf("unexpected error report: %+v", result.Errors)
+	}
+	if result.From == nil || !result.From.Equal(past) || !result.To.Equal(past) {
+		t.Fatalf("unexpected import period: %v - %v", result.From, result.To)
+	}
+
+	// Повторный импорт того же файла ничего не пишет
+	again, err := uc.Execute(context.Background(), &sliceRowReader{rows: rows[:5]}, false)
+	if err != nil {
+		t.Fatalf("second Execute() error = %v", err)
+	}
+	if again.Imported != 0 || again.Duplicates != 5 || len(repo.saved) != 3 {
+		t.Fatalf("re-import must be a no-op: %+v", again)
+	}
+}
+
+func TestImportMetricsDryRunAndBrokenFile(t *testing.T) {
+	repo := &importMockRepository{}
+	uc := NewImportMetricsUseCase(repo, service.NewMetricValidator(), nil, ImportMetricsConfig{}, logger.New("error"))
+	past := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	dryRun, err := uc.Execute(context.Background(), &sliceRowReader{rows: []port.ImportRow{
+		importTestRow(t, 2, valueobject.CPU, 10, "%", nil, past),
+	}}, true)
+	if err != nil || !dryRun.DryRun || dryRun.Imported != 1 || len(repo.saved) != 0 {
+		t.Fatalf("dry run must only count rows: %+v, %v (saved %d)", dryRun, err, len(repo.saved))
+	}
+
+	broken := errors.New("bare quote in field")
+	result, err := uc.Execute(context.Background(), &sliceRowReader{rows: []port.ImportRow{
+		importTestRow(t, 2, valueobject.CPU, 10, "%", nil, past),
+	}, err: broken}, false)
+	if !errors.Is(err, ErrInvalidImportFile) || !errors.Is(err, broken) {
+		t.Fatalf("Execute() error = %v, want ErrInvalidImportFile", err)
+	}
+	// Строки до поврежденного места записаны
+	if result.Imported != 1 || len(repo.saved) != 1 || result.Error == "" {
+		t.Fatalf("unexpected partial result: %+v", result)
+	}
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ErrInvalidMetricCursor курсор поврежден или выдан для другого набора фильтров
+var ErrInvalidMetricCursor = errors.New("invalid cursor")
+
+// ListMetricsQuery параметры постраничного просмотра сырых метрик
+type ListMetricsQuery struct {
+	// Type тип метрики (пустой - любой тип)
+	Type valueobject.MetricType
+
+	// Name имя метрики (пустое - любое имя)
+	Name string
+
+	// Selector условия на метки, включая host
+	Selector valueobject.LabelSelector
+
+	// Start начало периода (нулевое - без ограничения по времени либо период из курсора)
+	Start time.Time
+
+	// End конец периода (нулевое при заданном Start - текущий момент первой страницы)
+	End time.Time
+
+	// Limit размер страницы (0 - размер по умолчанию)
+	Limit int
+
+	// Cursor значение next_cursor предыдущей страницы (пустое - первая страница)
+	Cursor string
+}
+
+// ListMetricsConfig настройки постраничного просмотра
+type ListMetricsConfig struct {
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// ListMetricsUseCase отдает сырые метрики страницами по возрастанию (collected_at, id)
+// В отличие от FindByTimeRange выборка не обрезается: страницы продолжаются по курсору
+type ListMetricsUseCase struct {
+	repository repository.MetricRepository
+	config     ListMetricsConfig
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewListMetricsUseCase создает новый use case
+func NewListMetricsUseCase(
+	repository repository.MetricRepository,
+	config ListMetricsConfig,
+	logger *logger.Logger,
+) *ListMetricsUseCase {
+	if config.DefaultLimit <= 0 {
+		config.DefaultLimit = 100
+	}
+	if config.MaxLimit <= 0 {
+		config.MaxLimit = 1000
+	}
+	if config.DefaultLimit > config.MaxLimit {
+		config.DefaultLimit = config.MaxLimit
+	}
+
+	return &ListMetricsUseCase{
+		repository: repository,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Execute возвращает страницу метрик и курсор следующей страницы (пустой - страниц больше нет)
+// Курсор хранит фильтры и итоговый период первой страницы: с другими фильтрами он отклоняется,
+// а пропущенные start/end берутся из него, чтобы "сейчас" не сдвигалось между страницами
+func (uc *ListMetricsUseCase) Execute(ctx context.Context, query ListMetricsQuery) (*dto.MetricPageDTO, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = uc.config.DefaultLimit
+	}
+	if limit > uc.config.MaxLimit {
+		limit = uc.config.MaxLimit
+	}
+
+	var after *repository.MetricCursor
+	var payload *metricCursorPayload
+	if query.Cursor != "" {
+		decoded, err := decodeMetricCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		payload = decoded
+		if query.Start.IsZero() {
+			query.Start = unixNanoTime(payload.FromNS)
+		}
+		if query.End.IsZero() {
+			query.End = unixNanoTime(payload.ToNS)
+		}
+		after = &repository.MetricCursor{CollectedAt: time.Unix(0, payload.CollectedAtNS).UTC(), ID: payload.ID}
+	}
+
+	if !query.Start.IsZero() && query.End.IsZero() {
+		query.End = uc.now()
+	}
+
+	var timeRange valueobject.TimeRange
+	if !query.Start.IsZero() {
+		var err error
+		timeRange, err = valueobject.NewTimeRange(query.Start, query.End)
+		if err != nil && payload != nil {
+			return nil, fmt.Errorf("%w: cursor does not match query filters", ErrInvalidMetricCursor)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid time range: %w", err)
+		}
+	}
+
+	filters := metricCursorPayload{
+		Type:     query.Type.String(),
+		Name:     query.Name,
+		Selector: selectorKey(query.Selector),
+		FromNS:   timeNano(timeRange.Start()),
+		ToNS:     timeNano(timeRange.End()),
+	}
+	if payload != nil && !payload.sameFilters(filters) {
+		return nil, fmt.Errorf("%w: cursor does not match query filters", ErrInvalidMetricCursor)
+	}
+
+	// Лишняя строка показывает, есть ли следующая страница, без отдельного пустого запроса в конце
+	metrics, err := uc.repository.FindPage(ctx, repository.MetricQuery{
+		Type:      query.Type,
+		Name:      query.Name,
+		Selector:  query.Selector,
+		TimeRange: timeRange,
+		Limit:     limit + 1,
+	}, after)
+	if err != nil {
+		uc.logger.Error("Failed to fetch metrics page", err)
+		return nil, fmt.Errorf("failed to fetch metrics page: %w", err)
+	}
+
+	page := &dto.MetricPageDTO{Metrics: make([]*dto.MetricDTO, 0, min(len(metrics), limit))}
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+		last := metrics[len(metrics)-1]
+
+		next := filters
+		next.CollectedAtNS = last.CollectedAt().UnixNano()
+		next.ID = last.ID()
+		page.NextCursor, err = encodeMetricCursor(next)
+		if err != nil {
+			return nil, err
+		}
+	}
+	page.Metrics = append(page.Metrics, dto.ToMetricDTOs(metrics)...)
+
+	uc.logger.Debug("Listed metrics page", "count", len(page.Metrics), "has_more", page.NextCursor != "")
+
+	return page, nil
+}
+
+// metricCursorPayload содержимое курсора: позиция последней отданной строки и фильтры выборки
+type metricCursorPayload struct {
+	CollectedAtNS int64  `json:"collected_at_ns"`
+	ID            string `json:"id"`
+	Type          string `json:"type,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Selector      string `json:"selector,omitempty"`
+	FromNS        int64  `json:"from_ns,omitempty"`
+	ToNS          int64  `json:"to_ns,omitempty"`
+}
+
+// sameFilters сравнивает фильтры курсора без учета позиции
+func (p metricCursorPayload) sameFilters(other metricCursorPayload) bool {
+	return p.Type == other.Type &&
+		p.Name == other.Name &&
+		p.Selector == other.Selector &&
+		p.FromNS == other.FromNS &&
+		p.ToNS == other.ToNS
+}
+
+func encodeMetricCursor(payload metricCursorPayload) (string, error) {
+	serialized, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(serialized), nil
+}
+
+func decodeMetricCursor(cursor string) (*metricCursorPayload, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidMetricCursor
+	}
+
+	var payload metricCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == "" || payload.CollectedAtNS == 0 {
+		return nil, ErrInvalidMetricCursor
+	}
+	if (payload.FromNS == 0) != (payload.ToNS == 0) {
+		return nil, ErrInvalidMetricCursor
+	}
+
+	return &payload, nil
+}
+
+// selectorKey каноническое представление селектора (пустой селектор - пустая строка)
+func selectorKey(selector valueobject.LabelSelector) string {
+	if selector.IsEmpty() {
+		return ""
+	}
+	return selector.String()
+}
+
+// timeNano переводит момент в наносекунды (нулевой момент - 0)
+func timeNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// unixNanoTime обратное преобразование к timeNano
+func unixNanoTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// pageMockRepository отдает метрики по возрастанию времени после курсора и запоминает последний запрос
+type pageMockRepository struct {
+	repository.MetricRepository
+	metrics   []*entity.Metric
+	lastQuery repository.MetricQuery
+}
+
+func (m *pageMockRepository) FindPage(_ context.Context, query repository.MetricQuery, after *repository.MetricCursor) ([]*entity.Metric, error) {
+	m.lastQuery = query
+	var result []*entity.Metric
+	for _, metric := range m.metrics {
+		if query.Type != "" && metric.Type() != query.Type {
+			continue
+		}
+		if query.HasTimeRange() && !query.TimeRange.Contains(metric.CollectedAt()) {
+			continue
+		}
+		if after != nil && !metric.CollectedAt().After(after.CollectedAt) {
+			continue
+		}
+		result = append(result, metric)
+		if len(result) == query.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func listTestMetrics(t *testing.T, start time.Time, count int) []*entity.Metric {
+	t.Helper()
+	metrics := make([]*entity.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		value, _ := valueobject.NewMetricValue(float64(i), "%")
+		metric, err := entity.NewMetricAt(valueobject.CPU, "cpu_usage", value, start.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("NewMetricAt() error = %v", err)
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+func TestListMetricsPagesThroughAllRows(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &pageMockRepository{metrics: listTestMetrics(t, start, 5)}
+	uc := NewListMetricsUseCase(repo, ListMetricsConfig{}, logger.New("error"))
+	uc.now = func() time.Time { return start.Add(time.Hour) }
+
+	query := ListMetricsQuery{Type: valueobject.CPU, Start: start, Limit: 2}
+	var values []float64
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination does not terminate")
+		}
+		page, err := uc.Execute(context.Background(), query)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		for _, metric := range page.Metrics {
+			values = append(values, metric.Value)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		// Следующие страницы запрашиваются без end: период берется из курсора
+		uc.now = func() time.Time { return start.Add(2 * time.Hour) }
+		query.Cursor = page.NextCursor
+	}
+
+	if fmt.Sprint(values) != "[0 1 2 3 4]" {
+		t.Fatalf("values = %v, want all five rows once", values)
+	}
+	if !repo.lastQuery.TimeRange.End().Equal(start.Add(time.Hour)) || repo.lastQuery.Limit != 3 {
+		t.Fatalf("unexpected repository query: %+v", repo.lastQuery)
+	}
+}
+
+func TestListMetricsRejectsForeignCursor(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &pageMockRepository{metrics: listTestMetrics(t, start, 3)}
+	uc := NewListMetricsUseCase(repo, ListMetricsConfig{MaxLimit: 1}, logger.New("error"))
+
+	page, err := uc.Execute(context.Background(), ListMetricsQuery{Type: valueobject.CPU, Limit: 50})
+	if err != nil || len(page.Metrics) != 1 || page.NextCursor == "" {
+		t.Fatalf("limit must be capped at MaxLimit: %+v, %v", page, err)
+	}
+
+	_, err = uc.Execute(context.Background(), ListMetricsQuery{Type: valueobject.Memory, Cursor: page.NextCursor})
+	if !errors.Is(err, ErrInvalidMetricCursor) {
+		t.Fatalf("cursor with other filters: error = %v, want ErrInvalidMetricCursor", err)
+	}
+	if _, err := uc.Execute(context.Background(), ListMetricsQuery{Cursor: "not-a-cursor"}); !errors.Is(err, ErrInvalidMetricCursor) {
+		t.Fatalf("garbage cursor: error = %v, want ErrInvalidMetricCursor", err)
+	}
+}
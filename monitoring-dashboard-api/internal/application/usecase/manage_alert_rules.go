@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ManageAlertRulesUseCase реализует CRUD правил алертинга
+type ManageAlertRulesUseCase struct {
+	repository repository.AlertRuleRepository
+	logger     *logger.Logger
+}
+
+// NewManageAlertRulesUseCase создает новый use case
+func NewManageAlertRulesUseCase(
+	repository repository.AlertRuleRepository,
+	logger *logger.Logger,
+) *ManageAlertRulesUseCase {
+	return &ManageAlertRulesUseCase{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// List возвращает все правила
+func (uc *ManageAlertRulesUseCase) List(ctx context.Context) ([]*dto.AlertRuleDTO, error) {
+	rules, err := uc.repository.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	return dto.ToAlertRuleDTOs(rules), nil
+}
+
+// Get возвращает правило по идентификатору
+func (uc *ManageAlertRulesUseCase) Get(ctx context.Context, id string) (*dto.AlertRuleDTO, error) {
+	rule, err := uc.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromAlertRule(rule), nil
+}
+
+// Create создает правило
+func (uc *ManageAlertRulesUseCase) Create(ctx context.Context, req dto.AlertRuleRequestDTO) (*dto.AlertRuleDTO, error) {
+	params, err := alertRuleParamsFromDTO(req, true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert rule: %w", err)
+	}
+
+	rule, err := entity.NewAlertRule(params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert rule: %w", err)
+	}
+
+	if err := uc.repository.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Alert rule created", "id", rule.ID(), "name", rule.Name(), "condition", rule.Describe())
+	return dto.FromAlertRule(rule), nil
+}
+
+// Update заменяет параметры правила
+// Если enabled не передан, сохраняется текущее значение
+func (uc *ManageAlertRulesUseCase) Update(ctx context.Context, id string, req dto.AlertRuleRequestDTO) (*dto.AlertRuleDTO, error) {
+	rule, err := uc.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := alertRuleParamsFromDTO(req, rule.Enabled())
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert rule: %w", err)
+	}
+
+	if err := rule.Update(params); err != nil {
+		return nil, fmt.Errorf("invalid alert rule: %w", err)
+	}
+
+	if err := uc.repository.Save(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	uc.logger.Info("Alert rule updated", "id", rule.ID(), "name", rule.Name(), "condition", rule.Describe())
+	return dto.FromAlertRule(rule), nil
+}
+
+// Delete удаляет правило
+func (uc *ManageAlertRulesUseCase) Delete(ctx context.Context, id string) error {
+	if err := uc.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	uc.logger.Info("Alert rule deleted", "id", id)
+	return nil
+}
+
+// alertRuleParamsFromDTO разбирает запрос в параметры правила
+func alertRuleParamsFromDTO(req dto.AlertRuleRequestDTO, defaultEnabled bool) (entity.AlertRuleParams, error) {
+	selector, err := valueobject.ParseLabelSelector(req.Selector)
+	if err != nil {
+		return entity.AlertRuleParams{}, err
+	}
+
+	window, err := parseOptionalDuration("window", req.Window)
+	if err != nil {
+		return entity.AlertRuleParams{}, err
+	}
+	forDuration, err := parseOptionalDuration("for", req.For)
+	if err != nil {
+		return entity.AlertRuleParams{}, err
+	}
+	cooldown, err := parseOptionalDuration("cooldown", req.Cooldown)
+	if err != nil {
+		return entity.AlertRuleParams{}, err
+	}
+
+	enabled := defaultEnabled
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	return entity.AlertRuleParams{
+		Name:        req.Name,
+		Description: req.Description,
+		MetricType:  valueobject.MetricType(req.MetricType),
+		MetricName:  req.MetricName,
+		Unit:        req.Unit,
+		Selector:    selector,
+		Aggregate:   valueobject.WindowAggregate(req.Aggregate),
+		Window:      window,
+		Comparator:  valueobject.Comparator(req.Comparator),
+		Threshold:   req.Threshold,
+		For:         forDuration,
+		Cooldown:    cooldown,
+		Severity:    valueobject.AlertSeverity(req.Severity),
+		Enabled:     enabled,
+		Channels:    req.Channels,
+	}, nil
+}
+
+func parseOptionalDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s duration: %w", field, err)
+	}
+	return duration, nil
+}
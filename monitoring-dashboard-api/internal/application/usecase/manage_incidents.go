@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+const (
+	// incidentSystemActor проставляется в resolved_by при автоматическом закрытии
+	incidentSystemActor = "system"
+
+	// maxIncidentActorLength ограничивает длину имени в acknowledged_by/resolved_by
+	maxIncidentActorLength = 100
+
+	// maxRestoredIncidents ограничивает число незакрытых инцидентов, читаемых при старте
+	maxRestoredIncidents = 10000
+)
+
+// ManageIncidentsUseCase ведет инциденты: группирует алерты и реализует acknowledge/resolve
+type ManageIncidentsUseCase struct {
+	repository repository.IncidentRepository
+	notifier   port.NotificationService
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewManageIncidentsUseCase создает новый use case
+func NewManageIncidentsUseCase(
+	repository repository.IncidentRepository,
+	notifier port.NotificationService,
+	logger *logger.Logger,
+) *ManageIncidentsUseCase {
+	return &ManageIncidentsUseCase{
+		repository: repository,
+		notifier:   notifier,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// RecordAlertTransition отражает переход алерта в инциденте
+// firing открывает инцидент или учитывает повторный алерт в открытом; инцидент,
+// закрытый менее cooldown правила назад, переоткрывается.
+// resolved закрывает открытый инцидент серии.
+func (uc *ManageIncidentsUseCase) RecordAlertTransition(
+	ctx context.Context,
+	rule *entity.AlertRule,
+	state *entity.AlertState,
+	message string,
+) error {
+	fingerprint := incidentFingerprint(rule.ID(), state.SeriesKey())
+
+	latest, err := uc.repository.FindLatestByFingerprint(ctx, fingerprint)
+	if err != nil && !errors.Is(err, repository.ErrIncidentNotFound) {
+		return fmt.Errorf("failed to find incident: %w", err)
+	}
+
+	var incident *entity.Incident
+	switch state.Status() {
+	case entity.AlertFiring:
+		at := state.FiredAt()
+		if latest != nil && (latest.IsActive() || latest.ResolvedWithin(rule.Params().Cooldown, at)) {
+			latest.RecordAlert(state.Value(), message, at)
+			incident = latest
+		} else {
+			incident, err = entity.NewIncident(entity.IncidentParams{
+				Fingerprint: fingerprint,
+				RuleID:      rule.ID(),
+				SeriesKey:   state.SeriesKey(),
+				Title:       rule.Name(),
+				Severity:    rule.Severity(),
+				Host:        state.Host(),
+				Labels:      state.Labels(),
+			}, state.Value(), message, at)
+			if err != nil {
+				return fmt.Errorf("failed to open incident: %w", err)
+			}
+		}
+	case entity.AlertResolved:
+		if latest == nil || !latest.IsActive() {
+			return nil
+		}
+		if err := latest.Resolve(incidentSystemActor, message, state.ResolvedAt()); err != nil {
+			return err
+		}
+		incident = latest
+	default:
+		return nil
+	}
+
+	return uc.save(ctx, incident)
+}
+
+// ActiveAlertIncidents возвращает незакрытые инциденты, открытые правилами алертинга
+func (uc *ManageIncidentsUseCase) ActiveAlertIncidents(ctx context.Context) ([]*entity.Incident, error) {
+	incidents, err := uc.repository.FindAll(ctx, repository.IncidentFilter{
+		Statuses: []entity.IncidentStatus{entity.IncidentOpen, entity.IncidentAcknowledged},
+		Limit:    maxRestoredIncidents,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active incidents: %w", err)
+	}
+
+	result := make([]*entity.Incident, 0, len(incidents))
+	for _, incident := range incidents {
+		if params := incident.Params(); params.RuleID != "" && params.SeriesKey != "" {
+			result = append(result, incident)
+		}
+	}
+	return result, nil
+}
+
+// List возвращает инциденты по фильтру
+func (uc *ManageIncidentsUseCase) List(ctx context.Context, filter repository.IncidentFilter) ([]*dto.IncidentDTO, error) {
+	for _, status := range filter.Statuses {
+		if err := status.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid incident filter: %w", err)
+		}
+	}
+
+	incidents, err := uc.repository.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+	return dto.ToIncidentDTOs(incidents), nil
+}
+
+// Get возвращает инцидент по идентификатору
+func (uc *ManageIncidentsUseCase) Get(ctx context.Context, id string) (*dto.IncidentDTO, error) {
+	incident, err := uc.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromIncident(incident), nil
+}
+
+// Open создает инцидент вручную
+func (uc *ManageIncidentsUseCase) Open(ctx context.Context, req dto.CreateIncidentRequestDTO) (*dto.IncidentDTO, error) {
+	incident, err := entity.NewIncident(entity.IncidentParams{
+		Title:    req.Title,
+		Severity: valueobject.AlertSeverity(req.Severity),
+		Host:     strings.TrimSpace(req.Host),
+	}, 0, req.Message, uc.now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid incident: %w", err)
+	}
+
+	if err := uc.save(ctx, incident); err != nil {
+		return nil, err
+	}
+	return dto.FromIncident(incident), nil
+}
+
+// Acknowledge подтверждает инцидент от имени req.By
+func (uc *ManageIncidentsUseCase) Acknowledge(ctx context.Context, id string, req dto.IncidentActionRequestDTO) (*dto.IncidentDTO, error) {
+	by, err := incidentActor(req.By)
+	if err != nil {
+		return nil, err
+	}
+
+	incident, err := uc.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := incident.Acknowledge(by, uc.now()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.save(ctx, incident); err != nil {
+		return nil, err
+	}
+	return dto.FromIncident(incident), nil
+}
+
+// Resolve закрывает инцидент от имени req.By
+func (uc *ManageIncidentsUseCase) Resolve(ctx context.Context, id string, req dto.IncidentActionRequestDTO) (*dto.IncidentDTO, error) {
+	by, err := incidentActor(req.By)
+	if err != nil {
+		return nil, err
+	}
+
+	incident, err := uc.repository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := incident.Resolve(by, strings.TrimSpace(req.Message), uc.now()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.save(ctx, incident); err != nil {
+		return nil, err
+	}
+	return dto.FromIncident(incident), nil
+}
+
+// save сохраняет инцидент и рассылает изменение клиентам
+func (uc *ManageIncidentsUseCase) save(ctx context.Context, incident *entity.Incident) error {
+	if err := uc.repository.Save(ctx, incident); err != nil {
+		return err
+	}
+
+	uc.notifier.BroadcastIncident(dto.FromIncident(incident))
+	uc.logger.Info("Incident updated",
+		"id", incident.ID(),
+		"title", incident.Title(),
+		"host", incident.Host(),
+		"status", string(incident.Status()),
+		"alert_count", incident.Lifecycle().AlertCount)
+	return nil
+}
+
+// incidentFingerprint ключ группировки алертов серии в инцидент
+// Ключ серии включает хост и до 32 меток, поэтому хранится его хеш фиксированной длины
+func incidentFingerprint(ruleID, seriesKey string) string {
+	sum := sha256.Sum256([]byte(alertStateKey(ruleID, seriesKey)))
+	return hex.EncodeToString(sum[:])
+}
+
+// incidentActor валидирует имя того, кто выполняет действие
+func incidentActor(by string) (string, error) {
+	by = strings.TrimSpace(by)
+	if by == "" {
+		return "", errors.New("invalid incident action: field by is required")
+	}
+	if len(by) > maxIncidentActorLength {
+		return "", fmt.Errorf("invalid incident action: field by is too long (max %d)", maxIncidentActorLength)
+	}
+	return by, nil
+}
@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+type incidentMockRepository struct {
+	incidents map[string]*entity.Incident
+}
+
+func newIncidentMockRepository() *incidentMockRepository {
+	return &incidentMockRepository{incidents: make(map[string]*entity.Incident)}
+}
+
+func (m *incidentMockRepository) Save(_ context.Context, incident *entity.Incident) error {
+	m.incidents[incident.ID()] = incident
+	return nil
+}
+
+func (m *incidentMockRepository) FindByID(_ context.Context, id string) (*entity.Incident, error) {
+	incident, ok := m.incidents[id]
+	if !ok {
+		return nil, repository.ErrIncidentNotFound
+	}
+	return incident, nil
+}
+
+func (m *incidentMockRepository) FindLatestByFingerprint(ctx context.Context, fingerprint string) (*entity.Incident, error) {
+	all, _ := m.FindAll(ctx, repository.IncidentFilter{})
+	for _, incident := range all {
+		if incident.Fingerprint() == fingerprint {
+			return incident, nil
+		}
+	}
+	return nil, repository.ErrIncidentNotFound
+}
+
+func (m *incidentMockRepository) FindAll(_ context.Context, filter repository.IncidentFilter) ([]*entity.Incident, error) {
+	result := make([]*entity.Incident, 0, len(m.incidents))
+	for _, incident := range m.incidents {
+		if len(filter.Statuses) > 0 && !containsIncidentStatus(filter.Statuses, incident.Status()) {
+			continue
+		}
+		result = append(result, incident)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Lifecycle().OpenedAt.After(result[j].Lifecycle().OpenedAt)
+	})
+	return result, nil
+}
+
+func containsIncidentStatus(statuses []entity.IncidentStatus, status entity.IncidentStatus) bool {
+	for _, candidate := range statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+func highCPURule() entity.AlertRuleParams {
+	return entity.AlertRuleParams{
+		Name:       "High CPU",
+		MetricType: valueobject.CPU,
+		Aggregate:  valueobject.AggregateLast,
+		Window:     time.Minute,
+		Comparator: valueobject.ComparatorGreater,
+		Threshold:  90,
+		Cooldown:   time.Minute,
+		Severity:   valueobject.SeverityCritical,
+		Enabled:    true,
+	}
+}
+
+func listIncidents(t *testing.T, env *alertTestEnv) []*dto.IncidentDTO {
+	t.Helper()
+	incidents, err := env.incidents.List(context.Background(), repository.IncidentFilter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	return incidents
+}
+
+func TestManageIncidentsUseCase_GroupsRepeatedAlerts(t *testing.T) {
+	env := newAlertTestEnv(t, highCPURule())
+
+	env.step(t, "web-1", 95) // firing -> incident opened
+	env.step(t, "web-1", 10) // resolved -> incident resolved
+	env.step(t, "web-1", 95) // firing within cooldown -> same incident reopened
+	env.step(t, "web-2", 97) // another series -> separate incident
+
+	incidents := listIncidents(t, env)
+	if len(incidents) != 2 {
+		t.Fatalf("expected 2 incidents, got %d", len(incidents))
+	}
+
+	web1 := incidents[1]
+	if web1.Host != "web-1" || web1.Status != "open" || web1.AlertCount != 2 || web1.ResolvedAt != nil {
+		t.Fatalf("unexpected web-1 incident: %+v", web1)
+	}
+	if web1.Title != "High CPU" || web1.Severity != "critical" || web1.RuleID == "" {
+		t.Fatalf("unexpected web-1 incident source: %+v", web1)
+	}
+
+	// Одна серия вышла за cooldown после закрытия - открывается новый инцидент
+	env.step(t, "web-1", 10)
+	for i := 0; i < 6; i++ {
+		env.step(t, "web-1", 10)
+	}
+	env.step(t, "web-1", 99)
+
+	incidents = listIncidents(t, env)
+	if len(incidents) != 3 {
+		t.Fatalf("expected 3 incidents, got %d", len(incidents))
+	}
+	if incidents[0].Host != "web-1" || incidents[0].Status != "open" || incidents[0].AlertCount != 1 {
+		t.Fatalf("unexpected new incident: %+v", incidents[0])
+	}
+	if len(env.notifier.incidents) == 0 {
+		t.Fatal("expected incident broadcasts")
+	}
+}
+
+func TestManageIncidentsUseCase_AcknowledgeAndResolve(t *testing.T) {
+	env := newAlertTestEnv(t, highCPURule())
+	ctx := context.Background()
+
+	env.step(t, "web-1", 95)
+	incident := listIncidents(t, env)[0]
+
+	if _, err := env.incidents.Acknowledge(ctx, incident.ID, dto.IncidentActionRequestDTO{}); err == nil {
+		t.Fatal("expected error for missing actor")
+	}
+
+	acknowledged, err := env.incidents.Acknowledge(ctx, incident.ID, dto.IncidentActionRequestDTO{By: "alice"})
+	if err != nil {
+		t.Fatalf("Acknowledge failed: %v", err)
+	}
+	if acknowledged.Status != "acknowledged" || acknowledged.AcknowledgedBy != "alice" || acknowledged.AcknowledgedAt == nil {
+		t.Fatalf("unexpected acknowledged incident: %+v", acknowledged)
+	}
+
+	if _, err := env.incidents.Acknowledge(ctx, incident.ID, dto.IncidentActionRequestDTO{By: "bob"}); !errors.Is(err, entity.ErrIncidentAlreadyAcknowledged) {
+		t.Fatalf("expected ErrIncidentAlreadyAcknowledged, got %v", err)
+	}
+
+	// Повторный алерт не сбрасывает подтверждение открытого инцидента
+	env.step(t, "web-1", 96)
+	if got := listIncidents(t, env)[0]; got.Status != "acknowledged" {
+		t.Fatalf("expected incident to stay acknowledged, got %s", got.Status)
+	}
+
+	// Разрешение алерта закрывает инцидент автоматически
+	env.step(t, "web-1", 10)
+	resolved := listIncidents(t, env)[0]
+	if resolved.Status != "resolved" || resolved.ResolvedBy != incidentSystemActor || resolved.ResolvedAt == nil {
+		t.Fatalf("unexpected resolved incident: %+v", resolved)
+	}
+
+	if _, err := env.incidents.Resolve(ctx, incident.ID, dto.IncidentActionRequestDTO{By: "alice"}); !errors.Is(err, entity.ErrIncidentAlreadyResolved) {
+		t.Fatalf("expected ErrIncidentAlreadyResolved, got %v", err)
+	}
+
+	if _, err := env.incidents.Get(ctx, "missing"); !errors.Is(err, repository.ErrIncidentNotFound) {
+		t.Fatalf("expected ErrIncidentNotFound, got %v", err)
+	}
+}
+
+func TestManageIncidentsUseCase_OpenManual(t *testing.T) {
+	env := newAlertTestEnv(t, highCPURule())
+	ctx := context.Background()
+
+	if _, err := env.incidents.Open(ctx, dto.CreateIncidentRequestDTO{Severity: "critical"}); err == nil {
+		t.Fatal("expected error for missing title")
+	}
+
+	incident, err := env.incidents.Open(ctx, dto.CreateIncidentRequestDTO{
+		Title:    "Database failover",
+		Severity: "critical",
+		Host:     "db-1",
+		Message:  "Primary is unreachable",
+	})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if incident.Status != "open" || incident.RuleID != "" || incident.LastMessage != "Primary is unreachable" {
+		t.Fatalf("unexpected manual incident: %+v", incident)
+	}
+
+	resolved, err := env.incidents.Resolve(ctx, incident.ID, dto.IncidentActionRequestDTO{By: "alice", Message: "Failover completed"})
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if resolved.ResolvedBy != "alice" || resolved.LastMessage != "Failover completed" {
+		t.Fatalf("unexpected resolved incident: %+v", resolved)
+	}
+}
+
+func TestManageIncidentsUseCase_FingerprintHasFixedLength(t *testing.T) {
+	env := newAlertTestEnv(t, highCPURule())
+
+	// Длинный хост: ключ серии длиннее 512 байт, а fingerprint остается хешем фиксированной длины
+	host := strings.Repeat("h", 250) + "." + strings.Repeat("d", 250) + ".example"
+	env.step(t, host, 95)
+
+	incidents, err := env.incidents.ActiveAlertIncidents(context.Background())
+	if err != nil {
+		t.Fatalf("ActiveAlertIncidents failed: %v", err)
+	}
+	if len(incidents) != 1 {
+		t.Fatalf("expected 1 active incident, got %d", len(incidents))
+	}
+	params := incidents[0].Params()
+	if len(params.Fingerprint) != 64 || !strings.Contains(params.SeriesKey, host) {
+		t.Fatalf("unexpected fingerprint %q for series %q", params.Fingerprint, params.SeriesKey)
+	}
+}
+
+func TestEvaluateAlertRulesUseCase_RestoreResolvesIncidentsAfterRestart(t *testing.T) {
+	env := newAlertTestEnv(t, highCPURule())
+	env.step(t, "web-1", 95) // firing -> incident opened
+	env.step(t, "web-2", 97) // firing -> incident opened
+
+	// Перезапуск: состояние алертов в памяти потеряно, инциденты остались в хранилище
+	restarted := NewEvaluateAlertRulesUseCase(env.uc.rules, env.metrics, service.NewMetricAggregator(), env.notifier, nil, env.incidents, nil, logger.New("error"))
+	restarted.now = func() time.Time { return env.now }
+	if err := restarted.Restore(context.Background()); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	env.uc = restarted
+
+	// web-1 вышел из условия, web-2 по-прежнему горит: повторного firing нет
+	env.step(t, "web-1", 10)
+	env.step(t, "web-2", 98)
+	assertAlertStates(t, env.states(), "web-1:firing", "web-2:firing", "web-1:resolved")
+
+	statuses := make(map[string]string)
+	for _, incident := range listIncidents(t, env) {
+		statuses[incident.Host] = incident.Status
+	}
+	if statuses["web-1"] != "resolved" || statuses["web-2"] != "open" {
+		t.Fatalf("unexpected incident statuses after restart: %v", statuses)
+	}
+}
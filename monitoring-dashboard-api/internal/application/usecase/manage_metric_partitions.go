@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ManageMetricPartitionsConfig настройки управления партициями метрик
+type ManageMetricPartitionsConfig struct {
+	// Interval размер партиции (сутки или неделя); имена партиций содержат только дату, поэтому не меньше суток
+	Interval time.Duration
+
+	// Premake количество партиций, создаваемых заранее после текущей
+	Premake int
+
+	// Retention партиции, целиком старше этого срока, удаляются (0 - не удалять)
+	Retention time.Duration
+}
+
+// ManageMetricPartitionsUseCase заранее создает партиции метрик и удаляет устаревшие
+// Удаление партиции заменяет построчную очистку для срока хранения по умолчанию
+type ManageMetricPartitionsUseCase struct {
+	repository repository.MetricPartitionRepository
+	config     ManageMetricPartitionsConfig
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewManageMetricPartitionsUseCase создает новый use case
+func NewManageMetricPartitionsUseCase(
+	repository repository.MetricPartitionRepository,
+	config ManageMetricPartitionsConfig,
+	logger *logger.Logger,
+) *ManageMetricPartitionsUseCase {
+	if config.Interval <= 0 {
+		config.Interval = 24 * time.Hour
+	}
+	if config.Premake <= 0 {
+		config.Premake = 3
+	}
+
+	return &ManageMetricPartitionsUseCase{
+		repository: repository,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Execute создает недостающие партиции до now + Premake*Interval и удаляет устаревшие
+func (uc *ManageMetricPartitionsUseCase) Execute(ctx context.Context) error {
+	now := uc.now().UTC()
+
+	partitions, err := uc.repository.ListPartitions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list metric partitions: %w", err)
+	}
+
+	// Новые партиции продолжают последнюю существующую, чтобы диапазоны не пересекались
+	// даже после смены Interval; без партиций отсчет идет от начала текущего интервала
+	next := now.Truncate(uc.config.Interval)
+	if len(partitions) > 0 {
+		next = partitions[len(partitions)-1].To
+	}
+
+	horizon := now.Truncate(uc.config.Interval).Add(time.Duration(uc.config.Premake+1) * uc.config.Interval)
+	for next.Before(horizon) {
+		partition, err := uc.repository.CreatePartition(ctx, next, next.Add(uc.config.Interval))
+		if err != nil {
+			return fmt.Errorf("failed to create metric partition from %s: %w", next, err)
+		}
+		uc.logger.Info("Metric partition created",
+			"partition", partition.Name,
+			"from", partition.From,
+			"to", partition.To)
+		next = partition.To
+	}
+
+	if uc.config.Retention <= 0 {
+		return nil
+	}
+
+	cutoff := now.Add(-uc.config.Retention)
+	for _, partition := range partitions {
+		if partition.To.After(cutoff) {
+			continue
+		}
+		if err := uc.repository.DropPartition(ctx, partition.Name); err != nil {
+			return fmt.Errorf("failed to drop metric partition %s: %w", partition.Name, err)
+		}
+		uc.logger.Info("Expired metric partition dropped",
+			"partition", partition.Name,
+			"to", partition.To,
+			"cutoff", cutoff)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// partitionMockRepository хранит партиции в памяти
+type partitionMockRepository struct {
+	partitions []repository.MetricPartition
+	dropped    []string
+}
+
+func (m *partitionMockRepository) ListPartitions(_ context.Context) ([]repository.MetricPartition, error) {
+	return append([]repository.MetricPartition(nil), m.partitions...), nil
+}
+
+func (m *partitionMockRepository) CreatePartition(_ context.Context, from, to time.Time) (repository.MetricPartition, error) {
+	partition := repository.MetricPartition{Name: "metrics_p" + from.Format("20060102"), From: from, To: to}
+	m.partitions = append(m.partitions, partition)
+	return partition, nil
+}
+
+func (m *partitionMockRepository) DropPartition(_ context.Context, name string) error {
+	m.dropped = append(m.dropped, name)
+	return nil
+}
+
+func dailyPartition(day time.Time) repository.MetricPartition {
+	return repository.MetricPartition{Name: "metrics_p" + day.Format("20060102"), From: day, To: day.AddDate(0, 0, 1)}
+}
+
+func TestManageMetricPartitionsCreatesAheadAndDropsExpired(t *testing.T) {
+	repo := &partitionMockRepository{}
+	for day := 20; day <= 28; day++ {
+		repo.partitions = append(repo.partitions, dailyPartition(time.Date(2026, 2, day, 0, 0, 0, 0, time.UTC)))
+	}
+
+	uc := NewManageMetricPartitionsUseCase(repo, ManageMetricPartitionsConfig{
+		Interval:  24 * time.Hour,
+		Premake:   2,
+		Retention: 7 * 24 * time.Hour,
+	}, logger.New("error"))
+	uc.now = func() time.Time { return time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC) }
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Текущие сутки и две следующие
+	created := repo.partitions[9:]
+	if len(created) != 3 || created[0].Name != "metrics_p20260301" || created[2].Name != "metrics_p20260303" {
+		t.Fatalf("unexpected created partitions: %+v", created)
+	}
+
+	// Cutoff 2026-02-22 15:00: целиком старше только партиции 20 и 21 февраля
+	if len(repo.dropped) != 2 || repo.dropped[0] != "metrics_p20260220" || repo.dropped[1] != "metrics_p20260221" {
+		t.Fatalf("unexpected dropped partitions: %v", repo.dropped)
+	}
+}
+
+func TestManageMetricPartitionsWeeklyWithoutRetention(t *testing.T) {
+	repo := &partitionMockRepository{}
+
+	uc := NewManageMetricPartitionsUseCase(repo, ManageMetricPartitionsConfig{
+		Interval: 7 * 24 * time.Hour,
+		Premake:  1,
+	}, logger.New("error"))
+	// Среда
+	uc.now = func() time.Time { return time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC) }
+
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// Недельные партиции начинаются с понедельника
+	if len(repo.partitions) != 2 || repo.partitions[0].Name != "metrics_p20260302" || repo.partitions[1].Name != "metrics_p20260309" {
+		t.Fatalf("unexpected weekly partitions: %+v", repo.partitions)
+	}
+	if len(repo.dropped) != 0 {
+		t.Fatalf("partitions must be kept without retention: %v", repo.dropped)
+	}
+
+	// Повторный запуск ничего не создает
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(repo.partitions) != 2 {
+		t.Fatalf("expected no new partitions, got %+v", repo.partitions)
+	}
+}
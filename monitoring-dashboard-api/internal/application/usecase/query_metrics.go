@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/promql"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// QueryMetricsUseCase вычисляет выражения языка запросов (подмножество PromQL) по сырым метрикам
+type QueryMetricsUseCase struct {
+	engine *promql.Engine
+	logger *logger.Logger
+	now    func() time.Time
+}
+
+// NewQueryMetricsUseCase создает новый use case
+func NewQueryMetricsUseCase(engine *promql.Engine, logger *logger.Logger) *QueryMetricsUseCase {
+	return &QueryMetricsUseCase{
+		engine: engine,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Instant вычисляет выражение в момент ts (нулевой ts - текущий момент)
+func (uc *QueryMetricsUseCase) Instant(ctx context.Context, query string, ts time.Time) (*dto.QueryDataDTO, error) {
+	if ts.IsZero() {
+		ts = uc.now()
+	}
+
+	uc.logger.Debug("Evaluating instant query", "query", query, "time", ts)
+
+	result, err := uc.engine.Instant(ctx, query, ts)
+	if err != nil {
+		return nil, err
+	}
+	return toQueryDataDTO(result), nil
+}
+
+// Range вычисляет выражение в каждой точке [start, end] с шагом step
+func (uc *QueryMetricsUseCase) Range(
+	ctx context.Context,
+	query string,
+	start, end time.Time,
+	step time.Duration,
+) (*dto.QueryDataDTO, error) {
+	uc.logger.Debug("Evaluating range query",
+		"query", query,
+		"start", start,
+		"end", end,
+		"step", step)
+
+	result, err := uc.engine.Range(ctx, query, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+	return toQueryDataDTO(result), nil
+}
+
+// toQueryDataDTO конвертирует результат вычислителя в DTO
+func toQueryDataDTO(result promql.Result) *dto.QueryDataDTO {
+	data := &dto.QueryDataDTO{ResultType: string(result.Type)}
+
+	switch result.Type {
+	case promql.ValueTypeScalar:
+		data.Result = toQueryPointDTO(result.Scalar)
+	case promql.ValueTypeVector:
+		samples := make([]dto.QuerySampleDTO, 0, len(result.Vector))
+		for _, sample := range result.Vector {
+			samples = append(samples, dto.QuerySampleDTO{
+				Metric: sample.Metric,
+				Value:  toQueryPointDTO(sample.Point),
+			})
+		}
+		data.Result = samples
+	case promql.ValueTypeMatrix:
+		series := make([]dto.QuerySeriesDTO, 0, len(result.Matrix))
+		for _, s := range result.Matrix {
+			values := make([]dto.QueryPointDTO, 0, len(s.Points))
+			for _, p := range s.Points {
+				values = append(values, toQueryPointDTO(p))
+			}
+			series = append(series, dto.QuerySeriesDTO{Metric: s.Metric, Values: values})
+		}
+		data.Result = series
+	}
+
+	return data
+}
+
+// toQueryPointDTO форматирует точку как [unix-секунды, "значение"]
+// Значение передается строкой, чтобы NaN и ±Inf кодировались в JSON
+func toQueryPointDTO(p promql.Point) dto.QueryPointDTO {
+	seconds := float64(p.T.UnixMilli()) / 1000
+	return dto.QueryPointDTO{seconds, formatQueryValue(p.V)}
+}
+
+func formatQueryValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
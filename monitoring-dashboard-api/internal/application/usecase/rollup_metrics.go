@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// RollupMetricsConfig настройки построения уровней агрегации
+type RollupMetricsConfig struct {
+	// Lag задержка перед агрегацией закрытого бакета, чтобы дождаться опоздавших точек агентов
+	Lag time.Duration
+
+	// Backfill глубина первичного построения уровня, у которого еще нет watermark
+	Backfill time.Duration
+
+	// BucketsPerStep максимум бакетов, пересчитываемых одним запросом
+	BucketsPerStep int
+
+	// Retention сроки хранения бакетов по уровням (0 - хранить бессрочно)
+	Retention map[valueobject.RollupTier]time.Duration
+}
+
+// RollupMetricsUseCase инкрементально строит уровни агрегации 1m/5m/1h
+// Каждый уровень строится из предыдущего только до его watermark, поэтому бакеты всегда полные
+type RollupMetricsUseCase struct {
+	repository repository.MetricRollupRepository
+	config     RollupMetricsConfig
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewRollupMetricsUseCase создает новый use case
+func NewRollupMetricsUseCase(
+	repository repository.MetricRollupRepository,
+	config RollupMetricsConfig,
+	logger *logger.Logger,
+) *RollupMetricsUseCase {
+	if config.Lag < 0 {
+		config.Lag = 0
+	}
+	if config.Backfill <= 0 {
+		config.Backfill = 7 * 24 * time.Hour
+	}
+	if config.BucketsPerStep <= 0 {
+		config.BucketsPerStep = 60
+	}
+
+	return &RollupMetricsUseCase{
+		repository: repository,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Execute достраивает все уровни до последнего закрытого бакета и удаляет устаревшие бакеты
+func (uc *RollupMetricsUseCase) Execute(ctx context.Context) error {
+	now := uc.now()
+	limit := now.Add(-uc.config.Lag)
+
+	for _, tier := range valueobject.RollupTiers() {
+		// Уровень не может опережать свой источник
+		if source := tier.Source(); source != valueobject.TierRaw {
+			sourceWatermark, err := uc.repository.Watermark(ctx, source)
+			if err != nil {
+				return fmt.Errorf("failed to get %s rollup watermark: %w", source, err)
+			}
+			if sourceWatermark.Before(limit) {
+				limit = sourceWatermark
+			}
+		}
+
+		if err := uc.rollupTier(ctx, tier, limit.Truncate(tier.Resolution())); err != nil {
+			return err
+		}
+
+		if retention := uc.config.Retention[tier]; retention > 0 {
+			deleted, err := uc.repository.DeleteBefore(ctx, tier, now.Add(-retention))
+			if err != nil {
+				return fmt.Errorf("failed to delete expired %s rollups: %w", tier, err)
+			}
+			if deleted > 0 {
+				uc.logger.Debug("Expired rollups deleted", "tier", tier.String(), "deleted", deleted)
+			}
+		}
+	}
+
+	return nil
+}
+
+// rollupTier пересчитывает бакеты уровня от watermark до until шагами по BucketsPerStep бакетов
+// Rebuild пересчитывает уже построенные бакеты всех уровней, пересекающиеся с [from, to]
+// Нужен после загрузки точек задним числом: бакеты после watermark достроит обычный Execute
+func (uc *RollupMetricsUseCase) Rebuild(ctx context.Context, from, to time.Time) error {
+	var total int64
+	for _, tier := range valueobject.RollupTiers() {
+		resolution := tier.Resolution()
+
+		watermark, err := uc.repository.Watermark(ctx, tier)
+		if err != nil {
+			return fmt.Errorf("failed to get %s rollup watermark: %w", tier, err)
+		}
+
+		start := from.Truncate(resolution)
+		end := to.Truncate(resolution).Add(resolution)
+		if end.After(watermark) {
+			end = watermark
+		}
+
+		step := resolution * time.Duration(uc.config.BucketsPerStep)
+		for start.Before(end) {
+			stepEnd := start.Add(step)
+			if stepEnd.After(end) {
+				stepEnd = end
+			}
+
+			written, err := uc.repository.Rollup(ctx, tier, start, stepEnd)
+			if err != nil {
+				return fmt.Errorf("failed to rebuild %s rollups from %s: %w", tier, start, err)
+			}
+
+			total += written
+			start = stepEnd
+		}
+	}
+
+	uc.logger.Info("Rollups rebuilt", "from", from, "to", to, "buckets", total)
+	return nil
+}
+
+func (uc *RollupMetricsUseCase) rollupTier(ctx context.Context, tier valueobject.RollupTier, until time.Time) error {
+	resolution := tier.Resolution()
+
+	from, err := uc.repository.Watermark(ctx, tier)
+	if err != nil {
+		return fmt.Errorf("failed to get %s rollup watermark: %w", tier, err)
+	}
+	if from.IsZero() {
+		from = until.Add(-uc.config.Backfill).Truncate(resolution)
+	}
+
+	step := resolution * time.Duration(uc.config.BucketsPerStep)
+	var total int64
+	for from.Before(until) {
+		to := from.Add(step)
+		if to.After(until) {
+			to = until
+		}
+
+		written, err := uc.repository.Rollup(ctx, tier, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to build %s rollups from %s: %w", tier, from, err)
+		}
+		if err := uc.repository.SetWatermark(ctx, tier, to); err != nil {
+			return fmt.Errorf("failed to save %s rollup watermark: %w", tier, err)
+		}
+
+		total += written
+		from = to
+	}
+
+	if total > 0 {
+		uc.logger.Debug("Rollups built", "tier", tier.String(), "until", until, "buckets", total)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// rollupCall аргументы одного вызова Rollup
+type rollupCall struct {
+	tier     valueobject.RollupTier
+	from, to time.Time
+}
+
+// rollupMockRepository запоминает вызовы и хранит watermark уровней
+type rollupMockRepository struct {
+	watermarks map[valueobject.RollupTier]time.Time
+	rollups    map[valueobject.RollupTier][]*entity.MetricRollup
+	calls      []rollupCall
+	deleted    map[valueobject.RollupTier]time.Time
+}
+
+func newRollupMockRepository() *rollupMockRepository {
+	return &rollupMockRepository{
+		watermarks: make(map[valueobject.RollupTier]time.Time),
+		rollups:    make(map[valueobject.RollupTier][]*entity.MetricRollup),
+		deleted:    make(map[valueobject.RollupTier]time.Time),
+	}
+}
+
+func (m *rollupMockRepository) Rollup(_ context.Context, tier valueobject.RollupTier, from, to time.Time) (int64, error) {
+	m.calls = append(m.calls, rollupCall{tier: tier, from: from, to: to})
+	return 1, nil
+}
+
+func (m *rollupMockRepository) FindRollups(_ context.Context, tier valueobject.RollupTier, query repository.MetricQuery) ([]*entity.MetricRollup, error) {
+	var result []*entity.MetricRollup
+	for _, rollup := range m.rollups[tier] {
+		if query.TimeRange.Contains(rollup.BucketStart) {
+			result = append(result, rollup)
+		}
+	}
+	return result, nil
+}
+
+func (m *rollupMockRepository) Watermark(_ context.Context, tier valueobject.RollupTier) (time.Time, error) {
+	return m.watermarks[tier], nil
+}
+
+func (m *rollupMockRepository) SetWatermark(_ context.Context, tier valueobject.RollupTier, at time.Time) error {
+	m.watermarks[tier] = at
+	return nil
+}
+
+func (m *rollupMockRepository) DeleteBefore(_ context.Context, tier valueobject.RollupTier, before time.Time) (int64, error) {
+	m.deleted[tier] = before
+	return 0, nil
+}
+
+func (m *rollupMockRepository) callsFor(tier valueobject.RollupTier) []rollupCall {
+	var calls []rollupCall
+	for _, call := range m.calls {
+		if call.tier == tier {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func newRollupTestUseCase(repo *rollupMockRepository, config RollupMetricsConfig, now time.Time) *RollupMetricsUseCase {
+	uc := NewRollupMetricsUseCase(repo, config, logger.New("error"))
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestRollupMetricsBuildsTiersUpToSourceWatermark(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 7, 40, 0, time.UTC)
+	repo := newRollupMockRepository()
+	repo.watermarks[valueobject.Tier1m] = time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	repo.watermarks[valueobject.Tier5m] = time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	repo.watermarks[valueobject.Tier1h] = time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+
+	uc := newRollupTestUseCase(repo, RollupMetricsConfig{Lag: 30 * time.Second, BucketsPerStep: 30}, now)
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// 1m: 11:00 -> 12:07 шагами по 30 бакетов
+	minute := repo.callsFor(valueobject.Tier1m)
+	if len(minute) != 3 {
+		t.Fatalf("expected 3 steps for 1m tier, got %+v", minute)
+	}
+	if !minute[2].to.Equal(time.Date(2026, 3, 1, 12, 7, 0, 0, time.UTC)) {
+		t.Fatalf("1m tier must stop at the last closed bucket, got %s", minute[2].to)
+	}
+
+	// 5m строится только до watermark 1m, выровненного по 5 минутам
+	if got := repo.watermarks[valueobject.Tier5m]; !got.Equal(time.Date(2026, 3, 1, 12, 5, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected 5m watermark: %s", got)
+	}
+
+	// 1h: закрыт только бакет 11:00-12:00
+	hour := repo.callsFor(valueobject.Tier1h)
+	if len(hour) != 1 || !hour[0].to.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected 1h steps: %+v", hour)
+	}
+}
+
+func TestRollupMetricsBackfillAndRetention(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 10, 0, time.UTC)
+	repo := newRollupMockRepository()
+
+	uc := newRollupTestUseCase(repo, RollupMetricsConfig{
+		Backfill:       2 * time.Hour,
+		BucketsPerStep: 60,
+		Retention: map[valueobject.RollupTier]time.Duration{
+			valueobject.Tier1m: 48 * time.Hour,
+		},
+	}, now)
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	minute := repo.callsFor(valueobject.Tier1m)
+	if len(minute) != 2 || !minute[0].from.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected backfill from 10:00 in 2 steps, got %+v", minute)
+	}
+
+	if before, ok := repo.deleted[valueobject.Tier1m]; !ok || !before.Equal(now.Add(-48*time.Hour)) {
+		t.Fatalf("unexpected 1m retention cutoff: %v", repo.deleted)
+	}
+	if _, ok := repo.deleted[valueobject.Tier1h]; ok {
+		t.Fatal("tier without retention must be kept forever")
+	}
+
+	// Повторный запуск без новых закрытых бакетов ничего не пересчитывает
+	repo.calls = nil
+	if err := uc.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(repo.calls) != 0 {
+		t.Fatalf("expected no rollup calls, got %+v", repo.calls)
+	}
+}
+
+func TestRollupMetricsRebuildStopsAtWatermark(t *testing.T) {
+	repo := newRollupMockRepository()
+	repo.watermarks[valueobject.Tier1m] = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.watermarks[valueobject.Tier5m] = time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+
+	uc := newRollupTestUseCase(repo, RollupMetricsConfig{BucketsPerStep: 60}, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	err := uc.Rebuild(context.Background(),
+		time.Date(2026, 3, 1, 10, 58, 30, 0, time.UTC),
+		time.Date(2026, 3, 1, 11, 2, 10, 0, time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+
+	// 1m: бакеты 10:58-11:03, целиком до watermark
+	minute := repo.callsFor(valueobject.Tier1m)
+	if len(minute) != 1 || !minute[0].from.Equal(time.Date(2026, 3, 1, 10, 58, 0, 0, time.UTC)) || !minute[0].to.Equal(time.Date(2026, 3, 1, 11, 3, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected 1m rebuild: %+v", minute)
+	}
+
+	// 5m: бакет 11:00 еще не построен - его достроит Execute
+	five := repo.callsFor(valueobject.Tier5m)
+	if len(five) != 1 || !five[0].from.Equal(time.Date(2026, 3, 1, 10, 55, 0, 0, time.UTC)) || !five[0].to.Equal(time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected 5m rebuild: %+v", five)
+	}
+
+	// 1h еще не строился
+	if hour := repo.callsFor(valueobject.Tier1h); len(hour) != 0 {
+		t.Fatalf("unbuilt tier must not be rebuilt: %+v", hour)
+	}
+	if !repo.watermarks[valueobject.Tier1m].Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatal("Rebuild must not move watermarks")
+	}
+}
@@ -0,0 +1,275 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxAlertRuleNameLength ограничивает длину имени правила (размер колонки alert_rules.name)
+	maxAlertRuleNameLength = 100
+
+	// maxAlertRuleMetricNameLength ограничивает длину имени метрики (размер колонки alert_rules.metric_name)
+	maxAlertRuleMetricNameLength = 255
+
+	// defaultAlertWindow окно оценки по умолчанию
+	defaultAlertWindow = time.Minute
+
+	// maxAlertWindow ограничивает окно оценки, чтобы выборка помещалась в лимит репозитория
+	maxAlertWindow = 24 * time.Hour
+
+	// maxAlertRuleChannels ограничивает количество каналов уведомлений правила
+	maxAlertRuleChannels = 10
+)
+
+// channelNamePattern допустимое имя канала уведомлений
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// ValidateChannelName проверяет имя канала уведомлений (строчные буквы, цифры, '_' и '-')
+func ValidateChannelName(name string) error {
+	if !channelNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid channel name %q", name)
+	}
+	return nil
+}
+
+// AlertRuleParams параметры правила алертинга
+type AlertRuleParams struct {
+	Name        string
+	Description string
+
+	// MetricType, MetricName, Unit и Selector определяют метрики, к которым применяется правило
+	// Пустые MetricName и Unit означают любое имя и любую единицу измерения
+	MetricType valueobject.MetricType
+	MetricName string
+	Unit       string
+	Selector   valueobject.LabelSelector
+
+	// Aggregate агрегирует значения серии за Window, результат сравнивается с Threshold
+	Aggregate  valueobject.WindowAggregate
+	Window     time.Duration
+	Comparator valueobject.Comparator
+	Threshold  float64
+
+	// For - сколько условие должно выполняться, прежде чем алерт перейдет в firing
+	For time.Duration
+
+	// Cooldown - минимальный интервал между уведомлениями по одной серии
+	Cooldown time.Duration
+
+	Severity valueobject.AlertSeverity
+	Enabled  bool
+
+	// Channels - имена каналов уведомлений; пусто - каналы по умолчанию
+	Channels []string
+}
+
+// AlertRule правило алертинга (Aggregate Root)
+type AlertRule struct {
+	id        string
+	params    AlertRuleParams
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// NewAlertRule создает новое правило (Factory Method)
+func NewAlertRule(params AlertRuleParams) (*AlertRule, error) {
+	params, err := normalizeAlertRuleParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &AlertRule{
+		id:        uuid.New().String(),
+		params:    params,
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// ReconstructAlertRule восстанавливает правило из хранилища (для Repository)
+func ReconstructAlertRule(id string, params AlertRuleParams, createdAt, updatedAt time.Time) *AlertRule {
+	return &AlertRule{
+		id:        id,
+		params:    params,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+}
+
+// normalizeAlertRuleParams валидирует параметры и подставляет значения по умолчанию
+func normalizeAlertRuleParams(params AlertRuleParams) (AlertRuleParams, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		return params, errors.New("rule name cannot be empty")
+	}
+	if len(params.Name) > maxAlertRuleNameLength {
+		return params, fmt.Errorf("rule name is too long (max %d)", maxAlertRuleNameLength)
+	}
+
+	definition, ok := params.MetricType.Definition()
+	if !ok {
+		return params, errors.New("invalid metric type")
+	}
+	if len(params.MetricName) > maxAlertRuleMetricNameLength {
+		return params, fmt.Errorf("metric name is too long (max %d)", maxAlertRuleMetricNameLength)
+	}
+	params.Unit = strings.TrimSpace(params.Unit)
+	if params.Unit != "" && !definition.AllowsUnit(params.Unit) {
+		return params, fmt.Errorf("unit %q is not allowed for metric type %q", params.Unit, params.MetricType)
+	}
+
+	if params.Aggregate == "" {
+		params.Aggregate = valueobject.AggregateAvg
+	}
+	if err := params.Aggregate.Validate(); err != nil {
+		return params, err
+	}
+	if err := params.Comparator.Validate(); err != nil {
+		return params, err
+	}
+
+	if params.Severity == "" {
+		params.Severity = valueobject.SeverityWarning
+	}
+	if err := params.Severity.Validate(); err != nil {
+		return params, err
+	}
+
+	if params.Window == 0 {
+		params.Window = defaultAlertWindow
+	}
+	if params.Window < 0 || params.Window > maxAlertWindow {
+		return params, fmt.Errorf("window must be between 0 and %s", maxAlertWindow)
+	}
+	if params.For < 0 {
+		return params, errors.New("for duration cannot be negative")
+	}
+	if params.Cooldown < 0 {
+		return params, errors.New("cooldown cannot be negative")
+	}
+
+	if len(params.Channels) > maxAlertRuleChannels {
+		return params, fmt.Errorf("too many channels (max %d)", maxAlertRuleChannels)
+	}
+	channels := make([]string, 0, len(params.Channels))
+	seen := make(map[string]struct{}, len(params.Channels))
+	for _, channel := range params.Channels {
+		channel = strings.TrimSpace(channel)
+		if err := ValidateChannelName(channel); err != nil {
+			return params, err
+		}
+		if _, ok := seen[channel]; ok {
+			continue
+		}
+		seen[channel] = struct{}{}
+		channels = append(channels, channel)
+	}
+	params.Channels = channels
+
+	return params, nil
+}
+
+// ID возвращает идентификатор правила
+func (r *AlertRule) ID() string {
+	return r.id
+}
+
+// Name возвращает имя правила
+func (r *AlertRule) Name() string {
+	return r.params.Name
+}
+
+// Params возвращает копию параметров правила
+func (r *AlertRule) Params() AlertRuleParams {
+	return r.params
+}
+
+// MetricType возвращает тип метрик правила
+func (r *AlertRule) MetricType() valueobject.MetricType {
+	return r.params.MetricType
+}
+
+// Severity возвращает уровень важности правила
+func (r *AlertRule) Severity() valueobject.AlertSeverity {
+	return r.params.Severity
+}
+
+// Enabled проверяет, включено ли правило
+func (r *AlertRule) Enabled() bool {
+	return r.params.Enabled
+}
+
+// CreatedAt возвращает время создания
+func (r *AlertRule) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// UpdatedAt возвращает время последнего изменения
+func (r *AlertRule) UpdatedAt() time.Time {
+	return r.updatedAt
+}
+
+// Update заменяет параметры правила
+func (r *AlertRule) Update(params AlertRuleParams) error {
+	params, err := normalizeAlertRuleParams(params)
+	if err != nil {
+		return err
+	}
+
+	r.params = params
+	r.updatedAt = time.Now()
+	return nil
+}
+
+// Domain Methods (бизнес-логика)
+
+// Matches проверяет, относится ли метрика к правилу
+func (r *AlertRule) Matches(metric *Metric) bool {
+	if metric.Type() != r.params.MetricType {
+		return false
+	}
+	if r.params.MetricName != "" && metric.Name() != r.params.MetricName {
+		return false
+	}
+	// Порог сравнивается только со значениями в единице правила: байты не сравниваются с процентами
+	if r.params.Unit != "" && metric.Value().Unit() != r.params.Unit {
+		return false
+	}
+	return metric.MatchesSelector(r.params.Selector)
+}
+
+// Evaluate агрегирует значения окна (от старых к новым) и проверяет условие
+// Возвращает агрегированное значение и признак выполнения условия
+func (r *AlertRule) Evaluate(values []float64) (float64, bool) {
+	value, ok := r.params.Aggregate.Apply(values)
+	if !ok {
+		return 0, false
+	}
+	return value, r.params.Comparator.Compare(value, r.params.Threshold)
+}
+
+// Describe возвращает человекочитаемое условие, например "avg(cpu) over 1m0s > 90"
+func (r *AlertRule) Describe() string {
+	target := r.params.MetricType.String()
+	if r.params.MetricName != "" {
+		target = r.params.MetricName
+	}
+	if !r.params.Selector.IsEmpty() {
+		target += r.params.Selector.String()
+	}
+
+	condition := fmt.Sprintf("%s(%s) over %s %s %g",
+		r.params.Aggregate, target, r.params.Window, r.params.Comparator, r.params.Threshold)
+	if r.params.Unit != "" {
+		condition += " " + r.params.Unit
+	}
+	return condition
+}
@@ -0,0 +1,183 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// AlertStatus состояние алерта по одной серии
+type AlertStatus string
+
+const (
+	AlertInactive AlertStatus = "inactive"
+	AlertPending  AlertStatus = "pending"
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// AlertState отслеживает состояние правила для одной серии метрик
+// Переходы: inactive/resolved -> pending -> firing -> resolved
+type AlertState struct {
+	ruleID    string
+	seriesKey string
+	host      string
+	labels    valueobject.Labels
+
+	status      AlertStatus
+	value       float64
+	activeSince time.Time
+	firedAt     time.Time
+	resolvedAt  time.Time
+
+	lastNotifiedAt time.Time
+	notifiedFiring bool
+}
+
+// NewAlertState создает состояние серии в статусе inactive
+func NewAlertState(ruleID, seriesKey, host string, labels valueobject.Labels) *AlertState {
+	return &AlertState{
+		ruleID:    ruleID,
+		seriesKey: seriesKey,
+		host:      host,
+		labels:    labels,
+		status:    AlertInactive,
+	}
+}
+
+// RuleID возвращает идентификатор правила
+func (s *AlertState) RuleID() string {
+	return s.ruleID
+}
+
+// SeriesKey возвращает ключ серии
+func (s *AlertState) SeriesKey() string {
+	return s.seriesKey
+}
+
+// Host возвращает хост серии
+func (s *AlertState) Host() string {
+	return s.host
+}
+
+// Labels возвращает метки серии
+func (s *AlertState) Labels() valueobject.Labels {
+	return s.labels
+}
+
+// Status возвращает текущее состояние
+func (s *AlertState) Status() AlertStatus {
+	return s.status
+}
+
+// Value возвращает последнее агрегированное значение
+func (s *AlertState) Value() float64 {
+	return s.value
+}
+
+// ActiveSince возвращает момент, с которого условие выполняется
+func (s *AlertState) ActiveSince() time.Time {
+	return s.activeSince
+}
+
+// FiredAt возвращает момент перехода в firing
+func (s *AlertState) FiredAt() time.Time {
+	return s.firedAt
+}
+
+// ResolvedAt возвращает момент перехода в resolved
+func (s *AlertState) ResolvedAt() time.Time {
+	return s.resolvedAt
+}
+
+// Snapshot возвращает копию состояния, которую можно читать без синхронизации с дальнейшими переходами
+func (s *AlertState) Snapshot() *AlertState {
+	snapshot := *s
+	return &snapshot
+}
+
+// Observe применяет результат очередной оценки условия
+// Возвращает true, если состояние изменилось
+func (s *AlertState) Observe(conditionMet bool, value float64, forDuration time.Duration, now time.Time) bool {
+	s.value = value
+	previous := s.status
+
+	switch {
+	case conditionMet && (s.status == AlertInactive || s.status == AlertResolved):
+		s.activeSince = now
+		s.status = AlertPending
+		if forDuration <= 0 {
+			s.fire(now)
+		}
+	case conditionMet && s.status == AlertPending:
+		if now.Sub(s.activeSince) >= forDuration {
+			s.fire(now)
+		}
+	case !conditionMet && s.status == AlertPending:
+		s.status = AlertInactive
+		s.activeSince = time.Time{}
+	case !conditionMet && s.status == AlertFiring:
+		s.status = AlertResolved
+		s.resolvedAt = now
+	}
+
+	return s.status != previous
+}
+
+// RestoreFiring восстанавливает состояние firing, о котором уже было уведомлено до перезапуска:
+// повторного firing не будет, а при выходе серии из условия будет отправлено resolved
+func (s *AlertState) RestoreFiring(value float64, firedAt time.Time) {
+	s.value = value
+	s.activeSince = firedAt
+	s.fire(firedAt)
+	s.notifiedFiring = true
+	s.lastNotifiedAt = firedAt
+}
+
+func (s *AlertState) fire(now time.Time) {
+	s.status = AlertFiring
+	s.firedAt = now
+	s.resolvedAt = time.Time{}
+	s.notifiedFiring = false
+}
+
+// ShouldNotify определяет, нужно ли отправлять уведомление о текущем состоянии
+// firing уведомляется не чаще cooldown; resolved - только если был уведомлен firing
+func (s *AlertState) ShouldNotify(cooldown time.Duration, now time.Time) bool {
+	switch s.status {
+	case AlertFiring:
+		if s.notifiedFiring {
+			return false
+		}
+		return s.lastNotifiedAt.IsZero() || now.Sub(s.lastNotifiedAt) >= cooldown
+	case AlertResolved:
+		return s.notifiedFiring
+	default:
+		return false
+	}
+}
+
+// MarkNotified фиксирует отправку уведомления
+func (s *AlertState) MarkNotified(now time.Time) {
+	switch s.status {
+	case AlertFiring:
+		s.notifiedFiring = true
+		s.lastNotifiedAt = now
+	case AlertResolved:
+		s.notifiedFiring = false
+	}
+}
+
+// IsActive проверяет, находится ли алерт в pending или firing
+func (s *AlertState) IsActive() bool {
+	return s.status == AlertPending || s.status == AlertFiring
+}
+
+// IsStale проверяет, что состояние можно забыть: алерт не активен
+// и cooldown с момента последнего уведомления истек
+func (s *AlertState) IsStale(cooldown time.Duration, now time.Time) bool {
+	if s.IsActive() || (s.status == AlertResolved && s.notifiedFiring) {
+		return false
+	}
+	return s.lastNotifiedAt.IsZero() || now.Sub(s.lastNotifiedAt) >= cooldown
+}
@@ -0,0 +1,217 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/google/uuid"
+)
+
+// maxIncidentTitleLength ограничивает длину заголовка инцидента (размер колонки incidents.title)
+const maxIncidentTitleLength = 200
+
+// IncidentStatus состояние жизненного цикла инцидента
+type IncidentStatus string
+
+const (
+	IncidentOpen         IncidentStatus = "open"
+	IncidentAcknowledged IncidentStatus = "acknowledged"
+	IncidentResolved     IncidentStatus = "resolved"
+)
+
+// Validate проверяет валидность статуса
+func (s IncidentStatus) Validate() error {
+	switch s {
+	case IncidentOpen, IncidentAcknowledged, IncidentResolved:
+		return nil
+	default:
+		return fmt.Errorf("invalid incident status %q", s)
+	}
+}
+
+var (
+	// ErrIncidentAlreadyAcknowledged инцидент уже подтвержден
+	ErrIncidentAlreadyAcknowledged = errors.New("incident already acknowledged")
+
+	// ErrIncidentAlreadyResolved инцидент уже закрыт
+	ErrIncidentAlreadyResolved = errors.New("incident already resolved")
+)
+
+// IncidentParams описывает источник инцидента
+type IncidentParams struct {
+	// Fingerprint группирует повторные алерты одной серии в один инцидент
+	// SeriesKey - ключ серии алерта, по нему состояние алерта восстанавливается после перезапуска
+	Fingerprint string
+	RuleID      string
+	SeriesKey   string
+	Title       string
+	Severity    valueobject.AlertSeverity
+	Host        string
+	Labels      valueobject.Labels
+}
+
+// IncidentLifecycle содержит изменяемое состояние инцидента
+type IncidentLifecycle struct {
+	Status         IncidentStatus
+	LastValue      float64
+	LastMessage    string
+	AlertCount     int
+	OpenedAt       time.Time
+	LastAlertAt    time.Time
+	AcknowledgedAt time.Time
+	AcknowledgedBy string
+	ResolvedAt     time.Time
+	ResolvedBy     string
+	UpdatedAt      time.Time
+}
+
+// Incident инцидент, объединяющий повторные алерты одной серии (Aggregate Root)
+type Incident struct {
+	id        string
+	params    IncidentParams
+	lifecycle IncidentLifecycle
+}
+
+// NewIncident открывает новый инцидент по первому алерту (Factory Method)
+func NewIncident(params IncidentParams, value float64, message string, at time.Time) (*Incident, error) {
+	params.Title = strings.TrimSpace(params.Title)
+	if params.Title == "" {
+		return nil, errors.New("incident title is required")
+	}
+	if len(params.Title) > maxIncidentTitleLength {
+		return nil, fmt.Errorf("incident title is too long (max %d)", maxIncidentTitleLength)
+	}
+	if params.Severity == "" {
+		params.Severity = valueobject.SeverityWarning
+	}
+	if err := params.Severity.Validate(); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	if params.Fingerprint == "" {
+		// Инциденты без источника (созданные вручную) не группируются
+		params.Fingerprint = "manual|" + id
+	}
+
+	return &Incident{
+		id:     id,
+		params: params,
+		lifecycle: IncidentLifecycle{
+			Status:      IncidentOpen,
+			LastValue:   value,
+			LastMessage: message,
+			AlertCount:  1,
+			OpenedAt:    at,
+			LastAlertAt: at,
+			UpdatedAt:   at,
+		},
+	}, nil
+}
+
+// ReconstructIncident восстанавливает инцидент из хранилища (для Repository)
+func ReconstructIncident(id string, params IncidentParams, lifecycle IncidentLifecycle) *Incident {
+	return &Incident{
+		id:        id,
+		params:    params,
+		lifecycle: lifecycle,
+	}
+}
+
+// ID возвращает идентификатор инцидента
+func (i *Incident) ID() string {
+	return i.id
+}
+
+// Params возвращает параметры источника инцидента
+func (i *Incident) Params() IncidentParams {
+	return i.params
+}
+
+// Lifecycle возвращает состояние жизненного цикла
+func (i *Incident) Lifecycle() IncidentLifecycle {
+	return i.lifecycle
+}
+
+// Fingerprint возвращает ключ группировки
+func (i *Incident) Fingerprint() string {
+	return i.params.Fingerprint
+}
+
+// Title возвращает заголовок
+func (i *Incident) Title() string {
+	return i.params.Title
+}
+
+// Host возвращает хост
+func (i *Incident) Host() string {
+	return i.params.Host
+}
+
+// Status возвращает текущий статус
+func (i *Incident) Status() IncidentStatus {
+	return i.lifecycle.Status
+}
+
+// IsActive проверяет, что инцидент не закрыт
+func (i *Incident) IsActive() bool {
+	return i.lifecycle.Status != IncidentResolved
+}
+
+// ResolvedWithin проверяет, что инцидент закрыт менее window назад
+func (i *Incident) ResolvedWithin(window time.Duration, now time.Time) bool {
+	return i.lifecycle.Status == IncidentResolved && now.Sub(i.lifecycle.ResolvedAt) < window
+}
+
+// RecordAlert учитывает повторный алерт
+// Закрытый инцидент переоткрывается, подтверждение при этом сбрасывается
+func (i *Incident) RecordAlert(value float64, message string, at time.Time) {
+	if i.lifecycle.Status == IncidentResolved {
+		i.lifecycle.Status = IncidentOpen
+		i.lifecycle.AcknowledgedAt = time.Time{}
+		i.lifecycle.AcknowledgedBy = ""
+		i.lifecycle.ResolvedAt = time.Time{}
+		i.lifecycle.ResolvedBy = ""
+	}
+
+	i.lifecycle.LastValue = value
+	i.lifecycle.LastMessage = message
+	i.lifecycle.AlertCount++
+	i.lifecycle.LastAlertAt = at
+	i.lifecycle.UpdatedAt = at
+}
+
+// Acknowledge подтверждает инцидент
+func (i *Incident) Acknowledge(by string, at time.Time) error {
+	switch i.lifecycle.Status {
+	case IncidentAcknowledged:
+		return ErrIncidentAlreadyAcknowledged
+	case IncidentResolved:
+		return ErrIncidentAlreadyResolved
+	}
+
+	i.lifecycle.Status = IncidentAcknowledged
+	i.lifecycle.AcknowledgedAt = at
+	i.lifecycle.AcknowledgedBy = by
+	i.lifecycle.UpdatedAt = at
+	return nil
+}
+
+// Resolve закрывает инцидент
+func (i *Incident) Resolve(by, message string, at time.Time) error {
+	if i.lifecycle.Status == IncidentResolved {
+		return ErrIncidentAlreadyResolved
+	}
+
+	i.lifecycle.Status = IncidentResolved
+	i.lifecycle.ResolvedAt = at
+	i.lifecycle.ResolvedBy = by
+	if message != "" {
+		i.lifecycle.LastMessage = message
+	}
+	i.lifecycle.UpdatedAt = at
+	return nil
+}
@@ -13,6 +13,8 @@ type Metric struct {
 	id          string
 	metricType  valueobject.MetricType
 	metricName  string
+	host        string
+	labels      valueobject.Labels
 	value       valueobject.MetricValue
 	metadata    map[string]interface{}
 	collectedAt time.Time
@@ -24,6 +26,17 @@ func NewMetric(
 	metricType valueobject.MetricType,
 	metricName string,
 	value valueobject.MetricValue,
+) (*Metric, error) {
+	return NewMetricAt(metricType, metricName, value, time.Now())
+}
+
+// NewMetricAt создает новую метрику с заданным временем сбора
+// (используется для метрик, собранных удаленными агентами)
+func NewMetricAt(
+	metricType valueobject.MetricType,
+	metricName string,
+	value valueobject.MetricValue,
+	collectedAt time.Time,
 ) (*Metric, error) {
 	// Валидация типа метрики
 	if err := metricType.Validate(); err != nil {
@@ -31,6 +44,9 @@ func NewMetric(
 	}
 
 	now := time.Now()
+	if collectedAt.IsZero() {
+		collectedAt = now
+	}
 
 	return &Metric{
 		id:          uuid.New().String(),
@@ -38,7 +54,7 @@ func NewMetric(
 		metricName:  metricName,
 		value:       value,
 		metadata:    make(map[string]interface{}),
-		collectedAt: now,
+		collectedAt: collectedAt,
 		createdAt:   now,
 	}, nil
 }
@@ -48,6 +64,8 @@ func Reconstruct(
 	id string,
 	metricType valueobject.MetricType,
 	metricName string,
+	host string,
+	labels valueobject.Labels,
 	value valueobject.MetricValue,
 	metadata map[string]interface{},
 	collectedAt, createdAt time.Time,
@@ -60,6 +78,8 @@ func Reconstruct(
 		id:          id,
 		metricType:  metricType,
 		metricName:  metricName,
+		host:        host,
+		labels:      labels,
 		value:       value,
 		metadata:    metadata,
 		collectedAt: collectedAt,
@@ -82,6 +102,34 @@ func (m *Metric) Name() string {
 	return m.metricName
 }
 
+// Host возвращает идентификатор хоста, на котором собрана метрика
+func (m *Metric) Host() string {
+	return m.host
+}
+
+// Labels возвращает метки метрики
+func (m *Metric) Labels() valueobject.Labels {
+	return m.labels
+}
+
+// LabelValue возвращает значение метки с учетом зарезервированных host и __name__
+// Отсутствующая метка возвращается как пустая строка
+func (m *Metric) LabelValue(name string) string {
+	switch name {
+	case valueobject.HostLabel:
+		return m.host
+	case valueobject.NameLabel:
+		return m.metricName
+	}
+	value, _ := m.labels.Get(name)
+	return value
+}
+
+// SeriesKey возвращает ключ серии: метрики с одинаковым ключом образуют один временной ряд
+func (m *Metric) SeriesKey() string {
+	return m.metricType.String() + "|" + m.metricName + "|" + m.host + "|" + m.labels.String()
+}
+
 // Value возвращает значение метрики
 func (m *Metric) Value() valueobject.MetricValue {
 	return m.value
@@ -112,8 +160,23 @@ func (m *Metric) SetMetadata(key string, value interface{}) {
 	m.metadata[key] = value
 }
 
+// SetHost устанавливает идентификатор хоста
+func (m *Metric) SetHost(host string) {
+	m.host = host
+}
+
+// SetLabels устанавливает метки
+func (m *Metric) SetLabels(labels valueobject.Labels) {
+	m.labels = labels
+}
+
 // Domain Methods (бизнес-логика)
 
+// MatchesSelector проверяет, удовлетворяет ли метрика селектору меток
+func (m *Metric) MatchesSelector(selector valueobject.LabelSelector) bool {
+	return selector.Matches(m.LabelValue)
+}
+
 // IsStale проверяет, устарела ли метрика
 func (m *Metric) IsStale(threshold time.Duration) bool {
 	return time.Since(m.collectedAt) > threshold
@@ -125,31 +188,23 @@ func (m *Metric) ExceedsThreshold(threshold float64) bool {
 }
 
 // IsCritical проверяет, является ли значение метрики критическим
-// (Для CPU, Memory, Disk - более 90%)
+// Порог берется из описания типа в реестре (для CPU, Memory, Disk - более 90%)
 func (m *Metric) IsCritical() bool {
-	switch m.metricType {
-	case valueobject.CPU, valueobject.Memory, valueobject.Disk:
-		return m.value.Raw() > 90.0 && m.value.Unit() == "%"
-	case valueobject.Network:
-		// Для сети критическим считается более 100 MB/s
-		return m.value.Raw() > 100.0 && m.value.Unit() == "MB/s"
-	default:
+	definition, ok := m.metricType.Definition()
+	if !ok {
 		return false
 	}
+	return definition.IsCritical(m.value.Raw(), m.value.Unit())
 }
 
 // IsWarning проверяет, является ли значение метрики предупреждающим
-// (Для CPU, Memory, Disk - более 75%)
+// Порог берется из описания типа в реестре (для CPU, Memory, Disk - более 75%)
 func (m *Metric) IsWarning() bool {
-	switch m.metricType {
-	case valueobject.CPU, valueobject.Memory, valueobject.Disk:
-		return m.value.Raw() > 75.0 && m.value.Unit() == "%"
-	case valueobject.Network:
-		// Для сети предупреждение при более 50 MB/s
-		return m.value.Raw() > 50.0 && m.value.Unit() == "MB/s"
-	default:
+	definition, ok := m.metricType.Definition()
+	if !ok {
 		return false
 	}
+	return definition.IsWarning(m.value.Raw(), m.value.Unit())
 }
 
 // Age возвращает возраст метрики с момента сбора
@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// MetricRollup агрегат одной серии метрик за бакет уровня агрегации
+// Хранит сумму и количество, чтобы бакеты можно было объединять без потери точности среднего
+type MetricRollup struct {
+	Type        valueobject.MetricType
+	Name        string
+	Host        string
+	Labels      valueobject.Labels
+	Unit        string
+	BucketStart time.Time
+
+	Min    float64
+	Max    float64
+	Sum    float64
+	Count  int64
+	Last   float64
+	LastAt time.Time
+}
+
+// NewMetricRollupFrom создает бакет из одной метрики
+func NewMetricRollupFrom(metric *Metric, bucketStart time.Time) *MetricRollup {
+	value := metric.Value().Raw()
+	return &MetricRollup{
+		Type:        metric.Type(),
+		Name:        metric.Name(),
+		Host:        metric.Host(),
+		Labels:      metric.Labels(),
+		Unit:        metric.Value().Unit(),
+		BucketStart: bucketStart,
+		Min:         value,
+		Max:         value,
+		Sum:         value,
+		Count:       1,
+		Last:        value,
+		LastAt:      metric.CollectedAt(),
+	}
+}
+
+// Add добавляет точку в бакет
+func (r *MetricRollup) Add(value float64, at time.Time) {
+	if r.Count == 0 || value < r.Min {
+		r.Min = value
+	}
+	if r.Count == 0 || value > r.Max {
+		r.Max = value
+	}
+	r.Sum += value
+	r.Count++
+	if !at.Before(r.LastAt) {
+		r.Last = value
+		r.LastAt = at
+	}
+}
+
+// Merge добавляет в бакет агрегат другого бакета той же серии
+func (r *MetricRollup) Merge(other *MetricRollup) {
+	if other.Count == 0 {
+		return
+	}
+	if r.Count == 0 || other.Min < r.Min {
+		r.Min = other.Min
+	}
+	if r.Count == 0 || other.Max > r.Max {
+		r.Max = other.Max
+	}
+	r.Sum += other.Sum
+	r.Count += other.Count
+	if !other.LastAt.Before(r.LastAt) {
+		r.Last = other.Last
+		r.LastAt = other.LastAt
+	}
+}
+
+// Avg возвращает среднее значение бакета
+func (r *MetricRollup) Avg() float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return r.Sum / float64(r.Count)
+}
+
+// SeriesKey возвращает ключ серии (совпадает с Metric.SeriesKey)
+func (r *MetricRollup) SeriesKey() string {
+	return r.Type.String() + "|" + r.Name + "|" + r.Host + "|" + r.Labels.String()
+}
+
+// LabelValue возвращает значение метки с учетом зарезервированных host и __name__
+func (r *MetricRollup) LabelValue(name string) string {
+	switch name {
+	case valueobject.HostLabel:
+		return r.Host
+	case valueobject.NameLabel:
+		return r.Name
+	}
+	value, _ := r.Labels.Get(name)
+	return value
+}
+
+// IsCritical проверяет, превышает ли среднее значение бакета критический порог типа
+func (r *MetricRollup) IsCritical() bool {
+	definition, ok := r.Type.Definition()
+	if !ok {
+		return false
+	}
+	return definition.IsCritical(r.Avg(), r.Unit)
+}
+
+// IsWarning проверяет, превышает ли среднее значение бакета порог предупреждения типа
+func (r *MetricRollup) IsWarning() bool {
+	definition, ok := r.Type.Definition()
+	if !ok {
+		return false
+	}
+	return definition.IsWarning(r.Avg(), r.Unit)
+}
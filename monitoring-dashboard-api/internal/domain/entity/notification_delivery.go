@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxDeliveryErrorLength ограничивает длину сохраняемого текста ошибки доставки
+const maxDeliveryErrorLength = 1000
+
+// DeliveryStatus состояние доставки уведомления
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// NotificationDeliveryParams описывает, что и куда доставляется
+type NotificationDeliveryParams struct {
+	Channel     string
+	ChannelType string
+	RuleID      string
+	RuleName    string
+	Host        string
+	AlertState  string
+	Severity    string
+	Message     string
+}
+
+// NotificationDelivery запись журнала доставки уведомления в один канал (Entity)
+type NotificationDelivery struct {
+	id        string
+	params    NotificationDeliveryParams
+	status    DeliveryStatus
+	attempts  int
+	lastError string
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+// NewNotificationDelivery создает запись о доставке в статусе pending (Factory Method)
+func NewNotificationDelivery(params NotificationDeliveryParams, at time.Time) *NotificationDelivery {
+	return &NotificationDelivery{
+		id:        uuid.New().String(),
+		params:    params,
+		status:    DeliveryPending,
+		createdAt: at,
+		updatedAt: at,
+	}
+}
+
+// ReconstructNotificationDelivery восстанавливает запись из хранилища (для Repository)
+func ReconstructNotificationDelivery(
+	id string,
+	params NotificationDeliveryParams,
+	status DeliveryStatus,
+	attempts int,
+	lastError string,
+	createdAt, updatedAt time.Time,
+) *NotificationDelivery {
+	return &NotificationDelivery{
+		id:        id,
+		params:    params,
+		status:    status,
+		attempts:  attempts,
+		lastError: lastError,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+}
+
+// ID возвращает идентификатор записи
+func (d *NotificationDelivery) ID() string {
+	return d.id
+}
+
+// Params возвращает параметры доставки
+func (d *NotificationDelivery) Params() NotificationDeliveryParams {
+	return d.params
+}
+
+// Channel возвращает имя канала
+func (d *NotificationDelivery) Channel() string {
+	return d.params.Channel
+}
+
+// Status возвращает состояние доставки
+func (d *NotificationDelivery) Status() DeliveryStatus {
+	return d.status
+}
+
+// Attempts возвращает количество выполненных попыток
+func (d *NotificationDelivery) Attempts() int {
+	return d.attempts
+}
+
+// LastError возвращает ошибку последней неудачной попытки
+func (d *NotificationDelivery) LastError() string {
+	return d.lastError
+}
+
+// CreatedAt возвращает время постановки в очередь
+func (d *NotificationDelivery) CreatedAt() time.Time {
+	return d.createdAt
+}
+
+// UpdatedAt возвращает время последнего изменения
+func (d *NotificationDelivery) UpdatedAt() time.Time {
+	return d.updatedAt
+}
+
+// RecordAttempt фиксирует результат попытки доставки
+// final означает, что повторных попыток не будет
+func (d *NotificationDelivery) RecordAttempt(err error, final bool, at time.Time) {
+	d.attempts++
+	d.updatedAt = at
+
+	if err == nil {
+		d.status = DeliveryDelivered
+		d.lastError = ""
+		return
+	}
+
+	d.lastError = err.Error()
+	if len(d.lastError) > maxDeliveryErrorLength {
+		d.lastError = d.lastError[:maxDeliveryErrorLength]
+	}
+	if final {
+		d.status = DeliveryFailed
+	}
+}
+
+// Fail помечает доставку неуспешной без попытки (например, канал не найден)
+func (d *NotificationDelivery) Fail(err error, at time.Time) {
+	d.status = DeliveryFailed
+	d.lastError = err.Error()
+	d.updatedAt = at
+}
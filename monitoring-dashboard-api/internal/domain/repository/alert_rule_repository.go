@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+)
+
+// ErrAlertRuleNotFound возвращается, если правило алертинга не найдено
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+// AlertRuleRepository определяет интерфейс для хранения правил алертинга
+type AlertRuleRepository interface {
+	// Save создает или обновляет правило
+	Save(ctx context.Context, rule *entity.AlertRule) error
+
+	// FindByID находит правило по идентификатору
+	// Возвращает ErrAlertRuleNotFound, если правило не существует
+	FindByID(ctx context.Context, id string) (*entity.AlertRule, error)
+
+	// FindAll возвращает все правила, отсортированные по имени
+	FindAll(ctx context.Context) ([]*entity.AlertRule, error)
+
+	// FindEnabled возвращает только включенные правила
+	FindEnabled(ctx context.Context) ([]*entity.AlertRule, error)
+
+	// Delete удаляет правило
+	// Возвращает ErrAlertRuleNotFound, если правило не существует
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+)
+
+// ErrIncidentNotFound возвращается, если инцидент не найден
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// IncidentFilter параметры выборки инцидентов
+type IncidentFilter struct {
+	// Statuses ограничивает статусы (пусто - любые)
+	Statuses []entity.IncidentStatus
+	Host     string
+	Limit    int
+}
+
+// IncidentRepository определяет интерфейс для хранения инцидентов
+type IncidentRepository interface {
+	// Save создает или обновляет инцидент
+	Save(ctx context.Context, incident *entity.Incident) error
+
+	// FindByID находит инцидент по идентификатору
+	// Возвращает ErrIncidentNotFound, если инцидент не существует
+	FindByID(ctx context.Context, id string) (*entity.Incident, error)
+
+	// FindLatestByFingerprint возвращает последний открытый по fingerprint инцидент
+	// Возвращает ErrIncidentNotFound, если инцидентов с таким fingerprint нет
+	FindLatestByFingerprint(ctx context.Context, fingerprint string) (*entity.Incident, error)
+
+	// FindAll возвращает инциденты, начиная с самых новых
+	FindAll(ctx context.Context, filter IncidentFilter) ([]*entity.Incident, error)
+}
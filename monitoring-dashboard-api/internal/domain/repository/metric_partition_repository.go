@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// MetricPartition описывает партицию хранилища метрик с диапазоном [From, To) по времени сбора
+type MetricPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// MetricPartitionRepository определяет интерфейс управления партициями хранилища метрик (Port)
+type MetricPartitionRepository interface {
+	// ListPartitions возвращает партиции по диапазонам, отсортированные по From (без партиции по умолчанию)
+	ListPartitions(ctx context.Context) ([]MetricPartition, error)
+
+	// CreatePartition создает партицию [from, to)
+	// Метрики этого диапазона из партиции по умолчанию переносятся в новую партицию
+	CreatePartition(ctx context.Context, from, to time.Time) (MetricPartition, error)
+
+	// DropPartition удаляет партицию вместе с ее метриками
+	DropPartition(ctx context.Context, name string) error
+}
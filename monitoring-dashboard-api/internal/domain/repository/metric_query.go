@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// MetricQuery описывает выборку метрик по типу, имени, селектору меток и времени
+// Пустые поля не ограничивают выборку
+type MetricQuery struct {
+	// Type тип метрики (пустой - любой тип)
+	Type valueobject.MetricType
+
+	// Name имя метрики (пустое - любое имя)
+	Name string
+
+	// Selector условия на метки; host и __name__ сопоставляются с полями метрики
+	Selector valueobject.LabelSelector
+
+	// TimeRange временной диапазон (нулевой - без ограничения по времени)
+	TimeRange valueobject.TimeRange
+
+	// Limit максимальное количество записей (0 - лимит хранилища по умолчанию)
+	Limit int
+}
+
+// HasTimeRange проверяет, задан ли временной диапазон
+func (q MetricQuery) HasTimeRange() bool {
+	return !q.TimeRange.Start().IsZero() && !q.TimeRange.End().IsZero()
+}
+
+// MetricCursor позиция в выборке, упорядоченной по (collected_at, id)
+type MetricCursor struct {
+	CollectedAt time.Time
+	ID          string
+}
+
+// RetentionQuery описывает метрики с истекшим сроком хранения
+type RetentionQuery struct {
+	// Before удаляются метрики, собранные раньше этого момента
+	Before time.Time
+
+	// Types ограничивает выборку типами (пусто - любые типы)
+	Types []valueobject.MetricType
+
+	// ExcludeTypes исключает типы со своим сроком хранения
+	ExcludeTypes []valueobject.MetricType
+
+	// Limit размер пачки удаления (0 - без ограничения)
+	Limit int
+}
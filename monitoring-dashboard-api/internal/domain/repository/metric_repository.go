@@ -2,11 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 )
 
+// ErrMetricRejected возвращается SaveBatch, если хранилище отвергло данные пачки (нарушение ограничения,
+// недопустимое значение). Повторная запись той же пачки снова завершится ошибкой
+var ErrMetricRejected = errors.New("metric rejected by storage")
+
 // MetricRepository определяет интерфейс для работы с хранилищем метрик (Port)
 // Реализация будет в Infrastructure слое
 type MetricRepository interface {
@@ -29,15 +34,48 @@ type MetricRepository interface {
 		timeRange valueobject.TimeRange,
 	) ([]*entity.Metric, error)
 
-	// FindLatest находит последние метрики каждого типа
+	// FindByHostAndTimeRange находит метрики хоста по типу и временному диапазону
+	FindByHostAndTimeRange(
+		ctx context.Context,
+		host string,
+		metricType valueobject.MetricType,
+		timeRange valueobject.TimeRange,
+	) ([]*entity.Metric, error)
+
+	// FindLatest находит последние метрики каждого типа по всем хостам
+	// Из нескольких серий типа с одинаковым временем сбора выбирается серия с наибольшим значением
 	FindLatest(ctx context.Context) (map[valueobject.MetricType]*entity.Metric, error)
 
+	// FindLatestByHost находит последние метрики каждого типа для указанного хоста
+	FindLatestByHost(ctx context.Context, host string) (map[valueobject.MetricType]*entity.Metric, error)
+
 	// FindLatestByType находит последнюю метрику указанного типа
 	FindLatestByType(ctx context.Context, metricType valueobject.MetricType) (*entity.Metric, error)
 
+	// FindByLabels находит метрики, удовлетворяющие запросу (сортировка по времени, новые первыми)
+	FindByLabels(ctx context.Context, query MetricQuery) ([]*entity.Metric, error)
+
+	// FindPage находит до query.Limit метрик запроса по возрастанию (collected_at, id), строго после after
+	// (nil - с начала выборки). Позволяет обойти выборку любого размера страницами
+	FindPage(ctx context.Context, query MetricQuery, after *MetricCursor) ([]*entity.Metric, error)
+
+	// FindLatestSeries находит последнее значение каждой серии, удовлетворяющей запросу
+	FindLatestSeries(ctx context.Context, query MetricQuery) ([]*entity.Metric, error)
+
+	// GroupByLabel находит метрики по запросу и группирует их по значению метки label
+	// Метрики без метки попадают в группу с пустым ключом
+	GroupByLabel(ctx context.Context, query MetricQuery, label string) (map[string][]*entity.Metric, error)
+
 	// DeleteOlderThan удаляет метрики старше указанного времени
 	DeleteOlderThan(ctx context.Context, age valueobject.TimeRange) error
 
+	// CountExpired возвращает количество метрик с истекшим сроком хранения
+	CountExpired(ctx context.Context, query RetentionQuery) (int64, error)
+
+	// DeleteExpired удаляет не более query.Limit метрик с истекшим сроком хранения
+	// Возвращает количество удаленных строк
+	DeleteExpired(ctx context.Context, query RetentionQuery) (int64, error)
+
 	// Count возвращает количество метрик по типу
 	Count(ctx context.Context, metricType valueobject.MetricType) (int64, error)
 }
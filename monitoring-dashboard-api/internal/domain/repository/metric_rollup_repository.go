@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// MetricRollupRepository определяет интерфейс хранилища агрегатов метрик по уровням (Port)
+type MetricRollupRepository interface {
+	// Rollup пересчитывает бакеты уровня tier в интервале [from, to) из уровня tier.Source()
+	// Бакеты интервала перезаписываются целиком, поэтому повторный вызов безопасен
+	// Возвращает количество записанных бакетов
+	Rollup(ctx context.Context, tier valueobject.RollupTier, from, to time.Time) (int64, error)
+
+	// FindRollups находит бакеты уровня, удовлетворяющие запросу (сортировка по времени, новые первыми)
+	FindRollups(ctx context.Context, tier valueobject.RollupTier, query MetricQuery) ([]*entity.MetricRollup, error)
+
+	// Watermark возвращает момент, до которого уровень уже построен (нулевое время - уровень еще не строился)
+	Watermark(ctx context.Context, tier valueobject.RollupTier) (time.Time, error)
+
+	// SetWatermark сохраняет момент, до которого уровень построен
+	SetWatermark(ctx context.Context, tier valueobject.RollupTier, at time.Time) error
+
+	// DeleteBefore удаляет бакеты уровня, начавшиеся раньше before
+	// Возвращает количество удаленных строк
+	DeleteBefore(ctx context.Context, tier valueobject.RollupTier, before time.Time) (int64, error)
+}
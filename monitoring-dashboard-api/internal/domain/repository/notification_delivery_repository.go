@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+)
+
+// NotificationDeliveryFilter параметры выборки журнала доставки
+type NotificationDeliveryFilter struct {
+	Channel string
+	Status  entity.DeliveryStatus
+	Limit   int
+}
+
+// NotificationDeliveryRepository определяет интерфейс для хранения журнала доставки уведомлений
+type NotificationDeliveryRepository interface {
+	// Save создает или обновляет запись о доставке
+	Save(ctx context.Context, delivery *entity.NotificationDelivery) error
+
+	// FindRecent возвращает записи, начиная с самых новых
+	FindRecent(ctx context.Context, filter NotificationDeliveryFilter) ([]*entity.NotificationDelivery, error)
+}
@@ -3,8 +3,10 @@ package service
 import (
 	"errors"
 	"sort"
+	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 )
 
 // MetricAggregator предоставляет сервисы для агрегации метрик (Domain Service)
@@ -140,3 +142,115 @@ func (a *MetricAggregator) CalculatePercentile(metrics []*entity.Metric, percent
 
 	return sorted[index].Value().Raw(), nil
 }
+
+// RollupByBucket агрегирует метрики в бакеты шага resolution отдельно для каждой серии
+// Бакеты возвращаются отсортированными по времени (по возрастанию)
+func (a *MetricAggregator) RollupByBucket(metrics []*entity.Metric, resolution time.Duration) []*entity.MetricRollup {
+	if resolution <= 0 {
+		return nil
+	}
+
+	buckets := make(map[string]*entity.MetricRollup)
+	for _, m := range metrics {
+		bucketStart := valueobject.BucketStart(m.CollectedAt(), resolution)
+		key := m.SeriesKey() + "|" + bucketStart.String()
+		if rollup, ok := buckets[key]; ok {
+			rollup.Add(m.Value().Raw(), m.CollectedAt())
+			continue
+		}
+		buckets[key] = entity.NewMetricRollupFrom(m, bucketStart)
+	}
+
+	rollups := make([]*entity.MetricRollup, 0, len(buckets))
+	for _, rollup := range buckets {
+		rollups = append(rollups, rollup)
+	}
+
+	return a.SortRollupsByTime(rollups, false)
+}
+
+// BucketByStep агрегирует метрики диапазона timeRange в бакеты шага step отдельно для каждой серии
+// Метрики вне диапазона отбрасываются
+func (a *MetricAggregator) BucketByStep(
+	metrics []*entity.Metric,
+	timeRange valueobject.TimeRange,
+	step time.Duration,
+) []*entity.MetricRollup {
+	inRange := make([]*entity.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		if timeRange.Contains(m.CollectedAt()) {
+			inRange = append(inRange, m)
+		}
+	}
+
+	return a.RollupByBucket(inRange, step)
+}
+
+// MergeRollups объединяет бакеты в более крупные бакеты шага step отдельно для каждой серии
+// Шаг должен быть кратен шагу исходных бакетов, иначе бакет попадет в новый бакет целиком по своему началу
+func (a *MetricAggregator) MergeRollups(rollups []*entity.MetricRollup, step time.Duration) []*entity.MetricRollup {
+	if step <= 0 {
+		return nil
+	}
+
+	buckets := make(map[string]*entity.MetricRollup)
+	for _, r := range rollups {
+		bucketStart := valueobject.BucketStart(r.BucketStart, step)
+		key := r.SeriesKey() + "|" + bucketStart.String()
+		if merged, ok := buckets[key]; ok {
+			merged.Merge(r)
+			continue
+		}
+		merged := *r
+		merged.BucketStart = bucketStart
+		buckets[key] = &merged
+	}
+
+	merged := make([]*entity.MetricRollup, 0, len(buckets))
+	for _, r := range buckets {
+		merged = append(merged, r)
+	}
+
+	return a.SortRollupsByTime(merged, false)
+}
+
+// SummarizeRollups вычисляет среднее (с учетом количества точек в бакетах), минимум и максимум
+func (a *MetricAggregator) SummarizeRollups(rollups []*entity.MetricRollup) (avg, min, max float64, err error) {
+	if len(rollups) == 0 {
+		return 0, 0, 0, errors.New("no rollups to aggregate")
+	}
+
+	var sum float64
+	var count int64
+	min, max = rollups[0].Min, rollups[0].Max
+	for _, r := range rollups {
+		sum += r.Sum
+		count += r.Count
+		if r.Min < min {
+			min = r.Min
+		}
+		if r.Max > max {
+			max = r.Max
+		}
+	}
+	if count > 0 {
+		avg = sum / float64(count)
+	}
+
+	return avg, min, max, nil
+}
+
+// SortRollupsByTime сортирует бакеты по началу бакета
+func (a *MetricAggregator) SortRollupsByTime(rollups []*entity.MetricRollup, descending bool) []*entity.MetricRollup {
+	sorted := make([]*entity.MetricRollup, len(rollups))
+	copy(sorted, rollups)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].BucketStart.After(sorted[j].BucketStart)
+		}
+		return sorted[i].BucketStart.Before(sorted[j].BucketStart)
+	})
+
+	return sorted
+}
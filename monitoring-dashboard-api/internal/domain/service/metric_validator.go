@@ -2,12 +2,26 @@ package service
 
 import (
 	"errors"
+	"regexp"
 	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 )
 
+const (
+	// maxHostLength ограничивает длину идентификатора хоста (размер колонки metrics.host)
+	maxHostLength = 255
+
+	// maxMetricNameLength ограничивает длину имени метрики (размер колонки metrics.metric_name)
+	maxMetricNameLength = 255
+
+	// maxClockSkew допустимое опережение часов удаленного агента
+	maxClockSkew = 30 * time.Second
+)
+
+var hostPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
 // MetricValidator предоставляет сервисы для валидации метрик (Domain Service)
 type MetricValidator struct{}
 
@@ -27,6 +41,14 @@ func (v *MetricValidator) Validate(metric *entity.Metric) error {
 		return err
 	}
 
+	// Проверка имени: слишком длинное имя отклоняется здесь, а не ошибкой вставки всего пакета
+	if metric.Name() == "" {
+		return errors.New("metric name cannot be empty")
+	}
+	if len(metric.Name()) > maxMetricNameLength {
+		return errors.New("metric name is too long")
+	}
+
 	// Проверка значения
 	if metric.Value().Raw() < 0 {
 		return errors.New("metric value cannot be negative")
@@ -37,11 +59,18 @@ func (v *MetricValidator) Validate(metric *entity.Metric) error {
 		return errors.New("collected_at cannot be zero")
 	}
 
-	// Проверка, что метрика не из будущего
-	if metric.CollectedAt().After(time.Now()) {
+	// Проверка, что метрика не из будущего (с допуском на рассинхронизацию часов агентов)
+	if metric.CollectedAt().After(time.Now().Add(maxClockSkew)) {
 		return errors.New("collected_at cannot be in the future")
 	}
 
+	// Проверка идентификатора хоста (пустой допустим для метрик без привязки к хосту)
+	if metric.Host() != "" {
+		if err := v.ValidateHost(metric.Host()); err != nil {
+			return err
+		}
+	}
+
 	// Проверка валидности единиц измерения для типа метрики
 	if err := v.ValidateUnit(metric.Type(), metric.Value().Unit()); err != nil {
 		return err
@@ -52,25 +81,30 @@ func (v *MetricValidator) Validate(metric *entity.Metric) error {
 
 // ValidateUnit проверяет, соответствует ли единица измерения типу метрики
 func (v *MetricValidator) ValidateUnit(metricType valueobject.MetricType, unit string) error {
-	validUnits := map[valueobject.MetricType][]string{
-		valueobject.CPU:     {"%"},
-		valueobject.Memory:  {"%", "MB", "GB", "bytes"},
-		valueobject.Disk:    {"%", "MB", "GB", "TB", "bytes"},
-		valueobject.Network: {"KB/s", "MB/s", "GB/s", "bytes/s"},
-	}
-
-	allowedUnits, exists := validUnits[metricType]
+	definition, exists := metricType.Definition()
 	if !exists {
 		return errors.New("unknown metric type")
 	}
 
-	for _, allowedUnit := range allowedUnits {
-		if unit == allowedUnit {
-			return nil
-		}
+	if !definition.AllowsUnit(unit) {
+		return errors.New("invalid unit for metric type")
 	}
 
-	return errors.New("invalid unit for metric type")
+	return nil
+}
+
+// ValidateHost проверяет формат идентификатора хоста
+func (v *MetricValidator) ValidateHost(host string) error {
+	if host == "" {
+		return errors.New("host cannot be empty")
+	}
+	if len(host) > maxHostLength {
+		return errors.New("host is too long")
+	}
+	if !hostPattern.MatchString(host) {
+		return errors.New("host contains invalid characters")
+	}
+	return nil
 }
 
 // ValidateBatch валидирует группу метрик
@@ -87,27 +121,11 @@ func (v *MetricValidator) ValidateBatch(metrics []*entity.Metric) []error {
 }
 
 // IsReasonable проверяет, находится ли значение метрики в разумных пределах
+// Границы задаются в описании типа (например, проценты от 0 до 100)
 func (v *MetricValidator) IsReasonable(metric *entity.Metric) bool {
-	switch metric.Type() {
-	case valueobject.CPU, valueobject.Memory, valueobject.Disk:
-		// Процентные значения должны быть от 0 до 100
-		if metric.Value().Unit() == "%" {
-			val := metric.Value().Raw()
-			return val >= 0 && val <= 100
-		}
-		return true
-
-	case valueobject.Network:
-		// Сетевой трафик не должен быть чрезмерно большим (< 10 GB/s)
-		if metric.Value().Unit() == "GB/s" {
-			return metric.Value().Raw() < 10
-		}
-		if metric.Value().Unit() == "MB/s" {
-			return metric.Value().Raw() < 10000
-		}
-		return true
-
-	default:
+	definition, ok := metric.Type().Definition()
+	if !ok {
 		return true
 	}
+	return definition.IsReasonable(metric.Value().Raw(), metric.Value().Unit())
 }
@@ -0,0 +1,168 @@
+package valueobject
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Comparator оператор сравнения значения с порогом правила
+type Comparator string
+
+const (
+	ComparatorGreater        Comparator = ">"
+	ComparatorGreaterOrEqual Comparator = ">="
+	ComparatorLess           Comparator = "<"
+	ComparatorLessOrEqual    Comparator = "<="
+	ComparatorEqual          Comparator = "=="
+	ComparatorNotEqual       Comparator = "!="
+)
+
+// Validate проверяет валидность оператора
+func (c Comparator) Validate() error {
+	switch c {
+	case ComparatorGreater, ComparatorGreaterOrEqual, ComparatorLess,
+		ComparatorLessOrEqual, ComparatorEqual, ComparatorNotEqual:
+		return nil
+	default:
+		return errors.New("invalid comparator")
+	}
+}
+
+// Compare применяет оператор: value <op> threshold
+func (c Comparator) Compare(value, threshold float64) bool {
+	switch c {
+	case ComparatorGreater:
+		return value > threshold
+	case ComparatorGreaterOrEqual:
+		return value >= threshold
+	case ComparatorLess:
+		return value < threshold
+	case ComparatorLessOrEqual:
+		return value <= threshold
+	case ComparatorEqual:
+		return value == threshold
+	case ComparatorNotEqual:
+		return value != threshold
+	default:
+		return false
+	}
+}
+
+// WindowAggregate функция агрегации значений в окне оценки правила
+type WindowAggregate string
+
+const (
+	AggregateAvg  WindowAggregate = "avg"
+	AggregateMin  WindowAggregate = "min"
+	AggregateMax  WindowAggregate = "max"
+	AggregateP95  WindowAggregate = "p95"
+	AggregateLast WindowAggregate = "last"
+)
+
+// Validate проверяет валидность функции агрегации
+func (a WindowAggregate) Validate() error {
+	switch a {
+	case AggregateAvg, AggregateMin, AggregateMax, AggregateP95, AggregateLast:
+		return nil
+	default:
+		return errors.New("invalid aggregate")
+	}
+}
+
+// Apply агрегирует значения, упорядоченные по времени (от старых к новым)
+// Возвращает false для пустого набора
+func (a WindowAggregate) Apply(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	switch a {
+	case AggregateAvg:
+		sum := 0.0
+		for _, value := range values {
+			sum += value
+		}
+		return sum / float64(len(values)), true
+	case AggregateMin:
+		result := values[0]
+		for _, value := range values[1:] {
+			result = math.Min(result, value)
+		}
+		return result, true
+	case AggregateMax:
+		result := values[0]
+		for _, value := range values[1:] {
+			result = math.Max(result, value)
+		}
+		return result, true
+	case AggregateP95:
+		return Percentile(values, 0.95), true
+	case AggregateLast:
+		return values[len(values)-1], true
+	default:
+		return 0, false
+	}
+}
+
+// Percentile вычисляет перцентиль методом nearest-rank (q от 0 до 1)
+func Percentile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// AlertSeverity уровень важности алерта
+type AlertSeverity string
+
+const (
+	SeverityInfo     AlertSeverity = "info"
+	SeverityWarning  AlertSeverity = "warning"
+	SeverityCritical AlertSeverity = "critical"
+)
+
+// Validate проверяет валидность уровня важности
+func (s AlertSeverity) Validate() error {
+	switch s {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+		return nil
+	default:
+		return errors.New("invalid severity")
+	}
+}
+
+// String возвращает строковое представление уровня важности
+func (s AlertSeverity) String() string {
+	return string(s)
+}
+
+// Rank возвращает порядковый номер уровня (info < warning < critical)
+func (s AlertSeverity) Rank() int {
+	switch s {
+	case SeverityInfo:
+		return 1
+	case SeverityWarning:
+		return 2
+	case SeverityCritical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// AtLeast проверяет, что уровень не ниже min; пустой min пропускает любой уровень
+func (s AlertSeverity) AtLeast(min AlertSeverity) bool {
+	return min == "" || s.Rank() >= min.Rank()
+}
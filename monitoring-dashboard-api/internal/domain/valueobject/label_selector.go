@@ -0,0 +1,262 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MatchType тип сравнения значения метки
+type MatchType string
+
+const (
+	MatchEqual     MatchType = "="
+	MatchNotEqual  MatchType = "!="
+	MatchRegexp    MatchType = "=~"
+	MatchNotRegexp MatchType = "!~"
+)
+
+// LabelMatcher представляет условие на значение одной метки (Value Object)
+// Отсутствующая метка трактуется как пустая строка
+type LabelMatcher struct {
+	name      string
+	matchType MatchType
+	value     string
+	re        *regexp.Regexp
+}
+
+// NewLabelMatcher создает LabelMatcher с валидацией
+func NewLabelMatcher(name string, matchType MatchType, value string) (LabelMatcher, error) {
+	if err := ValidateLabelName(name); err != nil {
+		return LabelMatcher{}, err
+	}
+
+	matcher := LabelMatcher{
+		name:      name,
+		matchType: matchType,
+		value:     value,
+	}
+
+	switch matchType {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		// Регулярное выражение якорится целиком, как в Prometheus
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return LabelMatcher{}, fmt.Errorf("invalid regexp for label %q: %w", name, err)
+		}
+		matcher.re = re
+	default:
+		return LabelMatcher{}, fmt.Errorf("invalid match type %q", matchType)
+	}
+
+	return matcher, nil
+}
+
+// Name возвращает имя метки
+func (m LabelMatcher) Name() string {
+	return m.name
+}
+
+// Type возвращает тип сравнения
+func (m LabelMatcher) Type() MatchType {
+	return m.matchType
+}
+
+// Value возвращает значение (или регулярное выражение) для сравнения
+func (m LabelMatcher) Value() string {
+	return m.value
+}
+
+// Matches проверяет значение метки
+func (m LabelMatcher) Matches(value string) bool {
+	switch m.matchType {
+	case MatchEqual:
+		return value == m.value
+	case MatchNotEqual:
+		return value != m.value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	default:
+		return false
+	}
+}
+
+// String возвращает представление вида name="value"
+func (m LabelMatcher) String() string {
+	return m.name + string(m.matchType) + strconv.Quote(m.value)
+}
+
+// LabelSelector набор условий на метки, объединенных через AND (Value Object)
+type LabelSelector []LabelMatcher
+
+// NewLabelSelector создает селектор из набора условий
+func NewLabelSelector(matchers ...LabelMatcher) LabelSelector {
+	return LabelSelector(matchers)
+}
+
+// IsEmpty проверяет, что селектор не содержит условий
+func (s LabelSelector) IsEmpty() bool {
+	return len(s) == 0
+}
+
+// Matches проверяет набор меток; lookup возвращает значение метки по имени
+func (s LabelSelector) Matches(lookup func(name string) string) bool {
+	for _, matcher := range s {
+		if !matcher.Matches(lookup(matcher.name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// With возвращает новый селектор с дополнительным условием
+func (s LabelSelector) With(matcher LabelMatcher) LabelSelector {
+	result := make(LabelSelector, 0, len(s)+1)
+	result = append(result, s...)
+	return append(result, matcher)
+}
+
+// String возвращает представление вида {a="1",b=~"x.*"}
+func (s LabelSelector) String() string {
+	parts := make([]string, 0, len(s))
+	for _, matcher := range s {
+		parts = append(parts, matcher.String())
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// ParseLabelSelector разбирает селектор вида `host="web-01",mount=~"/data.*"`
+// Фигурные скобки необязательны, кавычки можно опустить для простых значений
+func ParseLabelSelector(input string) (LabelSelector, error) {
+	p := &selectorParser{input: strings.TrimSpace(input)}
+
+	if strings.HasPrefix(p.input, "{") {
+		if !strings.HasSuffix(p.input, "}") {
+			return nil, errors.New("selector: missing closing brace")
+		}
+		p.input = strings.TrimSpace(p.input[1 : len(p.input)-1])
+	}
+
+	selector := make(LabelSelector, 0)
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+
+		matcher, err := p.parseMatcher()
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, matcher)
+
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+		if p.input[p.pos] != ',' {
+			return nil, fmt.Errorf("selector: expected ',' at position %d", p.pos)
+		}
+		p.pos++
+	}
+
+	return selector, nil
+}
+
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *selectorParser) skipSpaces() {
+	for !p.eof() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseMatcher() (LabelMatcher, error) {
+	start := p.pos
+	for !p.eof() && isLabelNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	if name == "" {
+		return LabelMatcher{}, fmt.Errorf("selector: expected label name at position %d", start)
+	}
+
+	p.skipSpaces()
+	var matchType MatchType
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "=~"):
+		matchType = MatchRegexp
+	case strings.HasPrefix(p.input[p.pos:], "!~"):
+		matchType = MatchNotRegexp
+	case strings.HasPrefix(p.input[p.pos:], "!="):
+		matchType = MatchNotEqual
+	case strings.HasPrefix(p.input[p.pos:], "="):
+		matchType = MatchEqual
+	default:
+		return LabelMatcher{}, fmt.Errorf("selector: expected operator after %q", name)
+	}
+	p.pos += len(matchType)
+	p.skipSpaces()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return LabelMatcher{}, err
+	}
+
+	return NewLabelMatcher(name, matchType, value)
+}
+
+func (p *selectorParser) parseValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+
+	quote := p.input[p.pos]
+	if quote != '"' && quote != '\'' {
+		start := p.pos
+		for !p.eof() && p.input[p.pos] != ',' {
+			p.pos++
+		}
+		return strings.TrimSpace(p.input[start:p.pos]), nil
+	}
+
+	start := p.pos
+	p.pos++
+	for !p.eof() {
+		switch p.input[p.pos] {
+		case '\\':
+			p.pos += 2
+			continue
+		case quote:
+			p.pos++
+			raw := p.input[start:p.pos]
+			if quote == '\'' {
+				raw = `"` + strings.ReplaceAll(raw[1:len(raw)-1], `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(raw)
+			if err != nil {
+				return "", fmt.Errorf("selector: invalid quoted value %s", p.input[start:p.pos])
+			}
+			return value, nil
+		}
+		p.pos++
+	}
+
+	return "", errors.New("selector: unterminated quoted value")
+}
+
+func isLabelNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
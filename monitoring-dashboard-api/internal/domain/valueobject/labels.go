@@ -0,0 +1,135 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HostLabel зарезервированная метка, соответствующая хосту метрики
+	HostLabel = "host"
+
+	// NameLabel зарезервированная метка, соответствующая имени метрики
+	NameLabel = "__name__"
+
+	// maxLabelValueLength ограничивает длину значения метки
+	maxLabelValueLength = 255
+
+	// maxLabelsPerMetric ограничивает количество меток у одной метрики
+	maxLabelsPerMetric = 32
+)
+
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Labels представляет набор меток метрики (Value Object)
+// Иммутабельный объект: метки задают измерение серии (mount, interface, core, environment...)
+type Labels struct {
+	values map[string]string
+}
+
+// NewLabels создает Labels с валидацией имен и значений
+// Метки host и __name__ зарезервированы: они хранятся в отдельных полях метрики
+func NewLabels(values map[string]string) (Labels, error) {
+	if len(values) > maxLabelsPerMetric {
+		return Labels{}, fmt.Errorf("too many labels: %d (max %d)", len(values), maxLabelsPerMetric)
+	}
+
+	copied := make(map[string]string, len(values))
+	for name, value := range values {
+		if err := ValidateLabelName(name); err != nil {
+			return Labels{}, err
+		}
+		if name == HostLabel || strings.HasPrefix(name, "__") {
+			return Labels{}, fmt.Errorf("label %q is reserved", name)
+		}
+		if len(value) > maxLabelValueLength {
+			return Labels{}, fmt.Errorf("label %q value is too long", name)
+		}
+		// Пустое значение эквивалентно отсутствию метки
+		if value == "" {
+			continue
+		}
+		copied[name] = value
+	}
+
+	return Labels{values: copied}, nil
+}
+
+// ValidateLabelName проверяет формат имени метки
+func ValidateLabelName(name string) error {
+	if name == "" {
+		return errors.New("label name cannot be empty")
+	}
+	if !labelNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid label name %q", name)
+	}
+	return nil
+}
+
+// Get возвращает значение метки
+func (l Labels) Get(name string) (string, bool) {
+	value, ok := l.values[name]
+	return value, ok
+}
+
+// Len возвращает количество меток
+func (l Labels) Len() int {
+	return len(l.values)
+}
+
+// IsEmpty проверяет, что меток нет
+func (l Labels) IsEmpty() bool {
+	return len(l.values) == 0
+}
+
+// Names возвращает отсортированные имена меток
+func (l Labels) Names() []string {
+	names := make([]string, 0, len(l.values))
+	for name := range l.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Map возвращает копию меток
+func (l Labels) Map() map[string]string {
+	result := make(map[string]string, len(l.values))
+	for name, value := range l.values {
+		result[name] = value
+	}
+	return result
+}
+
+// String возвращает каноническое представление: {a="1",b="2"}
+func (l Labels) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range l.Names() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l.values[name]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Equals сравнивает два набора меток
+func (l Labels) Equals(other Labels) bool {
+	if len(l.values) != len(other.values) {
+		return false
+	}
+	for name, value := range l.values {
+		if otherValue, ok := other.values[name]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
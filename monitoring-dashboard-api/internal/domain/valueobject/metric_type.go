@@ -1,25 +1,68 @@
 package valueobject
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
 
 // MetricType представляет тип метрики (Value Object)
+// Допустимые типы определяются реестром типов (MetricTypeRegistry)
 type MetricType string
 
+// Встроенные типы метрик, которые собирает сам сервис
 const (
-	CPU     MetricType = "cpu"
-	Memory  MetricType = "memory"
-	Disk    MetricType = "disk"
-	Network MetricType = "network"
+	CPU             MetricType = "cpu"
+	Memory          MetricType = "memory"
+	Disk            MetricType = "disk"
+	Network         MetricType = "network"
+	CPUCore         MetricType = "cpu_core"
+	CPUMode         MetricType = "cpu_mode"
+	Load            MetricType = "load"
+	DiskSpace       MetricType = "disk_space"
+	DiskInodes      MetricType = "disk_inodes"
+	DiskIO          MetricType = "disk_io"
+	NetworkRecv     MetricType = "network_recv"
+	NetworkPackets  MetricType = "network_packets"
+	NetworkErrors   MetricType = "network_errors"
+	TCPConnections  MetricType = "tcp_connections"
+	ContainerCPU    MetricType = "container_cpu"
+	ContainerMemory MetricType = "container_memory"
+	ContainerIO     MetricType = "container_io"
+	Pressure        MetricType = "pressure"
+	NodeExporter    MetricType = "node_exporter"
 )
 
-// Validate проверяет валидность типа метрики
+// maxMetricTypeLength ограничивает длину имени типа (размер колонки metrics.metric_type)
+const maxMetricTypeLength = 20
+
+var metricTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ValidateFormat проверяет только формат имени типа, без обращения к реестру
+func (mt MetricType) ValidateFormat() error {
+	if mt == "" {
+		return errors.New("metric type cannot be empty")
+	}
+	if len(mt) > maxMetricTypeLength {
+		return fmt.Errorf("metric type is too long (max %d)", maxMetricTypeLength)
+	}
+	if !metricTypePattern.MatchString(string(mt)) {
+		return errors.New("metric type must match [a-z][a-z0-9_]*")
+	}
+	return nil
+}
+
+// Validate проверяет, что тип метрики зарегистрирован
 func (mt MetricType) Validate() error {
-	switch mt {
-	case CPU, Memory, Disk, Network:
-		return nil
-	default:
+	if _, ok := mt.Definition(); !ok {
 		return errors.New("invalid metric type")
 	}
+	return nil
+}
+
+// Definition возвращает описание типа из реестра по умолчанию
+func (mt MetricType) Definition() (MetricTypeDefinition, bool) {
+	return DefaultMetricTypeRegistry().Lookup(mt)
 }
 
 // String возвращает строковое представление типа метрики
@@ -27,7 +70,7 @@ func (mt MetricType) String() string {
 	return string(mt)
 }
 
-// AllMetricTypes возвращает список всех допустимых типов метрик
+// AllMetricTypes возвращает список всех зарегистрированных типов метрик
 func AllMetricTypes() []MetricType {
-	return []MetricType{CPU, Memory, Disk, Network}
+	return DefaultMetricTypeRegistry().Types()
 }
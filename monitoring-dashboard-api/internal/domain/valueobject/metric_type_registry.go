@@ -0,0 +1,363 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxUnitLength ограничивает длину единицы измерения (размер колонки metrics.unit)
+const maxUnitLength = 10
+
+// Thresholds пороги предупреждения и критического состояния для типа метрики
+// Пороги применяются только к значениям в единице Unit; нулевой порог отключен
+type Thresholds struct {
+	Unit     string
+	Warning  float64
+	Critical float64
+}
+
+// MetricTypeDefinition описывает тип метрики: допустимые единицы, пороги и отображение
+type MetricTypeDefinition struct {
+	Type        MetricType
+	DisplayName string
+	Description string
+
+	// Units допустимые единицы измерения
+	Units []string
+
+	// Thresholds пороги по умолчанию
+	Thresholds Thresholds
+
+	// MaxValues верхняя граница правдоподобного значения для единицы измерения
+	MaxValues map[string]float64
+}
+
+// Validate проверяет корректность описания типа
+func (d MetricTypeDefinition) Validate() error {
+	if err := d.Type.ValidateFormat(); err != nil {
+		return err
+	}
+	if len(d.Units) == 0 {
+		return fmt.Errorf("metric type %q: at least one unit is required", d.Type)
+	}
+	for _, unit := range d.Units {
+		if unit == "" || len(unit) > maxUnitLength {
+			return fmt.Errorf("metric type %q: invalid unit %q", d.Type, unit)
+		}
+	}
+
+	t := d.Thresholds
+	if t.Warning != 0 || t.Critical != 0 {
+		if !d.AllowsUnit(t.Unit) {
+			return fmt.Errorf("metric type %q: threshold unit %q is not allowed", d.Type, t.Unit)
+		}
+		if t.Warning != 0 && t.Critical != 0 && t.Warning > t.Critical {
+			return fmt.Errorf("metric type %q: warning threshold exceeds critical", d.Type)
+		}
+	}
+
+	for unit := range d.MaxValues {
+		if !d.AllowsUnit(unit) {
+			return fmt.Errorf("metric type %q: max value unit %q is not allowed", d.Type, unit)
+		}
+	}
+
+	return nil
+}
+
+// AllowsUnit проверяет, допустима ли единица измерения для типа
+func (d MetricTypeDefinition) AllowsUnit(unit string) bool {
+	for _, allowed := range d.Units {
+		if unit == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCritical проверяет, превышает ли значение критический порог
+func (d MetricTypeDefinition) IsCritical(value float64, unit string) bool {
+	t := d.Thresholds
+	return t.Critical != 0 && unit == t.Unit && value > t.Critical
+}
+
+// IsWarning проверяет, превышает ли значение порог предупреждения
+func (d MetricTypeDefinition) IsWarning(value float64, unit string) bool {
+	t := d.Thresholds
+	return t.Warning != 0 && unit == t.Unit && value > t.Warning
+}
+
+// IsReasonable проверяет, находится ли значение в правдоподобных пределах
+func (d MetricTypeDefinition) IsReasonable(value float64, unit string) bool {
+	if value < 0 {
+		return false
+	}
+	if maxValue, ok := d.MaxValues[unit]; ok {
+		return value <= maxValue
+	}
+	return true
+}
+
+// BuiltinMetricTypes возвращает описания встроенных типов cpu, memory, disk, network,
+// а также детализации CPU: cpu_core (по ядрам), cpu_mode (по режимам) и load (load average)
+// дисков: disk_space (байты по разделам), disk_inodes (заполненность inode) и disk_io (ввод-вывод)
+// сети: network_recv (прием), network_packets, network_errors (ошибки и отброшенные пакеты) и tcp_connections
+// cgroup v2: container_cpu, container_memory, container_io (относительно лимитов контейнера) и pressure (PSI)
+// и node_exporter (серии node_exporter, принятые по remote_write, в исходных единицах)
+func BuiltinMetricTypes() []MetricTypeDefinition {
+	percent := Thresholds{Unit: "%", Warning: 75, Critical: 90}
+
+	return []MetricTypeDefinition{
+		{
+			Type:        CPU,
+			DisplayName: "CPU Usage",
+			Units:       []string{"%"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        Memory,
+			DisplayName: "Memory Usage",
+			Units:       []string{"%", "MB", "GB", "bytes"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        Disk,
+			DisplayName: "Disk Usage",
+			Units:       []string{"%", "MB", "GB", "TB", "bytes"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        Network,
+			DisplayName: "Network Sent",
+			Units:       []string{"KB/s", "MB/s", "GB/s", "bytes/s"},
+			Thresholds:  Thresholds{Unit: "MB/s", Warning: 50, Critical: 100},
+			// Сетевой трафик не должен быть чрезмерно большим (< 10 GB/s)
+			MaxValues: map[string]float64{"MB/s": 10000, "GB/s": 10},
+		},
+		{
+			Type:        CPUCore,
+			DisplayName: "CPU Core Usage",
+			Units:       []string{"%"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        CPUMode,
+			DisplayName: "CPU Time by Mode",
+			Description: "Share of CPU time spent in user, system, iowait, steal, idle and other modes",
+			Units:       []string{"%"},
+			// Порогов нет: высокая доля idle - норма, для steal и iowait пороги задаются правилами алертов
+			MaxValues: map[string]float64{"%": 100},
+		},
+		{
+			Type:        Load,
+			DisplayName: "Load Average",
+			Description: "Run queue length averaged over 1, 5 and 15 minutes",
+			// Нормальная нагрузка зависит от числа ядер, поэтому пороги по умолчанию не задаются
+			Units: []string{"load"},
+		},
+		{
+			Type:        DiskSpace,
+			DisplayName: "Disk Space",
+			Description: "Used and free bytes per mount point",
+			Units:       []string{"bytes", "MB", "GB", "TB"},
+		},
+		{
+			Type:        DiskInodes,
+			DisplayName: "Disk Inodes Usage",
+			Units:       []string{"%"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        DiskIO,
+			DisplayName: "Disk I/O",
+			Description: "Read/write throughput, operations per second and average await per block device",
+			Units:       []string{"bytes/s", "KB/s", "MB/s", "ops/s", "ms"},
+		},
+		{
+			Type:        NetworkRecv,
+			DisplayName: "Network Received",
+			Units:       []string{"KB/s", "MB/s", "GB/s", "bytes/s"},
+			Thresholds:  Thresholds{Unit: "MB/s", Warning: 50, Critical: 100},
+			MaxValues:   map[string]float64{"MB/s": 10000, "GB/s": 10},
+		},
+		{
+			Type:        NetworkPackets,
+			DisplayName: "Network Packets",
+			Description: "Packets sent and received per second per interface",
+			Units:       []string{"packets/s"},
+		},
+		{
+			Type:        NetworkErrors,
+			DisplayName: "Network Errors",
+			Description: "Errored and dropped packets per second per interface and direction",
+			// Любые ошибки заслуживают внимания, но допустимый уровень зависит от сети: пороги задаются правилами алертов
+			Units: []string{"packets/s"},
+		},
+		{
+			Type:        TCPConnections,
+			DisplayName: "TCP Connections",
+			Description: "Number of TCP connections (IPv4 and IPv6) in each state",
+			Units:       []string{"count"},
+		},
+		{
+			Type:        ContainerCPU,
+			DisplayName: "Container CPU",
+			Description: "CPU usage relative to the cgroup limit and share of throttled CFS periods",
+			Units:       []string{"%"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        ContainerMemory,
+			DisplayName: "Container Memory",
+			Description: "Working set relative to the cgroup memory limit and memory sizes in bytes",
+			Units:       []string{"%", "bytes"},
+			Thresholds:  percent,
+			MaxValues:   map[string]float64{"%": 100},
+		},
+		{
+			Type:        ContainerIO,
+			DisplayName: "Container I/O",
+			Description: "Read/write throughput and operations per second of the cgroup per block device",
+			Units:       []string{"bytes/s", "ops/s"},
+		},
+		{
+			Type:        Pressure,
+			DisplayName: "Pressure Stall",
+			Description: "Share of time tasks of the cgroup stalled on CPU, memory or I/O (PSI)",
+			// Допустимый уровень задержек зависит от нагрузки: пороги задаются правилами алертов
+			Units:     []string{"%"},
+			MaxValues: map[string]float64{"%": 100},
+		},
+		{
+			Type:        NodeExporter,
+			DisplayName: "Node Exporter",
+			Description: "Series of Prometheus node_exporter received over remote_write: gauges and raw counters",
+			// Счетчики (_total) хранятся как есть, без пересчета в проценты: порогов нет
+			Units: []string{"bytes", "seconds", "count"},
+		},
+	}
+}
+
+// MetricTypeRegistry реестр допустимых типов метрик
+// Потокобезопасен; порядок типов соответствует порядку регистрации
+type MetricTypeRegistry struct {
+	mu          sync.RWMutex
+	definitions map[MetricType]MetricTypeDefinition
+	order       []MetricType
+}
+
+// NewMetricTypeRegistry создает реестр с указанными типами
+func NewMetricTypeRegistry(definitions ...MetricTypeDefinition) (*MetricTypeRegistry, error) {
+	registry := &MetricTypeRegistry{
+		definitions: make(map[MetricType]MetricTypeDefinition),
+	}
+
+	for _, definition := range definitions {
+		if err := registry.Register(definition); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// Register добавляет тип или заменяет описание уже зарегистрированного типа
+func (r *MetricTypeRegistry) Register(definition MetricTypeDefinition) error {
+	if err := definition.Validate(); err != nil {
+		return err
+	}
+
+	definition.Units = append([]string(nil), definition.Units...)
+	maxValues := make(map[string]float64, len(definition.MaxValues))
+	for unit, value := range definition.MaxValues {
+		maxValues[unit] = value
+	}
+	definition.MaxValues = maxValues
+
+	if definition.DisplayName == "" {
+		definition.DisplayName = definition.Type.String()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.definitions[definition.Type]; !exists {
+		r.order = append(r.order, definition.Type)
+	}
+	r.definitions[definition.Type] = definition
+
+	return nil
+}
+
+// Lookup возвращает описание типа
+func (r *MetricTypeRegistry) Lookup(metricType MetricType) (MetricTypeDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definition, ok := r.definitions[metricType]
+	return definition, ok
+}
+
+// ResolveByPrefix возвращает тип, имя которого с "_" является префиксом name
+// Из нескольких подходящих типов выбирается самый длинный: cpu_core_seconds относится к cpu_core, а не к cpu
+func (r *MetricTypeRegistry) ResolveByPrefix(name string) (MetricTypeDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var best MetricType
+	for _, metricType := range r.order {
+		if len(metricType) > len(best) && strings.HasPrefix(name, metricType.String()+"_") {
+			best = metricType
+		}
+	}
+	if best == "" {
+		return MetricTypeDefinition{}, false
+	}
+	return r.definitions[best], true
+}
+
+// Types возвращает зарегистрированные типы в порядке регистрации
+func (r *MetricTypeRegistry) Types() []MetricType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]MetricType(nil), r.order...)
+}
+
+// Definitions возвращает описания всех типов в порядке регистрации
+func (r *MetricTypeRegistry) Definitions() []MetricTypeDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]MetricTypeDefinition, 0, len(r.order))
+	for _, metricType := range r.order {
+		result = append(result, r.definitions[metricType])
+	}
+	return result
+}
+
+var (
+	defaultRegistryOnce sync.Once
+	defaultRegistry     *MetricTypeRegistry
+)
+
+// DefaultMetricTypeRegistry возвращает общий реестр процесса
+// Изначально содержит встроенные типы; дополнительные типы регистрируются при старте из конфигурации
+func DefaultMetricTypeRegistry() *MetricTypeRegistry {
+	defaultRegistryOnce.Do(func() {
+		registry, err := NewMetricTypeRegistry(BuiltinMetricTypes()...)
+		if err != nil {
+			panic(errors.New("invalid builtin metric types: " + err.Error()))
+		}
+		defaultRegistry = registry
+	})
+	return defaultRegistry
+}
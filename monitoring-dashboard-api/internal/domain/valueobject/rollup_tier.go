@@ -0,0 +1,91 @@
+package valueobject
+
+import (
+	"errors"
+	"time"
+)
+
+// RollupTier уровень агрегации истории метрик
+type RollupTier string
+
+const (
+	TierRaw RollupTier = "raw" // сырые точки без агрегации
+	Tier1m  RollupTier = "1m"
+	Tier5m  RollupTier = "5m"
+	Tier1h  RollupTier = "1h"
+)
+
+// RollupTiers возвращает уровни агрегации в порядке увеличения шага
+// Каждый уровень строится из предыдущего (1m - из сырых метрик)
+func RollupTiers() []RollupTier {
+	return []RollupTier{Tier1m, Tier5m, Tier1h}
+}
+
+// SelectRollupTier выбирает уровень для периода так, чтобы на серию приходилось
+// не больше ~2000 точек: до 1h - сырые данные, до 24h - 1m, до 7d - 5m, дальше - 1h
+func SelectRollupTier(duration time.Duration) RollupTier {
+	switch {
+	case duration <= time.Hour:
+		return TierRaw
+	case duration <= 24*time.Hour:
+		return Tier1m
+	case duration <= 7*24*time.Hour:
+		return Tier5m
+	default:
+		return Tier1h
+	}
+}
+
+// SelectRollupTierForStep выбирает самый крупный уровень, из бакетов которого
+// складываются бакеты шага step; для шага меньше минуты используются сырые данные
+func SelectRollupTierForStep(step time.Duration) RollupTier {
+	tiers := RollupTiers()
+	for i := len(tiers) - 1; i >= 0; i-- {
+		resolution := tiers[i].Resolution()
+		if step >= resolution && step%resolution == 0 {
+			return tiers[i]
+		}
+	}
+	return TierRaw
+}
+
+// Validate проверяет валидность уровня
+func (t RollupTier) Validate() error {
+	switch t {
+	case TierRaw, Tier1m, Tier5m, Tier1h:
+		return nil
+	default:
+		return errors.New("invalid rollup tier")
+	}
+}
+
+// Resolution возвращает шаг бакета уровня (0 для сырых данных)
+func (t RollupTier) Resolution() time.Duration {
+	switch t {
+	case Tier1m:
+		return time.Minute
+	case Tier5m:
+		return 5 * time.Minute
+	case Tier1h:
+		return time.Hour
+	default:
+		return 0
+	}
+}
+
+// Source возвращает уровень, из которого строится данный
+func (t RollupTier) Source() RollupTier {
+	switch t {
+	case Tier5m:
+		return Tier1m
+	case Tier1h:
+		return Tier5m
+	default:
+		return TierRaw
+	}
+}
+
+// String возвращает строковое представление уровня
+func (t RollupTier) String() string {
+	return string(t)
+}
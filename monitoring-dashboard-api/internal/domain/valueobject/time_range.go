@@ -2,6 +2,8 @@ package valueobject
 
 import (
 	"errors"
+	"math"
+	"strconv"
 	"time"
 )
 
@@ -43,6 +45,27 @@ func NewTimeRangeFromDuration(duration time.Duration) (TimeRange, error) {
 	}, nil
 }
 
+// ParseTimestamp разбирает момент времени в формате RFC3339 или unix-секунд (с дробной частью)
+func ParseTimestamp(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("timestamp is empty")
+	}
+
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		if math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds <= 0 {
+			return time.Time{}, errors.New("unix timestamp must be positive")
+		}
+		whole, frac := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(frac*float64(time.Second))).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return time.Time{}, errors.New("timestamp must be RFC3339 or unix seconds")
+	}
+	return t, nil
+}
+
 // Start возвращает начальное время
 func (tr TimeRange) Start() time.Time {
 	return tr.start
@@ -67,3 +90,22 @@ func (tr TimeRange) Contains(t time.Time) bool {
 func (tr TimeRange) Overlaps(other TimeRange) bool {
 	return tr.start.Before(other.end) && other.start.Before(tr.end)
 }
+
+// BucketStart возвращает начало бакета шага step, в который попадает t
+// Бакеты выровнены по unix-эпохе, поэтому совпадают с бакетами уровней агрегации
+func BucketStart(t time.Time, step time.Duration) time.Time {
+	if step <= 0 {
+		return t
+	}
+	return t.Truncate(step)
+}
+
+// BucketCount возвращает количество бакетов шага step, покрывающих диапазон
+func (tr TimeRange) BucketCount(step time.Duration) int {
+	if step <= 0 {
+		return 0
+	}
+	first := BucketStart(tr.start, step)
+	last := BucketStart(tr.end, step)
+	return int(last.Sub(first)/step) + 1
+}
@@ -0,0 +1,434 @@
+package collector
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// defaultCgroupRoot точка монтирования единой иерархии cgroup v2
+const defaultCgroupRoot = "/sys/fs/cgroup"
+
+// CgroupConfig настройки сборщика ресурсов cgroup v2 (контейнер, под, systemd unit)
+type CgroupConfig struct {
+	// Enabled включает сборщик; без cgroup v2 он ничего не отдает
+	Enabled bool
+
+	// Root точка монтирования cgroup2 (пусто - /sys/fs/cgroup)
+	Root string
+
+	// Path путь cgroup относительно Root (пусто - cgroup текущего процесса из /proc/self/cgroup)
+	Path string
+}
+
+// cgroupCPUStat счетчики cpu.stat
+type cgroupCPUStat struct {
+	usageUsec     uint64
+	nrPeriods     uint64
+	nrThrottled   uint64
+	throttledUsec uint64
+}
+
+// cgroupIOStat счетчики одного устройства из io.stat
+type cgroupIOStat struct {
+	rbytes, wbytes, rios, wios uint64
+}
+
+// CgroupCollector собирает потребление ресурсов cgroup v2 относительно ее лимитов:
+// CPU и троттлинг (cpu.stat, cpu.max), память (memory.current, memory.max, memory.stat),
+// ввод-вывод (io.stat) и PSI (cpu.pressure, memory.pressure, io.pressure)
+// Скорости считаются по разнице счетчиков между соседними вызовами Collect
+type CgroupCollector struct {
+	config CgroupConfig
+
+	mu       sync.Mutex
+	lastDir  string
+	lastCPU  *cgroupCPUStat
+	lastIO   map[string]cgroupIOStat
+	lastTime time.Time
+
+	// selfCgroup, sysBlock, numCPU и now подменяются в тестах
+	selfCgroup string
+	sysBlock   string
+	numCPU     func() int
+	now        func() time.Time
+}
+
+// NewCgroupCollector создает новый cgroup collector
+func NewCgroupCollector(config CgroupConfig) *CgroupCollector {
+	if config.Root == "" {
+		config.Root = defaultCgroupRoot
+	}
+
+	return &CgroupCollector{
+		config:     config,
+		lastIO:     make(map[string]cgroupIOStat),
+		selfCgroup: "/proc/self/cgroup",
+		sysBlock:   "/sys/dev/block",
+		numCPU:     runtime.NumCPU,
+		now:        time.Now,
+	}
+}
+
+// Collect собирает метрики cgroup:
+// container_cpu_usage (% от лимита CPU) и container_cpu_throttled (% периодов с троттлингом),
+// container_memory_usage (% рабочего набора от лимита) и размеры памяти в байтах,
+// скорости ввода-вывода по устройствам и pressure_stall{resource,kind,window}
+// Без cgroup v2 (нет cgroup.controllers) возвращает пустой список
+func (c *CgroupCollector) Collect(_ context.Context) ([]port.RawMetric, error) {
+	dir, ok := c.cgroupDir()
+	if !ok {
+		return nil, nil
+	}
+
+	metrics := c.memoryMetrics(dir)
+
+	c.mu.Lock()
+	now := c.now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	// Переезд в другую cgroup обнуляет историю счетчиков
+	if dir != c.lastDir {
+		c.lastDir = dir
+		c.lastCPU = nil
+		c.lastIO = make(map[string]cgroupIOStat)
+	}
+	metrics = append(metrics, c.cpuMetrics(dir, elapsed)...)
+	metrics = append(metrics, c.ioMetrics(dir, elapsed)...)
+	c.lastTime = now
+	c.mu.Unlock()
+
+	metrics = append(metrics, pressureMetrics(dir)...)
+	return metrics, nil
+}
+
+// cgroupDir каталог cgroup, метрики которой собираются
+// Путь из /proc/self/cgroup, которого нет в смонтированной иерархии (контейнер без cgroup namespace
+// видит путь хоста, но монтирует собственную cgroup), заменяется корнем иерархии
+func (c *CgroupCollector) cgroupDir() (string, bool) {
+	root := c.config.Root
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return "", false
+	}
+
+	relative := c.config.Path
+	if relative == "" {
+		relative = readSelfCgroup(c.selfCgroup)
+	}
+
+	dir := filepath.Join(root, filepath.Clean("/"+relative))
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return root, true
+	}
+	return dir, true
+}
+
+// readSelfCgroup путь единой иерархии (строка "0::/path") из /proc/self/cgroup
+func readSelfCgroup(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return strings.TrimSpace(path)
+		}
+	}
+	return ""
+}
+
+// memoryMetrics метрики памяти; корневая cgroup не имеет memory.current и пропускается
+// Рабочий набор (memory.current без inactive_file) - то, что kubelet сравнивает с лимитом при вытеснении
+func (c *CgroupCollector) memoryMetrics(dir string) []port.RawMetric {
+	current, ok := readCgroupUint(filepath.Join(dir, "memory.current"))
+	if !ok {
+		return nil
+	}
+
+	workingSet := current
+	if stat, err := readCgroupKeyValues(filepath.Join(dir, "memory.stat")); err == nil {
+		workingSet -= min(stat["inactive_file"], current)
+	}
+
+	metrics := []port.RawMetric{
+		cgroupMetric(valueobject.ContainerMemory, "container_memory_current", float64(current), "bytes", nil),
+		cgroupMetric(valueobject.ContainerMemory, "container_memory_working_set", float64(workingSet), "bytes", nil),
+	}
+
+	// "max" - лимита нет: доля от лимита не определена
+	if limit, limited := readCgroupUint(filepath.Join(dir, "memory.max")); limited && limit > 0 {
+		usage := cgroupMetric(valueobject.ContainerMemory, "container_memory_usage", min(float64(workingSet)/float64(limit)*100, 100), "%", nil)
+		usage.Metadata = map[string]interface{}{"limit_bytes": limit, "current_bytes": current}
+		metrics = append(metrics,
+			usage,
+			cgroupMetric(valueobject.ContainerMemory, "container_memory_limit", float64(limit), "bytes", nil),
+		)
+	}
+
+	return metrics
+}
+
+// cpuMetrics загрузка CPU относительно лимита и доля периодов с троттлингом
+// Лимит - квота cpu.max, без квоты - число доступных ядер (cpuset.cpus.effective)
+func (c *CgroupCollector) cpuMetrics(dir string, elapsed float64) []port.RawMetric {
+	values, err := readCgroupKeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil
+	}
+	stat := cgroupCPUStat{
+		usageUsec:     values["usage_usec"],
+		nrPeriods:     values["nr_periods"],
+		nrThrottled:   values["nr_throttled"],
+		throttledUsec: values["throttled_usec"],
+	}
+
+	last := c.lastCPU
+	c.lastCPU = &stat
+	// Уменьшение счетчиков означает пересозданную cgroup: интервал пропускается
+	if last == nil || elapsed <= 0 || stat.usageUsec < last.usageUsec || stat.nrPeriods < last.nrPeriods ||
+		stat.nrThrottled < last.nrThrottled || stat.throttledUsec < last.throttledUsec {
+		return nil
+	}
+
+	limitCores, quota := c.cpuLimit(dir)
+	usage := float64(stat.usageUsec-last.usageUsec) / (elapsed * 1e6 * limitCores) * 100
+	usageMetric := cgroupMetric(valueobject.ContainerCPU, "container_cpu_usage", min(usage, 100), "%", nil)
+	usageMetric.Metadata = map[string]interface{}{"limit_cores": limitCores, "quota": quota}
+	metrics := []port.RawMetric{usageMetric}
+
+	// Периоды есть только при заданной квоте
+	if periods := stat.nrPeriods - last.nrPeriods; periods > 0 {
+		throttled := cgroupMetric(valueobject.ContainerCPU, "container_cpu_throttled",
+			float64(stat.nrThrottled-last.nrThrottled)/float64(periods)*100, "%", nil)
+		throttled.Metadata = map[string]interface{}{
+			"periods":      periods,
+			"throttled_ms": float64(stat.throttledUsec-last.throttledUsec) / 1000,
+		}
+		metrics = append(metrics, throttled)
+	}
+
+	return metrics
+}
+
+// cpuLimit лимит CPU в ядрах; quota = true, если лимит задан квотой cpu.max
+func (c *CgroupCollector) cpuLimit(dir string) (float64, bool) {
+	if data, err := os.ReadFile(filepath.Join(dir, "cpu.max")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			quota, errQuota := strconv.ParseFloat(fields[0], 64)
+			period, errPeriod := strconv.ParseFloat(fields[1], 64)
+			if errQuota == nil && errPeriod == nil && quota > 0 && period > 0 {
+				return quota / period, true
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "cpuset.cpus.effective")); err == nil {
+		if cpus := countCPUList(strings.TrimSpace(string(data))); cpus > 0 {
+			return float64(cpus), false
+		}
+	}
+	return float64(max(c.numCPU(), 1)), false
+}
+
+// countCPUList считает ядра в списке вида "0-3,6,8-9"
+func countCPUList(list string) int {
+	count := 0
+	for _, part := range strings.Split(list, ",") {
+		if part == "" {
+			continue
+		}
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(from)
+		if err != nil {
+			return 0
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(to); err != nil || last < first {
+				return 0
+			}
+		}
+		count += last - first + 1
+	}
+	return count
+}
+
+// ioMetrics скорости ввода-вывода cgroup по устройствам из io.stat
+func (c *CgroupCollector) ioMetrics(dir string, elapsed float64) []port.RawMetric {
+	current, err := readCgroupIOStat(filepath.Join(dir, "io.stat"))
+	if err != nil {
+		return nil
+	}
+
+	devices := make([]string, 0, len(current))
+	for device := range current {
+		devices = append(devices, device)
+	}
+	sort.Strings(devices)
+
+	var metrics []port.RawMetric
+	for _, device := range devices {
+		stat := current[device]
+		previous, ok := c.lastIO[device]
+		if !ok || elapsed <= 0 || stat.rbytes < previous.rbytes || stat.wbytes < previous.wbytes ||
+			stat.rios < previous.rios || stat.wios < previous.wios {
+			continue
+		}
+
+		labels := map[string]string{"device": c.deviceName(device)}
+		metrics = append(metrics,
+			cgroupMetric(valueobject.ContainerIO, "container_io_read_bytes_per_second", float64(stat.rbytes-previous.rbytes)/elapsed, "bytes/s", labels),
+			cgroupMetric(valueobject.ContainerIO, "container_io_write_bytes_per_second", float64(stat.wbytes-previous.wbytes)/elapsed, "bytes/s", labels),
+			cgroupMetric(valueobject.ContainerIO, "container_io_reads_per_second", float64(stat.rios-previous.rios)/elapsed, "ops/s", labels),
+			cgroupMetric(valueobject.ContainerIO, "container_io_writes_per_second", float64(stat.wios-previous.wios)/elapsed, "ops/s", labels),
+		)
+	}
+
+	c.lastIO = current
+	return metrics
+}
+
+// deviceName имя блочного устройства (sda, nvme0n1) по номеру "major:minor"; без sysfs - сам номер
+func (c *CgroupCollector) deviceName(device string) string {
+	data, err := os.ReadFile(filepath.Join(c.sysBlock, device, "uevent"))
+	if err != nil {
+		return device
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if name, ok := strings.CutPrefix(line, "DEVNAME="); ok && name != "" {
+			return name
+		}
+	}
+	return device
+}
+
+// readCgroupIOStat разбирает строки io.stat вида "8:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=0 dios=0"
+func readCgroupIOStat(file string) (map[string]cgroupIOStat, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := make(map[string]cgroupIOStat)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var stat cgroupIOStat
+		for _, field := range fields[1:] {
+			key, raw, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				stat.rbytes = value
+			case "wbytes":
+				stat.wbytes = value
+			case "rios":
+				stat.rios = value
+			case "wios":
+				stat.wios = value
+			}
+		}
+		stats[fields[0]] = stat
+	}
+	return stats, scanner.Err()
+}
+
+// pressureMetrics доля времени, когда задачи cgroup простаивали из-за нехватки ресурса (PSI)
+// some - хотя бы одна задача, full - все задачи одновременно; окна 10s, 60s и 300s
+func pressureMetrics(dir string) []port.RawMetric {
+	var metrics []port.RawMetric
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		data, err := os.ReadFile(filepath.Join(dir, resource+".pressure"))
+		if err != nil {
+			// PSI выключен в ядре (psi=0) или недоступен для этой cgroup
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 || (fields[0] != "some" && fields[0] != "full") {
+				continue
+			}
+			for _, field := range fields[1:] {
+				key, raw, _ := strings.Cut(field, "=")
+				window, ok := strings.CutPrefix(key, "avg")
+				if !ok {
+					continue
+				}
+				value, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					continue
+				}
+				metrics = append(metrics, cgroupMetric(valueobject.Pressure, "pressure_stall", min(value, 100), "%", map[string]string{
+					"resource": resource,
+					"kind":     fields[0],
+					"window":   window + "s",
+				}))
+			}
+		}
+	}
+	return metrics
+}
+
+// readCgroupUint читает файл с одним числом (memory.current, memory.max)
+// ok = false для нечитаемого файла и значения "max" (лимит не задан)
+func readCgroupUint(file string) (uint64, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return value, err == nil
+}
+
+// readCgroupKeyValues разбирает файлы вида "key value" (cpu.stat, memory.stat)
+func readCgroupKeyValues(file string) (map[string]uint64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, raw, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if value, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64); err == nil {
+			values[key] = value
+		}
+	}
+	return values, scanner.Err()
+}
+
+// cgroupMetric создает метрику; значения неотрицательны, поэтому ошибка NewMetricValue невозможна
+func cgroupMetric(metricType valueobject.MetricType, name string, raw float64, unit string, labels map[string]string) port.RawMetric {
+	value, _ := valueobject.NewMetricValue(max(raw, 0), unit)
+	return port.RawMetric{
+		Type:   metricType,
+		Name:   name,
+		Value:  value,
+		Labels: labels,
+	}
+}
@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// writeCgroupFiles создает файлы поддельной cgroupfs
+func writeCgroupFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestCgroupCollector сборщик над поддельной иерархией root; процесс находится в /kubepods/pod1
+func newTestCgroupCollector(t *testing.T, root string) *CgroupCollector {
+	t.Helper()
+	collector := NewCgroupCollector(CgroupConfig{Enabled: true, Root: root})
+
+	selfCgroup := filepath.Join(t.TempDir(), "cgroup")
+	writeCgroupFiles(t, filepath.Dir(selfCgroup), map[string]string{"cgroup": "0::/kubepods/pod1\n"})
+	collector.selfCgroup = selfCgroup
+	collector.sysBlock = filepath.Join(t.TempDir(), "block")
+	collector.numCPU = func() int { return 8 }
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ticks := 0
+	collector.now = func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * 10 * time.Second)
+	}
+	return collector
+}
+
+// cgroupValues значения метрик по ключу "name{device}" или "name{resource,kind,window}"
+func cgroupValues(metrics []port.RawMetric, metricType valueobject.MetricType) map[string]float64 {
+	values := make(map[string]float64)
+	for _, metric := range metrics {
+		if metric.Type != metricType {
+			continue
+		}
+		key := metric.Name
+		if device, ok := metric.Labels["device"]; ok {
+			key += "{" + device + "}"
+		}
+		if resource, ok := metric.Labels["resource"]; ok {
+			key += "{" + resource + "," + metric.Labels["kind"] + "," + metric.Labels["window"] + "}"
+		}
+		values[key] = metric.Value.Raw()
+	}
+	return values
+}
+
+func TestCgroupCollectorReportsUsageRelativeToLimits(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFiles(t, root, map[string]string{"cgroup.controllers": "cpuset cpu io memory pids\n"})
+	pod := filepath.Join(root, "kubepods", "pod1")
+	writeCgroupFiles(t, pod, map[string]string{
+		"cpu.max":        "200000 100000\n",
+		"cpu.stat":       "usage_usec 1000000\nuser_usec 800000\nsystem_usec 200000\nnr_periods 100\nnr_throttled 10\nthrottled_usec 50000\n",
+		"memory.current": "536870912\n",
+		"memory.max":     "1073741824\n",
+		"memory.stat":    "anon 268435456\nfile 268435456\ninactive_file 134217728\n",
+		"io.stat":        "8:0 rbytes=1048576 wbytes=0 rios=10 wios=0 dbytes=0 dios=0\n",
+		"cpu.pressure":   "some avg10=1.50 avg60=0.75 avg300=0.10 total=12345\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+		"memory.pressure": "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\n" +
+			"full avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+	})
+	collector := newTestCgroupCollector(t, root)
+	writeCgroupFiles(t, filepath.Join(collector.sysBlock, "8:0"), map[string]string{"uevent": "MAJOR=8\nMINOR=0\nDEVNAME=sda\nDEVTYPE=disk\n"})
+
+	first, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("first Collect() error = %v", err)
+	}
+	if len(cgroupValues(first, valueobject.ContainerCPU)) != 0 || len(cgroupValues(first, valueobject.ContainerIO)) != 0 {
+		t.Fatalf("first cycle must not report rates: %+v", first)
+	}
+
+	// За 10 секунд: 5 секунд CPU при лимите 2 ядра, 20 из 50 периодов с троттлингом, 10 MB прочитано
+	writeCgroupFiles(t, pod, map[string]string{
+		"cpu.stat": "usage_usec 6000000\nnr_periods 150\nnr_throttled 30\nthrottled_usec 250000\n",
+		"io.stat":  "8:0 rbytes=11534336 wbytes=4096 rios=110 wios=1 dbytes=0 dios=0\n",
+	})
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("second Collect() error = %v", err)
+	}
+
+	cpu := cgroupValues(metrics, valueobject.ContainerCPU)
+	if cpu["container_cpu_usage"] != 25 || cpu["container_cpu_throttled"] != 40 {
+		t.Fatalf("unexpected cpu metrics: %v", cpu)
+	}
+
+	memory := cgroupValues(metrics, valueobject.ContainerMemory)
+	if memory["container_memory_usage"] != 37.5 || memory["container_memory_working_set"] != 402653184 ||
+		memory["container_memory_current"] != 536870912 || memory["container_memory_limit"] != 1073741824 {
+		t.Fatalf("unexpected memory metrics: %v", memory)
+	}
+
+	io := cgroupValues(metrics, valueobject.ContainerIO)
+	if io["container_io_read_bytes_per_second{sda}"] != 1048576 || io["container_io_reads_per_second{sda}"] != 10 ||
+		io["container_io_writes_per_second{sda}"] != 0.1 {
+		t.Fatalf("unexpected io metrics: %v", io)
+	}
+
+	pressure := cgroupValues(metrics, valueobject.Pressure)
+	if len(pressure) != 12 || pressure["pressure_stall{cpu,some,10s}"] != 1.5 || pressure["pressure_stall{cpu,some,60s}"] != 0.75 {
+		t.Fatalf("unexpected pressure metrics: %v", pressure)
+	}
+
+	for _, metric := range metrics {
+		definition, ok := valueobject.DefaultMetricTypeRegistry().Lookup(metric.Type)
+		if !ok || !definition.AllowsUnit(metric.Value.Unit()) {
+			t.Fatalf("metric %s has unregistered type or unit %q", metric.Name, metric.Value.Unit())
+		}
+	}
+}
+
+func TestCgroupCollectorWithoutLimits(t *testing.T) {
+	// Контейнер без cgroup namespace: /proc/self/cgroup указывает на путь хоста, которого нет в иерархии
+	root := t.TempDir()
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers":    "cpu memory\n",
+		"cpu.max":               "max 100000\n",
+		"cpuset.cpus.effective": "0-1,4-5\n",
+		"cpu.stat":              "usage_usec 0\nnr_periods 0\nnr_throttled 0\nthrottled_usec 0\n",
+		"memory.current":        "1048576\n",
+		"memory.max":            "max\n",
+	})
+	collector := newTestCgroupCollector(t, root)
+
+	if _, err := collector.Collect(context.Background()); err != nil {
+		t.Fatalf("first Collect() error = %v", err)
+	}
+	writeCgroupFiles(t, root, map[string]string{"cpu.stat": "usage_usec 20000000\nnr_periods 0\nnr_throttled 0\nthrottled_usec 0\n"})
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("second Collect() error = %v", err)
+	}
+
+	// Без квоты лимит - 4 ядра из cpuset: 2 ядра-секунды в секунду = 50%
+	cpu := cgroupValues(metrics, valueobject.ContainerCPU)
+	if len(cpu) != 1 || cpu["container_cpu_usage"] != 50 {
+		t.Fatalf("unexpected cpu metrics without quota: %v", cpu)
+	}
+	memory := cgroupValues(metrics, valueobject.ContainerMemory)
+	if _, ok := memory["container_memory_usage"]; ok || memory["container_memory_current"] != 1048576 {
+		t.Fatalf("memory share must not be reported without limit: %v", memory)
+	}
+}
+
+func TestCgroupCollectorSkipsResetAndMissingCgroupV2(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFiles(t, root, map[string]string{
+		"cgroup.controllers": "cpu\n",
+		"cpu.stat":           "usage_usec 5000000\nnr_periods 0\nnr_throttled 0\nthrottled_usec 0\n",
+	})
+	collector := newTestCgroupCollector(t, root)
+	if _, err := collector.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Пересозданная cgroup: счетчик уменьшился, интервал пропускается
+	writeCgroupFiles(t, root, map[string]string{"cpu.stat": "usage_usec 1000\nnr_periods 0\nnr_throttled 0\nthrottled_usec 0\n"})
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu := cgroupValues(metrics, valueobject.ContainerCPU); len(cpu) != 0 {
+		t.Fatalf("reset counters must skip the interval: %v", cpu)
+	}
+
+	// cgroup v1 или нет cgroupfs: метрик нет, ошибки тоже
+	collector = newTestCgroupCollector(t, t.TempDir())
+	metrics, err = collector.Collect(context.Background())
+	if err != nil || len(metrics) != 0 {
+		t.Fatalf("Collect() without cgroup v2 = %v, %v", metrics, err)
+	}
+}
+
+func TestCountCPUList(t *testing.T) {
+	tests := map[string]int{"0-3": 4, "0": 1, "0-1,4-5,7": 5, "": 0, "3-1": 0, "a": 0}
+	for list, want := range tests {
+		if got := countCPUList(list); got != want {
+			t.Errorf("countCPUList(%q) = %d, want %d", list, got, want)
+		}
+	}
+}
@@ -2,46 +2,161 @@ package collector
 
 import (
 	"context"
-	"time"
+	"strings"
+	"sync"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/load"
 )
 
-// CPUCollector собирает метрики CPU
-type CPUCollector struct{}
+// cpuModes режимы процессорного времени в порядке выгрузки; вместе дают 100%
+var cpuModes = []string{"user", "nice", "system", "iowait", "irq", "softirq", "steal", "idle"}
+
+// CPUCollector собирает метрики CPU без ожидания внутри цикла сбора
+// Загрузка считается по разнице счетчиков cpu.Times между соседними вызовами Collect,
+// поэтому первый вызов только запоминает счетчики и отдает лишь load average
+type CPUCollector struct {
+	mu       sync.Mutex
+	lastAll  *cpu.TimesStat
+	lastCore map[string]cpu.TimesStat
+
+	// times и loadAvg подменяются в тестах
+	times   func(ctx context.Context, perCPU bool) ([]cpu.TimesStat, error)
+	loadAvg func(ctx context.Context) (*load.AvgStat, error)
+}
 
 // NewCPUCollector создает новый CPU collector
 func NewCPUCollector() *CPUCollector {
-	return &CPUCollector{}
+	return &CPUCollector{
+		lastCore: make(map[string]cpu.TimesStat),
+		times:    cpu.TimesWithContext,
+		loadAvg:  load.AvgWithContext,
+	}
 }
 
-// Collect собирает CPU метрики
+// Collect собирает CPU метрики:
+// cpu_usage (общая загрузка), cpu_core_usage{core} по ядрам, cpu_mode_usage{mode} по режимам
+// и load_average{window} за 1, 5 и 15 минут
 func (c *CPUCollector) Collect(ctx context.Context) ([]port.RawMetric, error) {
-	// Получаем процент использования CPU за 1 секунду
-	percentages, err := cpu.PercentWithContext(ctx, time.Second, false)
+	total, err := c.times(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	perCore, err := c.times(ctx, true)
 	if err != nil {
 		return nil, err
 	}
 
-	// Получаем количество ядер
-	counts, _ := cpu.Counts(true)
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	var metrics []port.RawMetric
 
-	// Создаем метрику для общего использования CPU
-	if len(percentages) > 0 {
-		value, _ := valueobject.NewMetricValue(percentages[0], "%")
-		metrics = append(metrics, port.RawMetric{
-			Type:  valueobject.CPU,
-			Name:  "cpu_usage",
-			Value: value,
-			Metadata: map[string]interface{}{
-				"cores": counts,
-			},
-		})
+	if len(total) > 0 {
+		current := total[0]
+		if c.lastAll != nil {
+			if usage, modes, ok := cpuDelta(*c.lastAll, current); ok {
+				metrics = append(metrics, cpuMetric(valueobject.CPU, "cpu_usage", usage, nil, map[string]interface{}{
+					"cores": len(perCore),
+				}))
+				for i, mode := range cpuModes {
+					metrics = append(metrics, cpuMetric(valueobject.CPUMode, "cpu_mode_usage", modes[i], map[string]string{
+						"mode": mode,
+					}, nil))
+				}
+			}
+		}
+		c.lastAll = &current
+	}
+
+	seen := make(map[string]struct{}, len(perCore))
+	for _, current := range perCore {
+		seen[current.CPU] = struct{}{}
+		if last, ok := c.lastCore[current.CPU]; ok {
+			if usage, _, ok := cpuDelta(last, current); ok {
+				metrics = append(metrics, cpuMetric(valueobject.CPUCore, "cpu_core_usage", usage, map[string]string{
+					"core": strings.TrimPrefix(current.CPU, "cpu"),
+				}, nil))
+			}
+		}
+		c.lastCore[current.CPU] = current
+	}
+	// Ядра, отключенные между вызовами, не должны давать скачок при возврате
+	for name := range c.lastCore {
+		if _, ok := seen[name]; !ok {
+			delete(c.lastCore, name)
+		}
+	}
+
+	// Load average есть не на всех платформах (например, Windows): его отсутствие не ошибка сбора
+	if avg, err := c.loadAvg(ctx); err == nil && avg != nil {
+		for _, window := range []struct {
+			name  string
+			value float64
+		}{{"1m", avg.Load1}, {"5m", avg.Load5}, {"15m", avg.Load15}} {
+			metrics = append(metrics, cpuMetric(valueobject.Load, "load_average", window.value, map[string]string{
+				"window": window.name,
+			}, nil))
+		}
 	}
 
 	return metrics, nil
 }
+
+// cpuDelta вычисляет загрузку и доли режимов (в порядке cpuModes) между двумя снимками счетчиков
+// ok = false, если счетчики не выросли (слишком частый вызов или сброс счетчиков)
+func cpuDelta(last, current cpu.TimesStat) (usage float64, modes []float64, ok bool) {
+	lastModes := cpuModeTimes(last)
+	currentModes := cpuModeTimes(current)
+
+	deltas := make([]float64, len(cpuModes))
+	var total float64
+	for i := range cpuModes {
+		// Отдельный счетчик может уменьшиться при пересчете ядром: такой интервал считается нулевым
+		deltas[i] = max(currentModes[i]-lastModes[i], 0)
+		total += deltas[i]
+	}
+	if total <= 0 {
+		return 0, nil, false
+	}
+
+	modes = make([]float64, len(cpuModes))
+	for i, delta := range deltas {
+		modes[i] = delta / total * 100
+	}
+
+	// Простой - это idle и ожидание ввода-вывода, как в cpu.Percent
+	idle := max(current.Idle-last.Idle, 0) + max(current.Iowait-last.Iowait, 0)
+	usage = min(max((total-idle)/total*100, 0), 100)
+	return usage, modes, true
+}
+
+// cpuModeTimes счетчики режимов в порядке cpuModes
+// Guest не суммируется: на Linux оно уже учтено в user и nice
+func cpuModeTimes(t cpu.TimesStat) []float64 {
+	return []float64{t.User, t.Nice, t.System, t.Iowait, t.Irq, t.Softirq, t.Steal, t.Idle}
+}
+
+// cpuMetric создает метрику; значение уже неотрицательно, поэтому ошибка NewMetricValue невозможна
+func cpuMetric(
+	metricType valueobject.MetricType,
+	name string,
+	raw float64,
+	labels map[string]string,
+	metadata map[string]interface{},
+) port.RawMetric {
+	unit := "%"
+	if metricType == valueobject.Load {
+		unit = "load"
+	}
+	value, _ := valueobject.NewMetricValue(max(raw, 0), unit)
+	return port.RawMetric{
+		Type:     metricType,
+		Name:     name,
+		Value:    value,
+		Labels:   labels,
+		Metadata: metadata,
+	}
+}
@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/load"
+)
+
+// fakeCPUTimes отдает заранее заданные снимки счетчиков по очереди
+type fakeCPUTimes struct {
+	total   []cpu.TimesStat
+	perCore [][]cpu.TimesStat
+	call    int
+}
+
+func (f *fakeCPUTimes) times(_ context.Context, perCPU bool) ([]cpu.TimesStat, error) {
+	if perCPU {
+		result := f.perCore[f.call]
+		f.call++
+		return result, nil
+	}
+	return []cpu.TimesStat{f.total[f.call]}, nil
+}
+
+func findCPUMetric(metrics []port.RawMetric, metricType valueobject.MetricType, label, value string) (port.RawMetric, bool) {
+	for _, metric := range metrics {
+		if metric.Type == metricType && metric.Labels[label] == value {
+			return metric, true
+		}
+	}
+	return port.RawMetric{}, false
+}
+
+func TestCPUCollectorDiffsTimesBetweenCycles(t *testing.T) {
+	fake := &fakeCPUTimes{
+		total: []cpu.TimesStat{
+			{CPU: "cpu-total", User: 100, System: 50, Idle: 800, Iowait: 40, Steal: 10},
+			// +100 тиков: 30 user, 10 system, 40 idle, 10 iowait, 10 steal
+			{CPU: "cpu-total", User: 130, System: 60, Idle: 840, Iowait: 50, Steal: 20},
+		},
+		perCore: [][]cpu.TimesStat{
+			{{CPU: "cpu0", User: 50, Idle: 400}, {CPU: "cpu1", User: 50, Idle: 400}},
+			{{CPU: "cpu0", User: 75, Idle: 425}, {CPU: "cpu1", User: 50, Idle: 450}},
+		},
+	}
+	collector := NewCPUCollector()
+	collector.times = fake.times
+	collector.loadAvg = func(context.Context) (*load.AvgStat, error) {
+		return &load.AvgStat{Load1: 1.5, Load5: 0.75, Load15: 0.5}, nil
+	}
+
+	first, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("first Collect() error = %v", err)
+	}
+	// Первый вызов только запоминает счетчики: без прошлого снимка есть лишь load average
+	if len(first) != 3 || first[0].Type != valueobject.Load {
+		t.Fatalf("first cycle must report load average only, got %+v", first)
+	}
+
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("second Collect() error = %v", err)
+	}
+
+	usage, ok := findCPUMetric(metrics, valueobject.CPU, "", "")
+	if !ok || usage.Name != "cpu_usage" || math.Abs(usage.Value.Raw()-50) > 1e-9 || usage.Metadata["cores"] != 2 {
+		t.Fatalf("unexpected cpu_usage: %+v", usage)
+	}
+
+	expectedModes := map[string]float64{"user": 30, "system": 10, "idle": 40, "iowait": 10, "steal": 10, "nice": 0}
+	for mode, expected := range expectedModes {
+		metric, ok := findCPUMetric(metrics, valueobject.CPUMode, "mode", mode)
+		if !ok || math.Abs(metric.Value.Raw()-expected) > 1e-9 {
+			t.Fatalf("mode %s = %+v, want %.0f%%", mode, metric, expected)
+		}
+	}
+
+	core0, ok0 := findCPUMetric(metrics, valueobject.CPUCore, "core", "0")
+	core1, ok1 := findCPUMetric(metrics, valueobject.CPUCore, "core", "1")
+	if !ok0 || !ok1 || core0.Value.Raw() != 50 || core1.Value.Raw() != 0 {
+		t.Fatalf("unexpected per-core usage: %+v %+v", core0, core1)
+	}
+
+	load15, ok := findCPUMetric(metrics, valueobject.Load, "window", "15m")
+	if !ok || load15.Value.Raw() != 0.5 || load15.Value.Unit() != "load" {
+		t.Fatalf("unexpected load average: %+v", load15)
+	}
+}
+
+func TestCPUDeltaSkipsStalledCounters(t *testing.T) {
+	snapshot := cpu.TimesStat{User: 10, Idle: 90}
+	if _, _, ok := cpuDelta(snapshot, snapshot); ok {
+		t.Fatal("equal snapshots must not produce a sample")
+	}
+	// Сброс счетчиков (например, после восстановления ВМ) не дает отрицательных значений
+	if usage, _, ok := cpuDelta(cpu.TimesStat{User: 100, Idle: 900}, cpu.TimesStat{User: 5, Idle: 20}); ok && (usage < 0 || usage > 100) {
+		t.Fatalf("usage after counter reset = %v", usage)
+	}
+}
+
+func TestCPUCollectorWithoutLoadAverage(t *testing.T) {
+	collector := NewCPUCollector()
+	collector.times = func(context.Context, bool) ([]cpu.TimesStat, error) {
+		return []cpu.TimesStat{{CPU: "cpu-total", User: 1, Idle: 1}}, nil
+	}
+	collector.loadAvg = func(context.Context) (*load.AvgStat, error) {
+		return nil, errors.New("not implemented yet")
+	}
+
+	if _, err := collector.Collect(context.Background()); err != nil {
+		t.Fatalf("missing load average must not fail collection: %v", err)
+	}
+}
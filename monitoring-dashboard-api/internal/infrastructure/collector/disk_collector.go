@@ -2,43 +2,277 @@ package collector
 
 import (
 	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 	"github.com/shirou/gopsutil/v3/disk"
 )
 
-// DiskCollector собирает метрики дисков
-type DiskCollector struct{}
+// defaultExcludeFSTypes псевдо- и виртуальные файловые системы, не занимающие места на дисках
+var defaultExcludeFSTypes = []string{
+	"autofs", "binfmt_misc", "bpf", "cgroup", "cgroup2", "configfs", "debugfs", "devpts", "devtmpfs",
+	"efivarfs", "fuse.lxcfs", "fusectl", "hugetlbfs", "mqueue", "nsfs", "overlay", "proc", "procfs",
+	"pstore", "ramfs", "rpc_pipefs", "securityfs", "selinuxfs", "squashfs", "sysfs", "tmpfs", "tracefs",
+}
+
+// defaultExcludeMounts служебные каталоги и каталоги контейнерных рантаймов
+var defaultExcludeMounts = []string{"/proc", "/sys", "/dev", "/run", "/snap", "/var/lib/docker", "/var/lib/kubelet", "/var/lib/containers"}
+
+// defaultExcludeDevices блочные устройства без собственного носителя
+var defaultExcludeDevices = []string{"loop*", "ram*", "zram*", "fd*", "sr*"}
+
+// DiskConfig фильтры разделов и устройств
+// Пустой список исключений означает список по умолчанию
+type DiskConfig struct {
+	// FSTypes допустимые типы файловых систем (пусто - все, кроме ExcludeFSTypes); на "/" не действует
+	FSTypes []string
+
+	// ExcludeFSTypes исключаемые типы файловых систем (tmpfs, overlay, proc ...); на "/" не действует
+	ExcludeFSTypes []string
+
+	// ExcludeMounts исключаемые точки монтирования вместе со всем, что смонтировано внутри них
+	ExcludeMounts []string
+
+	// ExcludeDevices шаблоны имен блочных устройств (loop*, ram*), не попадающих в метрики ввода-вывода
+	ExcludeDevices []string
+}
+
+// DiskCollector собирает метрики дисков: заполненность каждого раздела и ввод-вывод каждого устройства
+// Скорости считаются по разнице счетчиков disk.IOCounters между соседними вызовами Collect
+type DiskCollector struct {
+	config DiskConfig
+
+	mu       sync.Mutex
+	lastIO   map[string]disk.IOCountersStat
+	lastTime time.Time
+
+	// partitions, usage, ioCounters и now подменяются в тестах
+	partitions func(ctx context.Context, all bool) ([]disk.PartitionStat, error)
+	usage      func(ctx context.Context, path string) (*disk.UsageStat, error)
+	ioCounters func(ctx context.Context, names ...string) (map[string]disk.IOCountersStat, error)
+	now        func() time.Time
+}
 
 // NewDiskCollector создает новый Disk collector
-func NewDiskCollector() *DiskCollector {
-	return &DiskCollector{}
+func NewDiskCollector(config DiskConfig) *DiskCollector {
+	if len(config.ExcludeFSTypes) == 0 {
+		config.ExcludeFSTypes = defaultExcludeFSTypes
+	}
+	if len(config.ExcludeMounts) == 0 {
+		config.ExcludeMounts = defaultExcludeMounts
+	}
+	if len(config.ExcludeDevices) == 0 {
+		config.ExcludeDevices = defaultExcludeDevices
+	}
+
+	return &DiskCollector{
+		config:     config,
+		lastIO:     make(map[string]disk.IOCountersStat),
+		partitions: disk.PartitionsWithContext,
+		usage:      disk.UsageWithContext,
+		ioCounters: disk.IOCountersWithContext,
+		now:        time.Now,
+	}
 }
 
-// Collect собирает Disk метрики
+// Collect собирает Disk метрики:
+// disk_usage, disk_used_bytes, disk_free_bytes и disk_inodes_usage по точкам монтирования,
+// скорости чтения/записи, IOPS и среднее время ожидания операции по блочным устройствам
 func (c *DiskCollector) Collect(ctx context.Context) ([]port.RawMetric, error) {
-	// Получаем информацию о корневом разделе
-	usage, err := disk.UsageWithContext(ctx, "/")
+	partitions, err := c.partitions(ctx, true)
 	if err != nil {
 		return nil, err
 	}
 
 	var metrics []port.RawMetric
 
-	// Процент использования диска
-	value, _ := valueobject.NewMetricValue(usage.UsedPercent, "%")
-	metrics = append(metrics, port.RawMetric{
-		Type:  valueobject.Disk,
-		Name:  "disk_usage",
-		Value: value,
+	seen := make(map[string]struct{}, len(partitions))
+	for _, partition := range partitions {
+		if _, duplicate := seen[partition.Mountpoint]; duplicate || !c.includePartition(partition) {
+			continue
+		}
+		seen[partition.Mountpoint] = struct{}{}
+
+		usage, err := c.usage(ctx, partition.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			// Недоступный раздел (нет прав, отключенный сетевой ресурс) не мешает остальным
+			continue
+		}
+		metrics = append(metrics, partitionMetrics(partition, usage)...)
+	}
+
+	// Счетчики ввода-вывода есть не на всех платформах: без них остаются метрики разделов
+	if counters, err := c.ioCounters(ctx); err == nil {
+		metrics = append(metrics, c.ioMetrics(counters)...)
+	}
+
+	return metrics, nil
+}
+
+// includePartition применяет фильтры по типу файловой системы и точке монтирования
+// Корень "/" не фильтруется по типу файловой системы: в контейнере это overlay
+func (c *DiskCollector) includePartition(partition disk.PartitionStat) bool {
+	if partition.Mountpoint != "/" {
+		if len(c.config.FSTypes) > 0 && !containsString(c.config.FSTypes, partition.Fstype) {
+			return false
+		}
+		if containsString(c.config.ExcludeFSTypes, partition.Fstype) {
+			return false
+		}
+	}
+	for _, excluded := range c.config.ExcludeMounts {
+		excluded = strings.TrimRight(excluded, "/")
+		if partition.Mountpoint == excluded || strings.HasPrefix(partition.Mountpoint, excluded+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// partitionMetrics метрики заполненности одного раздела
+func partitionMetrics(partition disk.PartitionStat, usage *disk.UsageStat) []port.RawMetric {
+	labels := func() map[string]string { return map[string]string{"mount": partition.Mountpoint} }
+
+	usagePercent, _ := valueobject.NewMetricValue(usage.UsedPercent, "%")
+	metrics := []port.RawMetric{{
+		Type:   valueobject.Disk,
+		Name:   "disk_usage",
+		Value:  usagePercent,
+		Labels: labels(),
 		Metadata: map[string]interface{}{
-			"mount":    usage.Path,
+			"mount":    partition.Mountpoint,
+			"device":   partition.Device,
+			"fstype":   partition.Fstype,
 			"total_gb": usage.Total / 1024 / 1024 / 1024,
 			"used_gb":  usage.Used / 1024 / 1024 / 1024,
 			"free_gb":  usage.Free / 1024 / 1024 / 1024,
 		},
-	})
+	}}
 
-	return metrics, nil
+	used, _ := valueobject.NewMetricValue(float64(usage.Used), "bytes")
+	free, _ := valueobject.NewMetricValue(float64(usage.Free), "bytes")
+	metrics = append(metrics,
+		port.RawMetric{Type: valueobject.DiskSpace, Name: "disk_used_bytes", Value: used, Labels: labels()},
+		port.RawMetric{Type: valueobject.DiskSpace, Name: "disk_free_bytes", Value: free, Labels: labels()},
+	)
+
+	// Часть файловых систем (vfat, btrfs) не ведет учет inode
+	if usage.InodesTotal > 0 {
+		inodes, _ := valueobject.NewMetricValue(usage.InodesUsedPercent, "%")
+		metrics = append(metrics, port.RawMetric{
+			Type:   valueobject.DiskInodes,
+			Name:   "disk_inodes_usage",
+			Value:  inodes,
+			Labels: labels(),
+			Metadata: map[string]interface{}{
+				"inodes_total": usage.InodesTotal,
+				"inodes_used":  usage.InodesUsed,
+				"inodes_free":  usage.InodesFree,
+			},
+		})
+	}
+
+	return metrics
+}
+
+// ioMetrics вычисляет скорости ввода-вывода по разнице с предыдущим снимком счетчиков
+// Первый вызов только запоминает счетчики
+func (c *DiskCollector) ioMetrics(counters map[string]disk.IOCountersStat) []port.RawMetric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	last := c.lastIO
+
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		if !c.includeDevice(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var metrics []port.RawMetric
+	current := make(map[string]disk.IOCountersStat, len(names))
+	for _, name := range names {
+		stat := counters[name]
+		current[name] = stat
+
+		previous, ok := last[name]
+		if !ok || elapsed <= 0 || !ioCountersGrew(previous, stat) {
+			continue
+		}
+		metrics = append(metrics, deviceIOMetrics(name, previous, stat, elapsed)...)
+	}
+
+	c.lastIO = current
+	c.lastTime = now
+	return metrics
+}
+
+// includeDevice проверяет имя устройства по шаблонам исключений
+func (c *DiskCollector) includeDevice(name string) bool {
+	return !matchesAny(c.config.ExcludeDevices, name)
+}
+
+// ioCountersGrew проверяет, что счетчики не сбросились (перезагрузка драйвера, переподключение устройства)
+func ioCountersGrew(previous, current disk.IOCountersStat) bool {
+	return current.ReadBytes >= previous.ReadBytes &&
+		current.WriteBytes >= previous.WriteBytes &&
+		current.ReadCount >= previous.ReadCount &&
+		current.WriteCount >= previous.WriteCount &&
+		current.ReadTime >= previous.ReadTime &&
+		current.WriteTime >= previous.WriteTime
+}
+
+// deviceIOMetrics метрики ввода-вывода одного устройства за интервал elapsed секунд
+// disk_await - среднее время операции (очередь и обслуживание) в миллисекундах, 0 без операций
+func deviceIOMetrics(device string, previous, current disk.IOCountersStat, elapsed float64) []port.RawMetric {
+	reads := current.ReadCount - previous.ReadCount
+	writes := current.WriteCount - previous.WriteCount
+
+	var await float64
+	if ops := reads + writes; ops > 0 {
+		await = float64((current.ReadTime-previous.ReadTime)+(current.WriteTime-previous.WriteTime)) / float64(ops)
+	}
+
+	rates := []struct {
+		name  string
+		value float64
+		unit  string
+	}{
+		{"disk_read_bytes_per_second", float64(current.ReadBytes-previous.ReadBytes) / elapsed, "bytes/s"},
+		{"disk_write_bytes_per_second", float64(current.WriteBytes-previous.WriteBytes) / elapsed, "bytes/s"},
+		{"disk_reads_per_second", float64(reads) / elapsed, "ops/s"},
+		{"disk_writes_per_second", float64(writes) / elapsed, "ops/s"},
+		{"disk_await", await, "ms"},
+	}
+
+	metrics := make([]port.RawMetric, 0, len(rates))
+	for _, rate := range rates {
+		value, _ := valueobject.NewMetricValue(rate.value, rate.unit)
+		metrics = append(metrics, port.RawMetric{
+			Type:   valueobject.DiskIO,
+			Name:   rate.name,
+			Value:  value,
+			Labels: map[string]string{"device": device},
+		})
+	}
+	return metrics
+}
+
+// containsString проверяет наличие значения в списке
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,204 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+func newTestDiskCollector(config DiskConfig, counters []map[string]disk.IOCountersStat) *DiskCollector {
+	collector := NewDiskCollector(config)
+	collector.partitions = func(context.Context, bool) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"}, // повтор (bind mount)
+			{Device: "/dev/sdb1", Mountpoint: "/data", Fstype: "xfs"},
+			{Device: "/dev/sdc1", Mountpoint: "/boot/efi", Fstype: "vfat"},
+			{Device: "tmpfs", Mountpoint: "/tmp", Fstype: "tmpfs"},
+			{Device: "overlay", Mountpoint: "/var/lib/docker/overlay2/abc/merged", Fstype: "ext4"},
+			{Device: "/dev/sdd1", Mountpoint: "/mnt/gone", Fstype: "ext4"},
+		}, nil
+	}
+	collector.usage = func(_ context.Context, mount string) (*disk.UsageStat, error) {
+		switch mount {
+		case "/":
+			return &disk.UsageStat{Path: mount, Total: 100 << 30, Used: 40 << 30, Free: 60 << 30, UsedPercent: 40,
+				InodesTotal: 1000, InodesUsed: 250, InodesFree: 750, InodesUsedPercent: 25}, nil
+		case "/data":
+			return &disk.UsageStat{Path: mount, Total: 200 << 30, Used: 180 << 30, Free: 20 << 30, UsedPercent: 90,
+				InodesTotal: 1000, InodesUsed: 10, InodesFree: 990, InodesUsedPercent: 1}, nil
+		case "/boot/efi":
+			return &disk.UsageStat{Path: mount, Total: 512 << 20, Used: 6 << 20, Free: 506 << 20, UsedPercent: 1.2}, nil
+		}
+		return nil, errors.New("stale file handle")
+	}
+
+	call := 0
+	collector.ioCounters = func(context.Context, ...string) (map[string]disk.IOCountersStat, error) {
+		result := counters[call]
+		call++
+		return result, nil
+	}
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ticks := 0
+	collector.now = func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * 2 * time.Second)
+	}
+	return collector
+}
+
+func TestDiskCollectorReportsEveryRealMount(t *testing.T) {
+	counters := []map[string]disk.IOCountersStat{{}}
+	collector := newTestDiskCollector(DiskConfig{}, counters)
+
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	usage := make(map[string]float64)
+	inodes := make(map[string]float64)
+	var freeRoot float64
+	for _, metric := range metrics {
+		switch metric.Type {
+		case valueobject.Disk:
+			usage[metric.Labels["mount"]] = metric.Value.Raw()
+		case valueobject.DiskInodes:
+			inodes[metric.Labels["mount"]] = metric.Value.Raw()
+		case valueobject.DiskSpace:
+			if metric.Name == "disk_free_bytes" && metric.Labels["mount"] == "/" {
+				freeRoot = metric.Value.Raw()
+			}
+		}
+	}
+
+	if len(usage) != 3 || usage["/"] != 40 || usage["/data"] != 90 || usage["/boot/efi"] != 1.2 {
+		t.Fatalf("unexpected mounts: %v", usage)
+	}
+	// vfat не ведет учет inode
+	if len(inodes) != 2 || inodes["/"] != 25 {
+		t.Fatalf("unexpected inode usage: %v", inodes)
+	}
+	if freeRoot != float64(60<<30) {
+		t.Fatalf("disk_free_bytes{mount=\"/\"} = %v", freeRoot)
+	}
+}
+
+func TestDiskCollectorFilters(t *testing.T) {
+	counters := []map[string]disk.IOCountersStat{{}}
+	collector := newTestDiskCollector(DiskConfig{FSTypes: []string{"ext4", "tmpfs"}, ExcludeMounts: []string{"/mnt"}}, counters)
+
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	for _, metric := range metrics {
+		if metric.Type == valueobject.Disk && metric.Labels["mount"] != "/" {
+			// tmpfs в списке разрешенных, но остается в исключениях по умолчанию
+			t.Fatalf("unexpected mount %q", metric.Labels["mount"])
+		}
+	}
+}
+
+func TestDiskCollectorKeepsOverlayRootInContainers(t *testing.T) {
+	counters := []map[string]disk.IOCountersStat{{}, {}}
+	collector := newTestDiskCollector(DiskConfig{}, counters)
+	collector.partitions = func(context.Context, bool) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Device: "overlay", Mountpoint: "/", Fstype: "overlay"},
+			{Device: "overlay", Mountpoint: "/mnt/layer", Fstype: "overlay"},
+			{Device: "/dev/sda1", Mountpoint: "/etc/hosts", Fstype: "ext4"},
+			{Device: "shm", Mountpoint: "/dev/shm", Fstype: "tmpfs"},
+		}, nil
+	}
+	collector.usage = func(_ context.Context, mount string) (*disk.UsageStat, error) {
+		return &disk.UsageStat{Path: mount, Total: 100 << 30, Used: 30 << 30, Free: 70 << 30, UsedPercent: 30}, nil
+	}
+
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	usage := make(map[string]float64)
+	for _, metric := range metrics {
+		if metric.Type == valueobject.Disk {
+			usage[metric.Labels["mount"]] = metric.Value.Raw()
+		}
+	}
+	// Корень overlay остается, прочие overlay по-прежнему исключены
+	if len(usage) != 2 || usage["/"] != 30 || usage["/etc/hosts"] != 30 {
+		t.Fatalf("unexpected mounts: %v", usage)
+	}
+
+	// Явный список типов тоже не убирает корень
+	collector.config.FSTypes = []string{"ext4"}
+	metrics, err = collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	found := false
+	for _, metric := range metrics {
+		found = found || (metric.Type == valueobject.Disk && metric.Labels["mount"] == "/")
+	}
+	if !found {
+		t.Fatal("root mount must be reported with an explicit fstype list")
+	}
+}
+
+func TestDiskCollectorComputesIORates(t *testing.T) {
+	counters := []map[string]disk.IOCountersStat{
+		{
+			"sda":   {ReadBytes: 1000, WriteBytes: 2000, ReadCount: 10, WriteCount: 20, ReadTime: 50, WriteTime: 100},
+			"loop0": {ReadBytes: 1},
+		},
+		{
+			// За 2 секунды: 4096 байт чтения, 8192 записи, 4 + 6 операций, 30 + 70 мс
+			"sda":   {ReadBytes: 5096, WriteBytes: 10192, ReadCount: 14, WriteCount: 26, ReadTime: 80, WriteTime: 170},
+			"loop0": {ReadBytes: 100},
+		},
+	}
+	collector := newTestDiskCollector(DiskConfig{}, counters)
+
+	first, _ := collector.Collect(context.Background())
+	for _, metric := range first {
+		if metric.Type == valueobject.DiskIO {
+			t.Fatalf("first cycle must not report rates, got %+v", metric)
+		}
+	}
+
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	rates := make(map[string]float64)
+	for _, metric := range metrics {
+		if metric.Type != valueobject.DiskIO {
+			continue
+		}
+		if metric.Labels["device"] != "sda" {
+			t.Fatalf("excluded device reported: %+v", metric)
+		}
+		rates[metric.Name] = metric.Value.Raw()
+	}
+
+	expected := map[string]float64{
+		"disk_read_bytes_per_second":  2048,
+		"disk_write_bytes_per_second": 4096,
+		"disk_reads_per_second":       2,
+		"disk_writes_per_second":      3,
+		"disk_await":                  10,
+	}
+	for name, value := range expected {
+		if rates[name] != value {
+			t.Fatalf("%s = %v, want %v (all: %v)", name, rates[name], value, rates)
+		}
+	}
+}
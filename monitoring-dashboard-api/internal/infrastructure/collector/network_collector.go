@@ -1,7 +1,17 @@
 package collector
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
@@ -9,61 +19,325 @@ import (
 	"github.com/shirou/gopsutil/v3/net"
 )
 
-// NetworkCollector собирает метрики сети
+// defaultExcludeInterfaces loopback и виртуальные интерфейсы контейнеров и мостов,
+// трафик которых уже учтен на физических интерфейсах
+var defaultExcludeInterfaces = []string{"lo", "veth*", "docker*", "br-*", "virbr*", "cni*", "flannel*", "cali*"}
+
+// tcpStates состояния TCP в порядке выгрузки; коды совпадают с колонкой st в /proc/net/tcp
+var tcpStates = []struct {
+	code string
+	name string
+}{
+	{"01", "established"},
+	{"02", "syn_sent"},
+	{"03", "syn_recv"},
+	{"04", "fin_wait1"},
+	{"05", "fin_wait2"},
+	{"06", "time_wait"},
+	{"07", "close"},
+	{"08", "close_wait"},
+	{"09", "last_ack"},
+	{"0A", "listen"},
+	{"0B", "closing"},
+}
+
+// NetworkConfig фильтры сетевых интерфейсов
+// Пустой список исключений означает список по умолчанию
+type NetworkConfig struct {
+	// Interfaces шаблоны допустимых интерфейсов (пусто - все, кроме ExcludeInterfaces)
+	Interfaces []string
+
+	// ExcludeInterfaces шаблоны исключаемых интерфейсов (lo, veth*, docker* ...)
+	ExcludeInterfaces []string
+}
+
+// NetworkCollector собирает метрики сети: трафик, пакеты, ошибки и отброшенные пакеты каждого интерфейса
+// и число TCP соединений по состояниям
+// Скорости считаются по разнице счетчиков net.IOCounters между соседними вызовами Collect
 type NetworkCollector struct {
-	lastStats     map[string]net.IOCountersStat
-	lastCheckTime time.Time
+	config NetworkConfig
+
+	mu       sync.Mutex
+	lastIO   map[string]net.IOCountersStat
+	lastTime time.Time
+
+	// ioCounters, tcpStates и now подменяются в тестах
+	ioCounters func(ctx context.Context, pernic bool) ([]net.IOCountersStat, error)
+	tcpStates  func(ctx context.Context) (map[string]int, error)
+	now        func() time.Time
 }
 
 // NewNetworkCollector создает новый Network collector
-func NewNetworkCollector() *NetworkCollector {
+func NewNetworkCollector(config NetworkConfig) *NetworkCollector {
+	if len(config.ExcludeInterfaces) == 0 {
+		config.ExcludeInterfaces = defaultExcludeInterfaces
+	}
+
 	return &NetworkCollector{
-		lastStats:     make(map[string]net.IOCountersStat),
-		lastCheckTime: time.Now(),
+		config:     config,
+		lastIO:     make(map[string]net.IOCountersStat),
+		ioCounters: net.IOCountersWithContext,
+		tcpStates:  countTCPStates,
+		now:        time.Now,
 	}
 }
 
-// Collect собирает Network метрики
+// Collect собирает Network метрики:
+// network_sent и network_received (KB/s) по интерфейсам и суммарно (interface="all"),
+// пакеты, ошибки и отброшенные пакеты в секунду по интерфейсам и tcp_connections{state}
 func (c *NetworkCollector) Collect(ctx context.Context) ([]port.RawMetric, error) {
-	stats, err := net.IOCountersWithContext(ctx, false)
-	if err != nil || len(stats) == 0 {
+	counters, err := c.ioCounters(ctx, true)
+	if err != nil {
 		return nil, err
 	}
 
-	currentTime := time.Now()
-	currentStats := stats[0]
+	metrics := c.ioMetrics(counters)
+
+	// Число соединений - дополнительная метрика: ее недоступность не мешает метрикам трафика
+	if states, err := c.tcpStates(ctx); err == nil {
+		metrics = append(metrics, tcpMetrics(states)...)
+	}
+
+	return metrics, nil
+}
+
+// ioMetrics вычисляет скорости по разнице с предыдущим снимком счетчиков
+// Первый вызов и первый вызов после появления интерфейса только запоминают счетчики
+func (c *NetworkCollector) ioMetrics(counters []net.IOCountersStat) []port.RawMetric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+	last := c.lastIO
+
+	sort.Slice(counters, func(i, j int) bool { return counters[i].Name < counters[j].Name })
 
 	var metrics []port.RawMetric
+	var totalSent, totalRecv float64
+	reported := 0
 
-	// Если есть предыдущие данные, вычисляем скорость
-	if lastStat, exists := c.lastStats["all"]; exists {
-		duration := currentTime.Sub(c.lastCheckTime).Seconds()
-		if duration > 0 {
-			// Вычисляем скорость в KB/s
-			bytesSentPerSec := float64(currentStats.BytesSent-lastStat.BytesSent) / duration / 1024
-			bytesRecvPerSec := float64(currentStats.BytesRecv-lastStat.BytesRecv) / duration / 1024
-
-			// Метрика для отправленных данных
-			valueSent, _ := valueobject.NewMetricValue(bytesSentPerSec, "KB/s")
-			metrics = append(metrics, port.RawMetric{
-				Type:  valueobject.Network,
-				Name:  "network_sent",
-				Value: valueSent,
-				Metadata: map[string]interface{}{
-					"interface":    "all",
-					"packets_sent": currentStats.PacketsSent,
-				},
-			})
-
-			// Для упрощения, используем только sent rate как основную метрику
-			// В production можно добавить отдельные метрики для sent/recv
-			_ = bytesRecvPerSec
+	// Исчезнувшие интерфейсы не попадают в новый снимок и не дают скачка при возвращении
+	current := make(map[string]net.IOCountersStat, len(counters))
+	for _, stat := range counters {
+		if !c.includeInterface(stat.Name) {
+			continue
 		}
+		current[stat.Name] = stat
+
+		previous, ok := last[stat.Name]
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		deltas, ok := interfaceDeltas(previous, stat)
+		if !ok {
+			continue
+		}
+
+		rates := make([]float64, len(deltas))
+		for i, delta := range deltas {
+			rates[i] = float64(delta) / elapsed
+		}
+		metrics = append(metrics, interfaceMetrics(stat.Name, rates)...)
+		totalSent += rates[0]
+		totalRecv += rates[1]
+		reported++
 	}
 
-	// Сохраняем текущие данные для следующего вызова
-	c.lastStats["all"] = currentStats
-	c.lastCheckTime = currentTime
+	if reported > 0 {
+		for _, metric := range []port.RawMetric{
+			trafficMetric(valueobject.Network, "network_sent", "all", totalSent),
+			trafficMetric(valueobject.NetworkRecv, "network_received", "all", totalRecv),
+		} {
+			metric.Metadata["interfaces"] = reported
+			metrics = append(metrics, metric)
+		}
+	}
 
-	return metrics, nil
+	c.lastIO = current
+	c.lastTime = now
+	return metrics
+}
+
+// includeInterface применяет шаблоны допустимых и исключаемых интерфейсов
+func (c *NetworkCollector) includeInterface(name string) bool {
+	if len(c.config.Interfaces) > 0 && !matchesAny(c.config.Interfaces, name) {
+		return false
+	}
+	return !matchesAny(c.config.ExcludeInterfaces, name)
+}
+
+// interfaceDeltas приращения счетчиков интерфейса в порядке:
+// байты отправлено/получено, пакеты отправлено/получено, ошибки вход/выход, отброшено вход/выход
+// ok = false, если хотя бы один счетчик сбросился (интерфейс пересоздан): интервал пропускается
+func interfaceDeltas(previous, current net.IOCountersStat) ([]uint64, bool) {
+	pairs := [][2]uint64{
+		{previous.BytesSent, current.BytesSent},
+		{previous.BytesRecv, current.BytesRecv},
+		{previous.PacketsSent, current.PacketsSent},
+		{previous.PacketsRecv, current.PacketsRecv},
+		{previous.Errin, current.Errin},
+		{previous.Errout, current.Errout},
+		{previous.Dropin, current.Dropin},
+		{previous.Dropout, current.Dropout},
+	}
+
+	deltas := make([]uint64, len(pairs))
+	for i, pair := range pairs {
+		delta, ok := counterDelta(pair[0], pair[1])
+		if !ok {
+			return nil, false
+		}
+		deltas[i] = delta
+	}
+	return deltas, true
+}
+
+// counterDelta приращение монотонного счетчика
+// Уменьшение счетчика из верхней половины 32-битного диапазона считается переполнением
+// (32-битные счетчики некоторых драйверов и платформ), любое другое уменьшение - сбросом
+func counterDelta(previous, current uint64) (uint64, bool) {
+	if current >= previous {
+		return current - previous, true
+	}
+	if previous <= math.MaxUint32 && previous > math.MaxUint32/2 && current <= math.MaxUint32/2 {
+		return math.MaxUint32 - previous + current + 1, true
+	}
+	return 0, false
+}
+
+// interfaceMetrics метрики одного интерфейса по скоростям в порядке interfaceDeltas
+func interfaceMetrics(name string, rates []float64) []port.RawMetric {
+	metrics := []port.RawMetric{
+		trafficMetric(valueobject.Network, "network_sent", name, rates[0]),
+		trafficMetric(valueobject.NetworkRecv, "network_received", name, rates[1]),
+	}
+
+	packets := []struct {
+		metricType valueobject.MetricType
+		name       string
+		value      float64
+	}{
+		{valueobject.NetworkPackets, "network_packets_sent", rates[2]},
+		{valueobject.NetworkPackets, "network_packets_received", rates[3]},
+		{valueobject.NetworkErrors, "network_errors_in", rates[4]},
+		{valueobject.NetworkErrors, "network_errors_out", rates[5]},
+		{valueobject.NetworkErrors, "network_drops_in", rates[6]},
+		{valueobject.NetworkErrors, "network_drops_out", rates[7]},
+	}
+	for _, rate := range packets {
+		value, _ := valueobject.NewMetricValue(rate.value, "packets/s")
+		metrics = append(metrics, port.RawMetric{
+			Type:   rate.metricType,
+			Name:   rate.name,
+			Value:  value,
+			Labels: map[string]string{"interface": name},
+		})
+	}
+	return metrics
+}
+
+// trafficMetric метрика скорости передачи в KB/s
+func trafficMetric(metricType valueobject.MetricType, name, iface string, bytesPerSec float64) port.RawMetric {
+	value, _ := valueobject.NewMetricValue(bytesPerSec/1024, "KB/s")
+	return port.RawMetric{
+		Type:   metricType,
+		Name:   name,
+		Value:  value,
+		Labels: map[string]string{"interface": iface},
+		Metadata: map[string]interface{}{
+			"interface": iface,
+		},
+	}
+}
+
+// tcpMetrics число соединений в каждом состоянии, включая нулевые, чтобы серии не прерывались
+func tcpMetrics(states map[string]int) []port.RawMetric {
+	metrics := make([]port.RawMetric, 0, len(tcpStates))
+	for _, state := range tcpStates {
+		value, _ := valueobject.NewMetricValue(float64(states[state.name]), "count")
+		metrics = append(metrics, port.RawMetric{
+			Type:   valueobject.TCPConnections,
+			Name:   "tcp_connections",
+			Value:  value,
+			Labels: map[string]string{"state": state.name},
+		})
+	}
+	return metrics
+}
+
+// countTCPStates считает TCP соединения (IPv4 и IPv6) по состояниям
+// На Linux читает /proc/net/tcp и /proc/net/tcp6 (учитывая HOST_PROC): net.Connections
+// дополнительно обходит дескрипторы всех процессов, что слишком дорого для каждого цикла сбора
+func countTCPStates(ctx context.Context) (map[string]int, error) {
+	root := os.Getenv("HOST_PROC")
+	if root == "" {
+		root = "/proc"
+	}
+
+	states, err := readProcTCPStates(filepath.Join(root, "net", "tcp"), filepath.Join(root, "net", "tcp6"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		return states, err
+	}
+
+	// Платформы без procfs
+	connections, err := net.ConnectionsWithContext(ctx, "tcp")
+	if err != nil {
+		return nil, err
+	}
+	states = make(map[string]int)
+	for _, connection := range connections {
+		if connection.Status != "" && connection.Status != "NONE" {
+			states[strings.ToLower(connection.Status)]++
+		}
+	}
+	return states, nil
+}
+
+// readProcTCPStates разбирает колонку st таблиц /proc/net/tcp*
+// Отсутствие tcp6 (ядро без IPv6) не ошибка
+func readProcTCPStates(files ...string) (map[string]int, error) {
+	names := make(map[string]string, len(tcpStates))
+	for _, state := range tcpStates {
+		names[state.code] = state.name
+	}
+
+	states := make(map[string]int)
+	for i, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			if i > 0 && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // заголовок
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 4 {
+				continue
+			}
+			if name, ok := names[strings.ToUpper(fields[3])]; ok {
+				states[name]++
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+// matchesAny проверяет имя по glob шаблонам
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func newTestNetworkCollector(config NetworkConfig, counters [][]net.IOCountersStat) *NetworkCollector {
+	collector := NewNetworkCollector(config)
+
+	call := 0
+	collector.ioCounters = func(context.Context, bool) ([]net.IOCountersStat, error) {
+		result := counters[call]
+		call++
+		return result, nil
+	}
+	collector.tcpStates = func(context.Context) (map[string]int, error) {
+		return map[string]int{"established": 12, "listen": 3}, nil
+	}
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ticks := 0
+	collector.now = func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * 2 * time.Second)
+	}
+	return collector
+}
+
+// networkValues значения метрик типа по ключу "name{interface}"
+func networkValues(metrics []port.RawMetric, metricType valueobject.MetricType) map[string]float64 {
+	values := make(map[string]float64)
+	for _, metric := range metrics {
+		if metric.Type == metricType {
+			values[metric.Name+"{"+metric.Labels["interface"]+"}"] = metric.Value.Raw()
+		}
+	}
+	return values
+}
+
+func TestNetworkCollectorReportsPerInterfaceRates(t *testing.T) {
+	counters := [][]net.IOCountersStat{
+		{
+			{Name: "eth0", BytesSent: 10240, BytesRecv: 20480, PacketsSent: 10, PacketsRecv: 20},
+			{Name: "eth1", BytesSent: 0, BytesRecv: 0},
+			{Name: "lo", BytesSent: 1 << 20, BytesRecv: 1 << 20},
+		},
+		{
+			// За 2 секунды: eth0 отправил 8 KB и получил 16 KB, 4 ошибки приема и 2 отброшенных пакета
+			{Name: "eth0", BytesSent: 18432, BytesRecv: 36864, PacketsSent: 30, PacketsRecv: 60, Errin: 4, Dropout: 2},
+			{Name: "eth1", BytesSent: 4096, BytesRecv: 2048},
+			{Name: "lo", BytesSent: 2 << 20, BytesRecv: 2 << 20},
+		},
+	}
+	collector := newTestNetworkCollector(NetworkConfig{}, counters)
+
+	first, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("first Collect() error = %v", err)
+	}
+	for _, metric := range first {
+		if metric.Type != valueobject.TCPConnections {
+			t.Fatalf("first cycle must not report rates, got %+v", metric)
+		}
+	}
+
+	metrics, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("second Collect() error = %v", err)
+	}
+
+	sent := networkValues(metrics, valueobject.Network)
+	received := networkValues(metrics, valueobject.NetworkRecv)
+	if _, ok := sent["network_sent{lo}"]; ok {
+		t.Fatalf("loopback must be excluded by default: %v", sent)
+	}
+	if sent["network_sent{eth0}"] != 4 || sent["network_sent{eth1}"] != 2 || sent["network_sent{all}"] != 6 {
+		t.Fatalf("unexpected sent rates: %v", sent)
+	}
+	if received["network_received{eth0}"] != 8 || received["network_received{all}"] != 9 {
+		t.Fatalf("unexpected received rates: %v", received)
+	}
+
+	packets := networkValues(metrics, valueobject.NetworkPackets)
+	if packets["network_packets_sent{eth0}"] != 10 || packets["network_packets_received{eth0}"] != 20 {
+		t.Fatalf("unexpected packet rates: %v", packets)
+	}
+	errs := networkValues(metrics, valueobject.NetworkErrors)
+	if errs["network_errors_in{eth0}"] != 2 || errs["network_drops_out{eth0}"] != 1 || errs["network_errors_out{eth0}"] != 0 {
+		t.Fatalf("unexpected error rates: %v", errs)
+	}
+
+	states := make(map[string]float64)
+	for _, metric := range metrics {
+		if metric.Type == valueobject.TCPConnections {
+			states[metric.Labels["state"]] = metric.Value.Raw()
+		}
+	}
+	if len(states) != len(tcpStates) || states["established"] != 12 || states["listen"] != 3 || states["time_wait"] != 0 {
+		t.Fatalf("unexpected tcp states: %v", states)
+	}
+}
+
+func TestNetworkCollectorHandlesResetsAndInterfaceChanges(t *testing.T) {
+	counters := [][]net.IOCountersStat{
+		{
+			{Name: "eth0", BytesSent: math.MaxUint32 - 1023, BytesRecv: 1000},
+			{Name: "wlan0", BytesSent: 5000, BytesRecv: 5000},
+		},
+		{
+			// eth0: 32-битный счетчик отправки переполнился (+2 KB); wlan0 пересоздан; появился eth1
+			{Name: "eth0", BytesSent: 1024, BytesRecv: 1000},
+			{Name: "wlan0", BytesSent: 10, BytesRecv: 10},
+			{Name: "eth1", BytesSent: 100, BytesRecv: 100},
+		},
+		{
+			// wlan0 исчез
+			{Name: "eth0", BytesSent: 1024, BytesRecv: 1000},
+			{Name: "eth1", BytesSent: 2148, BytesRecv: 100},
+		},
+	}
+	collector := newTestNetworkCollector(NetworkConfig{ExcludeInterfaces: []string{"docker*"}}, counters)
+	collector.tcpStates = func(context.Context) (map[string]int, error) {
+		return nil, errors.New("procfs is not mounted")
+	}
+
+	if _, err := collector.Collect(context.Background()); err != nil {
+		t.Fatalf("first Collect() error = %v", err)
+	}
+
+	second, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("second Collect() error = %v", err)
+	}
+	sent := networkValues(second, valueobject.Network)
+	if len(sent) != 2 || sent["network_sent{eth0}"] != 1 || sent["network_sent{all}"] != 1 {
+		t.Fatalf("wrapped counter must be counted, reset and new interfaces skipped: %v", sent)
+	}
+
+	third, err := collector.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("third Collect() error = %v", err)
+	}
+	sent = networkValues(third, valueobject.Network)
+	if len(sent) != 3 || sent["network_sent{eth1}"] != 1 || sent["network_sent{all}"] != 1 {
+		t.Fatalf("unexpected rates after interface removal: %v", sent)
+	}
+	if _, ok := collector.lastIO["wlan0"]; ok {
+		t.Fatal("state of a removed interface must be dropped")
+	}
+}
+
+func TestCounterDelta(t *testing.T) {
+	tests := []struct {
+		name              string
+		previous, current uint64
+		delta             uint64
+		ok                bool
+	}{
+		{"growth", 100, 150, 50, true},
+		{"32-bit wrap", math.MaxUint32 - 9, 5, 15, true},
+		{"reset", 1000, 10, 0, false},
+		{"64-bit counter decrease", math.MaxUint32 + 100, 10, 0, false},
+	}
+	for _, tt := range tests {
+		delta, ok := counterDelta(tt.previous, tt.current)
+		if delta != tt.delta || ok != tt.ok {
+			t.Errorf("%s: counterDelta(%d, %d) = %d, %v", tt.name, tt.previous, tt.current, delta, ok)
+		}
+	}
+}
+
+func TestReadProcTCPStates(t *testing.T) {
+	dir := t.TempDir()
+	tcp := filepath.Join(dir, "tcp")
+	content := "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n" +
+		"   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1 1\n" +
+		"   1: 0100007F:1F90 0100007F:A1B2 01 00000000:00000000 00:00000000 00000000     0        0 2 1\n" +
+		"   2: 0100007F:1F90 0100007F:A1B4 06 00000000:00000000 00:00000000 00000000     0        0 0 1\n"
+	if err := os.WriteFile(tcp, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// tcp6 отсутствует (ядро без IPv6)
+	states, err := readProcTCPStates(tcp, filepath.Join(dir, "tcp6"))
+	if err != nil {
+		t.Fatalf("readProcTCPStates() error = %v", err)
+	}
+	if states["listen"] != 1 || states["established"] != 1 || states["time_wait"] != 1 {
+		t.Fatalf("unexpected states: %v", states)
+	}
+}
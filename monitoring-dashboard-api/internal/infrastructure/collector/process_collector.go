@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// maxCmdlineLength ограничивает длину командной строки в снимке (аргументы JVM и т.п. бывают огромными)
+const maxCmdlineLength = 512
+
+// ProcessConfig настройки сборщика процессов
+type ProcessConfig struct {
+	// IncludeCmdline добавлять командную строку (в аргументах бывают секреты)
+	IncludeCmdline bool
+}
+
+// processStat счетчики процесса, читаемые каждый цикл
+type processStat struct {
+	createTime int64   // миллисекунды с начала эпохи, вместе с PID однозначно определяет процесс
+	cpuSeconds float64 // user + system
+	rssBytes   uint64
+}
+
+// processInfo неизменные сведения о процессе, читаются один раз за время его жизни
+type processInfo struct {
+	name    string
+	cmdline string
+	user    string
+}
+
+// processState состояние процесса с прошлого цикла
+type processState struct {
+	createTime int64
+	cpuSeconds float64
+	info       processInfo
+}
+
+// ProcessCollector собирает снимок процессов хоста
+// Реализует интерфейс port.ProcessCollector
+// Загрузка CPU считается по разнице процессорного времени между соседними вызовами;
+// для процесса, впервые увиденного в этом вызове, - в среднем за время его жизни
+type ProcessCollector struct {
+	config ProcessConfig
+
+	mu       sync.Mutex
+	last     map[int32]processState
+	lastTime time.Time
+
+	// pids, stat, describe, memTotal и now подменяются в тестах
+	pids     func(ctx context.Context) ([]int32, error)
+	stat     func(ctx context.Context, pid int32) (processStat, error)
+	describe func(ctx context.Context, pid int32, withCmdline bool) (processInfo, error)
+	memTotal func(ctx context.Context) (uint64, error)
+	now      func() time.Time
+}
+
+// NewProcessCollector создает новый Process collector
+func NewProcessCollector(config ProcessConfig) *ProcessCollector {
+	return &ProcessCollector{
+		config:   config,
+		last:     make(map[int32]processState),
+		pids:     process.PidsWithContext,
+		stat:     readProcessStat,
+		describe: describeProcess,
+		memTotal: totalMemory,
+		now:      time.Now,
+	}
+}
+
+// CollectProcesses возвращает снимок всех процессов, доступных для чтения
+// Процессы, завершившиеся во время обхода или недоступные по правам, пропускаются
+func (c *ProcessCollector) CollectProcesses(ctx context.Context) ([]port.ProcessSample, error) {
+	pids, err := c.pids(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Без объема памяти доля RSS не считается, но остальной снимок полезен
+	memTotal, err := c.memTotal(ctx)
+	if err != nil {
+		memTotal = 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	elapsed := now.Sub(c.lastTime).Seconds()
+
+	samples := make([]port.ProcessSample, 0, len(pids))
+	current := make(map[int32]processState, len(pids))
+	for _, pid := range pids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		stat, err := c.stat(ctx, pid)
+		if err != nil {
+			continue
+		}
+
+		// PID мог достаться новому процессу: он узнается по другому времени запуска
+		state, known := c.last[pid]
+		if !known || state.createTime != stat.createTime {
+			info, err := c.describe(ctx, pid, c.config.IncludeCmdline)
+			if err != nil {
+				continue
+			}
+			state = processState{createTime: stat.createTime, info: info}
+			known = false
+		}
+
+		var cpuPercent float64
+		if known && elapsed > 0 {
+			cpuPercent = max(stat.cpuSeconds-state.cpuSeconds, 0) / elapsed * 100
+		} else if lifetime := now.Sub(time.UnixMilli(stat.createTime)).Seconds(); lifetime > 0 {
+			cpuPercent = stat.cpuSeconds / lifetime * 100
+		}
+
+		var memoryPercent float64
+		if memTotal > 0 {
+			memoryPercent = float64(stat.rssBytes) / float64(memTotal) * 100
+		}
+
+		samples = append(samples, port.ProcessSample{
+			PID:           pid,
+			Name:          state.info.name,
+			Cmdline:       state.info.cmdline,
+			User:          state.info.user,
+			CPUPercent:    cpuPercent,
+			RSSBytes:      stat.rssBytes,
+			MemoryPercent: memoryPercent,
+		})
+
+		state.cpuSeconds = stat.cpuSeconds
+		current[pid] = state
+	}
+
+	// Завершившиеся процессы не попадают в новое состояние
+	c.last = current
+	c.lastTime = now
+	return samples, nil
+}
+
+// readProcessStat читает время запуска, процессорное время и RSS процесса
+func readProcessStat(ctx context.Context, pid int32) (processStat, error) {
+	p := &process.Process{Pid: pid}
+
+	createTime, err := p.CreateTimeWithContext(ctx)
+	if err != nil {
+		return processStat{}, err
+	}
+	times, err := p.TimesWithContext(ctx)
+	if err != nil {
+		return processStat{}, err
+	}
+	memory, err := p.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return processStat{}, err
+	}
+
+	return processStat{
+		createTime: createTime,
+		cpuSeconds: times.User + times.System,
+		rssBytes:   memory.RSS,
+	}, nil
+}
+
+// describeProcess читает имя, пользователя и (если разрешено) командную строку процесса
+// Ошибкой считается только недоступное имя; без пользователя и командной строки процесс остается в снимке
+func describeProcess(ctx context.Context, pid int32, withCmdline bool) (processInfo, error) {
+	p := &process.Process{Pid: pid}
+
+	name, err := p.NameWithContext(ctx)
+	if err != nil {
+		return processInfo{}, err
+	}
+	info := processInfo{name: name}
+
+	if username, err := p.UsernameWithContext(ctx); err == nil {
+		info.user = username
+	} else if uids, err := p.UidsWithContext(ctx); err == nil && len(uids) > 0 {
+		// Пользователя нет в /etc/passwd (например, процесс другого контейнера): показываем UID
+		info.user = strconv.Itoa(int(uids[0]))
+	}
+
+	if withCmdline {
+		if cmdline, err := p.CmdlineWithContext(ctx); err == nil {
+			info.cmdline = truncateCmdline(cmdline)
+		}
+	}
+
+	return info, nil
+}
+
+// truncateCmdline обрезает командную строку до maxCmdlineLength байт, не разрывая UTF-8 символы
+func truncateCmdline(cmdline string) string {
+	if len(cmdline) <= maxCmdlineLength {
+		return cmdline
+	}
+	return strings.ToValidUTF8(cmdline[:maxCmdlineLength], "") + "…"
+}
+
+// totalMemory объем физической памяти хоста
+func totalMemory(ctx context.Context) (uint64, error) {
+	vm, err := mem.VirtualMemoryWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return vm.Total, nil
+}
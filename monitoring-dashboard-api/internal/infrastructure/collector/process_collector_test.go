@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+// fakeProcessTable таблица процессов, меняющаяся между вызовами
+type fakeProcessTable struct {
+	stats     map[int32]processStat
+	described []int32
+}
+
+func newTestProcessCollector(table *fakeProcessTable, start time.Time) *ProcessCollector {
+	collector := NewProcessCollector(ProcessConfig{IncludeCmdline: true})
+	collector.pids = func(context.Context) ([]int32, error) {
+		pids := make([]int32, 0, len(table.stats))
+		for pid := range table.stats {
+			pids = append(pids, pid)
+		}
+		return pids, nil
+	}
+	collector.stat = func(_ context.Context, pid int32) (processStat, error) {
+		stat, ok := table.stats[pid]
+		if !ok || stat.createTime == 0 {
+			return processStat{}, errors.New("process exited")
+		}
+		return stat, nil
+	}
+	collector.describe = func(_ context.Context, pid int32, withCmdline bool) (processInfo, error) {
+		table.described = append(table.described, pid)
+		info := processInfo{name: "proc", user: "root"}
+		if withCmdline {
+			info.cmdline = "proc --serve"
+		}
+		return info, nil
+	}
+	collector.memTotal = func(context.Context) (uint64, error) { return 8 << 30, nil }
+
+	ticks := 0
+	collector.now = func() time.Time {
+		ticks++
+		return start.Add(time.Duration(ticks) * 2 * time.Second)
+	}
+	return collector
+}
+
+func samplesByPID(samples []port.ProcessSample) map[int32]port.ProcessSample {
+	result := make(map[int32]port.ProcessSample, len(samples))
+	for _, sample := range samples {
+		result[sample.PID] = sample
+	}
+	return result
+}
+
+func TestProcessCollectorComputesCPUFromDeltas(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Процесс 100 запущен за 98 секунд до первого вызова (часы тестов начинаются с start+2s) и успел потратить 49 секунд CPU
+	launched := start.Add(-96 * time.Second).UnixMilli()
+	table := &fakeProcessTable{stats: map[int32]processStat{
+		100: {createTime: launched, cpuSeconds: 49, rssBytes: 2 << 30},
+		200: {createTime: launched, cpuSeconds: 1, rssBytes: 1 << 20},
+	}}
+	collector := newTestProcessCollector(table, start)
+
+	first, err := collector.CollectProcesses(context.Background())
+	if err != nil {
+		t.Fatalf("first CollectProcesses() error = %v", err)
+	}
+	byPID := samplesByPID(first)
+	if math.Abs(byPID[100].CPUPercent-50) > 1e-9 {
+		t.Fatalf("first cycle must report the lifetime average, got %v", byPID[100].CPUPercent)
+	}
+	if byPID[100].MemoryPercent != 25 || byPID[100].Cmdline != "proc --serve" || byPID[100].User != "root" {
+		t.Fatalf("unexpected sample: %+v", byPID[100])
+	}
+
+	// За 2 секунды процесс 100 потратил 3 секунды CPU (150% одного ядра);
+	// PID 200 занят новым процессом, запущенным секунду назад
+	table.stats[100] = processStat{createTime: launched, cpuSeconds: 52, rssBytes: 2 << 30}
+	table.stats[200] = processStat{createTime: start.Add(3 * time.Second).UnixMilli(), cpuSeconds: 0.5, rssBytes: 1 << 20}
+	table.stats[300] = processStat{} // завершился во время обхода
+
+	second, err := collector.CollectProcesses(context.Background())
+	if err != nil {
+		t.Fatalf("second CollectProcesses() error = %v", err)
+	}
+	byPID = samplesByPID(second)
+	if len(byPID) != 2 || math.Abs(byPID[100].CPUPercent-150) > 1e-9 || math.Abs(byPID[200].CPUPercent-50) > 1e-9 {
+		t.Fatalf("unexpected samples: %+v", second)
+	}
+	// Описание перечитывается только для нового процесса
+	if len(table.described) != 3 {
+		t.Fatalf("processes described %v, want 100, 200 and the reused PID 200 once each", table.described)
+	}
+}
+
+func TestTruncateCmdline(t *testing.T) {
+	long := strings.Repeat("я", maxCmdlineLength)
+	truncated := truncateCmdline(long)
+	if !utf8.ValidString(truncated) || len(truncated) > maxCmdlineLength+len("…") {
+		t.Fatalf("truncated command line is invalid or too long: %d bytes", len(truncated))
+	}
+	if truncateCmdline("nginx -g daemon off;") != "nginx -g daemon off;" {
+		t.Fatal("short command line must be kept")
+	}
+}
@@ -3,10 +3,19 @@ package collector
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 )
 
+// Config настройки системных сборщиков
+type Config struct {
+	Disk    DiskConfig
+	Network NetworkConfig
+	Cgroup  CgroupConfig
+}
+
 // SystemMetricsCollector собирает все системные метрики
 // Реализует интерфейс port.MetricsCollector
 type SystemMetricsCollector struct {
@@ -14,30 +23,45 @@ type SystemMetricsCollector struct {
 	memoryCollector  *MemoryCollector
 	diskCollector    *DiskCollector
 	networkCollector *NetworkCollector
+	cgroupCollector  *CgroupCollector    // Optional: nil if cgroup metrics disabled
+	serviceMetrics   port.ServiceMetrics // Optional: counts collector errors by type
 }
 
 // NewSystemMetricsCollector создает новый системный collector
-func NewSystemMetricsCollector() *SystemMetricsCollector {
-	return &SystemMetricsCollector{
+func NewSystemMetricsCollector(
+	config Config,
+	serviceMetrics port.ServiceMetrics, // Can be nil if /metrics disabled
+) *SystemMetricsCollector {
+	collector := &SystemMetricsCollector{
 		cpuCollector:     NewCPUCollector(),
 		memoryCollector:  NewMemoryCollector(),
-		diskCollector:    NewDiskCollector(),
-		networkCollector: NewNetworkCollector(),
+		diskCollector:    NewDiskCollector(config.Disk),
+		networkCollector: NewNetworkCollector(config.Network),
+		serviceMetrics:   serviceMetrics,
 	}
+	if config.Cgroup.Enabled {
+		collector.cgroupCollector = NewCgroupCollector(config.Cgroup)
+	}
+	return collector
 }
 
 // CollectAll собирает все доступные метрики параллельно
+// Все метрики цикла получают одно время сбора, чтобы серии одного типа можно было сравнивать между собой
 func (c *SystemMetricsCollector) CollectAll(ctx context.Context) ([]port.RawMetric, error) {
+	collectedAt := time.Now()
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	allMetrics := make([]port.RawMetric, 0)
 
 	// Функция для сбора метрик с обработкой ошибок
-	collectFunc := func(collector func(context.Context) ([]port.RawMetric, error)) {
+	collectFunc := func(metricType valueobject.MetricType, collector func(context.Context) ([]port.RawMetric, error)) {
 		defer wg.Done()
 		metrics, err := collector(ctx)
 		if err != nil {
-			// Логируем ошибку, но продолжаем
+			// Учитываем ошибку, но продолжаем с остальными сборщиками
+			if c.serviceMetrics != nil {
+				c.serviceMetrics.CollectorFailed(metricType)
+			}
 			return
 		}
 		mu.Lock()
@@ -47,13 +71,23 @@ func (c *SystemMetricsCollector) CollectAll(ctx context.Context) ([]port.RawMetr
 
 	// Запускаем сбор всех метрик параллельно
 	wg.Add(4)
-	go collectFunc(c.cpuCollector.Collect)
-	go collectFunc(c.memoryCollector.Collect)
-	go collectFunc(c.diskCollector.Collect)
-	go collectFunc(c.networkCollector.Collect)
+	go collectFunc(valueobject.CPU, c.cpuCollector.Collect)
+	go collectFunc(valueobject.Memory, c.memoryCollector.Collect)
+	go collectFunc(valueobject.Disk, c.diskCollector.Collect)
+	go collectFunc(valueobject.Network, c.networkCollector.Collect)
+	if c.cgroupCollector != nil {
+		wg.Add(1)
+		go collectFunc(valueobject.ContainerCPU, c.cgroupCollector.Collect)
+	}
 
 	wg.Wait()
 
+	for i := range allMetrics {
+		if allMetrics[i].CollectedAt.IsZero() {
+			allMetrics[i].CollectedAt = collectedAt
+		}
+	}
+
 	return allMetrics, nil
 }
 
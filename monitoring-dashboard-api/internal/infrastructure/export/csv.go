@@ -0,0 +1,64 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+// csvWriter пишет метрики в CSV; заголовок выводится даже для пустой выгрузки
+type csvWriter struct {
+	buf    *bufio.Writer
+	writer *csv.Writer
+	record []string
+	header bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	buf := bufio.NewWriter(w)
+	return &csvWriter{
+		buf:    buf,
+		writer: csv.NewWriter(buf),
+		record: make([]string, len(Columns)),
+	}
+}
+
+// WriteRow пишет одну строку
+func (w *csvWriter) WriteRow(metric *dto.MetricDTO) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	w.record[0] = formatTime(metric.CollectedAt)
+	w.record[1] = metric.Type
+	w.record[2] = metric.Name
+	w.record[3] = metric.Host
+	w.record[4] = strconv.FormatFloat(metric.Value, 'f', -1, 64)
+	w.record[5] = metric.Unit
+	w.record[6] = formatLabels(metric.Labels)
+
+	return w.writer.Write(w.record)
+}
+
+// Close сбрасывает буферы
+func (w *csvWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.writer.Write(Columns)
+}
@@ -0,0 +1,287 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+func exportTestRows() []*dto.MetricDTO {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 500000000, time.UTC)
+	return []*dto.MetricDTO{
+		{Type: "cpu", Name: "cpu_usage", Host: "web-1", Value: 42.5, Unit: "%", CollectedAt: at},
+		{Type: "disk", Name: "disk_usage", Host: "web-2", Value: 70, Unit: "%", Labels: map[string]string{"mount": "/data", "device": "sda"}, CollectedAt: at.Add(time.Second)},
+		{Type: "custom", Name: "queue_depth", Value: 3, Unit: "count", Labels: map[string]string{"queue": "a,\"b\""}, CollectedAt: at.Add(2 * time.Second)},
+	}
+}
+
+func writeAll(t *testing.T, format Format, rows []*dto.MetricDTO) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := NewRowWriter(format, &buf)
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	for raw, want := range map[string]Format{"": FormatCSV, "CSV": FormatCSV, "ndjson": FormatNDJSON, " parquet ": FormatParquet} {
+		got, err := ParseFormat(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseFormat(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Fatal("ParseFormat(xlsx) should fail")
+	}
+	if FormatParquet.Extension() != "parquet" || FormatNDJSON.ContentType() != "application/x-ndjson" {
+		t.Fatal("unexpected format attributes")
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeAll(t, FormatCSV, exportTestRows()))).ReadAll()
+	if err != nil {
+		t.Fatalf("csv.ReadAll() error = %v", err)
+	}
+	if len(records) != 4 || fmt.Sprint(records[0]) != fmt.Sprint(Columns) {
+		t.Fatalf("unexpected csv records: %v", records)
+	}
+	if got := records[1]; got[0] != "2026-03-01T12:00:00.5Z" || got[4] != "42.5" || got[6] != "{}" {
+		t.Fatalf("unexpected cpu row: %v", got)
+	}
+	if got := records[2][6]; got != `{"device":"sda","mount":"/data"}` {
+		t.Fatalf("labels column = %s", got)
+	}
+	if got := records[3][6]; got != `{"queue":"a,\"b\""}` {
+		t.Fatalf("escaped labels column = %s", got)
+	}
+
+	// Пустая выгрузка - только заголовок
+	if got := string(writeAll(t, FormatCSV, nil)); got != "collected_at,type,name,host,value,unit,labels\n" {
+		t.Fatalf("empty csv = %q", got)
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	scanner := bufio.NewScanner(bytes.NewReader(writeAll(t, FormatNDJSON, exportTestRows())))
+	var rows []ndjsonRow
+	for scanner.Scan() {
+		var row ndjsonRow
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("invalid ndjson line %q: %v", scanner.Text(), err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("ndjson rows = %d, want 3", len(rows))
+	}
+	if rows[0].Labels == nil || rows[1].Labels["mount"] != "/data" || rows[2].Host != "" || rows[2].Value != 3 {
+		t.Fatalf("unexpected ndjson rows: %+v", rows)
+	}
+}
+
+func TestParquetWriterLayout(t *testing.T) {
+	rows := exportTestRows()
+	var buf bytes.Buffer
+	writer := newParquetWriter(&buf, 2) // две группы строк: 2 + 1
+	for _, row := range rows {
+		if err := writer.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := newThriftReader(data[len(data)-8-footerLen : len(data)-8]).readStruct()
+
+	if footer[3] != int64(3) {
+		t.Fatalf("num_rows = %v, want 3", footer[3])
+	}
+	schema := footer[2].([]interface{})
+	if len(schema) != len(Columns)+1 || schema[0].(thriftStructValue)[5] != int64(len(Columns)) {
+		t.Fatalf("unexpected schema: %v", schema)
+	}
+	for i, name := range Columns {
+		element := schema[i+1].(thriftStructValue)
+		if string(element[4].([]byte)) != name || element[3] != int64(parquetRequired) {
+			t.Fatalf("schema column %d = %v, want required %s", i, element, name)
+		}
+	}
+	if ts := schema[1].(thriftStructValue)[10].(thriftStructValue)[8].(thriftStructValue); ts[1] != true {
+		t.Fatalf("collected_at must be a UTC timestamp: %v", ts)
+	}
+
+	groups := footer[4].([]interface{})
+	if len(groups) != 2 || groups[0].(thriftStructValue)[3] != int64(2) || groups[1].(thriftStructValue)[3] != int64(1) {
+		t.Fatalf("unexpected row groups: %v", groups)
+	}
+
+	var collectedAt, values []int64
+	var names, labels []string
+	for _, group := range groups {
+		chunks := group.(thriftStructValue)[1].([]interface{})
+		collectedAt = append(collectedAt, readInt64Column(t, data, chunks[0])...)
+		names = append(names, readByteArrayColumn(t, data, chunks[2])...)
+		for _, bits := range readInt64Column(t, data, chunks[4]) {
+			values = append(values, int64(math.Float64frombits(uint64(bits))*10))
+		}
+		labels = append(labels, readByteArrayColumn(t, data, chunks[6])...)
+	}
+
+	if collectedAt[0] != rows[0].CollectedAt.UnixMicro() || collectedAt[2] != rows[2].CollectedAt.UnixMicro() {
+		t.Fatalf("collected_at = %v", collectedAt)
+	}
+	if fmt.Sprint(names) != "[cpu_usage disk_usage queue_depth]" || fmt.Sprint(values) != "[425 700 30]" {
+		t.Fatalf("names = %v, values = %v", names, values)
+	}
+	if labels[0] != "{}" || labels[1] != `{"device":"sda","mount":"/data"}` {
+		t.Fatalf("labels = %v", labels)
+	}
+}
+
+func TestParquetWriterEmpty(t *testing.T) {
+	data := writeAll(t, FormatParquet, nil)
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	if 4+footerLen+8 != len(data) {
+		t.Fatalf("unexpected empty file layout: %d bytes, footer %d", len(data), footerLen)
+	}
+	footer := newThriftReader(data[4 : 4+footerLen]).readStruct()
+	if footer[3] != int64(0) || len(footer[4].([]interface{})) != 0 {
+		t.Fatalf("unexpected empty footer: %v", footer)
+	}
+}
+
+// readPage читает страницу данных column chunk и возвращает распакованные PLAIN-значения
+func readPage(t *testing.T, data []byte, chunk interface{}) ([]byte, int) {
+	t.Helper()
+	meta := chunk.(thriftStructValue)[3].(thriftStructValue)
+	reader := newThriftReader(data[meta[9].(int64):])
+	header := reader.readStruct()
+	compressed := data[int(meta[9].(int64))+reader.pos:][:header[3].(int64)]
+	values, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		t.Fatalf("snappy.Decode() error = %v", err)
+	}
+	if int64(len(values)) != header[2].(int64) {
+		t.Fatalf("uncompressed size = %d, header says %d", len(values), header[2])
+	}
+	return values, int(header[5].(thriftStructValue)[1].(int64))
+}
+
+func readInt64Column(t *testing.T, data []byte, chunk interface{}) []int64 {
+	values, count := readPage(t, data, chunk)
+	result := make([]int64, count)
+	for i := range result {
+		result[i] = int64(binary.LittleEndian.Uint64(values[i*8:]))
+	}
+	return result
+}
+
+func readByteArrayColumn(t *testing.T, data []byte, chunk interface{}) []string {
+	values, count := readPage(t, data, chunk)
+	result := make([]string, 0, count)
+	for len(result) < count {
+		size := binary.LittleEndian.Uint32(values)
+		result = append(result, string(values[4:4+size]))
+		values = values[4+size:]
+	}
+	return result
+}
+
+// thriftStructValue разобранная структура Thrift: id поля -> значение
+type thriftStructValue map[int16]interface{}
+
+// thriftReader минимальный читатель Thrift Compact Protocol для проверки footer
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func newThriftReader(data []byte) *thriftReader {
+	return &thriftReader{data: data}
+}
+
+func (r *thriftReader) readStruct() thriftStructValue {
+	result := make(thriftStructValue)
+	var last int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return result
+		}
+		fieldType := header & 0x0f
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.readZigzag())
+		}
+		last = id
+		result[id] = r.readValue(fieldType)
+	}
+}
+
+func (r *thriftReader) readValue(fieldType byte) interface{} {
+	switch fieldType {
+	case thriftBoolTrue:
+		return true
+	case thriftBoolFalse:
+		return false
+	case thriftI32, thriftI64:
+		return r.readZigzag()
+	case thriftBinary:
+		size := int(r.readVarint())
+		value := r.data[r.pos : r.pos+size]
+		r.pos += size
+		return value
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.readVarint())
+		}
+		items := make([]interface{}, size)
+		for i := range items {
+			items[i] = r.readValue(header & 0x0f)
+		}
+		return items
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		panic(fmt.Sprintf("unexpected thrift type %d", fieldType))
+	}
+}
+
+func (r *thriftReader) readVarint() uint64 {
+	value, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) readZigzag() int64 {
+	value := r.readVarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
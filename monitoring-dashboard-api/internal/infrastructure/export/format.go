@@ -0,0 +1,91 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+// Format формат выгрузки исторических метрик
+type Format string
+
+const (
+	// FormatCSV CSV с заголовком, метки - JSON-объект в одной колонке
+	FormatCSV Format = "csv"
+	// FormatNDJSON одна JSON-строка на метрику
+	FormatNDJSON Format = "ndjson"
+	// FormatParquet колоночный формат Apache Parquet (страницы сжаты snappy)
+	FormatParquet Format = "parquet"
+)
+
+// Columns колонки выгрузки (одинаковые для всех форматов)
+var Columns = []string{"collected_at", "type", "name", "host", "value", "unit", "labels"}
+
+// ParseFormat разбирает параметр format (пустой - CSV)
+func ParseFormat(raw string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(raw))); format {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q: use csv, ndjson or parquet", raw)
+	}
+}
+
+// ContentType возвращает значение заголовка Content-Type для формата
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension возвращает расширение файла выгрузки
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// RowWriter последовательно пишет метрики в выходной поток
+// Close дописывает хвост формата (footer Parquet) и сбрасывает буферы, но не закрывает сам поток
+type RowWriter interface {
+	WriteRow(metric *dto.MetricDTO) error
+	Close() error
+}
+
+// NewRowWriter создает writer для формата
+func NewRowWriter(format Format, w io.Writer) RowWriter {
+	switch format {
+	case FormatNDJSON:
+		return newNDJSONWriter(w)
+	case FormatParquet:
+		return newParquetWriter(w, defaultRowGroupSize)
+	default:
+		return newCSVWriter(w)
+	}
+}
+
+// formatTime время строки выгрузки: RFC3339 в UTC
+func formatTime(at time.Time) string {
+	return at.UTC().Format(time.RFC3339Nano)
+}
+
+// formatLabels метки строки как JSON-объект с отсортированными ключами ("{}" - без меток)
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+// ndjsonRow строка NDJSON-выгрузки
+type ndjsonRow struct {
+	CollectedAt string            `json:"collected_at"`
+	Type        string            `json:"type"`
+	Name        string            `json:"name"`
+	Host        string            `json:"host"`
+	Value       float64           `json:"value"`
+	Unit        string            `json:"unit"`
+	Labels      map[string]string `json:"labels"`
+}
+
+// ndjsonWriter пишет по одному JSON-объекту на строку
+type ndjsonWriter struct {
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	return &ndjsonWriter{buf: buf, encoder: encoder}
+}
+
+// WriteRow пишет одну строку
+func (w *ndjsonWriter) WriteRow(metric *dto.MetricDTO) error {
+	labels := metric.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return w.encoder.Encode(ndjsonRow{
+		CollectedAt: formatTime(metric.CollectedAt),
+		Type:        metric.Type,
+		Name:        metric.Name,
+		Host:        metric.Host,
+		Value:       metric.Value,
+		Unit:        metric.Unit,
+		Labels:      labels,
+	})
+}
+
+// Close сбрасывает буфер
+func (w *ndjsonWriter) Close() error {
+	return w.buf.Flush()
+}
@@ -0,0 +1,296 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+// defaultRowGroupSize строк в одной группе строк Parquet: столько строк буферизуется в памяти
+const defaultRowGroupSize = 65536
+
+const parquetMagic = "PAR1"
+
+// Значения перечислений parquet.thrift
+const (
+	parquetTypeInt64     int32 = 2
+	parquetTypeDouble    int32 = 5
+	parquetTypeByteArray int32 = 6
+
+	parquetRequired int32 = 0
+
+	parquetConvertedNone            int32 = -1
+	parquetConvertedUTF8            int32 = 0
+	parquetConvertedTimestampMicros int32 = 10
+	parquetConvertedJSON            int32 = 19
+
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+
+	parquetCodecSnappy int32 = 1
+
+	parquetPageData int32 = 0
+)
+
+// parquetLogical логический тип колонки (union LogicalType)
+type parquetLogical int
+
+const (
+	logicalNone parquetLogical = iota
+	logicalString
+	logicalJSON
+	logicalTimestampMicros
+)
+
+// parquetColumn описание колонки схемы; все колонки REQUIRED, без вложенности
+type parquetColumn struct {
+	name      string
+	physical  int32
+	converted int32
+	logical   parquetLogical
+}
+
+// parquetColumns схема выгрузки в порядке Columns
+var parquetColumns = []parquetColumn{
+	{name: "collected_at", physical: parquetTypeInt64, converted: parquetConvertedTimestampMicros, logical: logicalTimestampMicros},
+	{name: "type", physical: parquetTypeByteArray, converted: parquetConvertedUTF8, logical: logicalString},
+	{name: "name", physical: parquetTypeByteArray, converted: parquetConvertedUTF8, logical: logicalString},
+	{name: "host", physical: parquetTypeByteArray, converted: parquetConvertedUTF8, logical: logicalString},
+	{name: "value", physical: parquetTypeDouble, converted: parquetConvertedNone, logical: logicalNone},
+	{name: "unit", physical: parquetTypeByteArray, converted: parquetConvertedUTF8, logical: logicalString},
+	{name: "labels", physical: parquetTypeByteArray, converted: parquetConvertedJSON, logical: logicalJSON},
+}
+
+// parquetChunk расположение column chunk в файле
+type parquetChunk struct {
+	offset           int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// parquetRowGroup записанная группа строк
+type parquetRowGroup struct {
+	rows   int64
+	chunks []parquetChunk
+}
+
+// parquetWriter пишет Parquet потоково: строки копятся по колонкам и сбрасываются группами строк
+// В каждой группе по одной странице данных на колонку (PLAIN, snappy); footer пишется в Close
+type parquetWriter struct {
+	out          *bufio.Writer
+	offset       int64
+	rowGroupSize int
+	started      bool
+
+	columns   [][]byte // PLAIN-значения текущей группы по колонкам
+	rows      int
+	totalRows int64
+	rowGroups []parquetRowGroup
+}
+
+func newParquetWriter(w io.Writer, rowGroupSize int) *parquetWriter {
+	if rowGroupSize <= 0 {
+		rowGroupSize = defaultRowGroupSize
+	}
+	return &parquetWriter{
+		out:          bufio.NewWriter(w),
+		rowGroupSize: rowGroupSize,
+		columns:      make([][]byte, len(parquetColumns)),
+	}
+}
+
+// WriteRow добавляет строку в текущую группу
+func (w *parquetWriter) WriteRow(metric *dto.MetricDTO) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.columns[0] = binary.LittleEndian.AppendUint64(w.columns[0], uint64(metric.CollectedAt.UnixMicro()))
+	w.columns[1] = appendByteArray(w.columns[1], metric.Type)
+	w.columns[2] = appendByteArray(w.columns[2], metric.Name)
+	w.columns[3] = appendByteArray(w.columns[3], metric.Host)
+	w.columns[4] = binary.LittleEndian.AppendUint64(w.columns[4], math.Float64bits(metric.Value))
+	w.columns[5] = appendByteArray(w.columns[5], metric.Unit)
+	w.columns[6] = appendByteArray(w.columns[6], formatLabels(metric.Labels))
+	w.rows++
+
+	if w.rows >= w.rowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// Close сбрасывает последнюю группу и пишет footer
+func (w *parquetWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if w.rows > 0 {
+		if err := w.flushRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	footer := w.encodeFileMetaData()
+	if err := w.write(footer); err != nil {
+		return err
+	}
+	if err := w.write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	if err := w.write([]byte(parquetMagic)); err != nil {
+		return err
+	}
+	return w.out.Flush()
+}
+
+func (w *parquetWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.write([]byte(parquetMagic))
+}
+
+// flushRowGroup пишет накопленные колонки страницами данных
+func (w *parquetWriter) flushRowGroup() error {
+	group := parquetRowGroup{
+		rows:   int64(w.rows),
+		chunks: make([]parquetChunk, len(w.columns)),
+	}
+
+	for i, values := range w.columns {
+		compressed := snappy.Encode(nil, values)
+		header := encodePageHeader(len(values), len(compressed), w.rows)
+
+		group.chunks[i] = parquetChunk{
+			offset:           w.offset,
+			uncompressedSize: int64(len(header) + len(values)),
+			compressedSize:   int64(len(header) + len(compressed)),
+		}
+		if err := w.write(header); err != nil {
+			return err
+		}
+		if err := w.write(compressed); err != nil {
+			return err
+		}
+		w.columns[i] = values[:0]
+	}
+
+	w.rowGroups = append(w.rowGroups, group)
+	w.totalRows += group.rows
+	w.rows = 0
+	return nil
+}
+
+func (w *parquetWriter) write(data []byte) error {
+	n, err := w.out.Write(data)
+	w.offset += int64(n)
+	return err
+}
+
+// encodeFileMetaData сериализует FileMetaData: схему, группы строк и расположение колонок
+func (w *parquetWriter) encodeFileMetaData() []byte {
+	e := newThriftEncoder()
+	e.i32(1, 1) // version
+
+	e.listBegin(2, thriftStruct, len(parquetColumns)+1)
+	e.elemBegin()
+	e.string(4, "schema")
+	e.i32(5, int32(len(parquetColumns)))
+	e.structEnd()
+	for _, column := range parquetColumns {
+		e.elemBegin()
+		e.i32(1, column.physical)
+		e.i32(3, parquetRequired)
+		e.string(4, column.name)
+		if column.converted != parquetConvertedNone {
+			e.i32(6, column.converted)
+		}
+		encodeLogicalType(e, column.logical)
+		e.structEnd()
+	}
+
+	e.i64(3, w.totalRows)
+
+	e.listBegin(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		e.elemBegin()
+		var totalBytes int64
+		e.listBegin(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			totalBytes += chunk.uncompressedSize
+			e.elemBegin()
+			e.i64(2, chunk.offset)
+			e.structBegin(3)
+			e.i32(1, parquetColumns[i].physical)
+			e.listBegin(2, thriftI32, 2)
+			e.elemI32(parquetEncodingPlain)
+			e.elemI32(parquetEncodingRLE)
+			e.listBegin(3, thriftBinary, 1)
+			e.elemString(parquetColumns[i].name)
+			e.i32(4, parquetCodecSnappy)
+			e.i64(5, group.rows)
+			e.i64(6, chunk.uncompressedSize)
+			e.i64(7, chunk.compressedSize)
+			e.i64(9, chunk.offset)
+			e.structEnd()
+			e.structEnd()
+		}
+		e.i64(2, totalBytes)
+		e.i64(3, group.rows)
+		e.structEnd()
+	}
+
+	e.string(6, "monitoring-dashboard")
+	return e.bytes()
+}
+
+// encodeLogicalType пишет поле logicalType элемента схемы
+func encodeLogicalType(e *thriftEncoder, logical parquetLogical) {
+	switch logical {
+	case logicalString:
+		e.structBegin(10)
+		e.emptyStruct(1)
+		e.structEnd()
+	case logicalJSON:
+		e.structBegin(10)
+		e.emptyStruct(12)
+		e.structEnd()
+	case logicalTimestampMicros:
+		e.structBegin(10)
+		e.structBegin(8)
+		e.bool(1, true) // isAdjustedToUTC
+		e.structBegin(2)
+		e.emptyStruct(2) // MICROS
+		e.structEnd()
+		e.structEnd()
+		e.structEnd()
+	}
+}
+
+// encodePageHeader сериализует PageHeader страницы данных v1 без уровней определения и повторения
+func encodePageHeader(uncompressedSize, compressedSize, values int) []byte {
+	e := newThriftEncoder()
+	e.i32(1, parquetPageData)
+	e.i32(2, int32(uncompressedSize))
+	e.i32(3, int32(compressedSize))
+	e.structBegin(5)
+	e.i32(1, int32(values))
+	e.i32(2, parquetEncodingPlain)
+	e.i32(3, parquetEncodingRLE)
+	e.i32(4, parquetEncodingRLE)
+	e.structEnd()
+	return e.bytes()
+}
+
+// appendByteArray PLAIN-кодирование BYTE_ARRAY: длина (4 байта LE) и байты
+func appendByteArray(buf []byte, value string) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(value)))
+	return append(buf, value...)
+}
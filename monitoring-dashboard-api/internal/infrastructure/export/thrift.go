@@ -0,0 +1,112 @@
+package export
+
+import "encoding/binary"
+
+// Типы полей Thrift Compact Protocol, которыми сериализуются метаданные Parquet
+const (
+	thriftBoolTrue  byte = 1
+	thriftBoolFalse byte = 2
+	thriftI32       byte = 5
+	thriftI64       byte = 6
+	thriftBinary    byte = 8
+	thriftList      byte = 9
+	thriftStruct    byte = 12
+)
+
+// thriftEncoder минимальный писатель Thrift Compact Protocol: только то, что нужно для footer и заголовков страниц
+// Поля структуры должны записываться по возрастанию id; вложенные структуры открываются structBegin/elemBegin
+type thriftEncoder struct {
+	buf  []byte
+	last []int16 // id последнего поля в каждой открытой структуре
+}
+
+func newThriftEncoder() *thriftEncoder {
+	return &thriftEncoder{last: []int16{0}}
+}
+
+// bytes завершает корневую структуру и возвращает результат
+func (e *thriftEncoder) bytes() []byte {
+	return append(e.buf, 0)
+}
+
+func (e *thriftEncoder) field(id int16, fieldType byte) {
+	top := len(e.last) - 1
+	delta := id - e.last[top]
+	if delta > 0 && delta <= 15 {
+		e.buf = append(e.buf, byte(delta)<<4|fieldType)
+	} else {
+		e.buf = append(e.buf, fieldType)
+		e.buf = binary.AppendUvarint(e.buf, zigzag(int64(id)))
+	}
+	e.last[top] = id
+}
+
+func (e *thriftEncoder) i32(id int16, value int32) {
+	e.field(id, thriftI32)
+	e.buf = binary.AppendUvarint(e.buf, zigzag(int64(value)))
+}
+
+func (e *thriftEncoder) i64(id int16, value int64) {
+	e.field(id, thriftI64)
+	e.buf = binary.AppendUvarint(e.buf, zigzag(value))
+}
+
+func (e *thriftEncoder) bool(id int16, value bool) {
+	if value {
+		e.field(id, thriftBoolTrue)
+	} else {
+		e.field(id, thriftBoolFalse)
+	}
+}
+
+func (e *thriftEncoder) string(id int16, value string) {
+	e.field(id, thriftBinary)
+	e.elemString(value)
+}
+
+// structBegin открывает вложенную структуру в поле id
+func (e *thriftEncoder) structBegin(id int16) {
+	e.field(id, thriftStruct)
+	e.last = append(e.last, 0)
+}
+
+// emptyStruct пишет поле-структуру без полей (варианты union LogicalType)
+func (e *thriftEncoder) emptyStruct(id int16) {
+	e.structBegin(id)
+	e.structEnd()
+}
+
+// structEnd закрывает структуру, открытую structBegin или elemBegin
+func (e *thriftEncoder) structEnd() {
+	e.buf = append(e.buf, 0)
+	e.last = e.last[:len(e.last)-1]
+}
+
+// listBegin пишет заголовок списка из size элементов типа elemType
+func (e *thriftEncoder) listBegin(id int16, elemType byte, size int) {
+	e.field(id, thriftList)
+	if size < 15 {
+		e.buf = append(e.buf, byte(size)<<4|elemType)
+		return
+	}
+	e.buf = append(e.buf, 0xf0|elemType)
+	e.buf = binary.AppendUvarint(e.buf, uint64(size))
+}
+
+// elemBegin открывает структуру - элемент списка
+func (e *thriftEncoder) elemBegin() {
+	e.last = append(e.last, 0)
+}
+
+func (e *thriftEncoder) elemI32(value int32) {
+	e.buf = binary.AppendUvarint(e.buf, zigzag(int64(value)))
+}
+
+func (e *thriftEncoder) elemString(value string) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(value)))
+	e.buf = append(e.buf, value...)
+}
+
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
@@ -0,0 +1,136 @@
+package bulk
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+func readAll(t *testing.T, format Format, data string) []port.ImportRow {
+	t.Helper()
+	reader, err := NewReader(format, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	var rows []port.ImportRow
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReaderParsesExportedFile(t *testing.T) {
+	data := "\ufeffcollected_at,type,name,host,value,unit,labels\n" +
+		"2026-03-01T12:00:00.5Z,cpu,cpu_usage,web-1,42.5,%,{}\n" +
+		`1772366401,disk,disk_usage,web-2,70,%,"{""mount"":""/data""}"` + "\n" +
+		"2026-03-01T12:00:02Z,cpu,cpu_usage,web-1,abc,%,\n" +
+		"2026-03-01T12:00:03Z,cpu,cpu_usage\n" +
+		"2026-03-01T12:00:04Z,memory,,web-1,10,,\n"
+
+	rows := readAll(t, FormatCSV, data)
+	if len(rows) != 5 {
+		t.Fatalf("rows = %d, want 5", len(rows))
+	}
+
+	first := rows[0]
+	if first.Err != nil || first.Line != 2 || first.Metric.Name != "cpu_usage" || first.Metric.Host != "web-1" || first.Metric.Value.Raw() != 42.5 {
+		t.Fatalf("unexpected first row: %+v", first)
+	}
+	if !first.Metric.CollectedAt.Equal(time.Date(2026, 3, 1, 12, 0, 0, 500000000, time.UTC)) || len(first.Metric.Labels) != 0 {
+		t.Fatalf("unexpected first row time or labels: %+v", first.Metric)
+	}
+	if second := rows[1]; second.Err != nil || second.Metric.Labels["mount"] != "/data" || second.Metric.CollectedAt.Unix() != 1772366401 {
+		t.Fatalf("unexpected second row: %+v", second)
+	}
+	if rows[2].Err == nil || rows[2].Line != 4 {
+		t.Fatalf("invalid value must be a row error: %+v", rows[2])
+	}
+	if rows[3].Err == nil || rows[3].Line != 5 {
+		t.Fatalf("short record must be a row error: %+v", rows[3])
+	}
+
+	// Без имени и единицы берутся тип и основная единица типа
+	if last := rows[4]; last.Err != nil || last.Metric.Name != "memory" || last.Metric.Value.Unit() != "%" {
+		t.Fatalf("unexpected defaults: %+v", last)
+	}
+}
+
+func TestCSVReaderRejectsBadHeader(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":     "",
+		"unknown":   "collected_at,type,value,labes\n",
+		"missing":   "collected_at,name,value\n",
+		"duplicate": "collected_at,type,value,type\n",
+	} {
+		if _, err := NewReader(FormatCSV, strings.NewReader(data)); !errors.Is(err, ErrInvalidFile) {
+			t.Fatalf("%s header: error = %v, want ErrInvalidFile", name, err)
+		}
+	}
+}
+
+func TestCSVReaderStopsOnBrokenQuoting(t *testing.T) {
+	reader, err := NewReader(FormatCSV, strings.NewReader("collected_at,type,value\n1772366400,cpu,\"1\n"))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Fatalf("broken quoting must stop the import, got %v", err)
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	data := `{"collected_at":"2026-03-01T12:00:00Z","type":"cpu","name":"cpu_usage","host":"web-1","value":42.5,"unit":"%","labels":{"core":"0"},"metadata":{"model":"x"}}` + "\n" +
+		"\n" +
+		`{"collected_at":1772366401,"type":"disk","value":"70","unit":"%"}` + "\n" +
+		`{"collected_at":"2026-03-01T12:00:02Z","type":"cpu","value":1` + "\n" +
+		`{"collected_at":"2026-03-01T12:00:03Z","type":"cpu","unit":"%"}`
+
+	rows := readAll(t, FormatNDJSON, data)
+	if len(rows) != 4 {
+		t.Fatalf("rows = %d, want 4", len(rows))
+	}
+	if first := rows[0]; first.Err != nil || first.Metric.Labels["core"] != "0" || first.Metric.Metadata["model"] != "x" {
+		t.Fatalf("unexpected first row: %+v", first)
+	}
+	if second := rows[1]; second.Err != nil || second.Line != 3 || second.Metric.Value.Raw() != 70 || second.Metric.Name != "disk" {
+		t.Fatalf("unexpected second row: %+v", second)
+	}
+	if rows[2].Err == nil || rows[2].Line != 4 {
+		t.Fatalf("invalid JSON must be a row error: %+v", rows[2])
+	}
+	if rows[3].Err == nil || !strings.Contains(rows[3].Err.Error(), "value is required") {
+		t.Fatalf("missing value must be a row error: %+v", rows[3])
+	}
+}
+
+func TestNDJSONReaderRejectsHugeLine(t *testing.T) {
+	reader, _ := NewReader(FormatNDJSON, strings.NewReader(strings.Repeat("x", maxLineSize+10)))
+	if _, err := reader.Next(); !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("Next() error = %v, want ErrInvalidFile", err)
+	}
+}
+
+func TestFormatDetection(t *testing.T) {
+	if format, err := ParseFormat("JSONL"); err != nil || format != FormatNDJSON {
+		t.Fatalf("ParseFormat(JSONL) = %q, %v", format, err)
+	}
+	if _, err := ParseFormat("parquet"); err == nil {
+		t.Fatal("parquet import is not supported")
+	}
+	if format, ok := FormatFromContentType("text/csv; charset=utf-8"); !ok || format != FormatCSV {
+		t.Fatalf("FormatFromContentType(text/csv) = %q, %v", format, ok)
+	}
+	if _, ok := FormatFromContentType("application/octet-stream"); ok {
+		t.Fatal("octet-stream must not be detected")
+	}
+}
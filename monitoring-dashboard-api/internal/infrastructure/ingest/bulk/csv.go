@@ -0,0 +1,107 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+// requiredColumns колонки, без которых CSV не принимается
+var requiredColumns = []string{"collected_at", "type", "value"}
+
+// knownColumns все поддерживаемые колонки
+var knownColumns = map[string]bool{
+	"collected_at": true,
+	"type":         true,
+	"name":         true,
+	"host":         true,
+	"value":        true,
+	"unit":         true,
+	"labels":       true,
+}
+
+// csvReader читает CSV с заголовком; порядок колонок произвольный
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file, a header row is required", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !knownColumns[column] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidFile, column)
+		}
+		if _, duplicate := columns[column]; duplicate {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidFile, column)
+		}
+		columns[column] = i
+	}
+	for _, column := range requiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFile, column)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+// Next читает следующую строку
+func (r *csvReader) Next() (port.ImportRow, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return port.ImportRow{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if err != nil && !(errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount)) {
+		// Нарушенное экранирование сбивает разбор всех следующих строк
+		return port.ImportRow{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	if err != nil {
+		return port.ImportRow{Line: parseErr.StartLine, Err: errors.New("wrong number of fields")}, nil
+	}
+
+	labels, err := parseLabels(r.field(record, "labels"))
+	if err != nil {
+		return port.ImportRow{Line: line, Err: err}, nil
+	}
+
+	metric, err := toRawMetric(rowFields{
+		collectedAt: r.field(record, "collected_at"),
+		metricType:  r.field(record, "type"),
+		name:        r.field(record, "name"),
+		host:        r.field(record, "host"),
+		value:       r.field(record, "value"),
+		unit:        r.field(record, "unit"),
+		labels:      labels,
+	})
+	return port.ImportRow{Line: line, Metric: metric, Err: err}, nil
+}
+
+// field значение колонки (пустое, если колонки нет в файле)
+func (r *csvReader) field(record []string, column string) string {
+	index, ok := r.columns[column]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
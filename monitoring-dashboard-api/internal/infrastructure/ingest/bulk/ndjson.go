@@ -0,0 +1,107 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+// maxLineSize ограничивает длину строки NDJSON
+const maxLineSize = 1024 * 1024
+
+// ndjsonRow строка NDJSON (поля как у выгрузки; collected_at - RFC3339 или unix-секунды, value - число)
+type ndjsonRow struct {
+	CollectedAt json.RawMessage        `json:"collected_at"`
+	Type        string                 `json:"type"`
+	Name        string                 `json:"name"`
+	Host        string                 `json:"host"`
+	Value       json.RawMessage        `json:"value"`
+	Unit        string                 `json:"unit"`
+	Labels      map[string]string      `json:"labels"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// ndjsonReader читает по одному JSON-объекту на строку; пустые строки пропускаются
+type ndjsonReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	return &ndjsonReader{reader: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Next читает следующую непустую строку
+func (r *ndjsonReader) Next() (port.ImportRow, error) {
+	for {
+		data, err := r.readLine()
+		if err != nil {
+			return port.ImportRow{}, err
+		}
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var row ndjsonRow
+		if err := json.Unmarshal(data, &row); err != nil {
+			return port.ImportRow{Line: r.line, Err: fmt.Errorf("invalid JSON: %w", err)}, nil
+		}
+
+		metric, err := toRawMetric(rowFields{
+			collectedAt: jsonScalar(row.CollectedAt),
+			metricType:  strings.TrimSpace(row.Type),
+			name:        strings.TrimSpace(row.Name),
+			host:        strings.TrimSpace(row.Host),
+			value:       jsonScalar(row.Value),
+			unit:        strings.TrimSpace(row.Unit),
+			labels:      row.Labels,
+			metadata:    row.Metadata,
+		})
+		return port.ImportRow{Line: r.line, Metric: metric, Err: err}, nil
+	}
+}
+
+// readLine читает строку целиком; io.EOF - только если данных больше нет
+func (r *ndjsonReader) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxLineSize {
+			return nil, fmt.Errorf("%w: line %d is longer than %d bytes", ErrInvalidFile, r.line+1, maxLineSize)
+		}
+		switch {
+		case err == nil:
+			return line, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && len(line) > 0:
+			return line, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// jsonScalar строковое представление JSON-строки или числа (null и отсутствие - пустая строка)
+func jsonScalar(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	if raw[0] == '"' {
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil {
+			return strings.TrimSpace(value)
+		}
+	}
+	return string(raw)
+}
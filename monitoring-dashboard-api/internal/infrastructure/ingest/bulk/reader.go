@@ -0,0 +1,144 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// ErrInvalidFile файл импорта не удается разобрать (заголовок CSV, слишком длинная строка)
+var ErrInvalidFile = errors.New("invalid import file")
+
+// Format формат файла импорта
+type Format string
+
+const (
+	// FormatCSV CSV с заголовком (колонки как у выгрузки /api/v1/metrics/export)
+	FormatCSV Format = "csv"
+	// FormatNDJSON одна JSON-строка на метрику
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat разбирает имя формата (пустое - CSV); jsonl - синоним ndjson
+func ParseFormat(raw string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported import format %q: use csv or ndjson", raw)
+	}
+}
+
+// FormatFromContentType определяет формат по Content-Type запроса
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/x-ndjson", "application/jsonl", "application/json":
+		return FormatNDJSON, true
+	default:
+		return "", false
+	}
+}
+
+// NewReader создает читатель строк для формата
+// Для CSV сразу читается заголовок, поэтому ошибка заголовка возвращается здесь
+func NewReader(format Format, r io.Reader) (port.MetricRowReader, error) {
+	if format == FormatNDJSON {
+		return newNDJSONReader(r), nil
+	}
+	return newCSVReader(r)
+}
+
+// rowFields поля строки до преобразования в метрику
+type rowFields struct {
+	collectedAt string
+	metricType  string
+	name        string
+	host        string
+	value       string
+	unit        string
+	labels      map[string]string
+	metadata    map[string]interface{}
+}
+
+// toRawMetric проверяет формат полей строки и собирает port.RawMetric
+// Тип, метки и правдоподобие значения проверяет use case
+func toRawMetric(fields rowFields) (port.RawMetric, error) {
+	if fields.collectedAt == "" {
+		return port.RawMetric{}, errors.New("collected_at is required")
+	}
+	collectedAt, err := valueobject.ParseTimestamp(fields.collectedAt)
+	if err != nil {
+		return port.RawMetric{}, fmt.Errorf("invalid collected_at: %w", err)
+	}
+
+	if fields.metricType == "" {
+		return port.RawMetric{}, errors.New("type is required")
+	}
+	metricType := valueobject.MetricType(fields.metricType)
+
+	if fields.value == "" {
+		return port.RawMetric{}, errors.New("value is required")
+	}
+	number, err := strconv.ParseFloat(fields.value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return port.RawMetric{}, fmt.Errorf("invalid value %q", fields.value)
+	}
+
+	unit := fields.unit
+	if unit == "" {
+		// Без единицы берется основная единица типа
+		definition, ok := metricType.Definition()
+		if !ok {
+			return port.RawMetric{}, fmt.Errorf("unknown metric type %q", fields.metricType)
+		}
+		unit = definition.Units[0]
+	}
+	value, err := valueobject.NewMetricValue(number, unit)
+	if err != nil {
+		return port.RawMetric{}, err
+	}
+
+	name := fields.name
+	if name == "" {
+		name = fields.metricType
+	}
+
+	return port.RawMetric{
+		Type:        metricType,
+		Name:        name,
+		Value:       value,
+		Labels:      fields.labels,
+		Metadata:    fields.metadata,
+		Host:        fields.host,
+		CollectedAt: collectedAt,
+	}, nil
+}
+
+// parseLabels разбирает колонку labels: JSON-объект строк (пусто и {} - без меток)
+func parseLabels(raw string) (map[string]string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(raw), &labels); err != nil {
+		return nil, errors.New("labels must be a JSON object with string values")
+	}
+	return labels, nil
+}
@@ -0,0 +1,146 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/protowire"
+)
+
+// maxDecodedSize ограничивает размер распакованного запроса (защита от gzip-бомб)
+const maxDecodedSize = 32 * 1024 * 1024
+
+// ErrInvalidPayload тело запроса не является ExportMetricsServiceRequest
+var ErrInvalidPayload = errors.New("invalid OTLP payload")
+
+// ErrUnsupportedEncoding неподдерживаемый Content-Encoding
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// Format кодировка OTLP/HTTP
+type Format int
+
+const (
+	FormatProtobuf Format = iota
+	FormatJSON
+)
+
+// ParseContentType определяет кодировку по заголовку Content-Type
+func ParseContentType(contentType string) (Format, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return FormatProtobuf, false
+	}
+	switch mediaType {
+	case "application/x-protobuf", "application/protobuf":
+		return FormatProtobuf, true
+	case "application/json":
+		return FormatJSON, true
+	}
+	return FormatProtobuf, false
+}
+
+// ContentType значение заголовка Content-Type ответа
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "application/x-protobuf"
+}
+
+// ReadBody читает тело запроса, распаковывая gzip (OTLP-экспортеры сжимают по умолчанию)
+func ReadBody(body io.Reader, contentEncoding string) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", "identity":
+	case "gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+		}
+		defer reader.Close()
+		body = reader
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, contentEncoding)
+	}
+
+	// Ошибка чтения сохраняет исходную причину (например, *http.MaxBytesError)
+	data, err := io.ReadAll(io.LimitReader(body, maxDecodedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+	if len(data) > maxDecodedSize {
+		return nil, fmt.Errorf("%w: decoded size exceeds %d bytes", ErrInvalidPayload, maxDecodedSize)
+	}
+	return data, nil
+}
+
+// DecodeRequest разбирает ExportMetricsServiceRequest в кодировке format
+func DecodeRequest(data []byte, format Format) (*ExportRequest, error) {
+	var req ExportRequest
+	var err error
+	if format == FormatJSON {
+		err = json.Unmarshal(data, &req)
+	} else {
+		err = req.UnmarshalProto(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return &req, nil
+}
+
+// EncodeResponse кодирует ExportMetricsServiceResponse
+// При rejected > 0 заполняется partial_success, иначе ответ пустой
+func EncodeResponse(format Format, rejected int64, message string) []byte {
+	if format == FormatJSON {
+		if rejected == 0 {
+			return []byte("{}")
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"partialSuccess": map[string]interface{}{
+				"rejectedDataPoints": fmt.Sprint(rejected),
+				"errorMessage":       message,
+			},
+		})
+		return data
+	}
+
+	if rejected == 0 {
+		return []byte{}
+	}
+	partial := protowire.AppendVarint(nil, 1, uint64(rejected))
+	partial = protowire.AppendBytes(partial, 2, []byte(message))
+	return protowire.AppendBytes(nil, 1, partial)
+}
+
+// EncodeStatus кодирует google.rpc.Status - тело ответа с ошибкой по спецификации OTLP/HTTP
+// Код статуса - код gRPC, соответствующий HTTP-статусу httpStatus
+func EncodeStatus(format Format, httpStatus int, message string) []byte {
+	code := grpcCode(httpStatus)
+	if format == FormatJSON {
+		data, _ := json.Marshal(map[string]interface{}{"code": code, "message": message})
+		return data
+	}
+	status := protowire.AppendVarint(nil, 1, uint64(code))
+	return protowire.AppendBytes(status, 2, []byte(message))
+}
+
+// grpcCode код google.rpc.Code для HTTP-статуса ответа
+func grpcCode(httpStatus int) int {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return 3 // INVALID_ARGUMENT
+	case http.StatusUnauthorized:
+		return 16 // UNAUTHENTICATED
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return 8 // RESOURCE_EXHAUSTED
+	case http.StatusServiceUnavailable:
+		return 14 // UNAVAILABLE
+	}
+	return 13 // INTERNAL
+}
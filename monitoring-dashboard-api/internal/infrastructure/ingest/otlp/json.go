@@ -0,0 +1,285 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Представление OTLP/JSON: поля в lowerCamelCase, 64-битные целые - строки или числа,
+// enum - числа (допускаются и имена), неизвестные поля игнорируются
+
+type jsonRequest struct {
+	ResourceMetrics []jsonResourceMetrics `json:"resourceMetrics"`
+}
+
+type jsonResourceMetrics struct {
+	Resource struct {
+		Attributes []jsonKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeMetrics []jsonScopeMetrics `json:"scopeMetrics"`
+}
+
+type jsonScopeMetrics struct {
+	Metrics []jsonMetric `json:"metrics"`
+}
+
+type jsonMetric struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Unit        string `json:"unit"`
+	Gauge       *struct {
+		DataPoints []jsonNumberDataPoint `json:"dataPoints"`
+	} `json:"gauge"`
+	Sum *struct {
+		DataPoints             []jsonNumberDataPoint `json:"dataPoints"`
+		AggregationTemporality jsonTemporality       `json:"aggregationTemporality"`
+		IsMonotonic            bool                  `json:"isMonotonic"`
+	} `json:"sum"`
+	Histogram *struct {
+		DataPoints             []jsonHistogramDataPoint `json:"dataPoints"`
+		AggregationTemporality jsonTemporality          `json:"aggregationTemporality"`
+	} `json:"histogram"`
+	ExponentialHistogram *jsonUnsupported `json:"exponentialHistogram"`
+	Summary              *jsonUnsupported `json:"summary"`
+}
+
+type jsonUnsupported struct {
+	DataPoints []json.RawMessage `json:"dataPoints"`
+}
+
+type jsonNumberDataPoint struct {
+	Attributes   []jsonKeyValue `json:"attributes"`
+	TimeUnixNano jsonUint64     `json:"timeUnixNano"`
+	AsDouble     *jsonFloat     `json:"asDouble"`
+	AsInt        *jsonInt64     `json:"asInt"`
+	Flags        uint32         `json:"flags"`
+}
+
+type jsonHistogramDataPoint struct {
+	Attributes     []jsonKeyValue `json:"attributes"`
+	TimeUnixNano   jsonUint64     `json:"timeUnixNano"`
+	Count          jsonUint64     `json:"count"`
+	Sum            *jsonFloat     `json:"sum"`
+	BucketCounts   []jsonUint64   `json:"bucketCounts"`
+	ExplicitBounds []jsonFloat    `json:"explicitBounds"`
+	Flags          uint32         `json:"flags"`
+}
+
+type jsonKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string    `json:"stringValue"`
+		BoolValue   *bool      `json:"boolValue"`
+		IntValue    *jsonInt64 `json:"intValue"`
+		DoubleValue *jsonFloat `json:"doubleValue"`
+	} `json:"value"`
+}
+
+// UnmarshalJSON разбирает ExportMetricsServiceRequest в кодировке OTLP/JSON
+func (r *ExportRequest) UnmarshalJSON(data []byte) error {
+	var req jsonRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return err
+	}
+
+	r.ResourceMetrics = make([]ResourceMetrics, 0, len(req.ResourceMetrics))
+	for _, jrm := range req.ResourceMetrics {
+		rm := ResourceMetrics{Resource: convertAttributes(jrm.Resource.Attributes)}
+		for _, jsm := range jrm.ScopeMetrics {
+			sm := ScopeMetrics{Metrics: make([]Metric, 0, len(jsm.Metrics))}
+			for _, jm := range jsm.Metrics {
+				sm.Metrics = append(sm.Metrics, jm.convert())
+			}
+			rm.ScopeMetrics = append(rm.ScopeMetrics, sm)
+		}
+		r.ResourceMetrics = append(r.ResourceMetrics, rm)
+	}
+	return nil
+}
+
+func (jm jsonMetric) convert() Metric {
+	metric := Metric{Name: jm.Name, Description: jm.Description, Unit: jm.Unit}
+
+	switch {
+	case jm.Gauge != nil:
+		metric.Gauge = &Gauge{DataPoints: convertNumberDataPoints(jm.Gauge.DataPoints)}
+	case jm.Sum != nil:
+		metric.Sum = &Sum{
+			DataPoints:  convertNumberDataPoints(jm.Sum.DataPoints),
+			Temporality: AggregationTemporality(jm.Sum.AggregationTemporality),
+			IsMonotonic: jm.Sum.IsMonotonic,
+		}
+	case jm.Histogram != nil:
+		metric.Histogram = &Histogram{Temporality: AggregationTemporality(jm.Histogram.AggregationTemporality)}
+		for _, jp := range jm.Histogram.DataPoints {
+			point := HistogramDataPoint{
+				Attributes:   convertAttributes(jp.Attributes),
+				TimeUnixNano: uint64(jp.TimeUnixNano),
+				Count:        uint64(jp.Count),
+				Flags:        jp.Flags,
+			}
+			if jp.Sum != nil {
+				point.Sum, point.HasSum = float64(*jp.Sum), true
+			}
+			for _, count := range jp.BucketCounts {
+				point.BucketCounts = append(point.BucketCounts, uint64(count))
+			}
+			for _, bound := range jp.ExplicitBounds {
+				point.ExplicitBounds = append(point.ExplicitBounds, float64(bound))
+			}
+			metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, point)
+		}
+	case jm.ExponentialHistogram != nil:
+		metric.UnsupportedPoints = len(jm.ExponentialHistogram.DataPoints)
+	case jm.Summary != nil:
+		metric.UnsupportedPoints = len(jm.Summary.DataPoints)
+	}
+
+	return metric
+}
+
+func convertNumberDataPoints(jsonPoints []jsonNumberDataPoint) []NumberDataPoint {
+	points := make([]NumberDataPoint, 0, len(jsonPoints))
+	for _, jp := range jsonPoints {
+		point := NumberDataPoint{
+			Attributes:   convertAttributes(jp.Attributes),
+			TimeUnixNano: uint64(jp.TimeUnixNano),
+			Flags:        jp.Flags,
+		}
+		switch {
+		case jp.AsDouble != nil:
+			point.Value = float64(*jp.AsDouble)
+		case jp.AsInt != nil:
+			point.Value = float64(*jp.AsInt)
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+func convertAttributes(jsonAttributes []jsonKeyValue) []KeyValue {
+	attributes := make([]KeyValue, 0, len(jsonAttributes))
+	for _, attr := range jsonAttributes {
+		var value string
+		switch v := attr.Value; {
+		case v.StringValue != nil:
+			value = *v.StringValue
+		case v.BoolValue != nil:
+			value = strconv.FormatBool(*v.BoolValue)
+		case v.IntValue != nil:
+			value = strconv.FormatInt(int64(*v.IntValue), 10)
+		case v.DoubleValue != nil:
+			value = strconv.FormatFloat(float64(*v.DoubleValue), 'g', -1, 64)
+		default:
+			continue
+		}
+		if attr.Key != "" {
+			attributes = append(attributes, KeyValue{Key: attr.Key, Value: value})
+		}
+	}
+	return attributes
+}
+
+// unquote снимает кавычки со строкового JSON-значения; числа возвращаются как есть
+func unquote(data []byte) (string, error) {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	return string(data), nil
+}
+
+// jsonUint64 fixed64/uint64: строка или число
+type jsonUint64 uint64
+
+func (v *jsonUint64) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid uint64 %q", s)
+	}
+	*v = jsonUint64(parsed)
+	return nil
+}
+
+// jsonInt64 int64/sfixed64: строка или число
+type jsonInt64 int64
+
+func (v *jsonInt64) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid int64 %q", s)
+	}
+	*v = jsonInt64(parsed)
+	return nil
+}
+
+// jsonFloat double: число или строка ("NaN", "Infinity", "-Infinity")
+type jsonFloat float64
+
+func (v *jsonFloat) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	switch s {
+	case "NaN":
+		*v = jsonFloat(math.NaN())
+	case "Infinity":
+		*v = jsonFloat(math.Inf(1))
+	case "-Infinity":
+		*v = jsonFloat(math.Inf(-1))
+	default:
+		parsed, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid double %q", s)
+		}
+		*v = jsonFloat(parsed)
+	}
+	return nil
+}
+
+// jsonTemporality enum AggregationTemporality: число или имя значения
+type jsonTemporality AggregationTemporality
+
+func (v *jsonTemporality) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s, err := unquote(data)
+	if err != nil {
+		return err
+	}
+	switch strings.TrimPrefix(s, "AGGREGATION_TEMPORALITY_") {
+	case "DELTA", "1":
+		*v = jsonTemporality(TemporalityDelta)
+	case "CUMULATIVE", "2":
+		*v = jsonTemporality(TemporalityCumulative)
+	default:
+		*v = jsonTemporality(TemporalityUnspecified)
+	}
+	return nil
+}
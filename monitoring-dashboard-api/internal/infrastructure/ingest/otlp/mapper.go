@@ -0,0 +1,366 @@
+package otlp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// Атрибуты, управляющие сопоставлением; в метки метрики они не попадают
+const (
+	typeAttribute     = "type"
+	hostNameAttribute = "host.name"
+)
+
+// AllResourceAttributes в MapperConfig.ResourceAttributes переносит в метки все атрибуты ресурса
+const AllResourceAttributes = "*"
+
+// semconvPrefixes сопоставляет префиксы имен семантических соглашений OpenTelemetry
+// (после замены точек на "_") со встроенными типами
+var semconvPrefixes = []struct {
+	prefix     string
+	metricType valueobject.MetricType
+}{
+	{"system_cpu_", valueobject.CPU},
+	{"process_cpu_", valueobject.CPU},
+	{"container_cpu_", valueobject.CPU},
+	{"system_memory_", valueobject.Memory},
+	{"process_memory_", valueobject.Memory},
+	{"container_memory_", valueobject.Memory},
+	{"system_filesystem_", valueobject.Disk},
+	{"system_disk_", valueobject.Disk},
+	{"system_network_", valueobject.Network},
+}
+
+// ucumUnits единицы UCUM, которыми пользуется OpenTelemetry, и соответствующие единицы проекта
+var ucumUnits = map[string]string{
+	"%":      "%",
+	"By":     "bytes",
+	"MBy":    "MB",
+	"MiBy":   "MB",
+	"GBy":    "GB",
+	"GiBy":   "GB",
+	"TBy":    "TB",
+	"TiBy":   "TB",
+	"By/s":   "bytes/s",
+	"KBy/s":  "KB/s",
+	"KiBy/s": "KB/s",
+	"MBy/s":  "MB/s",
+	"MiBy/s": "MB/s",
+	"GBy/s":  "GB/s",
+	"GiBy/s": "GB/s",
+}
+
+// MapperConfig настройки сопоставления метрик OTLP с метриками проекта
+type MapperConfig struct {
+	// DefaultType тип метрик, для которых тип не удалось определить (пустой - такие метрики отклоняются)
+	DefaultType valueobject.MetricType
+
+	// ResourceAttributes атрибуты ресурса, переносимые в метки ("*" - все)
+	ResourceAttributes []string
+}
+
+// Mapper сопоставляет точки OTLP с сырыми метриками
+//
+// Имя - имя метрики OTLP с точками, замененными на "_"; монотонные cumulative sum
+// получают суффикс _total, гистограммы раскладываются на _bucket{le}, _sum и _count.
+// Хост - атрибут host.name (точки или ресурса). Метки - атрибуты ресурса из
+// ResourceAttributes и атрибуты точки, имена приводятся к [a-zA-Z0-9_].
+// Тип - атрибут type, префикс имени (cpu_*, system.memory.* ...) или DefaultType.
+// Единица - unit метрики (UCUM), если тип ее допускает, иначе первая единица типа;
+// доли с unit "1" переводятся в проценты, если тип допускает "%"
+type Mapper struct {
+	config             MapperConfig
+	allResourceLabels  bool
+	resourceAttributes map[string]bool
+}
+
+// NewMapper создает новый Mapper
+func NewMapper(config MapperConfig) *Mapper {
+	m := &Mapper{config: config, resourceAttributes: make(map[string]bool, len(config.ResourceAttributes))}
+	for _, name := range config.ResourceAttributes {
+		if name == AllResourceAttributes {
+			m.allResourceLabels = true
+		}
+		m.resourceAttributes[name] = true
+	}
+	return m
+}
+
+// pointContext метки и хост, общие для всех сэмплов точки
+type pointContext struct {
+	host     string
+	labels   map[string]string
+	explicit string
+}
+
+// ToRawMetrics конвертирует точки всех метрик запроса в port.RawMetric
+// Возвращает количество отклоненных точек и сэмплов (без типа, NaN/Inf, неподдерживаемые виды)
+func (m *Mapper) ToRawMetrics(req *ExportRequest) ([]port.RawMetric, int) {
+	var rawMetrics []port.RawMetric
+	rejected := 0
+
+	for _, rm := range req.ResourceMetrics {
+		resourceHost, resourceLabels := m.resourceLabels(rm.Resource)
+
+		for _, sm := range rm.ScopeMetrics {
+			for _, metric := range sm.Metrics {
+				rejected += metric.UnsupportedPoints
+				name := sanitizeName(metric.Name)
+
+				switch {
+				case metric.Gauge != nil:
+					for _, point := range metric.Gauge.DataPoints {
+						pc := newPointContext(resourceHost, resourceLabels, point.Attributes)
+						raw, ok := m.numberPoint(name, metric.Unit, pc, point)
+						rawMetrics, rejected = collect(rawMetrics, rejected, raw, ok)
+					}
+				case metric.Sum != nil:
+					sumName := name
+					if metric.Sum.IsMonotonic && metric.Sum.Temporality == TemporalityCumulative && !strings.HasSuffix(sumName, "_total") {
+						sumName += "_total"
+					}
+					for _, point := range metric.Sum.DataPoints {
+						pc := newPointContext(resourceHost, resourceLabels, point.Attributes)
+						raw, ok := m.numberPoint(sumName, metric.Unit, pc, point)
+						rawMetrics, rejected = collect(rawMetrics, rejected, raw, ok)
+					}
+				case metric.Histogram != nil:
+					for _, point := range metric.Histogram.DataPoints {
+						pc := newPointContext(resourceHost, resourceLabels, point.Attributes)
+						samples, pointRejected := m.histogramPoint(name, metric.Unit, pc, point)
+						rawMetrics = append(rawMetrics, samples...)
+						rejected += pointRejected
+					}
+				}
+			}
+		}
+	}
+
+	return rawMetrics, rejected
+}
+
+func collect(rawMetrics []port.RawMetric, rejected int, raw *port.RawMetric, ok bool) ([]port.RawMetric, int) {
+	switch {
+	case raw != nil:
+		return append(rawMetrics, *raw), rejected
+	case !ok:
+		return rawMetrics, rejected + 1
+	}
+	return rawMetrics, rejected
+}
+
+// numberPoint конвертирует точку gauge или sum
+// (nil, true) - точка без значения, пропускается без отклонения
+func (m *Mapper) numberPoint(name, otlpUnit string, pc pointContext, point NumberDataPoint) (*port.RawMetric, bool) {
+	if point.Flags&flagNoRecordedValue != 0 {
+		return nil, true
+	}
+
+	definition, ok := m.resolveType(name, pc.explicit)
+	if !ok || name == "" {
+		return nil, false
+	}
+	unit, scale := resolveUnit(definition, otlpUnit)
+
+	raw, ok := newRawMetric(definition.Type, name, unit, point.Value*scale, pc.host, pc.labels, point.TimeUnixNano)
+	return raw, ok
+}
+
+// histogramPoint раскладывает точку гистограммы на кумулятивные _bucket{le}, _sum и _count
+func (m *Mapper) histogramPoint(name, otlpUnit string, pc pointContext, point HistogramDataPoint) ([]port.RawMetric, int) {
+	if point.Flags&flagNoRecordedValue != 0 {
+		return nil, 0
+	}
+
+	definition, ok := m.resolveType(name, pc.explicit)
+	if !ok || name == "" {
+		return nil, 1
+	}
+	if len(point.BucketCounts) != 0 && len(point.BucketCounts) != len(point.ExplicitBounds)+1 {
+		return nil, 1
+	}
+
+	// Счетчики бакетов безразмерны: им достается первая единица типа
+	countUnit := definition.Units[0]
+	sumUnit, scale := resolveUnit(definition, otlpUnit)
+
+	var samples []port.RawMetric
+	rejected := 0
+	add := func(sampleName, unit string, value float64, labels map[string]string) {
+		raw, ok := newRawMetric(definition.Type, sampleName, unit, value, pc.host, labels, point.TimeUnixNano)
+		if !ok {
+			rejected++
+			return
+		}
+		samples = append(samples, *raw)
+	}
+
+	if len(point.BucketCounts) != 0 {
+		var cumulative uint64
+		for i, bound := range point.ExplicitBounds {
+			cumulative += point.BucketCounts[i]
+			add(name+"_bucket", countUnit, float64(cumulative), withLabel(pc.labels, "le", strconv.FormatFloat(bound, 'g', -1, 64)))
+		}
+		add(name+"_bucket", countUnit, float64(point.Count), withLabel(pc.labels, "le", "+Inf"))
+	}
+	if point.HasSum {
+		add(name+"_sum", sumUnit, point.Sum*scale, pc.labels)
+	}
+	add(name+"_count", countUnit, float64(point.Count), pc.labels)
+
+	return samples, rejected
+}
+
+func newRawMetric(
+	metricType valueobject.MetricType,
+	name, unit string,
+	value float64,
+	host string,
+	labels map[string]string,
+	timeUnixNano uint64,
+) (*port.RawMetric, bool) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, false
+	}
+	metricValue, err := valueobject.NewMetricValue(value, unit)
+	if err != nil {
+		return nil, false
+	}
+
+	var collectedAt time.Time
+	if timeUnixNano != 0 {
+		collectedAt = time.Unix(0, int64(timeUnixNano))
+	}
+
+	return &port.RawMetric{
+		Type:        metricType,
+		Name:        name,
+		Value:       metricValue,
+		Host:        host,
+		Labels:      labels,
+		CollectedAt: collectedAt,
+	}, true
+}
+
+// resourceLabels выделяет хост и разрешенные атрибуты ресурса
+func (m *Mapper) resourceLabels(attributes []KeyValue) (string, map[string]string) {
+	var host string
+	labels := make(map[string]string)
+	for _, attr := range attributes {
+		if attr.Key == hostNameAttribute {
+			host = attr.Value
+			continue
+		}
+		if !m.allResourceLabels && !m.resourceAttributes[attr.Key] {
+			continue
+		}
+		if name, ok := sanitizeLabelName(attr.Key); ok {
+			labels[name] = attr.Value
+		}
+	}
+	return host, labels
+}
+
+// newPointContext объединяет метки ресурса с атрибутами точки (атрибуты точки приоритетнее)
+func newPointContext(host string, resourceLabels map[string]string, attributes []KeyValue) pointContext {
+	pc := pointContext{host: host, labels: make(map[string]string, len(resourceLabels)+len(attributes))}
+	for name, value := range resourceLabels {
+		pc.labels[name] = value
+	}
+	for _, attr := range attributes {
+		switch attr.Key {
+		case valueobject.HostLabel, hostNameAttribute:
+			pc.host = attr.Value
+		case typeAttribute:
+			pc.explicit = attr.Value
+		default:
+			if name, ok := sanitizeLabelName(attr.Key); ok {
+				pc.labels[name] = attr.Value
+			}
+		}
+	}
+	return pc
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	copied := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		copied[k] = v
+	}
+	copied[name] = value
+	return copied
+}
+
+// resolveType определяет тип по атрибуту type, префиксу имени или типу по умолчанию
+func (m *Mapper) resolveType(name, explicit string) (valueobject.MetricTypeDefinition, bool) {
+	if explicit != "" {
+		return valueobject.MetricType(explicit).Definition()
+	}
+
+	registry := valueobject.DefaultMetricTypeRegistry()
+	if definition, ok := registry.ResolveByPrefix(name); ok {
+		return definition, true
+	}
+	for _, rule := range semconvPrefixes {
+		if strings.HasPrefix(name, rule.prefix) {
+			return registry.Lookup(rule.metricType)
+		}
+	}
+
+	if m.config.DefaultType != "" {
+		return registry.Lookup(m.config.DefaultType)
+	}
+	return valueobject.MetricTypeDefinition{}, false
+}
+
+// resolveUnit выбирает единицу, допустимую для типа, и множитель значения
+func resolveUnit(definition valueobject.MetricTypeDefinition, otlpUnit string) (string, float64) {
+	if otlpUnit == "1" && definition.AllowsUnit("%") {
+		return "%", 100
+	}
+	if unit, ok := ucumUnits[otlpUnit]; ok && definition.AllowsUnit(unit) {
+		return unit, 1
+	}
+	if definition.AllowsUnit(otlpUnit) {
+		return otlpUnit, 1
+	}
+	return definition.Units[0], 1
+}
+
+// sanitizeName приводит имя OTLP к виду [a-zA-Z_:][a-zA-Z0-9_:]*
+func sanitizeName(name string) string {
+	return sanitize(name, true)
+}
+
+// sanitizeLabelName приводит имя атрибута к имени метки; зарезервированные имена отбрасываются
+func sanitizeLabelName(key string) (string, bool) {
+	name := sanitize(key, false)
+	if name == "" || name == valueobject.HostLabel || strings.HasPrefix(name, "__") {
+		return "", false
+	}
+	return name, true
+}
+
+func sanitize(name string, allowColon bool) string {
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9' && i > 0) || (allowColon && r == ':')
+		switch {
+		case valid:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteByte('_')
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Package otlp приемник метрик OpenTelemetry (OTLP/HTTP, protobuf и JSON)
+package otlp
+
+// AggregationTemporality способ накопления значений sum и histogram
+type AggregationTemporality int
+
+const (
+	TemporalityUnspecified AggregationTemporality = 0
+	TemporalityDelta       AggregationTemporality = 1
+	TemporalityCumulative  AggregationTemporality = 2
+)
+
+// flagNoRecordedValue флаг точки без значения (аналог stale-маркера Prometheus)
+const flagNoRecordedValue = 1
+
+// ExportRequest тело ExportMetricsServiceRequest
+// Разбираются gauge, sum и histogram; exponential histogram и summary только подсчитываются
+type ExportRequest struct {
+	ResourceMetrics []ResourceMetrics
+}
+
+// ResourceMetrics метрики одного ресурса (сервиса, хоста, пода)
+type ResourceMetrics struct {
+	Resource     []KeyValue
+	ScopeMetrics []ScopeMetrics
+}
+
+// ScopeMetrics метрики одного instrumentation scope
+type ScopeMetrics struct {
+	Metrics []Metric
+}
+
+// Metric метрика OTLP; заполнен ровно один из Gauge, Sum, Histogram
+type Metric struct {
+	Name        string
+	Description string
+	Unit        string
+
+	Gauge     *Gauge
+	Sum       *Sum
+	Histogram *Histogram
+
+	// UnsupportedPoints количество точек exponential histogram и summary (отклоняются)
+	UnsupportedPoints int
+}
+
+// Gauge мгновенные значения
+type Gauge struct {
+	DataPoints []NumberDataPoint
+}
+
+// Sum накопленные (или за интервал) суммы
+type Sum struct {
+	DataPoints  []NumberDataPoint
+	Temporality AggregationTemporality
+	IsMonotonic bool
+}
+
+// Histogram гистограммы с явными границами бакетов
+type Histogram struct {
+	DataPoints  []HistogramDataPoint
+	Temporality AggregationTemporality
+}
+
+// KeyValue атрибут; значения-скаляры приводятся к строке, массивы и словари пропускаются
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// NumberDataPoint точка gauge или sum; as_int приводится к float64
+type NumberDataPoint struct {
+	Attributes   []KeyValue
+	TimeUnixNano uint64
+	Value        float64
+	Flags        uint32
+}
+
+// HistogramDataPoint точка гистограммы; BucketCounts не кумулятивны,
+// len(BucketCounts) == len(ExplicitBounds)+1
+type HistogramDataPoint struct {
+	Attributes     []KeyValue
+	TimeUnixNano   uint64
+	Count          uint64
+	Sum            float64
+	HasSum         bool
+	BucketCounts   []uint64
+	ExplicitBounds []float64
+	Flags          uint32
+}
@@ -0,0 +1,289 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/protowire"
+)
+
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func stringAttribute(key, value string) []byte {
+	anyValue := protowire.AppendBytes(nil, 1, []byte(value))
+	kv := protowire.AppendBytes(nil, 1, []byte(key))
+	return protowire.AppendBytes(kv, 2, anyValue)
+}
+
+// protoRequest собирает ExportMetricsServiceRequest: ресурс host.name=web-1, service.name=api
+// и метрики gauge system.memory.usage (int), sum http.requests (cumulative monotonic) и гистограмму
+func protoRequest() []byte {
+	ts := uint64(testTime.UnixNano())
+
+	gaugePoint := protowire.AppendBytes(nil, 7, stringAttribute("state", "used"))
+	gaugePoint = protowire.AppendFixed64(gaugePoint, 3, ts)
+	gaugePoint = protowire.AppendFixed64(gaugePoint, 6, 2048)
+	gauge := protowire.AppendBytes(nil, 1, gaugePoint)
+	gaugeMetric := protowire.AppendBytes(nil, metricFieldName, []byte("system.memory.usage"))
+	gaugeMetric = protowire.AppendBytes(gaugeMetric, metricFieldUnit, []byte("By"))
+	gaugeMetric = protowire.AppendBytes(gaugeMetric, metricFieldGauge, gauge)
+
+	sumPoint := protowire.AppendFixed64(nil, 3, ts)
+	sumPoint = protowire.AppendDouble(sumPoint, 4, 7)
+	sum := protowire.AppendBytes(nil, 1, sumPoint)
+	sum = protowire.AppendVarint(sum, 2, uint64(TemporalityCumulative))
+	sum = protowire.AppendVarint(sum, 3, 1)
+	sumMetric := protowire.AppendBytes(nil, metricFieldName, []byte("cpu.requests"))
+	sumMetric = protowire.AppendBytes(sumMetric, metricFieldSum, sum)
+
+	// bucket_counts и explicit_bounds - packed repeated
+	var counts, bounds []byte
+	for _, count := range []uint64{1, 2, 3} {
+		counts = binary.LittleEndian.AppendUint64(counts, count)
+	}
+	for _, bound := range []float64{0.1, 1} {
+		bounds = binary.LittleEndian.AppendUint64(bounds, math.Float64bits(bound))
+	}
+	histogramPoint := protowire.AppendFixed64(nil, 3, ts)
+	histogramPoint = protowire.AppendFixed64(histogramPoint, 4, 6)
+	histogramPoint = protowire.AppendDouble(histogramPoint, 5, 4.5)
+	histogramPoint = protowire.AppendBytes(histogramPoint, 6, counts)
+	histogramPoint = protowire.AppendBytes(histogramPoint, 7, bounds)
+	histogram := protowire.AppendBytes(nil, 1, histogramPoint)
+	histogram = protowire.AppendVarint(histogram, 2, uint64(TemporalityCumulative))
+	histogramMetric := protowire.AppendBytes(nil, metricFieldName, []byte("cpu.latency"))
+	histogramMetric = protowire.AppendBytes(histogramMetric, metricFieldHistogram, histogram)
+
+	summaryMetric := protowire.AppendBytes(nil, metricFieldName, []byte("cpu.summary"))
+	summaryMetric = protowire.AppendBytes(summaryMetric, metricFieldSummary, protowire.AppendBytes(nil, 1, nil))
+
+	scope := protowire.AppendBytes(nil, 1, protowire.AppendBytes(nil, 1, []byte("test-scope")))
+	for _, metric := range [][]byte{gaugeMetric, sumMetric, histogramMetric, summaryMetric} {
+		scope = protowire.AppendBytes(scope, 2, metric)
+	}
+
+	resource := protowire.AppendBytes(nil, 1, stringAttribute("host.name", "web-1"))
+	resource = protowire.AppendBytes(resource, 1, stringAttribute("service.name", "api"))
+	resource = protowire.AppendBytes(resource, 1, stringAttribute("telemetry.sdk.name", "opentelemetry"))
+
+	rm := protowire.AppendBytes(nil, 1, resource)
+	rm = protowire.AppendBytes(rm, 2, scope)
+	return protowire.AppendBytes(nil, 1, rm)
+}
+
+const jsonRequestBody = `{"resourceMetrics":[{
+	"resource":{"attributes":[
+		{"key":"host.name","value":{"stringValue":"web-1"}},
+		{"key":"service.name","value":{"stringValue":"api"}},
+		{"key":"telemetry.sdk.name","value":{"stringValue":"opentelemetry"}}
+	]},
+	"scopeMetrics":[{"scope":{"name":"test-scope"},"metrics":[
+		{"name":"system.memory.usage","unit":"By","gauge":{"dataPoints":[
+			{"attributes":[{"key":"state","value":{"stringValue":"used"}}],"timeUnixNano":"1767323045000000000","asInt":"2048"}
+		]}},
+		{"name":"cpu.requests","sum":{"aggregationTemporality":2,"isMonotonic":true,"dataPoints":[
+			{"timeUnixNano":"1767323045000000000","asDouble":7}
+		]}},
+		{"name":"cpu.latency","histogram":{"aggregationTemporality":"AGGREGATION_TEMPORALITY_CUMULATIVE","dataPoints":[
+			{"timeUnixNano":"1767323045000000000","count":"6","sum":4.5,"bucketCounts":["1","2",3],"explicitBounds":[0.1,1]}
+		]}},
+		{"name":"cpu.summary","summary":{"dataPoints":[{}]}}
+	]}]
+}]}`
+
+func TestDecodeProtobufAndJSONAreEquivalent(t *testing.T) {
+	fromProto, err := DecodeRequest(protoRequest(), FormatProtobuf)
+	if err != nil {
+		t.Fatalf("DecodeRequest(protobuf) error = %v", err)
+	}
+	fromJSON, err := DecodeRequest([]byte(jsonRequestBody), FormatJSON)
+	if err != nil {
+		t.Fatalf("DecodeRequest(json) error = %v", err)
+	}
+
+	for format, req := range map[string]*ExportRequest{"protobuf": fromProto, "json": fromJSON} {
+		if len(req.ResourceMetrics) != 1 || len(req.ResourceMetrics[0].Resource) != 3 {
+			t.Fatalf("%s: unexpected resource: %+v", format, req.ResourceMetrics)
+		}
+		metrics := req.ResourceMetrics[0].ScopeMetrics[0].Metrics
+		if len(metrics) != 4 {
+			t.Fatalf("%s: expected 4 metrics, got %d", format, len(metrics))
+		}
+		gauge := metrics[0].Gauge
+		if metrics[0].Unit != "By" || gauge == nil || len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value != 2048 ||
+			gauge.DataPoints[0].Attributes[0] != (KeyValue{"state", "used"}) {
+			t.Fatalf("%s: unexpected gauge: %+v", format, metrics[0])
+		}
+		if sum := metrics[1].Sum; sum == nil || !sum.IsMonotonic || sum.Temporality != TemporalityCumulative || sum.DataPoints[0].Value != 7 {
+			t.Fatalf("%s: unexpected sum: %+v", format, metrics[1])
+		}
+		histogram := metrics[2].Histogram
+		if histogram == nil || histogram.Temporality != TemporalityCumulative || len(histogram.DataPoints) != 1 {
+			t.Fatalf("%s: unexpected histogram: %+v", format, metrics[2])
+		}
+		point := histogram.DataPoints[0]
+		if point.Count != 6 || !point.HasSum || point.Sum != 4.5 || len(point.BucketCounts) != 3 || point.BucketCounts[2] != 3 ||
+			len(point.ExplicitBounds) != 2 || point.ExplicitBounds[0] != 0.1 || point.TimeUnixNano != uint64(testTime.UnixNano()) {
+			t.Fatalf("%s: unexpected histogram point: %+v", format, point)
+		}
+		if metrics[3].UnsupportedPoints != 1 {
+			t.Fatalf("%s: summary points must be counted as unsupported: %+v", format, metrics[3])
+		}
+	}
+}
+
+func TestDecodeRejectsInvalidPayloads(t *testing.T) {
+	if _, err := DecodeRequest([]byte{0x0a, 0x05, 0x01}, FormatProtobuf); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("truncated protobuf: expected ErrInvalidPayload, got %v", err)
+	}
+	if _, err := DecodeRequest([]byte(`{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"gauge":{"dataPoints":[{"asInt":"x"}]}}]}]}]}`), FormatJSON); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("invalid asInt: expected ErrInvalidPayload, got %v", err)
+	}
+}
+
+func TestReadBodyDecompressesGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(jsonRequestBody))
+	_ = writer.Close()
+
+	data, err := ReadBody(&compressed, "gzip")
+	if err != nil || string(data) != jsonRequestBody {
+		t.Fatalf("ReadBody(gzip) = %q, %v", data, err)
+	}
+	if _, err := ReadBody(bytes.NewBufferString("plain"), "gzip"); !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected ErrInvalidPayload for non-gzip body, got %v", err)
+	}
+	if _, err := ReadBody(bytes.NewBufferString("plain"), "br"); !errors.Is(err, ErrUnsupportedEncoding) {
+		t.Fatalf("expected ErrUnsupportedEncoding, got %v", err)
+	}
+}
+
+func TestMapperConvertsGaugeSumAndHistogram(t *testing.T) {
+	req, err := DecodeRequest(protoRequest(), FormatProtobuf)
+	if err != nil {
+		t.Fatalf("DecodeRequest() error = %v", err)
+	}
+
+	rawMetrics, rejected := NewMapper(MapperConfig{ResourceAttributes: []string{"service.name"}}).ToRawMetrics(req)
+	if rejected != 1 {
+		t.Fatalf("expected the summary point to be rejected, got %d", rejected)
+	}
+
+	byName := make(map[string][]port.RawMetric)
+	for _, raw := range rawMetrics {
+		if raw.Host != "web-1" || raw.Labels["service_name"] != "api" || raw.Labels["telemetry_sdk_name"] != "" {
+			t.Fatalf("unexpected host or resource labels: %+v", raw)
+		}
+		if !raw.CollectedAt.Equal(testTime) {
+			t.Fatalf("collected_at = %v, want %v", raw.CollectedAt, testTime)
+		}
+		byName[raw.Name] = append(byName[raw.Name], raw)
+	}
+
+	gauge := byName["system_memory_usage"]
+	if len(gauge) != 1 || gauge[0].Type != valueobject.Memory || gauge[0].Value.Unit() != "bytes" || gauge[0].Value.Raw() != 2048 || gauge[0].Labels["state"] != "used" {
+		t.Fatalf("unexpected gauge: %+v", gauge)
+	}
+	if sum := byName["cpu_requests_total"]; len(sum) != 1 || sum[0].Type != valueobject.CPU || sum[0].Value.Raw() != 7 {
+		t.Fatalf("monotonic cumulative sum must get _total: %+v", byName)
+	}
+
+	buckets := make(map[string]float64)
+	for _, raw := range byName["cpu_latency_bucket"] {
+		buckets[raw.Labels["le"]] = raw.Value.Raw()
+	}
+	if len(buckets) != 3 || buckets["0.1"] != 1 || buckets["1"] != 3 || buckets["+Inf"] != 6 {
+		t.Fatalf("unexpected cumulative buckets: %v", buckets)
+	}
+	if sum := byName["cpu_latency_sum"]; len(sum) != 1 || sum[0].Value.Raw() != 4.5 {
+		t.Fatalf("unexpected histogram sum: %+v", sum)
+	}
+	if count := byName["cpu_latency_count"]; len(count) != 1 || count[0].Value.Raw() != 6 || count[0].Labels["le"] != "" {
+		t.Fatalf("unexpected histogram count: %+v", count)
+	}
+}
+
+func TestMapperResolvesTypeAndUnit(t *testing.T) {
+	point := func(name, unit string, value float64, attributes ...KeyValue) Metric {
+		return Metric{Name: name, Unit: unit, Gauge: &Gauge{DataPoints: []NumberDataPoint{{
+			Attributes: attributes, TimeUnixNano: uint64(testTime.UnixNano()), Value: value,
+		}}}}
+	}
+	req := &ExportRequest{ResourceMetrics: []ResourceMetrics{{ScopeMetrics: []ScopeMetrics{{Metrics: []Metric{
+		point("system.cpu.utilization", "1", 0.25),
+		point("queue.depth", "{items}", 3, KeyValue{"type", "memory"}, KeyValue{"host", "db-1"}),
+		point("http.active_requests", "{requests}", 3),
+		point("system.network.io", "By/s", 10),
+		{Name: "system.cpu.time", Sum: &Sum{DataPoints: []NumberDataPoint{{Value: 1, Flags: flagNoRecordedValue}}}},
+	}}}}}}
+
+	rawMetrics, rejected := NewMapper(MapperConfig{}).ToRawMetrics(req)
+	if rejected != 1 || len(rawMetrics) != 3 {
+		t.Fatalf("expected 3 metrics and 1 rejected, got %d and %d: %+v", len(rawMetrics), rejected, rawMetrics)
+	}
+	if raw := rawMetrics[0]; raw.Type != valueobject.CPU || raw.Value.Unit() != "%" || raw.Value.Raw() != 25 {
+		t.Fatalf("ratio must be stored as percent: %+v", raw)
+	}
+	if raw := rawMetrics[1]; raw.Type != valueobject.Memory || raw.Host != "db-1" || raw.Value.Unit() != "%" || len(raw.Labels) != 0 {
+		t.Fatalf("explicit type and host attributes: %+v", raw)
+	}
+	if raw := rawMetrics[2]; raw.Type != valueobject.Network || raw.Value.Unit() != "bytes/s" {
+		t.Fatalf("UCUM unit mapping: %+v", raw)
+	}
+
+	// С типом по умолчанию метрика без типа принимается
+	rawMetrics, rejected = NewMapper(MapperConfig{DefaultType: valueobject.CPU}).ToRawMetrics(req)
+	if rejected != 0 || len(rawMetrics) != 4 {
+		t.Fatalf("expected 4 metrics with default type, got %d (rejected %d)", len(rawMetrics), rejected)
+	}
+}
+
+func TestMapperPrefersLongestTypePrefix(t *testing.T) {
+	point := func(name, unit string) Metric {
+		return Metric{Name: name, Unit: unit, Gauge: &Gauge{DataPoints: []NumberDataPoint{{
+			TimeUnixNano: uint64(testTime.UnixNano()), Value: 1,
+		}}}}
+	}
+	req := &ExportRequest{ResourceMetrics: []ResourceMetrics{{ScopeMetrics: []ScopeMetrics{{Metrics: []Metric{
+		point("cpu_usage", "%"),
+		point("cpu_core_usage", "%"),
+		point("disk_space_used", "By"),
+		point("disk_io_read", "By/s"),
+		point("network_recv_rate", "By/s"),
+	}}}}}}
+
+	rawMetrics, rejected := NewMapper(MapperConfig{}).ToRawMetrics(req)
+	if rejected != 0 || len(rawMetrics) != 5 {
+		t.Fatalf("expected 5 metrics, got %d (rejected %d)", len(rawMetrics), rejected)
+	}
+	want := []valueobject.MetricType{valueobject.CPU, valueobject.CPUCore, valueobject.DiskSpace, valueobject.DiskIO, valueobject.NetworkRecv}
+	for i, raw := range rawMetrics {
+		if raw.Type != want[i] {
+			t.Fatalf("%s mapped to %s, want %s", raw.Name, raw.Type, want[i])
+		}
+	}
+}
+
+func TestEncodeResponse(t *testing.T) {
+	if got := string(EncodeResponse(FormatJSON, 0, "")); got != "{}" {
+		t.Fatalf("empty JSON response = %s", got)
+	}
+	if got := string(EncodeResponse(FormatJSON, 2, "bad")); got != `{"partialSuccess":{"errorMessage":"bad","rejectedDataPoints":"2"}}` {
+		t.Fatalf("partial JSON response = %s", got)
+	}
+	want := []byte{0x0a, 0x07, 0x08, 0x02, 0x12, 0x03, 'b', 'a', 'd'}
+	if got := EncodeResponse(FormatProtobuf, 2, "bad"); !bytes.Equal(got, want) {
+		t.Fatalf("partial protobuf response = %x, want %x", got, want)
+	}
+	if got := string(EncodeStatus(FormatJSON, 400, "invalid")); got != `{"code":3,"message":"invalid"}` {
+		t.Fatalf("JSON status = %s", got)
+	}
+}
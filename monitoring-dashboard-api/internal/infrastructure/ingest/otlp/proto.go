@@ -0,0 +1,256 @@
+package otlp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/protowire"
+)
+
+// Номера полей opentelemetry/proto/metrics/v1/metrics.proto
+const (
+	metricFieldName                 = 1
+	metricFieldDescription          = 2
+	metricFieldUnit                 = 3
+	metricFieldGauge                = 5
+	metricFieldSum                  = 7
+	metricFieldHistogram            = 9
+	metricFieldExponentialHistogram = 10
+	metricFieldSummary              = 11
+)
+
+// UnmarshalProto разбирает ExportMetricsServiceRequest из protobuf
+func (r *ExportRequest) UnmarshalProto(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.Number != 1 || field.WireType != protowire.WireBytes {
+			return nil
+		}
+		var rm ResourceMetrics
+		if err := rm.unmarshal(field.Bytes); err != nil {
+			return fmt.Errorf("resource_metrics: %w", err)
+		}
+		r.ResourceMetrics = append(r.ResourceMetrics, rm)
+		return nil
+	})
+}
+
+func (rm *ResourceMetrics) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.WireType != protowire.WireBytes {
+			return nil
+		}
+		switch field.Number {
+		case 1:
+			// Resource: attributes = 1
+			return protowire.Walk(field.Bytes, func(resourceField protowire.Field) error {
+				if resourceField.Number != 1 || resourceField.WireType != protowire.WireBytes {
+					return nil
+				}
+				return appendKeyValue(&rm.Resource, resourceField.Bytes)
+			})
+		case 2:
+			var sm ScopeMetrics
+			if err := sm.unmarshal(field.Bytes); err != nil {
+				return fmt.Errorf("scope_metrics: %w", err)
+			}
+			rm.ScopeMetrics = append(rm.ScopeMetrics, sm)
+		}
+		return nil
+	})
+}
+
+func (sm *ScopeMetrics) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.Number != 2 || field.WireType != protowire.WireBytes {
+			return nil
+		}
+		var metric Metric
+		if err := metric.unmarshal(field.Bytes); err != nil {
+			return fmt.Errorf("metric: %w", err)
+		}
+		sm.Metrics = append(sm.Metrics, metric)
+		return nil
+	})
+}
+
+func (m *Metric) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.WireType != protowire.WireBytes {
+			return nil
+		}
+		switch field.Number {
+		case metricFieldName:
+			m.Name = string(field.Bytes)
+		case metricFieldDescription:
+			m.Description = string(field.Bytes)
+		case metricFieldUnit:
+			m.Unit = string(field.Bytes)
+		case metricFieldGauge:
+			m.Gauge = &Gauge{}
+			return protowire.Walk(field.Bytes, func(f protowire.Field) error {
+				if f.Number != 1 || f.WireType != protowire.WireBytes {
+					return nil
+				}
+				return appendNumberDataPoint(&m.Gauge.DataPoints, f.Bytes)
+			})
+		case metricFieldSum:
+			m.Sum = &Sum{}
+			return protowire.Walk(field.Bytes, func(f protowire.Field) error {
+				switch {
+				case f.Number == 1 && f.WireType == protowire.WireBytes:
+					return appendNumberDataPoint(&m.Sum.DataPoints, f.Bytes)
+				case f.Number == 2 && f.WireType == protowire.WireVarint:
+					m.Sum.Temporality = AggregationTemporality(f.Uint)
+				case f.Number == 3 && f.WireType == protowire.WireVarint:
+					m.Sum.IsMonotonic = f.Uint != 0
+				}
+				return nil
+			})
+		case metricFieldHistogram:
+			m.Histogram = &Histogram{}
+			return protowire.Walk(field.Bytes, func(f protowire.Field) error {
+				switch {
+				case f.Number == 1 && f.WireType == protowire.WireBytes:
+					var point HistogramDataPoint
+					if err := point.unmarshal(f.Bytes); err != nil {
+						return fmt.Errorf("histogram data point: %w", err)
+					}
+					m.Histogram.DataPoints = append(m.Histogram.DataPoints, point)
+				case f.Number == 2 && f.WireType == protowire.WireVarint:
+					m.Histogram.Temporality = AggregationTemporality(f.Uint)
+				}
+				return nil
+			})
+		case metricFieldExponentialHistogram, metricFieldSummary:
+			// Точки лежат в поле data_points = 1 обоих типов
+			return protowire.Walk(field.Bytes, func(f protowire.Field) error {
+				if f.Number == 1 && f.WireType == protowire.WireBytes {
+					m.UnsupportedPoints++
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+func appendNumberDataPoint(points *[]NumberDataPoint, data []byte) error {
+	var point NumberDataPoint
+	err := protowire.Walk(data, func(field protowire.Field) error {
+		switch {
+		case field.Number == 7 && field.WireType == protowire.WireBytes:
+			return appendKeyValue(&point.Attributes, field.Bytes)
+		case field.Number == 3 && field.WireType == protowire.WireFixed64:
+			point.TimeUnixNano = field.Uint
+		case field.Number == 4 && field.WireType == protowire.WireFixed64:
+			point.Value = field.Double()
+		case field.Number == 6 && field.WireType == protowire.WireFixed64:
+			point.Value = float64(int64(field.Uint))
+		case field.Number == 8 && field.WireType == protowire.WireVarint:
+			point.Flags = uint32(field.Uint)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("number data point: %w", err)
+	}
+	*points = append(*points, point)
+	return nil
+}
+
+func (p *HistogramDataPoint) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		switch field.Number {
+		case 9:
+			if field.WireType == protowire.WireBytes {
+				return appendKeyValue(&p.Attributes, field.Bytes)
+			}
+		case 3:
+			p.TimeUnixNano = field.Uint
+		case 4:
+			p.Count = field.Uint
+		case 5:
+			if field.WireType == protowire.WireFixed64 {
+				p.Sum, p.HasSum = field.Double(), true
+			}
+		case 6:
+			values, err := repeatedFixed64(field)
+			if err != nil {
+				return fmt.Errorf("bucket_counts: %w", err)
+			}
+			p.BucketCounts = append(p.BucketCounts, values...)
+		case 7:
+			values, err := repeatedFixed64(field)
+			if err != nil {
+				return fmt.Errorf("explicit_bounds: %w", err)
+			}
+			for _, bits := range values {
+				p.ExplicitBounds = append(p.ExplicitBounds, math.Float64frombits(bits))
+			}
+		case 10:
+			p.Flags = uint32(field.Uint)
+		}
+		return nil
+	})
+}
+
+// repeatedFixed64 значения repeated fixed64/double в packed или обычной кодировке
+func repeatedFixed64(field protowire.Field) ([]uint64, error) {
+	switch field.WireType {
+	case protowire.WireBytes:
+		return protowire.PackedFixed64(field.Bytes)
+	case protowire.WireFixed64:
+		return []uint64{field.Uint}, nil
+	}
+	return nil, fmt.Errorf("unexpected wire type %d", field.WireType)
+}
+
+// appendKeyValue разбирает KeyValue и добавляет его, если значение - скаляр
+func appendKeyValue(attributes *[]KeyValue, data []byte) error {
+	var kv KeyValue
+	hasValue := false
+	err := protowire.Walk(data, func(field protowire.Field) error {
+		if field.WireType != protowire.WireBytes {
+			return nil
+		}
+		switch field.Number {
+		case 1:
+			kv.Key = string(field.Bytes)
+		case 2:
+			value, ok, err := anyValueString(field.Bytes)
+			if err != nil {
+				return err
+			}
+			kv.Value, hasValue = value, ok
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("attribute: %w", err)
+	}
+	if hasValue && kv.Key != "" {
+		*attributes = append(*attributes, kv)
+	}
+	return nil
+}
+
+// anyValueString приводит скалярный AnyValue к строке
+func anyValueString(data []byte) (string, bool, error) {
+	var value string
+	ok := false
+	err := protowire.Walk(data, func(field protowire.Field) error {
+		switch {
+		case field.Number == 1 && field.WireType == protowire.WireBytes:
+			value, ok = string(field.Bytes), true
+		case field.Number == 2 && field.WireType == protowire.WireVarint:
+			value, ok = strconv.FormatBool(field.Uint != 0), true
+		case field.Number == 3 && field.WireType == protowire.WireVarint:
+			value, ok = strconv.FormatInt(int64(field.Uint), 10), true
+		case field.Number == 4 && field.WireType == protowire.WireFixed64:
+			value, ok = strconv.FormatFloat(field.Double(), 'g', -1, 64), true
+		}
+		return nil
+	})
+	return value, ok, err
+}
@@ -0,0 +1,121 @@
+// Package protowire минимальный разбор и запись protobuf wire format
+// Используется приемниками remote_write и OTLP вместо сгенерированного кода
+package protowire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Типы полей protobuf wire format
+const (
+	WireVarint  = 0
+	WireFixed64 = 1
+	WireBytes   = 2
+	WireFixed32 = 5
+)
+
+// ErrTruncated сообщение обрывается посреди поля
+var ErrTruncated = errors.New("truncated protobuf message")
+
+// Field поле сообщения: для WireBytes заполнен Bytes, для числовых типов - Number
+type Field struct {
+	Number   int
+	WireType int
+	Bytes    []byte
+	Uint     uint64
+}
+
+// Double значение fixed64-поля как double
+func (f Field) Double() float64 {
+	return math.Float64frombits(f.Uint)
+}
+
+// Walk обходит поля сообщения по порядку; группы (wire types 3, 4) не поддерживаются
+func Walk(data []byte, fn func(field Field) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrTruncated
+		}
+		data = data[n:]
+
+		field := Field{Number: int(key >> 3), WireType: int(key & 7)}
+		if field.Number == 0 {
+			return errors.New("invalid field number 0")
+		}
+
+		switch field.WireType {
+		case WireVarint:
+			field.Uint, n = binary.Uvarint(data)
+			if n <= 0 {
+				return ErrTruncated
+			}
+			data = data[n:]
+		case WireFixed64:
+			if len(data) < 8 {
+				return ErrTruncated
+			}
+			field.Uint = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case WireFixed32:
+			if len(data) < 4 {
+				return ErrTruncated
+			}
+			field.Uint = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		case WireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return ErrTruncated
+			}
+			field.Bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", field.WireType)
+		}
+
+		if err := fn(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PackedFixed64 разбирает packed repeated fixed64/double
+func PackedFixed64(data []byte) ([]uint64, error) {
+	if len(data)%8 != 0 {
+		return nil, ErrTruncated
+	}
+	values := make([]uint64, len(data)/8)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return values, nil
+}
+
+// AppendBytes дописывает length-delimited поле
+func AppendBytes(out []byte, field int, value []byte) []byte {
+	out = binary.AppendUvarint(out, uint64(field)<<3|WireBytes)
+	out = binary.AppendUvarint(out, uint64(len(value)))
+	return append(out, value...)
+}
+
+// AppendVarint дописывает varint-поле
+func AppendVarint(out []byte, field int, value uint64) []byte {
+	out = binary.AppendUvarint(out, uint64(field)<<3|WireVarint)
+	return binary.AppendUvarint(out, value)
+}
+
+// AppendFixed64 дописывает fixed64-поле
+func AppendFixed64(out []byte, field int, value uint64) []byte {
+	out = binary.AppendUvarint(out, uint64(field)<<3|WireFixed64)
+	return binary.LittleEndian.AppendUint64(out, value)
+}
+
+// AppendDouble дописывает double-поле
+func AppendDouble(out []byte, field int, value float64) []byte {
+	return AppendFixed64(out, field, math.Float64bits(value))
+}
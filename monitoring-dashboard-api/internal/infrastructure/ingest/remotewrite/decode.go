@@ -0,0 +1,41 @@
+package remotewrite
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+// maxDecodedSize ограничивает размер распакованного запроса (защита от snappy-бомб)
+const maxDecodedSize = 32 * 1024 * 1024
+
+// ErrInvalidPayload тело запроса не является snappy-сжатым prompb.WriteRequest
+var ErrInvalidPayload = errors.New("invalid remote_write payload")
+
+// DecodeWriteRequest распаковывает snappy (block format) и разбирает WriteRequest
+func DecodeWriteRequest(body []byte) (*WriteRequest, error) {
+	size, err := snappy.DecodedLen(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if size > maxDecodedSize {
+		return nil, fmt.Errorf("%w: decoded size %d exceeds %d bytes", ErrInvalidPayload, size, maxDecodedSize)
+	}
+
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	var req WriteRequest
+	if err := req.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	return &req, nil
+}
+
+// EncodeWriteRequest кодирует WriteRequest в тело запроса remote_write
+func EncodeWriteRequest(req *WriteRequest) []byte {
+	return snappy.Encode(nil, req.Marshal())
+}
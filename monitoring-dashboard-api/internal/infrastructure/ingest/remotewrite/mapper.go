@@ -0,0 +1,196 @@
+package remotewrite
+
+import (
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// Метки серии, управляющие сопоставлением; в метки метрики они не попадают
+const (
+	typeLabel     = "type"
+	unitLabel     = "unit"
+	instanceLabel = "instance"
+)
+
+// staleNaN маркер устаревания серии в Prometheus (не является значением)
+const staleNaN uint64 = 0x7ff0000000000002
+
+// nodeExporterPrefixes сопоставляет префиксы имен node_exporter с типами
+// Серии node_exporter (счетчики секунд CPU, байты памяти и дисков) не попадают в cpu, memory, disk
+// и network: там они сравнивались бы с процентными порогами. Пустая единица определяется по суффиксу
+var nodeExporterPrefixes = []struct {
+	prefix     string
+	metricType valueobject.MetricType
+	unit       string
+}{
+	{"node_load", valueobject.Load, "load"},
+	{"node_", valueobject.NodeExporter, ""},
+}
+
+// unitSuffixes единица измерения по суффиксу имени (соглашения Prometheus)
+// Более длинные суффиксы проверяются раньше: _bytes_total - байты, прочие _total - количество
+var unitSuffixes = []struct {
+	suffix string
+	unit   string
+}{
+	{"_bytes_per_second", "bytes/s"},
+	{"_bytes_total", "bytes"},
+	{"_seconds_total", "seconds"},
+	{"_total", "count"},
+	{"_percent", "%"},
+	{"_usage", "%"},
+	{"_bytes", "bytes"},
+	{"_seconds", "seconds"},
+}
+
+// MapperConfig настройки сопоставления серий remote_write с метриками
+type MapperConfig struct {
+	// DefaultType тип серий, для которых тип не удалось определить (пустой - такие серии отклоняются)
+	DefaultType valueobject.MetricType
+}
+
+// Mapper сопоставляет серии remote_write с сырыми метриками
+//
+// Имя метрики - __name__, хост - метка host или хост из instance (без порта).
+// Тип - метка type, префикс имени (cpu_*, node_* ...) или DefaultType.
+// Единица - метка unit, суффикс имени (_bytes, _percent, _total) или единица префикса node_exporter;
+// серии, для которых допустимую единицу определить не удалось, отклоняются
+type Mapper struct {
+	config MapperConfig
+}
+
+// NewMapper создает новый Mapper
+func NewMapper(config MapperConfig) *Mapper {
+	return &Mapper{config: config}
+}
+
+// ToRawMetrics конвертирует сэмплы всех серий в port.RawMetric
+// Возвращает количество отклоненных сэмплов (серия без типа или единицы, NaN/Inf, гистограммы)
+func (m *Mapper) ToRawMetrics(req *WriteRequest) ([]port.RawMetric, int) {
+	rawMetrics := make([]port.RawMetric, 0, len(req.Timeseries))
+	rejected := 0
+
+	for _, series := range req.Timeseries {
+		rejected += series.Histograms
+
+		name, host, labels, controls := splitLabels(series.Labels)
+		definition, prefixUnit, ok := m.resolveType(name, controls[typeLabel])
+		if !ok || name == "" {
+			rejected += len(series.Samples)
+			continue
+		}
+		unit, ok := resolveUnit(definition, name, controls[unitLabel], prefixUnit)
+		if !ok {
+			rejected += len(series.Samples)
+			continue
+		}
+
+		for _, sample := range series.Samples {
+			if math.Float64bits(sample.Value) == staleNaN {
+				continue
+			}
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				rejected++
+				continue
+			}
+			value, err := valueobject.NewMetricValue(sample.Value, unit)
+			if err != nil {
+				rejected++
+				continue
+			}
+
+			rawMetrics = append(rawMetrics, port.RawMetric{
+				Type:        definition.Type,
+				Name:        name,
+				Value:       value,
+				Host:        host,
+				Labels:      labels,
+				CollectedAt: time.UnixMilli(sample.Timestamp),
+			})
+		}
+	}
+
+	return rawMetrics, rejected
+}
+
+// splitLabels разделяет метки серии на имя, хост, метки метрики и управляющие метки
+func splitLabels(seriesLabels []Label) (string, string, map[string]string, map[string]string) {
+	var name, host, instance string
+	labels := make(map[string]string, len(seriesLabels))
+	controls := make(map[string]string, 2)
+
+	for _, label := range seriesLabels {
+		switch {
+		case label.Name == valueobject.NameLabel:
+			name = label.Value
+		case label.Name == valueobject.HostLabel:
+			host = label.Value
+		case label.Name == typeLabel || label.Name == unitLabel:
+			controls[label.Name] = label.Value
+		case strings.HasPrefix(label.Name, "__"):
+			// Служебные метки Prometheus (__replica__ и т.п.) не сохраняются
+		default:
+			if label.Name == instanceLabel {
+				instance = label.Value
+			}
+			labels[label.Name] = label.Value
+		}
+	}
+
+	if host == "" && instance != "" {
+		host = instance
+		if h, _, err := net.SplitHostPort(instance); err == nil {
+			host = h
+		}
+	}
+
+	return name, host, labels, controls
+}
+
+// resolveType определяет тип серии по метке type, префиксу имени или типу по умолчанию
+// Для префиксов node_exporter дополнительно возвращает единицу измерения префикса (может быть пустой)
+func (m *Mapper) resolveType(name, explicit string) (valueobject.MetricTypeDefinition, string, bool) {
+	if explicit != "" {
+		definition, ok := valueobject.MetricType(explicit).Definition()
+		return definition, "", ok
+	}
+
+	registry := valueobject.DefaultMetricTypeRegistry()
+	if definition, ok := registry.ResolveByPrefix(name); ok {
+		return definition, "", true
+	}
+	for _, rule := range nodeExporterPrefixes {
+		if strings.HasPrefix(name, rule.prefix) {
+			definition, ok := registry.Lookup(rule.metricType)
+			return definition, rule.unit, ok
+		}
+	}
+
+	if m.config.DefaultType != "" {
+		definition, ok := registry.Lookup(m.config.DefaultType)
+		return definition, "", ok
+	}
+	return valueobject.MetricTypeDefinition{}, "", false
+}
+
+// resolveUnit выбирает единицу измерения, допустимую для типа
+// Явная метка unit, не допустимая для типа, не заменяется другой единицей: серия отклоняется
+func resolveUnit(definition valueobject.MetricTypeDefinition, name, explicit, prefixUnit string) (string, bool) {
+	if explicit != "" {
+		return explicit, definition.AllowsUnit(explicit)
+	}
+	for _, rule := range unitSuffixes {
+		if strings.HasSuffix(name, rule.suffix) && definition.AllowsUnit(rule.unit) {
+			return rule.unit, true
+		}
+	}
+	if prefixUnit != "" && definition.AllowsUnit(prefixUnit) {
+		return prefixUnit, true
+	}
+	return "", false
+}
@@ -0,0 +1,126 @@
+package remotewrite
+
+import (
+	"fmt"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/protowire"
+)
+
+// WriteRequest тело запроса Prometheus remote_write 1.0 (prompb.WriteRequest)
+// Разбираются только серии с метками и сэмплами; exemplars, гистограммы и metadata пропускаются
+type WriteRequest struct {
+	Timeseries []TimeSeries
+}
+
+// TimeSeries серия: метки (включая __name__) и сэмплы
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+
+	// Histograms количество нативных гистограмм в серии (не поддерживаются и отклоняются)
+	Histograms int
+}
+
+// Label метка серии
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample значение серии в момент Timestamp (unix-миллисекунды)
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// Unmarshal разбирает WriteRequest из protobuf
+func (r *WriteRequest) Unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.Number != 1 || field.WireType != protowire.WireBytes {
+			return nil
+		}
+		var series TimeSeries
+		if err := series.unmarshal(field.Bytes); err != nil {
+			return fmt.Errorf("timeseries: %w", err)
+		}
+		r.Timeseries = append(r.Timeseries, series)
+		return nil
+	})
+}
+
+func (s *TimeSeries) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.WireType != protowire.WireBytes {
+			return nil
+		}
+		switch field.Number {
+		case 1:
+			var label Label
+			if err := label.unmarshal(field.Bytes); err != nil {
+				return fmt.Errorf("label: %w", err)
+			}
+			s.Labels = append(s.Labels, label)
+		case 2:
+			var sample Sample
+			if err := sample.unmarshal(field.Bytes); err != nil {
+				return fmt.Errorf("sample: %w", err)
+			}
+			s.Samples = append(s.Samples, sample)
+		case 4:
+			s.Histograms++
+		}
+		return nil
+	})
+}
+
+func (l *Label) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		if field.WireType != protowire.WireBytes {
+			return nil
+		}
+		switch field.Number {
+		case 1:
+			l.Name = string(field.Bytes)
+		case 2:
+			l.Value = string(field.Bytes)
+		}
+		return nil
+	})
+}
+
+func (s *Sample) unmarshal(data []byte) error {
+	return protowire.Walk(data, func(field protowire.Field) error {
+		switch {
+		case field.Number == 1 && field.WireType == protowire.WireFixed64:
+			s.Value = field.Double()
+		case field.Number == 2 && field.WireType == protowire.WireVarint:
+			s.Timestamp = int64(field.Uint)
+		}
+		return nil
+	})
+}
+
+// Marshal кодирует WriteRequest в protobuf (нужен агентам и тестам, которые шлют remote_write)
+func (r *WriteRequest) Marshal() []byte {
+	var out []byte
+	for _, series := range r.Timeseries {
+		out = protowire.AppendBytes(out, 1, series.marshal())
+	}
+	return out
+}
+
+func (s TimeSeries) marshal() []byte {
+	var out []byte
+	for _, label := range s.Labels {
+		var encoded []byte
+		encoded = protowire.AppendBytes(encoded, 1, []byte(label.Name))
+		encoded = protowire.AppendBytes(encoded, 2, []byte(label.Value))
+		out = protowire.AppendBytes(out, 1, encoded)
+	}
+	for _, sample := range s.Samples {
+		encoded := protowire.AppendDouble(nil, 1, sample.Value)
+		encoded = protowire.AppendVarint(encoded, 2, uint64(sample.Timestamp))
+		out = protowire.AppendBytes(out, 2, encoded)
+	}
+	return out
+}
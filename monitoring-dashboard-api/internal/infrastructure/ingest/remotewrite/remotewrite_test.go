@@ -0,0 +1,198 @@
+package remotewrite
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// cannedWriteRequest - prompb.WriteRequest{up{job="node"} 1 @1000}, закодированный вручную
+var cannedWriteRequest = []byte{
+	0x0a, 0x2b, // timeseries, 43 байта
+	0x0a, 0x0e, 0x0a, 0x08, '_', '_', 'n', 'a', 'm', 'e', '_', '_', 0x12, 0x02, 'u', 'p', // label __name__="up"
+	0x0a, 0x0b, 0x0a, 0x03, 'j', 'o', 'b', 0x12, 0x04, 'n', 'o', 'd', 'e', // label job="node"
+	0x12, 0x0c, 0x09, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, 0x10, 0xe8, 0x07, // sample 1.0 @ 1000
+	0x1a, 0x00, // metadata (пропускается)
+}
+
+func TestDecodeCannedWriteRequest(t *testing.T) {
+	req, err := DecodeWriteRequest(snappy.Encode(nil, cannedWriteRequest))
+	if err != nil {
+		t.Fatalf("DecodeWriteRequest() error = %v", err)
+	}
+	if len(req.Timeseries) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(req.Timeseries))
+	}
+	series := req.Timeseries[0]
+	if len(series.Labels) != 2 || series.Labels[0] != (Label{"__name__", "up"}) || series.Labels[1] != (Label{"job", "node"}) {
+		t.Fatalf("unexpected labels: %+v", series.Labels)
+	}
+	if len(series.Samples) != 1 || series.Samples[0] != (Sample{Value: 1, Timestamp: 1000}) {
+		t.Fatalf("unexpected samples: %+v", series.Samples)
+	}
+}
+
+func TestDecodeRejectsInvalidPayloads(t *testing.T) {
+	cases := map[string][]byte{
+		"not snappy":        []byte("plain text body"),
+		"truncated message": snappy.Encode(nil, cannedWriteRequest[:10]),
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeWriteRequest(body); !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("expected ErrInvalidPayload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	want := &WriteRequest{Timeseries: []TimeSeries{{
+		Labels:  []Label{{"__name__", "cpu_usage"}, {"instance", "web-1:9100"}},
+		Samples: []Sample{{Value: 42.5, Timestamp: 1700000000000}, {Value: 0, Timestamp: -1}},
+	}}}
+
+	got, err := DecodeWriteRequest(EncodeWriteRequest(want))
+	if err != nil {
+		t.Fatalf("DecodeWriteRequest() error = %v", err)
+	}
+	if len(got.Timeseries) != 1 || len(got.Timeseries[0].Samples) != 2 || got.Timeseries[0].Samples[1].Timestamp != -1 {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+}
+
+func TestMapperResolvesTypeUnitAndHost(t *testing.T) {
+	at := time.UnixMilli(1700000000000)
+	req := &WriteRequest{Timeseries: []TimeSeries{
+		{
+			Labels:  []Label{{"__name__", "node_memory_MemAvailable_bytes"}, {"instance", "web-1:9100"}, {"job", "node"}, {"__replica__", "a"}},
+			Samples: []Sample{{Value: 1024, Timestamp: at.UnixMilli()}, {Value: math.Float64frombits(staleNaN), Timestamp: at.UnixMilli()}},
+		},
+		{
+			Labels:  []Label{{"__name__", "cpu_usage"}, {"host", "db-1"}, {"instance", "db-1:8080"}},
+			Samples: []Sample{{Value: 12, Timestamp: at.UnixMilli()}, {Value: math.Inf(1), Timestamp: at.UnixMilli()}},
+		},
+		{
+			Labels:  []Label{{"__name__", "queue_depth"}, {"type", "disk"}, {"unit", "GB"}},
+			Samples: []Sample{{Value: 3, Timestamp: at.UnixMilli()}},
+		},
+		{
+			Labels:  []Label{{"__name__", "http_requests_total"}},
+			Samples: []Sample{{Value: 1, Timestamp: at.UnixMilli()}, {Value: 2, Timestamp: at.UnixMilli()}},
+		},
+	}}
+
+	rawMetrics, rejected := NewMapper(MapperConfig{}).ToRawMetrics(req)
+
+	// +Inf и две точки серии без типа отклонены, stale-маркер пропущен без учета
+	if rejected != 3 || len(rawMetrics) != 3 {
+		t.Fatalf("got %d metrics, %d rejected; want 3 and 3", len(rawMetrics), rejected)
+	}
+
+	memory := rawMetrics[0]
+	if memory.Type != valueobject.NodeExporter || memory.Value.Unit() != "bytes" || memory.Host != "web-1" || !memory.CollectedAt.Equal(at) {
+		t.Fatalf("unexpected memory metric: %+v", memory)
+	}
+	if len(memory.Labels) != 2 || memory.Labels["instance"] != "web-1:9100" || memory.Labels["job"] != "node" {
+		t.Fatalf("unexpected memory labels: %v", memory.Labels)
+	}
+
+	if cpu := rawMetrics[1]; cpu.Type != valueobject.CPU || cpu.Value.Unit() != "%" || cpu.Host != "db-1" {
+		t.Fatalf("unexpected cpu metric: %+v", cpu)
+	}
+	if disk := rawMetrics[2]; disk.Type != valueobject.Disk || disk.Value.Unit() != "GB" || len(disk.Labels) != 0 {
+		t.Fatalf("explicit type and unit labels must win: %+v", disk)
+	}
+
+	// С типом по умолчанию серия без распознанного типа принимается, если тип допускает ее единицу
+	rawMetrics, rejected = NewMapper(MapperConfig{DefaultType: valueobject.NodeExporter}).ToRawMetrics(&WriteRequest{Timeseries: req.Timeseries[3:]})
+	if rejected != 0 || len(rawMetrics) != 2 || rawMetrics[0].Type != valueobject.NodeExporter || rawMetrics[0].Value.Unit() != "count" {
+		t.Fatalf("unexpected default type mapping: %+v (rejected %d)", rawMetrics, rejected)
+	}
+	rawMetrics, rejected = NewMapper(MapperConfig{DefaultType: valueobject.Network}).ToRawMetrics(&WriteRequest{Timeseries: req.Timeseries[3:]})
+	if rejected != 2 || len(rawMetrics) != 0 {
+		t.Fatalf("series without an allowed unit must be rejected: %+v (rejected %d)", rawMetrics, rejected)
+	}
+}
+
+func TestMapperKeepsNodeExporterOutOfBuiltinTypes(t *testing.T) {
+	at := time.UnixMilli(1700000000000).UnixMilli()
+	series := func(name string, extra ...Label) TimeSeries {
+		labels := append([]Label{{"__name__", name}, {"instance", "web-1:9100"}}, extra...)
+		return TimeSeries{Labels: labels, Samples: []Sample{{Value: 123456, Timestamp: at}}}
+	}
+	req := &WriteRequest{Timeseries: []TimeSeries{
+		series("node_cpu_seconds_total", Label{"cpu", "0"}, Label{"mode", "user"}),
+		series("node_memory_MemAvailable_bytes"),
+		series("node_filesystem_avail_bytes", Label{"mountpoint", "/"}),
+		series("node_network_receive_packets_total", Label{"device", "eth0"}),
+		series("node_load1"),
+		series("node_filesystem_files"),                        // единица не определяется
+		series("node_memory_Active_bytes", Label{"unit", "%"}), // явная единица не допустима для типа
+	}}
+
+	rawMetrics, rejected := NewMapper(MapperConfig{DefaultType: valueobject.CPU}).ToRawMetrics(req)
+	if rejected != 2 || len(rawMetrics) != 5 {
+		t.Fatalf("got %d metrics, %d rejected; want 5 and 2", len(rawMetrics), rejected)
+	}
+
+	want := []struct {
+		metricType valueobject.MetricType
+		unit       string
+	}{
+		{valueobject.NodeExporter, "seconds"},
+		{valueobject.NodeExporter, "bytes"},
+		{valueobject.NodeExporter, "bytes"},
+		{valueobject.NodeExporter, "count"},
+		{valueobject.Load, "load"},
+	}
+	for i, metric := range rawMetrics {
+		if metric.Type != want[i].metricType || metric.Value.Unit() != want[i].unit {
+			t.Fatalf("%s mapped to %s/%s, want %s/%s", metric.Name, metric.Type, metric.Value.Unit(), want[i].metricType, want[i].unit)
+		}
+	}
+}
+
+func TestMapperPrefersLongestTypePrefix(t *testing.T) {
+	at := time.UnixMilli(1700000000000).UnixMilli()
+	tests := []struct {
+		name string
+		unit string
+		want valueobject.MetricType
+	}{
+		{name: "cpu_usage", want: valueobject.CPU},
+		{name: "cpu_core_usage", want: valueobject.CPUCore},
+		{name: "cpu_mode_usage", want: valueobject.CPUMode},
+		{name: "disk_usage", want: valueobject.Disk},
+		{name: "disk_space_used_bytes", want: valueobject.DiskSpace},
+		{name: "disk_inodes_used_percent", want: valueobject.DiskInodes},
+		{name: "disk_io_read_bytes_per_second", want: valueobject.DiskIO},
+		{name: "network_sent_bytes_per_second", want: valueobject.Network},
+		{name: "network_recv_bytes_per_second", want: valueobject.NetworkRecv},
+		{name: "network_packets_rx", unit: "packets/s", want: valueobject.NetworkPackets},
+		{name: "network_errors_rx", unit: "packets/s", want: valueobject.NetworkErrors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := []Label{{"__name__", tt.name}}
+			if tt.unit != "" {
+				labels = append(labels, Label{"unit", tt.unit})
+			}
+			req := &WriteRequest{Timeseries: []TimeSeries{{Labels: labels, Samples: []Sample{{Value: 1, Timestamp: at}}}}}
+
+			rawMetrics, rejected := NewMapper(MapperConfig{}).ToRawMetrics(req)
+			if rejected != 0 || len(rawMetrics) != 1 {
+				t.Fatalf("got %d metrics, %d rejected; want 1 and 0", len(rawMetrics), rejected)
+			}
+			if rawMetrics[0].Type != tt.want {
+				t.Fatalf("%s mapped to %s, want %s", tt.name, rawMetrics[0].Type, tt.want)
+			}
+		})
+	}
+}
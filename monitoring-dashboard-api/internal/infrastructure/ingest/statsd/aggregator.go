@@ -0,0 +1,309 @@
+package statsd
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// Теги, управляющие сопоставлением; в метки метрики они не попадают
+const (
+	typeTag = "type"
+	unitTag = "unit"
+)
+
+// maxTimerValues количество значений таймера за окно, по которым считаются перцентили
+// Значения сверх предела учитываются только в count, sum, min и max
+const maxTimerValues = 10000
+
+// timerPercentiles перцентили, которые выдаются для таймеров
+var timerPercentiles = []struct {
+	suffix string
+	q      float64
+}{
+	{"_p50", 0.5},
+	{"_p90", 0.9},
+	{"_p99", 0.99},
+}
+
+// AggregatorConfig настройки агрегации
+type AggregatorConfig struct {
+	// DefaultType тип метрик, для которых тип не удалось определить (пустой - такие метрики отклоняются)
+	DefaultType valueobject.MetricType
+
+	// DefaultHost хост метрик без тега host
+	DefaultHost string
+
+	// MaxSeries предел различных серий за окно; новые серии сверх него отбрасываются
+	MaxSeries int
+}
+
+// series накопленное за окно состояние одной серии
+type series struct {
+	kind       Kind
+	name       string
+	host       string
+	labels     map[string]string
+	definition valueobject.MetricTypeDefinition
+	unit       string
+
+	// counter - сумма с учетом sample rate; gauge - текущее значение
+	value   float64
+	updated bool
+
+	count    float64 // с учетом sample rate
+	observed int     // фактически полученные значения
+	sum      float64
+	min, max float64
+	values   []float64
+
+	set map[string]struct{}
+}
+
+// Aggregator накапливает значения StatsD за окно и превращает их в сырые метрики
+//
+// Имя - имя метрики с символами вне [a-zA-Z0-9_:] замененными на "_" (app.requests -> app_requests).
+// Хост - тег host или DefaultHost. Тип - тег type, самый длинный префикс имени (cpu_*, cpu_core_* ...)
+// или DefaultType. Единица - тег unit, если тип ее допускает, иначе первая единица типа
+type Aggregator struct {
+	config AggregatorConfig
+
+	mu       sync.Mutex
+	series   map[string]*series
+	rejected int
+}
+
+// NewAggregator создает новый Aggregator
+func NewAggregator(config AggregatorConfig) *Aggregator {
+	if config.MaxSeries <= 0 {
+		config.MaxSeries = 10000
+	}
+	return &Aggregator{config: config, series: make(map[string]*series)}
+}
+
+// Add учитывает значение; значения без типа и сверх MaxSeries отклоняются
+func (a *Aggregator) Add(sample Sample) {
+	name := sanitizeName(sample.Name)
+	host := a.config.DefaultHost
+	labels := make(map[string]string, len(sample.Tags))
+	var explicitType, explicitUnit string
+	for tag, value := range sample.Tags {
+		switch tag {
+		case valueobject.HostLabel:
+			host = value
+		case typeTag:
+			explicitType = value
+		case unitTag:
+			explicitUnit = value
+		default:
+			if label, ok := sanitizeLabelName(tag); ok {
+				labels[label] = value
+			}
+		}
+	}
+	key := seriesKey(sample.Kind, name, host, labels)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.series[key]
+	if !ok {
+		definition, found := a.resolveType(name, explicitType)
+		if !found || len(a.series) >= a.config.MaxSeries {
+			a.rejected++
+			return
+		}
+		unit := definition.Units[0]
+		if explicitUnit != "" && definition.AllowsUnit(explicitUnit) {
+			unit = explicitUnit
+		}
+		s = &series{kind: sample.Kind, name: name, host: host, labels: labels, definition: definition, unit: unit}
+		a.series[key] = s
+	}
+
+	s.updated = true
+	switch sample.Kind {
+	case KindCounter:
+		for _, value := range sample.Values {
+			s.value += value / sample.SampleRate
+		}
+	case KindGauge:
+		for _, value := range sample.Values {
+			if sample.Delta {
+				s.value += value
+			} else {
+				s.value = value
+			}
+		}
+	case KindTimer:
+		for _, value := range sample.Values {
+			if s.count == 0 || value < s.min {
+				s.min = value
+			}
+			if s.count == 0 || value > s.max {
+				s.max = value
+			}
+			s.count += 1 / sample.SampleRate
+			s.observed++
+			s.sum += value
+			if len(s.values) < maxTimerValues {
+				s.values = append(s.values, value)
+			}
+		}
+	case KindSet:
+		if s.set == nil {
+			s.set = make(map[string]struct{})
+		}
+		for _, value := range sample.SetValues {
+			s.set[value] = struct{}{}
+		}
+	}
+}
+
+// Flush возвращает агрегаты окна с временем at и начинает новое окно
+// Gauge сохраняется в следующем окне (для изменений со знаком), пока он обновляется
+// Возвращает количество значений, отклоненных за окно
+func (a *Aggregator) Flush(at time.Time) ([]port.RawMetric, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var rawMetrics []port.RawMetric
+	rejected := a.rejected
+	a.rejected = 0
+
+	emit := func(s *series, name string, value float64) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			rejected++
+			return
+		}
+		metricValue, err := valueobject.NewMetricValue(value, s.unit)
+		if err != nil {
+			rejected++
+			return
+		}
+		rawMetrics = append(rawMetrics, port.RawMetric{
+			Type:        s.definition.Type,
+			Name:        name,
+			Value:       metricValue,
+			Host:        s.host,
+			Labels:      s.labels,
+			CollectedAt: at,
+		})
+	}
+
+	for key, s := range a.series {
+		switch s.kind {
+		case KindCounter:
+			emit(s, s.name, s.value)
+		case KindGauge:
+			if !s.updated {
+				delete(a.series, key)
+				continue
+			}
+			emit(s, s.name, s.value)
+			s.updated = false
+			continue
+		case KindTimer:
+			emit(s, s.name+"_count", s.count)
+			emit(s, s.name+"_sum", s.sum)
+			emit(s, s.name+"_min", s.min)
+			emit(s, s.name+"_max", s.max)
+			emit(s, s.name+"_avg", s.sum/float64(s.observed))
+			sort.Float64s(s.values)
+			for _, p := range timerPercentiles {
+				emit(s, s.name+p.suffix, percentile(s.values, p.q))
+			}
+		case KindSet:
+			emit(s, s.name, float64(len(s.set)))
+		}
+		delete(a.series, key)
+	}
+
+	return rawMetrics, rejected
+}
+
+// resolveType определяет тип по тегу type, префиксу имени или типу по умолчанию
+func (a *Aggregator) resolveType(name, explicit string) (valueobject.MetricTypeDefinition, bool) {
+	if explicit != "" {
+		return valueobject.MetricType(explicit).Definition()
+	}
+
+	registry := valueobject.DefaultMetricTypeRegistry()
+	if definition, ok := registry.ResolveByPrefix(name); ok {
+		return definition, true
+	}
+
+	if a.config.DefaultType != "" {
+		return registry.Lookup(a.config.DefaultType)
+	}
+	return valueobject.MetricTypeDefinition{}, false
+}
+
+// percentile значение по методу nearest-rank; sorted отсортирован по возрастанию
+func percentile(sorted []float64, q float64) float64 {
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func seriesKey(kind Kind, name, host string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(string(kind))
+	b.WriteByte(0xff)
+	b.WriteString(name)
+	b.WriteByte(0xff)
+	b.WriteString(host)
+	for _, label := range names {
+		b.WriteByte(0xff)
+		b.WriteString(label)
+		b.WriteByte('=')
+		b.WriteString(labels[label])
+	}
+	return b.String()
+}
+
+// sanitizeName приводит имя StatsD к виду [a-zA-Z_:][a-zA-Z0-9_:]*
+func sanitizeName(name string) string {
+	return sanitize(name, true)
+}
+
+// sanitizeLabelName приводит имя тега к имени метки; зарезервированные имена отбрасываются
+func sanitizeLabelName(tag string) (string, bool) {
+	name := sanitize(tag, false)
+	if name == "" || strings.HasPrefix(name, "__") {
+		return "", false
+	}
+	return name, true
+}
+
+func sanitize(name string, allowColon bool) string {
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		valid := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9' && i > 0) || (allowColon && r == ':')
+		switch {
+		case valid:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteByte('_')
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
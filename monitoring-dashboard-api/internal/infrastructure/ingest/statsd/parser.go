@@ -0,0 +1,121 @@
+// Package statsd UDP-приемник метрик StatsD с расширениями DogStatsD
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind вид метрики StatsD
+type Kind string
+
+const (
+	KindCounter Kind = "c"
+	KindGauge   Kind = "g"
+	KindTimer   Kind = "ms"
+	KindSet     Kind = "s"
+)
+
+// ErrSkipped строка не является метрикой (события и service checks DogStatsD)
+var ErrSkipped = errors.New("not a metric")
+
+// Sample одно значение из строки протокола
+//
+//	<name>:<value>[:<value>...]|<type>[|@<sample rate>][|#<tag>:<value>,<tag>...]
+type Sample struct {
+	Name string
+	Kind Kind
+
+	// Values числовые значения (для set не заполняется)
+	Values []float64
+
+	// SetValues значения set (учитываются как строки)
+	SetValues []string
+
+	// Delta gauge со знаком "+" или "-" изменяет текущее значение, а не заменяет его
+	Delta bool
+
+	SampleRate float64
+	Tags       map[string]string
+}
+
+// ParseLine разбирает одну строку протокола StatsD/DogStatsD
+func ParseLine(line string) (Sample, error) {
+	if strings.HasPrefix(line, "_e{") || strings.HasPrefix(line, "_sc|") {
+		return Sample{}, ErrSkipped
+	}
+
+	nameEnd := strings.IndexByte(line, ':')
+	if nameEnd <= 0 {
+		return Sample{}, fmt.Errorf("missing metric name in %q", line)
+	}
+	sample := Sample{Name: line[:nameEnd], SampleRate: 1}
+
+	parts := strings.Split(line[nameEnd+1:], "|")
+	if len(parts) < 2 {
+		return Sample{}, fmt.Errorf("missing metric type in %q", line)
+	}
+
+	switch parts[1] {
+	case "c":
+		sample.Kind = KindCounter
+	case "g":
+		sample.Kind = KindGauge
+	case "ms", "h", "d":
+		// histogram и distribution DogStatsD агрегируются как таймеры
+		sample.Kind = KindTimer
+	case "s":
+		sample.Kind = KindSet
+	default:
+		return Sample{}, fmt.Errorf("unsupported metric type %q", parts[1])
+	}
+
+	for _, raw := range strings.Split(parts[0], ":") {
+		if raw == "" {
+			return Sample{}, fmt.Errorf("empty value in %q", line)
+		}
+		if sample.Kind == KindSet {
+			sample.SetValues = append(sample.SetValues, raw)
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return Sample{}, fmt.Errorf("invalid value %q", raw)
+		}
+		if sample.Kind == KindGauge && (raw[0] == '+' || raw[0] == '-') {
+			sample.Delta = true
+		}
+		sample.Values = append(sample.Values, value)
+	}
+
+	for _, part := range parts[2:] {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			rate, err := strconv.ParseFloat(part[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return Sample{}, fmt.Errorf("invalid sample rate %q", part)
+			}
+			sample.SampleRate = rate
+		case strings.HasPrefix(part, "#"):
+			sample.Tags = parseTags(part[1:])
+		}
+		// Остальные поля DogStatsD (c:<container>, T<timestamp>) игнорируются
+	}
+
+	return sample, nil
+}
+
+// parseTags разбирает теги DogStatsD; теги без значения пропускаются
+func parseTags(raw string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(raw, ",") {
+		name, value, ok := strings.Cut(tag, ":")
+		if !ok || name == "" || value == "" {
+			continue
+		}
+		tags[name] = value
+	}
+	return tags
+}
@@ -0,0 +1,199 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// maxPacketSize предел размера UDP-датаграммы
+const maxPacketSize = 65535
+
+// maxRetainedMetrics предел агрегатов неудачных сбросов, которые хранятся до следующего сброса
+// Сверх него отбрасываются самые старые агрегаты
+const maxRetainedMetrics = 50000
+
+// Sink принимает агрегированные метрики (реализуется usecase.CollectMetricsUseCase)
+type Sink interface {
+	IngestSeries(ctx context.Context, rawMetrics []port.RawMetric) (*dto.IngestMetricsResultDTO, error)
+}
+
+// ServerConfig настройки приемника
+type ServerConfig struct {
+	// Address UDP-адрес, например ":8125"
+	Address string
+
+	// FlushInterval окно агрегации; по его окончании агрегаты передаются в Sink
+	FlushInterval time.Duration
+
+	Aggregator AggregatorConfig
+}
+
+// Server принимает датаграммы StatsD (по строке на метрику) и раз в FlushInterval
+// передает агрегаты в конвейер сбора
+type Server struct {
+	config         ServerConfig
+	aggregator     *Aggregator
+	sink           Sink
+	serviceMetrics port.ServiceMetrics // Optional: counts aggregates dropped after failed flushes
+	logger         *logger.Logger
+
+	flushMu  sync.Mutex
+	retained []port.RawMetric // агрегаты окон, которые Sink не принял
+	dropped  atomic.Uint64
+
+	conn    net.PacketConn
+	readers sync.WaitGroup
+}
+
+// NewServer создает приемник; сокет открывается в Listen
+func NewServer(
+	config ServerConfig,
+	sink Sink,
+	serviceMetrics port.ServiceMetrics, // Can be nil if /metrics disabled
+	logger *logger.Logger,
+) *Server {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+
+	return &Server{
+		config:         config,
+		aggregator:     NewAggregator(config.Aggregator),
+		sink:           sink,
+		serviceMetrics: serviceMetrics,
+		logger:         logger,
+	}
+}
+
+// Listen открывает UDP-сокет
+func (s *Server) Listen() error {
+	conn, err := net.ListenPacket("udp", s.config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Addr адрес открытого сокета (nil до Listen)
+func (s *Server) Addr() net.Addr {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr()
+}
+
+// Run читает датаграммы и сбрасывает агрегаты до отмены ctx
+// Агрегаты последнего окна записываются вызовом Flush после остановки
+func (s *Server) Run(ctx context.Context) {
+	s.readers.Add(1)
+	go s.read()
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("Failed to ingest StatsD aggregates", err)
+			}
+		case <-ctx.Done():
+			_ = s.conn.Close()
+			s.readers.Wait()
+			return
+		}
+	}
+}
+
+// Flush передает агрегаты текущего окна в Sink
+// Если Sink вернул ошибку (например, буфер записи переполнен), агрегаты окна не теряются:
+// они отправляются следующим сбросом со временем своего окна. Храниться может не больше
+// maxRetainedMetrics агрегатов; более старые отбрасываются и учитываются в Dropped
+func (s *Server) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	rawMetrics, rejected := s.aggregator.Flush(time.Now())
+	rawMetrics = append(s.retained, rawMetrics...)
+	s.retained = nil
+	if len(rawMetrics) == 0 {
+		if rejected > 0 {
+			s.logger.Debug("StatsD values rejected", "rejected", rejected)
+		}
+		return nil
+	}
+
+	result, err := s.sink.IngestSeries(ctx, rawMetrics)
+	if err != nil {
+		s.retain(rawMetrics)
+		return err
+	}
+
+	s.logger.Debug("StatsD aggregates ingested",
+		"accepted", result.Accepted,
+		"rejected", rejected+result.Rejected,
+	)
+	return nil
+}
+
+// retain сохраняет агрегаты до следующего сброса
+func (s *Server) retain(rawMetrics []port.RawMetric) {
+	if excess := len(rawMetrics) - maxRetainedMetrics; excess > 0 {
+		s.dropped.Add(uint64(excess))
+		if s.serviceMetrics != nil {
+			for i := 0; i < excess; i++ {
+				s.serviceMetrics.MetricDropped(port.DropReasonStatsDRetention)
+			}
+		}
+		s.logger.Warn("StatsD aggregates dropped after failed flushes", "dropped", excess)
+		rawMetrics = rawMetrics[excess:]
+	}
+	s.retained = rawMetrics
+}
+
+// Dropped возвращает количество агрегатов, отброшенных с момента запуска из-за неудачных сбросов
+func (s *Server) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *Server) read() {
+	defer s.readers.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Warn("StatsD read failed", "error", err.Error())
+			continue
+		}
+
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			sample, err := ParseLine(line)
+			if err != nil {
+				if !errors.Is(err, ErrSkipped) {
+					s.logger.Debug("Invalid StatsD line", "remote_addr", addr.String(), "error", err.Error())
+				}
+				continue
+			}
+			s.aggregator.Add(sample)
+		}
+	}
+}
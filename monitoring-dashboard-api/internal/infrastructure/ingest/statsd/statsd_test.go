@@ -0,0 +1,271 @@
+package statsd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+func TestParseLine(t *testing.T) {
+	sample, err := ParseLine("app.requests:2|c|@0.5|#env:prod,region:eu,novalue")
+	if err != nil {
+		t.Fatalf("ParseLine() error = %v", err)
+	}
+	if sample.Name != "app.requests" || sample.Kind != KindCounter || sample.SampleRate != 0.5 || len(sample.Values) != 1 || sample.Values[0] != 2 {
+		t.Fatalf("unexpected counter: %+v", sample)
+	}
+	if len(sample.Tags) != 2 || sample.Tags["env"] != "prod" || sample.Tags["region"] != "eu" {
+		t.Fatalf("unexpected tags: %v", sample.Tags)
+	}
+
+	if sample, err = ParseLine("queue.depth:-3|g"); err != nil || !sample.Delta || sample.Values[0] != -3 {
+		t.Fatalf("gauge delta: %+v, %v", sample, err)
+	}
+	if sample, err = ParseLine("db.query:10:20:30|d"); err != nil || sample.Kind != KindTimer || len(sample.Values) != 3 {
+		t.Fatalf("packed distribution: %+v, %v", sample, err)
+	}
+	if sample, err = ParseLine("users.online:alice|s"); err != nil || sample.Kind != KindSet || sample.SetValues[0] != "alice" {
+		t.Fatalf("set: %+v, %v", sample, err)
+	}
+
+	if _, err := ParseLine("_e{5,4}:title|text"); !errors.Is(err, ErrSkipped) {
+		t.Fatalf("events must be skipped, got %v", err)
+	}
+	for _, line := range []string{"novalue", ":1|c", "name:1", "name:x|c", "name:1|x", "name:1|c|@2"} {
+		if _, err := ParseLine(line); err == nil {
+			t.Fatalf("ParseLine(%q) must fail", line)
+		}
+	}
+}
+
+func mustParse(t *testing.T, line string) Sample {
+	t.Helper()
+	sample, err := ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine(%q) error = %v", line, err)
+	}
+	return sample
+}
+
+func byName(rawMetrics []port.RawMetric) map[string]port.RawMetric {
+	result := make(map[string]port.RawMetric, len(rawMetrics))
+	for _, raw := range rawMetrics {
+		result[raw.Name] = raw
+	}
+	return result
+}
+
+func TestAggregatorFlushesWindow(t *testing.T) {
+	aggregator := NewAggregator(AggregatorConfig{DefaultType: valueobject.CPU, DefaultHost: "app-1"})
+
+	for _, line := range []string{
+		"app.requests:1|c|@0.5|#env:prod",
+		"app.requests:3|c|#env:prod",
+		"memory.used:100|g|#host:db-1,unit:MB",
+		"memory.used:+20|g|#host:db-1,unit:MB",
+		"app.latency:10:20:30:40|ms",
+		"app.users:alice|s",
+		"app.users:bob|s",
+		"app.users:alice|s",
+		"app.gpu:1|g|#type:gpu",
+	} {
+		aggregator.Add(mustParse(t, line))
+	}
+
+	at := time.Now()
+	rawMetrics, rejected := aggregator.Flush(at)
+	if rejected != 1 {
+		t.Fatalf("expected the unknown type to be rejected, got %d", rejected)
+	}
+	metrics := byName(rawMetrics)
+
+	counter := metrics["app_requests"]
+	if counter.Value.Raw() != 5 || counter.Host != "app-1" || counter.Labels["env"] != "prod" || counter.Type != valueobject.CPU || !counter.CollectedAt.Equal(at) {
+		t.Fatalf("unexpected counter: %+v", counter)
+	}
+	gauge := metrics["memory_used"]
+	if gauge.Value.Raw() != 120 || gauge.Value.Unit() != "MB" || gauge.Host != "db-1" || gauge.Type != valueobject.Memory || len(gauge.Labels) != 0 {
+		t.Fatalf("unexpected gauge: %+v", gauge)
+	}
+	for name, want := range map[string]float64{
+		"app_latency_count": 4, "app_latency_sum": 100, "app_latency_min": 10, "app_latency_max": 40,
+		"app_latency_avg": 25, "app_latency_p50": 20, "app_latency_p90": 40, "app_latency_p99": 40,
+	} {
+		if got := metrics[name].Value.Raw(); got != want {
+			t.Fatalf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if set := metrics["app_users"]; set.Value.Raw() != 2 {
+		t.Fatalf("unexpected set size: %+v", set)
+	}
+
+	// Gauge переживает окно, и изменение со знаком применяется к прежнему значению
+	aggregator.Add(mustParse(t, "memory.used:-70|g|#host:db-1,unit:MB"))
+	rawMetrics, _ = aggregator.Flush(at)
+	if len(rawMetrics) != 1 || rawMetrics[0].Value.Raw() != 50 {
+		t.Fatalf("expected only the updated gauge, got %+v", rawMetrics)
+	}
+
+	// Необновленный gauge не выдается и забывается
+	if rawMetrics, _ = aggregator.Flush(at); len(rawMetrics) != 0 {
+		t.Fatalf("expected empty window, got %+v", rawMetrics)
+	}
+}
+
+func TestAggregatorLimitsSeries(t *testing.T) {
+	aggregator := NewAggregator(AggregatorConfig{DefaultType: valueobject.CPU, MaxSeries: 1})
+	aggregator.Add(mustParse(t, "a:1|c"))
+	aggregator.Add(mustParse(t, "b:1|c"))
+	aggregator.Add(mustParse(t, "a:1|c"))
+
+	rawMetrics, rejected := aggregator.Flush(time.Now())
+	if len(rawMetrics) != 1 || rawMetrics[0].Value.Raw() != 2 || rejected != 1 {
+		t.Fatalf("expected one series and one rejected value, got %+v (rejected %d)", rawMetrics, rejected)
+	}
+}
+
+// recordingSink запоминает переданные метрики
+type recordingSink struct {
+	mu      sync.Mutex
+	metrics []port.RawMetric
+	err     error
+}
+
+func (s *recordingSink) IngestSeries(_ context.Context, rawMetrics []port.RawMetric) (*dto.IngestMetricsResultDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.metrics = append(s.metrics, rawMetrics...)
+	return &dto.IngestMetricsResultDTO{Received: len(rawMetrics), Accepted: len(rawMetrics)}, nil
+}
+
+func TestAggregatorPrefersLongestTypePrefix(t *testing.T) {
+	aggregator := NewAggregator(AggregatorConfig{})
+	for _, line := range []string{
+		"cpu.usage:10|g",
+		"cpu_core.usage:20|g",
+		"disk_io.read:5|g",
+		"network_recv.rate:7|g",
+	} {
+		aggregator.Add(mustParse(t, line))
+	}
+
+	rawMetrics, rejected := aggregator.Flush(time.Now())
+	if rejected != 0 {
+		t.Fatalf("expected no rejected values, got %d", rejected)
+	}
+	metrics := byName(rawMetrics)
+	for name, want := range map[string]valueobject.MetricType{
+		"cpu_usage":         valueobject.CPU,
+		"cpu_core_usage":    valueobject.CPUCore,
+		"disk_io_read":      valueobject.DiskIO,
+		"network_recv_rate": valueobject.NetworkRecv,
+	} {
+		if got := metrics[name].Type; got != want {
+			t.Fatalf("%s mapped to %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestServerKeepsAggregatesAfterFailedFlush(t *testing.T) {
+	sink := &recordingSink{err: errors.New("metric write buffer is full")}
+	server := NewServer(ServerConfig{Aggregator: AggregatorConfig{DefaultType: valueobject.CPU}}, sink, nil, logger.New("error"))
+
+	server.aggregator.Add(mustParse(t, "jobs.done:4|c"))
+	if err := server.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush error")
+	}
+
+	// Следующий сброс отправляет и прошлое окно, и новое
+	sink.mu.Lock()
+	sink.err = nil
+	sink.mu.Unlock()
+	server.aggregator.Add(mustParse(t, "jobs.done:1|c"))
+	if err := server.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	if len(sink.metrics) != 2 || sink.metrics[0].Value.Raw() != 4 || sink.metrics[1].Value.Raw() != 1 {
+		t.Fatalf("expected both windows in order, got %+v", sink.metrics)
+	}
+	if !sink.metrics[0].CollectedAt.Before(sink.metrics[1].CollectedAt) || server.Dropped() != 0 {
+		t.Fatalf("retained window must keep its time: %+v (dropped %d)", sink.metrics, server.Dropped())
+	}
+}
+
+func TestServerAggregatesUDPPackets(t *testing.T) {
+	sink := &recordingSink{}
+	server := NewServer(ServerConfig{
+		Address:       "127.0.0.1:0",
+		FlushInterval: time.Hour,
+		Aggregator:    AggregatorConfig{DefaultType: valueobject.CPU, DefaultHost: "legacy-1"},
+	}, sink, nil, logger.New("error"))
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Run(ctx)
+		close(done)
+	}()
+
+	conn, err := net.Dial("udp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	// Несколько строк в одной датаграмме и мусор между ними
+	if _, err := conn.Write([]byte("jobs.done:1|c\ngarbage\njobs.done:2|c\r\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := conn.Write([]byte("jobs.done:3|c")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// UDP доставляется асинхронно: ждем, пока оба пакета будут агрегированы
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.aggregator.mu.Lock()
+		var total float64
+		for _, s := range server.aggregator.series {
+			total = s.value
+		}
+		server.aggregator.mu.Unlock()
+		if total == 6 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for packets, counter = %v", total)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	<-done
+	if err := server.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.metrics) != 1 {
+		t.Fatalf("expected 1 aggregated metric, got %+v", sink.metrics)
+	}
+	if metric := sink.metrics[0]; metric.Name != "jobs_done" || metric.Value.Raw() != 6 || metric.Host != "legacy-1" {
+		t.Fatalf("unexpected metric: %+v", metric)
+	}
+}
@@ -6,19 +6,22 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
 	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
 	"github.com/nats-io/nats.go"
 )
 
 // NATSPublisher implements EventPublisher for NATS JetStream
 type NATSPublisher struct {
-	nc     *nats.Conn
-	js     nats.JetStreamContext
-	logger *logger.Logger
+	nc      *nats.Conn
+	js      nats.JetStreamContext
+	metrics port.ServiceMetrics // Optional: counts failed publishes
+	logger  *logger.Logger
 }
 
 // NewNATSPublisher creates a new NATS publisher
-func NewNATSPublisher(natsURL string, log *logger.Logger) (*NATSPublisher, error) {
+// serviceMetrics can be nil if /metrics is disabled
+func NewNATSPublisher(natsURL string, serviceMetrics port.ServiceMetrics, log *logger.Logger) (*NATSPublisher, error) {
 	// Connect to NATS with retry
 	nc, err := nats.Connect(natsURL,
 		nats.RetryOnFailedConnect(true),
@@ -37,8 +40,13 @@ func NewNATSPublisher(natsURL string, log *logger.Logger) (*NATSPublisher, error
 		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
 	}
 
-	// Get JetStream context
-	js, err := nc.JetStream()
+	// Get JetStream context; async publish failures are only reported here
+	js, err := nc.JetStream(nats.PublishAsyncErrHandler(func(_ nats.JetStream, msg *nats.Msg, err error) {
+		log.Warn("NATS async publish failed", "subject", msg.Subject, "error", err.Error())
+		if serviceMetrics != nil {
+			serviceMetrics.PublishFailed(port.PublishTargetNATS)
+		}
+	}))
 	if err != nil {
 		nc.Close()
 		return nil, fmt.Errorf("failed to get JetStream context: %w", err)
@@ -47,9 +55,10 @@ func NewNATSPublisher(natsURL string, log *logger.Logger) (*NATSPublisher, error
 	log.Info("Connected to NATS", "url", natsURL)
 
 	return &NATSPublisher{
-		nc:     nc,
-		js:     js,
-		logger: log,
+		nc:      nc,
+		js:      js,
+		metrics: serviceMetrics,
+		logger:  log,
 	}, nil
 }
 
@@ -64,6 +73,9 @@ func (p *NATSPublisher) PublishEvent(ctx context.Context, subject string, event
 	// Async publish (fire-and-forget for better performance)
 	_, err = p.js.PublishAsync(subject, data)
 	if err != nil {
+		if p.metrics != nil {
+			p.metrics.PublishFailed(port.PublishTargetNATS)
+		}
 		p.logger.Error("Failed to publish event", err,
 			"subject", subject,
 		)
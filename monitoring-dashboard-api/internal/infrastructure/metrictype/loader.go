@@ -0,0 +1,88 @@
+package metrictype
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// fileDefinition описание типа метрики в JSON-файле конфигурации
+type fileDefinition struct {
+	Name        string             `json:"name"`
+	DisplayName string             `json:"display_name"`
+	Description string             `json:"description"`
+	Units       []string           `json:"units"`
+	Thresholds  *fileThresholds    `json:"thresholds"`
+	MaxValues   map[string]float64 `json:"max_values"`
+}
+
+type fileThresholds struct {
+	Unit     string  `json:"unit"`
+	Warning  float64 `json:"warning"`
+	Critical float64 `json:"critical"`
+}
+
+// LoadFile читает описания типов метрик из JSON-файла (массив объектов)
+func LoadFile(path string) ([]valueobject.MetricTypeDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metric types file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse разбирает описания типов метрик из JSON
+func Parse(data []byte) ([]valueobject.MetricTypeDefinition, error) {
+	var items []fileDefinition
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse metric types: %w", err)
+	}
+
+	definitions := make([]valueobject.MetricTypeDefinition, 0, len(items))
+	for _, item := range items {
+		definition := valueobject.MetricTypeDefinition{
+			Type:        valueobject.MetricType(item.Name),
+			DisplayName: item.DisplayName,
+			Description: item.Description,
+			Units:       item.Units,
+			MaxValues:   item.MaxValues,
+		}
+		if item.Thresholds != nil {
+			definition.Thresholds = valueobject.Thresholds{
+				Unit:     item.Thresholds.Unit,
+				Warning:  item.Thresholds.Warning,
+				Critical: item.Thresholds.Critical,
+			}
+		}
+		if err := definition.Validate(); err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, nil
+}
+
+// Configure регистрирует типы из файла в реестре
+// Пустой путь оставляет только встроенные типы; типы из файла могут переопределять встроенные
+func Configure(registry *valueobject.MetricTypeRegistry, path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	definitions, err := LoadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, definition := range definitions {
+		if err := registry.Register(definition); err != nil {
+			return 0, fmt.Errorf("failed to register metric type %q: %w", definition.Type, err)
+		}
+	}
+
+	return len(definitions), nil
+}
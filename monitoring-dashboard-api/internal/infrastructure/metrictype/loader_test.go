@@ -0,0 +1,81 @@
+package metrictype
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid", `[{"name":"load_avg","units":["load"],"thresholds":{"unit":"load","warning":4,"critical":8}}]`, false},
+		{"no thresholds", `[{"name":"process_count","units":["count"]}]`, false},
+		{"invalid name", `[{"name":"Load-Avg","units":["load"]}]`, true},
+		{"missing units", `[{"name":"load_avg"}]`, true},
+		{"threshold unit not allowed", `[{"name":"load_avg","units":["load"],"thresholds":{"unit":"%","warning":4}}]`, true},
+		{"warning above critical", `[{"name":"load_avg","units":["load"],"thresholds":{"unit":"load","warning":9,"critical":8}}]`, true},
+		{"malformed json", `{"name":"load_avg"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metric_types.json")
+	content := `[
+		{"name":"temperature","display_name":"CPU Temperature","units":["C"],"thresholds":{"unit":"C","warning":70,"critical":85},"max_values":{"C":150}},
+		{"name":"cpu","units":["%"],"thresholds":{"unit":"%","warning":60,"critical":80}}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	registry, err := valueobject.NewMetricTypeRegistry(valueobject.BuiltinMetricTypes()...)
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	count, err := Configure(registry, path)
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 registered types, got %d", count)
+	}
+
+	temperature, ok := registry.Lookup("temperature")
+	if !ok {
+		t.Fatal("expected temperature type to be registered")
+	}
+	if !temperature.IsCritical(90, "C") || temperature.IsWarning(60, "C") || temperature.IsReasonable(200, "C") {
+		t.Fatalf("unexpected temperature thresholds: %+v", temperature)
+	}
+
+	cpu, _ := registry.Lookup(valueobject.CPU)
+	if !cpu.IsCritical(85, "%") {
+		t.Fatal("expected cpu thresholds to be overridden")
+	}
+
+	types := registry.Types()
+	builtin := len(valueobject.BuiltinMetricTypes())
+	if len(types) != builtin+1 || types[builtin] != "temperature" {
+		t.Fatalf("unexpected registry order: %v", types)
+	}
+
+	if count, err := Configure(registry, ""); err != nil || count != 0 {
+		t.Fatalf("expected empty path to be a no-op, got %d, %v", count, err)
+	}
+}
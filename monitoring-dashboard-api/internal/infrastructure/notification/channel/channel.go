@@ -0,0 +1,137 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// maxErrorBodyBytes ограничивает часть тела ответа, попадающую в текст ошибки
+const maxErrorBodyBytes = 512
+
+// base общие свойства каналов: имя и минимальный уровень алертов
+type base struct {
+	name        string
+	minSeverity valueobject.AlertSeverity
+}
+
+// Name возвращает имя канала
+func (b base) Name() string {
+	return b.name
+}
+
+// MinSeverity возвращает минимальный уровень алертов канала
+func (b base) MinSeverity() valueobject.AlertSeverity {
+	return b.minSeverity
+}
+
+// postJSON отправляет JSON и проверяет код ответа
+// Ответы 4xx (кроме 408 и 429) считаются окончательным отказом
+func postJSON(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	body []byte,
+	headers map[string]string,
+) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to build request: %v", port.ErrNotificationRejected, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return respBody, statusError(resp.StatusCode, respBody)
+	}
+	return respBody, nil
+}
+
+// statusError формирует ошибку по коду ответа
+func statusError(status int, body []byte) error {
+	snippet := strings.TrimSpace(string(body))
+	if len(snippet) > maxErrorBodyBytes {
+		snippet = snippet[:maxErrorBodyBytes]
+	}
+
+	err := fmt.Errorf("unexpected status %d: %s", status, snippet)
+	if isPermanentStatus(status) {
+		return fmt.Errorf("%w: %v", port.ErrNotificationRejected, err)
+	}
+	return err
+}
+
+func isPermanentStatus(status int) bool {
+	return status >= 400 && status < 500 &&
+		status != http.StatusRequestTimeout &&
+		status != http.StatusTooManyRequests
+}
+
+// formatSubject возвращает заголовок уведомления, например "[CRITICAL] FIRING: High CPU on web-1"
+func formatSubject(alert *dto.AlertDTO) string {
+	state := "ALERT"
+	if alert.State != "" {
+		state = strings.ToUpper(alert.State)
+	}
+
+	title := alert.RuleName
+	if title == "" {
+		title = "alert"
+	}
+	if alert.Host != "" {
+		title += " on " + alert.Host
+	}
+
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(alert.Level), state, title)
+}
+
+// formatText возвращает текст уведомления для чатов и email
+func formatText(alert *dto.AlertDTO) string {
+	var b strings.Builder
+	b.WriteString(formatSubject(alert))
+	b.WriteString("\n")
+	b.WriteString(alert.Message)
+	fmt.Fprintf(&b, "\nValue: %g (threshold %g)", alert.Value, alert.Threshold)
+	if len(alert.Labels) > 0 {
+		b.WriteString("\nLabels: ")
+		b.WriteString(formatLabels(alert.Labels))
+	}
+	fmt.Fprintf(&b, "\nTime: %s", alert.Timestamp.UTC().Format("2006-01-02 15:04:05 MST"))
+	return b.String()
+}
+
+// formatLabels возвращает метки в виде "k1=v1, k2=v2" в порядке имен
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + labels[name]
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,309 @@
+package channel
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+func testAlert() *dto.AlertDTO {
+	return &dto.AlertDTO{
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Level:     "critical",
+		State:     "firing",
+		RuleID:    "rule-1",
+		RuleName:  "High CPU",
+		Host:      "web-1",
+		Labels:    map[string]string{"core": "0"},
+		Value:     97.5,
+		Threshold: 90,
+		Message:   "avg(cpu) over 1m0s > 90 on web-1 (value 97.50)",
+	}
+}
+
+func TestWebhookChannelSignsPayload(t *testing.T) {
+	var (
+		gotBody    []byte
+		gotHeaders http.Header
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeaders = r.Header.Clone()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	channel := NewWebhookChannel("ops", server.URL, "s3cret", map[string]string{"X-Team": "sre"}, Options{})
+	channel.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	if err := channel.Send(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got := gotHeaders.Get(TimestampHeader); got != "1700000000" {
+		t.Fatalf("timestamp header = %q", got)
+	}
+	if got, want := gotHeaders.Get(SignatureHeader), Sign("s3cret", "1700000000", gotBody); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	if got := gotHeaders.Get("X-Team"); got != "sre" {
+		t.Fatalf("custom header = %q", got)
+	}
+
+	var alert dto.AlertDTO
+	if err := json.Unmarshal(gotBody, &alert); err != nil {
+		t.Fatalf("body is not an alert: %v", err)
+	}
+	if alert.RuleName != "High CPU" || alert.Host != "web-1" {
+		t.Fatalf("unexpected payload: %+v", alert)
+	}
+}
+
+func TestWebhookChannelErrorClassification(t *testing.T) {
+	tests := []struct {
+		status       int
+		wantRejected bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusNotFound, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusRequestTimeout, false},
+		{http.StatusBadGateway, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", tt.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookChannel("ops", server.URL, "", nil, Options{}).Send(context.Background(), testAlert())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := errors.Is(err, port.ErrNotificationRejected); got != tt.wantRejected {
+				t.Fatalf("rejected = %v, want %v (err: %v)", got, tt.wantRejected, err)
+			}
+		})
+	}
+}
+
+func TestSlackChannel(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	if err := NewSlackChannel("slack-ops", server.URL, Options{}).Send(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	text := payload["text"]
+	if !strings.HasPrefix(text, "[CRITICAL] FIRING: High CPU on web-1") {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if !strings.Contains(text, "Labels: core=0") {
+		t.Fatalf("labels missing from text: %q", text)
+	}
+}
+
+func TestTelegramChannel(t *testing.T) {
+	var (
+		gotPath string
+		payload map[string]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["chat_id"] == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	channel := NewTelegramChannel("tg", server.URL, "123:abc", "-100", Options{})
+	if err := channel.Send(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if gotPath != "/bot123:abc/sendMessage" {
+		t.Fatalf("path = %q", gotPath)
+	}
+	if payload["chat_id"] != "-100" || !strings.Contains(payload["text"], "High CPU") {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+
+	err := NewTelegramChannel("tg", server.URL, "123:abc", "bad", Options{}).Send(context.Background(), testAlert())
+	if !errors.Is(err, port.ErrNotificationRejected) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+}
+
+func TestTelegramChannelRedactsToken(t *testing.T) {
+	// Закрытый сервер: ошибка подключения содержит URL с токеном
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := NewTelegramChannel("tg", server.URL, "123:secret-token", "-100", Options{}).Send(context.Background(), testAlert())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("error leaks bot token: %v", err)
+	}
+}
+
+// fakeSMTPServer минимальный SMTP-сервер для тестов
+type fakeSMTPServer struct {
+	listener net.Listener
+	rejectTo string
+
+	mu         sync.Mutex
+	from       string
+	recipients []string
+	data       string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP fake")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if to == s.rejectTo {
+				reply("550 No such user")
+				continue
+			}
+			s.mu.Lock()
+			s.recipients = append(s.recipients, to)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPChannel(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectTo = "ghost@example.com"
+
+	config := SMTPConfig{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "monitoring@example.com",
+		To:   []string{"oncall@example.com", "sre@example.com"},
+		TLS:  SMTPTLSNone,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := NewSMTPChannel("email", config, Options{}).Send(ctx, testAlert()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	server.mu.Lock()
+	from, recipients, data := server.from, server.recipients, server.data
+	server.mu.Unlock()
+
+	if from != "monitoring@example.com" {
+		t.Fatalf("from = %q", from)
+	}
+	if len(recipients) != 2 {
+		t.Fatalf("recipients = %v", recipients)
+	}
+	if !strings.Contains(data, "Subject: [CRITICAL] FIRING: High CPU on web-1\r\n") {
+		t.Fatalf("subject missing from message:\n%s", data)
+	}
+	if !strings.Contains(data, "Value: 97.5 (threshold 90)") {
+		t.Fatalf("body missing from message:\n%s", data)
+	}
+
+	config.To = []string{"ghost@example.com"}
+	err := NewSMTPChannel("email", config, Options{}).Send(ctx, testAlert())
+	if !errors.Is(err, port.ErrNotificationRejected) {
+		t.Fatalf("expected rejection for unknown recipient, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package channel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// Типы каналов в файле конфигурации
+const (
+	TypeWebhook  = "webhook"
+	TypeSlack    = "slack"
+	TypeTelegram = "telegram"
+	TypeSMTP     = "smtp"
+)
+
+// Options общие параметры каналов
+type Options struct {
+	MinSeverity valueobject.AlertSeverity
+	HTTPClient  *http.Client // nil - http.DefaultClient (таймаут задает контекст попытки)
+}
+
+func (o Options) httpClient() *http.Client {
+	if o.HTTPClient != nil {
+		return o.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// Config каналы, загруженные из файла
+type Config struct {
+	DefaultChannels []string
+	Channels        []port.NotificationChannel
+}
+
+// fileConfig формат JSON-файла каналов
+type fileConfig struct {
+	DefaultChannels []string      `json:"default_channels"`
+	Channels        []fileChannel `json:"channels"`
+}
+
+type fileChannel struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	MinSeverity string `json:"min_severity"`
+
+	// webhook, slack
+	URL     string            `json:"url"`
+	Secret  string            `json:"secret"`
+	Headers map[string]string `json:"headers"`
+
+	// telegram
+	BotToken string `json:"bot_token"`
+	ChatID   string `json:"chat_id"`
+	APIURL   string `json:"api_url"`
+
+	// smtp
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	TLS      string   `json:"tls"`
+}
+
+// LoadFile читает описание каналов из JSON-файла
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification channels file: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse разбирает описание каналов из JSON
+// Секреты (url, secret, headers, bot_token, username, password) могут ссылаться на переменные окружения: "${SLACK_WEBHOOK_URL}"
+func Parse(data []byte) (*Config, error) {
+	var file fileConfig
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse notification channels: %w", err)
+	}
+
+	config := &Config{Channels: make([]port.NotificationChannel, 0, len(file.Channels))}
+	names := make(map[string]struct{}, len(file.Channels))
+
+	for _, item := range file.Channels {
+		channel, err := buildChannel(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification channel %q: %w", item.Name, err)
+		}
+		if _, ok := names[item.Name]; ok {
+			return nil, fmt.Errorf("duplicate notification channel %q", item.Name)
+		}
+		names[item.Name] = struct{}{}
+		config.Channels = append(config.Channels, channel)
+	}
+
+	for _, name := range file.DefaultChannels {
+		if _, ok := names[name]; !ok {
+			return nil, fmt.Errorf("default channel %q is not defined", name)
+		}
+		config.DefaultChannels = append(config.DefaultChannels, name)
+	}
+
+	return config, nil
+}
+
+func buildChannel(item fileChannel) (port.NotificationChannel, error) {
+	if err := entity.ValidateChannelName(item.Name); err != nil {
+		return nil, err
+	}
+
+	opts := Options{MinSeverity: valueobject.AlertSeverity(item.MinSeverity)}
+	if opts.MinSeverity != "" {
+		if err := opts.MinSeverity.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch item.Type {
+	case TypeWebhook:
+		target := os.ExpandEnv(item.URL)
+		if err := validateURL(target); err != nil {
+			return nil, err
+		}
+		headers := make(map[string]string, len(item.Headers))
+		for key, value := range item.Headers {
+			headers[key] = os.ExpandEnv(value)
+		}
+		return NewWebhookChannel(item.Name, target, os.ExpandEnv(item.Secret), headers, opts), nil
+
+	case TypeSlack:
+		target := os.ExpandEnv(item.URL)
+		if err := validateURL(target); err != nil {
+			return nil, err
+		}
+		return NewSlackChannel(item.Name, target, opts), nil
+
+	case TypeTelegram:
+		token := os.ExpandEnv(item.BotToken)
+		if token == "" || item.ChatID == "" {
+			return nil, fmt.Errorf("bot_token and chat_id are required")
+		}
+		if item.APIURL != "" {
+			if err := validateURL(item.APIURL); err != nil {
+				return nil, err
+			}
+		}
+		return NewTelegramChannel(item.Name, item.APIURL, token, item.ChatID, opts), nil
+
+	case TypeSMTP:
+		config := SMTPConfig{
+			Host:     item.Host,
+			Port:     item.Port,
+			Username: os.ExpandEnv(item.Username),
+			Password: os.ExpandEnv(item.Password),
+			From:     item.From,
+			To:       item.To,
+			TLS:      item.TLS,
+		}
+		if err := validateSMTPConfig(config); err != nil {
+			return nil, err
+		}
+		return NewSMTPChannel(item.Name, config, opts), nil
+
+	default:
+		return nil, fmt.Errorf("unknown channel type %q", item.Type)
+	}
+}
+
+func validateURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	return nil
+}
+
+func validateSMTPConfig(config SMTPConfig) error {
+	if config.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if config.Port <= 0 || config.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if config.From == "" || len(config.To) == 0 {
+		return fmt.Errorf("from and to are required")
+	}
+	for _, address := range append([]string{config.From}, config.To...) {
+		if strings.ContainsAny(address, "\r\n") {
+			return fmt.Errorf("invalid address %q", address)
+		}
+	}
+	switch config.TLS {
+	case "", SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit:
+		return nil
+	default:
+		return fmt.Errorf("tls must be one of none, starttls, tls")
+	}
+}
@@ -0,0 +1,58 @@
+package channel
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"webhook", `{"channels":[{"name":"ops","type":"webhook","url":"https://example.com/hook","secret":"x"}]}`, false},
+		{"slack", `{"channels":[{"name":"slack","type":"slack","url":"https://hooks.slack.com/services/T/B/X"}]}`, false},
+		{"telegram", `{"channels":[{"name":"tg","type":"telegram","bot_token":"1:a","chat_id":"-100"}]}`, false},
+		{"smtp", `{"channels":[{"name":"mail","type":"smtp","host":"smtp.example.com","port":587,"from":"a@example.com","to":["b@example.com"]}]}`, false},
+		{"default channels", `{"default_channels":["ops"],"channels":[{"name":"ops","type":"slack","url":"https://example.com"}]}`, false},
+		{"unknown default", `{"default_channels":["nope"],"channels":[]}`, true},
+		{"unknown type", `{"channels":[{"name":"ops","type":"pager"}]}`, true},
+		{"invalid name", `{"channels":[{"name":"Ops!","type":"slack","url":"https://example.com"}]}`, true},
+		{"duplicate name", `{"channels":[{"name":"ops","type":"slack","url":"https://a.example.com"},{"name":"ops","type":"slack","url":"https://b.example.com"}]}`, true},
+		{"relative url", `{"channels":[{"name":"ops","type":"webhook","url":"/hook"}]}`, true},
+		{"invalid severity", `{"channels":[{"name":"ops","type":"slack","url":"https://example.com","min_severity":"urgent"}]}`, true},
+		{"telegram without chat", `{"channels":[{"name":"tg","type":"telegram","bot_token":"1:a"}]}`, true},
+		{"smtp without port", `{"channels":[{"name":"mail","type":"smtp","host":"smtp.example.com","from":"a@example.com","to":["b@example.com"]}]}`, true},
+		{"smtp invalid tls", `{"channels":[{"name":"mail","type":"smtp","host":"h","port":25,"from":"a@example.com","to":["b@example.com"],"tls":"ssl"}]}`, true},
+		{"malformed json", `[]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseExpandsEnv(t *testing.T) {
+	t.Setenv("TEST_SLACK_URL", "https://hooks.example.com/abc")
+
+	config, err := Parse([]byte(`{"channels":[{"name":"slack","type":"slack","url":"${TEST_SLACK_URL}","min_severity":"critical"}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	slack, ok := config.Channels[0].(*SlackChannel)
+	if !ok {
+		t.Fatalf("unexpected channel type %T", config.Channels[0])
+	}
+	if slack.webhookURL != "https://hooks.example.com/abc" {
+		t.Fatalf("url = %q", slack.webhookURL)
+	}
+	if slack.MinSeverity() != "critical" {
+		t.Fatalf("min severity = %q", slack.MinSeverity())
+	}
+}
@@ -0,0 +1,42 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+// SlackChannel отправляет алерт в Slack через incoming webhook
+type SlackChannel struct {
+	base
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlackChannel создает канал Slack
+func NewSlackChannel(name, webhookURL string, opts Options) *SlackChannel {
+	return &SlackChannel{
+		base:       base{name: name, minSeverity: opts.MinSeverity},
+		webhookURL: webhookURL,
+		client:     opts.httpClient(),
+	}
+}
+
+// Type возвращает тип канала
+func (c *SlackChannel) Type() string {
+	return TypeSlack
+}
+
+// Send отправляет алерт
+func (c *SlackChannel) Send(ctx context.Context, alert *dto.AlertDTO) error {
+	body, err := json.Marshal(map[string]string{"text": formatText(alert)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	_, err = postJSON(ctx, c.client, c.webhookURL, body, nil)
+	return err
+}
@@ -0,0 +1,156 @@
+package channel
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+// Режимы шифрования SMTP
+const (
+	SMTPTLSNone     = "none"     // без шифрования
+	SMTPTLSStartTLS = "starttls" // STARTTLS после подключения
+	SMTPTLSImplicit = "tls"      // TLS с момента подключения (обычно порт 465)
+)
+
+// SMTPConfig параметры подключения к SMTP-серверу
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Пустой - без аутентификации
+	Password string
+	From     string
+	To       []string
+	TLS      string
+}
+
+// SMTPChannel отправляет алерт письмом через SMTP
+type SMTPChannel struct {
+	base
+	config SMTPConfig
+	now    func() time.Time
+}
+
+// NewSMTPChannel создает канал email
+func NewSMTPChannel(name string, config SMTPConfig, opts Options) *SMTPChannel {
+	if config.TLS == "" {
+		config.TLS = SMTPTLSStartTLS
+	}
+
+	return &SMTPChannel{
+		base:   base{name: name, minSeverity: opts.MinSeverity},
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Type возвращает тип канала
+func (c *SMTPChannel) Type() string {
+	return TypeSMTP
+}
+
+// Send отправляет алерт
+func (c *SMTPChannel) Send(ctx context.Context, alert *dto.AlertDTO) error {
+	err := c.send(ctx, c.buildMessage(alert))
+	if err == nil {
+		return nil
+	}
+
+	// Коды 5xx - окончательный отказ сервера (неверный адрес, аутентификация)
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return fmt.Errorf("%w: %v", port.ErrNotificationRejected, err)
+	}
+	return err
+}
+
+func (c *SMTPChannel) send(ctx context.Context, message []byte) error {
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	tlsConfig := &tls.Config{ServerName: c.config.Host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.config.TLS == SMTPTLSImplicit {
+		dialer := &tls.Dialer{Config: tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if c.config.TLS == SMTPTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if c.config.Username != "" {
+		auth := smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, to := range c.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", to, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage формирует письмо в формате RFC 5322
+func (c *SMTPChannel) buildMessage(alert *dto.AlertDTO) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + c.config.From + "\r\n")
+	b.WriteString("To: " + strings.Join(c.config.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", formatSubject(alert)) + "\r\n")
+	b.WriteString("Date: " + c.now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(formatText(alert), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
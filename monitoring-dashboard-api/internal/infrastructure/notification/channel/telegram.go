@@ -0,0 +1,98 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+)
+
+// DefaultTelegramAPIURL адрес Telegram Bot API
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramChannel отправляет алерт в чат через Telegram Bot API (sendMessage)
+type TelegramChannel struct {
+	base
+	apiURL   string
+	botToken string
+	chatID   string
+	client   *http.Client
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// NewTelegramChannel создает канал Telegram
+// apiURL можно переопределить для тестов или прокси; пустой - DefaultTelegramAPIURL
+func NewTelegramChannel(name, apiURL, botToken, chatID string, opts Options) *TelegramChannel {
+	if apiURL == "" {
+		apiURL = DefaultTelegramAPIURL
+	}
+
+	return &TelegramChannel{
+		base:     base{name: name, minSeverity: opts.MinSeverity},
+		apiURL:   strings.TrimRight(apiURL, "/"),
+		botToken: botToken,
+		chatID:   chatID,
+		client:   opts.httpClient(),
+	}
+}
+
+// Type возвращает тип канала
+func (c *TelegramChannel) Type() string {
+	return TypeTelegram
+}
+
+// Send отправляет алерт
+func (c *TelegramChannel) Send(ctx context.Context, alert *dto.AlertDTO) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": c.chatID,
+		"text":    formatText(alert),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal telegram message: %w", err)
+	}
+
+	url := c.apiURL + "/bot" + c.botToken + "/sendMessage"
+	respBody, err := postJSON(ctx, c.client, url, body, nil)
+	if err != nil {
+		// URL содержит токен бота, поэтому в ошибку он не попадает
+		return redactToken(err, c.botToken)
+	}
+
+	var resp telegramResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("failed to decode telegram response: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("%w: telegram API error: %s", port.ErrNotificationRejected, resp.Description)
+	}
+	return nil
+}
+
+// redactToken убирает токен из текста ошибки, сохраняя цепочку для errors.Is
+func redactToken(err error, token string) error {
+	if token == "" || !strings.Contains(err.Error(), token) {
+		return err
+	}
+	return &redactedError{message: strings.ReplaceAll(err.Error(), token, "<redacted>"), err: err}
+}
+
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,81 @@
+package channel
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+const (
+	// TimestampHeader содержит Unix-время отправки, входящее в подпись
+	TimestampHeader = "X-Monitoring-Timestamp"
+
+	// SignatureHeader содержит подпись "sha256=<hex>" от HMAC-SHA256(secret, timestamp + "." + body)
+	SignatureHeader = "X-Monitoring-Signature"
+)
+
+// WebhookChannel отправляет алерт в формате JSON на произвольный URL
+// Если задан секрет, запрос подписывается HMAC-SHA256
+type WebhookChannel struct {
+	base
+	url     string
+	secret  string
+	headers map[string]string
+	client  *http.Client
+	now     func() time.Time
+}
+
+// NewWebhookChannel создает канал webhook
+func NewWebhookChannel(name, url, secret string, headers map[string]string, opts Options) *WebhookChannel {
+	return &WebhookChannel{
+		base:    base{name: name, minSeverity: opts.MinSeverity},
+		url:     url,
+		secret:  secret,
+		headers: headers,
+		client:  opts.httpClient(),
+		now:     time.Now,
+	}
+}
+
+// Type возвращает тип канала
+func (c *WebhookChannel) Type() string {
+	return TypeWebhook
+}
+
+// Send отправляет алерт
+func (c *WebhookChannel) Send(ctx context.Context, alert *dto.AlertDTO) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	headers := make(map[string]string, len(c.headers)+2)
+	for key, value := range c.headers {
+		headers[key] = value
+	}
+	if c.secret != "" {
+		timestamp := strconv.FormatInt(c.now().Unix(), 10)
+		headers[TimestampHeader] = timestamp
+		headers[SignatureHeader] = Sign(c.secret, timestamp, body)
+	}
+
+	_, err = postJSON(ctx, c.client, c.url, body, headers)
+	return err
+}
+
+// Sign вычисляет подпись webhook: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -32,20 +32,30 @@ type Client struct {
 	// Канал для отправки сообщений
 	send chan Message
 
+	// Хост, на метрики которого подписан клиент (пустой - все хосты)
+	host string
+
 	// Logger
 	logger *logger.Logger
 }
 
 // NewClient создает нового WebSocket клиента
-func NewClient(hub *Hub, conn *websocket.Conn, logger *logger.Logger) *Client {
+// host ограничивает рассылку метриками одного хоста (пустой - все хосты)
+func NewClient(hub *Hub, conn *websocket.Conn, host string, logger *logger.Logger) *Client {
 	return &Client{
 		conn:   conn,
 		hub:    hub,
 		send:   make(chan Message, 256),
+		host:   host,
 		logger: logger,
 	}
 }
 
+// accepts проверяет, подписан ли клиент на сообщения указанного хоста
+func (c *Client) accepts(host string) bool {
+	return c.host == "" || c.host == host
+}
+
 // ReadPump читает сообщения от клиента
 // Запускается в отдельной goroutine
 func (c *Client) ReadPump() {
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
 	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
@@ -19,6 +20,12 @@ type Hub struct {
 	// Канал для broadcast alerts
 	broadcastAlert chan *dto.AlertDTO
 
+	// Канал для broadcast изменений инцидентов
+	broadcastIncident chan *dto.IncidentDTO
+
+	// Канал для broadcast снимков процессов
+	broadcastProcesses chan *dto.TopProcessesDTO
+
 	// Канал для регистрации клиентов
 	register chan *Client
 
@@ -28,6 +35,9 @@ type Hub struct {
 	// Mutex для защиты clients map
 	mu sync.RWMutex
 
+	// Количество snapshot'ов, отброшенных из-за переполненного канала broadcast
+	droppedSnapshots atomic.Uint64
+
 	// Logger
 	logger *logger.Logger
 }
@@ -35,12 +45,14 @@ type Hub struct {
 // NewHub создает новый WebSocket hub
 func NewHub(logger *logger.Logger) *Hub {
 	return &Hub{
-		clients:        make(map[*Client]bool),
-		broadcast:      make(chan *dto.MetricSnapshotDTO, 256),
-		broadcastAlert: make(chan *dto.AlertDTO, 256),
-		register:       make(chan *Client),
-		unregister:     make(chan *Client),
-		logger:         logger,
+		clients:            make(map[*Client]bool),
+		broadcast:          make(chan *dto.MetricSnapshotDTO, 256),
+		broadcastAlert:     make(chan *dto.AlertDTO, 256),
+		broadcastIncident:  make(chan *dto.IncidentDTO, 256),
+		broadcastProcesses: make(chan *dto.TopProcessesDTO, 16),
+		register:           make(chan *Client),
+		unregister:         make(chan *Client),
+		logger:             logger,
 	}
 }
 
@@ -68,6 +80,9 @@ func (h *Hub) Run() {
 		case snapshot := <-h.broadcast:
 			h.mu.RLock()
 			for client := range h.clients {
+				if !client.accepts(snapshot.Host) {
+					continue
+				}
 				select {
 				case client.send <- Message{Type: "snapshot", Data: snapshot}:
 					// Сообщение отправлено
@@ -83,6 +98,9 @@ func (h *Hub) Run() {
 		case alert := <-h.broadcastAlert:
 			h.mu.RLock()
 			for client := range h.clients {
+				if !client.accepts(alert.Host) {
+					continue
+				}
 				select {
 				case client.send <- Message{Type: "alert", Data: alert}:
 					// Alert отправлен
@@ -93,6 +111,39 @@ func (h *Hub) Run() {
 			}
 			h.mu.RUnlock()
 			h.logger.Debug("Alert broadcasted to clients", "level", alert.Level)
+
+		case incident := <-h.broadcastIncident:
+			h.mu.RLock()
+			for client := range h.clients {
+				if !client.accepts(incident.Host) {
+					continue
+				}
+				select {
+				case client.send <- Message{Type: "incident", Data: incident}:
+					// Инцидент отправлен
+				default:
+					close(client.send)
+					delete(h.clients, client)
+				}
+			}
+			h.mu.RUnlock()
+			h.logger.Debug("Incident broadcasted to clients", "id", incident.ID, "status", incident.Status)
+
+		case processes := <-h.broadcastProcesses:
+			h.mu.RLock()
+			for client := range h.clients {
+				if !client.accepts(processes.Host) {
+					continue
+				}
+				select {
+				case client.send <- Message{Type: "processes", Data: processes}:
+					// Снимок процессов отправлен
+				default:
+					close(client.send)
+					delete(h.clients, client)
+				}
+			}
+			h.mu.RUnlock()
 		}
 	}
 }
@@ -113,6 +164,7 @@ func (h *Hub) Broadcast(snapshot *dto.MetricSnapshotDTO) {
 	case h.broadcast <- snapshot:
 		// Snapshot отправлен в канал
 	default:
+		h.droppedSnapshots.Add(1)
 		h.logger.Warn("Broadcast channel full, dropping snapshot")
 	}
 }
@@ -127,6 +179,27 @@ func (h *Hub) BroadcastAlert(alert *dto.AlertDTO) {
 	}
 }
 
+// BroadcastIncident отправляет изменение инцидента всем клиентам (реализация port.NotificationService)
+func (h *Hub) BroadcastIncident(incident *dto.IncidentDTO) {
+	select {
+	case h.broadcastIncident <- incident:
+		// Инцидент отправлен в канал
+	default:
+		h.logger.Warn("Broadcast incident channel full, dropping incident")
+	}
+}
+
+// BroadcastProcesses отправляет снимок процессов всем клиентам (реализация port.NotificationService)
+// Снимок устаревает к следующему циклу, поэтому при переполненном канале просто отбрасывается
+func (h *Hub) BroadcastProcesses(processes *dto.TopProcessesDTO) {
+	select {
+	case h.broadcastProcesses <- processes:
+		// Снимок отправлен в канал
+	default:
+		h.logger.Warn("Broadcast processes channel full, dropping processes snapshot")
+	}
+}
+
 // ClientCount возвращает количество подключенных клиентов (реализация port.NotificationService)
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
@@ -134,8 +207,13 @@ func (h *Hub) ClientCount() int {
 	return len(h.clients)
 }
 
+// DroppedSnapshots возвращает количество snapshot'ов, отброшенных с момента запуска
+func (h *Hub) DroppedSnapshots() uint64 {
+	return h.droppedSnapshots.Load()
+}
+
 // Message представляет сообщение для отправки клиенту
 type Message struct {
-	Type string      `json:"type"` // "snapshot" или "alert"
+	Type string      `json:"type"` // "snapshot", "alert", "incident" или "processes"
 	Data interface{} `json:"data"`
 }
@@ -33,6 +33,9 @@ type LogsPublisherConfig struct {
 	BufferSize      int    // Buffer size before auto-flush
 	FlushInterval   time.Duration
 	AutoCreate      bool // Automatically create log group/stream if missing
+
+	// ServiceMetrics counts failed publishes (optional)
+	ServiceMetrics applicationPort.ServiceMetrics
 }
 
 // LogsPublisher publishes logs to AWS CloudWatch Logs.
@@ -48,6 +51,8 @@ type LogsPublisher struct {
 
 	sequenceToken *string // CloudWatch requires sequence tokens for ordering
 
+	serviceMetrics applicationPort.ServiceMetrics
+
 	flushTicker *time.Ticker
 	stopCh      chan struct{}
 	wg          sync.WaitGroup
@@ -81,14 +86,15 @@ func NewLogsPublisher(ctx context.Context, cfg LogsPublisherConfig) (*LogsPublis
 	client := cloudwatchlogs.NewFromConfig(awsCfg)
 
 	p := &LogsPublisher{
-		client:        client,
-		logGroupName:  cfg.LogGroupName,
-		logStreamName: cfg.LogStreamName,
-		autoCreate:    cfg.AutoCreate,
-		buffer:        make([]applicationPort.LogEntry, 0, cfg.BufferSize),
-		bufferSize:    cfg.BufferSize,
-		flushTicker:   time.NewTicker(cfg.FlushInterval),
-		stopCh:        make(chan struct{}),
+		client:         client,
+		logGroupName:   cfg.LogGroupName,
+		logStreamName:  cfg.LogStreamName,
+		autoCreate:     cfg.AutoCreate,
+		serviceMetrics: cfg.ServiceMetrics,
+		buffer:         make([]applicationPort.LogEntry, 0, cfg.BufferSize),
+		bufferSize:     cfg.BufferSize,
+		flushTicker:    time.NewTicker(cfg.FlushInterval),
+		stopCh:         make(chan struct{}),
 	}
 
 	// Ensure log group and stream exist if auto-create is enabled
@@ -217,6 +223,9 @@ func (p *LogsPublisher) flushBufferUnsafe(ctx context.Context) error {
 
 		chunk := events[i:end]
 		if err := p.publishLogEventsWithRetry(ctx, chunk); err != nil {
+			if p.serviceMetrics != nil {
+				p.serviceMetrics.PublishFailed(applicationPort.PublishTargetCloudWatchLogs)
+			}
 			return fmt.Errorf("failed to publish chunk: %w", err)
 		}
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
+	applicationPort "github.com/dreschagin/monitoring-dashboard/internal/application/port"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
 )
 
@@ -33,6 +34,9 @@ type MetricsPublisherConfig struct {
 	BufferSize        int               // Buffer size before auto-flush
 	FlushInterval     time.Duration     // Automatic flush interval
 	StorageResolution int32             // Storage resolution in seconds (1 or 60)
+
+	// ServiceMetrics counts failed publishes (optional)
+	ServiceMetrics applicationPort.ServiceMetrics
 }
 
 // MetricsPublisher publishes metrics to AWS CloudWatch.
@@ -41,6 +45,7 @@ type MetricsPublisher struct {
 	namespace         string
 	defaultDimensions map[string]string
 	storageResolution int32
+	serviceMetrics    applicationPort.ServiceMetrics
 
 	buffer     []*entity.Metric
 	bufferSize int
@@ -83,6 +88,7 @@ func NewMetricsPublisher(ctx context.Context, cfg MetricsPublisherConfig) (*Metr
 		namespace:         cfg.Namespace,
 		defaultDimensions: cfg.DefaultDimensions,
 		storageResolution: cfg.StorageResolution,
+		serviceMetrics:    cfg.ServiceMetrics,
 		buffer:            make([]*entity.Metric, 0, cfg.BufferSize),
 		bufferSize:        cfg.BufferSize,
 		flushTicker:       time.NewTicker(cfg.FlushInterval),
@@ -126,7 +132,11 @@ func (p *MetricsPublisher) PublishSingle(ctx context.Context, metric *entity.Met
 	}
 
 	datum := p.convertToDatum(metric)
-	return p.publishBatchWithRetry(ctx, []types.MetricDatum{datum})
+	if err := p.publishBatchWithRetry(ctx, []types.MetricDatum{datum}); err != nil {
+		p.publishFailed()
+		return err
+	}
+	return nil
 }
 
 // Flush forces immediate publication of all buffered metrics.
@@ -187,6 +197,7 @@ func (p *MetricsPublisher) flushBufferUnsafe(ctx context.Context) error {
 
 		chunk := data[i:end]
 		if err := p.publishBatchWithRetry(ctx, chunk); err != nil {
+			p.publishFailed()
 			return fmt.Errorf("failed to publish chunk: %w", err)
 		}
 	}
@@ -197,6 +208,13 @@ func (p *MetricsPublisher) flushBufferUnsafe(ctx context.Context) error {
 	return nil
 }
 
+// publishFailed counts a failed publish when service metrics are enabled.
+func (p *MetricsPublisher) publishFailed() {
+	if p.serviceMetrics != nil {
+		p.serviceMetrics.PublishFailed(applicationPort.PublishTargetCloudWatchMetrics)
+	}
+}
+
 // publishBatchWithRetry publishes a batch of metrics with exponential backoff retry.
 func (p *MetricsPublisher) publishBatchWithRetry(ctx context.Context, data []types.MetricDatum) error {
 	var lastErr error
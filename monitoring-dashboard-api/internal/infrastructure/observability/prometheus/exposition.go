@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"bufio"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// OpenMetricsContentType тип ответа формата OpenMetrics 1.0
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Format формат экспозиции метрик
+type Format int
+
+const (
+	// FormatText текстовый формат Prometheus 0.0.4
+	FormatText Format = iota
+	// FormatOpenMetrics формат OpenMetrics 1.0
+	FormatOpenMetrics
+)
+
+// NegotiateFormat выбирает формат по заголовку Accept (по умолчанию - текстовый)
+func NegotiateFormat(accept string) Format {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if mediaType == "application/openmetrics-text" {
+			return FormatOpenMetrics
+		}
+	}
+	return FormatText
+}
+
+// ContentType возвращает значение заголовка Content-Type для формата
+func (f Format) ContentType() string {
+	if f == FormatOpenMetrics {
+		return OpenMetricsContentType
+	}
+	return ContentType
+}
+
+// WriteFamilies записывает семейства метрик как gauge с временем сбора каждой точки
+// Имена метрик и меток, недопустимые в Prometheus, приводятся к допустимым
+func WriteFamilies(w *bufio.Writer, format Format, families []*dto.MetricFamilyDTO) {
+	for _, family := range families {
+		name := sanitizeName(family.Name)
+		WriteHeader(w, name, family.Help, "gauge")
+		for _, sample := range family.Samples {
+			writeSampleAt(w, format, name, sortedLabels(sample.Labels), sample.Value, sample.CollectedAt.UnixMilli())
+		}
+	}
+	if format == FormatOpenMetrics {
+		w.WriteString("# EOF\n")
+	}
+}
+
+// writeSampleAt записывает сэмпл с меткой времени
+// Текстовый формат ожидает миллисекунды, OpenMetrics - секунды
+func writeSampleAt(w *bufio.Writer, format Format, name string, labels []Label, value float64, timestampMs int64) {
+	timestamp := strconv.FormatInt(timestampMs, 10)
+	if format == FormatOpenMetrics {
+		timestamp = strconv.FormatFloat(float64(timestampMs)/1000, 'f', -1, 64)
+	}
+	writeSampleLine(w, name, labels, value, timestamp)
+}
+
+// sortedLabels переводит метки в отсортированный список; host идет первым
+func sortedLabels(values map[string]string) []Label {
+	labels := make([]Label, 0, len(values))
+	for name, value := range values {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		labels = append(labels, Label{Name: sanitizeName(name), Value: value})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if (labels[i].Name == valueobject.HostLabel) != (labels[j].Name == valueobject.HostLabel) {
+			return labels[i].Name == valueobject.HostLabel
+		}
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+// sanitizeName заменяет недопустимые символы на '_' ([a-zA-Z_][a-zA-Z0-9_]*)
+func sanitizeName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
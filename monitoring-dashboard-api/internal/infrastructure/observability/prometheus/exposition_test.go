@@ -0,0 +1,54 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+)
+
+func TestWriteFamiliesFormats(t *testing.T) {
+	at := time.UnixMilli(1700000000123)
+	families := []*dto.MetricFamilyDTO{{
+		Name: "network.sent",
+		Help: "Network Sent (MB/s)",
+		Samples: []dto.MetricSampleDTO{{
+			Labels:      map[string]string{"interface": "eth0", "host": "web-1", "__name__": "skipped"},
+			Value:       1.5,
+			CollectedAt: at,
+		}},
+	}}
+
+	cases := map[Format]string{
+		FormatText: "# HELP network_sent Network Sent (MB/s)\n" +
+			"# TYPE network_sent gauge\n" +
+			`network_sent{host="web-1",interface="eth0"} 1.5 1700000000123` + "\n",
+		FormatOpenMetrics: "# HELP network_sent Network Sent (MB/s)\n" +
+			"# TYPE network_sent gauge\n" +
+			`network_sent{host="web-1",interface="eth0"} 1.5 1700000000.123` + "\n" +
+			"# EOF\n",
+	}
+	for format, want := range cases {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		WriteFamilies(w, format, families)
+		_ = w.Flush()
+		if got := buf.String(); got != want {
+			t.Fatalf("format %d:\n%s\nwant:\n%s", format, got, want)
+		}
+	}
+}
+
+func TestNegotiateFormat(t *testing.T) {
+	if NegotiateFormat("text/plain;version=0.0.4;q=0.9,*/*;q=0.1") != FormatText {
+		t.Fatal("text/plain must select the text format")
+	}
+	if NegotiateFormat("application/openmetrics-text;version=1.0.0;q=0.9,text/plain;q=0.5") != FormatOpenMetrics {
+		t.Fatal("openmetrics Accept must select OpenMetrics")
+	}
+	if NegotiateFormat("") != FormatText {
+		t.Fatal("empty Accept must select the text format")
+	}
+}
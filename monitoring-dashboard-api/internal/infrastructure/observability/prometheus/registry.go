@@ -0,0 +1,392 @@
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ContentType тип ответа текстового формата экспозиции Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefBuckets границы гистограммы по умолчанию (секунды), как в client_golang
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Collector источник семейства метрик для экспозиции
+type Collector interface {
+	describe() (name, help, kind string)
+	write(w *bufio.Writer)
+}
+
+// Registry набор метрик, отдаваемых на /metrics
+// Минимальная реализация текстового формата 0.0.4 без зависимости от client_golang
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry создает пустой реестр
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]Collector)}
+}
+
+// MustRegister добавляет метрики; повторное имя - ошибка программиста
+func (r *Registry) MustRegister(collectors ...Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range collectors {
+		name, _, _ := c.describe()
+		if _, ok := r.collectors[name]; ok {
+			panic(fmt.Sprintf("prometheus: metric %q is already registered", name))
+		}
+		r.collectors[name] = c
+	}
+}
+
+// WriteTo записывает все метрики реестра в текстовом формате, отсортированными по имени
+func (r *Registry) WriteTo(w *bufio.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]Collector, 0, len(names))
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+	r.mu.RUnlock()
+
+	for _, c := range collectors {
+		name, help, kind := c.describe()
+		WriteHeader(w, name, help, kind)
+		c.write(w)
+	}
+}
+
+// Handler возвращает HTTP handler, отдающий метрики реестра
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		buf := bufio.NewWriter(w)
+		r.WriteTo(buf)
+		_ = buf.Flush()
+	})
+}
+
+// Opts имя и описание метрики
+type Opts struct {
+	Name string
+	Help string
+}
+
+// HistogramOpts параметры гистограммы; пустые Buckets - DefBuckets
+type HistogramOpts struct {
+	Name    string
+	Help    string
+	Buckets []float64
+}
+
+// Counter монотонно растущий счетчик
+type Counter struct {
+	opts Opts
+	bits atomic.Uint64
+}
+
+// NewCounter создает счетчик
+func NewCounter(opts Opts) *Counter {
+	return &Counter{opts: opts}
+}
+
+// Inc увеличивает счетчик на 1
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add увеличивает счетчик на delta (отрицательные значения игнорируются)
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	addFloat(&c.bits, delta)
+}
+
+// Value возвращает текущее значение
+func (c *Counter) Value() float64 {
+	return math.Float64frombits(c.bits.Load())
+}
+
+func (c *Counter) describe() (string, string, string) { return c.opts.Name, c.opts.Help, "counter" }
+
+func (c *Counter) write(w *bufio.Writer) {
+	WriteSample(w, c.opts.Name, nil, c.Value())
+}
+
+// Gauge значение, которое может расти и уменьшаться
+type Gauge struct {
+	opts Opts
+	bits atomic.Uint64
+}
+
+// NewGauge создает gauge
+func NewGauge(opts Opts) *Gauge {
+	return &Gauge{opts: opts}
+}
+
+// Set устанавливает значение
+func (g *Gauge) Set(value float64) {
+	g.bits.Store(math.Float64bits(value))
+}
+
+// Add изменяет значение на delta
+func (g *Gauge) Add(delta float64) {
+	addFloat(&g.bits, delta)
+}
+
+// Value возвращает текущее значение
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(g.bits.Load())
+}
+
+func (g *Gauge) describe() (string, string, string) { return g.opts.Name, g.opts.Help, "gauge" }
+
+func (g *Gauge) write(w *bufio.Writer) {
+	WriteSample(w, g.opts.Name, nil, g.Value())
+}
+
+// valueFunc метрика, значение которой читается при каждом scrape
+type valueFunc struct {
+	opts Opts
+	kind string
+	fn   func() float64
+}
+
+// NewGaugeFunc создает gauge, значение которого возвращает fn
+func NewGaugeFunc(opts Opts, fn func() float64) Collector {
+	return &valueFunc{opts: opts, kind: "gauge", fn: fn}
+}
+
+// NewCounterFunc создает счетчик, значение которого возвращает fn (fn должна быть монотонной)
+func NewCounterFunc(opts Opts, fn func() float64) Collector {
+	return &valueFunc{opts: opts, kind: "counter", fn: fn}
+}
+
+func (f *valueFunc) describe() (string, string, string) { return f.opts.Name, f.opts.Help, f.kind }
+
+func (f *valueFunc) write(w *bufio.Writer) {
+	WriteSample(w, f.opts.Name, nil, f.fn())
+}
+
+// CounterVec набор счетчиков с метками
+type CounterVec struct {
+	opts   Opts
+	labels []string
+
+	mu       sync.RWMutex
+	counters map[string]*labeledCounter
+}
+
+type labeledCounter struct {
+	values []string
+	Counter
+}
+
+// NewCounterVec создает набор счетчиков с метками labelNames
+func NewCounterVec(opts Opts, labelNames []string) *CounterVec {
+	return &CounterVec{opts: opts, labels: labelNames, counters: make(map[string]*labeledCounter)}
+}
+
+// WithLabelValues возвращает счетчик для значений меток (в порядке labelNames)
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("prometheus: %s expects %d label values, got %d", v.opts.Name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	c, ok := v.counters[key]
+	v.mu.RUnlock()
+	if ok {
+		return &c.Counter
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok = v.counters[key]; !ok {
+		c = &labeledCounter{values: append([]string(nil), values...)}
+		v.counters[key] = c
+	}
+	return &c.Counter
+}
+
+func (v *CounterVec) describe() (string, string, string) { return v.opts.Name, v.opts.Help, "counter" }
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.counters))
+	for key := range v.counters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		c := v.counters[key]
+		WriteSample(w, v.opts.Name, zipLabels(v.labels, c.values), c.Value())
+	}
+	v.mu.RUnlock()
+}
+
+// Histogram распределение наблюдений по кумулятивным бакетам
+type Histogram struct {
+	opts    HistogramOpts
+	mu      sync.Mutex
+	counts  []uint64
+	count   uint64
+	sum     float64
+	buckets []float64
+}
+
+// NewHistogram создает гистограмму
+func NewHistogram(opts HistogramOpts) *Histogram {
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Histogram{opts: opts, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Observe добавляет наблюдение
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// ObserveDuration добавляет длительность в секундах
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+func (h *Histogram) describe() (string, string, string) { return h.opts.Name, h.opts.Help, "histogram" }
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	count, sum := h.count, h.sum
+	h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		WriteSample(w, h.opts.Name+"_bucket", []Label{{"le", formatFloat(bound)}}, float64(counts[i]))
+	}
+	WriteSample(w, h.opts.Name+"_bucket", []Label{{"le", "+Inf"}}, float64(count))
+	WriteSample(w, h.opts.Name+"_sum", nil, sum)
+	WriteSample(w, h.opts.Name+"_count", nil, float64(count))
+}
+
+// Label пара имя-значение метки сэмпла
+type Label struct {
+	Name  string
+	Value string
+}
+
+// WriteHeader записывает строки # HELP и # TYPE семейства метрик
+func WriteHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// WriteSample записывает одну строку сэмпла: name{label="value"} 1.5
+func WriteSample(w *bufio.Writer, name string, labels []Label, value float64) {
+	writeSampleLine(w, name, labels, value, "")
+}
+
+// writeSampleLine записывает строку сэмпла; пустой timestamp не выводится
+func writeSampleLine(w *bufio.Writer, name string, labels []Label, value float64, timestamp string) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label.Name)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabelValue(label.Value))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	if timestamp != "" {
+		w.WriteByte(' ')
+		w.WriteString(timestamp)
+	}
+	w.WriteByte('\n')
+}
+
+func zipLabels(names, values []string) []Label {
+	labels := make([]Label, len(names))
+	for i, name := range names {
+		labels[i] = Label{Name: name, Value: values[i]}
+	}
+	return labels
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// addFloat атомарно прибавляет delta к float64, хранящемуся в bits
+func addFloat(bits *atomic.Uint64, delta float64) {
+	for {
+		old := bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+func expose(t *testing.T, registry *Registry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	registry.WriteTo(w)
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRegistryWritesTextFormat(t *testing.T) {
+	registry := NewRegistry()
+	requests := NewCounterVec(Opts{Name: "requests_total", Help: "Requests by path.\nSecond line"}, []string{"path"})
+	latency := NewHistogram(HistogramOpts{Name: "latency_seconds", Help: "Latency.", Buckets: []float64{1, 0.1}})
+	registry.MustRegister(requests, latency, NewGaugeFunc(Opts{Name: "clients", Help: "Clients."}, func() float64 { return 3 }))
+
+	requests.WithLabelValues(`/a"b\`).Inc()
+	requests.WithLabelValues("/").Add(2)
+	requests.WithLabelValues("/").Add(-1) // счетчик не уменьшается
+	latency.Observe(0.05)
+	latency.ObserveDuration(500 * time.Millisecond)
+
+	want := `# HELP clients Clients.
+# TYPE clients gauge
+clients 3
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.1"} 1
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 0.55
+latency_seconds_count 2
+# HELP requests_total Requests by path.\nSecond line
+# TYPE requests_total counter
+requests_total{path="/"} 2
+requests_total{path="/a\"b\\"} 1
+`
+	if got := expose(t, registry); got != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	registry := NewRegistry()
+	registry.MustRegister(NewCounter(Opts{Name: "dup_total"}))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustRegister() must panic on a duplicate name")
+		}
+	}()
+	registry.MustRegister(NewGauge(Opts{Name: "dup_total"}))
+}
+
+func TestServiceMetricsHandler(t *testing.T) {
+	registry := NewRegistry()
+	metrics := NewServiceMetrics(registry)
+
+	metrics.CollectorFailed(valueobject.Disk)
+	metrics.MetricDropped(port.DropReasonValidation)
+	metrics.ObserveSaveBatch(20*time.Millisecond, errors.New("db down"))
+	metrics.CacheHit()
+	metrics.CacheHit()
+	metrics.CacheMiss()
+	metrics.PublishFailed(port.PublishTargetNATS)
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("unexpected response: %d %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	body := recorder.Body.String()
+	for _, line := range []string{
+		`monitoring_api_collector_errors_total{type="disk"} 1`,
+		`monitoring_api_metrics_dropped_total{reason="validation"} 1`,
+		`monitoring_api_save_batch_duration_seconds_bucket{le="0.025"} 1`,
+		`monitoring_api_save_batch_errors_total 1`,
+		`monitoring_api_history_cache_requests_total{result="hit"} 2`,
+		`monitoring_api_history_cache_requests_total{result="miss"} 1`,
+		`monitoring_api_publish_failures_total{target="nats"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("exposition is missing %q:\n%s", line, body)
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// namespace префикс имен метрик сервиса
+const namespace = "monitoring_api_"
+
+// ServiceMetrics реализует port.ServiceMetrics поверх Registry (Adapter)
+type ServiceMetrics struct {
+	registry *Registry
+
+	collectionDuration *Histogram
+	collectorErrors    *CounterVec
+	metricsDropped     *CounterVec
+	saveBatchDuration  *Histogram
+	saveBatchErrors    *Counter
+	cacheRequests      *CounterVec
+	publishFailures    *CounterVec
+}
+
+// NewServiceMetrics создает метрики сервиса и регистрирует их в registry
+func NewServiceMetrics(registry *Registry) *ServiceMetrics {
+	m := &ServiceMetrics{
+		registry: registry,
+		collectionDuration: NewHistogram(HistogramOpts{
+			Name: namespace + "collection_duration_seconds",
+			Help: "Duration of a local metrics collection cycle.",
+		}),
+		collectorErrors: NewCounterVec(Opts{
+			Name: namespace + "collector_errors_total",
+			Help: "Failed system metric collections by metric type.",
+		}, []string{"type"}),
+		metricsDropped: NewCounterVec(Opts{
+			Name: namespace + "metrics_dropped_total",
+			Help: "Collected metrics dropped by validation, by reason.",
+		}, []string{"reason"}),
+		saveBatchDuration: NewHistogram(HistogramOpts{
+			Name: namespace + "save_batch_duration_seconds",
+			Help: "Latency of writing a batch of metrics to storage.",
+		}),
+		saveBatchErrors: NewCounter(Opts{
+			Name: namespace + "save_batch_errors_total",
+			Help: "Failed writes of metric batches to storage.",
+		}),
+		cacheRequests: NewCounterVec(Opts{
+			Name: namespace + "history_cache_requests_total",
+			Help: "Historical metrics cache lookups by result (hit or miss).",
+		}, []string{"result"}),
+		publishFailures: NewCounterVec(Opts{
+			Name: namespace + "publish_failures_total",
+			Help: "Failed publishes to external systems by target.",
+		}, []string{"target"}),
+	}
+
+	registry.MustRegister(
+		m.collectionDuration,
+		m.collectorErrors,
+		m.metricsDropped,
+		m.saveBatchDuration,
+		m.saveBatchErrors,
+		m.cacheRequests,
+		m.publishFailures,
+	)
+
+	return m
+}
+
+// RegisterWebSocketHub регистрирует метрики WebSocket hub, читаемые при каждом scrape
+func (m *ServiceMetrics) RegisterWebSocketHub(clientCount func() int, droppedSnapshots func() uint64) {
+	m.registry.MustRegister(
+		NewGaugeFunc(Opts{
+			Name: namespace + "websocket_clients",
+			Help: "Currently connected WebSocket clients.",
+		}, func() float64 { return float64(clientCount()) }),
+		NewCounterFunc(Opts{
+			Name: namespace + "websocket_dropped_snapshots_total",
+			Help: "Snapshots dropped because the WebSocket hub broadcast queue was full.",
+		}, func() float64 { return float64(droppedSnapshots()) }),
+	)
+}
+
+// ObserveCollection учитывает длительность цикла сбора
+func (m *ServiceMetrics) ObserveCollection(duration time.Duration) {
+	m.collectionDuration.ObserveDuration(duration)
+}
+
+// CollectorFailed учитывает ошибку сборщика
+func (m *ServiceMetrics) CollectorFailed(metricType valueobject.MetricType) {
+	m.collectorErrors.WithLabelValues(metricType.String()).Inc()
+}
+
+// MetricDropped учитывает отброшенную метрику
+func (m *ServiceMetrics) MetricDropped(reason string) {
+	m.metricsDropped.WithLabelValues(reason).Inc()
+}
+
+// ObserveSaveBatch учитывает длительность и результат записи пачки
+func (m *ServiceMetrics) ObserveSaveBatch(duration time.Duration, err error) {
+	m.saveBatchDuration.ObserveDuration(duration)
+	if err != nil {
+		m.saveBatchErrors.Inc()
+	}
+}
+
+// CacheHit учитывает попадание в кеш
+func (m *ServiceMetrics) CacheHit() {
+	m.cacheRequests.WithLabelValues("hit").Inc()
+}
+
+// CacheMiss учитывает промах кеша
+func (m *ServiceMetrics) CacheMiss() {
+	m.cacheRequests.WithLabelValues("miss").Inc()
+}
+
+// PublishFailed учитывает неудачную публикацию
+func (m *ServiceMetrics) PublishFailed(target string) {
+	m.publishFailures.WithLabelValues(target).Inc()
+}
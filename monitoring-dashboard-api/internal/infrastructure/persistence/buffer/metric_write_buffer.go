@@ -0,0 +1,310 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ErrWriteBufferFull возвращается, если буфер не освободился за EnqueueTimeout
+var ErrWriteBufferFull = errors.New("metric write buffer is full")
+
+// MetricWriteBufferConfig настройки буфера записи метрик
+type MetricWriteBufferConfig struct {
+	// FlushSize количество метрик, при накоплении которого буфер сбрасывается досрочно
+	FlushSize int
+
+	// FlushInterval максимальное время, которое метрика проводит в буфере
+	FlushInterval time.Duration
+
+	// MaxPending предел метрик в буфере, после которого запись блокируется
+	MaxPending int
+
+	// EnqueueTimeout сколько запись ждет освобождения буфера перед ErrWriteBufferFull
+	EnqueueTimeout time.Duration
+}
+
+// MetricWriteBuffer накапливает метрики в памяти и пишет их пачками через SaveBatch
+// Реализует repository.MetricRepository: чтение делегируется обернутому repository,
+// Save и SaveBatch только ставят метрики в очередь
+type MetricWriteBuffer struct {
+	repository.MetricRepository
+
+	config         MetricWriteBufferConfig
+	serviceMetrics port.ServiceMetrics // Optional: counts metrics rejected by storage
+	logger         *logger.Logger
+
+	rejected atomic.Uint64 // метрики, отброшенные из-за ErrMetricRejected
+
+	mu       sync.Mutex
+	pending  []*entity.Metric
+	inflight []*entity.Metric // пачка, которая пишется текущим сбросом
+	drained  chan struct{}    // закрывается после каждого сброса и будит ожидающих писателей
+
+	flushMu sync.Mutex    // сбросы выполняются строго по одному
+	flushCh chan struct{} // сигнал досрочного сброса
+}
+
+// NewMetricWriteBuffer создает буфер записи поверх repository
+func NewMetricWriteBuffer(
+	repository repository.MetricRepository,
+	config MetricWriteBufferConfig,
+	serviceMetrics port.ServiceMetrics, // Can be nil if /metrics disabled
+	logger *logger.Logger,
+) *MetricWriteBuffer {
+	if config.FlushSize <= 0 {
+		config.FlushSize = 1000
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.MaxPending < config.FlushSize {
+		config.MaxPending = config.FlushSize * 10
+	}
+	if config.EnqueueTimeout <= 0 {
+		config.EnqueueTimeout = 5 * time.Second
+	}
+
+	return &MetricWriteBuffer{
+		MetricRepository: repository,
+		config:           config,
+		serviceMetrics:   serviceMetrics,
+		logger:           logger,
+		drained:          make(chan struct{}),
+		flushCh:          make(chan struct{}, 1),
+	}
+}
+
+// Save ставит одну метрику в очередь на запись
+func (b *MetricWriteBuffer) Save(ctx context.Context, metric *entity.Metric) error {
+	return b.SaveBatch(ctx, []*entity.Metric{metric})
+}
+
+// SaveBatch ставит метрики в очередь на запись
+// Если буфер заполнен до MaxPending, вызов ждет очередного сброса (backpressure)
+func (b *MetricWriteBuffer) SaveBatch(ctx context.Context, metrics []*entity.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	// Пачка больше всего буфера никогда в него не поместится
+	if len(metrics) >= b.config.MaxPending {
+		return b.MetricRepository.SaveBatch(ctx, metrics)
+	}
+
+	var timeout <-chan time.Time
+	for {
+		b.mu.Lock()
+		if len(b.pending)+len(metrics) <= b.config.MaxPending {
+			b.pending = append(b.pending, metrics...)
+			full := len(b.pending) >= b.config.FlushSize
+			b.mu.Unlock()
+
+			if full {
+				b.requestFlush()
+			}
+			return nil
+		}
+		drained := b.drained
+		b.mu.Unlock()
+
+		b.requestFlush()
+		if timeout == nil {
+			timer := time.NewTimer(b.config.EnqueueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-drained:
+		case <-timeout:
+			return ErrWriteBufferFull
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Pending возвращает количество метрик, ожидающих записи
+func (b *MetricWriteBuffer) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.pending)
+}
+
+// Rejected возвращает количество метрик, отброшенных с момента запуска, потому что хранилище их отвергло
+func (b *MetricWriteBuffer) Rejected() uint64 {
+	return b.rejected.Load()
+}
+
+// FindByLabels дополняет выборку из хранилища еще не записанными метриками
+// Без этого оценка алертов сразу после приема пачки не видела бы только что пришедшие точки
+func (b *MetricWriteBuffer) FindByLabels(ctx context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	// Снимок буфера берется до запроса в хранилище: метрика, записанная между ними,
+	// попадет в обе выборки и отсеется по ID, но не потеряется
+	buffered := b.matchBuffered(query)
+
+	stored, err := b.MetricRepository.FindByLabels(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(buffered) == 0 {
+		return stored, nil
+	}
+
+	seen := make(map[string]struct{}, len(stored))
+	for _, metric := range stored {
+		seen[metric.ID()] = struct{}{}
+	}
+	for _, metric := range buffered {
+		if _, ok := seen[metric.ID()]; !ok {
+			stored = append(stored, metric)
+		}
+	}
+
+	// Порядок как у хранилища: от новых к старым
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].CollectedAt().After(stored[j].CollectedAt())
+	})
+	if query.Limit > 0 && len(stored) > query.Limit {
+		stored = stored[:query.Limit]
+	}
+
+	return stored, nil
+}
+
+// matchBuffered возвращает метрики буфера и текущего сброса, подходящие под запрос
+func (b *MetricWriteBuffer) matchBuffered(query repository.MetricQuery) []*entity.Metric {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var matched []*entity.Metric
+	for _, group := range [][]*entity.Metric{b.inflight, b.pending} {
+		for _, metric := range group {
+			if query.Type != "" && metric.Type() != query.Type {
+				continue
+			}
+			if query.Name != "" && metric.Name() != query.Name {
+				continue
+			}
+			if query.HasTimeRange() && !query.TimeRange.Contains(metric.CollectedAt()) {
+				continue
+			}
+			if !metric.MatchesSelector(query.Selector) {
+				continue
+			}
+			matched = append(matched, metric)
+		}
+	}
+
+	return matched
+}
+
+// requestFlush будит цикл сброса, не блокируясь, если сигнал уже отправлен
+func (b *MetricWriteBuffer) requestFlush() {
+	select {
+	case b.flushCh <- struct{}{}:
+	default:
+	}
+}
+
+// Flush записывает все накопленные метрики
+// При временной ошибке незаписанные метрики возвращаются в буфер и будут записаны следующим сбросом.
+// Метрики, которые хранилище отвергло (repository.ErrMetricRejected), отбрасываются и учитываются в Rejected:
+// иначе одна плохая строка блокировала бы все последующие сбросы
+func (b *MetricWriteBuffer) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	b.inflight = batch
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	unsaved, err := b.saveIsolatingRejected(ctx, batch)
+
+	b.mu.Lock()
+	b.inflight = nil
+	if len(unsaved) > 0 {
+		b.pending = append(unsaved, b.pending...)
+	}
+	close(b.drained)
+	b.drained = make(chan struct{})
+	b.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to flush %d buffered metrics: %w", len(unsaved), err)
+	}
+	return nil
+}
+
+// saveIsolatingRejected записывает пачку; если хранилище ее отвергло, пачка делится пополам,
+// пока отвергнутые метрики не останутся по одной, и они отбрасываются
+// При временной ошибке возвращает незаписанный хвост пачки и эту ошибку
+func (b *MetricWriteBuffer) saveIsolatingRejected(ctx context.Context, batch []*entity.Metric) ([]*entity.Metric, error) {
+	err := b.MetricRepository.SaveBatch(ctx, batch)
+	switch {
+	case err == nil:
+		return nil, nil
+	case !errors.Is(err, repository.ErrMetricRejected):
+		return batch, err
+	case len(batch) == 1:
+		b.reject(batch[0], err)
+		return nil, nil
+	}
+
+	mid := len(batch) / 2
+	if unsaved, err := b.saveIsolatingRejected(ctx, batch[:mid]); err != nil {
+		// Незаписанное всегда хвост половины, поэтому вместе со второй половиной это хвост всей пачки
+		return batch[mid-len(unsaved):], err
+	}
+	return b.saveIsolatingRejected(ctx, batch[mid:])
+}
+
+// reject учитывает метрику, отвергнутую хранилищем
+func (b *MetricWriteBuffer) reject(metric *entity.Metric, err error) {
+	b.rejected.Add(1)
+	if b.serviceMetrics != nil {
+		b.serviceMetrics.MetricDropped(port.DropReasonStorageRejected)
+	}
+	b.logger.Warn("Buffered metric rejected by storage, dropping",
+		"type", metric.Type().String(),
+		"name", metric.Name(),
+		"host", metric.Host(),
+		"error", err.Error())
+}
+
+// Run сбрасывает буфер по FlushInterval и при достижении FlushSize до отмены ctx
+// Оставшиеся метрики нужно записать вызовом Flush после остановки
+func (b *MetricWriteBuffer) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.flushCh:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := b.Flush(ctx); err != nil && ctx.Err() == nil {
+			b.logger.Error("Failed to flush metric write buffer", err, "pending", b.Pending())
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package buffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// storeMock запоминает записанные пачки
+type storeMock struct {
+	repository.MetricRepository
+
+	mu      sync.Mutex
+	batches [][]*entity.Metric
+	failErr error
+	reject  map[string]bool // ID метрик, которые хранилище отвергает
+	saved   chan struct{}
+}
+
+func newStoreMock() *storeMock {
+	return &storeMock{saved: make(chan struct{}, 16)}
+}
+
+func (m *storeMock) SaveBatch(_ context.Context, metrics []*entity.Metric) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failErr != nil {
+		return m.failErr
+	}
+	for _, metric := range metrics {
+		if m.reject[metric.ID()] {
+			return fmt.Errorf("%w: value out of range", repository.ErrMetricRejected)
+		}
+	}
+	m.batches = append(m.batches, metrics)
+	select {
+	case m.saved <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (m *storeMock) FindByLabels(_ context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*entity.Metric
+	for _, batch := range m.batches {
+		for _, metric := range batch {
+			if query.TimeRange.Contains(metric.CollectedAt()) {
+				result = append(result, metric)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *storeMock) savedCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, batch := range m.batches {
+		count += len(batch)
+	}
+	return count
+}
+
+func testMetrics(t *testing.T, count int, at time.Time) []*entity.Metric {
+	t.Helper()
+	metrics := make([]*entity.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		value, err := valueobject.NewMetricValue(float64(i), "%")
+		if err != nil {
+			t.Fatalf("NewMetricValue() error = %v", err)
+		}
+		collectedAt := at.Add(time.Duration(i) * time.Second)
+		metrics = append(metrics, entity.Reconstruct(
+			fmt.Sprintf("%s-%d", at.Format(time.RFC3339Nano), i),
+			valueobject.CPU, "cpu_usage", "web-1", valueobject.Labels{}, value, nil, collectedAt, collectedAt,
+		))
+	}
+	return metrics
+}
+
+func TestMetricWriteBufferFlushesBySize(t *testing.T) {
+	store := newStoreMock()
+	buffer := NewMetricWriteBuffer(store, MetricWriteBufferConfig{
+		FlushSize:     10,
+		FlushInterval: time.Hour,
+		MaxPending:    100,
+	}, nil, logger.New("error"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go buffer.Run(ctx)
+
+	if err := buffer.SaveBatch(ctx, testMetrics(t, 4, time.Now())); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+	if store.savedCount() != 0 || buffer.Pending() != 4 {
+		t.Fatalf("small batch must stay in the buffer, saved=%d pending=%d", store.savedCount(), buffer.Pending())
+	}
+
+	if err := buffer.SaveBatch(ctx, testMetrics(t, 6, time.Now())); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	select {
+	case <-store.saved:
+	case <-time.After(time.Second):
+		t.Fatal("buffer was not flushed after reaching FlushSize")
+	}
+	if store.savedCount() != 10 {
+		t.Fatalf("expected 10 metrics in one flush, got %d", store.savedCount())
+	}
+}
+
+func TestMetricWriteBufferFlushesByInterval(t *testing.T) {
+	store := newStoreMock()
+	buffer := NewMetricWriteBuffer(store, MetricWriteBufferConfig{
+		FlushSize:     1000,
+		FlushInterval: 20 * time.Millisecond,
+	}, nil, logger.New("error"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go buffer.Run(ctx)
+
+	if err := buffer.Save(ctx, testMetrics(t, 1, time.Now())[0]); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	select {
+	case <-store.saved:
+	case <-time.After(time.Second):
+		t.Fatal("buffer was not flushed by interval")
+	}
+}
+
+func TestMetricWriteBufferBackpressure(t *testing.T) {
+	store := newStoreMock()
+	store.failErr = errors.New("database is down")
+	buffer := NewMetricWriteBuffer(store, MetricWriteBufferConfig{
+		FlushSize:      5,
+		FlushInterval:  time.Hour,
+		MaxPending:     5,
+		EnqueueTimeout: 50 * time.Millisecond,
+	}, nil, logger.New("error"))
+
+	ctx := context.Background()
+	if err := buffer.SaveBatch(ctx, testMetrics(t, 3, time.Now())); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	// Без работающего сброса места не освобождается
+	if err := buffer.SaveBatch(ctx, testMetrics(t, 3, time.Now())); !errors.Is(err, ErrWriteBufferFull) {
+		t.Fatalf("expected ErrWriteBufferFull, got %v", err)
+	}
+
+	// Неудачный сброс возвращает метрики в буфер
+	if err := buffer.Flush(ctx); err == nil {
+		t.Fatal("expected flush error")
+	}
+	if buffer.Pending() != 3 {
+		t.Fatalf("failed batch must be kept, pending=%d", buffer.Pending())
+	}
+
+	// Ожидающий писатель продолжает работу после успешного сброса
+	store.mu.Lock()
+	store.failErr = nil
+	store.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	buffer.config.EnqueueTimeout = time.Second
+	go buffer.Run(ctx)
+
+	if err := buffer.SaveBatch(ctx, testMetrics(t, 3, time.Now())); err != nil {
+		t.Fatalf("SaveBatch() after drain error = %v", err)
+	}
+	if store.savedCount() != 3 || buffer.Pending() != 3 {
+		t.Fatalf("unexpected state: saved=%d pending=%d", store.savedCount(), buffer.Pending())
+	}
+}
+
+func TestMetricWriteBufferDropsRejectedMetrics(t *testing.T) {
+	store := newStoreMock()
+	buffer := NewMetricWriteBuffer(store, MetricWriteBufferConfig{FlushSize: 100}, nil, logger.New("error"))
+
+	ctx := context.Background()
+	metrics := testMetrics(t, 10, time.Now())
+	store.reject = map[string]bool{metrics[3].ID(): true, metrics[8].ID(): true}
+	if err := buffer.SaveBatch(ctx, metrics); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	// Отвергнутые строки отбрасываются, остальная пачка записывается, и буфер не блокируется
+	if err := buffer.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if store.savedCount() != 8 || buffer.Pending() != 0 || buffer.Rejected() != 2 {
+		t.Fatalf("unexpected state: saved=%d pending=%d rejected=%d", store.savedCount(), buffer.Pending(), buffer.Rejected())
+	}
+}
+
+func TestMetricWriteBufferFindByLabelsIncludesPending(t *testing.T) {
+	store := newStoreMock()
+	buffer := NewMetricWriteBuffer(store, MetricWriteBufferConfig{FlushSize: 100}, nil, logger.New("error"))
+
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+	written := testMetrics(t, 2, start)
+	if err := buffer.SaveBatch(ctx, written); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+	if err := buffer.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if err := buffer.SaveBatch(ctx, testMetrics(t, 1, start.Add(30*time.Second))); err != nil {
+		t.Fatalf("SaveBatch() error = %v", err)
+	}
+
+	timeRange, err := valueobject.NewTimeRange(start, time.Now())
+	if err != nil {
+		t.Fatalf("NewTimeRange() error = %v", err)
+	}
+	metrics, err := buffer.FindByLabels(ctx, repository.MetricQuery{Type: valueobject.CPU, TimeRange: timeRange})
+	if err != nil {
+		t.Fatalf("FindByLabels() error = %v", err)
+	}
+
+	if len(metrics) != 3 || !metrics[0].CollectedAt().Equal(start.Add(30*time.Second)) {
+		t.Fatalf("expected pending metric first among 3, got %d", len(metrics))
+	}
+}
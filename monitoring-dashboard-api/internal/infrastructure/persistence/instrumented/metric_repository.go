@@ -0,0 +1,43 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+)
+
+// MetricRepository измеряет задержку записи метрик в обернутый repository (Decorator)
+// Чтение делегируется без изменений. Оборачивать нужно хранилище под буфером записи,
+// иначе будет измеряться только постановка в очередь
+type MetricRepository struct {
+	repository.MetricRepository
+
+	metrics port.ServiceMetrics
+}
+
+// NewMetricRepository оборачивает repository учетом метрик сервиса
+func NewMetricRepository(repository repository.MetricRepository, metrics port.ServiceMetrics) *MetricRepository {
+	return &MetricRepository{
+		MetricRepository: repository,
+		metrics:          metrics,
+	}
+}
+
+// Save сохраняет метрику и учитывает задержку записи
+func (r *MetricRepository) Save(ctx context.Context, metric *entity.Metric) error {
+	start := time.Now()
+	err := r.MetricRepository.Save(ctx, metric)
+	r.metrics.ObserveSaveBatch(time.Since(start), err)
+	return err
+}
+
+// SaveBatch сохраняет пачку метрик и учитывает задержку записи
+func (r *MetricRepository) SaveBatch(ctx context.Context, metrics []*entity.Metric) error {
+	start := time.Now()
+	err := r.MetricRepository.SaveBatch(ctx, metrics)
+	r.metrics.ObserveSaveBatch(time.Since(start), err)
+	return err
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/lib/pq"
+)
+
+const alertRuleColumns = `id, name, description, metric_type, metric_name, unit, selector, aggregate, window_seconds,
+	comparator, threshold, for_seconds, cooldown_seconds, severity, enabled, channels, created_at, updated_at`
+
+// PostgresAlertRuleRepository реализует repository.AlertRuleRepository для PostgreSQL
+type PostgresAlertRuleRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresAlertRuleRepository создает новый repository правил алертинга
+func NewPostgresAlertRuleRepository(db *sql.DB) *PostgresAlertRuleRepository {
+	return &PostgresAlertRuleRepository{
+		db: db,
+	}
+}
+
+// Save создает или обновляет правило
+func (r *PostgresAlertRuleRepository) Save(ctx context.Context, rule *entity.AlertRule) error {
+	params := rule.Params()
+
+	query := `
+		INSERT INTO alert_rules (` + alertRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			description = EXCLUDED.description,
+			metric_type = EXCLUDED.metric_type,
+			metric_name = EXCLUDED.metric_name,
+			unit = EXCLUDED.unit,
+			selector = EXCLUDED.selector,
+			aggregate = EXCLUDED.aggregate,
+			window_seconds = EXCLUDED.window_seconds,
+			comparator = EXCLUDED.comparator,
+			threshold = EXCLUDED.threshold,
+			for_seconds = EXCLUDED.for_seconds,
+			cooldown_seconds = EXCLUDED.cooldown_seconds,
+			severity = EXCLUDED.severity,
+			enabled = EXCLUDED.enabled,
+			channels = EXCLUDED.channels,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	selector := ""
+	if !params.Selector.IsEmpty() {
+		selector = params.Selector.String()
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID(),
+		params.Name,
+		params.Description,
+		params.MetricType.String(),
+		params.MetricName,
+		params.Unit,
+		selector,
+		string(params.Aggregate),
+		int64(params.Window/time.Second),
+		string(params.Comparator),
+		params.Threshold,
+		int64(params.For/time.Second),
+		int64(params.Cooldown/time.Second),
+		params.Severity.String(),
+		params.Enabled,
+		pq.Array(params.Channels),
+		rule.CreatedAt(),
+		rule.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save alert rule: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID находит правило по идентификатору
+func (r *PostgresAlertRuleRepository) FindByID(ctx context.Context, id string) (*entity.AlertRule, error) {
+	query := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id = $1`
+
+	rule, err := scanAlertRule(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrAlertRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// FindAll возвращает все правила
+func (r *PostgresAlertRuleRepository) FindAll(ctx context.Context) ([]*entity.AlertRule, error) {
+	return r.findRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules ORDER BY name`)
+}
+
+// FindEnabled возвращает включенные правила
+func (r *PostgresAlertRuleRepository) FindEnabled(ctx context.Context) ([]*entity.AlertRule, error) {
+	return r.findRules(ctx, `SELECT `+alertRuleColumns+` FROM alert_rules WHERE enabled ORDER BY name`)
+}
+
+// Delete удаляет правило
+func (r *PostgresAlertRuleRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	if rowsAffected == 0 {
+		return repository.ErrAlertRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresAlertRuleRepository) findRules(ctx context.Context, query string) ([]*entity.AlertRule, error) {
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*entity.AlertRule, 0)
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return rules, nil
+}
+
+// scanAlertRule сканирует строку alert_rules в entity
+func scanAlertRule(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.AlertRule, error) {
+	var (
+		id, name, description, metricType, metricName, unit string
+		selectorStr, aggregate, comparator, severity        string
+		windowSeconds, forSeconds, cooldownSeconds          int64
+		threshold                                           float64
+		enabled                                             bool
+		channels                                            []string
+		createdAt, updatedAt                                time.Time
+	)
+
+	err := scanner.Scan(
+		&id, &name, &description, &metricType, &metricName, &unit, &selectorStr, &aggregate, &windowSeconds,
+		&comparator, &threshold, &forSeconds, &cooldownSeconds, &severity, &enabled, pq.Array(&channels), &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := valueobject.ParseLabelSelector(selectorStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of alert rule %s: %w", id, err)
+	}
+
+	return entity.ReconstructAlertRule(id, entity.AlertRuleParams{
+		Name:        name,
+		Description: description,
+		MetricType:  valueobject.MetricType(metricType),
+		MetricName:  metricName,
+		Unit:        unit,
+		Selector:    selector,
+		Aggregate:   valueobject.WindowAggregate(aggregate),
+		Window:      time.Duration(windowSeconds) * time.Second,
+		Comparator:  valueobject.Comparator(comparator),
+		Threshold:   threshold,
+		For:         time.Duration(forSeconds) * time.Second,
+		Cooldown:    time.Duration(cooldownSeconds) * time.Second,
+		Severity:    valueobject.AlertSeverity(severity),
+		Enabled:     enabled,
+		Channels:    channels,
+	}, createdAt, updatedAt), nil
+}
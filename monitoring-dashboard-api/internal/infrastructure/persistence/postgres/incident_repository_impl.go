@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+const incidentColumns = `id, fingerprint, rule_id, series_key, title, severity, host, labels, status, last_value, last_message,
+	alert_count, opened_at, last_alert_at, acknowledged_at, acknowledged_by, resolved_at, resolved_by, updated_at`
+
+// defaultIncidentLimit ограничивает выборку инцидентов, если лимит не задан
+const defaultIncidentLimit = 100
+
+// PostgresIncidentRepository реализует repository.IncidentRepository для PostgreSQL
+type PostgresIncidentRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresIncidentRepository создает новый repository инцидентов
+func NewPostgresIncidentRepository(db *sql.DB) *PostgresIncidentRepository {
+	return &PostgresIncidentRepository{
+		db: db,
+	}
+}
+
+// Save создает или обновляет инцидент
+func (r *PostgresIncidentRepository) Save(ctx context.Context, incident *entity.Incident) error {
+	params := incident.Params()
+	lifecycle := incident.Lifecycle()
+
+	labels, err := json.Marshal(params.Labels.Map())
+	if err != nil {
+		return fmt.Errorf("failed to marshal incident labels: %w", err)
+	}
+
+	query := `
+		INSERT INTO incidents (` + incidentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			last_value = EXCLUDED.last_value,
+			last_message = EXCLUDED.last_message,
+			alert_count = EXCLUDED.alert_count,
+			last_alert_at = EXCLUDED.last_alert_at,
+			acknowledged_at = EXCLUDED.acknowledged_at,
+			acknowledged_by = EXCLUDED.acknowledged_by,
+			resolved_at = EXCLUDED.resolved_at,
+			resolved_by = EXCLUDED.resolved_by,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		incident.ID(),
+		params.Fingerprint,
+		nullString(params.RuleID),
+		params.SeriesKey,
+		params.Title,
+		params.Severity.String(),
+		params.Host,
+		labels,
+		string(lifecycle.Status),
+		lifecycle.LastValue,
+		lifecycle.LastMessage,
+		lifecycle.AlertCount,
+		lifecycle.OpenedAt,
+		lifecycle.LastAlertAt,
+		nullTime(lifecycle.AcknowledgedAt),
+		lifecycle.AcknowledgedBy,
+		nullTime(lifecycle.ResolvedAt),
+		lifecycle.ResolvedBy,
+		lifecycle.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save incident: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID находит инцидент по идентификатору
+func (r *PostgresIncidentRepository) FindByID(ctx context.Context, id string) (*entity.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM incidents WHERE id = $1`
+
+	incident, err := scanIncident(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to scan incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// FindLatestByFingerprint возвращает последний открытый по fingerprint инцидент
+func (r *PostgresIncidentRepository) FindLatestByFingerprint(ctx context.Context, fingerprint string) (*entity.Incident, error) {
+	query := `
+		SELECT ` + incidentColumns + `
+		FROM incidents
+		WHERE fingerprint = $1
+		ORDER BY opened_at DESC
+		LIMIT 1
+	`
+
+	incident, err := scanIncident(r.db.QueryRowContext(ctx, query, fingerprint))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to scan incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// FindAll возвращает инциденты, начиная с самых новых
+func (r *PostgresIncidentRepository) FindAll(ctx context.Context, filter repository.IncidentFilter) ([]*entity.Incident, error) {
+	where := &whereBuilder{}
+
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			placeholders[i] = where.arg(string(status))
+		}
+		where.add("status IN (" + strings.Join(placeholders, ", ") + ")")
+	}
+	if filter.Host != "" {
+		where.add("host = " + where.arg(filter.Host))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultIncidentLimit
+	}
+	limitArg := where.arg(limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM incidents
+		%s
+		ORDER BY opened_at DESC
+		LIMIT %s
+	`, incidentColumns, where.sql(), limitArg)
+
+	rows, err := r.db.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := make([]*entity.Incident, 0)
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan incident: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return incidents, nil
+}
+
+// scanIncident сканирует строку incidents в entity
+func scanIncident(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*entity.Incident, error) {
+	var (
+		id, fingerprint, seriesKey, title, severity, host string
+		status                                            string
+		lastMessage, acknowledgedBy, resolvedBy           string
+		ruleID                                            sql.NullString
+		labelsBytes                                       []byte
+		lastValue                                         float64
+		alertCount                                        int
+		openedAt, lastAlertAt, updatedAt                  time.Time
+		acknowledgedAt, resolvedAt                        sql.NullTime
+	)
+
+	err := scanner.Scan(
+		&id, &fingerprint, &ruleID, &seriesKey, &title, &severity, &host, &labelsBytes, &status, &lastValue, &lastMessage,
+		&alertCount, &openedAt, &lastAlertAt, &acknowledgedAt, &acknowledgedBy, &resolvedAt, &resolvedBy, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var rawLabels map[string]string
+	if len(labelsBytes) > 0 {
+		if err := json.Unmarshal(labelsBytes, &rawLabels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of incident %s: %w", id, err)
+		}
+	}
+	labels, err := valueobject.NewLabels(rawLabels)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labels of incident %s: %w", id, err)
+	}
+
+	return entity.ReconstructIncident(id, entity.IncidentParams{
+		Fingerprint: fingerprint,
+		RuleID:      ruleID.String,
+		SeriesKey:   seriesKey,
+		Title:       title,
+		Severity:    valueobject.AlertSeverity(severity),
+		Host:        host,
+		Labels:      labels,
+	}, entity.IncidentLifecycle{
+		Status:         entity.IncidentStatus(status),
+		LastValue:      lastValue,
+		LastMessage:    lastMessage,
+		AlertCount:     alertCount,
+		OpenedAt:       openedAt,
+		LastAlertAt:    lastAlertAt,
+		AcknowledgedAt: acknowledgedAt.Time,
+		AcknowledgedBy: acknowledgedBy,
+		ResolvedAt:     resolvedAt.Time,
+		ResolvedBy:     resolvedBy,
+		UpdatedAt:      updatedAt,
+	}), nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// defaultLabelQueryLimit лимит выборки по меткам, если в запросе он не задан
+const defaultLabelQueryLimit = 5000
+
+// maxLabelQueryLimit верхняя граница явно заданного лимита (языку запросов нужны большие окна)
+const maxLabelQueryLimit = 1000000
+
+// metricColumns список колонок, который ожидает ScanMetricRow
+const metricColumns = "id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at"
+
+// whereBuilder собирает WHERE-условия с позиционными параметрами
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg добавляет параметр и возвращает его плейсхолдер
+func (b *whereBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *whereBuilder) add(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+// sql возвращает WHERE-часть запроса (или пустую строку)
+func (b *whereBuilder) sql() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// buildMetricQueryWhere транслирует MetricQuery в условия SQL
+// host и __name__ сопоставляются с колонками, остальные метки - с JSONB-колонкой labels
+func buildMetricQueryWhere(query repository.MetricQuery) (*whereBuilder, error) {
+	return buildSeriesQueryWhere(query, "collected_at")
+}
+
+// buildSeriesQueryWhere транслирует MetricQuery в условия SQL для таблицы с колонками серии
+// timeColumn - колонка, по которой фильтруется временной диапазон
+func buildSeriesQueryWhere(query repository.MetricQuery, timeColumn string) (*whereBuilder, error) {
+	b := &whereBuilder{}
+
+	if query.Type != "" {
+		b.add("metric_type = " + b.arg(query.Type.String()))
+	}
+	if query.Name != "" {
+		b.add("metric_name = " + b.arg(query.Name))
+	}
+	if query.HasTimeRange() {
+		b.add(fmt.Sprintf("%s BETWEEN %s AND %s",
+			timeColumn,
+			b.arg(query.TimeRange.Start()),
+			b.arg(query.TimeRange.End()),
+		))
+	}
+
+	for _, matcher := range query.Selector {
+		condition, err := matcherCondition(b, matcher)
+		if err != nil {
+			return nil, err
+		}
+		b.add(condition)
+	}
+
+	return b, nil
+}
+
+// matcherCondition строит условие для одного LabelMatcher
+// Регулярные выражения переводятся из RE2 в ARE, чтобы SQL-фильтр совпадал с LabelMatcher.Matches
+func matcherCondition(b *whereBuilder, matcher valueobject.LabelMatcher) (string, error) {
+	var column string
+	switch matcher.Name() {
+	case valueobject.HostLabel:
+		column = "host"
+	case valueobject.NameLabel:
+		column = "metric_name"
+	default:
+		// Равенство с непустым значением использует GIN-индекс по labels
+		if matcher.Type() == valueobject.MatchEqual && matcher.Value() != "" {
+			return fmt.Sprintf("labels @> jsonb_build_object(%s::text, %s::text)",
+				b.arg(matcher.Name()), b.arg(matcher.Value())), nil
+		}
+		column = fmt.Sprintf("COALESCE(labels->>%s, '')", b.arg(matcher.Name()))
+	}
+
+	switch matcher.Type() {
+	case valueobject.MatchNotEqual:
+		return fmt.Sprintf("%s <> %s", column, b.arg(matcher.Value())), nil
+	case valueobject.MatchRegexp, valueobject.MatchNotRegexp:
+		pattern, err := postgresRegexp(matcher.Value())
+		if err != nil {
+			return "", fmt.Errorf("invalid matcher for label %q: %w", matcher.Name(), err)
+		}
+		operator := "~"
+		if matcher.Type() == valueobject.MatchNotRegexp {
+			operator = "!~"
+		}
+		return fmt.Sprintf("%s %s %s", column, operator, b.arg(pattern)), nil
+	default:
+		return fmt.Sprintf("%s = %s", column, b.arg(matcher.Value())), nil
+	}
+}
+
+// queryLimit возвращает лимит выборки с учетом значения по умолчанию
+func queryLimit(query repository.MetricQuery) int {
+	switch {
+	case query.Limit <= 0:
+		return defaultLabelQueryLimit
+	case query.Limit > maxLabelQueryLimit:
+		return maxLabelQueryLimit
+	}
+	return query.Limit
+}
@@ -0,0 +1,190 @@
+package postgres
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// asciiWordClass символы слова для \b в RE2 (только ASCII, в отличие от \y в PostgreSQL)
+const asciiWordClass = "[0-9A-Za-z_]"
+
+// postgresRegexp переводит регулярное выражение селектора (синтаксис RE2, как в Prometheus)
+// в эквивалентное регулярное выражение PostgreSQL (ARE), заякоренное целиком
+//
+// Шаблон не передается в базу как есть: одинаковая запись в RE2 и ARE значит разное (\b, точка
+// и перевод строки, (?i) в середине выражения, \pL, предел повторений 255 в ARE), и фильтр в SQL
+// расходился бы с LabelMatcher.Matches. Вместо этого разобранное RE2-дерево записывается
+// конструкциями, которые в ARE значат то же самое
+func postgresRegexp(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("invalid regexp %q: %w", pattern, err)
+	}
+
+	var b strings.Builder
+	b.WriteString("^(?:")
+	writePostgresRegexp(&b, re.Simplify()) // Simplify раскрывает {n,m} в конкатенации
+	b.WriteString(")$")
+	return b.String(), nil
+}
+
+// writePostgresRegexp записывает узел RE2-дерева в синтаксисе ARE
+func writePostgresRegexp(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		writeNoMatch(b)
+	case syntax.OpEmptyMatch:
+		b.WriteString("(?:)")
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				writeFoldedRune(b, r)
+			} else {
+				writeLiteralRune(b, r)
+			}
+		}
+	case syntax.OpCharClass:
+		writeCharClass(b, re.Rune)
+	case syntax.OpAnyCharNotNL:
+		b.WriteString(`[^\n]`)
+	case syntax.OpAnyChar:
+		// В ARE без флага n точка совпадает и с переводом строки
+		b.WriteString(".")
+	case syntax.OpBeginLine:
+		b.WriteString(`(?:^|(?<=\n))`)
+	case syntax.OpEndLine:
+		b.WriteString(`(?:$|(?=\n))`)
+	case syntax.OpBeginText:
+		b.WriteString(`\A`)
+	case syntax.OpEndText:
+		b.WriteString(`\Z`)
+	case syntax.OpWordBoundary:
+		fmt.Fprintf(b, "(?:(?<=%[1]s)(?!%[1]s)|(?<!%[1]s)(?=%[1]s))", asciiWordClass)
+	case syntax.OpNoWordBoundary:
+		fmt.Fprintf(b, "(?:(?<=%[1]s)(?=%[1]s)|(?<!%[1]s)(?!%[1]s))", asciiWordClass)
+	case syntax.OpCapture:
+		// Группы нужны только для сопоставления, захват не используется
+		writeGroup(b, re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		// Жадность квантификатора не влияет на то, совпадает ли строка целиком
+		writeGroup(b, re.Sub[0])
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteByte('*')
+		case syntax.OpPlus:
+			b.WriteByte('+')
+		default:
+			b.WriteByte('?')
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePostgresRegexp(b, sub)
+		}
+	case syntax.OpAlternate:
+		b.WriteString("(?:")
+		for i, sub := range re.Sub {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			writePostgresRegexp(b, sub)
+		}
+		b.WriteByte(')')
+	default:
+		// После Simplify других узлов (OpRepeat) не остается
+		writeNoMatch(b)
+	}
+}
+
+// writeGroup записывает узел в незахватывающей группе
+func writeGroup(b *strings.Builder, re *syntax.Regexp) {
+	b.WriteString("(?:")
+	writePostgresRegexp(b, re)
+	b.WriteByte(')')
+}
+
+// writeNoMatch записывает выражение, которое не совпадает ни с одной строкой
+// Текст в PostgreSQL не может содержать символ с кодом 0, а класс допускает только его
+func writeNoMatch(b *strings.Builder) {
+	b.WriteString(`[^\U00000001-\U0010FFFF]`)
+}
+
+// writeLiteralRune записывает символ вне скобочного выражения
+func writeLiteralRune(b *strings.Builder, r rune) {
+	switch {
+	case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		b.WriteRune(r)
+	case r < unicode.MaxASCII && unicode.IsPrint(r):
+		// В ARE обратная косая черта перед не буквой и не цифрой означает сам символ
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	default:
+		fmt.Fprintf(b, `\U%08X`, r)
+	}
+}
+
+// writeFoldedRune записывает символ без учета регистра как класс из всех его регистровых вариантов
+// Флаг (?i) в ARE допустим только в начале выражения, поэтому регистр раскрывается здесь
+func writeFoldedRune(b *strings.Builder, r rune) {
+	runes := []rune{r}
+	for folded := unicode.SimpleFold(r); folded != r; folded = unicode.SimpleFold(folded) {
+		runes = append(runes, folded)
+	}
+	if len(runes) == 1 {
+		writeLiteralRune(b, r)
+		return
+	}
+
+	b.WriteByte('[')
+	for _, folded := range runes {
+		writeClassRune(b, folded)
+	}
+	b.WriteByte(']')
+}
+
+// writeCharClass записывает класс из пар диапазонов [lo, hi] разобранного RE2
+// Символ с кодом 0 и суррогаты не могут встретиться в тексте PostgreSQL и в классе пропускаются
+func writeCharClass(b *strings.Builder, ranges []rune) {
+	var written bool
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for _, part := range splitClassRange(ranges[i], ranges[i+1]) {
+			if !written {
+				b.WriteByte('[')
+				written = true
+			}
+			writeClassRune(b, part[0])
+			if part[1] != part[0] {
+				b.WriteByte('-')
+				writeClassRune(b, part[1])
+			}
+		}
+	}
+
+	if !written {
+		writeNoMatch(b)
+		return
+	}
+	b.WriteByte(']')
+}
+
+// splitClassRange обрезает диапазон до символов, допустимых в тексте PostgreSQL
+func splitClassRange(lo, hi rune) [][2]rune {
+	var parts [][2]rune
+	for _, allowed := range [][2]rune{{1, 0xD7FF}, {0xE000, unicode.MaxRune}} {
+		from, to := max(lo, allowed[0]), min(hi, allowed[1])
+		if from <= to {
+			parts = append(parts, [2]rune{from, to})
+		}
+	}
+	return parts
+}
+
+// writeClassRune записывает символ внутри скобочного выражения
+func writeClassRune(b *strings.Builder, r rune) {
+	if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		b.WriteRune(r)
+		return
+	}
+	fmt.Fprintf(b, `\U%08X`, r)
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import "testing"
+
+func TestPostgresRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "web-.*", want: `^(?:web\-(?:[^\n])*)$`},
+		{pattern: "sda|sdb", want: `^(?:sd[a-b])$`},
+		{pattern: "(?i)eth", want: `^(?:[Ee][Tt][Hh])$`},
+		{pattern: `a\b`, want: `^(?:a(?:(?<=[0-9A-Za-z_])(?![0-9A-Za-z_])|(?<![0-9A-Za-z_])(?=[0-9A-Za-z_])))$`},
+		{pattern: "x{2,3}", want: `^(?:xx(?:x)?)$`},
+		{pattern: "[^a]", want: `^(?:[\U00000001-\U00000060b-\U0000D7FF\U0000E000-\U0010FFFF])$`},
+		{pattern: "(?s:.)", want: `^(?:.)$`},
+		{pattern: "", want: `^(?:(?:))$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := postgresRegexp(tt.pattern)
+			if err != nil {
+				t.Fatalf("postgresRegexp() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("postgresRegexp(%q) = %s, want %s", tt.pattern, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := postgresRegexp("(unclosed"); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+}
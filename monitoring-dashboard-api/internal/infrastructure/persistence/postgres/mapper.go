@@ -14,6 +14,8 @@ type MetricDBModel struct {
 	ID          string
 	MetricType  string
 	MetricName  string
+	Host        string
+	Labels      []byte // JSON
 	Value       float64
 	Unit        string
 	Metadata    []byte // JSON
@@ -34,10 +36,17 @@ func ToDBModel(metric *entity.Metric) (*MetricDBModel, error) {
 		}
 	}
 
+	labelsBytes, err := json.Marshal(metric.Labels().Map())
+	if err != nil {
+		return nil, err
+	}
+
 	return &MetricDBModel{
 		ID:          metric.ID(),
 		MetricType:  metric.Type().String(),
 		MetricName:  metric.Name(),
+		Host:        metric.Host(),
+		Labels:      labelsBytes,
 		Value:       metric.Value().Raw(),
 		Unit:        metric.Value().Unit(),
 		Metadata:    metadataBytes,
@@ -56,6 +65,19 @@ func ToEntity(model *MetricDBModel) (*entity.Metric, error) {
 		}
 	}
 
+	// Парсим labels
+	var rawLabels map[string]string
+	if len(model.Labels) > 0 {
+		if err := json.Unmarshal(model.Labels, &rawLabels); err != nil {
+			return nil, err
+		}
+	}
+
+	labels, err := valueobject.NewLabels(rawLabels)
+	if err != nil {
+		return nil, err
+	}
+
 	// Создаем MetricType
 	metricType := valueobject.MetricType(model.MetricType)
 
@@ -70,6 +92,8 @@ func ToEntity(model *MetricDBModel) (*entity.Metric, error) {
 		model.ID,
 		metricType,
 		model.MetricName,
+		model.Host,
+		labels,
 		metricValue,
 		metadata,
 		model.CollectedAt,
@@ -85,11 +109,14 @@ func ScanMetricRow(row interface {
 }) (*MetricDBModel, error) {
 	var model MetricDBModel
 	var metadata sql.NullString
+	var labels sql.NullString
 
 	err := row.Scan(
 		&model.ID,
 		&model.MetricType,
 		&model.MetricName,
+		&model.Host,
+		&labels,
 		&model.Value,
 		&model.Unit,
 		&metadata,
@@ -105,5 +132,9 @@ func ScanMetricRow(row interface {
 		model.Metadata = []byte(metadata.String)
 	}
 
+	if labels.Valid {
+		model.Labels = []byte(labels.String)
+	}
+
 	return &model, nil
 }
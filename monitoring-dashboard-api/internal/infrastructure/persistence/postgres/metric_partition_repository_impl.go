@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/lib/pq"
+)
+
+// partitionBoundLayout формат границ партиции в pg_get_expr при TimeZone = UTC
+const partitionBoundLayout = "2006-01-02 15:04:05-07"
+
+// partitionBoundPattern разбирает "FOR VALUES FROM ('...') TO ('...')"
+var partitionBoundPattern = regexp.MustCompile(`FROM \('([^']+)'\) TO \('([^']+)'\)`)
+
+// PostgresMetricPartitionRepository реализует repository.MetricPartitionRepository для PostgreSQL
+// Партиции metrics_pYYYYMMDD подключаются к секционированной по collected_at таблице metrics
+type PostgresMetricPartitionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresMetricPartitionRepository создает новый repository партиций метрик
+func NewPostgresMetricPartitionRepository(db *sql.DB) *PostgresMetricPartitionRepository {
+	return &PostgresMetricPartitionRepository{
+		db: db,
+	}
+}
+
+// ListPartitions возвращает партиции таблицы metrics по диапазонам
+func (r *PostgresMetricPartitionRepository) ListPartitions(ctx context.Context) ([]repository.MetricPartition, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Границы форматируются в часовом поясе сессии
+	if _, err := tx.ExecContext(ctx, "SET LOCAL TimeZone = 'UTC'"); err != nil {
+		return nil, fmt.Errorf("failed to set session time zone: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT c.relname, pg_get_expr(c.relpartbound, c.oid)
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'metrics'::regclass
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []repository.MetricPartition
+	for rows.Next() {
+		var name, bound string
+		if err := rows.Scan(&name, &bound); err != nil {
+			return nil, fmt.Errorf("failed to scan metric partition: %w", err)
+		}
+		if strings.TrimSpace(bound) == "DEFAULT" {
+			continue
+		}
+
+		partition, err := parsePartitionBound(name, bound)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, partition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].From.Before(partitions[j].From) })
+	return partitions, nil
+}
+
+// parsePartitionBound разбирает границы партиции из pg_get_expr
+func parsePartitionBound(name, bound string) (repository.MetricPartition, error) {
+	match := partitionBoundPattern.FindStringSubmatch(bound)
+	if match == nil {
+		return repository.MetricPartition{}, fmt.Errorf("unexpected bound of partition %s: %s", name, bound)
+	}
+
+	from, err := time.Parse(partitionBoundLayout, match[1])
+	if err != nil {
+		return repository.MetricPartition{}, fmt.Errorf("invalid lower bound of partition %s: %w", name, err)
+	}
+	to, err := time.Parse(partitionBoundLayout, match[2])
+	if err != nil {
+		return repository.MetricPartition{}, fmt.Errorf("invalid upper bound of partition %s: %w", name, err)
+	}
+
+	return repository.MetricPartition{Name: name, From: from.UTC(), To: to.UTC()}, nil
+}
+
+// partitionName возвращает имя партиции по началу диапазона
+// Имя содержит только дату, поэтому диапазон должен начинаться в полночь UTC и длиться не меньше суток:
+// иначе у двух партиций одного дня совпали бы имена
+func partitionName(from, to time.Time) (string, error) {
+	from = from.UTC()
+	if !from.Equal(from.Truncate(24*time.Hour)) || to.Sub(from) < 24*time.Hour {
+		return "", fmt.Errorf("partition [%s, %s) must start at midnight UTC and span at least a day",
+			from.Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	}
+	return "metrics_p" + from.Format("20060102"), nil
+}
+
+// CreatePartition создает партицию [from, to) и переносит в нее строки из metrics_default
+// Без переноса подключение партиции завершилось бы ошибкой, если в metrics_default есть строки диапазона
+func (r *PostgresMetricPartitionRepository) CreatePartition(
+	ctx context.Context,
+	from, to time.Time,
+) (repository.MetricPartition, error) {
+	name, err := partitionName(from, to)
+	if err != nil {
+		return repository.MetricPartition{From: from.UTC(), To: to.UTC()}, err
+	}
+	partition := repository.MetricPartition{Name: name, From: from.UTC(), To: to.UTC()}
+	table := pq.QuoteIdentifier(partition.Name)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return partition, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{query: fmt.Sprintf("CREATE TABLE %s (LIKE metrics INCLUDING DEFAULTS INCLUDING CONSTRAINTS)", table)},
+		{
+			query: fmt.Sprintf(`
+				WITH moved AS (
+					DELETE FROM metrics_default
+					WHERE collected_at >= $1 AND collected_at < $2
+					RETURNING *
+				)
+				INSERT INTO %s SELECT * FROM moved
+			`, table),
+			args: []interface{}{partition.From, partition.To},
+		},
+		{
+			query: fmt.Sprintf("ALTER TABLE metrics ATTACH PARTITION %s FOR VALUES FROM (%s) TO (%s)",
+				table,
+				pq.QuoteLiteral(partition.From.Format(time.RFC3339)),
+				pq.QuoteLiteral(partition.To.Format(time.RFC3339)),
+			),
+		},
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return partition, fmt.Errorf("failed to create partition %s: %w", partition.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return partition, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return partition, nil
+}
+
+// DropPartition отключает и удаляет партицию
+func (r *PostgresMetricPartitionRepository) DropPartition(ctx context.Context, name string) error {
+	if _, err := r.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+pq.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("failed to drop partition %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartitionName(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     string
+		wantErr  bool
+	}{
+		{name: "daily", from: day, to: day.Add(24 * time.Hour), want: "metrics_p20260302"},
+		{name: "weekly", from: day, to: day.Add(7 * 24 * time.Hour), want: "metrics_p20260302"},
+		{name: "shorter than a day", from: day, to: day.Add(6 * time.Hour), wantErr: true},
+		{name: "not at midnight", from: day.Add(6 * time.Hour), to: day.Add(30 * time.Hour), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := partitionName(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("partitionName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("partitionName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
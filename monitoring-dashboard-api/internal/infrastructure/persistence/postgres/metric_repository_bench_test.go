@@ -0,0 +1,144 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+)
+
+// benchHost хост, которым помечаются строки бенчмарка, чтобы удалить их после прогона
+const benchHost = "bench-ingest"
+
+// openBenchDB подключается к БД с примененными миграциями (INTEGRATION_POSTGRES_DSN)
+func openBenchDB(b *testing.B) *sql.DB {
+	b.Helper()
+
+	dsn := os.Getenv("INTEGRATION_POSTGRES_DSN")
+	if dsn == "" {
+		dsn = "host=localhost port=5432 user=postgres password=postgres dbname=monitoring sslmode=disable"
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		b.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		b.Skipf("postgres is not available: %v", err)
+	}
+
+	b.Cleanup(func() {
+		_, _ = db.Exec("DELETE FROM metrics WHERE host = $1", benchHost)
+		_ = db.Close()
+	})
+	return db
+}
+
+func benchMetrics(b *testing.B, count int) []*entity.Metric {
+	b.Helper()
+
+	labels, err := valueobject.NewLabels(map[string]string{"core": "0", "mode": "user"})
+	if err != nil {
+		b.Fatalf("NewLabels() error = %v", err)
+	}
+
+	now := time.Now().UTC()
+	metrics := make([]*entity.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		value, err := valueobject.NewMetricValue(float64(i%100), "%")
+		if err != nil {
+			b.Fatalf("NewMetricValue() error = %v", err)
+		}
+		collectedAt := now.Add(-time.Duration(i) * time.Millisecond)
+		metrics = append(metrics, entity.Reconstruct(
+			uuid.New().String(), valueobject.CPU, "cpu_usage", benchHost, labels, value, nil, collectedAt, now,
+		))
+	}
+	return metrics
+}
+
+// savePrepared прежняя реализация SaveBatch: prepared INSERT на каждую строку
+func savePrepared(ctx context.Context, db *sql.DB, models []*MetricDBModel) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO metrics (id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, model := range models {
+		if _, err := stmt.ExecContext(ctx,
+			model.ID, model.MetricType, model.MetricName, model.Host, model.Labels,
+			model.Value, model.Unit, model.Metadata, model.CollectedAt, model.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// BenchmarkMetricBatchInsert сравнивает способы записи пачки метрик
+//
+//	go test -tags integration -run '^$' -bench BenchmarkMetricBatchInsert ./internal/infrastructure/persistence/postgres/
+func BenchmarkMetricBatchInsert(b *testing.B) {
+	db := openBenchDB(b)
+	repo := NewPostgresMetricRepository(db)
+	ctx := context.Background()
+
+	writers := []struct {
+		name  string
+		write func(models []*MetricDBModel) error
+	}{
+		{name: "prepared", write: func(models []*MetricDBModel) error { return savePrepared(ctx, db, models) }},
+		{name: "values", write: func(models []*MetricDBModel) error { return repo.insertModels(ctx, models) }},
+		{name: "copy", write: func(models []*MetricDBModel) error { return repo.copyModels(ctx, models) }},
+	}
+
+	for _, size := range []int{10, 100, 1000, 5000} {
+		for _, writer := range writers {
+			b.Run(fmt.Sprintf("%s/%d", writer.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					// Новые ID на каждой итерации, конвертация вне замера
+					b.StopTimer()
+					models := make([]*MetricDBModel, 0, size)
+					for _, metric := range benchMetrics(b, size) {
+						model, err := ToDBModel(metric)
+						if err != nil {
+							b.Fatalf("ToDBModel() error = %v", err)
+						}
+						models = append(models, model)
+					}
+					b.StartTimer()
+
+					if err := writer.write(models); err != nil {
+						b.Fatalf("%s write error = %v", writer.name, err)
+					}
+				}
+				b.ReportMetric(float64(b.N*size)/b.Elapsed().Seconds(), "rows/s")
+			})
+		}
+	}
+}
@@ -3,11 +3,14 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // PostgresMetricRepository реализует repository.MetricRepository для PostgreSQL
@@ -30,14 +33,16 @@ func (r *PostgresMetricRepository) Save(ctx context.Context, metric *entity.Metr
 	}
 
 	query := `
-		INSERT INTO metrics (id, metric_type, metric_name, value, unit, metadata, collected_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO metrics (id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	_, err = r.db.ExecContext(ctx, query,
 		model.ID,
 		model.MetricType,
 		model.MetricName,
+		model.Host,
+		model.Labels,
 		model.Value,
 		model.Unit,
 		model.Metadata,
@@ -52,12 +57,56 @@ func (r *PostgresMetricRepository) Save(ctx context.Context, metric *entity.Metr
 	return nil
 }
 
+// copyBatchThreshold минимальный размер пачки, начиная с которого запись идет через COPY
+// Для маленьких пачек один multi-row INSERT дешевле: COPY требует отдельной транзакции и протокольного обмена
+const copyBatchThreshold = 64
+
+// insertChunkRows максимальное число строк в одном multi-row INSERT
+// PostgreSQL ограничивает запрос 65535 параметрами, на строку приходится len(metricInsertColumns)
+const insertChunkRows = 1000
+
+// metricInsertColumns колонки таблицы metrics в порядке записи (совпадает с metricColumns)
+var metricInsertColumns = []string{
+	"id", "metric_type", "metric_name", "host", "labels", "value", "unit", "metadata", "collected_at", "created_at",
+}
+
 // SaveBatch сохраняет несколько метрик одной транзакцией
+// Крупные пачки пишутся через COPY FROM, маленькие - одним multi-row INSERT
 func (r *PostgresMetricRepository) SaveBatch(ctx context.Context, metrics []*entity.Metric) error {
 	if len(metrics) == 0 {
 		return nil
 	}
 
+	models := make([]*MetricDBModel, 0, len(metrics))
+	for _, metric := range metrics {
+		model, err := ToDBModel(metric)
+		if err != nil {
+			return fmt.Errorf("%w: failed to convert metric to DB model: %v", repository.ErrMetricRejected, err)
+		}
+		models = append(models, model)
+	}
+
+	var err error
+	if len(models) >= copyBatchThreshold {
+		err = r.copyModels(ctx, models)
+	} else {
+		err = r.insertModels(ctx, models)
+	}
+	return classifyWriteError(err)
+}
+
+// classifyWriteError помечает ошибки данных (класс 22) и нарушения ограничений (класс 23)
+// как repository.ErrMetricRejected: в отличие от сбоев соединения они не исчезнут при повторе
+func classifyWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23") {
+		return fmt.Errorf("%w: %v", repository.ErrMetricRejected, err)
+	}
+	return err
+}
+
+// copyModels записывает строки через COPY FROM STDIN в одной транзакции
+func (r *PostgresMetricRepository) copyModels(ctx context.Context, models []*MetricDBModel) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -66,36 +115,41 @@ func (r *PostgresMetricRepository) SaveBatch(ctx context.Context, metrics []*ent
 		_ = tx.Rollback()
 	}()
 
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO metrics (id, metric_type, metric_name, value, unit, metadata, collected_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	`)
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("metrics", metricInsertColumns...))
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return fmt.Errorf("failed to prepare copy: %w", err)
 	}
 	defer stmt.Close()
 
-	for _, metric := range metrics {
-		model, err := ToDBModel(metric)
-		if err != nil {
-			return fmt.Errorf("failed to convert metric to DB model: %w", err)
+	for _, model := range models {
+		// JSON передается строкой: []byte в COPY кодируется как bytea
+		var metadata interface{}
+		if len(model.Metadata) > 0 {
+			metadata = string(model.Metadata)
 		}
 
 		_, err = stmt.ExecContext(ctx,
 			model.ID,
 			model.MetricType,
 			model.MetricName,
+			model.Host,
+			string(model.Labels),
 			model.Value,
 			model.Unit,
-			model.Metadata,
+			metadata,
 			model.CollectedAt,
 			model.CreatedAt,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to insert metric: %w", err)
+			return fmt.Errorf("failed to copy metric: %w", err)
 		}
 	}
 
+	// Пустой Exec завершает COPY и отправляет буфер серверу
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush copy: %w", err)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -103,10 +157,75 @@ func (r *PostgresMetricRepository) SaveBatch(ctx context.Context, metrics []*ent
 	return nil
 }
 
+// insertModels записывает строки multi-row INSERT частями по insertChunkRows
+func (r *PostgresMetricRepository) insertModels(ctx context.Context, models []*MetricDBModel) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for start := 0; start < len(models); start += insertChunkRows {
+		end := start + insertChunkRows
+		if end > len(models) {
+			end = len(models)
+		}
+
+		query, args := buildMultiRowInsert(models[start:end])
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert metrics: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// buildMultiRowInsert строит INSERT ... VALUES (...), (...) для пачки строк
+func buildMultiRowInsert(models []*MetricDBModel) (string, []interface{}) {
+	var query strings.Builder
+	query.WriteString("INSERT INTO metrics (" + metricColumns + ") VALUES ")
+
+	args := make([]interface{}, 0, len(models)*len(metricInsertColumns))
+	for i, model := range models {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteByte('(')
+		for column := range metricInsertColumns {
+			if column > 0 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*len(metricInsertColumns)+column+1)
+		}
+		query.WriteByte(')')
+
+		args = append(args,
+			model.ID,
+			model.MetricType,
+			model.MetricName,
+			model.Host,
+			model.Labels,
+			model.Value,
+			model.Unit,
+			model.Metadata,
+			model.CollectedAt,
+			model.CreatedAt,
+		)
+	}
+
+	return query.String(), args
+}
+
 // FindByID находит метрику по идентификатору
 func (r *PostgresMetricRepository) FindByID(ctx context.Context, id string) (*entity.Metric, error) {
 	query := `
-		SELECT id, metric_type, metric_name, value, unit, metadata, collected_at, created_at
+		SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
 		FROM metrics
 		WHERE id = $1
 	`
@@ -130,7 +249,7 @@ func (r *PostgresMetricRepository) FindByType(
 	limit int,
 ) ([]*entity.Metric, error) {
 	query := `
-		SELECT id, metric_type, metric_name, value, unit, metadata, collected_at, created_at
+		SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
 		FROM metrics
 		WHERE metric_type = $1
 		ORDER BY collected_at DESC
@@ -158,7 +277,7 @@ func (r *PostgresMetricRepository) FindByTimeRange(
 	const maxRecords = 5000
 
 	query := `
-		SELECT id, metric_type, metric_name, value, unit, metadata, collected_at, created_at
+		SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
 		FROM metrics
 		WHERE metric_type = $1 AND collected_at BETWEEN $2 AND $3
 		ORDER BY collected_at DESC
@@ -179,13 +298,47 @@ func (r *PostgresMetricRepository) FindByTimeRange(
 	return r.scanMetrics(rows)
 }
 
+// FindByHostAndTimeRange находит метрики хоста по типу и временному диапазону
+func (r *PostgresMetricRepository) FindByHostAndTimeRange(
+	ctx context.Context,
+	host string,
+	metricType valueobject.MetricType,
+	timeRange valueobject.TimeRange,
+) ([]*entity.Metric, error) {
+	// Тот же лимит, что и в FindByTimeRange
+	const maxRecords = 5000
+
+	query := `
+		SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
+		FROM metrics
+		WHERE host = $1 AND metric_type = $2 AND collected_at BETWEEN $3 AND $4
+		ORDER BY collected_at DESC
+		LIMIT $5
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		host,
+		metricType.String(),
+		timeRange.Start(),
+		timeRange.End(),
+		maxRecords,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMetrics(rows)
+}
+
 // FindLatest находит последние метрики каждого типа
+// Из одновременных серий типа (разделы, интерфейсы) выбирается серия с наибольшим значением
 func (r *PostgresMetricRepository) FindLatest(ctx context.Context) (map[valueobject.MetricType]*entity.Metric, error) {
 	query := `
 		SELECT DISTINCT ON (metric_type)
-			id, metric_type, metric_name, value, unit, metadata, collected_at, created_at
+			id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
 		FROM metrics
-		ORDER BY metric_type, collected_at DESC
+		ORDER BY metric_type, collected_at DESC, value DESC
 	`
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -208,13 +361,46 @@ func (r *PostgresMetricRepository) FindLatest(ctx context.Context) (map[valueobj
 	return result, nil
 }
 
+// FindLatestByHost находит последние метрики каждого типа для указанного хоста
+// Из одновременных серий типа выбирается серия с наибольшим значением
+func (r *PostgresMetricRepository) FindLatestByHost(
+	ctx context.Context,
+	host string,
+) (map[valueobject.MetricType]*entity.Metric, error) {
+	query := `
+		SELECT DISTINCT ON (metric_type)
+			id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
+		FROM metrics
+		WHERE host = $1
+		ORDER BY metric_type, collected_at DESC, value DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest metrics for host: %w", err)
+	}
+	defer rows.Close()
+
+	metrics, err := r.scanMetrics(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[valueobject.MetricType]*entity.Metric)
+	for _, metric := range metrics {
+		result[metric.Type()] = metric
+	}
+
+	return result, nil
+}
+
 // FindLatestByType находит последнюю метрику указанного типа
 func (r *PostgresMetricRepository) FindLatestByType(
 	ctx context.Context,
 	metricType valueobject.MetricType,
 ) (*entity.Metric, error) {
 	query := `
-		SELECT id, metric_type, metric_name, value, unit, metadata, collected_at, created_at
+		SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
 		FROM metrics
 		WHERE metric_type = $1
 		ORDER BY collected_at DESC
@@ -254,6 +440,74 @@ func (r *PostgresMetricRepository) DeleteOlderThan(ctx context.Context, timeRang
 	return nil
 }
 
+// CountExpired возвращает количество метрик с истекшим сроком хранения
+func (r *PostgresMetricRepository) CountExpired(ctx context.Context, query repository.RetentionQuery) (int64, error) {
+	where := buildRetentionWhere(query)
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM metrics "+where.sql(), where.args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired metrics: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteExpired удаляет пачку метрик с истекшим сроком хранения, начиная с самых старых
+// Ограничение пачки держит транзакцию и блокировки короткими
+func (r *PostgresMetricRepository) DeleteExpired(ctx context.Context, query repository.RetentionQuery) (int64, error) {
+	where := buildRetentionWhere(query)
+
+	statement := "DELETE FROM metrics " + where.sql()
+	if query.Limit > 0 {
+		limitArg := where.arg(query.Limit)
+		statement = fmt.Sprintf(`
+			DELETE FROM metrics
+			WHERE id IN (
+				SELECT id FROM metrics
+				%s
+				ORDER BY collected_at
+				LIMIT %s
+			)
+		`, where.sql(), limitArg)
+	}
+
+	result, err := r.db.ExecContext(ctx, statement, where.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired metrics: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted rows count: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// buildRetentionWhere транслирует RetentionQuery в условия SQL
+func buildRetentionWhere(query repository.RetentionQuery) *whereBuilder {
+	b := &whereBuilder{}
+
+	b.add("collected_at < " + b.arg(query.Before))
+	if len(query.Types) > 0 {
+		b.add("metric_type = ANY(" + b.arg(pq.Array(metricTypeStrings(query.Types))) + ")")
+	}
+	if len(query.ExcludeTypes) > 0 {
+		b.add("metric_type <> ALL(" + b.arg(pq.Array(metricTypeStrings(query.ExcludeTypes))) + ")")
+	}
+
+	return b
+}
+
+func metricTypeStrings(types []valueobject.MetricType) []string {
+	result := make([]string, len(types))
+	for i, metricType := range types {
+		result[i] = metricType.String()
+	}
+	return result
+}
+
 // Count возвращает количество метрик по типу
 func (r *PostgresMetricRepository) Count(ctx context.Context, metricType valueobject.MetricType) (int64, error) {
 	query := `
@@ -295,3 +549,116 @@ func (r *PostgresMetricRepository) scanMetrics(rows *sql.Rows) ([]*entity.Metric
 
 	return metrics, nil
 }
+
+// FindByLabels находит метрики по типу, имени, селектору меток и временному диапазону
+func (r *PostgresMetricRepository) FindByLabels(
+	ctx context.Context,
+	query repository.MetricQuery,
+) ([]*entity.Metric, error) {
+	where, err := buildMetricQueryWhere(query)
+	if err != nil {
+		return nil, err
+	}
+	limit := where.arg(queryLimit(query))
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM metrics
+		%s
+		ORDER BY collected_at DESC
+		LIMIT %s
+	`, metricColumns, where.sql(), limit)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics by labels: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMetrics(rows)
+}
+
+// FindPage находит страницу метрик по возрастанию (collected_at, id) после курсора (keyset pagination)
+func (r *PostgresMetricRepository) FindPage(
+	ctx context.Context,
+	query repository.MetricQuery,
+	after *repository.MetricCursor,
+) ([]*entity.Metric, error) {
+	where, err := buildMetricQueryWhere(query)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		where.add(fmt.Sprintf("(collected_at, id) > (%s, %s)", where.arg(after.CollectedAt), where.arg(after.ID)))
+	}
+	limit := where.arg(queryLimit(query))
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM metrics
+		%s
+		ORDER BY collected_at, id
+		LIMIT %s
+	`, metricColumns, where.sql(), limit)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics page: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMetrics(rows)
+}
+
+// FindLatestSeries находит последнее значение каждой серии (type, name, host, labels)
+func (r *PostgresMetricRepository) FindLatestSeries(
+	ctx context.Context,
+	query repository.MetricQuery,
+) ([]*entity.Metric, error) {
+	where, err := buildMetricQueryWhere(query)
+	if err != nil {
+		return nil, err
+	}
+	limit := where.arg(queryLimit(query))
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT DISTINCT ON (metric_type, metric_name, host, labels)
+			%s
+		FROM metrics
+		%s
+		ORDER BY metric_type, metric_name, host, labels, collected_at DESC
+		LIMIT %s
+	`, metricColumns, where.sql(), limit)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest series: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanMetrics(rows)
+}
+
+// GroupByLabel находит метрики по запросу и группирует их по значению метки
+func (r *PostgresMetricRepository) GroupByLabel(
+	ctx context.Context,
+	query repository.MetricQuery,
+	label string,
+) (map[string][]*entity.Metric, error) {
+	if err := valueobject.ValidateLabelName(label); err != nil {
+		return nil, fmt.Errorf("invalid group label: %w", err)
+	}
+
+	metrics, err := r.FindByLabels(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]*entity.Metric)
+	for _, metric := range metrics {
+		key := metric.LabelValue(label)
+		groups[key] = append(groups[key], metric)
+	}
+
+	return groups, nil
+}
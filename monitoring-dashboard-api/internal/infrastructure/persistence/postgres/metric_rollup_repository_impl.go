@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+)
+
+// defaultRollupQueryLimit лимит выборки бакетов
+// Уровень выбирается так, чтобы на серию приходилось до ~2000 бакетов, лимит рассчитан на десятки серий
+const defaultRollupQueryLimit = 50000
+
+// rollupColumns список колонок, который ожидает scanRollups
+const rollupColumns = `metric_type, metric_name, host, labels, unit, bucket_start,
+	min_value, max_value, sum_value, sample_count, last_value, last_at`
+
+// rollupUpsert перезаписывает пересчитанные бакеты целиком
+const rollupUpsert = `
+	ON CONFLICT (metric_type, metric_name, host, labels, bucket_start) DO UPDATE SET
+		unit = EXCLUDED.unit,
+		min_value = EXCLUDED.min_value,
+		max_value = EXCLUDED.max_value,
+		sum_value = EXCLUDED.sum_value,
+		sample_count = EXCLUDED.sample_count,
+		last_value = EXCLUDED.last_value,
+		last_at = EXCLUDED.last_at
+`
+
+// PostgresMetricRollupRepository реализует repository.MetricRollupRepository для PostgreSQL
+// Каждый уровень хранится в своей таблице metrics_rollup_<tier>
+type PostgresMetricRollupRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresMetricRollupRepository создает новый repository уровней агрегации
+func NewPostgresMetricRollupRepository(db *sql.DB) *PostgresMetricRollupRepository {
+	return &PostgresMetricRollupRepository{
+		db: db,
+	}
+}
+
+// rollupTable возвращает таблицу уровня агрегации
+func rollupTable(tier valueobject.RollupTier) (string, error) {
+	if tier == valueobject.TierRaw {
+		return "", errors.New("raw tier has no rollup table")
+	}
+	if err := tier.Validate(); err != nil {
+		return "", err
+	}
+	return "metrics_rollup_" + tier.String(), nil
+}
+
+// Rollup пересчитывает бакеты уровня в интервале [from, to) из сырых метрик или предыдущего уровня
+func (r *PostgresMetricRollupRepository) Rollup(
+	ctx context.Context,
+	tier valueobject.RollupTier,
+	from, to time.Time,
+) (int64, error) {
+	table, err := rollupTable(tier)
+	if err != nil {
+		return 0, err
+	}
+
+	bucket := fmt.Sprintf("%d seconds", int64(tier.Resolution()/time.Second))
+
+	var query string
+	if source := tier.Source(); source == valueobject.TierRaw {
+		query = fmt.Sprintf(`
+			INSERT INTO %s (%s)
+			SELECT
+				metric_type, metric_name, host, labels, MAX(unit),
+				date_bin($1::interval, collected_at, TIMESTAMPTZ '1970-01-01 00:00:00+00') AS bucket,
+				MIN(value), MAX(value), SUM(value), COUNT(*),
+				(ARRAY_AGG(value ORDER BY collected_at DESC))[1], MAX(collected_at)
+			FROM metrics
+			WHERE collected_at >= $2 AND collected_at < $3
+			GROUP BY metric_type, metric_name, host, labels, bucket
+			%s
+		`, table, rollupColumns, rollupUpsert)
+	} else {
+		sourceTable, err := rollupTable(source)
+		if err != nil {
+			return 0, err
+		}
+		query = fmt.Sprintf(`
+			INSERT INTO %s (%s)
+			SELECT
+				metric_type, metric_name, host, labels, MAX(unit),
+				date_bin($1::interval, bucket_start, TIMESTAMPTZ '1970-01-01 00:00:00+00') AS bucket,
+				MIN(min_value), MAX(max_value), SUM(sum_value), SUM(sample_count),
+				(ARRAY_AGG(last_value ORDER BY last_at DESC))[1], MAX(last_at)
+			FROM %s
+			WHERE bucket_start >= $2 AND bucket_start < $3
+			GROUP BY metric_type, metric_name, host, labels, bucket
+			%s
+		`, table, rollupColumns, sourceTable, rollupUpsert)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, bucket, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build %s rollups: %w", tier, err)
+	}
+
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get written rollups count: %w", err)
+	}
+
+	return written, nil
+}
+
+// FindRollups находит бакеты уровня по типу, имени, селектору меток и временному диапазону
+func (r *PostgresMetricRollupRepository) FindRollups(
+	ctx context.Context,
+	tier valueobject.RollupTier,
+	query repository.MetricQuery,
+) ([]*entity.MetricRollup, error) {
+	table, err := rollupTable(tier)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 || limit > defaultRollupQueryLimit {
+		limit = defaultRollupQueryLimit
+	}
+
+	where, err := buildSeriesQueryWhere(query, "bucket_start")
+	if err != nil {
+		return nil, err
+	}
+	limitArg := where.arg(limit)
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		%s
+		ORDER BY bucket_start DESC
+		LIMIT %s
+	`, rollupColumns, table, where.sql(), limitArg)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s rollups: %w", tier, err)
+	}
+	defer rows.Close()
+
+	return scanRollups(rows)
+}
+
+// Watermark возвращает момент, до которого уровень построен
+func (r *PostgresMetricRollupRepository) Watermark(ctx context.Context, tier valueobject.RollupTier) (time.Time, error) {
+	var watermark time.Time
+	err := r.db.QueryRowContext(ctx,
+		`SELECT rolled_up_to FROM metrics_rollup_watermarks WHERE tier = $1`,
+		tier.String(),
+	).Scan(&watermark)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+
+	return watermark, nil
+}
+
+// SetWatermark сохраняет момент, до которого уровень построен
+func (r *PostgresMetricRollupRepository) SetWatermark(ctx context.Context, tier valueobject.RollupTier, at time.Time) error {
+	query := `
+		INSERT INTO metrics_rollup_watermarks (tier, rolled_up_to, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (tier) DO UPDATE SET
+			rolled_up_to = EXCLUDED.rolled_up_to,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, tier.String(), at); err != nil {
+		return fmt.Errorf("failed to save rollup watermark: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBefore удаляет бакеты уровня, начавшиеся раньше before
+func (r *PostgresMetricRollupRepository) DeleteBefore(
+	ctx context.Context,
+	tier valueobject.RollupTier,
+	before time.Time,
+) (int64, error) {
+	table, err := rollupTable(tier)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE bucket_start < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired %s rollups: %w", tier, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted rows count: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// scanRollups сканирует строки уровня агрегации
+func scanRollups(rows *sql.Rows) ([]*entity.MetricRollup, error) {
+	var rollups []*entity.MetricRollup
+
+	for rows.Next() {
+		var rollup entity.MetricRollup
+		var metricType string
+		var labels sql.NullString
+
+		err := rows.Scan(
+			&metricType,
+			&rollup.Name,
+			&rollup.Host,
+			&labels,
+			&rollup.Unit,
+			&rollup.BucketStart,
+			&rollup.Min,
+			&rollup.Max,
+			&rollup.Sum,
+			&rollup.Count,
+			&rollup.Last,
+			&rollup.LastAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rollup row: %w", err)
+		}
+
+		var rawLabels map[string]string
+		if labels.Valid && labels.String != "" {
+			if err := json.Unmarshal([]byte(labels.String), &rawLabels); err != nil {
+				return nil, fmt.Errorf("failed to parse rollup labels: %w", err)
+			}
+		}
+		rollup.Labels, err = valueobject.NewLabels(rawLabels)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert rollup labels: %w", err)
+		}
+		rollup.Type = valueobject.MetricType(metricType)
+
+		rollups = append(rollups, &rollup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return rollups, nil
+}
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics
+    ADD COLUMN IF NOT EXISTS host VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Per-host dashboards and history queries (DISTINCT ON (metric_type) WHERE host = $1)
+CREATE INDEX IF NOT EXISTS idx_metrics_host_type_collected_at
+    ON metrics(host, metric_type, collected_at DESC);
+
+COMMENT ON COLUMN metrics.host IS 'Host identity of the machine the metric was collected on (empty for legacy rows)';
+COMMENT ON INDEX idx_metrics_host_type_collected_at IS 'Optimizes per-host latest and history queries';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_metrics_host_type_collected_at;
+ALTER TABLE metrics DROP COLUMN IF EXISTS host;
+-- +goose StatementEnd
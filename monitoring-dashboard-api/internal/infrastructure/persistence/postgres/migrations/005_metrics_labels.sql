@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE metrics
+    ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- Label selectors with equality matchers are translated to labels @> '{...}'
+CREATE INDEX IF NOT EXISTS idx_metrics_labels_gin
+    ON metrics USING GIN (labels jsonb_path_ops);
+
+-- Latest value per series (DISTINCT ON metric_type, metric_name, host, labels)
+CREATE INDEX IF NOT EXISTS idx_metrics_series_collected_at
+    ON metrics(metric_type, metric_name, host, collected_at DESC);
+
+COMMENT ON COLUMN metrics.labels IS 'Series dimensions (mount, interface, core, environment, ...) as a flat string map';
+COMMENT ON INDEX idx_metrics_labels_gin IS 'Optimizes label selector queries';
+COMMENT ON INDEX idx_metrics_series_collected_at IS 'Optimizes per-series latest and range queries';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_metrics_series_collected_at;
+DROP INDEX IF EXISTS idx_metrics_labels_gin;
+ALTER TABLE metrics DROP COLUMN IF EXISTS labels;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Allowed metric types come from the application registry (METRIC_TYPES_FILE),
+-- so the database only checks the type name format
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_metric_type_check;
+
+ALTER TABLE metrics
+    ADD CONSTRAINT metrics_metric_type_format_check CHECK (metric_type ~ '^[a-z][a-z0-9_]*$');
+
+COMMENT ON COLUMN metrics.metric_type IS 'Type of metric from the metric type registry (cpu, memory, disk, network, ...)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE metrics DROP CONSTRAINT IF EXISTS metrics_metric_type_format_check;
+
+ALTER TABLE metrics
+    ADD CONSTRAINT metrics_metric_type_check CHECK (metric_type IN ('cpu', 'memory', 'disk', 'network'));
+
+COMMENT ON COLUMN metrics.metric_type IS 'Type of metric: cpu, memory, disk, network';
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    metric_type VARCHAR(20) NOT NULL,
+    metric_name VARCHAR(255) NOT NULL DEFAULT '',
+    unit VARCHAR(10) NOT NULL DEFAULT '',
+    selector TEXT NOT NULL DEFAULT '',
+    aggregate VARCHAR(8) NOT NULL DEFAULT 'avg' CHECK (aggregate IN ('avg', 'min', 'max', 'p95', 'last')),
+    window_seconds INTEGER NOT NULL DEFAULT 60 CHECK (window_seconds > 0),
+    comparator VARCHAR(2) NOT NULL CHECK (comparator IN ('>', '>=', '<', '<=', '==', '!=')),
+    threshold DOUBLE PRECISION NOT NULL,
+    for_seconds INTEGER NOT NULL DEFAULT 0 CHECK (for_seconds >= 0),
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0),
+    severity VARCHAR(16) NOT NULL DEFAULT 'warning' CHECK (severity IN ('info', 'warning', 'critical')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_enabled
+    ON alert_rules(enabled, name);
+
+COMMENT ON TABLE alert_rules IS 'Alert rules evaluated on every collection cycle';
+COMMENT ON COLUMN alert_rules.unit IS 'Unit of the compared values; empty matches any unit of the type';
+COMMENT ON COLUMN alert_rules.selector IS 'Label selector, e.g. {host="web-01",mount="/"}';
+COMMENT ON COLUMN alert_rules.for_seconds IS 'How long the condition must hold before the alert fires';
+COMMENT ON COLUMN alert_rules.cooldown_seconds IS 'Minimum interval between notifications for the same series';
+
+-- Default rules replace the former hard-coded "> 90%" check on every sample
+-- They are bound to the percentage series only: other series of the same types (bytes, ingested ones) are not compared with 90
+INSERT INTO alert_rules (name, description, metric_type, metric_name, unit, aggregate, window_seconds, comparator, threshold, for_seconds, cooldown_seconds, severity)
+VALUES
+    ('CPU usage critical', 'Average CPU usage above 90% for 1 minute', 'cpu', 'cpu_usage', '%', 'avg', 60, '>', 90, 60, 300, 'critical'),
+    ('Memory usage critical', 'Average memory usage above 90% for 1 minute', 'memory', 'memory_usage', '%', 'avg', 60, '>', 90, 60, 300, 'critical'),
+    ('Disk usage critical', 'Disk usage above 90%', 'disk', 'disk_usage', '%', 'last', 60, '>', 90, 0, 1800, 'critical');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS alert_rules;
+-- +goose StatementEnd
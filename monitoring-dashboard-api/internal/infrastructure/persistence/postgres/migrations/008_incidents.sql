@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    fingerprint VARCHAR(64) NOT NULL,
+    rule_id UUID REFERENCES alert_rules(id) ON DELETE SET NULL,
+    series_key TEXT NOT NULL DEFAULT '',
+    title VARCHAR(200) NOT NULL,
+    severity VARCHAR(16) NOT NULL CHECK (severity IN ('info', 'warning', 'critical')),
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    status VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    last_value DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_message TEXT NOT NULL DEFAULT '',
+    alert_count INTEGER NOT NULL DEFAULT 1 CHECK (alert_count > 0),
+    opened_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_alert_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_by VARCHAR(100) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by VARCHAR(100) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- At most one unresolved incident per alert series
+CREATE UNIQUE INDEX IF NOT EXISTS idx_incidents_active_fingerprint
+    ON incidents(fingerprint) WHERE status <> 'resolved';
+
+CREATE INDEX IF NOT EXISTS idx_incidents_fingerprint_opened
+    ON incidents(fingerprint, opened_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_status_opened
+    ON incidents(status, opened_at DESC);
+
+COMMENT ON TABLE incidents IS 'Incidents grouping repeated alerts of one series, with acknowledge/resolve lifecycle';
+COMMENT ON COLUMN incidents.fingerprint IS 'Grouping key: sha256 hex of alert rule id and series key (manual|<id> for manual incidents)';
+COMMENT ON COLUMN incidents.series_key IS 'Series key of the alert, used to restore alert state after a restart';
+COMMENT ON COLUMN incidents.alert_count IS 'Number of times the series started firing while the incident was open';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS incidents;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE alert_rules
+    ADD COLUMN IF NOT EXISTS channels TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN alert_rules.channels IS 'Notification channels of the rule; empty uses the default channels';
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    channel VARCHAR(50) NOT NULL,
+    channel_type VARCHAR(20) NOT NULL DEFAULT '',
+    rule_id VARCHAR(36) NOT NULL DEFAULT '',
+    rule_name VARCHAR(100) NOT NULL DEFAULT '',
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    alert_state VARCHAR(16) NOT NULL DEFAULT '',
+    severity VARCHAR(16) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_created
+    ON notification_deliveries(created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel_created
+    ON notification_deliveries(channel, created_at DESC);
+
+COMMENT ON TABLE notification_deliveries IS 'Delivery log of alert notifications to external channels';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_deliveries;
+ALTER TABLE alert_rules DROP COLUMN IF EXISTS channels;
+-- +goose StatementEnd
@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rollup tiers replace the metrics_hourly materialized view: they are maintained
+-- incrementally by the rollup worker instead of a full refresh over 30 days of raw data
+DROP FUNCTION IF EXISTS refresh_metrics_hourly();
+DROP MATERIALIZED VIEW IF EXISTS metrics_hourly;
+
+CREATE TABLE IF NOT EXISTS metrics_rollup_1m (
+    metric_type VARCHAR(20) NOT NULL,
+    metric_name VARCHAR(255) NOT NULL,
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    unit VARCHAR(10) NOT NULL,
+    bucket_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    min_value DOUBLE PRECISION NOT NULL,
+    max_value DOUBLE PRECISION NOT NULL,
+    sum_value DOUBLE PRECISION NOT NULL,
+    sample_count BIGINT NOT NULL CHECK (sample_count > 0),
+    last_value DOUBLE PRECISION NOT NULL,
+    last_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (metric_type, metric_name, host, labels, bucket_start)
+);
+
+CREATE TABLE IF NOT EXISTS metrics_rollup_5m (LIKE metrics_rollup_1m INCLUDING ALL);
+CREATE TABLE IF NOT EXISTS metrics_rollup_1h (LIKE metrics_rollup_1m INCLUDING ALL);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_rollup_1m_type_bucket ON metrics_rollup_1m(metric_type, bucket_start DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_rollup_5m_type_bucket ON metrics_rollup_5m(metric_type, bucket_start DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_rollup_1h_type_bucket ON metrics_rollup_1h(metric_type, bucket_start DESC);
+
+-- Tiers are built from the previous one, so range scans by bucket_start are needed for every tier
+CREATE INDEX IF NOT EXISTS idx_metrics_rollup_1m_bucket ON metrics_rollup_1m(bucket_start);
+CREATE INDEX IF NOT EXISTS idx_metrics_rollup_5m_bucket ON metrics_rollup_5m(bucket_start);
+CREATE INDEX IF NOT EXISTS idx_metrics_rollup_1h_bucket ON metrics_rollup_1h(bucket_start);
+
+CREATE TABLE IF NOT EXISTS metrics_rollup_watermarks (
+    tier VARCHAR(8) PRIMARY KEY,
+    rolled_up_to TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE metrics_rollup_1m IS '1-minute rollups (min/max/sum/count/last) built from raw metrics';
+COMMENT ON TABLE metrics_rollup_5m IS '5-minute rollups built from metrics_rollup_1m';
+COMMENT ON TABLE metrics_rollup_1h IS 'Hourly rollups built from metrics_rollup_5m';
+COMMENT ON TABLE metrics_rollup_watermarks IS 'End of the last rolled up interval per tier';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS metrics_rollup_watermarks;
+DROP TABLE IF EXISTS metrics_rollup_1h;
+DROP TABLE IF EXISTS metrics_rollup_5m;
+DROP TABLE IF EXISTS metrics_rollup_1m;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS metrics_hourly AS
+SELECT
+    metric_type,
+    metric_name,
+    DATE_TRUNC('hour', collected_at) as hour_bucket,
+    AVG(value) as avg_value,
+    MIN(value) as min_value,
+    MAX(value) as max_value,
+    COUNT(*) as sample_count,
+    unit
+FROM metrics
+WHERE collected_at > NOW() - INTERVAL '30 days'
+GROUP BY metric_type, metric_name, hour_bucket, unit;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_metrics_hourly_unique
+    ON metrics_hourly(metric_type, metric_name, hour_bucket);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_hourly_time
+    ON metrics_hourly(hour_bucket DESC);
+
+CREATE OR REPLACE FUNCTION refresh_metrics_hourly()
+RETURNS void AS $$
+BEGIN
+    REFRESH MATERIALIZED VIEW CONCURRENTLY metrics_hourly;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
@@ -0,0 +1,112 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Range partitioning by collected_at: daily partitions metrics_pYYYYMMDD are created ahead of time
+-- and dropped after the retention period by the partition manager of the API service.
+-- Rows outside of all partitions (agent clock skew, imports of old data) land in metrics_default.
+-- Partitions for existing rows go back at most 31 days: a single outlier timestamp must not create
+-- thousands of daily partitions. Older rows stay in metrics_default until the retention worker deletes them.
+-- The rebuilt table also widens value and metric_name: byte counters and names of ingested
+-- series (Prometheus, OTLP, StatsD) do not fit NUMERIC(15,2) and VARCHAR(50).
+CREATE TABLE metrics_partitioned (
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    metric_type VARCHAR(20) NOT NULL CHECK (metric_type ~ '^[a-z][a-z0-9_]*$'),
+    metric_name VARCHAR(255) NOT NULL,
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    value DOUBLE PRECISION NOT NULL CHECK (value >= 0),
+    unit VARCHAR(10) NOT NULL,
+    metadata JSONB,
+    collected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, collected_at)
+) PARTITION BY RANGE (collected_at);
+
+CREATE TABLE metrics_default PARTITION OF metrics_partitioned DEFAULT;
+
+DO $$
+DECLARE
+    day DATE;
+    first_day DATE := (NOW() AT TIME ZONE 'UTC')::date - 31;
+    last_day DATE := (NOW() AT TIME ZONE 'UTC')::date + 3;
+BEGIN
+    SELECT GREATEST(COALESCE(MIN((collected_at AT TIME ZONE 'UTC')::date), last_day - 3), first_day)
+    INTO day
+    FROM metrics;
+
+    WHILE day <= last_day LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF metrics_partitioned FOR VALUES FROM (%L) TO (%L)',
+            'metrics_p' || to_char(day, 'YYYYMMDD'),
+            day::timestamp AT TIME ZONE 'UTC',
+            (day + 1)::timestamp AT TIME ZONE 'UTC'
+        );
+        day := day + 1;
+    END LOOP;
+END $$;
+
+INSERT INTO metrics_partitioned (id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at)
+SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
+FROM metrics;
+
+DROP TABLE metrics;
+ALTER TABLE metrics_partitioned RENAME TO metrics;
+
+-- Indexes are created on the parent and propagated to every partition. Partition pruning replaces
+-- the plain collected_at, created_at and "recent 7 days" indexes of the unpartitioned table.
+CREATE INDEX IF NOT EXISTS idx_metrics_type_collected_at
+    ON metrics(metric_type, collected_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_host_type_collected_at
+    ON metrics(host, metric_type, collected_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_series_collected_at
+    ON metrics(metric_type, metric_name, host, collected_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_labels_gin
+    ON metrics USING GIN (labels jsonb_path_ops);
+
+COMMENT ON TABLE metrics IS 'System metrics collected over time, partitioned by collected_at';
+COMMENT ON COLUMN metrics.metric_type IS 'Type of metric from the metric type registry (cpu, memory, disk, network, ...)';
+COMMENT ON COLUMN metrics.value IS 'Metric value in unit; stored as float64 without rounding';
+COMMENT ON COLUMN metrics.host IS 'Host identity of the machine the metric was collected on (empty for legacy rows)';
+COMMENT ON COLUMN metrics.labels IS 'Series dimensions (mount, interface, core, environment, ...) as a flat string map';
+COMMENT ON TABLE metrics_default IS 'Metrics outside of all collected_at partitions';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- value and metric_name keep their widened types: narrowing them back would fail on ingested series
+CREATE TABLE metrics_unpartitioned (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    metric_type VARCHAR(20) NOT NULL,
+    metric_name VARCHAR(255) NOT NULL,
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    value DOUBLE PRECISION NOT NULL CHECK (value >= 0),
+    unit VARCHAR(10) NOT NULL,
+    metadata JSONB,
+    collected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT metrics_metric_type_format_check CHECK (metric_type ~ '^[a-z][a-z0-9_]*$')
+);
+
+INSERT INTO metrics_unpartitioned (id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at)
+SELECT id, metric_type, metric_name, host, labels, value, unit, metadata, collected_at, created_at
+FROM metrics;
+
+DROP TABLE metrics CASCADE;
+ALTER TABLE metrics_unpartitioned RENAME TO metrics;
+ALTER TABLE metrics RENAME CONSTRAINT metrics_unpartitioned_pkey TO metrics_pkey;
+
+CREATE INDEX IF NOT EXISTS idx_metrics_type_collected_at ON metrics(metric_type, collected_at DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_collected_at ON metrics(collected_at DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_name_collected_at ON metrics(metric_name, collected_at DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_metadata_gin ON metrics USING GIN (metadata);
+CREATE INDEX IF NOT EXISTS idx_metrics_created_at ON metrics(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_type_time_covering
+    ON metrics(metric_type, collected_at DESC) INCLUDE (metric_name, value, unit, metadata);
+CREATE INDEX IF NOT EXISTS idx_metrics_type_time_id ON metrics(metric_type, collected_at DESC, id);
+CREATE INDEX IF NOT EXISTS idx_metrics_host_type_collected_at ON metrics(host, metric_type, collected_at DESC);
+CREATE INDEX IF NOT EXISTS idx_metrics_labels_gin ON metrics USING GIN (labels jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_metrics_series_collected_at ON metrics(metric_type, metric_name, host, collected_at DESC);
+-- +goose StatementEnd
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+)
+
+const notificationDeliveryColumns = `id, channel, channel_type, rule_id, rule_name, host, alert_state, severity, message,
+	status, attempts, last_error, created_at, updated_at`
+
+// defaultDeliveryLimit ограничивает выборку журнала доставки, если лимит не задан
+const defaultDeliveryLimit = 100
+
+// PostgresNotificationDeliveryRepository реализует repository.NotificationDeliveryRepository для PostgreSQL
+type PostgresNotificationDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresNotificationDeliveryRepository создает новый repository журнала доставки
+func NewPostgresNotificationDeliveryRepository(db *sql.DB) *PostgresNotificationDeliveryRepository {
+	return &PostgresNotificationDeliveryRepository{
+		db: db,
+	}
+}
+
+// Save создает или обновляет запись о доставке
+func (r *PostgresNotificationDeliveryRepository) Save(ctx context.Context, delivery *entity.NotificationDelivery) error {
+	params := delivery.Params()
+
+	query := `
+		INSERT INTO notification_deliveries (` + notificationDeliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			attempts = EXCLUDED.attempts,
+			last_error = EXCLUDED.last_error,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.ID(),
+		params.Channel,
+		params.ChannelType,
+		params.RuleID,
+		params.RuleName,
+		params.Host,
+		params.AlertState,
+		params.Severity,
+		params.Message,
+		string(delivery.Status()),
+		delivery.Attempts(),
+		delivery.LastError(),
+		delivery.CreatedAt(),
+		delivery.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification delivery: %w", err)
+	}
+
+	return nil
+}
+
+// FindRecent возвращает записи, начиная с самых новых
+func (r *PostgresNotificationDeliveryRepository) FindRecent(
+	ctx context.Context,
+	filter repository.NotificationDeliveryFilter,
+) ([]*entity.NotificationDelivery, error) {
+	where := &whereBuilder{}
+	if filter.Channel != "" {
+		where.add("channel = " + where.arg(filter.Channel))
+	}
+	if filter.Status != "" {
+		where.add("status = " + where.arg(string(filter.Status)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	limitArg := where.arg(limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM notification_deliveries
+		%s
+		ORDER BY created_at DESC
+		LIMIT %s
+	`, notificationDeliveryColumns, where.sql(), limitArg)
+
+	rows, err := r.db.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*entity.NotificationDelivery, 0)
+	for rows.Next() {
+		var (
+			id, status, lastError string
+			params                entity.NotificationDeliveryParams
+			attempts              int
+			createdAt, updatedAt  time.Time
+		)
+
+		err := rows.Scan(
+			&id, &params.Channel, &params.ChannelType, &params.RuleID, &params.RuleName, &params.Host,
+			&params.AlertState, &params.Severity, &params.Message, &status, &attempts, &lastError,
+			&createdAt, &updatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, entity.ReconstructNotificationDelivery(
+			id, params, entity.DeliveryStatus(status), attempts, lastError, createdAt, updatedAt,
+		))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -24,6 +24,7 @@ import (
 	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/promql"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
@@ -174,8 +175,8 @@ func integrationServer(t *testing.T, repo *postgres.PostgresMetricRepository, en
 	log := logger.New("error")
 
 	aggregator := service.NewMetricAggregator()
-	getHistoricalMetricsUC := usecase.NewGetHistoricalMetricsUseCase(repo, aggregator, log)
-	getCurrentMetricsUC := usecase.NewGetCurrentMetricsUseCase(repo, log)
+	getHistoricalMetricsUC := usecase.NewGetHistoricalMetricsUseCase(repo, nil, aggregator, usecase.GetHistoricalMetricsConfig{}, log)
+	getCurrentMetricsUC := usecase.NewGetCurrentMetricsUseCase(repo, "", log)
 
 	hub := wsInfra.NewHub(log)
 	websocketHandler := handler.NewWebSocketHandler(hub, []string{"http://localhost:8080"}, middleware.AuthConfig{
@@ -184,7 +185,18 @@ func integrationServer(t *testing.T, repo *postgres.PostgresMetricRepository, en
 	}, log)
 
 	dashboardHandler := handler.NewDashboardHandler(getCurrentMetricsUC, log)
-	metricsAPIHandler := handler.NewMetricsAPIHandler(getHistoricalMetricsUC, 24*time.Hour, log)
+	metricsAPIHandler := handler.NewMetricsAPIHandler(getHistoricalMetricsUC, usecase.NewListMetricsUseCase(repo, usecase.ListMetricsConfig{}, log), 24*time.Hour, log)
+	queryAPIHandler := handler.NewQueryAPIHandler(
+		usecase.NewQueryMetricsUseCase(promql.NewEngine(repo, promql.EngineConfig{}), log),
+		24*time.Hour,
+		log,
+	)
+	exportAPIHandler := handler.NewExportAPIHandler(usecase.NewExportMetricsUseCase(repo, usecase.ExportMetricsConfig{}, log), 0, log)
+	importAPIHandler := handler.NewImportAPIHandler(
+		usecase.NewImportMetricsUseCase(repo, service.NewMetricValidator(), nil, usecase.ImportMetricsConfig{}, log),
+		0,
+		log,
+	)
 
 	s3Store := buildS3Storage(t, env)
 	metadataRepo := buildDynamoRepo(t, env)
@@ -210,6 +222,16 @@ func integrationServer(t *testing.T, repo *postgres.PostgresMetricRepository, en
 		screenshotAPIHandler,
 		authAPIHandler,
 		releaseAnalyzerAPIHandler,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		queryAPIHandler,
+		exportAPIHandler,
+		importAPIHandler,
+		nil,
+		nil,
 		config.SecurityConfig{
 			AllowedOrigins: []string{"http://localhost:8080"},
 			AuthEnabled:    true,
@@ -298,7 +320,7 @@ func seedMetricsIntegration(t *testing.T, repo *postgres.PostgresMetricRepositor
 		if err != nil {
 			t.Fatalf("metric value: %v", err)
 		}
-		metric := entity.Reconstruct(entry.id, entry.metricType, entry.metricType.String(), value, nil, entry.collected, entry.collected)
+		metric := entity.Reconstruct(entry.id, entry.metricType, entry.metricType.String(), "", valueobject.Labels{}, value, nil, entry.collected, entry.collected)
 		if err := repo.Save(context.Background(), metric); err != nil {
 			t.Fatalf("seed metrics: %v", err)
 		}
@@ -2,12 +2,17 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -15,11 +20,18 @@ import (
 
 	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/promql"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/service"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/otlp"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/remotewrite"
+	notificationChannel "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/notification/channel"
 	wsInfra "github.com/dreschagin/monitoring-dashboard/internal/infrastructure/notification/websocket"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/observability/prometheus"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/instrumented"
 	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/handler"
 	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
 	"github.com/dreschagin/monitoring-dashboard/pkg/config"
@@ -28,6 +40,7 @@ import (
 
 const (
 	testToken        = "test-token"
+	testIngestToken  = "test-ingest-token"
 	minimalPngBase64 = "iVBORw0KGgo=" // PNG signature only
 )
 
@@ -98,13 +111,50 @@ func (r *memoryMetricRepo) FindByTimeRange(_ context.Context, metricType valueob
 	return result, nil
 }
 
+func (r *memoryMetricRepo) FindByHostAndTimeRange(_ context.Context, host string, metricType valueobject.MetricType, timeRange valueobject.TimeRange) ([]*entity.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.Metric, 0)
+	for _, metric := range r.metrics {
+		if metric.Host() != host || metric.Type() != metricType {
+			continue
+		}
+		if timeRange.Contains(metric.CollectedAt()) {
+			result = append(result, metric)
+		}
+	}
+	return result, nil
+}
+
 func (r *memoryMetricRepo) FindLatest(_ context.Context) (map[valueobject.MetricType]*entity.Metric, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	latest := make(map[valueobject.MetricType]*entity.Metric)
 	for _, metric := range r.metrics {
-		current, ok := latest[metric.Type()]
-		if !ok || metric.CollectedAt().After(current.CollectedAt()) {
+		if current, ok := latest[metric.Type()]; !ok || newerMetric(metric, current) {
+			latest[metric.Type()] = metric
+		}
+	}
+	return latest, nil
+}
+
+// newerMetric порядок FindLatest: более позднее время, при равном времени - большее значение
+func newerMetric(metric, current *entity.Metric) bool {
+	if !metric.CollectedAt().Equal(current.CollectedAt()) {
+		return metric.CollectedAt().After(current.CollectedAt())
+	}
+	return metric.Value().Raw() > current.Value().Raw()
+}
+
+func (r *memoryMetricRepo) FindLatestByHost(_ context.Context, host string) (map[valueobject.MetricType]*entity.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	latest := make(map[valueobject.MetricType]*entity.Metric)
+	for _, metric := range r.metrics {
+		if metric.Host() != host {
+			continue
+		}
+		if current, ok := latest[metric.Type()]; !ok || newerMetric(metric, current) {
 			latest[metric.Type()] = metric
 		}
 	}
@@ -140,6 +190,53 @@ func (r *memoryMetricRepo) DeleteOlderThan(_ context.Context, age valueobject.Ti
 	return nil
 }
 
+func (r *memoryMetricRepo) matchRetention(metric *entity.Metric, query repository.RetentionQuery) bool {
+	if !metric.CollectedAt().Before(query.Before) {
+		return false
+	}
+	if len(query.Types) > 0 && !containsMetricType(query.Types, metric.Type()) {
+		return false
+	}
+	return !containsMetricType(query.ExcludeTypes, metric.Type())
+}
+
+func containsMetricType(types []valueobject.MetricType, metricType valueobject.MetricType) bool {
+	for _, candidate := range types {
+		if candidate == metricType {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryMetricRepo) CountExpired(_ context.Context, query repository.RetentionQuery) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var count int64
+	for _, metric := range r.metrics {
+		if r.matchRetention(metric, query) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryMetricRepo) DeleteExpired(_ context.Context, query repository.RetentionQuery) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var deleted int64
+	filtered := r.metrics[:0]
+	for _, metric := range r.metrics {
+		if r.matchRetention(metric, query) && (query.Limit == 0 || deleted < int64(query.Limit)) {
+			deleted++
+			continue
+		}
+		filtered = append(filtered, metric)
+	}
+	r.metrics = filtered
+	return deleted, nil
+}
+
 func (r *memoryMetricRepo) Count(_ context.Context, metricType valueobject.MetricType) (int64, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -152,6 +249,102 @@ func (r *memoryMetricRepo) Count(_ context.Context, metricType valueobject.Metri
 	return count, nil
 }
 
+func (r *memoryMetricRepo) matchQuery(metric *entity.Metric, query repository.MetricQuery) bool {
+	if query.Type != "" && metric.Type() != query.Type {
+		return false
+	}
+	if query.Name != "" && metric.Name() != query.Name {
+		return false
+	}
+	if query.HasTimeRange() && !query.TimeRange.Contains(metric.CollectedAt()) {
+		return false
+	}
+	return metric.MatchesSelector(query.Selector)
+}
+
+func (r *memoryMetricRepo) FindByLabels(_ context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.Metric, 0)
+	for _, metric := range r.metrics {
+		if r.matchQuery(metric, query) {
+			result = append(result, metric)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CollectedAt().After(result[j].CollectedAt())
+	})
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+func (r *memoryMetricRepo) FindPage(_ context.Context, query repository.MetricQuery, after *repository.MetricCursor) ([]*entity.Metric, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.Metric, 0)
+	for _, metric := range r.metrics {
+		if !r.matchQuery(metric, query) {
+			continue
+		}
+		if after != nil && !cursorBefore(*after, metric) {
+			continue
+		}
+		result = append(result, metric)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return cursorBefore(repository.MetricCursor{CollectedAt: result[i].CollectedAt(), ID: result[i].ID()}, result[j])
+	})
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+// cursorBefore проверяет, что курсор предшествует метрике в порядке (collected_at, id)
+func cursorBefore(cursor repository.MetricCursor, metric *entity.Metric) bool {
+	if !cursor.CollectedAt.Equal(metric.CollectedAt()) {
+		return cursor.CollectedAt.Before(metric.CollectedAt())
+	}
+	return cursor.ID < metric.ID()
+}
+
+func (r *memoryMetricRepo) FindLatestSeries(ctx context.Context, query repository.MetricQuery) ([]*entity.Metric, error) {
+	metrics, err := r.FindByLabels(ctx, repository.MetricQuery{
+		Type:      query.Type,
+		Name:      query.Name,
+		Selector:  query.Selector,
+		TimeRange: query.TimeRange,
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	result := make([]*entity.Metric, 0)
+	for _, metric := range metrics {
+		if seen[metric.SeriesKey()] {
+			continue
+		}
+		seen[metric.SeriesKey()] = true
+		result = append(result, metric)
+	}
+	return result, nil
+}
+
+func (r *memoryMetricRepo) GroupByLabel(ctx context.Context, query repository.MetricQuery, label string) (map[string][]*entity.Metric, error) {
+	metrics, err := r.FindByLabels(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string][]*entity.Metric)
+	for _, metric := range metrics {
+		key := metric.LabelValue(label)
+		groups[key] = append(groups[key], metric)
+	}
+	return groups, nil
+}
+
 type memoryScreenshotStorage struct {
 	mu      sync.RWMutex
 	objects map[string]storedScreenshot
@@ -212,6 +405,184 @@ func (s *memoryScreenshotStorage) GetObjectURL(_ context.Context, key string) (s
 	return "https://storage.local/" + key, nil
 }
 
+type memoryAlertRuleRepo struct {
+	mu    sync.RWMutex
+	rules map[string]*entity.AlertRule
+}
+
+func newMemoryAlertRuleRepo() *memoryAlertRuleRepo {
+	return &memoryAlertRuleRepo{
+		rules: make(map[string]*entity.AlertRule),
+	}
+}
+
+func (r *memoryAlertRuleRepo) Save(_ context.Context, rule *entity.AlertRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules[rule.ID()] = rule
+	return nil
+}
+
+func (r *memoryAlertRuleRepo) FindByID(_ context.Context, id string) (*entity.AlertRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rule, ok := r.rules[id]
+	if !ok {
+		return nil, repository.ErrAlertRuleNotFound
+	}
+	return rule, nil
+}
+
+func (r *memoryAlertRuleRepo) FindAll(_ context.Context) ([]*entity.AlertRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rules := make([]*entity.AlertRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Name() < rules[j].Name()
+	})
+	return rules, nil
+}
+
+func (r *memoryAlertRuleRepo) FindEnabled(ctx context.Context) ([]*entity.AlertRule, error) {
+	all, _ := r.FindAll(ctx)
+	enabled := make([]*entity.AlertRule, 0, len(all))
+	for _, rule := range all {
+		if rule.Enabled() {
+			enabled = append(enabled, rule)
+		}
+	}
+	return enabled, nil
+}
+
+func (r *memoryAlertRuleRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rules[id]; !ok {
+		return repository.ErrAlertRuleNotFound
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+type memoryIncidentRepo struct {
+	mu        sync.RWMutex
+	incidents map[string]*entity.Incident
+}
+
+func newMemoryIncidentRepo() *memoryIncidentRepo {
+	return &memoryIncidentRepo{
+		incidents: make(map[string]*entity.Incident),
+	}
+}
+
+func (r *memoryIncidentRepo) Save(_ context.Context, incident *entity.Incident) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.incidents[incident.ID()] = incident
+	return nil
+}
+
+func (r *memoryIncidentRepo) FindByID(_ context.Context, id string) (*entity.Incident, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	incident, ok := r.incidents[id]
+	if !ok {
+		return nil, repository.ErrIncidentNotFound
+	}
+	return incident, nil
+}
+
+func (r *memoryIncidentRepo) FindLatestByFingerprint(ctx context.Context, fingerprint string) (*entity.Incident, error) {
+	all, _ := r.FindAll(ctx, repository.IncidentFilter{})
+	for _, incident := range all {
+		if incident.Fingerprint() == fingerprint {
+			return incident, nil
+		}
+	}
+	return nil, repository.ErrIncidentNotFound
+}
+
+func (r *memoryIncidentRepo) FindAll(_ context.Context, filter repository.IncidentFilter) ([]*entity.Incident, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.Incident, 0, len(r.incidents))
+	for _, incident := range r.incidents {
+		if filter.Host != "" && incident.Host() != filter.Host {
+			continue
+		}
+		if len(filter.Statuses) > 0 {
+			matched := false
+			for _, status := range filter.Statuses {
+				matched = matched || incident.Status() == status
+			}
+			if !matched {
+				continue
+			}
+		}
+		result = append(result, incident)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Lifecycle().OpenedAt.After(result[j].Lifecycle().OpenedAt)
+	})
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+type memoryNotificationDeliveryRepo struct {
+	mu         sync.RWMutex
+	deliveries []*entity.NotificationDelivery
+}
+
+// Save хранит копию: воркеры диспетчера продолжают изменять исходную запись
+func (r *memoryNotificationDeliveryRepo) Save(_ context.Context, delivery *entity.NotificationDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snapshot := entity.ReconstructNotificationDelivery(delivery.ID(), delivery.Params(), delivery.Status(),
+		delivery.Attempts(), delivery.LastError(), delivery.CreatedAt(), delivery.UpdatedAt())
+	for i, existing := range r.deliveries {
+		if existing.ID() == delivery.ID() {
+			r.deliveries[i] = snapshot
+			return nil
+		}
+	}
+	r.deliveries = append(r.deliveries, snapshot)
+	return nil
+}
+
+func (r *memoryNotificationDeliveryRepo) FindRecent(_ context.Context, filter repository.NotificationDeliveryFilter) ([]*entity.NotificationDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*entity.NotificationDelivery, 0, len(r.deliveries))
+	for i := len(r.deliveries) - 1; i >= 0; i-- {
+		delivery := r.deliveries[i]
+		if filter.Channel != "" && delivery.Channel() != filter.Channel {
+			continue
+		}
+		if filter.Status != "" && delivery.Status() != filter.Status {
+			continue
+		}
+		result = append(result, delivery)
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// staticProcessCollector отдает один и тот же список процессов
+type staticProcessCollector struct {
+	samples []port.ProcessSample
+}
+
+func (c *staticProcessCollector) CollectProcesses(context.Context) ([]port.ProcessSample, error) {
+	return c.samples, nil
+}
+
 func newTestServer(t *testing.T, releaseAnalyzerBaseURL string) (*httptest.Server, *memoryScreenshotStorage) {
 	t.Helper()
 
@@ -220,8 +591,8 @@ func newTestServer(t *testing.T, releaseAnalyzerBaseURL string) (*httptest.Serve
 	seedMetrics(t, repo)
 
 	aggregator := service.NewMetricAggregator()
-	getHistoricalMetricsUC := usecase.NewGetHistoricalMetricsUseCase(repo, aggregator, log)
-	getCurrentMetricsUC := usecase.NewGetCurrentMetricsUseCase(repo, log)
+	getHistoricalMetricsUC := usecase.NewGetHistoricalMetricsUseCase(repo, nil, aggregator, usecase.GetHistoricalMetricsConfig{}, log)
+	getCurrentMetricsUC := usecase.NewGetCurrentMetricsUseCase(repo, "dashboard-host", log)
 
 	hub := wsInfra.NewHub(log)
 	websocketHandler := handler.NewWebSocketHandler(hub, []string{"http://localhost:8080"}, middleware.AuthConfig{
@@ -230,7 +601,7 @@ func newTestServer(t *testing.T, releaseAnalyzerBaseURL string) (*httptest.Serve
 	}, log)
 
 	dashboardHandler := handler.NewDashboardHandler(getCurrentMetricsUC, log)
-	metricsAPIHandler := handler.NewMetricsAPIHandler(getHistoricalMetricsUC, time.Hour*24, log)
+	metricsAPIHandler := handler.NewMetricsAPIHandler(getHistoricalMetricsUC, usecase.NewListMetricsUseCase(repo, usecase.ListMetricsConfig{}, log), time.Hour*24, log)
 
 	storage := newMemoryScreenshotStorage()
 	saveScreenshotsUC := usecase.NewSaveDashboardScreenshotsUseCase(storage, nil, usecase.SaveDashboardScreenshotsConfig{}, log)
@@ -248,6 +619,72 @@ func newTestServer(t *testing.T, releaseAnalyzerBaseURL string) (*httptest.Serve
 	authAPIHandler := handler.NewAuthAPIHandler(middleware.AuthConfig{Enabled: true, BearerToken: testToken}, log)
 	releaseAnalyzerAPIHandler := handler.NewReleaseAnalyzerAPIHandler(releaseAnalyzerBaseURL, 2*time.Second, log)
 
+	alertRuleRepo := newMemoryAlertRuleRepo()
+	manageIncidentsUC := usecase.NewManageIncidentsUseCase(newMemoryIncidentRepo(), hub, log)
+	incidentsAPIHandler := handler.NewIncidentsAPIHandler(manageIncidentsUC, log)
+
+	// Канал "ops" доставляет алерты на локальный webhook-приемник
+	webhookReceiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(webhookReceiver.Close)
+	dispatchNotificationsUC := usecase.NewDispatchNotificationsUseCase(
+		[]port.NotificationChannel{notificationChannel.NewWebhookChannel("ops", webhookReceiver.URL, "secret", nil, notificationChannel.Options{})},
+		&memoryNotificationDeliveryRepo{},
+		usecase.DispatchNotificationsConfig{Workers: 1, MaxAttempts: 1},
+		log,
+	)
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	t.Cleanup(stopDispatch)
+	go dispatchNotificationsUC.Run(dispatchCtx)
+	notificationsAPIHandler := handler.NewNotificationsAPIHandler(dispatchNotificationsUC, log)
+
+	enforceRetentionUC := usecase.NewEnforceRetentionUseCase(repo, usecase.EnforceRetentionConfig{
+		Retention: 2 * time.Hour,
+		Overrides: map[valueobject.MetricType]time.Duration{valueobject.Disk: 0},
+		BatchSize: 2,
+	}, log)
+	retentionAPIHandler := handler.NewRetentionAPIHandler(enforceRetentionUC, false, log)
+	queryAPIHandler := handler.NewQueryAPIHandler(
+		usecase.NewQueryMetricsUseCase(promql.NewEngine(repo, promql.EngineConfig{}), log),
+		24*time.Hour,
+		log,
+	)
+
+	exportAPIHandler := handler.NewExportAPIHandler(usecase.NewExportMetricsUseCase(repo, usecase.ExportMetricsConfig{
+		Staleness:      10 * time.Minute,
+		MetadataLabels: []string{"mount", "cores"},
+		PageSize:       2,
+	}, log), 24*time.Hour, log)
+	importAPIHandler := handler.NewImportAPIHandler(usecase.NewImportMetricsUseCase(repo, service.NewMetricValidator(), nil, usecase.ImportMetricsConfig{
+		BatchSize: 2,
+	}, log), 1024*1024, log)
+
+	processesAPIHandler := handler.NewProcessesAPIHandler(usecase.NewCollectProcessesUseCase(&staticProcessCollector{
+		samples: []port.ProcessSample{
+			{PID: 1, Name: "systemd", User: "root", CPUPercent: 0.1, RSSBytes: 12 << 20},
+			{PID: 812, Name: "postgres", User: "postgres", CPUPercent: 35, RSSBytes: 900 << 20},
+			{PID: 4242, Name: "java", Cmdline: "java -jar app.jar", User: "app", CPUPercent: 180, RSSBytes: 2 << 30},
+		},
+	}, hub, usecase.CollectProcessesConfig{TopN: 2, MaxTopN: 3}, "dashboard-host", log), log)
+
+	evaluateAlertRulesUC := usecase.NewEvaluateAlertRulesUseCase(alertRuleRepo, repo, aggregator, hub, nil, manageIncidentsUC, dispatchNotificationsUC, log)
+	alertRulesAPIHandler := handler.NewAlertRulesAPIHandler(usecase.NewManageAlertRulesUseCase(alertRuleRepo, log), log)
+
+	serviceMetricsRegistry := prometheus.NewRegistry()
+	serviceMetrics := prometheus.NewServiceMetrics(serviceMetricsRegistry)
+	serviceMetrics.RegisterWebSocketHub(hub.ClientCount, hub.DroppedSnapshots)
+
+	collectMetricsUC := usecase.NewCollectMetricsUseCase(nil, instrumented.NewMetricRepository(repo, serviceMetrics), hub, service.NewMetricValidator(), nil, nil, evaluateAlertRulesUC, serviceMetrics, "dashboard-host", log)
+	ingestAPIHandler := handler.NewIngestAPIHandler(
+		collectMetricsUC,
+		remotewrite.NewMapper(remotewrite.MapperConfig{}),
+		otlp.NewMapper(otlp.MapperConfig{ResourceAttributes: []string{"service.name"}}),
+		middleware.AuthConfig{Enabled: true, BearerToken: testIngestToken},
+		1024*1024,
+		log,
+	)
+
 	router := NewRouter(
 		dashboardHandler,
 		websocketHandler,
@@ -255,6 +692,16 @@ func newTestServer(t *testing.T, releaseAnalyzerBaseURL string) (*httptest.Serve
 		screenshotAPIHandler,
 		authAPIHandler,
 		releaseAnalyzerAPIHandler,
+		ingestAPIHandler,
+		alertRulesAPIHandler,
+		incidentsAPIHandler,
+		notificationsAPIHandler,
+		retentionAPIHandler,
+		queryAPIHandler,
+		exportAPIHandler,
+		importAPIHandler,
+		processesAPIHandler,
+		serviceMetricsRegistry.Handler(),
 		config.SecurityConfig{
 			AllowedOrigins: []string{"http://localhost:8080"},
 			AuthEnabled:    true,
@@ -290,7 +737,7 @@ func seedMetrics(t *testing.T, repo *memoryMetricRepo) {
 		if err != nil {
 			t.Fatalf("failed to build metric value: %v", err)
 		}
-		metric := entity.Reconstruct(entry.id, entry.metricType, entry.metricType.String(), value, nil, entry.collected, entry.collected)
+		metric := entity.Reconstruct(entry.id, entry.metricType, entry.metricType.String(), "", valueobject.Labels{}, value, nil, entry.collected, entry.collected)
 		if err := repo.Save(context.Background(), metric); err != nil {
 			t.Fatalf("failed to seed metrics: %v", err)
 		}
@@ -312,49 +759,476 @@ func TestE2EHealthEndpoints(t *testing.T) {
 	}
 }
 
-func TestE2EAuthAndMetricsHistory(t *testing.T) {
+func TestE2EServiceMetricsEndpoint(t *testing.T) {
 	server, _ := newTestServer(t, "http://example.invalid")
 	client := server.Client()
 
-	statusResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/auth/status", nil, nil)
-	if statusResp.StatusCode != http.StatusOK {
-		t.Fatalf("unexpected auth status: %d", statusResp.StatusCode)
+	// Загрузка CPU выше 100% отбрасывается конвейером, вторая метрика пишется пачкой
+	payload := `{"host":"edge-1","metrics":[
+		{"type":"cpu","name":"cpu_usage","value":42.5,"unit":"%"},
+		{"type":"cpu","name":"cpu_usage","value":150,"unit":"%"}
+	]}`
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	ingestResp.Body.Close()
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
 	}
 
-	var statusPayload map[string]interface{}
-	if err := json.NewDecoder(statusResp.Body).Decode(&statusPayload); err != nil {
-		t.Fatalf("decode status response: %v", err)
+	// /metrics доступен без авторизации, как и health-пробы
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for /metrics, got %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type: %s", contentType)
 	}
-	statusResp.Body.Close()
 
-	if statusPayload["auth_enabled"] != true {
-		t.Fatalf("expected auth_enabled true, got %v", statusPayload["auth_enabled"])
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("read body: %v", err)
 	}
-	if statusPayload["authenticated"] != false {
-		t.Fatalf("expected authenticated false, got %v", statusPayload["authenticated"])
+	exposition := body.String()
+	for _, line := range []string{
+		"# TYPE monitoring_api_save_batch_duration_seconds histogram",
+		"monitoring_api_save_batch_duration_seconds_count 1",
+		`monitoring_api_metrics_dropped_total{reason="unreasonable"} 1`,
+		"monitoring_api_websocket_clients 0",
+		"monitoring_api_websocket_dropped_snapshots_total 0",
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Fatalf("exposition is missing %q:\n%s", line, exposition)
+		}
 	}
+}
 
-	loginBody := bytes.NewBufferString(`{"token":"bad-token"}`)
-	loginResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/auth/login", loginBody, map[string]string{
-		"Content-Type": "application/json",
+func TestE2EPrometheusExport(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	collectedAt := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	payload := fmt.Sprintf(`{"host":"edge-1","metrics":[
+		{"type":"disk","name":"disk_usage","value":70,"unit":"%%","labels":{"mount":"/data"},"collected_at":%q},
+		{"type":"cpu","name":"cpu_usage","value":42.5,"unit":"%%","metadata":{"cores":8,"model":"x"},"collected_at":%q}
+	]}`, collectedAt.Format(time.RFC3339Nano), collectedAt.Format(time.RFC3339Nano))
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
 	})
-	if loginResp.StatusCode != http.StatusUnauthorized {
-		t.Fatalf("expected 401 for invalid login, got %d", loginResp.StatusCode)
+	ingestResp.Body.Close()
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
 	}
-	loginResp.Body.Close()
 
-	loginBody = bytes.NewBufferString(`{"token":"` + testToken + `"}`)
-	loginResp = doRequest(t, client, http.MethodPost, server.URL+"/api/v1/auth/login", loginBody, map[string]string{
-		"Content-Type": "application/json",
+	unauthorizedResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/export/prometheus", nil, nil)
+	unauthorizedResp.Body.Close()
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+
+	resp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/export/prometheus?host=edge-1", nil, map[string]string{
+		"Authorization": "Bearer " + testToken,
 	})
-	if loginResp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200 for valid login, got %d", loginResp.StatusCode)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for export, got %d", resp.StatusCode)
+	}
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("read body: %v", err)
 	}
-	loginResp.Body.Close()
 
-	cookies := loginResp.Cookies()
-	if len(cookies) == 0 {
-		t.Fatal("expected auth cookie")
+	ts := fmt.Sprint(collectedAt.UnixMilli())
+	want := "# HELP cpu_usage CPU Usage (%)\n" +
+		"# TYPE cpu_usage gauge\n" +
+		`cpu_usage{host="edge-1",cores="8"} 42.5 ` + ts + "\n" +
+		"# HELP disk_usage Disk Usage (%)\n" +
+		"# TYPE disk_usage gauge\n" +
+		`disk_usage{host="edge-1",mount="/data"} 70 ` + ts + "\n"
+	if got := body.String(); got != want {
+		t.Fatalf("unexpected export:\n%s\nwant:\n%s", got, want)
+	}
+
+	openMetricsResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/export/prometheus?selector={mount=\"/data\"}", nil, map[string]string{
+		"Authorization": "Bearer " + testToken,
+		"Accept":        "application/openmetrics-text;version=1.0.0,text/plain;q=0.5",
+	})
+	defer openMetricsResp.Body.Close()
+	if contentType := openMetricsResp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/openmetrics-text") {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+	body.Reset()
+	if _, err := body.ReadFrom(openMetricsResp.Body); err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if got := body.String(); !strings.Contains(got, `disk_usage{host="edge-1",mount="/data"} 70 `) || strings.Contains(got, "cpu_usage") || !strings.HasSuffix(got, "# EOF\n") {
+		t.Fatalf("unexpected OpenMetrics export:\n%s", got)
+	}
+}
+
+func TestE2EMetricsHistoryExport(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	// Пять точек - больше нескольких страниц при PageSize 2
+	windowStart := time.Now().UTC().AddDate(0, 0, -2).Truncate(time.Hour)
+	var points []string
+	for i := 0; i < 5; i++ {
+		points = append(points, fmt.Sprintf(`{"type":"cpu","name":"cpu_usage","value":%d,"unit":"%%","collected_at":%q}`,
+			10*(i+1), windowStart.Add(time.Duration(i)*time.Minute).Format(time.RFC3339)))
+	}
+	points = append(points, fmt.Sprintf(`{"type":"disk","name":"disk_usage","value":70,"unit":"%%","labels":{"mount":"/data"},"collected_at":%q}`,
+		windowStart.Add(30*time.Second).Format(time.RFC3339)))
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics",
+		bytes.NewBufferString(`{"host":"export-1","metrics":[`+strings.Join(points, ",")+`]}`),
+		map[string]string{"Authorization": "Bearer " + testIngestToken})
+	ingestResp.Body.Close()
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+
+	window := fmt.Sprintf("start=%d&end=%d&host=export-1", windowStart.Unix(), windowStart.Add(time.Hour).Unix())
+
+	unauthorizedResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/export?"+window, nil, nil)
+	unauthorizedResp.Body.Close()
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+
+	// CSV через Compression middleware
+	csvResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/export?format=csv&"+window, nil, map[string]string{
+		"Authorization":   "Bearer " + testToken,
+		"Accept-Encoding": "gzip",
+	})
+	defer csvResp.Body.Close()
+	if csvResp.StatusCode != http.StatusOK || csvResp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzipped 200 for csv export, got %d %q", csvResp.StatusCode, csvResp.Header.Get("Content-Encoding"))
+	}
+	if disposition := csvResp.Header.Get("Content-Disposition"); !strings.HasPrefix(disposition, `attachment; filename="metrics-`) || !strings.HasSuffix(disposition, `.csv"`) {
+		t.Fatalf("unexpected Content-Disposition: %s", disposition)
+	}
+	gz, err := gzip.NewReader(csvResp.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	records, err := csv.NewReader(gz).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 7 || records[0][0] != "collected_at" {
+		t.Fatalf("expected header and 6 rows, got %v", records)
+	}
+	if records[1][2] != "cpu_usage" || records[2][2] != "disk_usage" || records[2][6] != `{"mount":"/data"}` || records[6][4] != "50" {
+		t.Fatalf("rows are not ordered by collected_at: %v", records)
+	}
+
+	// NDJSON с фильтром по типу
+	ndjsonResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/export?format=ndjson&type=disk&"+window, nil, authHeaders)
+	defer ndjsonResp.Body.Close()
+	if ndjsonResp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("unexpected content type: %s", ndjsonResp.Header.Get("Content-Type"))
+	}
+	var row struct {
+		Name   string            `json:"name"`
+		Host   string            `json:"host"`
+		Labels map[string]string `json:"labels"`
+	}
+	decoder := json.NewDecoder(ndjsonResp.Body)
+	if err := decoder.Decode(&row); err != nil {
+		t.Fatalf("decode ndjson row: %v", err)
+	}
+	if row.Name != "disk_usage" || row.Host != "export-1" || row.Labels["mount"] != "/data" || decoder.More() {
+		t.Fatalf("unexpected ndjson export: %+v", row)
+	}
+
+	parquetResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/export?format=parquet&"+window, nil, authHeaders)
+	defer parquetResp.Body.Close()
+	var parquetBody bytes.Buffer
+	if _, err := parquetBody.ReadFrom(parquetResp.Body); err != nil {
+		t.Fatalf("read parquet: %v", err)
+	}
+	if data := parquetBody.Bytes(); len(data) < 12 || string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("unexpected parquet export of %d bytes", len(data))
+	}
+
+	for _, bad := range []string{
+		"format=xlsx&" + window,
+		"end=" + strconv.FormatInt(windowStart.Unix(), 10),
+		fmt.Sprintf("start=%d&end=%d", windowStart.Unix(), windowStart.Add(48*time.Hour).Unix()),
+		"type=unknown&" + window,
+	} {
+		resp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/export?"+bad, nil, authHeaders)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", bad, resp.StatusCode)
+		}
+	}
+}
+
+func TestE2EMetricsHistoryImport(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	windowStart := time.Now().UTC().AddDate(0, 0, -5).Truncate(time.Hour)
+	at := func(offset time.Duration) string { return windowStart.Add(offset).Format(time.RFC3339) }
+	csvFile := "collected_at,type,name,host,value,unit,labels\n" +
+		at(0) + ",cpu,cpu_usage,migrated-1,12.5,%,{}\n" +
+		at(time.Minute) + `,disk,disk_usage,migrated-1,70,%,"{""mount"":""/data""}"` + "\n" +
+		at(time.Minute) + `,disk,disk_usage,migrated-1,70,%,"{""mount"":""/data""}"` + "\n" +
+		at(2*time.Minute) + ",cpu,cpu_usage,migrated-1,-5,%,{}\n" +
+		at(3*time.Minute) + ",cpu,cpu_usage,migrated-1,30,%,{}\n"
+
+	postImport := func(query, contentType string, body *bytes.Buffer, extra map[string]string) (*http.Response, dto.ImportMetricsResultDTO) {
+		t.Helper()
+		headers := map[string]string{"Authorization": "Bearer " + testToken, "Content-Type": contentType}
+		for name, value := range extra {
+			headers[name] = value
+		}
+		resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/metrics/import"+query, body, headers)
+		defer resp.Body.Close()
+		var result dto.ImportMetricsResultDTO
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("decode import report: %v", err)
+			}
+		}
+		return resp, result
+	}
+
+	unauthorizedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/metrics/import", bytes.NewBufferString(csvFile), nil)
+	unauthorizedResp.Body.Close()
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+
+	resp, result := postImport("?dry_run=true", "text/csv", bytes.NewBufferString(csvFile), nil)
+	if resp.StatusCode != http.StatusOK || !result.DryRun || result.Imported != 3 {
+		t.Fatalf("unexpected dry run: %d %+v", resp.StatusCode, result)
+	}
+
+	resp, result = postImport("", "text/csv", bytes.NewBufferString(csvFile), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for import, got %d", resp.StatusCode)
+	}
+	if result.Rows != 5 || result.Imported != 3 || result.Duplicates != 1 || result.Rejected != 1 {
+		t.Fatalf("unexpected import report: %+v", result)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 5 {
+		t.Fatalf("expected the negative value on line 5 to be reported: %+v", result.Errors)
+	}
+
+	// Повторная загрузка того же файла (NDJSON, gzip) ничего не добавляет
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	for _, line := range []string{
+		fmt.Sprintf(`{"collected_at":%q,"type":"cpu","name":"cpu_usage","host":"migrated-1","value":12.5,"unit":"%%"}`, at(0)),
+		fmt.Sprintf(`{"collected_at":%d,"type":"cpu","name":"cpu_usage","host":"migrated-1","value":40,"unit":"%%"}`, windowStart.Add(4*time.Minute).Unix()),
+	} {
+		_, _ = gz.Write([]byte(line + "\n"))
+	}
+	_ = gz.Close()
+	resp, result = postImport("?format=ndjson", "application/octet-stream", &compressed, map[string]string{"Content-Encoding": "gzip"})
+	if resp.StatusCode != http.StatusOK || result.Imported != 1 || result.Duplicates != 1 {
+		t.Fatalf("unexpected NDJSON re-import: %d %+v", resp.StatusCode, result)
+	}
+
+	// Загруженная история видна в выгрузке
+	exportResp := doRequest(t, client, http.MethodGet, server.URL+fmt.Sprintf("/api/v1/metrics/export?format=csv&host=migrated-1&start=%d&end=%d",
+		windowStart.Unix(), windowStart.Add(time.Hour).Unix()), nil, authHeaders)
+	records, err := csv.NewReader(exportResp.Body).ReadAll()
+	exportResp.Body.Close()
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	if len(records) != 5 || records[1][4] != "12.5" || records[2][6] != `{"mount":"/data"}` || records[4][4] != "40" {
+		t.Fatalf("unexpected exported history: %v", records)
+	}
+
+	resp, _ = postImport("", "text/csv", bytes.NewBufferString("collected_at,type,valeu\n"), nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown column, got %d", resp.StatusCode)
+	}
+	resp, result = postImport("", "text/csv", bytes.NewBufferString("collected_at,type,value\n"+at(5*time.Minute)+",cpu,1\n"+at(6*time.Minute)+",cpu,\"2\n"), nil)
+	if resp.StatusCode != http.StatusBadRequest || result.Imported != 1 || result.Error == "" {
+		t.Fatalf("expected 400 with a partial report for broken quoting, got %d %+v", resp.StatusCode, result)
+	}
+}
+
+func TestE2EMetricsList(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	windowStart := time.Now().UTC().Add(-3 * time.Hour).Truncate(time.Minute)
+	var points []string
+	for i := 0; i < 5; i++ {
+		points = append(points, fmt.Sprintf(`{"type":"disk","name":"disk_usage","value":%d,"unit":"%%","labels":{"mount":"/data"},"collected_at":%q}`,
+			50+i, windowStart.Add(time.Duration(i)*time.Minute).Format(time.RFC3339)))
+	}
+	points = append(points, fmt.Sprintf(`{"type":"disk","name":"disk_usage","value":90,"unit":"%%","labels":{"mount":"/"},"collected_at":%q}`,
+		windowStart.Format(time.RFC3339)))
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics",
+		bytes.NewBufferString(`{"host":"list-1","metrics":[`+strings.Join(points, ",")+`]}`),
+		map[string]string{"Authorization": "Bearer " + testIngestToken})
+	ingestResp.Body.Close()
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+
+	listPage := func(query string) (*http.Response, dto.MetricPageDTO) {
+		t.Helper()
+		resp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics?"+query, nil, authHeaders)
+		defer resp.Body.Close()
+		var page dto.MetricPageDTO
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+				t.Fatalf("decode metrics page: %v", err)
+			}
+		}
+		return resp, page
+	}
+
+	unauthorizedResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics", nil, nil)
+	unauthorizedResp.Body.Close()
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+
+	filters := fmt.Sprintf("type=disk&host=list-1&label=mount=/data&start=%d", windowStart.Unix())
+	var values []float64
+	cursor := ""
+	for pages := 1; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination does not terminate")
+		}
+		resp, page := listPage(filters + "&limit=2&cursor=" + url.QueryEscape(cursor))
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for page %d, got %d", pages, resp.StatusCode)
+		}
+		for _, metric := range page.Metrics {
+			if metric.Labels["mount"] != "/data" || metric.Host != "list-1" {
+				t.Fatalf("filters ignored: %+v", metric)
+			}
+			values = append(values, metric.Value)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if fmt.Sprint(values) != "[50 51 52 53 54]" {
+		t.Fatalf("values = %v, want every /data sample once in time order", values)
+	}
+
+	// Курсор привязан к фильтрам первой страницы
+	_, first := listPage(filters + "&limit=2")
+	resp, _ := listPage("type=cpu&limit=2&cursor=" + url.QueryEscape(first.NextCursor))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a cursor with other filters, got %d", resp.StatusCode)
+	}
+
+	for _, bad := range []string{"cursor=bm90LWpzb24", "limit=0", "type=bogus", "end=1700000000", "label=mount", "selector=" + url.QueryEscape(`mount=~"("`)} {
+		resp, _ := listPage(bad)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", bad, resp.StatusCode)
+		}
+	}
+}
+
+func TestE2ETopProcesses(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	unauthorizedResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/processes", nil, nil)
+	unauthorizedResp.Body.Close()
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+
+	getTop := func(query string) dto.TopProcessesDTO {
+		t.Helper()
+		resp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/processes"+query, nil, authHeaders)
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for %q, got %d", query, resp.StatusCode)
+		}
+		var top dto.TopProcessesDTO
+		if err := json.NewDecoder(resp.Body).Decode(&top); err != nil {
+			t.Fatalf("decode processes: %v", err)
+		}
+		return top
+	}
+
+	top := getTop("")
+	if top.Host != "dashboard-host" || top.TotalProcesses != 3 || len(top.ByCPU) != 2 || len(top.ByMemory) != 2 {
+		t.Fatalf("unexpected default top: %+v", top)
+	}
+	if top.ByCPU[0].Name != "java" || top.ByCPU[1].Name != "postgres" || top.ByCPU[0].Cmdline != "java -jar app.jar" {
+		t.Fatalf("unexpected order by CPU: %+v", top.ByCPU)
+	}
+
+	// limit выше MaxTopN ограничивается
+	if top := getTop("?limit=50"); len(top.ByMemory) != 3 || top.ByMemory[2].Name != "systemd" {
+		t.Fatalf("unexpected top by memory: %+v", top.ByMemory)
+	}
+
+	badResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/processes?limit=-1", nil, authHeaders)
+	badResp.Body.Close()
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for negative limit, got %d", badResp.StatusCode)
+	}
+}
+
+func TestE2EAuthAndMetricsHistory(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	statusResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/auth/status", nil, nil)
+	if statusResp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected auth status: %d", statusResp.StatusCode)
+	}
+
+	var statusPayload map[string]interface{}
+	if err := json.NewDecoder(statusResp.Body).Decode(&statusPayload); err != nil {
+		t.Fatalf("decode status response: %v", err)
+	}
+	statusResp.Body.Close()
+
+	if statusPayload["auth_enabled"] != true {
+		t.Fatalf("expected auth_enabled true, got %v", statusPayload["auth_enabled"])
+	}
+	if statusPayload["authenticated"] != false {
+		t.Fatalf("expected authenticated false, got %v", statusPayload["authenticated"])
+	}
+
+	loginBody := bytes.NewBufferString(`{"token":"bad-token"}`)
+	loginResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/auth/login", loginBody, map[string]string{
+		"Content-Type": "application/json",
+	})
+	if loginResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for invalid login, got %d", loginResp.StatusCode)
+	}
+	loginResp.Body.Close()
+
+	loginBody = bytes.NewBufferString(`{"token":"` + testToken + `"}`)
+	loginResp = doRequest(t, client, http.MethodPost, server.URL+"/api/v1/auth/login", loginBody, map[string]string{
+		"Content-Type": "application/json",
+	})
+	if loginResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for valid login, got %d", loginResp.StatusCode)
+	}
+	loginResp.Body.Close()
+
+	cookies := loginResp.Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("expected auth cookie")
 	}
 
 	authorizedStatusReq, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/auth/status", nil)
@@ -409,6 +1283,965 @@ func TestE2EAuthAndMetricsHistory(t *testing.T) {
 	legacyResp.Body.Close()
 }
 
+func TestE2EIngestMetrics(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	// Метрика неизвестного типа и метрика со слишком длинным именем отклоняются по одной, не роняя пакет
+	payload := `{"host":"edge-1","metrics":[
+		{"type":"cpu","name":"cpu_usage","value":42.5,"unit":"%"},
+		{"type":"memory","name":"memory_usage","value":61,"unit":"%"},
+		{"type":"gpu","name":"gpu_usage","value":10,"unit":"%"},
+		{"type":"cpu","name":"` + strings.Repeat("x", 256) + `","value":10,"unit":"%"}
+	]}`
+
+	unauthorizedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testToken,
+	})
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for dashboard token on ingest, got %d", unauthorizedResp.StatusCode)
+	}
+	unauthorizedResp.Body.Close()
+
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+		"Content-Type":  "application/json",
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+
+	var result dto.IngestMetricsResultDTO
+	if err := json.NewDecoder(ingestResp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode ingest response: %v", err)
+	}
+	ingestResp.Body.Close()
+
+	if result.Host != "edge-1" || result.Received != 4 || result.Accepted != 2 || result.Rejected != 2 {
+		t.Fatalf("unexpected ingest result: %+v", result)
+	}
+
+	badHostResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(`{"host":"bad host","metrics":[{"type":"cpu","name":"cpu_usage","value":1,"unit":"%"}]}`), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if badHostResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid host, got %d", badHostResp.StatusCode)
+	}
+	badHostResp.Body.Close()
+
+	oversized := `{"host":"edge-1","metrics":[{"type":"cpu","name":"` + strings.Repeat("x", 2*1024*1024) + `","value":1,"unit":"%"}]}`
+	oversizedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(oversized), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if oversizedResp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for oversized payload, got %d", oversizedResp.StatusCode)
+	}
+	oversizedResp.Body.Close()
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=cpu&duration=1h&host=edge-1", nil, map[string]string{
+		"Authorization": "Bearer " + testToken,
+	})
+	if historyResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for host history, got %d", historyResp.StatusCode)
+	}
+	defer historyResp.Body.Close()
+
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	if len(history.Metrics) != 1 {
+		t.Fatalf("expected 1 metric for edge-1, got %d", len(history.Metrics))
+	}
+	if history.Metrics[0].Host != "edge-1" || history.Metrics[0].Value != 42.5 {
+		t.Fatalf("unexpected host metric: %+v", history.Metrics[0])
+	}
+}
+
+func TestE2EPrometheusRemoteWrite(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	at := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	body := remotewrite.EncodeWriteRequest(&remotewrite.WriteRequest{Timeseries: []remotewrite.TimeSeries{
+		{
+			Labels:  []remotewrite.Label{{Name: "__name__", Value: "node_memory_MemAvailable_bytes"}, {Name: "instance", Value: "prom-1:9100"}, {Name: "job", Value: "node"}},
+			Samples: []remotewrite.Sample{{Value: 2048, Timestamp: at.UnixMilli()}},
+		},
+		{
+			// Тип не определяется и типа по умолчанию нет: серия отклоняется, запрос все равно принят
+			Labels:  []remotewrite.Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "instance", Value: "prom-1:8080"}},
+			Samples: []remotewrite.Sample{{Value: 7, Timestamp: at.UnixMilli()}},
+		},
+	}})
+	headers := map[string]string{
+		"Authorization":                     "Bearer " + testIngestToken,
+		"Content-Type":                      "application/x-protobuf",
+		"Content-Encoding":                  "snappy",
+		"X-Prometheus-Remote-Write-Version": "0.1.0",
+	}
+
+	resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/remote_write", bytes.NewBuffer(body), headers)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for remote_write, got %d", resp.StatusCode)
+	}
+
+	badResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/remote_write", bytes.NewBufferString("not snappy"), headers)
+	badResp.Body.Close()
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid payload, got %d", badResp.StatusCode)
+	}
+
+	unauthorizedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/remote_write", bytes.NewBuffer(body), map[string]string{
+		"Authorization": "Bearer " + testToken,
+	})
+	unauthorizedResp.Body.Close()
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for dashboard token, got %d", unauthorizedResp.StatusCode)
+	}
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=node_exporter&duration=1h&host=prom-1", nil, map[string]string{
+		"Authorization": "Bearer " + testToken,
+	})
+	defer historyResp.Body.Close()
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	if len(history.Metrics) != 1 {
+		t.Fatalf("expected 1 remote_write sample for prom-1, got %d", len(history.Metrics))
+	}
+	metric := history.Metrics[0]
+	if metric.Name != "node_memory_MemAvailable_bytes" || metric.Value != 2048 || metric.Unit != "bytes" || metric.Labels["job"] != "node" || !metric.CollectedAt.Equal(at) {
+		t.Fatalf("unexpected remote_write metric: %+v", metric)
+	}
+}
+
+func TestE2ERemoteWriteAcceptsLargeRequestInChunks(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	// Больше maxIngestBatchSize сэмплов: 413 Prometheus не повторил бы, и данные были бы потеряны
+	start := time.Now().UTC().Add(-50 * time.Minute).Truncate(time.Millisecond)
+	samples := make([]remotewrite.Sample, 0, 6000)
+	for i := 0; i < cap(samples); i++ {
+		samples = append(samples, remotewrite.Sample{Value: float64(i), Timestamp: start.Add(time.Duration(i) * 100 * time.Millisecond).UnixMilli()})
+	}
+	body := remotewrite.EncodeWriteRequest(&remotewrite.WriteRequest{Timeseries: []remotewrite.TimeSeries{{
+		Labels:  []remotewrite.Label{{Name: "__name__", Value: "node_memory_MemAvailable_bytes"}, {Name: "instance", Value: "bulk-1:9100"}},
+		Samples: samples,
+	}}})
+
+	resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/remote_write", bytes.NewBuffer(body), map[string]string{
+		"Authorization":    "Bearer " + testIngestToken,
+		"Content-Type":     "application/x-protobuf",
+		"Content-Encoding": "snappy",
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for a large remote_write request, got %d", resp.StatusCode)
+	}
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=node_exporter&duration=1h&host=bulk-1", nil, map[string]string{
+		"Authorization": "Bearer " + testToken,
+	})
+	defer historyResp.Body.Close()
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	if len(history.Metrics) != len(samples) {
+		t.Fatalf("expected all %d samples to be stored, got %d", len(samples), len(history.Metrics))
+	}
+}
+
+func TestE2ERemoteWriteNodeExporterDoesNotFireAlerts(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{
+		"Authorization": "Bearer " + testToken,
+		"Content-Type":  "application/json",
+	}
+
+	// Правила миграции 007 и правила на весь тип без имени и единицы
+	for _, rule := range []string{
+		`{"name":"CPU usage critical","metric_type":"cpu","metric_name":"cpu_usage","unit":"%","aggregate":"avg","window":"5m","comparator":">","threshold":90,"severity":"critical"}`,
+		`{"name":"Memory usage critical","metric_type":"memory","metric_name":"memory_usage","unit":"%","aggregate":"avg","window":"5m","comparator":">","threshold":90,"severity":"critical"}`,
+		`{"name":"Disk usage critical","metric_type":"disk","metric_name":"disk_usage","unit":"%","aggregate":"last","comparator":">","threshold":90,"severity":"critical"}`,
+		`{"name":"Any CPU","metric_type":"cpu","aggregate":"last","comparator":">","threshold":90,"severity":"critical"}`,
+		`{"name":"Any memory","metric_type":"memory","aggregate":"last","comparator":">","threshold":90,"severity":"critical"}`,
+	} {
+		resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(rule), authHeaders)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201 for rule create, got %d", resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+
+	// Счетчики и байты node_exporter далеко за процентными порогами
+	at := time.Now().UTC().Add(-time.Minute).UnixMilli()
+	nodeSeries := func(name string, value float64, extra ...remotewrite.Label) remotewrite.TimeSeries {
+		labels := append([]remotewrite.Label{{Name: "__name__", Value: name}, {Name: "instance", Value: "node-1:9100"}, {Name: "job", Value: "node"}}, extra...)
+		return remotewrite.TimeSeries{Labels: labels, Samples: []remotewrite.Sample{{Value: value, Timestamp: at}}}
+	}
+	body := remotewrite.EncodeWriteRequest(&remotewrite.WriteRequest{Timeseries: []remotewrite.TimeSeries{
+		nodeSeries("node_cpu_seconds_total", 183412.5, remotewrite.Label{Name: "cpu", Value: "0"}, remotewrite.Label{Name: "mode", Value: "user"}),
+		nodeSeries("node_memory_MemAvailable_bytes", 8<<30),
+		nodeSeries("node_filesystem_avail_bytes", 120<<30, remotewrite.Label{Name: "mountpoint", Value: "/"}),
+		nodeSeries("node_disk_read_bytes_total", 5<<40, remotewrite.Label{Name: "device", Value: "sda"}),
+		nodeSeries("node_network_receive_bytes_total", 3<<40, remotewrite.Label{Name: "device", Value: "eth0"}),
+	}})
+	resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/remote_write", bytes.NewBuffer(body), map[string]string{
+		"Authorization":    "Bearer " + testIngestToken,
+		"Content-Type":     "application/x-protobuf",
+		"Content-Encoding": "snappy",
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for remote_write, got %d", resp.StatusCode)
+	}
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=node_exporter&duration=1h&host=node-1", nil, authHeaders)
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	historyResp.Body.Close()
+	if len(history.Metrics) != 5 {
+		t.Fatalf("expected 5 node_exporter samples for node-1, got %d", len(history.Metrics))
+	}
+
+	listResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/incidents?status=active", nil, authHeaders)
+	var incidents []dto.IncidentDTO
+	if err := json.NewDecoder(listResp.Body).Decode(&incidents); err != nil {
+		t.Fatalf("decode incidents response: %v", err)
+	}
+	listResp.Body.Close()
+	if len(incidents) != 0 {
+		t.Fatalf("node_exporter series must not fire percentage rules: %+v", incidents)
+	}
+}
+
+func TestE2EOpenTelemetryOTLP(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	at := time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	payload := `{"resourceMetrics":[{
+		"resource":{"attributes":[
+			{"key":"host.name","value":{"stringValue":"otel-1"}},
+			{"key":"service.name","value":{"stringValue":"checkout"}}
+		]},
+		"scopeMetrics":[{"metrics":[
+			{"name":"system.memory.utilization","unit":"1","gauge":{"dataPoints":[
+				{"attributes":[{"key":"state","value":{"stringValue":"used"}}],"timeUnixNano":"` + strconv.FormatInt(at.UnixNano(), 10) + `","asDouble":0.42}
+			]}},
+			{"name":"http.server.active_requests","sum":{"aggregationTemporality":2,"dataPoints":[{"asInt":"3"}]}}
+		]}]
+	}]}`
+
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, _ = gzipWriter.Write([]byte(payload))
+	_ = gzipWriter.Close()
+
+	headers := map[string]string{
+		"Authorization":    "Bearer " + testIngestToken,
+		"Content-Type":     "application/json",
+		"Content-Encoding": "gzip",
+	}
+	resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/otlp/v1/metrics", &compressed, headers)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for OTLP export, got %d", resp.StatusCode)
+	}
+	// Сумма без типа отклоняется и попадает в partial_success
+	var exportResp struct {
+		PartialSuccess struct {
+			RejectedDataPoints string `json:"rejectedDataPoints"`
+		} `json:"partialSuccess"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&exportResp); err != nil {
+		t.Fatalf("decode OTLP response: %v", err)
+	}
+	if exportResp.PartialSuccess.RejectedDataPoints != "1" {
+		t.Fatalf("expected 1 rejected data point, got %+v", exportResp)
+	}
+
+	unsupportedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/otlp/v1/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+		"Content-Type":  "text/plain",
+	})
+	unsupportedResp.Body.Close()
+	if unsupportedResp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415 for unsupported content type, got %d", unsupportedResp.StatusCode)
+	}
+
+	badResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/otlp/v1/metrics", bytes.NewBufferString("{"), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+		"Content-Type":  "application/json",
+	})
+	badResp.Body.Close()
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid payload, got %d", badResp.StatusCode)
+	}
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=memory&duration=1h&host=otel-1", nil, map[string]string{
+		"Authorization": "Bearer " + testToken,
+	})
+	defer historyResp.Body.Close()
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	if len(history.Metrics) != 1 {
+		t.Fatalf("expected 1 OTLP metric for otel-1, got %d", len(history.Metrics))
+	}
+	metric := history.Metrics[0]
+	if metric.Name != "system_memory_utilization" || metric.Value != 42 || metric.Unit != "%" ||
+		metric.Labels["service_name"] != "checkout" || metric.Labels["state"] != "used" || !metric.CollectedAt.Equal(at) {
+		t.Fatalf("unexpected OTLP metric: %+v", metric)
+	}
+}
+
+func TestE2EMetricLabelsAndSeries(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+
+	for _, host := range []string{"db-1", "db-2"} {
+		payload := `{"host":"` + host + `","metrics":[
+			{"type":"disk","name":"disk_usage","value":30,"unit":"%","labels":{"mount":"/","environment":"prod"}},
+			{"type":"disk","name":"disk_usage","value":80,"unit":"%","labels":{"mount":"/data","environment":"prod"}}
+		]}`
+		ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+			"Authorization": "Bearer " + testIngestToken,
+		})
+		if ingestResp.StatusCode != http.StatusAccepted {
+			t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+		}
+		ingestResp.Body.Close()
+	}
+
+	reservedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(`{"host":"db-1","metrics":[{"type":"disk","name":"disk_usage","value":1,"unit":"%","labels":{"host":"spoofed"}}]}`), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	var reservedResult dto.IngestMetricsResultDTO
+	if err := json.NewDecoder(reservedResp.Body).Decode(&reservedResult); err != nil {
+		t.Fatalf("decode ingest response: %v", err)
+	}
+	reservedResp.Body.Close()
+	if reservedResult.Accepted != 0 || reservedResult.Rejected != 1 {
+		t.Fatalf("expected reserved label to be rejected, got %+v", reservedResult)
+	}
+
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+`/api/v1/metrics/history?type=disk&duration=1h&host=db-2&selector=`+url.QueryEscape(`{mount=~"/d.*"}`), nil, authHeaders)
+	if historyResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for selector history, got %d", historyResp.StatusCode)
+	}
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	historyResp.Body.Close()
+	if len(history.Metrics) != 1 {
+		t.Fatalf("expected 1 metric for selector, got %d", len(history.Metrics))
+	}
+	if history.Metrics[0].Host != "db-2" || history.Metrics[0].Labels["mount"] != "/data" {
+		t.Fatalf("unexpected selector metric: %+v", history.Metrics[0])
+	}
+
+	badSelectorResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=disk&duration=1h&selector="+url.QueryEscape(`mount=~"("`), nil, authHeaders)
+	if badSelectorResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid selector, got %d", badSelectorResp.StatusCode)
+	}
+	badSelectorResp.Body.Close()
+
+	seriesResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/series?type=disk&duration=1h&group_by=mount&selector="+url.QueryEscape(`environment="prod"`), nil, authHeaders)
+	if seriesResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for series, got %d", seriesResp.StatusCode)
+	}
+	defer seriesResp.Body.Close()
+
+	var grouped dto.MetricGroupedHistoryDTO
+	if err := json.NewDecoder(seriesResp.Body).Decode(&grouped); err != nil {
+		t.Fatalf("decode series response: %v", err)
+	}
+	if grouped.GroupBy != "mount" || len(grouped.Series) != 2 {
+		t.Fatalf("unexpected grouped series: %+v", grouped)
+	}
+	if grouped.Series[0].Value != "/" || grouped.Series[1].Value != "/data" {
+		t.Fatalf("unexpected series order: %s, %s", grouped.Series[0].Value, grouped.Series[1].Value)
+	}
+	if grouped.Series[1].History.Average != 80 || len(grouped.Series[1].History.Metrics) != 2 {
+		t.Fatalf("unexpected /data history: %+v", grouped.Series[1].History)
+	}
+}
+
+func TestE2EHistoryAbsoluteRangeAndStep(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	// Окно инцидента три дня назад: точки 10:00:10, 10:00:40 и 10:01:20
+	windowStart := time.Now().UTC().AddDate(0, 0, -3).Truncate(time.Hour)
+	payload := `{"host":"web-1","metrics":[
+		{"type":"cpu","name":"cpu_usage","value":10,"unit":"%","collected_at":"` + windowStart.Add(10*time.Second).Format(time.RFC3339) + `"},
+		{"type":"cpu","name":"cpu_usage","value":30,"unit":"%","collected_at":"` + windowStart.Add(40*time.Second).Format(time.RFC3339) + `"},
+		{"type":"cpu","name":"cpu_usage","value":90,"unit":"%","collected_at":"` + windowStart.Add(80*time.Second).Format(time.RFC3339) + `"},
+		{"type":"cpu","name":"cpu_usage","value":50,"unit":"%"}
+	]}`
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+	ingestResp.Body.Close()
+
+	query := fmt.Sprintf("/api/v1/metrics/history?type=cpu&host=web-1&start=%s&end=%d&step=60",
+		url.QueryEscape(windowStart.Format(time.RFC3339)), windowStart.Add(10*time.Minute).Unix())
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+query, nil, authHeaders)
+	if historyResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for absolute history, got %d", historyResp.StatusCode)
+	}
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	historyResp.Body.Close()
+
+	if history.StepSeconds != 60 || len(history.Metrics) != 2 {
+		t.Fatalf("expected two 1m buckets, got step=%v points=%d", history.StepSeconds, len(history.Metrics))
+	}
+	first := history.Metrics[0]
+	if !first.CollectedAt.Equal(windowStart) || first.Value != 20 || first.Rollup == nil || first.Rollup.Count != 2 {
+		t.Fatalf("unexpected first bucket: %+v", first)
+	}
+	if history.Max != 90 {
+		t.Fatalf("expected max 90 inside the window, got %v", history.Max)
+	}
+
+	for _, bad := range []string{
+		"type=cpu&start=" + url.QueryEscape(windowStart.Format(time.RFC3339)) + "&end=" + url.QueryEscape(windowStart.Add(-time.Hour).Format(time.RFC3339)),
+		"type=cpu&start=yesterday",
+		"type=cpu&duration=1h&step=0",
+		"type=cpu&duration=24h&step=1s",
+	} {
+		resp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?"+bad, nil, authHeaders)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %q, got %d", bad, resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestE2EQueryLanguage(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	evalAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Minute)
+	at := func(offset time.Duration) string {
+		return evalAt.Add(offset).Format(time.RFC3339)
+	}
+	payload := `{"host":"web-1","metrics":[
+		{"type":"cpu","name":"cpu_usage","value":20,"unit":"%","labels":{"core":"0"},"collected_at":"` + at(-2*time.Minute) + `"},
+		{"type":"cpu","name":"cpu_usage","value":40,"unit":"%","labels":{"core":"0"},"collected_at":"` + at(-time.Minute) + `"},
+		{"type":"cpu","name":"cpu_usage","value":60,"unit":"%","labels":{"core":"1"},"collected_at":"` + at(-time.Minute) + `"}
+	]}`
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+	ingestResp.Body.Close()
+
+	unauthorized := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/query?query=cpu_usage", nil, nil)
+	if unauthorized.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorized.StatusCode)
+	}
+	unauthorized.Body.Close()
+
+	query := "/api/v1/query?time=" + fmt.Sprint(evalAt.Unix()) + "&query=" +
+		url.QueryEscape(`avg by (host) (avg_over_time(cpu_usage{host="web-1"}[5m])) * 2`)
+	resp := doRequest(t, client, http.MethodGet, server.URL+query, nil, authHeaders)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for instant query, got %d", resp.StatusCode)
+	}
+	var instant struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string               `json:"resultType"`
+			Result     []dto.QuerySampleDTO `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&instant); err != nil {
+		t.Fatalf("decode query response: %v", err)
+	}
+	resp.Body.Close()
+
+	// avg_over_time: core 0 = 30, core 1 = 60; среднее по хосту 45, умноженное на 2
+	if instant.Status != "success" || instant.Data.ResultType != "vector" || len(instant.Data.Result) != 1 {
+		t.Fatalf("unexpected instant response: %+v", instant)
+	}
+	sample := instant.Data.Result[0]
+	if sample.Metric["host"] != "web-1" || len(sample.Metric) != 1 || sample.Value[1] != "90" {
+		t.Fatalf("unexpected sample: %+v", sample)
+	}
+
+	rangeQuery := fmt.Sprintf("/api/v1/query_range?query=%s&start=%d&end=%d&step=60",
+		url.QueryEscape(`max(cpu_usage)`), evalAt.Add(-2*time.Minute).Unix(), evalAt.Unix())
+	resp = doRequest(t, client, http.MethodGet, server.URL+rangeQuery, nil, authHeaders)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for range query, got %d", resp.StatusCode)
+	}
+	var ranged struct {
+		Data struct {
+			ResultType string               `json:"resultType"`
+			Result     []dto.QuerySeriesDTO `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ranged); err != nil {
+		t.Fatalf("decode query_range response: %v", err)
+	}
+	resp.Body.Close()
+
+	if ranged.Data.ResultType != "matrix" || len(ranged.Data.Result) != 1 || len(ranged.Data.Result[0].Values) != 3 {
+		t.Fatalf("unexpected range response: %+v", ranged)
+	}
+	values := ranged.Data.Result[0].Values
+	if values[0][1] != "20" || values[1][1] != "60" || values[2][1] != "60" {
+		t.Fatalf("unexpected range values: %+v", values)
+	}
+
+	for bad, status := range map[string]int{
+		"/api/v1/query?query=" + url.QueryEscape(`rate(cpu_usage)`):                                                        http.StatusBadRequest,
+		"/api/v1/query?time=" + fmt.Sprint(evalAt.Unix()) + "&query=" + url.QueryEscape(`cpu_usage + on (host) cpu_usage`): http.StatusUnprocessableEntity,
+		"/api/v1/query?query=cpu_usage&time=yesterday":                                                                     http.StatusBadRequest,
+		"/api/v1/query_range?query=cpu_usage&start=1&end=2":                                                                http.StatusBadRequest,
+		"/api/v1/query_range?query=cpu_usage&start=1000&end=100000&step=1":                                                 http.StatusBadRequest,
+	} {
+		resp := doRequest(t, client, http.MethodGet, server.URL+bad, nil, authHeaders)
+		if resp.StatusCode != status {
+			t.Fatalf("expected %d for %q, got %d", status, bad, resp.StatusCode)
+		}
+		var body dto.QueryResponseDTO
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Status != "error" {
+			t.Fatalf("expected error envelope for %q, got %+v (%v)", bad, body, err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestE2ECustomMetricType(t *testing.T) {
+	err := valueobject.DefaultMetricTypeRegistry().Register(valueobject.MetricTypeDefinition{
+		Type:        "load_avg",
+		DisplayName: "Load Average",
+		Units:       []string{"load"},
+		Thresholds:  valueobject.Thresholds{Unit: "load", Warning: 4, Critical: 8},
+	})
+	if err != nil {
+		t.Fatalf("register metric type: %v", err)
+	}
+
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{"Authorization": "Bearer " + testToken}
+
+	typesResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/types", nil, authHeaders)
+	if typesResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for metric types, got %d", typesResp.StatusCode)
+	}
+	var types []dto.MetricTypeDTO
+	if err := json.NewDecoder(typesResp.Body).Decode(&types); err != nil {
+		t.Fatalf("decode metric types: %v", err)
+	}
+	typesResp.Body.Close()
+	found := false
+	for _, metricType := range types {
+		if metricType.Name == "load_avg" && metricType.DisplayName == "Load Average" && metricType.Critical == 8 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected load_avg in metric types, got %+v", types)
+	}
+
+	payload := `{"host":"app-1","metrics":[
+		{"type":"load_avg","name":"load1","value":9.5,"unit":"load"},
+		{"type":"load_avg","name":"load1","value":1,"unit":"%"}
+	]}`
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(payload), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	var result dto.IngestMetricsResultDTO
+	if err := json.NewDecoder(ingestResp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode ingest response: %v", err)
+	}
+	ingestResp.Body.Close()
+	if result.Accepted != 1 || result.Rejected != 1 {
+		t.Fatalf("expected unit mismatch to be rejected, got %+v", result)
+	}
+
+	historyResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/metrics/history?type=load_avg&duration=1h", nil, authHeaders)
+	if historyResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for custom type history, got %d", historyResp.StatusCode)
+	}
+	defer historyResp.Body.Close()
+
+	var history dto.MetricHistoryDTO
+	if err := json.NewDecoder(historyResp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode history response: %v", err)
+	}
+	if len(history.Metrics) != 1 || !history.Metrics[0].IsCritical || history.CriticalCount != 1 {
+		t.Fatalf("expected one critical load_avg metric, got %+v", history)
+	}
+}
+
+func TestE2EAlertRulesCRUD(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{
+		"Authorization": "Bearer " + testToken,
+		"Content-Type":  "application/json",
+	}
+
+	unauthorizedResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/alerts/rules", nil, nil)
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+	unauthorizedResp.Body.Close()
+
+	invalidResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(`{"name":"bad","metric_type":"cpu","comparator":"~","threshold":1}`), authHeaders)
+	if invalidResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid comparator, got %d", invalidResp.StatusCode)
+	}
+	invalidResp.Body.Close()
+
+	badUnitResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(`{"name":"bad","metric_type":"cpu","unit":"bytes","comparator":">","threshold":1}`), authHeaders)
+	if badUnitResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unit not allowed for the type, got %d", badUnitResp.StatusCode)
+	}
+	badUnitResp.Body.Close()
+
+	payload := `{"name":"High disk on data","metric_type":"disk","unit":"%","selector":"mount=\"/data\"","aggregate":"max","window":"5m","comparator":">","threshold":85,"for":"0s","cooldown":"10m","severity":"critical"}`
+	createResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(payload), authHeaders)
+	if createResp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for create, got %d", createResp.StatusCode)
+	}
+	var created dto.AlertRuleDTO
+	if err := json.NewDecoder(createResp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode create response: %v", err)
+	}
+	createResp.Body.Close()
+
+	if created.ID == "" || !created.Enabled || created.Window != "5m0s" || created.Selector != `{mount="/data"}` || created.Unit != "%" {
+		t.Fatalf("unexpected created rule: %+v", created)
+	}
+
+	// Метрика, нарушающая правило, не должна ломать прием пакета
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(`{"host":"db-1","metrics":[
+		{"type":"disk","name":"disk_usage","value":95,"unit":"%","labels":{"mount":"/data"}}
+	]}`), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+	ingestResp.Body.Close()
+
+	updateResp := doRequest(t, client, http.MethodPut, server.URL+"/api/v1/alerts/rules/"+created.ID, bytes.NewBufferString(`{"name":"High disk on data","metric_type":"disk","comparator":">=","threshold":90,"enabled":false}`), authHeaders)
+	if updateResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for update, got %d", updateResp.StatusCode)
+	}
+	var updated dto.AlertRuleDTO
+	if err := json.NewDecoder(updateResp.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode update response: %v", err)
+	}
+	updateResp.Body.Close()
+
+	if updated.Enabled || updated.Comparator != ">=" || updated.Threshold != 90 {
+		t.Fatalf("unexpected updated rule: %+v", updated)
+	}
+
+	listResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/alerts/rules", nil, authHeaders)
+	if listResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for list, got %d", listResp.StatusCode)
+	}
+	var rules []dto.AlertRuleDTO
+	if err := json.NewDecoder(listResp.Body).Decode(&rules); err != nil {
+		t.Fatalf("decode list response: %v", err)
+	}
+	listResp.Body.Close()
+	if len(rules) != 1 || rules[0].ID != created.ID {
+		t.Fatalf("unexpected rules list: %+v", rules)
+	}
+
+	deleteResp := doRequest(t, client, http.MethodDelete, server.URL+"/api/v1/alerts/rules/"+created.ID, nil, authHeaders)
+	if deleteResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 for delete, got %d", deleteResp.StatusCode)
+	}
+	deleteResp.Body.Close()
+
+	missingResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/alerts/rules/"+created.ID, nil, authHeaders)
+	if missingResp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", missingResp.StatusCode)
+	}
+	missingResp.Body.Close()
+}
+
+func TestE2EIncidentsLifecycle(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{
+		"Authorization": "Bearer " + testToken,
+		"Content-Type":  "application/json",
+	}
+
+	ruleResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(`{"name":"Memory pressure","metric_type":"memory","aggregate":"last","comparator":">","threshold":90,"severity":"critical"}`), authHeaders)
+	if ruleResp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for rule create, got %d", ruleResp.StatusCode)
+	}
+	ruleResp.Body.Close()
+
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(`{"host":"cache-1","metrics":[
+		{"type":"memory","name":"memory_usage","value":97,"unit":"%"}
+	]}`), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+	ingestResp.Body.Close()
+
+	listResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/incidents?status=active", nil, authHeaders)
+	if listResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for incidents list, got %d", listResp.StatusCode)
+	}
+	var incidents []dto.IncidentDTO
+	if err := json.NewDecoder(listResp.Body).Decode(&incidents); err != nil {
+		t.Fatalf("decode incidents response: %v", err)
+	}
+	listResp.Body.Close()
+
+	if len(incidents) != 1 || incidents[0].Host != "cache-1" || incidents[0].Title != "Memory pressure" || incidents[0].Status != "open" {
+		t.Fatalf("unexpected incidents: %+v", incidents)
+	}
+	incidentURL := server.URL + "/api/v1/incidents/" + incidents[0].ID
+
+	badStatusResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/incidents?status=closed", nil, authHeaders)
+	if badStatusResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown status, got %d", badStatusResp.StatusCode)
+	}
+	badStatusResp.Body.Close()
+
+	ackResp := doRequest(t, client, http.MethodPost, incidentURL+"/acknowledge", bytes.NewBufferString(`{"by":"oncall"}`), authHeaders)
+	if ackResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for acknowledge, got %d", ackResp.StatusCode)
+	}
+	var acknowledged dto.IncidentDTO
+	if err := json.NewDecoder(ackResp.Body).Decode(&acknowledged); err != nil {
+		t.Fatalf("decode acknowledge response: %v", err)
+	}
+	ackResp.Body.Close()
+	if acknowledged.Status != "acknowledged" || acknowledged.AcknowledgedBy != "oncall" {
+		t.Fatalf("unexpected acknowledged incident: %+v", acknowledged)
+	}
+
+	conflictResp := doRequest(t, client, http.MethodPost, incidentURL+"/acknowledge", bytes.NewBufferString(`{"by":"oncall"}`), authHeaders)
+	if conflictResp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for second acknowledge, got %d", conflictResp.StatusCode)
+	}
+	conflictResp.Body.Close()
+
+	resolveResp := doRequest(t, client, http.MethodPost, incidentURL+"/resolve", bytes.NewBufferString(`{"by":"oncall","message":"Restarted cache"}`), authHeaders)
+	if resolveResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for resolve, got %d", resolveResp.StatusCode)
+	}
+	resolveResp.Body.Close()
+
+	getResp := doRequest(t, client, http.MethodGet, incidentURL, nil, authHeaders)
+	if getResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for incident, got %d", getResp.StatusCode)
+	}
+	var resolved dto.IncidentDTO
+	if err := json.NewDecoder(getResp.Body).Decode(&resolved); err != nil {
+		t.Fatalf("decode incident response: %v", err)
+	}
+	getResp.Body.Close()
+	if resolved.Status != "resolved" || resolved.ResolvedBy != "oncall" || resolved.LastMessage != "Restarted cache" {
+		t.Fatalf("unexpected resolved incident: %+v", resolved)
+	}
+
+	createResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/incidents", bytes.NewBufferString(`{"title":"Planned maintenance","severity":"info","host":"db-1"}`), authHeaders)
+	if createResp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for manual incident, got %d", createResp.StatusCode)
+	}
+	createResp.Body.Close()
+
+	missingResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/incidents/unknown", nil, authHeaders)
+	if missingResp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown incident, got %d", missingResp.StatusCode)
+	}
+	missingResp.Body.Close()
+}
+
+func TestE2ENotificationDeliveries(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{
+		"Authorization": "Bearer " + testToken,
+		"Content-Type":  "application/json",
+	}
+
+	ruleResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(`{"name":"Disk full","metric_type":"disk","aggregate":"last","comparator":">","threshold":95,"severity":"critical","channels":["ops","pager"]}`), authHeaders)
+	if ruleResp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for rule create, got %d", ruleResp.StatusCode)
+	}
+	var rule dto.AlertRuleDTO
+	if err := json.NewDecoder(ruleResp.Body).Decode(&rule); err != nil {
+		t.Fatalf("decode rule response: %v", err)
+	}
+	ruleResp.Body.Close()
+	if len(rule.Channels) != 2 || rule.Channels[0] != "ops" {
+		t.Fatalf("unexpected rule channels: %v", rule.Channels)
+	}
+
+	invalidResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/alerts/rules", bytes.NewBufferString(`{"name":"Bad","metric_type":"disk","comparator":">","threshold":1,"channels":["Not Valid"]}`), authHeaders)
+	if invalidResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid channel name, got %d", invalidResp.StatusCode)
+	}
+	invalidResp.Body.Close()
+
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(`{"host":"db-1","metrics":[
+		{"type":"disk","name":"disk_usage","value":99,"unit":"%"}
+	]}`), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+	ingestResp.Body.Close()
+
+	// Доставка асинхронная: ждем, пока воркер отправит уведомление в "ops"
+	var delivered []dto.NotificationDeliveryDTO
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/notifications/deliveries?status=delivered", nil, authHeaders)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for deliveries, got %d", resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&delivered); err != nil {
+			t.Fatalf("decode deliveries response: %v", err)
+		}
+		resp.Body.Close()
+		if len(delivered) > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if len(delivered) != 1 || delivered[0].Channel != "ops" || delivered[0].Host != "db-1" || delivered[0].Attempts != 1 {
+		t.Fatalf("unexpected delivered notifications: %+v", delivered)
+	}
+
+	// Неизвестный канал фиксируется в журнале как неудачная доставка
+	failedResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/notifications/deliveries?channel=pager", nil, authHeaders)
+	var failed []dto.NotificationDeliveryDTO
+	if err := json.NewDecoder(failedResp.Body).Decode(&failed); err != nil {
+		t.Fatalf("decode deliveries response: %v", err)
+	}
+	failedResp.Body.Close()
+	if len(failed) != 1 || failed[0].Status != "failed" || !strings.Contains(failed[0].LastError, "unknown channel") {
+		t.Fatalf("unexpected pager deliveries: %+v", failed)
+	}
+
+	badStatusResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/notifications/deliveries?status=lost", nil, authHeaders)
+	if badStatusResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown delivery status, got %d", badStatusResp.StatusCode)
+	}
+	badStatusResp.Body.Close()
+}
+
+func TestE2ERetentionAdmin(t *testing.T) {
+	server, _ := newTestServer(t, "http://example.invalid")
+	client := server.Client()
+	authHeaders := map[string]string{
+		"Authorization": "Bearer " + testToken,
+	}
+
+	// Три старые точки CPU подпадают под общий срок (2h); диск хранится бессрочно
+	old := time.Now().UTC().Add(-5 * time.Hour).Format(time.RFC3339)
+	ingestResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/ingest/metrics", bytes.NewBufferString(`{"host":"old-1","metrics":[
+		{"type":"cpu","name":"cpu_usage","value":10,"unit":"%","collected_at":"`+old+`"},
+		{"type":"cpu","name":"cpu_usage","value":11,"unit":"%","collected_at":"`+old+`"},
+		{"type":"cpu","name":"cpu_usage","value":12,"unit":"%","collected_at":"`+old+`"},
+		{"type":"disk","name":"disk_usage","value":50,"unit":"%","collected_at":"`+old+`"}
+	]}`), map[string]string{
+		"Authorization": "Bearer " + testIngestToken,
+	})
+	if ingestResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for ingest, got %d", ingestResp.StatusCode)
+	}
+	ingestResp.Body.Close()
+
+	runRetention := func(query string) dto.RetentionRunDTO {
+		t.Helper()
+		resp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/admin/retention/run"+query, nil, authHeaders)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 for retention run, got %d", resp.StatusCode)
+		}
+		defer resp.Body.Close()
+		var run dto.RetentionRunDTO
+		if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+			t.Fatalf("decode retention run: %v", err)
+		}
+		return run
+	}
+
+	dryRun := runRetention("?dry_run=true")
+	if !dryRun.DryRun || dryRun.Rows != 3 || len(dryRun.Policies) != 1 || dryRun.Policies[0].MetricType != "*" {
+		t.Fatalf("unexpected dry run result: %+v", dryRun)
+	}
+
+	run := runRetention("")
+	if run.DryRun || run.Rows != 3 || run.Policies[0].Batches != 2 {
+		t.Fatalf("unexpected retention run result: %+v", run)
+	}
+
+	if again := runRetention("?dry_run=1"); again.Rows != 0 {
+		t.Fatalf("expected nothing left to delete, got %d", again.Rows)
+	}
+
+	statsResp := doRequest(t, client, http.MethodGet, server.URL+"/api/v1/admin/retention", nil, authHeaders)
+	if statsResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for retention stats, got %d", statsResp.StatusCode)
+	}
+	var stats dto.RetentionStatsDTO
+	if err := json.NewDecoder(statsResp.Body).Decode(&stats); err != nil {
+		t.Fatalf("decode retention stats: %v", err)
+	}
+	statsResp.Body.Close()
+	if stats.Runs != 3 || stats.RowsDeleted != 3 || stats.LastRun == nil || !stats.LastRun.DryRun {
+		t.Fatalf("unexpected retention stats: %+v", stats)
+	}
+
+	badResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/admin/retention/run?dry_run=maybe", nil, authHeaders)
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid dry_run, got %d", badResp.StatusCode)
+	}
+	badResp.Body.Close()
+
+	unauthorizedResp := doRequest(t, client, http.MethodPost, server.URL+"/api/v1/admin/retention/run", nil, nil)
+	if unauthorizedResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", unauthorizedResp.StatusCode)
+	}
+	unauthorizedResp.Body.Close()
+}
+
 func TestE2EScreenshotEndpoints(t *testing.T) {
 	server, _ := newTestServer(t, "http://example.invalid")
 	client := server.Client()
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// alertRulesPath базовый путь API правил алертинга
+const alertRulesPath = "/api/v1/alerts/rules"
+
+// maxJSONPayloadBytes ограничивает размер JSON-тела запросов управления (правила, инциденты)
+const maxJSONPayloadBytes = 64 * 1024
+
+// AlertRulesAPIHandler обрабатывает CRUD API правил алертинга
+type AlertRulesAPIHandler struct {
+	manageAlertRulesUC *usecase.ManageAlertRulesUseCase
+	logger             *logger.Logger
+}
+
+// NewAlertRulesAPIHandler создает новый handler
+func NewAlertRulesAPIHandler(
+	manageAlertRulesUC *usecase.ManageAlertRulesUseCase,
+	logger *logger.Logger,
+) *AlertRulesAPIHandler {
+	return &AlertRulesAPIHandler{
+		manageAlertRulesUC: manageAlertRulesUC,
+		logger:             logger,
+	}
+}
+
+// HandleRules обрабатывает /api/v1/alerts/rules (список и создание)
+func (h *AlertRulesAPIHandler) HandleRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListRules(w, r)
+	case http.MethodPost:
+		h.CreateRule(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleRule обрабатывает /api/v1/alerts/rules/{id}
+func (h *AlertRulesAPIHandler) HandleRule(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, alertRulesPath), "/")
+	if id == "" {
+		h.HandleRules(w, r)
+		return
+	}
+	if strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.GetRule(w, r, id)
+	case http.MethodPut:
+		h.UpdateRule(w, r, id)
+	case http.MethodDelete:
+		h.DeleteRule(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListRules возвращает все правила
+func (h *AlertRulesAPIHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.manageAlertRulesUC.List(r.Context())
+	if err != nil {
+		h.writeError(w, err, "Failed to list alert rules")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, rules)
+}
+
+// CreateRule создает правило
+func (h *AlertRulesAPIHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req dto.AlertRuleRequestDTO
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	rule, err := h.manageAlertRulesUC.Create(r.Context(), req)
+	if err != nil {
+		h.writeError(w, err, "Failed to create alert rule")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, rule)
+}
+
+// GetRule возвращает правило по идентификатору
+func (h *AlertRulesAPIHandler) GetRule(w http.ResponseWriter, r *http.Request, id string) {
+	rule, err := h.manageAlertRulesUC.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Failed to get alert rule")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, rule)
+}
+
+// UpdateRule заменяет параметры правила
+func (h *AlertRulesAPIHandler) UpdateRule(w http.ResponseWriter, r *http.Request, id string) {
+	var req dto.AlertRuleRequestDTO
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	rule, err := h.manageAlertRulesUC.Update(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, err, "Failed to update alert rule")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, rule)
+}
+
+// DeleteRule удаляет правило
+func (h *AlertRulesAPIHandler) DeleteRule(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.manageAlertRulesUC.Delete(r.Context(), id); err != nil {
+		h.writeError(w, err, "Failed to delete alert rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeJSONBody декодирует JSON-тело запроса в dst
+// При ошибке пишет ответ 400/413 и возвращает false
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONPayloadBytes)
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		if strings.Contains(err.Error(), "http: request body too large") {
+			http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// writeError преобразует ошибку use case в HTTP статус
+func (h *AlertRulesAPIHandler) writeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrAlertRuleNotFound):
+		http.Error(w, "Alert rule not found", http.StatusNotFound)
+	case strings.Contains(err.Error(), "invalid alert rule"):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
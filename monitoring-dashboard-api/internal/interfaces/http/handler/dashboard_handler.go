@@ -27,8 +27,8 @@ func NewDashboardHandler(
 
 // ShowDashboard отображает главную страницу dashboard
 func (h *DashboardHandler) ShowDashboard(w http.ResponseWriter, r *http.Request) {
-	// Получаем текущие метрики
-	snapshot, err := h.getCurrentMetricsUC.Execute(r.Context())
+	// Получаем текущие метрики (опционально для конкретного хоста)
+	snapshot, err := h.getCurrentMetricsUC.ExecuteForHost(r.Context(), r.URL.Query().Get("host"))
 	if err != nil {
 		h.logger.Error("Failed to get current metrics", err)
 		http.Error(w, "Failed to load metrics", http.StatusInternalServerError)
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/export"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/observability/prometheus"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ExportAPIHandler отдает собранные метрики во внешние системы
+type ExportAPIHandler struct {
+	exportMetricsUC *usecase.ExportMetricsUseCase
+	maxRange        time.Duration
+	logger          *logger.Logger
+}
+
+// NewExportAPIHandler создает новый handler
+// maxRange - самый длинный диапазон выгрузки истории
+func NewExportAPIHandler(exportMetricsUC *usecase.ExportMetricsUseCase, maxRange time.Duration, logger *logger.Logger) *ExportAPIHandler {
+	if maxRange <= 0 {
+		maxRange = 31 * 24 * time.Hour
+	}
+
+	return &ExportAPIHandler{
+		exportMetricsUC: exportMetricsUC,
+		maxRange:        maxRange,
+		logger:          logger,
+	}
+}
+
+// Prometheus обрабатывает GET /api/v1/export/prometheus[?host=...][&selector=...]
+// Последнее значение каждой серии в текстовом формате Prometheus или OpenMetrics (по Accept)
+func (h *ExportAPIHandler) Prometheus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	selector, message := parseExportSelector(r.URL.Query())
+	if message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	families, err := h.exportMetricsUC.Latest(r.Context(), selector)
+	if err != nil {
+		h.logger.Error("Failed to export metrics", err)
+		http.Error(w, "Failed to export metrics", http.StatusInternalServerError)
+		return
+	}
+
+	format := prometheus.NegotiateFormat(r.Header.Get("Accept"))
+	w.Header().Set("Content-Type", format.ContentType())
+	buf := bufio.NewWriter(w)
+	prometheus.WriteFamilies(buf, format, families)
+	_ = buf.Flush()
+}
+
+// History обрабатывает GET /api/v1/metrics/export?format=csv|ndjson|parquet&start=...[&end=...][&type=...][&host=...][&selector=...]
+// Выгружает все точки диапазона по возрастанию времени, читая хранилище страницами, а не одним запросом с лимитом
+func (h *ExportAPIHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+
+	format, err := export.ParseFormat(params.Get("format"))
+	if err != nil {
+		http.Error(w, "Invalid format: use csv, ndjson or parquet", http.StatusBadRequest)
+		return
+	}
+
+	query, message := h.parseHistoryQuery(params)
+	if message != "" {
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	filename := fmt.Sprintf("metrics-%s-%s.%s",
+		query.TimeRange.Start().UTC().Format("20060102T150405Z"),
+		query.TimeRange.End().UTC().Format("20060102T150405Z"),
+		format.Extension(),
+	)
+
+	// Заголовки отправляются с первой строкой, чтобы ошибка первого запроса к хранилищу осталась обычным 500
+	var writer export.RowWriter
+	begin := func() {
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+		w.WriteHeader(http.StatusOK)
+		writer = export.NewRowWriter(format, w)
+	}
+
+	rows, err := h.exportMetricsUC.Stream(r.Context(), query, func(metric *dto.MetricDTO) error {
+		if writer == nil {
+			begin()
+		}
+		return writer.WriteRow(metric)
+	})
+	if err != nil {
+		if writer == nil {
+			h.logger.Error("Failed to export metrics history", err)
+			http.Error(w, "Failed to export metrics", http.StatusInternalServerError)
+			return
+		}
+		// Часть файла уже отправлена: обрываем соединение, чтобы клиент не принял обрезанный файл за полный
+		h.logger.Warn("Metrics history export aborted", "rows", rows, "error", err.Error())
+		panic(http.ErrAbortHandler)
+	}
+
+	if writer == nil {
+		begin()
+	}
+	if err := writer.Close(); err != nil {
+		h.logger.Warn("Failed to finish metrics history export", "rows", rows, "error", err.Error())
+		panic(http.ErrAbortHandler)
+	}
+
+	h.logger.Debug("Metrics history exported", "format", format, "rows", rows)
+}
+
+// parseHistoryQuery разбирает фильтры и диапазон выгрузки истории
+// Возвращает текст ошибки для ответа 400 (пустой - параметры корректны)
+func (h *ExportAPIHandler) parseHistoryQuery(params url.Values) (repository.MetricQuery, string) {
+	if params.Get("start") == "" {
+		return repository.MetricQuery{}, "Missing required parameter: start"
+	}
+	start, err := valueobject.ParseTimestamp(params.Get("start"))
+	if err != nil {
+		return repository.MetricQuery{}, "Invalid start: use RFC3339 or unix seconds"
+	}
+
+	end := time.Now()
+	if raw := params.Get("end"); raw != "" {
+		end, err = valueobject.ParseTimestamp(raw)
+		if err != nil {
+			return repository.MetricQuery{}, "Invalid end: use RFC3339 or unix seconds"
+		}
+	}
+
+	if !start.Before(end) {
+		return repository.MetricQuery{}, "Invalid time range: start must be before end"
+	}
+	if end.Sub(start) > h.maxRange {
+		return repository.MetricQuery{}, fmt.Sprintf("Time range too long (max %s)", h.maxRange)
+	}
+	timeRange, err := valueobject.NewTimeRange(start, end)
+	if err != nil {
+		return repository.MetricQuery{}, "Invalid time range"
+	}
+
+	metricType := valueobject.MetricType(params.Get("type"))
+	if metricType != "" {
+		if err := metricType.Validate(); err != nil {
+			return repository.MetricQuery{}, "Invalid metric type"
+		}
+	}
+
+	selector, message := parseExportSelector(params)
+	if message != "" {
+		return repository.MetricQuery{}, message
+	}
+
+	return repository.MetricQuery{
+		Type:      metricType,
+		Selector:  selector,
+		TimeRange: timeRange,
+	}, ""
+}
+
+// parseExportSelector разбирает параметры selector и host в селектор меток
+func parseExportSelector(params url.Values) (valueobject.LabelSelector, string) {
+	selector, err := valueobject.ParseLabelSelector(params.Get("selector"))
+	if err != nil {
+		return valueobject.LabelSelector{}, "Invalid selector"
+	}
+	if host := params.Get("host"); host != "" {
+		hostMatcher, err := valueobject.NewLabelMatcher(valueobject.HostLabel, valueobject.MatchEqual, host)
+		if err != nil {
+			return valueobject.LabelSelector{}, "Invalid host"
+		}
+		selector = selector.With(hostMatcher)
+	}
+	return selector, ""
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/bulk"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ImportAPIHandler принимает файлы с историей метрик (бэкфилл при переносе хостов)
+type ImportAPIHandler struct {
+	importMetricsUC *usecase.ImportMetricsUseCase
+	maxPayloadBytes int64
+	logger          *logger.Logger
+}
+
+// NewImportAPIHandler создает новый handler
+// maxPayloadBytes - предельный размер тела запроса (до распаковки gzip)
+func NewImportAPIHandler(
+	importMetricsUC *usecase.ImportMetricsUseCase,
+	maxPayloadBytes int64,
+	logger *logger.Logger,
+) *ImportAPIHandler {
+	if maxPayloadBytes <= 0 {
+		maxPayloadBytes = 64 * 1024 * 1024
+	}
+
+	return &ImportAPIHandler{
+		importMetricsUC: importMetricsUC,
+		maxPayloadBytes: maxPayloadBytes,
+		logger:          logger,
+	}
+}
+
+// Import обрабатывает POST /api/v1/metrics/import[?format=csv|ndjson][&dry_run=true]
+// Формат берется из параметра format, иначе из Content-Type; тело может быть сжато gzip
+func (h *ImportAPIHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := bulk.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, "Invalid format: use csv or ndjson", http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("format") == "" {
+		if detected, ok := bulk.FormatFromContentType(r.Header.Get("Content-Type")); ok {
+			format = detected
+		}
+	}
+
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxPayloadBytes)
+	defer r.Body.Close()
+
+	var body io.Reader = r.Body
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		http.Error(w, "Unsupported content encoding, use gzip", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	reader, err := bulk.NewReader(format, body)
+	if err != nil {
+		h.writeReadError(w, err)
+		return
+	}
+
+	result, err := h.importMetricsUC.Execute(r.Context(), reader, dryRun)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			// Строки до предела уже записаны: отчет показывает, с какого места продолжить
+			middleware.WriteJSON(w, http.StatusRequestEntityTooLarge, result)
+		case errors.Is(err, usecase.ErrInvalidImportFile):
+			middleware.WriteJSON(w, http.StatusBadRequest, result)
+		default:
+			h.logger.Error("Metrics import failed", err)
+			middleware.WriteJSON(w, http.StatusInternalServerError, result)
+		}
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, result)
+}
+
+// writeReadError отвечает на ошибку чтения заголовка файла
+func (h *ImportAPIHandler) writeReadError(w http.ResponseWriter, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, bulk.ErrInvalidFile):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+	}
+}
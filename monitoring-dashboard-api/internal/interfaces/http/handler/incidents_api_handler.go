@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// incidentsPath базовый путь API инцидентов
+const incidentsPath = "/api/v1/incidents"
+
+// maxIncidentsLimit ограничивает размер страницы списка инцидентов
+const maxIncidentsLimit = 500
+
+// IncidentsAPIHandler обрабатывает API инцидентов
+type IncidentsAPIHandler struct {
+	manageIncidentsUC *usecase.ManageIncidentsUseCase
+	logger            *logger.Logger
+}
+
+// NewIncidentsAPIHandler создает новый handler
+func NewIncidentsAPIHandler(
+	manageIncidentsUC *usecase.ManageIncidentsUseCase,
+	logger *logger.Logger,
+) *IncidentsAPIHandler {
+	return &IncidentsAPIHandler{
+		manageIncidentsUC: manageIncidentsUC,
+		logger:            logger,
+	}
+}
+
+// HandleIncidents обрабатывает /api/v1/incidents (список и ручное создание)
+func (h *IncidentsAPIHandler) HandleIncidents(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListIncidents(w, r)
+	case http.MethodPost:
+		h.CreateIncident(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleIncident обрабатывает /api/v1/incidents/{id} и /api/v1/incidents/{id}/{acknowledge|resolve}
+func (h *IncidentsAPIHandler) HandleIncident(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, incidentsPath), "/"), "/")
+	if parts[0] == "" {
+		h.HandleIncidents(w, r)
+		return
+	}
+
+	id := parts[0]
+	switch {
+	case len(parts) == 1:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetIncident(w, r, id)
+	case len(parts) == 2 && (parts[1] == "acknowledge" || parts[1] == "resolve"):
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.ChangeIncidentStatus(w, r, id, parts[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ListIncidents возвращает инциденты
+// Параметры: status (через запятую; active = open,acknowledged), host, limit
+func (h *IncidentsAPIHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.IncidentFilter{
+		Host: strings.TrimSpace(query.Get("host")),
+	}
+
+	for _, status := range strings.Split(query.Get("status"), ",") {
+		switch status = strings.TrimSpace(status); status {
+		case "":
+		case "active":
+			filter.Statuses = append(filter.Statuses, entity.IncidentOpen, entity.IncidentAcknowledged)
+		default:
+			filter.Statuses = append(filter.Statuses, entity.IncidentStatus(status))
+		}
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxIncidentsLimit {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	incidents, err := h.manageIncidentsUC.List(r.Context(), filter)
+	if err != nil {
+		h.writeError(w, err, "Failed to list incidents")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, incidents)
+}
+
+// CreateIncident создает инцидент вручную
+func (h *IncidentsAPIHandler) CreateIncident(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateIncidentRequestDTO
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	incident, err := h.manageIncidentsUC.Open(r.Context(), req)
+	if err != nil {
+		h.writeError(w, err, "Failed to create incident")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusCreated, incident)
+}
+
+// GetIncident возвращает инцидент по идентификатору
+func (h *IncidentsAPIHandler) GetIncident(w http.ResponseWriter, r *http.Request, id string) {
+	incident, err := h.manageIncidentsUC.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err, "Failed to get incident")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, incident)
+}
+
+// ChangeIncidentStatus подтверждает или закрывает инцидент
+func (h *IncidentsAPIHandler) ChangeIncidentStatus(w http.ResponseWriter, r *http.Request, id, action string) {
+	var req dto.IncidentActionRequestDTO
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	var (
+		incident *dto.IncidentDTO
+		err      error
+	)
+	if action == "acknowledge" {
+		incident, err = h.manageIncidentsUC.Acknowledge(r.Context(), id, req)
+	} else {
+		incident, err = h.manageIncidentsUC.Resolve(r.Context(), id, req)
+	}
+	if err != nil {
+		h.writeError(w, err, "Failed to "+action+" incident")
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, incident)
+}
+
+// writeError преобразует ошибку use case в HTTP статус
+func (h *IncidentsAPIHandler) writeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrIncidentNotFound):
+		http.Error(w, "Incident not found", http.StatusNotFound)
+	case errors.Is(err, entity.ErrIncidentAlreadyAcknowledged), errors.Is(err, entity.ErrIncidentAlreadyResolved):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "invalid incident"):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(message, err)
+		http.Error(w, message, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/port"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/otlp"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/ingest/remotewrite"
+	"github.com/dreschagin/monitoring-dashboard/internal/infrastructure/persistence/buffer"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// maxIngestBatchSize ограничивает количество метрик в одном пакете агента
+const maxIngestBatchSize = 5000
+
+// IngestAPIHandler принимает метрики от удаленных агентов (monitoring-agent, Prometheus remote_write, OTLP)
+type IngestAPIHandler struct {
+	collectMetricsUC  *usecase.CollectMetricsUseCase
+	remoteWriteMapper *remotewrite.Mapper
+	otlpMapper        *otlp.Mapper
+	authConfig        middleware.AuthConfig
+	maxPayloadBytes   int64
+	logger            *logger.Logger
+}
+
+// NewIngestAPIHandler создает новый handler
+func NewIngestAPIHandler(
+	collectMetricsUC *usecase.CollectMetricsUseCase,
+	remoteWriteMapper *remotewrite.Mapper,
+	otlpMapper *otlp.Mapper,
+	authConfig middleware.AuthConfig,
+	maxPayloadBytes int64,
+	logger *logger.Logger,
+) *IngestAPIHandler {
+	if maxPayloadBytes <= 0 {
+		maxPayloadBytes = 1024 * 1024
+	}
+
+	return &IngestAPIHandler{
+		collectMetricsUC:  collectMetricsUC,
+		remoteWriteMapper: remoteWriteMapper,
+		otlpMapper:        otlpMapper,
+		authConfig:        authConfig,
+		maxPayloadBytes:   maxPayloadBytes,
+		logger:            logger,
+	}
+}
+
+// IngestMetrics принимает пакет сырых метрик одного хоста
+func (h *IngestAPIHandler) IngestMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxPayloadBytes)
+	defer r.Body.Close()
+
+	var req dto.IngestMetricsRequestDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Host = strings.TrimSpace(req.Host)
+	if req.Host == "" {
+		http.Error(w, "Missing required field: host", http.StatusBadRequest)
+		return
+	}
+	if len(req.Metrics) == 0 {
+		http.Error(w, "Missing required field: metrics", http.StatusBadRequest)
+		return
+	}
+	if len(req.Metrics) > maxIngestBatchSize {
+		http.Error(w, fmt.Sprintf("Too many metrics in batch (max %d)", maxIngestBatchSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	rawMetrics, rejected := toRawMetrics(req.Metrics)
+
+	result, err := h.collectMetricsUC.Ingest(r.Context(), req.Host, rawMetrics)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidHost) {
+			http.Error(w, "Invalid host", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, buffer.ErrWriteBufferFull) {
+			// Буфер записи не успевает сбрасываться: агент повторит отправку позже
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Ingest is overloaded, retry later", http.StatusServiceUnavailable)
+			return
+		}
+		h.logger.Error("Failed to ingest metrics", err, "host", req.Host)
+		http.Error(w, "Failed to ingest metrics", http.StatusInternalServerError)
+		return
+	}
+
+	// Метрики, отброшенные еще при разборе, тоже считаются отклоненными
+	result.Received += rejected
+	result.Rejected += rejected
+
+	middleware.WriteJSON(w, http.StatusAccepted, result)
+}
+
+// RemoteWrite принимает Prometheus remote_write 1.0 (snappy-сжатый protobuf WriteRequest)
+// Успешный прием - 204; 4xx Prometheus не повторяет, 5xx - повторяет
+// Число сэмплов в запросе ограничено только maxPayloadBytes
+func (h *IngestAPIHandler) RemoteWrite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	if version := r.Header.Get("X-Prometheus-Remote-Write-Version"); version != "" && !strings.HasPrefix(version, "0.1") {
+		http.Error(w, "Unsupported remote_write version, use 1.0", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxPayloadBytes)
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	req, err := remotewrite.DecodeWriteRequest(body)
+	if err != nil {
+		h.logger.Warn("Invalid remote_write payload", "remote_addr", r.RemoteAddr, "error", err.Error())
+		http.Error(w, "Invalid remote_write payload", http.StatusBadRequest)
+		return
+	}
+
+	rawMetrics, rejected := h.remoteWriteMapper.ToRawMetrics(req)
+
+	// Prometheus не повторяет 4xx, поэтому большой запрос (размер уже ограничен maxPayloadBytes)
+	// не отклоняется, а записывается частями по maxIngestBatchSize. После ошибки на середине
+	// Prometheus повторит весь запрос, и уже записанные части сохранятся повторно
+	result := &dto.IngestMetricsResultDTO{}
+	for start := 0; start < len(rawMetrics); start += maxIngestBatchSize {
+		end := min(start+maxIngestBatchSize, len(rawMetrics))
+
+		var chunk *dto.IngestMetricsResultDTO
+		chunk, err = h.collectMetricsUC.IngestSeries(r.Context(), rawMetrics[start:end])
+		if err != nil {
+			break
+		}
+		result.Received += chunk.Received
+		result.Accepted += chunk.Accepted
+		result.Rejected += chunk.Rejected
+	}
+	if err != nil {
+		if errors.Is(err, buffer.ErrWriteBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Ingest is overloaded, retry later", http.StatusServiceUnavailable)
+			return
+		}
+		h.logger.Error("Failed to ingest remote_write samples", err)
+		http.Error(w, "Failed to ingest metrics", http.StatusInternalServerError)
+		return
+	}
+
+	// Отклоненные сэмплы не повторяются: Prometheus получает 204 и продолжает отправку
+	if rejected+result.Rejected > 0 {
+		h.logger.Debug("Remote_write samples rejected",
+			"series", len(req.Timeseries),
+			"accepted", result.Accepted,
+			"rejected", rejected+result.Rejected,
+		)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// OTLPMetrics принимает OTLP/HTTP ExportMetricsServiceRequest (protobuf или JSON, опционально gzip)
+// Ответ - ExportMetricsServiceResponse в кодировке запроса; отклоненные точки - в partial_success
+func (h *IngestAPIHandler) OTLPMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.authorize(w, r) {
+		return
+	}
+
+	format, ok := otlp.ParseContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		http.Error(w, "Unsupported content type, use application/x-protobuf or application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxPayloadBytes)
+	defer r.Body.Close()
+
+	body, err := otlp.ReadBody(r.Body, r.Header.Get("Content-Encoding"))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			writeOTLPStatus(w, format, http.StatusRequestEntityTooLarge, "Payload too large")
+		case errors.Is(err, otlp.ErrUnsupportedEncoding):
+			writeOTLPStatus(w, format, http.StatusUnsupportedMediaType, "Unsupported content encoding, use gzip")
+		default:
+			writeOTLPStatus(w, format, http.StatusBadRequest, "Failed to read request body")
+		}
+		return
+	}
+
+	req, err := otlp.DecodeRequest(body, format)
+	if err != nil {
+		h.logger.Warn("Invalid OTLP payload", "remote_addr", r.RemoteAddr, "error", err.Error())
+		writeOTLPStatus(w, format, http.StatusBadRequest, "Invalid OTLP metrics payload")
+		return
+	}
+
+	rawMetrics, rejected := h.otlpMapper.ToRawMetrics(req)
+	if len(rawMetrics) > maxIngestBatchSize {
+		writeOTLPStatus(w, format, http.StatusRequestEntityTooLarge, fmt.Sprintf("Too many samples in request (max %d)", maxIngestBatchSize))
+		return
+	}
+
+	result, err := h.collectMetricsUC.IngestSeries(r.Context(), rawMetrics)
+	if err != nil {
+		if errors.Is(err, buffer.ErrWriteBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			writeOTLPStatus(w, format, http.StatusServiceUnavailable, "Ingest is overloaded, retry later")
+			return
+		}
+		h.logger.Error("Failed to ingest OTLP metrics", err)
+		writeOTLPStatus(w, format, http.StatusInternalServerError, "Failed to ingest metrics")
+		return
+	}
+
+	rejected += result.Rejected
+	message := ""
+	if rejected > 0 {
+		message = "some data points were rejected: unknown metric type, unsupported kind or invalid value"
+		h.logger.Debug("OTLP data points rejected",
+			"accepted", result.Accepted,
+			"rejected", rejected,
+		)
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(otlp.EncodeResponse(format, int64(rejected), message))
+}
+
+// writeOTLPStatus отвечает ошибкой в виде google.rpc.Status, как требует OTLP/HTTP
+func writeOTLPStatus(w http.ResponseWriter, format otlp.Format, status int, message string) {
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(status)
+	_, _ = w.Write(otlp.EncodeStatus(format, status, message))
+}
+
+// authorize проверяет токен ingest; при ошибке отвечает 401
+func (h *IngestAPIHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if err := middleware.ValidateRequestAuth(r, h.authConfig); err != nil {
+		h.logger.Warn("Ingest unauthorized",
+			"path", r.URL.Path,
+			"remote_addr", r.RemoteAddr,
+		)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="monitoring-dashboard"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// toRawMetrics конвертирует метрики запроса в port.RawMetric
+// Возвращает количество метрик, которые не удалось разобрать
+func toRawMetrics(items []dto.IngestMetricDTO) ([]port.RawMetric, int) {
+	rawMetrics := make([]port.RawMetric, 0, len(items))
+	rejected := 0
+
+	for _, item := range items {
+		metricType := valueobject.MetricType(item.Type)
+		if err := metricType.Validate(); err != nil {
+			rejected++
+			continue
+		}
+
+		value, err := valueobject.NewMetricValue(item.Value, item.Unit)
+		if err != nil {
+			rejected++
+			continue
+		}
+
+		rawMetrics = append(rawMetrics, port.RawMetric{
+			Type:        metricType,
+			Name:        item.Name,
+			Value:       value,
+			Labels:      item.Labels,
+			Metadata:    item.Metadata,
+			CollectedAt: item.CollectedAt,
+		})
+	}
+
+	return rawMetrics, rejected
+}
@@ -2,17 +2,28 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
 	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
 	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
 )
 
+// maxHistoryPoints ограничивает количество бакетов шага step на серию
+const maxHistoryPoints = 11000
+
 // MetricsAPIHandler обрабатывает API запросы для метрик
 type MetricsAPIHandler struct {
 	getHistoricalMetricsUC *usecase.GetHistoricalMetricsUseCase
+	listMetricsUC          *usecase.ListMetricsUseCase
 	maxDuration            time.Duration
 	logger                 *logger.Logger
 }
@@ -20,6 +31,7 @@ type MetricsAPIHandler struct {
 // NewMetricsAPIHandler создает новый handler
 func NewMetricsAPIHandler(
 	getHistoricalMetricsUC *usecase.GetHistoricalMetricsUseCase,
+	listMetricsUC *usecase.ListMetricsUseCase,
 	maxDuration time.Duration,
 	logger *logger.Logger,
 ) *MetricsAPIHandler {
@@ -29,6 +41,7 @@ func NewMetricsAPIHandler(
 
 	return &MetricsAPIHandler{
 		getHistoricalMetricsUC: getHistoricalMetricsUC,
+		listMetricsUC:          listMetricsUC,
 		maxDuration:            maxDuration,
 		logger:                 logger,
 	}
@@ -36,52 +49,334 @@ func NewMetricsAPIHandler(
 
 // GetHistoricalMetrics возвращает исторические данные
 func (h *MetricsAPIHandler) GetHistoricalMetrics(w http.ResponseWriter, r *http.Request) {
-	// Получаем параметры из query string
-	metricTypeStr := r.URL.Query().Get("type")
-	durationStr := r.URL.Query().Get("duration")
+	params, errMsg := h.parseHistoryParams(r)
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
+		return
+	}
 
-	if metricTypeStr == "" || durationStr == "" {
-		http.Error(w, "Missing required parameters: type, duration", http.StatusBadRequest)
+	// Получаем метрики
+	var history *dto.MetricHistoryDTO
+	var err error
+	switch {
+	case params.step > 0:
+		selector, selectorErr := params.selectorWithHost()
+		if selectorErr != nil {
+			http.Error(w, "Invalid host", http.StatusBadRequest)
+			return
+		}
+		history, err = h.getHistoricalMetricsUC.ExecuteWithSelector(r.Context(), params.metricType, selector, params.timeRange, params.step)
+	case params.selector.IsEmpty():
+		history, err = h.getHistoricalMetricsUC.ExecuteWithAggregationForHost(r.Context(), params.host, params.metricType, params.timeRange)
+	default:
+		history, err = h.getHistoricalMetricsUC.ExecuteWithSelector(r.Context(), params.metricType, params.selector, params.timeRange, 0)
+	}
+	if err != nil {
+		if errors.Is(err, usecase.ErrTooManySamples) {
+			http.Error(w, err.Error()+"; narrow the selector or increase step", http.StatusUnprocessableEntity)
+			return
+		}
+		h.logger.Error("Failed to get historical metrics", err)
+		http.Error(w, "Failed to fetch metrics", http.StatusInternalServerError)
 		return
 	}
 
-	// Парсим metric type
-	metricType := valueobject.MetricType(metricTypeStr)
-	if err := metricType.Validate(); err != nil {
-		http.Error(w, "Invalid metric type", http.StatusBadRequest)
+	h.writeJSON(w, history)
+}
+
+// GetMetricSeries возвращает исторические данные, сгруппированные по метке group_by
+func (h *MetricsAPIHandler) GetMetricSeries(w http.ResponseWriter, r *http.Request) {
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		http.Error(w, "Missing required parameter: group_by", http.StatusBadRequest)
+		return
+	}
+	if err := valueobject.ValidateLabelName(groupBy); err != nil {
+		http.Error(w, "Invalid group_by label", http.StatusBadRequest)
+		return
+	}
+
+	params, errMsg := h.parseHistoryParams(r)
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
 		return
 	}
 
-	// Парсим duration
-	duration, err := time.ParseDuration(durationStr)
+	series, err := h.getHistoricalMetricsUC.ExecuteGroupedByLabel(r.Context(), params.metricType, params.selector, params.timeRange, groupBy, params.step)
 	if err != nil {
-		http.Error(w, "Invalid duration format", http.StatusBadRequest)
+		if errors.Is(err, usecase.ErrTooManySamples) {
+			http.Error(w, err.Error()+"; narrow the selector or increase step", http.StatusUnprocessableEntity)
+			return
+		}
+		h.logger.Error("Failed to get metric series", err)
+		http.Error(w, "Failed to fetch metrics", http.StatusInternalServerError)
 		return
 	}
-	if duration <= 0 || duration > h.maxDuration {
-		http.Error(w, "Duration out of allowed range", http.StatusBadRequest)
+
+	h.writeJSON(w, series)
+}
+
+// ListMetrics обрабатывает GET /api/v1/metrics[?type=...][&name=...][&host=...][&label=k=v][&selector=...][&start=...][&end=...][&limit=N][&cursor=...]
+// Сырые точки по возрастанию времени страницами; next_cursor ответа передается в cursor с теми же фильтрами
+func (h *MetricsAPIHandler) ListMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Создаем time range
-	timeRange, err := valueobject.NewTimeRangeFromDuration(duration)
-	if err != nil {
-		http.Error(w, "Invalid time range", http.StatusBadRequest)
+	query, errMsg := parseListMetricsQuery(r.URL.Query())
+	if errMsg != "" {
+		http.Error(w, errMsg, http.StatusBadRequest)
 		return
 	}
 
-	// Получаем метрики
-	history, err := h.getHistoricalMetricsUC.ExecuteWithAggregation(r.Context(), metricType, timeRange)
+	page, err := h.listMetricsUC.Execute(r.Context(), query)
 	if err != nil {
-		h.logger.Error("Failed to get historical metrics", err)
+		if errors.Is(err, usecase.ErrInvalidMetricCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to list metrics", err)
 		http.Error(w, "Failed to fetch metrics", http.StatusInternalServerError)
 		return
 	}
 
-	// Отправляем JSON
+	h.writeJSON(w, page)
+}
+
+// parseListMetricsQuery разбирает фильтры и параметры страницы для ListMetrics
+// Возвращает текст ошибки для ответа 400, если параметры некорректны
+func parseListMetricsQuery(params url.Values) (usecase.ListMetricsQuery, string) {
+	query := usecase.ListMetricsQuery{
+		Name:   params.Get("name"),
+		Cursor: params.Get("cursor"),
+	}
+
+	if raw := params.Get("type"); raw != "" {
+		query.Type = valueobject.MetricType(raw)
+		if err := query.Type.Validate(); err != nil {
+			return usecase.ListMetricsQuery{}, "Invalid metric type"
+		}
+	}
+
+	if raw := params.Get("start"); raw != "" {
+		start, err := valueobject.ParseTimestamp(raw)
+		if err != nil {
+			return usecase.ListMetricsQuery{}, "Invalid start: use RFC3339 or unix seconds"
+		}
+		query.Start = start
+	}
+	if raw := params.Get("end"); raw != "" {
+		if query.Start.IsZero() {
+			return usecase.ListMetricsQuery{}, "Parameter end requires start"
+		}
+		end, err := valueobject.ParseTimestamp(raw)
+		if err != nil {
+			return usecase.ListMetricsQuery{}, "Invalid end: use RFC3339 or unix seconds"
+		}
+		if !query.Start.Before(end) {
+			return usecase.ListMetricsQuery{}, "Invalid time range: start must be before end"
+		}
+		query.End = end
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return usecase.ListMetricsQuery{}, "Invalid limit: must be a positive integer"
+		}
+		query.Limit = limit
+	}
+
+	// host и label=name=value добавляются к selector как условия равенства
+	selector, message := parseExportSelector(params)
+	if message != "" {
+		return usecase.ListMetricsQuery{}, message
+	}
+	for _, raw := range params["label"] {
+		name, value, ok := strings.Cut(raw, "=")
+		if !ok {
+			return usecase.ListMetricsQuery{}, "Invalid label: use name=value"
+		}
+		matcher, err := valueobject.NewLabelMatcher(name, valueobject.MatchEqual, value)
+		if err != nil {
+			return usecase.ListMetricsQuery{}, "Invalid label: use name=value"
+		}
+		selector = selector.With(matcher)
+	}
+	query.Selector = selector
+
+	return query, ""
+}
+
+// historyParams разобранные параметры запроса истории
+type historyParams struct {
+	metricType valueobject.MetricType
+	timeRange  valueobject.TimeRange
+	step       time.Duration // 0 - разрешение выбирается по длительности периода
+	host       string
+	selector   valueobject.LabelSelector
+}
+
+// selectorWithHost возвращает селектор с условием host="...", если host задан
+func (p historyParams) selectorWithHost() (valueobject.LabelSelector, error) {
+	if p.host == "" {
+		return p.selector, nil
+	}
+	hostMatcher, err := valueobject.NewLabelMatcher(valueobject.HostLabel, valueobject.MatchEqual, p.host)
+	if err != nil {
+		return valueobject.LabelSelector{}, err
+	}
+	return p.selector.With(hostMatcher), nil
+}
+
+// parseHistoryParams разбирает type, start, end, duration, step, host и selector из query string
+// Период задается start/end (RFC3339 или unix-секунды), либо duration относительно end (по умолчанию - сейчас)
+// Возвращает текст ошибки для ответа 400, если параметры некорректны
+func (h *MetricsAPIHandler) parseHistoryParams(r *http.Request) (historyParams, string) {
+	// Получаем параметры из query string
+	metricTypeStr := r.URL.Query().Get("type")
+	startStr := r.URL.Query().Get("start")
+	endStr := r.URL.Query().Get("end")
+	durationStr := r.URL.Query().Get("duration")
+	stepStr := r.URL.Query().Get("step")
+	host := r.URL.Query().Get("host")
+	selectorStr := r.URL.Query().Get("selector")
+
+	if metricTypeStr == "" || (durationStr == "" && startStr == "") {
+		return historyParams{}, "Missing required parameters: type and duration or start"
+	}
+	if startStr != "" && endStr != "" && durationStr != "" {
+		return historyParams{}, "Use either start and end or duration, not all three"
+	}
+
+	// Парсим metric type
+	metricType := valueobject.MetricType(metricTypeStr)
+	if err := metricType.Validate(); err != nil {
+		return historyParams{}, "Invalid metric type"
+	}
+
+	// Создаем time range
+	timeRange, errMsg := h.parseTimeRange(startStr, endStr, durationStr)
+	if errMsg != "" {
+		return historyParams{}, errMsg
+	}
+
+	// Парсим шаг бакетов
+	var step time.Duration
+	if stepStr != "" {
+		var err error
+		step, err = parseStep(stepStr)
+		if err != nil || step < time.Second {
+			return historyParams{}, "Invalid step: use a duration (30s, 5m) or seconds, at least 1s"
+		}
+		if timeRange.BucketCount(step) > maxHistoryPoints {
+			return historyParams{}, fmt.Sprintf("Step too small: at most %d points per series", maxHistoryPoints)
+		}
+	}
+
+	// Парсим селектор меток; host из query string добавляется как условие host="..."
+	selector, err := valueobject.ParseLabelSelector(selectorStr)
+	if err != nil {
+		return historyParams{}, "Invalid selector"
+	}
+	if host != "" && !selector.IsEmpty() {
+		hostMatcher, err := valueobject.NewLabelMatcher(valueobject.HostLabel, valueobject.MatchEqual, host)
+		if err != nil {
+			return historyParams{}, "Invalid host"
+		}
+		selector = selector.With(hostMatcher)
+	}
+
+	return historyParams{
+		metricType: metricType,
+		timeRange:  timeRange,
+		step:       step,
+		host:       host,
+		selector:   selector,
+	}, ""
+}
+
+// parseTimeRange строит период из start, end и duration
+// Возвращает текст ошибки для ответа 400, если параметры некорректны
+func (h *MetricsAPIHandler) parseTimeRange(startStr, endStr, durationStr string) (valueobject.TimeRange, string) {
+	end := time.Now()
+	if endStr != "" {
+		parsed, err := valueobject.ParseTimestamp(endStr)
+		if err != nil {
+			return valueobject.TimeRange{}, "Invalid end: use RFC3339 or unix seconds"
+		}
+		end = parsed
+	}
+
+	var start time.Time
+	if startStr != "" {
+		parsed, err := valueobject.ParseTimestamp(startStr)
+		if err != nil {
+			return valueobject.TimeRange{}, "Invalid start: use RFC3339 or unix seconds"
+		}
+		start = parsed
+	}
+
+	if durationStr != "" {
+		duration, err := time.ParseDuration(durationStr)
+		if err != nil {
+			return valueobject.TimeRange{}, "Invalid duration format"
+		}
+		if duration <= 0 {
+			return valueobject.TimeRange{}, "Duration out of allowed range"
+		}
+
+		// start + duration отсчитывается вперед, иначе - назад от end
+		if startStr != "" {
+			end = start.Add(duration)
+		} else {
+			start = end.Add(-duration)
+		}
+	}
+
+	if !start.Before(end) {
+		return valueobject.TimeRange{}, "Invalid time range: start must be before end"
+	}
+	if end.Sub(start) > h.maxDuration {
+		return valueobject.TimeRange{}, "Duration out of allowed range"
+	}
+
+	timeRange, err := valueobject.NewTimeRange(start, end)
+	if err != nil {
+		return valueobject.TimeRange{}, "Invalid time range"
+	}
+
+	return timeRange, ""
+}
+
+// parseStep разбирает шаг как длительность Go (30s, 5m) или количество секунд
+func parseStep(raw string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		// Отсекает NaN, Inf и переполнение Duration
+		if !(seconds > 0 && seconds < float64(math.MaxInt64)/float64(time.Second)) {
+			return 0, errors.New("step out of range")
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// writeJSON отправляет ответ в формате JSON
+func (h *MetricsAPIHandler) writeJSON(w http.ResponseWriter, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(history); err != nil {
-		h.logger.Error("Failed to encode historical metrics response", err)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		h.logger.Error("Failed to encode metrics response", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
+
+// GetMetricTypes возвращает зарегистрированные типы метрик с единицами и порогами
+func (h *MetricsAPIHandler) GetMetricTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.writeJSON(w, dto.ToMetricTypeDTOs(valueobject.DefaultMetricTypeRegistry().Definitions()))
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/entity"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/repository"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// maxDeliveriesLimit ограничивает размер страницы журнала доставки
+const maxDeliveriesLimit = 500
+
+// NotificationsAPIHandler обрабатывает API журнала доставки уведомлений
+type NotificationsAPIHandler struct {
+	dispatchNotificationsUC *usecase.DispatchNotificationsUseCase
+	logger                  *logger.Logger
+}
+
+// NewNotificationsAPIHandler создает новый handler
+func NewNotificationsAPIHandler(
+	dispatchNotificationsUC *usecase.DispatchNotificationsUseCase,
+	logger *logger.Logger,
+) *NotificationsAPIHandler {
+	return &NotificationsAPIHandler{
+		dispatchNotificationsUC: dispatchNotificationsUC,
+		logger:                  logger,
+	}
+}
+
+// ListDeliveries обрабатывает GET /api/v1/notifications/deliveries?channel=&status=&limit=
+func (h *NotificationsAPIHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := repository.NotificationDeliveryFilter{
+		Channel: strings.TrimSpace(query.Get("channel")),
+		Status:  entity.DeliveryStatus(strings.TrimSpace(query.Get("status"))),
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxDeliveriesLimit {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	deliveries, err := h.dispatchNotificationsUC.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid delivery filter") {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("Failed to list notification deliveries", err)
+		http.Error(w, "Failed to list notification deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// ProcessesAPIHandler обрабатывает API снимков процессов
+type ProcessesAPIHandler struct {
+	collectProcessesUC *usecase.CollectProcessesUseCase
+	logger             *logger.Logger
+}
+
+// NewProcessesAPIHandler создает новый handler
+func NewProcessesAPIHandler(
+	collectProcessesUC *usecase.CollectProcessesUseCase,
+	logger *logger.Logger,
+) *ProcessesAPIHandler {
+	return &ProcessesAPIHandler{
+		collectProcessesUC: collectProcessesUC,
+		logger:             logger,
+	}
+}
+
+// TopProcesses обрабатывает GET /api/v1/processes?limit=
+func (h *ProcessesAPIHandler) TopProcesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var limit int
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	processes, err := h.collectProcessesUC.Top(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to get top processes", err)
+		http.Error(w, "Failed to get top processes", http.StatusInternalServerError)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, processes)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/dto"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/promql"
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// QueryAPIHandler обрабатывает API языка запросов (/api/v1/query, /api/v1/query_range)
+// Ответы совместимы с Prometheus HTTP API, поэтому источник можно подключить к Grafana
+type QueryAPIHandler struct {
+	queryMetricsUC *usecase.QueryMetricsUseCase
+	maxDuration    time.Duration
+	logger         *logger.Logger
+}
+
+// NewQueryAPIHandler создает новый handler
+// maxDuration ограничивает длину диапазона query_range
+func NewQueryAPIHandler(
+	queryMetricsUC *usecase.QueryMetricsUseCase,
+	maxDuration time.Duration,
+	logger *logger.Logger,
+) *QueryAPIHandler {
+	if maxDuration <= 0 {
+		maxDuration = 24 * time.Hour
+	}
+
+	return &QueryAPIHandler{
+		queryMetricsUC: queryMetricsUC,
+		maxDuration:    maxDuration,
+		logger:         logger,
+	}
+}
+
+// Query обрабатывает GET|POST /api/v1/query?query=...&time=...
+func (h *QueryAPIHandler) Query(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.FormValue("query")
+	if query == "" {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "query is required")
+		return
+	}
+
+	var ts time.Time
+	if raw := r.FormValue("time"); raw != "" {
+		parsed, err := valueobject.ParseTimestamp(raw)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "bad_data", "invalid time: use RFC3339 or unix seconds")
+			return
+		}
+		ts = parsed
+	}
+
+	data, err := h.queryMetricsUC.Instant(r.Context(), query, ts)
+	if err != nil {
+		h.handleQueryError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, dto.QueryResponseDTO{Status: "success", Data: data})
+}
+
+// QueryRange обрабатывает GET|POST /api/v1/query_range?query=...&start=...&end=...&step=...
+func (h *QueryAPIHandler) QueryRange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.FormValue("query")
+	if query == "" {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "query is required")
+		return
+	}
+
+	start, err := valueobject.ParseTimestamp(r.FormValue("start"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "invalid start: use RFC3339 or unix seconds")
+		return
+	}
+	end, err := valueobject.ParseTimestamp(r.FormValue("end"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "invalid end: use RFC3339 or unix seconds")
+		return
+	}
+	if end.Before(start) {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "end must not be before start")
+		return
+	}
+	if end.Sub(start) > h.maxDuration {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "range out of allowed duration")
+		return
+	}
+
+	step, err := parseStep(r.FormValue("step"))
+	if err != nil || step <= 0 {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "invalid step: use a duration (30s) or seconds")
+		return
+	}
+	if int64(end.Sub(start)/step)+1 > maxHistoryPoints {
+		h.writeError(w, http.StatusBadRequest, "bad_data", "step too small: exceeded maximum resolution of 11000 points per series")
+		return
+	}
+
+	data, err := h.queryMetricsUC.Range(r.Context(), query, start, end, step)
+	if err != nil {
+		h.handleQueryError(w, err)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, dto.QueryResponseDTO{Status: "success", Data: data})
+}
+
+// handleQueryError сопоставляет ошибку вычисления с кодом ответа
+func (h *QueryAPIHandler) handleQueryError(w http.ResponseWriter, err error) {
+	var parseErr *promql.ParseError
+	var evalErr *promql.EvalError
+
+	switch {
+	case errors.As(err, &parseErr):
+		h.writeError(w, http.StatusBadRequest, "bad_data", err.Error())
+	case errors.Is(err, promql.ErrTooManySamples), errors.As(err, &evalErr):
+		h.writeError(w, http.StatusUnprocessableEntity, "execution", err.Error())
+	default:
+		h.logger.Error("Failed to evaluate query", err)
+		h.writeError(w, http.StatusInternalServerError, "internal", "failed to evaluate query")
+	}
+}
+
+func (h *QueryAPIHandler) writeError(w http.ResponseWriter, status int, errorType, message string) {
+	middleware.WriteJSON(w, status, dto.QueryResponseDTO{
+		Status:    "error",
+		ErrorType: errorType,
+		Error:     message,
+	})
+}
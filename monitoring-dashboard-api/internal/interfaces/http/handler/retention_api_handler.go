@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/application/usecase"
+	"github.com/dreschagin/monitoring-dashboard/internal/interfaces/http/middleware"
+	"github.com/dreschagin/monitoring-dashboard/pkg/logger"
+)
+
+// RetentionAPIHandler обрабатывает административный API очистки метрик
+type RetentionAPIHandler struct {
+	enforceRetentionUC *usecase.EnforceRetentionUseCase
+	defaultDryRun      bool
+	logger             *logger.Logger
+}
+
+// NewRetentionAPIHandler создает новый handler
+// defaultDryRun используется, если запрос не передает параметр dry_run
+func NewRetentionAPIHandler(
+	enforceRetentionUC *usecase.EnforceRetentionUseCase,
+	defaultDryRun bool,
+	logger *logger.Logger,
+) *RetentionAPIHandler {
+	return &RetentionAPIHandler{
+		enforceRetentionUC: enforceRetentionUC,
+		defaultDryRun:      defaultDryRun,
+		logger:             logger,
+	}
+}
+
+// GetStats обрабатывает GET /api/v1/admin/retention
+func (h *RetentionAPIHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, h.enforceRetentionUC.Stats())
+}
+
+// RunNow обрабатывает POST /api/v1/admin/retention/run[?dry_run=true]
+func (h *RetentionAPIHandler) RunNow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dryRun := h.defaultDryRun
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+			return
+		}
+		dryRun = parsed
+	}
+
+	run, err := h.enforceRetentionUC.Execute(r.Context(), dryRun)
+	if errors.Is(err, usecase.ErrRetentionInProgress) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		// Часть пачек могла быть удалена: возвращаем результат вместе с ошибкой
+		h.logger.Error("Retention run failed", err)
+		middleware.WriteJSON(w, http.StatusInternalServerError, run)
+		return
+	}
+
+	middleware.WriteJSON(w, http.StatusOK, run)
+}
@@ -95,7 +95,8 @@ func (h *WebSocketHandler) HandleConnection(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	client := wsInfra.NewClient(h.hub, conn, h.logger)
+	// Клиент может подписаться на метрики одного хоста: /ws?host=<host>
+	client := wsInfra.NewClient(h.hub, conn, r.URL.Query().Get("host"), h.logger)
 	h.hub.Register(client)
 
 	// Запускаем pumps в отдельных goroutines
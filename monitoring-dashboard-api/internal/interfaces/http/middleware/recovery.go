@@ -13,6 +13,10 @@ func Recovery(log *logger.Logger) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
+					// Handler намеренно обрывает уже начатый ответ: net/http закроет соединение сам
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
 					log.Error("Panic recovered", fmt.Errorf("%v", err),
 						"path", r.URL.Path,
 						"method", r.Method,
@@ -19,6 +19,16 @@ type Router struct {
 	screenshotAPIHandler      *handler.ScreenshotAPIHandler
 	authAPIHandler            *handler.AuthAPIHandler
 	releaseAnalyzerAPIHandler *handler.ReleaseAnalyzerAPIHandler
+	ingestAPIHandler          *handler.IngestAPIHandler
+	alertRulesAPIHandler      *handler.AlertRulesAPIHandler
+	incidentsAPIHandler       *handler.IncidentsAPIHandler
+	notificationsAPIHandler   *handler.NotificationsAPIHandler
+	retentionAPIHandler       *handler.RetentionAPIHandler
+	queryAPIHandler           *handler.QueryAPIHandler
+	exportAPIHandler          *handler.ExportAPIHandler
+	importAPIHandler          *handler.ImportAPIHandler
+	processesAPIHandler       *handler.ProcessesAPIHandler
+	serviceMetricsHandler     http.Handler
 	security                  config.SecurityConfig
 	logger                    *logger.Logger
 }
@@ -31,6 +41,16 @@ func NewRouter(
 	screenshotAPIHandler *handler.ScreenshotAPIHandler,
 	authAPIHandler *handler.AuthAPIHandler,
 	releaseAnalyzerAPIHandler *handler.ReleaseAnalyzerAPIHandler,
+	ingestAPIHandler *handler.IngestAPIHandler, // Can be nil if ingest disabled
+	alertRulesAPIHandler *handler.AlertRulesAPIHandler, // Can be nil if alerting disabled
+	incidentsAPIHandler *handler.IncidentsAPIHandler, // Can be nil if incidents disabled
+	notificationsAPIHandler *handler.NotificationsAPIHandler, // Can be nil if notification channels disabled
+	retentionAPIHandler *handler.RetentionAPIHandler, // Can be nil if retention disabled
+	queryAPIHandler *handler.QueryAPIHandler,
+	exportAPIHandler *handler.ExportAPIHandler,
+	importAPIHandler *handler.ImportAPIHandler,
+	processesAPIHandler *handler.ProcessesAPIHandler, // Can be nil if process collection disabled
+	serviceMetricsHandler http.Handler, // Can be nil if /metrics disabled
 	security config.SecurityConfig,
 	logger *logger.Logger,
 ) *Router {
@@ -42,6 +62,16 @@ func NewRouter(
 		screenshotAPIHandler:      screenshotAPIHandler,
 		authAPIHandler:            authAPIHandler,
 		releaseAnalyzerAPIHandler: releaseAnalyzerAPIHandler,
+		ingestAPIHandler:          ingestAPIHandler,
+		alertRulesAPIHandler:      alertRulesAPIHandler,
+		incidentsAPIHandler:       incidentsAPIHandler,
+		notificationsAPIHandler:   notificationsAPIHandler,
+		retentionAPIHandler:       retentionAPIHandler,
+		queryAPIHandler:           queryAPIHandler,
+		exportAPIHandler:          exportAPIHandler,
+		importAPIHandler:          importAPIHandler,
+		processesAPIHandler:       processesAPIHandler,
+		serviceMetricsHandler:     serviceMetricsHandler,
 		security:                  security,
 		logger:                    logger,
 	}
@@ -66,6 +96,11 @@ func (rt *Router) Setup() http.Handler {
 		_, _ = w.Write([]byte("ready"))
 	})
 
+	// Prometheus scrape endpoint is unauthenticated like the probes.
+	if rt.serviceMetricsHandler != nil {
+		rt.mux.Handle("/metrics", rt.serviceMetricsHandler)
+	}
+
 	authMiddleware := middleware.Auth(middleware.AuthConfig{
 		Enabled:     rt.security.AuthEnabled,
 		BearerToken: rt.security.AuthToken,
@@ -82,12 +117,47 @@ func (rt *Router) Setup() http.Handler {
 	rt.mux.HandleFunc("/api/v1/auth/logout", rt.authAPIHandler.Logout)
 	rt.mux.HandleFunc("/api/v1/auth/status", rt.authAPIHandler.Status)
 
+	rt.mux.Handle("/api/v1/metrics", authMiddleware(http.HandlerFunc(rt.metricsAPIHandler.ListMetrics)))
 	rt.mux.Handle("/api/v1/metrics/history", authMiddleware(http.HandlerFunc(rt.metricsAPIHandler.GetHistoricalMetrics)))
 	rt.mux.Handle("/api/metrics/history", authMiddleware(http.HandlerFunc(rt.metricsAPIHandler.GetHistoricalMetrics)))
+	rt.mux.Handle("/api/v1/metrics/series", authMiddleware(http.HandlerFunc(rt.metricsAPIHandler.GetMetricSeries)))
+	rt.mux.Handle("/api/v1/metrics/types", authMiddleware(http.HandlerFunc(rt.metricsAPIHandler.GetMetricTypes)))
+	rt.mux.Handle("/api/v1/query", authMiddleware(http.HandlerFunc(rt.queryAPIHandler.Query)))
+	rt.mux.Handle("/api/v1/query_range", authMiddleware(http.HandlerFunc(rt.queryAPIHandler.QueryRange)))
+	rt.mux.Handle("/api/v1/metrics/export", authMiddleware(middleware.Compression(http.HandlerFunc(rt.exportAPIHandler.History))))
+	rt.mux.Handle("/api/v1/metrics/import", authMiddleware(http.HandlerFunc(rt.importAPIHandler.Import)))
+	rt.mux.Handle("/api/v1/export/prometheus", authMiddleware(http.HandlerFunc(rt.exportAPIHandler.Prometheus)))
 	rt.mux.Handle("/api/v1/screenshots/dashboard", authMiddleware(http.HandlerFunc(rt.screenshotAPIHandler.HandleDashboardScreenshots)))
 	rt.mux.Handle("/api/v1/release-analyzer/summary", authMiddleware(http.HandlerFunc(rt.releaseAnalyzerAPIHandler.GetSummary)))
 	rt.mux.Handle("/api/v1/release-analyzer/run", authMiddleware(http.HandlerFunc(rt.releaseAnalyzerAPIHandler.RunNow)))
 
+	if rt.alertRulesAPIHandler != nil {
+		rt.mux.Handle("/api/v1/alerts/rules", authMiddleware(http.HandlerFunc(rt.alertRulesAPIHandler.HandleRules)))
+		rt.mux.Handle("/api/v1/alerts/rules/", authMiddleware(http.HandlerFunc(rt.alertRulesAPIHandler.HandleRule)))
+	}
+	if rt.incidentsAPIHandler != nil {
+		rt.mux.Handle("/api/v1/incidents", authMiddleware(http.HandlerFunc(rt.incidentsAPIHandler.HandleIncidents)))
+		rt.mux.Handle("/api/v1/incidents/", authMiddleware(http.HandlerFunc(rt.incidentsAPIHandler.HandleIncident)))
+	}
+	if rt.notificationsAPIHandler != nil {
+		rt.mux.Handle("/api/v1/notifications/deliveries", authMiddleware(http.HandlerFunc(rt.notificationsAPIHandler.ListDeliveries)))
+	}
+	if rt.processesAPIHandler != nil {
+		rt.mux.Handle("/api/v1/processes", authMiddleware(http.HandlerFunc(rt.processesAPIHandler.TopProcesses)))
+	}
+	if rt.retentionAPIHandler != nil {
+		rt.mux.Handle("/api/v1/admin/retention", authMiddleware(http.HandlerFunc(rt.retentionAPIHandler.GetStats)))
+		rt.mux.Handle("/api/v1/admin/retention/run", authMiddleware(http.HandlerFunc(rt.retentionAPIHandler.RunNow)))
+	}
+
+	// Ingest endpoint authenticates agents with its own token (INGEST_AUTH_TOKEN)
+	if rt.ingestAPIHandler != nil {
+		rt.mux.HandleFunc("/api/v1/ingest/metrics", rt.ingestAPIHandler.IngestMetrics)
+		rt.mux.HandleFunc("/api/v1/ingest/remote_write", rt.ingestAPIHandler.RemoteWrite)
+		// OTLP-экспортеры дописывают /v1/metrics к OTEL_EXPORTER_OTLP_ENDPOINT
+		rt.mux.HandleFunc("/api/v1/ingest/otlp/v1/metrics", rt.ingestAPIHandler.OTLPMetrics)
+	}
+
 	// Применяем middleware
 	var handler http.Handler = rt.mux
 	handler = middleware.Logger(rt.logger)(handler)
@@ -5,6 +5,8 @@ class MetricsWebSocket {
         this.maxReconnectDelay = 30000;
         this.charts = {};
         this.screenshotsCaptured = false;
+        this.processes = null;
+        this.processSort = 'cpu';
         this.authToken = this.loadAuthToken();
         this.init();
     }
@@ -28,6 +30,7 @@ class MetricsWebSocket {
             .finally(() => {
                 this.connect();
                 this.initCharts();
+                this.initProcessesTable();
                 return this.loadHistoricalData();
             })
             .finally(() => {
@@ -82,6 +85,10 @@ class MetricsWebSocket {
                 this.handleSnapshot(message.data);
             } else if (message.type === 'alert') {
                 this.handleAlert(message.data);
+            } else if (message.type === 'incident') {
+                this.handleIncident(message.data);
+            } else if (message.type === 'processes') {
+                this.handleProcesses(message.data);
             }
         };
 
@@ -137,6 +144,82 @@ class MetricsWebSocket {
         console.warn('Alert received:', alert);
     }
 
+    handleIncident(incident) {
+        console.info('Incident updated:', incident);
+    }
+
+    initProcessesTable() {
+        const buttons = {
+            cpu: document.getElementById('processes-sort-cpu'),
+            memory: document.getElementById('processes-sort-memory')
+        };
+
+        Object.entries(buttons).forEach(([sort, button]) => {
+            if (!button) return;
+            button.addEventListener('click', () => {
+                this.processSort = sort;
+                Object.values(buttons).forEach(b => b && b.classList.toggle('active', b === button));
+                this.renderProcesses();
+            });
+        });
+
+        this.fetchWithAuth('/api/v1/processes')
+            .then(response => (response.ok ? response.json() : null))
+            .then(processes => {
+                if (processes && !this.processes) this.handleProcesses(processes);
+            })
+            .catch(err => console.warn('Failed to load processes:', err));
+    }
+
+    handleProcesses(processes) {
+        this.processes = processes;
+        this.renderProcesses();
+    }
+
+    renderProcesses() {
+        const body = document.getElementById('processes-body');
+        if (!body || !this.processes) return;
+
+        const rows = (this.processSort === 'memory' ? this.processes.by_memory : this.processes.by_cpu) || [];
+        body.replaceChildren(...rows.map(process => {
+            const row = document.createElement('tr');
+            const cells = [
+                process.pid,
+                process.name,
+                process.user || '-',
+                process.cpu_percent.toFixed(1),
+                this.formatBytes(process.rss_bytes),
+                process.cmdline || ''
+            ];
+            cells.forEach((value, index) => {
+                const cell = document.createElement('td');
+                cell.textContent = value;
+                if (index === cells.length - 1) {
+                    cell.className = 'process-cmdline';
+                    cell.title = value;
+                }
+                row.appendChild(cell);
+            });
+            return row;
+        }));
+
+        const totalEl = document.getElementById('processes-total');
+        if (totalEl) totalEl.textContent = this.processes.total_processes;
+        const updatedEl = document.getElementById('processes-updated-at');
+        if (updatedEl) updatedEl.textContent = new Date(this.processes.collected_at).toLocaleTimeString();
+    }
+
+    formatBytes(bytes) {
+        const units = ['B', 'KB', 'MB', 'GB', 'TB'];
+        let value = bytes;
+        let unit = 0;
+        while (value >= 1024 && unit < units.length - 1) {
+            value /= 1024;
+            unit++;
+        }
+        return `${value.toFixed(unit === 0 ? 0 : 1)} ${units[unit]}`;
+    }
+
     updateConnectionStatus(connected) {
         const statusEl = document.getElementById('connection-status');
         if (statusEl) {
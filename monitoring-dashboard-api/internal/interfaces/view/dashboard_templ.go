@@ -72,7 +72,7 @@ func Dashboard(snapshot *dto.MetricSnapshotDTO) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</div><div class=\"status-indicator\"><span id=\"connection-status\" class=\"status connected\">● Connected</span> <span id=\"client-count\">Clients: <span id=\"client-count-value\">-</span></span></div><div class=\"release-analyzer-panel\"><div class=\"release-analyzer-header\"><h3>Release Analyzer</h3><button id=\"ra-run-btn\" class=\"action-btn\" type=\"button\">Run now</button></div><div class=\"release-analyzer-summary\"><div>State: <span id=\"ra-state\" class=\"ra-state unknown\">Unknown</span></div><div>Last run: <span id=\"ra-last-run\">-</span></div><div>Total metrics: <span id=\"ra-metrics-total\">-</span></div><div>Warnings: <span id=\"ra-warning-count\">-</span></div><div>Critical: <span id=\"ra-critical-count\">-</span></div><div>Oldest metric age: <span id=\"ra-oldest-age\">-</span></div></div><div id=\"ra-last-error\" class=\"ra-last-error hidden\"></div><div class=\"release-analyzer-table-wrapper\"><table class=\"release-analyzer-table\"><thead><tr><th>Metric</th><th>Value</th><th>Unit</th><th>Severity</th><th>Collected At</th></tr></thead> <tbody id=\"ra-assessments-body\"><tr><td colspan=\"5\">No data yet</td></tr></tbody></table></div><div class=\"release-analyzer-meta\">Updated at: <span id=\"ra-updated-at\">-</span></div></div><div class=\"screenshot-gallery-panel\"><div class=\"screenshot-gallery-header\"><h3>Dashboard Screenshots</h3><div class=\"screenshot-gallery-actions\"><button id=\"screenshots-refresh-btn\" class=\"action-btn\" type=\"button\">Refresh list</button><div class=\"screenshot-gallery-pagination\"><button id=\"screenshots-prev-btn\" class=\"action-btn screenshot-page-btn\" type=\"button\" disabled>Prev</button> <span id=\"screenshots-page-label\" class=\"screenshot-page-label\">Page 1</span> <button id=\"screenshots-next-btn\" class=\"action-btn screenshot-page-btn\" type=\"button\" disabled>Next</button></div></div></div><div class=\"screenshot-gallery-meta\">Updated at: <span id=\"screenshots-updated-at\">-</span></div><div id=\"screenshots-grid\" class=\"screenshot-gallery-grid\"><div class=\"screenshot-gallery-empty\">No screenshots yet</div></div></div><div class=\"charts-container\"><div class=\"chart-wrapper\"><h3>CPU History (1 Hour)</h3><canvas id=\"cpuChart\"></canvas></div><div class=\"chart-wrapper\"><h3>Memory History (1 Hour)</h3><canvas id=\"memoryChart\"></canvas></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</div><div class=\"status-indicator\"><span id=\"connection-status\" class=\"status connected\">● Connected</span> <span id=\"client-count\">Clients: <span id=\"client-count-value\">-</span></span></div><div class=\"processes-panel\"><div class=\"processes-header\"><h3>Top Processes</h3><div class=\"processes-actions\"><button id=\"processes-sort-cpu\" class=\"action-btn active\" type=\"button\">By CPU</button> <button id=\"processes-sort-memory\" class=\"action-btn\" type=\"button\">By memory</button></div></div><div class=\"processes-table-wrapper\"><table class=\"processes-table\"><thead><tr><th>PID</th><th>Name</th><th>User</th><th>CPU %</th><th>RSS</th><th>Command</th></tr></thead> <tbody id=\"processes-body\"><tr><td colspan=\"6\">No data yet</td></tr></tbody></table></div><div class=\"processes-meta\">Processes: <span id=\"processes-total\">-</span> · Updated at: <span id=\"processes-updated-at\">-</span></div></div><div class=\"release-analyzer-panel\"><div class=\"release-analyzer-header\"><h3>Release Analyzer</h3><button id=\"ra-run-btn\" class=\"action-btn\" type=\"button\">Run now</button></div><div class=\"release-analyzer-summary\"><div>State: <span id=\"ra-state\" class=\"ra-state unknown\">Unknown</span></div><div>Last run: <span id=\"ra-last-run\">-</span></div><div>Total metrics: <span id=\"ra-metrics-total\">-</span></div><div>Warnings: <span id=\"ra-warning-count\">-</span></div><div>Critical: <span id=\"ra-critical-count\">-</span></div><div>Oldest metric age: <span id=\"ra-oldest-age\">-</span></div></div><div id=\"ra-last-error\" class=\"ra-last-error hidden\"></div><div class=\"release-analyzer-table-wrapper\"><table class=\"release-analyzer-table\"><thead><tr><th>Metric</th><th>Value</th><th>Unit</th><th>Severity</th><th>Collected At</th></tr></thead> <tbody id=\"ra-assessments-body\"><tr><td colspan=\"5\">No data yet</td></tr></tbody></table></div><div class=\"release-analyzer-meta\">Updated at: <span id=\"ra-updated-at\">-</span></div></div><div class=\"screenshot-gallery-panel\"><div class=\"screenshot-gallery-header\"><h3>Dashboard Screenshots</h3><div class=\"screenshot-gallery-actions\"><button id=\"screenshots-refresh-btn\" class=\"action-btn\" type=\"button\">Refresh list</button><div class=\"screenshot-gallery-pagination\"><button id=\"screenshots-prev-btn\" class=\"action-btn screenshot-page-btn\" type=\"button\" disabled>Prev</button> <span id=\"screenshots-page-label\" class=\"screenshot-page-label\">Page 1</span> <button id=\"screenshots-next-btn\" class=\"action-btn screenshot-page-btn\" type=\"button\" disabled>Next</button></div></div></div><div class=\"screenshot-gallery-meta\">Updated at: <span id=\"screenshots-updated-at\">-</span></div><div id=\"screenshots-grid\" class=\"screenshot-gallery-grid\"><div class=\"screenshot-gallery-empty\">No screenshots yet</div></div></div><div class=\"charts-container\"><div class=\"chart-wrapper\"><h3>CPU History (1 Hour)</h3><canvas id=\"cpuChart\"></canvas></div><div class=\"chart-wrapper\"><h3>Memory History (1 Hour)</h3><canvas id=\"memoryChart\"></canvas></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 	"time"
+
+	"github.com/dreschagin/monitoring-dashboard/internal/domain/valueobject"
 )
 
 const latestMetricsQuery = `
@@ -70,24 +72,15 @@ func (s *Service) EvaluateLatest(ctx context.Context) (*CycleSummary, error) {
 }
 
 func severityFor(metricType string, value float64, unit string) Severity {
-	switch metricType {
-	case "cpu", "memory", "disk":
-		if unit == "%" && value > 90.0 {
-			return SeverityCritical
-		}
-		if unit == "%" && value > 75.0 {
-			return SeverityWarning
-		}
-		return SeverityOK
-	case "network":
-		if unit == "MB/s" && value > 100.0 {
-			return SeverityCritical
-		}
-		if unit == "MB/s" && value > 50.0 {
-			return SeverityWarning
-		}
-		return SeverityOK
-	default:
+	definition, ok := valueobject.MetricType(metricType).Definition()
+	if !ok {
 		return SeverityOK
 	}
+	if definition.IsCritical(value, unit) {
+		return SeverityCritical
+	}
+	if definition.IsWarning(value, unit) {
+		return SeverityWarning
+	}
+	return SeverityOK
 }
@@ -22,6 +22,9 @@ type Config struct {
 	ReleaseAnalyzer ReleaseAnalyzerConfig
 	CloudWatch      CloudWatchConfig
 	NATS            NATSConfig
+	Ingest          IngestConfig
+	StatsD          StatsDConfig
+	Notifications   NotificationsConfig
 }
 
 type ServerConfig struct {
@@ -61,8 +64,47 @@ type RedisConfig struct {
 }
 
 type MetricsConfig struct {
-	CollectionInterval time.Duration
-	RetentionDays      int
+	CollectionInterval  time.Duration
+	RetentionDays       int            // 0 keeps metrics forever
+	RetentionOverrides  map[string]int // Per-type retention in days, e.g. "cpu=3,disk=30"
+	RetentionInterval   time.Duration
+	RetentionBatchSize  int
+	RetentionBatchPause time.Duration
+	RetentionDryRun     bool
+	RollupsEnabled      bool // Build 1m/5m/1h rollups and use them for long history ranges
+	RollupInterval      time.Duration
+	RollupLag           time.Duration  // Delay before a closed bucket is rolled up (late agent pushes)
+	RollupRetention     map[string]int // Per-tier retention in days, e.g. "1m=2,5m=14,1h=90"
+	HistoryMaxDuration  time.Duration  // Longest range accepted by the history API
+	PartitioningEnabled bool           // Manage collected_at partitions of the metrics table
+	PartitionInterval   time.Duration  // 24h (daily) or 168h (weekly)
+	PartitionPremake    int            // Partitions created ahead of the current one
+	PartitionCheck      time.Duration  // How often partitions are created and dropped
+	WriteBufferEnabled  bool           // Buffer incoming metrics in memory and write them in batches
+	WriteBufferSize     int            // Buffered metrics that trigger an early flush
+	WriteBufferInterval time.Duration  // Longest time a metric waits in the buffer
+	WriteBufferMax      int            // Buffered metrics after which writers block (backpressure)
+	WriteBufferTimeout  time.Duration  // How long a blocked writer waits before the ingest is rejected
+	QueryLookback       time.Duration  // How far back an instant selector looks for the latest sample
+	QueryMaxSamples     int            // Samples one query may load before it is rejected
+	EndpointEnabled     bool           // Expose the service's own metrics on /metrics (Prometheus format)
+	ExportLabels        []string       // Metadata keys exported as labels by /api/v1/export/prometheus
+	ExportMaxRange      time.Duration  // Longest range accepted by the history export (/api/v1/metrics/export)
+	ImportMaxBytes      int64          // Largest request body accepted by the history import (/api/v1/metrics/import)
+	DiskFSTypes         []string       // Filesystem types the disk collector reports (empty - all but excluded)
+	DiskExcludeFSTypes  []string       // Filesystem types skipped by the disk collector (empty - built-in pseudo filesystems)
+	DiskExcludeMounts   []string       // Mount points skipped with everything mounted under them (empty - /proc, /sys, /run ...)
+	DiskExcludeDevices  []string       // Block device patterns without I/O metrics (empty - loop*, ram* ...)
+	NetworkInterfaces   []string       // Interface patterns the network collector reports (empty - all but excluded)
+	NetworkExclude      []string       // Interface patterns skipped by the network collector (empty - lo, veth*, docker* ...)
+	ProcessesEnabled    bool           // Sample local processes every cycle for the top table and /api/v1/processes
+	ProcessesTopN       int            // Processes per list (by CPU, by memory) broadcast over WebSocket
+	ProcessesCmdline    bool           // Include command lines in process snapshots (arguments may contain secrets)
+	CgroupEnabled       bool           // Report usage of the service's cgroup v2 relative to its limits (container, pod)
+	CgroupRoot          string         // cgroup2 mount point
+	CgroupPath          string         // cgroup path relative to CgroupRoot (empty - own cgroup from /proc/self/cgroup)
+	Host                string         // Host identity for locally collected metrics
+	TypesFile           string         // JSON file with additional metric type definitions
 }
 
 type S3Config struct {
@@ -137,6 +179,37 @@ type NATSConfig struct {
 	URL     string
 }
 
+// IngestConfig настраивает прием метрик от удаленных агентов
+type IngestConfig struct {
+	Enabled                bool
+	AuthToken              string
+	MaxPayloadBytes        int64
+	RemoteWriteDefaultType string   // Type for remote_write series whose type can't be inferred; empty rejects them
+	OTLPDefaultType        string   // Type for OTLP metrics whose type can't be inferred; empty rejects them
+	OTLPResourceAttributes []string // OTLP resource attributes copied into metric labels; "*" copies all
+}
+
+// StatsDConfig настраивает UDP-приемник метрик StatsD/DogStatsD
+type StatsDConfig struct {
+	Enabled       bool
+	Address       string        // UDP address to listen on
+	FlushInterval time.Duration // Aggregation window; aggregates are ingested at the end of each window
+	MaxSeries     int           // Distinct series kept per window; new series beyond it are dropped
+	DefaultType   string        // Type for metrics whose type can't be inferred; empty rejects them
+	DefaultHost   string        // Host for metrics without a host tag
+}
+
+// NotificationsConfig настраивает доставку алертов во внешние каналы
+type NotificationsConfig struct {
+	ChannelsFile string // JSON file with notification channel definitions; empty disables delivery
+	Workers      int
+	QueueSize    int
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+	SendTimeout  time.Duration
+}
+
 func Load() (*Config, error) {
 	// Загружаем .env файл (игнорируем ошибку если файла нет)
 	_ = godotenv.Load()
@@ -151,6 +224,114 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid METRICS_RETENTION_DAYS: %w", err)
 	}
 
+	retentionOverrides, err := parseRetentionOverrides(getEnv("METRICS_RETENTION_OVERRIDES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_RETENTION_OVERRIDES: %w", err)
+	}
+
+	retentionInterval, err := parseDuration(getEnv("METRICS_RETENTION_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_RETENTION_INTERVAL: %w", err)
+	}
+
+	retentionBatchSize, err := strconv.Atoi(getEnv("METRICS_RETENTION_BATCH_SIZE", "5000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_RETENTION_BATCH_SIZE: %w", err)
+	}
+
+	retentionBatchPause, err := parseDuration(getEnv("METRICS_RETENTION_BATCH_PAUSE", "100ms"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_RETENTION_BATCH_PAUSE: %w", err)
+	}
+
+	rollupInterval, err := parseDuration(getEnv("METRICS_ROLLUP_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_ROLLUP_INTERVAL: %w", err)
+	}
+
+	rollupLag, err := parseDuration(getEnv("METRICS_ROLLUP_LAG", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_ROLLUP_LAG: %w", err)
+	}
+
+	rollupRetention, err := parseRetentionOverrides(getEnv("METRICS_ROLLUP_RETENTION", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_ROLLUP_RETENTION: %w", err)
+	}
+	for tier, days := range defaultRollupRetention {
+		if _, ok := rollupRetention[tier]; !ok {
+			rollupRetention[tier] = days
+		}
+	}
+
+	historyMaxDuration, err := parseDuration(getEnv("METRICS_HISTORY_MAX_DURATION", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_HISTORY_MAX_DURATION: %w", err)
+	}
+
+	exportMaxRange, err := parseDuration(getEnv("METRICS_EXPORT_MAX_RANGE", "744h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_EXPORT_MAX_RANGE: %w", err)
+	}
+
+	importMaxMB, err := strconv.Atoi(getEnv("METRICS_IMPORT_MAX_PAYLOAD_MB", "256"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_IMPORT_MAX_PAYLOAD_MB: %w", err)
+	}
+
+	partitionInterval, err := parseDuration(getEnv("METRICS_PARTITION_INTERVAL", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_PARTITION_INTERVAL: %w", err)
+	}
+	if partitionInterval != 24*time.Hour && partitionInterval != 7*24*time.Hour {
+		return nil, fmt.Errorf("invalid METRICS_PARTITION_INTERVAL: must be 24h or 168h")
+	}
+
+	partitionPremake, err := strconv.Atoi(getEnv("METRICS_PARTITION_PREMAKE", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_PARTITION_PREMAKE: %w", err)
+	}
+
+	partitionCheck, err := parseDuration(getEnv("METRICS_PARTITION_CHECK_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_PARTITION_CHECK_INTERVAL: %w", err)
+	}
+
+	writeBufferFlushSize, err := strconv.Atoi(getEnv("METRICS_WRITE_BUFFER_FLUSH_SIZE", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_WRITE_BUFFER_FLUSH_SIZE: %w", err)
+	}
+
+	writeBufferFlushInterval, err := parseDuration(getEnv("METRICS_WRITE_BUFFER_FLUSH_INTERVAL", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_WRITE_BUFFER_FLUSH_INTERVAL: %w", err)
+	}
+
+	writeBufferMaxPending, err := strconv.Atoi(getEnv("METRICS_WRITE_BUFFER_MAX_PENDING", "50000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_WRITE_BUFFER_MAX_PENDING: %w", err)
+	}
+
+	writeBufferEnqueueTimeout, err := parseDuration(getEnv("METRICS_WRITE_BUFFER_ENQUEUE_TIMEOUT", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_WRITE_BUFFER_ENQUEUE_TIMEOUT: %w", err)
+	}
+
+	queryLookback, err := parseDuration(getEnv("METRICS_QUERY_LOOKBACK", "5m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_QUERY_LOOKBACK: %w", err)
+	}
+
+	queryMaxSamples, err := strconv.Atoi(getEnv("METRICS_QUERY_MAX_SAMPLES", "50000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRICS_QUERY_MAX_SAMPLES: %w", err)
+	}
+
+	processesTopN, err := strconv.Atoi(getEnv("METRICS_PROCESSES_TOP", "10"))
+	if err != nil || processesTopN <= 0 {
+		return nil, fmt.Errorf("invalid METRICS_PROCESSES_TOP: must be a positive integer")
+	}
+
 	presignedTTL, err := parseDuration(getEnv("S3_PRESIGNED_TTL", "5m"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid S3_PRESIGNED_TTL: %w", err)
@@ -207,6 +388,51 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid CLOUDWATCH_METRICS_STORAGE_RESOLUTION: %w", err)
 	}
 
+	ingestMaxPayloadKB, err := strconv.Atoi(getEnv("INGEST_MAX_PAYLOAD_KB", "1024"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INGEST_MAX_PAYLOAD_KB: %w", err)
+	}
+
+	statsdFlushInterval, err := parseDuration(getEnv("STATSD_FLUSH_INTERVAL", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATSD_FLUSH_INTERVAL: %w", err)
+	}
+
+	statsdMaxSeries, err := strconv.Atoi(getEnv("STATSD_MAX_SERIES", "10000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATSD_MAX_SERIES: %w", err)
+	}
+
+	notificationWorkers, err := strconv.Atoi(getEnv("NOTIFICATION_WORKERS", "2"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_WORKERS: %w", err)
+	}
+
+	notificationQueueSize, err := strconv.Atoi(getEnv("NOTIFICATION_QUEUE_SIZE", "256"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_QUEUE_SIZE: %w", err)
+	}
+
+	notificationMaxAttempts, err := strconv.Atoi(getEnv("NOTIFICATION_MAX_ATTEMPTS", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_MAX_ATTEMPTS: %w", err)
+	}
+
+	notificationRetryBackoff, err := parseDuration(getEnv("NOTIFICATION_RETRY_BACKOFF", "2s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_RETRY_BACKOFF: %w", err)
+	}
+
+	notificationMaxBackoff, err := parseDuration(getEnv("NOTIFICATION_MAX_BACKOFF", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_MAX_BACKOFF: %w", err)
+	}
+
+	notificationSendTimeout, err := parseDuration(getEnv("NOTIFICATION_SEND_TIMEOUT", "10s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFICATION_SEND_TIMEOUT: %w", err)
+	}
+
 	redisCacheTTL, err := parseDuration(getEnv("REDIS_CACHE_TTL", "60s"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid REDIS_CACHE_TTL: %w", err)
@@ -282,8 +508,47 @@ func Load() (*Config, error) {
 			WriteTimeout: redisWriteTimeout,
 		},
 		Metrics: MetricsConfig{
-			CollectionInterval: collectionInterval,
-			RetentionDays:      retentionDays,
+			CollectionInterval:  collectionInterval,
+			RetentionDays:       retentionDays,
+			RetentionOverrides:  retentionOverrides,
+			RetentionInterval:   retentionInterval,
+			RetentionBatchSize:  retentionBatchSize,
+			RetentionBatchPause: retentionBatchPause,
+			RetentionDryRun:     getEnvBool("METRICS_RETENTION_DRY_RUN", false),
+			RollupsEnabled:      getEnvBool("METRICS_ROLLUPS_ENABLED", true),
+			RollupInterval:      rollupInterval,
+			RollupLag:           rollupLag,
+			RollupRetention:     rollupRetention,
+			HistoryMaxDuration:  historyMaxDuration,
+			PartitioningEnabled: getEnvBool("METRICS_PARTITIONING_ENABLED", true),
+			PartitionInterval:   partitionInterval,
+			PartitionPremake:    partitionPremake,
+			PartitionCheck:      partitionCheck,
+			WriteBufferEnabled:  getEnvBool("METRICS_WRITE_BUFFER_ENABLED", true),
+			WriteBufferSize:     writeBufferFlushSize,
+			WriteBufferInterval: writeBufferFlushInterval,
+			WriteBufferMax:      writeBufferMaxPending,
+			WriteBufferTimeout:  writeBufferEnqueueTimeout,
+			QueryLookback:       queryLookback,
+			QueryMaxSamples:     queryMaxSamples,
+			EndpointEnabled:     getEnvBool("METRICS_ENDPOINT_ENABLED", true),
+			ExportLabels:        splitCSV(getEnv("METRICS_EXPORT_METADATA_LABELS", "mount,cores,interface")),
+			ExportMaxRange:      exportMaxRange,
+			ImportMaxBytes:      int64(importMaxMB) * 1024 * 1024,
+			DiskFSTypes:         splitCSV(getEnv("METRICS_DISK_FS_TYPES", "")),
+			DiskExcludeFSTypes:  splitCSV(getEnv("METRICS_DISK_FS_TYPES_EXCLUDE", "")),
+			DiskExcludeMounts:   splitCSV(getEnv("METRICS_DISK_MOUNTS_EXCLUDE", "")),
+			DiskExcludeDevices:  splitCSV(getEnv("METRICS_DISK_DEVICES_EXCLUDE", "")),
+			NetworkInterfaces:   splitCSV(getEnv("METRICS_NETWORK_INTERFACES", "")),
+			NetworkExclude:      splitCSV(getEnv("METRICS_NETWORK_INTERFACES_EXCLUDE", "")),
+			ProcessesEnabled:    getEnvBool("METRICS_PROCESSES_ENABLED", true),
+			ProcessesTopN:       processesTopN,
+			ProcessesCmdline:    getEnvBool("METRICS_PROCESSES_CMDLINE", true),
+			CgroupEnabled:       getEnvBool("METRICS_CGROUP_ENABLED", true),
+			CgroupRoot:          getEnv("METRICS_CGROUP_ROOT", "/sys/fs/cgroup"),
+			CgroupPath:          getEnv("METRICS_CGROUP_PATH", ""),
+			Host:                getEnv("METRICS_HOST", defaultHostname()),
+			TypesFile:           getEnv("METRIC_TYPES_FILE", ""),
 		},
 		S3: S3Config{
 			Enabled:         getEnvBool("S3_ENABLED", true),
@@ -345,12 +610,41 @@ func Load() (*Config, error) {
 			Enabled: getEnvBool("NATS_ENABLED", false),
 			URL:     getEnv("NATS_URL", "nats://nats:4222"),
 		},
+		Ingest: IngestConfig{
+			Enabled:                getEnvBool("INGEST_ENABLED", false),
+			AuthToken:              getEnv("INGEST_AUTH_TOKEN", getEnv("AUTH_BEARER_TOKEN", "")),
+			MaxPayloadBytes:        int64(ingestMaxPayloadKB) * 1024,
+			RemoteWriteDefaultType: getEnv("INGEST_REMOTE_WRITE_DEFAULT_TYPE", ""),
+			OTLPDefaultType:        getEnv("INGEST_OTLP_DEFAULT_TYPE", ""),
+			OTLPResourceAttributes: splitCSV(getEnv("INGEST_OTLP_RESOURCE_ATTRIBUTES", "service.name,service.namespace,service.instance.id,deployment.environment,k8s.namespace.name,k8s.pod.name,container.name")),
+		},
+		StatsD: StatsDConfig{
+			Enabled:       getEnvBool("STATSD_ENABLED", false),
+			Address:       getEnv("STATSD_ADDRESS", ":8125"),
+			FlushInterval: statsdFlushInterval,
+			MaxSeries:     statsdMaxSeries,
+			DefaultType:   getEnv("STATSD_DEFAULT_TYPE", ""),
+			DefaultHost:   getEnv("STATSD_DEFAULT_HOST", ""),
+		},
+		Notifications: NotificationsConfig{
+			ChannelsFile: getEnv("NOTIFICATION_CHANNELS_FILE", ""),
+			Workers:      notificationWorkers,
+			QueueSize:    notificationQueueSize,
+			MaxAttempts:  notificationMaxAttempts,
+			RetryBackoff: notificationRetryBackoff,
+			MaxBackoff:   notificationMaxBackoff,
+			SendTimeout:  notificationSendTimeout,
+		},
 	}
 
 	if cfg.Security.AuthEnabled && cfg.Security.AuthToken == "" {
 		return nil, fmt.Errorf("AUTH_BEARER_TOKEN is required when AUTH_ENABLED=true")
 	}
 
+	if cfg.Ingest.Enabled && cfg.Ingest.AuthToken == "" {
+		return nil, fmt.Errorf("INGEST_AUTH_TOKEN (or AUTH_BEARER_TOKEN) is required when INGEST_ENABLED=true")
+	}
+
 	return cfg, nil
 }
 
@@ -366,6 +660,14 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func defaultHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "localhost"
+	}
+	return hostname
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	value := os.Getenv(key)
 	if value == "" {
@@ -416,6 +718,32 @@ func normalizeReleaseAnalyzerBaseURL(raw string) string {
 	return strings.TrimRight(trimmed, "/")
 }
 
+// defaultRollupRetention keeps each rollup tier long enough for the history ranges it serves
+var defaultRollupRetention = map[string]int{"1m": 2, "5m": 14, "1h": 90}
+
+// parseRetentionOverrides parses a comma-separated type=days string into a map.
+// Example: "cpu=3,disk=30" → {"cpu": 3, "disk": 30}
+func parseRetentionOverrides(raw string) (map[string]int, error) {
+	overrides := make(map[string]int)
+	if strings.TrimSpace(raw) == "" {
+		return overrides, nil
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("expected type=days, got %q", pair)
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid retention days for %q", parts[0])
+		}
+		overrides[strings.TrimSpace(parts[0])] = days
+	}
+
+	return overrides, nil
+}
+
 // parseDimensions parses a comma-separated key=value string into a map.
 // Example: "Environment=production,Host=server-01" → {"Environment": "production", "Host": "server-01"}
 func parseDimensions(raw string) map[string]string {